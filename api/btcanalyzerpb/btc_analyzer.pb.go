@@ -0,0 +1,1208 @@
+// Protobuf schema of the analyzer's gRPC API, served by `serve -grpc-addr`.
+// Field names mirror internal/types; optional analyses without a dedicated
+// message are carried as their JSON encoding in Analytics.details_json.
+//
+// Generate Go stubs with:
+//   protoc --go_out=. --go_opt=module=btc-analyzer \
+//     --go-grpc_out=. --go-grpc_opt=module=btc-analyzer \
+//     api/proto/btc_analyzer.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: api/proto/btc_analyzer.proto
+
+package btcanalyzerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TimeSeriesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Restrict the series to [start, end); unset bounds are open
+	Start *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	// Return only the last n bars when positive
+	Last          int32 `protobuf:"varint,3,opt,name=last,proto3" json:"last,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeSeriesRequest) Reset() {
+	*x = TimeSeriesRequest{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeSeriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeriesRequest) ProtoMessage() {}
+
+func (x *TimeSeriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSeriesRequest.ProtoReflect.Descriptor instead.
+func (*TimeSeriesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TimeSeriesRequest) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *TimeSeriesRequest) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *TimeSeriesRequest) GetLast() int32 {
+	if x != nil {
+		return x.Last
+	}
+	return 0
+}
+
+type AnalyticsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Include the full indicator series, not just their latest values
+	IncludeSeries bool `protobuf:"varint,1,opt,name=include_series,json=includeSeries,proto3" json:"include_series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyticsRequest) Reset() {
+	*x = AnalyticsRequest{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyticsRequest) ProtoMessage() {}
+
+func (x *AnalyticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyticsRequest.ProtoReflect.Descriptor instead.
+func (*AnalyticsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AnalyticsRequest) GetIncludeSeries() bool {
+	if x != nil {
+		return x.IncludeSeries
+	}
+	return false
+}
+
+type SignalsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalsRequest) Reset() {
+	*x = SignalsRequest{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalsRequest) ProtoMessage() {}
+
+func (x *SignalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalsRequest.ProtoReflect.Descriptor instead.
+func (*SignalsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{2}
+}
+
+type StreamRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	IncludeAnalytics bool                   `protobuf:"varint,1,opt,name=include_analytics,json=includeAnalytics,proto3" json:"include_analytics,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StreamRequest) Reset() {
+	*x = StreamRequest{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequest) ProtoMessage() {}
+
+func (x *StreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamRequest) GetIncludeAnalytics() bool {
+	if x != nil {
+		return x.IncludeAnalytics
+	}
+	return false
+}
+
+type Price struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Open          float64                `protobuf:"fixed64,2,opt,name=open,proto3" json:"open,omitempty"`
+	High          float64                `protobuf:"fixed64,3,opt,name=high,proto3" json:"high,omitempty"`
+	Low           float64                `protobuf:"fixed64,4,opt,name=low,proto3" json:"low,omitempty"`
+	Close         float64                `protobuf:"fixed64,5,opt,name=close,proto3" json:"close,omitempty"`
+	Volume        float64                `protobuf:"fixed64,6,opt,name=volume,proto3" json:"volume,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Price) Reset() {
+	*x = Price{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Price) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Price) ProtoMessage() {}
+
+func (x *Price) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Price.ProtoReflect.Descriptor instead.
+func (*Price) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Price) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Price) GetOpen() float64 {
+	if x != nil {
+		return x.Open
+	}
+	return 0
+}
+
+func (x *Price) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *Price) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *Price) GetClose() float64 {
+	if x != nil {
+		return x.Close
+	}
+	return 0
+}
+
+func (x *Price) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+type TimeSeries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Data          []*Price               `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeSeries) Reset() {
+	*x = TimeSeries{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeSeries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeries) ProtoMessage() {}
+
+func (x *TimeSeries) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSeries.ProtoReflect.Descriptor instead.
+func (*TimeSeries) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TimeSeries) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *TimeSeries) GetData() []*Price {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Statistics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int32                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Mean          float64                `protobuf:"fixed64,2,opt,name=mean,proto3" json:"mean,omitempty"`
+	Median        float64                `protobuf:"fixed64,3,opt,name=median,proto3" json:"median,omitempty"`
+	StdDev        float64                `protobuf:"fixed64,4,opt,name=std_dev,json=stdDev,proto3" json:"std_dev,omitempty"`
+	Min           float64                `protobuf:"fixed64,5,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,6,opt,name=max,proto3" json:"max,omitempty"`
+	Variance      float64                `protobuf:"fixed64,7,opt,name=variance,proto3" json:"variance,omitempty"`
+	Skewness      float64                `protobuf:"fixed64,8,opt,name=skewness,proto3" json:"skewness,omitempty"`
+	Kurtosis      float64                `protobuf:"fixed64,9,opt,name=kurtosis,proto3" json:"kurtosis,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Statistics) Reset() {
+	*x = Statistics{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Statistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Statistics) ProtoMessage() {}
+
+func (x *Statistics) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Statistics.ProtoReflect.Descriptor instead.
+func (*Statistics) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Statistics) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Statistics) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *Statistics) GetMedian() float64 {
+	if x != nil {
+		return x.Median
+	}
+	return 0
+}
+
+func (x *Statistics) GetStdDev() float64 {
+	if x != nil {
+		return x.StdDev
+	}
+	return 0
+}
+
+func (x *Statistics) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *Statistics) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *Statistics) GetVariance() float64 {
+	if x != nil {
+		return x.Variance
+	}
+	return 0
+}
+
+func (x *Statistics) GetSkewness() float64 {
+	if x != nil {
+		return x.Skewness
+	}
+	return 0
+}
+
+func (x *Statistics) GetKurtosis() float64 {
+	if x != nil {
+		return x.Kurtosis
+	}
+	return 0
+}
+
+type MACD struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Macd          []float64              `protobuf:"fixed64,1,rep,packed,name=macd,proto3" json:"macd,omitempty"`
+	Signal        []float64              `protobuf:"fixed64,2,rep,packed,name=signal,proto3" json:"signal,omitempty"`
+	Histogram     []float64              `protobuf:"fixed64,3,rep,packed,name=histogram,proto3" json:"histogram,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MACD) Reset() {
+	*x = MACD{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MACD) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MACD) ProtoMessage() {}
+
+func (x *MACD) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MACD.ProtoReflect.Descriptor instead.
+func (*MACD) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MACD) GetMacd() []float64 {
+	if x != nil {
+		return x.Macd
+	}
+	return nil
+}
+
+func (x *MACD) GetSignal() []float64 {
+	if x != nil {
+		return x.Signal
+	}
+	return nil
+}
+
+func (x *MACD) GetHistogram() []float64 {
+	if x != nil {
+		return x.Histogram
+	}
+	return nil
+}
+
+type BollingerBands struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upper         []float64              `protobuf:"fixed64,1,rep,packed,name=upper,proto3" json:"upper,omitempty"`
+	Middle        []float64              `protobuf:"fixed64,2,rep,packed,name=middle,proto3" json:"middle,omitempty"`
+	Lower         []float64              `protobuf:"fixed64,3,rep,packed,name=lower,proto3" json:"lower,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BollingerBands) Reset() {
+	*x = BollingerBands{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BollingerBands) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BollingerBands) ProtoMessage() {}
+
+func (x *BollingerBands) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BollingerBands.ProtoReflect.Descriptor instead.
+func (*BollingerBands) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BollingerBands) GetUpper() []float64 {
+	if x != nil {
+		return x.Upper
+	}
+	return nil
+}
+
+func (x *BollingerBands) GetMiddle() []float64 {
+	if x != nil {
+		return x.Middle
+	}
+	return nil
+}
+
+func (x *BollingerBands) GetLower() []float64 {
+	if x != nil {
+		return x.Lower
+	}
+	return nil
+}
+
+type SRLevel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         float64                `protobuf:"fixed64,1,opt,name=price,proto3" json:"price,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SRLevel) Reset() {
+	*x = SRLevel{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SRLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SRLevel) ProtoMessage() {}
+
+func (x *SRLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SRLevel.ProtoReflect.Descriptor instead.
+func (*SRLevel) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SRLevel) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SRLevel) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SRLevel) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type SupportResistance struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SupportLevels    []float64              `protobuf:"fixed64,1,rep,packed,name=support_levels,json=supportLevels,proto3" json:"support_levels,omitempty"`
+	ResistanceLevels []float64              `protobuf:"fixed64,2,rep,packed,name=resistance_levels,json=resistanceLevels,proto3" json:"resistance_levels,omitempty"`
+	Levels           []*SRLevel             `protobuf:"bytes,3,rep,name=levels,proto3" json:"levels,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SupportResistance) Reset() {
+	*x = SupportResistance{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SupportResistance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SupportResistance) ProtoMessage() {}
+
+func (x *SupportResistance) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SupportResistance.ProtoReflect.Descriptor instead.
+func (*SupportResistance) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SupportResistance) GetSupportLevels() []float64 {
+	if x != nil {
+		return x.SupportLevels
+	}
+	return nil
+}
+
+func (x *SupportResistance) GetResistanceLevels() []float64 {
+	if x != nil {
+		return x.ResistanceLevels
+	}
+	return nil
+}
+
+func (x *SupportResistance) GetLevels() []*SRLevel {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
+type RiskConfig struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	RiskFreeRate   float64                `protobuf:"fixed64,1,opt,name=risk_free_rate,json=riskFreeRate,proto3" json:"risk_free_rate,omitempty"`
+	FundingRate    float64                `protobuf:"fixed64,2,opt,name=funding_rate,json=fundingRate,proto3" json:"funding_rate,omitempty"`
+	PeriodsPerYear int32                  `protobuf:"varint,3,opt,name=periods_per_year,json=periodsPerYear,proto3" json:"periods_per_year,omitempty"`
+	Compounding    string                 `protobuf:"bytes,4,opt,name=compounding,proto3" json:"compounding,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RiskConfig) Reset() {
+	*x = RiskConfig{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RiskConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RiskConfig) ProtoMessage() {}
+
+func (x *RiskConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RiskConfig.ProtoReflect.Descriptor instead.
+func (*RiskConfig) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RiskConfig) GetRiskFreeRate() float64 {
+	if x != nil {
+		return x.RiskFreeRate
+	}
+	return 0
+}
+
+func (x *RiskConfig) GetFundingRate() float64 {
+	if x != nil {
+		return x.FundingRate
+	}
+	return 0
+}
+
+func (x *RiskConfig) GetPeriodsPerYear() int32 {
+	if x != nil {
+		return x.PeriodsPerYear
+	}
+	return 0
+}
+
+func (x *RiskConfig) GetCompounding() string {
+	if x != nil {
+		return x.Compounding
+	}
+	return ""
+}
+
+type Analytics struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	PriceStats        *Statistics            `protobuf:"bytes,1,opt,name=price_stats,json=priceStats,proto3" json:"price_stats,omitempty"`
+	VolumeStats       *Statistics            `protobuf:"bytes,2,opt,name=volume_stats,json=volumeStats,proto3" json:"volume_stats,omitempty"`
+	Volatility        float64                `protobuf:"fixed64,3,opt,name=volatility,proto3" json:"volatility,omitempty"`
+	SharpeRatio       float64                `protobuf:"fixed64,4,opt,name=sharpe_ratio,json=sharpeRatio,proto3" json:"sharpe_ratio,omitempty"`
+	MaxDrawdown       float64                `protobuf:"fixed64,5,opt,name=max_drawdown,json=maxDrawdown,proto3" json:"max_drawdown,omitempty"`
+	Returns           []float64              `protobuf:"fixed64,6,rep,packed,name=returns,proto3" json:"returns,omitempty"`
+	LogReturns        []float64              `protobuf:"fixed64,7,rep,packed,name=log_returns,json=logReturns,proto3" json:"log_returns,omitempty"`
+	Rsi               []float64              `protobuf:"fixed64,8,rep,packed,name=rsi,proto3" json:"rsi,omitempty"`
+	Macd              *MACD                  `protobuf:"bytes,9,opt,name=macd,proto3" json:"macd,omitempty"`
+	BollingerBands    *BollingerBands        `protobuf:"bytes,10,opt,name=bollinger_bands,json=bollingerBands,proto3" json:"bollinger_bands,omitempty"`
+	SupportResistance *SupportResistance     `protobuf:"bytes,11,opt,name=support_resistance,json=supportResistance,proto3" json:"support_resistance,omitempty"`
+	RiskConvention    *RiskConfig            `protobuf:"bytes,12,opt,name=risk_convention,json=riskConvention,proto3" json:"risk_convention,omitempty"`
+	// JSON encoding of the optional analyses (order book, order flow, Elliott
+	// waves, harmonics, Wyckoff, forensics, premium, implied volatility, ...)
+	DetailsJson   []byte `protobuf:"bytes,13,opt,name=details_json,json=detailsJson,proto3" json:"details_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Analytics) Reset() {
+	*x = Analytics{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Analytics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Analytics) ProtoMessage() {}
+
+func (x *Analytics) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Analytics.ProtoReflect.Descriptor instead.
+func (*Analytics) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Analytics) GetPriceStats() *Statistics {
+	if x != nil {
+		return x.PriceStats
+	}
+	return nil
+}
+
+func (x *Analytics) GetVolumeStats() *Statistics {
+	if x != nil {
+		return x.VolumeStats
+	}
+	return nil
+}
+
+func (x *Analytics) GetVolatility() float64 {
+	if x != nil {
+		return x.Volatility
+	}
+	return 0
+}
+
+func (x *Analytics) GetSharpeRatio() float64 {
+	if x != nil {
+		return x.SharpeRatio
+	}
+	return 0
+}
+
+func (x *Analytics) GetMaxDrawdown() float64 {
+	if x != nil {
+		return x.MaxDrawdown
+	}
+	return 0
+}
+
+func (x *Analytics) GetReturns() []float64 {
+	if x != nil {
+		return x.Returns
+	}
+	return nil
+}
+
+func (x *Analytics) GetLogReturns() []float64 {
+	if x != nil {
+		return x.LogReturns
+	}
+	return nil
+}
+
+func (x *Analytics) GetRsi() []float64 {
+	if x != nil {
+		return x.Rsi
+	}
+	return nil
+}
+
+func (x *Analytics) GetMacd() *MACD {
+	if x != nil {
+		return x.Macd
+	}
+	return nil
+}
+
+func (x *Analytics) GetBollingerBands() *BollingerBands {
+	if x != nil {
+		return x.BollingerBands
+	}
+	return nil
+}
+
+func (x *Analytics) GetSupportResistance() *SupportResistance {
+	if x != nil {
+		return x.SupportResistance
+	}
+	return nil
+}
+
+func (x *Analytics) GetRiskConvention() *RiskConfig {
+	if x != nil {
+		return x.RiskConvention
+	}
+	return nil
+}
+
+func (x *Analytics) GetDetailsJson() []byte {
+	if x != nil {
+		return x.DetailsJson
+	}
+	return nil
+}
+
+type Signals struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Signal name to text, e.g. "RSI" -> "BUY (oversold)"
+	Signals map[string]string `protobuf:"bytes,1,rep,name=signals,proto3" json:"signals,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Mean of the signals from -1 (all SELL) to +1 (all BUY)
+	Score         float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Signals) Reset() {
+	*x = Signals{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Signals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signals) ProtoMessage() {}
+
+func (x *Signals) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signals.ProtoReflect.Descriptor instead.
+func (*Signals) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Signals) GetSignals() map[string]string {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+func (x *Signals) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Update struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bar           *Price                 `protobuf:"bytes,1,opt,name=bar,proto3" json:"bar,omitempty"`
+	Signals       *Signals               `protobuf:"bytes,2,opt,name=signals,proto3" json:"signals,omitempty"`
+	Analytics     *Analytics             `protobuf:"bytes,3,opt,name=analytics,proto3" json:"analytics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Update) Reset() {
+	*x = Update{}
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Update) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Update) ProtoMessage() {}
+
+func (x *Update) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_btc_analyzer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Update.ProtoReflect.Descriptor instead.
+func (*Update) Descriptor() ([]byte, []int) {
+	return file_api_proto_btc_analyzer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Update) GetBar() *Price {
+	if x != nil {
+		return x.Bar
+	}
+	return nil
+}
+
+func (x *Update) GetSignals() *Signals {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+func (x *Update) GetAnalytics() *Analytics {
+	if x != nil {
+		return x.Analytics
+	}
+	return nil
+}
+
+var File_api_proto_btc_analyzer_proto protoreflect.FileDescriptor
+
+const file_api_proto_btc_analyzer_proto_rawDesc = "" +
+	"\n" +
+	"\x1capi/proto/btc_analyzer.proto\x12\x0ebtcanalyzer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x87\x01\n" +
+	"\x11TimeSeriesRequest\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x12\x12\n" +
+	"\x04last\x18\x03 \x01(\x05R\x04last\"9\n" +
+	"\x10AnalyticsRequest\x12%\n" +
+	"\x0einclude_series\x18\x01 \x01(\bR\rincludeSeries\"\x10\n" +
+	"\x0eSignalsRequest\"<\n" +
+	"\rStreamRequest\x12+\n" +
+	"\x11include_analytics\x18\x01 \x01(\bR\x10includeAnalytics\"\xa9\x01\n" +
+	"\x05Price\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x12\n" +
+	"\x04open\x18\x02 \x01(\x01R\x04open\x12\x12\n" +
+	"\x04high\x18\x03 \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\x04 \x01(\x01R\x03low\x12\x14\n" +
+	"\x05close\x18\x05 \x01(\x01R\x05close\x12\x16\n" +
+	"\x06volume\x18\x06 \x01(\x01R\x06volume\"O\n" +
+	"\n" +
+	"TimeSeries\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12)\n" +
+	"\x04data\x18\x02 \x03(\v2\x15.btcanalyzer.v1.PriceR\x04data\"\xdf\x01\n" +
+	"\n" +
+	"Statistics\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x05R\x05count\x12\x12\n" +
+	"\x04mean\x18\x02 \x01(\x01R\x04mean\x12\x16\n" +
+	"\x06median\x18\x03 \x01(\x01R\x06median\x12\x17\n" +
+	"\astd_dev\x18\x04 \x01(\x01R\x06stdDev\x12\x10\n" +
+	"\x03min\x18\x05 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\x06 \x01(\x01R\x03max\x12\x1a\n" +
+	"\bvariance\x18\a \x01(\x01R\bvariance\x12\x1a\n" +
+	"\bskewness\x18\b \x01(\x01R\bskewness\x12\x1a\n" +
+	"\bkurtosis\x18\t \x01(\x01R\bkurtosis\"P\n" +
+	"\x04MACD\x12\x12\n" +
+	"\x04macd\x18\x01 \x03(\x01R\x04macd\x12\x16\n" +
+	"\x06signal\x18\x02 \x03(\x01R\x06signal\x12\x1c\n" +
+	"\thistogram\x18\x03 \x03(\x01R\thistogram\"T\n" +
+	"\x0eBollingerBands\x12\x14\n" +
+	"\x05upper\x18\x01 \x03(\x01R\x05upper\x12\x16\n" +
+	"\x06middle\x18\x02 \x03(\x01R\x06middle\x12\x14\n" +
+	"\x05lower\x18\x03 \x03(\x01R\x05lower\"K\n" +
+	"\aSRLevel\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\x01R\x05price\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\"\x98\x01\n" +
+	"\x11SupportResistance\x12%\n" +
+	"\x0esupport_levels\x18\x01 \x03(\x01R\rsupportLevels\x12+\n" +
+	"\x11resistance_levels\x18\x02 \x03(\x01R\x10resistanceLevels\x12/\n" +
+	"\x06levels\x18\x03 \x03(\v2\x17.btcanalyzer.v1.SRLevelR\x06levels\"\xa1\x01\n" +
+	"\n" +
+	"RiskConfig\x12$\n" +
+	"\x0erisk_free_rate\x18\x01 \x01(\x01R\friskFreeRate\x12!\n" +
+	"\ffunding_rate\x18\x02 \x01(\x01R\vfundingRate\x12(\n" +
+	"\x10periods_per_year\x18\x03 \x01(\x05R\x0eperiodsPerYear\x12 \n" +
+	"\vcompounding\x18\x04 \x01(\tR\vcompounding\"\xe7\x04\n" +
+	"\tAnalytics\x12;\n" +
+	"\vprice_stats\x18\x01 \x01(\v2\x1a.btcanalyzer.v1.StatisticsR\n" +
+	"priceStats\x12=\n" +
+	"\fvolume_stats\x18\x02 \x01(\v2\x1a.btcanalyzer.v1.StatisticsR\vvolumeStats\x12\x1e\n" +
+	"\n" +
+	"volatility\x18\x03 \x01(\x01R\n" +
+	"volatility\x12!\n" +
+	"\fsharpe_ratio\x18\x04 \x01(\x01R\vsharpeRatio\x12!\n" +
+	"\fmax_drawdown\x18\x05 \x01(\x01R\vmaxDrawdown\x12\x18\n" +
+	"\areturns\x18\x06 \x03(\x01R\areturns\x12\x1f\n" +
+	"\vlog_returns\x18\a \x03(\x01R\n" +
+	"logReturns\x12\x10\n" +
+	"\x03rsi\x18\b \x03(\x01R\x03rsi\x12(\n" +
+	"\x04macd\x18\t \x01(\v2\x14.btcanalyzer.v1.MACDR\x04macd\x12G\n" +
+	"\x0fbollinger_bands\x18\n" +
+	" \x01(\v2\x1e.btcanalyzer.v1.BollingerBandsR\x0ebollingerBands\x12P\n" +
+	"\x12support_resistance\x18\v \x01(\v2!.btcanalyzer.v1.SupportResistanceR\x11supportResistance\x12C\n" +
+	"\x0frisk_convention\x18\f \x01(\v2\x1a.btcanalyzer.v1.RiskConfigR\x0eriskConvention\x12!\n" +
+	"\fdetails_json\x18\r \x01(\fR\vdetailsJson\"\x9b\x01\n" +
+	"\aSignals\x12>\n" +
+	"\asignals\x18\x01 \x03(\v2$.btcanalyzer.v1.Signals.SignalsEntryR\asignals\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x1a:\n" +
+	"\fSignalsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9d\x01\n" +
+	"\x06Update\x12'\n" +
+	"\x03bar\x18\x01 \x01(\v2\x15.btcanalyzer.v1.PriceR\x03bar\x121\n" +
+	"\asignals\x18\x02 \x01(\v2\x17.btcanalyzer.v1.SignalsR\asignals\x127\n" +
+	"\tanalytics\x18\x03 \x01(\v2\x19.btcanalyzer.v1.AnalyticsR\tanalytics2\xb8\x02\n" +
+	"\bAnalyzer\x12N\n" +
+	"\rGetTimeSeries\x12!.btcanalyzer.v1.TimeSeriesRequest\x1a\x1a.btcanalyzer.v1.TimeSeries\x12K\n" +
+	"\fGetAnalytics\x12 .btcanalyzer.v1.AnalyticsRequest\x1a\x19.btcanalyzer.v1.Analytics\x12E\n" +
+	"\n" +
+	"GetSignals\x12\x1e.btcanalyzer.v1.SignalsRequest\x1a\x17.btcanalyzer.v1.Signals\x12H\n" +
+	"\rStreamUpdates\x12\x1d.btcanalyzer.v1.StreamRequest\x1a\x16.btcanalyzer.v1.Update0\x01B Z\x1ebtc-analyzer/api/btcanalyzerpbb\x06proto3"
+
+var (
+	file_api_proto_btc_analyzer_proto_rawDescOnce sync.Once
+	file_api_proto_btc_analyzer_proto_rawDescData []byte
+)
+
+func file_api_proto_btc_analyzer_proto_rawDescGZIP() []byte {
+	file_api_proto_btc_analyzer_proto_rawDescOnce.Do(func() {
+		file_api_proto_btc_analyzer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_btc_analyzer_proto_rawDesc), len(file_api_proto_btc_analyzer_proto_rawDesc)))
+	})
+	return file_api_proto_btc_analyzer_proto_rawDescData
+}
+
+var file_api_proto_btc_analyzer_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_api_proto_btc_analyzer_proto_goTypes = []any{
+	(*TimeSeriesRequest)(nil),     // 0: btcanalyzer.v1.TimeSeriesRequest
+	(*AnalyticsRequest)(nil),      // 1: btcanalyzer.v1.AnalyticsRequest
+	(*SignalsRequest)(nil),        // 2: btcanalyzer.v1.SignalsRequest
+	(*StreamRequest)(nil),         // 3: btcanalyzer.v1.StreamRequest
+	(*Price)(nil),                 // 4: btcanalyzer.v1.Price
+	(*TimeSeries)(nil),            // 5: btcanalyzer.v1.TimeSeries
+	(*Statistics)(nil),            // 6: btcanalyzer.v1.Statistics
+	(*MACD)(nil),                  // 7: btcanalyzer.v1.MACD
+	(*BollingerBands)(nil),        // 8: btcanalyzer.v1.BollingerBands
+	(*SRLevel)(nil),               // 9: btcanalyzer.v1.SRLevel
+	(*SupportResistance)(nil),     // 10: btcanalyzer.v1.SupportResistance
+	(*RiskConfig)(nil),            // 11: btcanalyzer.v1.RiskConfig
+	(*Analytics)(nil),             // 12: btcanalyzer.v1.Analytics
+	(*Signals)(nil),               // 13: btcanalyzer.v1.Signals
+	(*Update)(nil),                // 14: btcanalyzer.v1.Update
+	nil,                           // 15: btcanalyzer.v1.Signals.SignalsEntry
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_api_proto_btc_analyzer_proto_depIdxs = []int32{
+	16, // 0: btcanalyzer.v1.TimeSeriesRequest.start:type_name -> google.protobuf.Timestamp
+	16, // 1: btcanalyzer.v1.TimeSeriesRequest.end:type_name -> google.protobuf.Timestamp
+	16, // 2: btcanalyzer.v1.Price.timestamp:type_name -> google.protobuf.Timestamp
+	4,  // 3: btcanalyzer.v1.TimeSeries.data:type_name -> btcanalyzer.v1.Price
+	9,  // 4: btcanalyzer.v1.SupportResistance.levels:type_name -> btcanalyzer.v1.SRLevel
+	6,  // 5: btcanalyzer.v1.Analytics.price_stats:type_name -> btcanalyzer.v1.Statistics
+	6,  // 6: btcanalyzer.v1.Analytics.volume_stats:type_name -> btcanalyzer.v1.Statistics
+	7,  // 7: btcanalyzer.v1.Analytics.macd:type_name -> btcanalyzer.v1.MACD
+	8,  // 8: btcanalyzer.v1.Analytics.bollinger_bands:type_name -> btcanalyzer.v1.BollingerBands
+	10, // 9: btcanalyzer.v1.Analytics.support_resistance:type_name -> btcanalyzer.v1.SupportResistance
+	11, // 10: btcanalyzer.v1.Analytics.risk_convention:type_name -> btcanalyzer.v1.RiskConfig
+	15, // 11: btcanalyzer.v1.Signals.signals:type_name -> btcanalyzer.v1.Signals.SignalsEntry
+	4,  // 12: btcanalyzer.v1.Update.bar:type_name -> btcanalyzer.v1.Price
+	13, // 13: btcanalyzer.v1.Update.signals:type_name -> btcanalyzer.v1.Signals
+	12, // 14: btcanalyzer.v1.Update.analytics:type_name -> btcanalyzer.v1.Analytics
+	0,  // 15: btcanalyzer.v1.Analyzer.GetTimeSeries:input_type -> btcanalyzer.v1.TimeSeriesRequest
+	1,  // 16: btcanalyzer.v1.Analyzer.GetAnalytics:input_type -> btcanalyzer.v1.AnalyticsRequest
+	2,  // 17: btcanalyzer.v1.Analyzer.GetSignals:input_type -> btcanalyzer.v1.SignalsRequest
+	3,  // 18: btcanalyzer.v1.Analyzer.StreamUpdates:input_type -> btcanalyzer.v1.StreamRequest
+	5,  // 19: btcanalyzer.v1.Analyzer.GetTimeSeries:output_type -> btcanalyzer.v1.TimeSeries
+	12, // 20: btcanalyzer.v1.Analyzer.GetAnalytics:output_type -> btcanalyzer.v1.Analytics
+	13, // 21: btcanalyzer.v1.Analyzer.GetSignals:output_type -> btcanalyzer.v1.Signals
+	14, // 22: btcanalyzer.v1.Analyzer.StreamUpdates:output_type -> btcanalyzer.v1.Update
+	19, // [19:23] is the sub-list for method output_type
+	15, // [15:19] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_btc_analyzer_proto_init() }
+func file_api_proto_btc_analyzer_proto_init() {
+	if File_api_proto_btc_analyzer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_btc_analyzer_proto_rawDesc), len(file_api_proto_btc_analyzer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_proto_btc_analyzer_proto_goTypes,
+		DependencyIndexes: file_api_proto_btc_analyzer_proto_depIdxs,
+		MessageInfos:      file_api_proto_btc_analyzer_proto_msgTypes,
+	}.Build()
+	File_api_proto_btc_analyzer_proto = out.File
+	file_api_proto_btc_analyzer_proto_goTypes = nil
+	file_api_proto_btc_analyzer_proto_depIdxs = nil
+}
@@ -0,0 +1,258 @@
+// Protobuf schema of the analyzer's gRPC API, served by `serve -grpc-addr`.
+// Field names mirror internal/types; optional analyses without a dedicated
+// message are carried as their JSON encoding in Analytics.details_json.
+//
+// Generate Go stubs with:
+//   protoc --go_out=. --go_opt=module=btc-analyzer \
+//     --go-grpc_out=. --go-grpc_opt=module=btc-analyzer \
+//     api/proto/btc_analyzer.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/proto/btc_analyzer.proto
+
+package btcanalyzerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Analyzer_GetTimeSeries_FullMethodName = "/btcanalyzer.v1.Analyzer/GetTimeSeries"
+	Analyzer_GetAnalytics_FullMethodName  = "/btcanalyzer.v1.Analyzer/GetAnalytics"
+	Analyzer_GetSignals_FullMethodName    = "/btcanalyzer.v1.Analyzer/GetSignals"
+	Analyzer_StreamUpdates_FullMethodName = "/btcanalyzer.v1.Analyzer/StreamUpdates"
+)
+
+// AnalyzerClient is the client API for Analyzer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AnalyzerClient interface {
+	// GetTimeSeries returns the loaded OHLCV series
+	GetTimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (*TimeSeries, error)
+	// GetAnalytics runs the analysis pipeline on the loaded series
+	GetAnalytics(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*Analytics, error)
+	// GetSignals returns the current trading signals and their composite score
+	GetSignals(ctx context.Context, in *SignalsRequest, opts ...grpc.CallOption) (*Signals, error)
+	// StreamUpdates sends the current Update, then a new one after every
+	// re-analysis of the served series, as its latest bar is added or updated
+	StreamUpdates(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Update], error)
+}
+
+type analyzerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAnalyzerClient(cc grpc.ClientConnInterface) AnalyzerClient {
+	return &analyzerClient{cc}
+}
+
+func (c *analyzerClient) GetTimeSeries(ctx context.Context, in *TimeSeriesRequest, opts ...grpc.CallOption) (*TimeSeries, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TimeSeries)
+	err := c.cc.Invoke(ctx, Analyzer_GetTimeSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyzerClient) GetAnalytics(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*Analytics, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Analytics)
+	err := c.cc.Invoke(ctx, Analyzer_GetAnalytics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyzerClient) GetSignals(ctx context.Context, in *SignalsRequest, opts ...grpc.CallOption) (*Signals, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Signals)
+	err := c.cc.Invoke(ctx, Analyzer_GetSignals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyzerClient) StreamUpdates(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Update], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Analyzer_ServiceDesc.Streams[0], Analyzer_StreamUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRequest, Update]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Analyzer_StreamUpdatesClient = grpc.ServerStreamingClient[Update]
+
+// AnalyzerServer is the server API for Analyzer service.
+// All implementations must embed UnimplementedAnalyzerServer
+// for forward compatibility.
+type AnalyzerServer interface {
+	// GetTimeSeries returns the loaded OHLCV series
+	GetTimeSeries(context.Context, *TimeSeriesRequest) (*TimeSeries, error)
+	// GetAnalytics runs the analysis pipeline on the loaded series
+	GetAnalytics(context.Context, *AnalyticsRequest) (*Analytics, error)
+	// GetSignals returns the current trading signals and their composite score
+	GetSignals(context.Context, *SignalsRequest) (*Signals, error)
+	// StreamUpdates sends the current Update, then a new one after every
+	// re-analysis of the served series, as its latest bar is added or updated
+	StreamUpdates(*StreamRequest, grpc.ServerStreamingServer[Update]) error
+	mustEmbedUnimplementedAnalyzerServer()
+}
+
+// UnimplementedAnalyzerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAnalyzerServer struct{}
+
+func (UnimplementedAnalyzerServer) GetTimeSeries(context.Context, *TimeSeriesRequest) (*TimeSeries, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTimeSeries not implemented")
+}
+func (UnimplementedAnalyzerServer) GetAnalytics(context.Context, *AnalyticsRequest) (*Analytics, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAnalytics not implemented")
+}
+func (UnimplementedAnalyzerServer) GetSignals(context.Context, *SignalsRequest) (*Signals, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSignals not implemented")
+}
+func (UnimplementedAnalyzerServer) StreamUpdates(*StreamRequest, grpc.ServerStreamingServer[Update]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUpdates not implemented")
+}
+func (UnimplementedAnalyzerServer) mustEmbedUnimplementedAnalyzerServer() {}
+func (UnimplementedAnalyzerServer) testEmbeddedByValue()                  {}
+
+// UnsafeAnalyzerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AnalyzerServer will
+// result in compilation errors.
+type UnsafeAnalyzerServer interface {
+	mustEmbedUnimplementedAnalyzerServer()
+}
+
+func RegisterAnalyzerServer(s grpc.ServiceRegistrar, srv AnalyzerServer) {
+	// If the following call pancis, it indicates UnimplementedAnalyzerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Analyzer_ServiceDesc, srv)
+}
+
+func _Analyzer_GetTimeSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimeSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyzerServer).GetTimeSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Analyzer_GetTimeSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyzerServer).GetTimeSeries(ctx, req.(*TimeSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Analyzer_GetAnalytics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyzerServer).GetAnalytics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Analyzer_GetAnalytics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyzerServer).GetAnalytics(ctx, req.(*AnalyticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Analyzer_GetSignals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyzerServer).GetSignals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Analyzer_GetSignals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyzerServer).GetSignals(ctx, req.(*SignalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Analyzer_StreamUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AnalyzerServer).StreamUpdates(m, &grpc.GenericServerStream[StreamRequest, Update]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Analyzer_StreamUpdatesServer = grpc.ServerStreamingServer[Update]
+
+// Analyzer_ServiceDesc is the grpc.ServiceDesc for Analyzer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Analyzer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "btcanalyzer.v1.Analyzer",
+	HandlerType: (*AnalyzerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTimeSeries",
+			Handler:    _Analyzer_GetTimeSeries_Handler,
+		},
+		{
+			MethodName: "GetAnalytics",
+			Handler:    _Analyzer_GetAnalytics_Handler,
+		},
+		{
+			MethodName: "GetSignals",
+			Handler:    _Analyzer_GetSignals_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUpdates",
+			Handler:       _Analyzer_StreamUpdates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/proto/btc_analyzer.proto",
+}
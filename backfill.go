@@ -0,0 +1,284 @@
+package main
+
+import (
+	"btc-analyzer/internal/candlelog"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/store"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxGapsPrinted bounds the gaps listed by the continuity check
+const maxGapsPrinted = 10
+
+// backfillRetryDelay is the wait before the first retry of a failed chunk;
+// it doubles with every further retry
+const backfillRetryDelay = 2 * time.Second
+
+// runBackfillCommand pages Binance kline history from -from in chunks of
+// one request each, stitches the chunks into one series, checks it for gaps
+// and writes it to the candle log and/or an export file. Chunks are appended
+// to the candle log as they arrive, so an interrupted backfill resumes after
+// the last stored candle. A backfill to a file stages its chunks next to it
+// and checkpoints its progress in the checkpoint store, so it resumes from
+// the last complete chunk too.
+func runBackfillCommand(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	fromFlag := fs.String("from", "", "First day to backfill, YYYY-MM-DD (required)")
+	toFlag := fs.String("to", "", "Day to backfill up to, YYYY-MM-DD (empty = now)")
+	symbol := fs.String("symbol", "BTCUSDT", "Binance symbol")
+	interval := fs.String("interval", "1d", "Binance kline interval")
+	var logCfg candlelog.Config
+	fs.StringVar(&logCfg.Path, "candle-log", "", "Candle log to append the history to")
+	fs.Int64Var(&logCfg.MaxBytes, "candle-log-max-bytes", 64<<20, "Rotate the candle log before it grows past this size (0 = no limit)")
+	out := fs.String("out", "", "Also write the backfilled series to this .csv, .json or .ndjson file")
+	checkpointPath := fs.String("checkpoints", "backfill_checkpoints.json", "Checkpoint store, so an interrupted backfill to -out resumes from its last complete chunk ('' = off)")
+	restart := fs.Bool("restart", false, "Discard the checkpoint of this backfill and start over")
+	retries := fs.Int("retries", 3, "Retries of a failed chunk, waiting twice as long before each, before the backfill stops")
+	fs.Parse(args)
+	if err := applyEnvFlags(fs); err != nil {
+		log.Fatal(err)
+	}
+
+	if *fromFlag == "" || (logCfg.Path == "" && *out == "") {
+		log.Fatal("usage: btc-analyzer backfill -from YYYY-MM-DD [-to YYYY-MM-DD] (-candle-log FILE | -out FILE) [flags]")
+	}
+	step, err := dataloader.BinanceInterval(*interval)
+	if err != nil {
+		log.Fatal(err)
+	}
+	from, err := time.Parse("2006-01-02", *fromFlag)
+	if err != nil {
+		log.Fatalf("Invalid -from: %v", err)
+	}
+	to := time.Now()
+	if *toFlag != "" {
+		if to, err = time.Parse("2006-01-02", *toFlag); err != nil {
+			log.Fatalf("Invalid -to: %v", err)
+		}
+	}
+	if !from.Before(to) {
+		log.Fatal("-from must be before -to")
+	}
+	if *retries < 0 {
+		log.Fatal("-retries must not be negative")
+	}
+
+	var candles *candlelog.Log
+	var stored time.Time
+	if logCfg.Path != "" {
+		if candles, err = candlelog.Open(logCfg); err != nil {
+			log.Fatal(err)
+		}
+		defer candles.Close()
+		if stored = candles.Last(); !stored.Before(from) {
+			from = stored.Add(step)
+			fmt.Printf("⏩ %s already holds candles up to %s; resuming after it\n", logCfg.Path, stored.Format("2006-01-02 15:04"))
+		}
+	}
+
+	bts := timeseries.New(*symbol)
+	var checkpoints *store.CheckpointStore
+	var checkpoint types.DownloadCheckpoint
+	var staging *candlelog.Log
+	if *out != "" && *checkpointPath != "" {
+		if checkpoints, err = store.OpenCheckpoints(*checkpointPath); err != nil {
+			log.Fatal(err)
+		}
+		checkpoint = types.DownloadCheckpoint{
+			ID:       backfillID(*symbol, *interval, *fromFlag, *toFlag, *out),
+			Symbol:   *symbol,
+			Interval: *interval,
+			Staging:  *out + ".partial.ndjson",
+		}
+		if saved, ok := checkpoints.Get(checkpoint.ID); ok && !*restart {
+			staged, err := candlelog.Load(saved.Staging, *symbol)
+			if err != nil {
+				log.Fatal(err)
+			}
+			// Candles staged after the checkpoint was saved are fetched again
+			for _, bar := range staged.Data {
+				if bar.Timestamp.Before(saved.Next) {
+					timeseries.AddPrice(bts, bar)
+				}
+			}
+			checkpoint = saved
+			if saved.Next.After(from) {
+				from = saved.Next
+			}
+			fmt.Printf("⏯️  Resuming from checkpoint: %d candles in %d chunks, next chunk at %s\n",
+				len(bts.Data), saved.Chunks, saved.Next.Format("2006-01-02 15:04"))
+		} else if err := os.Remove(checkpoint.Staging); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatalf("Failed to remove staged candles of an earlier backfill: %v", err)
+		}
+		if staging, err = candlelog.Open(candlelog.Config{Path: checkpoint.Staging}); err != nil {
+			log.Fatal(err)
+		}
+		defer staging.Close()
+	}
+
+	first, err := dataloader.FirstKlineFromBinance(*symbol, *interval, from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if first.IsZero() || !first.Before(to) {
+		if len(bts.Data) == 0 {
+			fmt.Printf("✅ No %s %s candles to backfill after %s\n", *symbol, *interval, from.Format("2006-01-02 15:04"))
+			return
+		}
+		first = to
+	} else if first.Sub(from) >= step {
+		fmt.Printf("ℹ️  Binance history of %s starts at %s\n", *symbol, first.Format("2006-01-02 15:04"))
+	}
+
+	err = backfillChunks(bts, *symbol, *interval, step, first, to, *retries, func(closed []types.BTCPrice, next time.Time) error {
+		if candles != nil {
+			if _, err := candles.Append(closed); err != nil {
+				return err
+			}
+		}
+		if checkpoints == nil {
+			return nil
+		}
+		if _, err := staging.Append(closed); err != nil {
+			return err
+		}
+		checkpoint.Next = next
+		checkpoint.Chunks++
+		checkpoint.Candles = len(bts.Data)
+		checkpoint.UpdatedAt = time.Now()
+		return checkpoints.Put(checkpoint)
+	})
+	if err != nil {
+		if candles != nil || checkpoints != nil {
+			log.Fatalf("%v; rerun to resume from the last complete chunk", err)
+		}
+		log.Fatal(err)
+	}
+	if len(bts.Data) == 0 {
+		fmt.Println("✅ No closed candles to backfill yet")
+		return
+	}
+	fmt.Printf("🧵 Stitched %d candles from %s to %s\n", len(bts.Data),
+		bts.Data[0].Timestamp.Format("2006-01-02 15:04"), bts.Data[len(bts.Data)-1].Timestamp.Format("2006-01-02 15:04"))
+
+	gaps := timeseries.Gaps(bts, step)
+	// A candle log that already had history must continue without a hole too
+	if !stored.IsZero() && bts.Data[0].Timestamp.Sub(stored) > step {
+		seam := bts.Data[0].Timestamp.Sub(stored)
+		gaps = append([]timeseries.Gap{{After: stored, Before: bts.Data[0].Timestamp, Missing: int((seam - 1) / step)}}, gaps...)
+	}
+	reportContinuity(gaps)
+	if issues := dataloader.ValidateData(bts); len(issues) > 0 {
+		fmt.Printf("⚠️  %d validation issues, first: %s\n", len(issues), issues[0])
+	}
+
+	if *out != "" {
+		format := strings.TrimPrefix(filepath.Ext(*out), ".")
+		if err := dataloader.SaveSeries(bts, *out, format); err != nil {
+			log.Fatalf("Failed to save backfill: %v", err)
+		}
+		fmt.Printf("💾 Backfill saved: %s\n", *out)
+	}
+	if checkpoints != nil {
+		staging.Close()
+		if err := os.Remove(checkpoint.Staging); err != nil {
+			log.Printf("⚠️  Failed to remove staged candles: %v", err)
+		}
+		if err := checkpoints.Delete(checkpoint.ID); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	}
+	if candles != nil {
+		fmt.Printf("💾 Candle log: %s\n", logCfg.Path)
+	}
+}
+
+// backfillID identifies a backfill by its symbol, interval, -from and -to
+// flags and output file, so only the same backfill resumes a checkpoint
+func backfillID(symbol, interval, from, to, out string) string {
+	if to == "" {
+		to = "now"
+	}
+	return fmt.Sprintf("%s %s %s..%s %s", symbol, interval, from, to, out)
+}
+
+// backfillChunks fetches [start, end) into bts in chunks of
+// MaxKlinesPerRequest candles, keeping only closed candles after the last
+// one in bts. A failed chunk is retried up to retries times, waiting twice as
+// long before each retry. The closed candles of each complete chunk are
+// handed to done with the start of the next chunk.
+func backfillChunks(bts *types.BTCTimeSeries, symbol, interval string, step time.Duration, start, end time.Time, retries int, done func(closed []types.BTCPrice, next time.Time) error) error {
+	chunk := step * dataloader.MaxKlinesPerRequest
+	total := int((end.Sub(start) + chunk - 1) / chunk)
+	now := time.Now()
+
+	for i, cursor := 1, start; cursor.Before(end); i, cursor = i+1, cursor.Add(chunk) {
+		chunkEnd := cursor.Add(chunk)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		// endTime is inclusive, so stop short of the next chunk's first candle
+		var page *types.BTCTimeSeries
+		var err error
+		for attempt := 0; ; attempt++ {
+			page, err = dataloader.LoadKlinesFromBinance(symbol, interval, cursor, chunkEnd.Add(-time.Millisecond))
+			if err == nil || attempt == retries {
+				break
+			}
+			wait := backfillRetryDelay << attempt
+			fmt.Printf("⚠️  [%d/%d] %v; retry %d of %d in %s\n", i, total, err, attempt+1, retries, wait)
+			time.Sleep(wait)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to backfill %s to %s: %w", cursor.Format("2006-01-02 15:04"), chunkEnd.Format("2006-01-02 15:04"), err)
+		}
+
+		var closed []types.BTCPrice
+		for _, bar := range page.Data {
+			if bar.Timestamp.Add(step).After(now) {
+				continue
+			}
+			if n := len(bts.Data); n > 0 && !bar.Timestamp.After(bts.Data[n-1].Timestamp) {
+				continue
+			}
+			timeseries.AddPrice(bts, bar)
+			closed = append(closed, bar)
+		}
+		if err := done(closed, chunkEnd); err != nil {
+			return err
+		}
+		fmt.Printf("📥 [%d/%d] %s → %s: %d candles\n", i, total,
+			cursor.Format("2006-01-02 15:04"), chunkEnd.Format("2006-01-02 15:04"), len(closed))
+	}
+	return nil
+}
+
+// reportContinuity prints the gaps of a backfill. Binance has a few known
+// outages, so gaps are reported but do not fail the backfill.
+func reportContinuity(gaps []timeseries.Gap) {
+	if len(gaps) == 0 {
+		fmt.Println("✅ Continuity verified: no missing candles")
+		return
+	}
+	missing := 0
+	for _, gap := range gaps {
+		missing += gap.Missing
+	}
+	fmt.Printf("⚠️  %d gaps, %d candles missing:\n", len(gaps), missing)
+	for i, gap := range gaps {
+		if i == maxGapsPrinted {
+			fmt.Printf("  ... and %d more\n", len(gaps)-maxGapsPrinted)
+			break
+		}
+		fmt.Printf("  %s → %s (%d missing)\n", gap.After.Format("2006-01-02 15:04"), gap.Before.Format("2006-01-02 15:04"), gap.Missing)
+	}
+}
@@ -0,0 +1,731 @@
+package main
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/backtest"
+	"btc-analyzer/internal/comparison"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/plugins"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/store"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/version"
+	"btc-analyzer/internal/visualizer"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backtestSorts maps the -sort names of backtest list to the metric they
+// rank by, higher first
+var backtestSorts = map[string]func(run types.BacktestRun) float64{
+	"return":   func(run types.BacktestRun) float64 { return run.Metrics.TotalReturn },
+	"cagr":     runCAGR,
+	"annual":   func(run types.BacktestRun) float64 { return run.Metrics.AnnualizedReturn },
+	"sharpe":   func(run types.BacktestRun) float64 { return run.Metrics.SharpeRatio },
+	"drawdown": func(run types.BacktestRun) float64 { return -run.Metrics.MaxDrawdown },
+	"winrate":  func(run types.BacktestRun) float64 { return run.Metrics.WinRate },
+}
+
+// runCAGR returns the compound annual growth rate of a run over its period
+func runCAGR(run types.BacktestRun) float64 {
+	return backtest.CAGR(run.Metrics.TotalReturn, run.Start, run.End)
+}
+
+// runBacktestCommand backtests buy-and-hold and every custom indicator with
+// trading rules on the loaded series and stores the runs, or lists and
+// compares stored runs
+func runBacktestCommand(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+			runBacktestList(args[1:])
+			return
+		case "compare":
+			runBacktestCompare(args[1:])
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	cfg := registerRunFlags(fs)
+	storePath := fs.String("store", "backtests.json", "Backtest run store file")
+	vcfg := backtest.DefaultValidationConfig()
+	fs.Float64Var(&vcfg.TrainFraction, "train", vcfg.TrainFraction, "Leading share of the period used as the in-sample part of the train/test split")
+	fs.IntVar(&vcfg.Folds, "folds", vcfg.Folds, "Purged k-fold splits (0 = none)")
+	fs.IntVar(&vcfg.Purge, "purge", vcfg.Purge, "Bars dropped from training on each side of a test fold")
+	fs.IntVar(&vcfg.PBOBlocks, "pbo-blocks", vcfg.PBOBlocks, "Even number of blocks for the probability of backtest overfitting")
+	var costs types.BacktestCosts
+	fs.Float64Var(&costs.FeeBps, "fee-bps", 0, "Trading fee per change of position, in basis points")
+	fs.Float64Var(&costs.SlippageBps, "slippage-bps", 0, "Slippage per change of position, in basis points")
+	mcSims := fs.Int("mc-sims", 1000, "Monte Carlo resamplings of each strategy's trades (0 = none)")
+	mcSeed := fs.Int64("mc-seed", 1, "Seed of the Monte Carlo resampling")
+	ruin := fs.Float64("ruin", 0.5, "Drawdown that counts as ruin in the Monte Carlo risk of ruin")
+	sharpeWindow := fs.Int("sharpe-window", 90, "Returns per rolling Sharpe ratio in the backtest report")
+	replay := fs.Bool("replay", false, "Also save an animated GIF replay of each rule strategy's trades")
+	replayFrames := fs.Int("replay-frames", 60, "Frames of each replay")
+	replayDelay := fs.Duration("replay-delay", 100*time.Millisecond, "Time each replay frame is shown")
+	sweepSpec := fs.String("cost-sweep", "0,5,10,15,20,25,30,40,50", "Costs per trade in basis points for the fee and slippage sensitivity sweep (empty = none)")
+	fs.Parse(args)
+	if err := validateRunConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
+	if vcfg.TrainFraction <= 0 || vcfg.TrainFraction >= 1 {
+		log.Fatalf("invalid -train %g: use a fraction between 0 and 1", vcfg.TrainFraction)
+	}
+	if vcfg.Folds < 0 || vcfg.Purge < 0 {
+		log.Fatal("-folds and -purge must not be negative")
+	}
+	if vcfg.PBOBlocks < 2 || vcfg.PBOBlocks%2 != 0 {
+		log.Fatalf("invalid -pbo-blocks %d: use an even number of at least 2", vcfg.PBOBlocks)
+	}
+	if costs.FeeBps < 0 || costs.SlippageBps < 0 {
+		log.Fatal("-fee-bps and -slippage-bps must not be negative")
+	}
+	if *sharpeWindow < 2 {
+		log.Fatalf("invalid -sharpe-window %d: use at least 2 returns", *sharpeWindow)
+	}
+	if *replayFrames < 1 || *replayDelay <= 0 {
+		log.Fatal("-replay-frames and -replay-delay must be positive")
+	}
+	if *mcSims < 0 || *ruin <= 0 || *ruin > 1 {
+		log.Fatal("-mc-sims must not be negative and -ruin must be a drawdown between 0 and 1")
+	}
+	sweep, err := parseCostSweep(*sweepSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	configureCache(cfg)
+
+	runs, err := store.OpenRuns(*storePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	inputs, err := loadInputs(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bts := inputs.Series
+	if len(bts.Data) < 2 {
+		log.Fatal("Backtesting needs at least 2 bars")
+	}
+
+	strategies, err := backtestStrategies(inputs, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rc := statistics.ResolveRiskConfig(bts, riskConfig(cfg))
+	meanReversion := analyzer.DescribeMeanReversion(statistics.FitOULogPrices(timeseries.GetClosePrices(bts), float64(rc.PeriodsPerYear)/365), bts.Denomination)
+
+	// Every strategy of the run is a trial for the overfitting diagnostics,
+	// which like the metrics are net of costs
+	gross := make([][]float64, len(strategies))
+	returns := make([][]float64, len(strategies))
+	sharpes := make([]float64, len(strategies))
+	for i, strategy := range strategies {
+		gross[i] = strategy.Returns
+		if gross[i] == nil {
+			gross[i] = backtest.Returns(bts, strategy.Positions)
+		}
+		returns[i] = backtest.NetReturns(gross[i], strategy.Positions, costs.FeeBps+costs.SlippageBps)
+		sharpes[i] = backtest.PeriodSharpe(returns[i], rc)
+	}
+	trialStdDev := statistics.Calculate(sharpes).StdDev
+	pbo, pboSplits := backtest.ProbabilityOfOverfitting(returns, vcfg.PBOBlocks, rc)
+
+	rng := rand.New(rand.NewSource(*mcSeed))
+	var stored []types.BacktestRun
+	simulations := make(map[string][2][]float64)
+	for i, strategy := range strategies {
+		validation := backtest.Validate(bts, returns[i], strategy.Positions, vcfg, rc)
+		validation.Trials = len(strategies)
+		validation.DeflatedSharpe = backtest.DeflatedSharpe(returns[i], len(strategies), trialStdDev*trialStdDev, rc)
+		validation.PBO, validation.PBOSplits = pbo, pboSplits
+
+		var monteCarlo *types.MonteCarloSummary
+		if trades := backtest.TradeReturns(returns[i], strategy.Positions); *mcSims > 0 && len(trades) >= backtest.MinMonteCarloTrades {
+			finals, drawdowns := backtest.MonteCarlo(trades, *mcSims, rng)
+			summary := backtest.SummarizeMonteCarlo(finals, drawdowns, len(trades), *ruin)
+			monteCarlo = &summary
+			simulations[strategy.Name] = [2][]float64{finals, drawdowns}
+		}
+
+		run, err := runs.Add(types.BacktestRun{
+			Version:      version.String(),
+			Strategy:     strategy.Name,
+			Params:       strategy.Params,
+			Source:       cfg.Source,
+			Symbol:       bts.Symbol,
+			Start:        bts.Data[0].Timestamp,
+			End:          bts.Data[len(bts.Data)-1].Timestamp,
+			Bars:         len(bts.Data),
+			Risk:         rc,
+			Costs:        costs,
+			Metrics:      backtest.Evaluate(returns[i], strategy.Positions, rc),
+			CostSweep:    backtest.CostSensitivity(gross[i], strategy.Positions, sweep, rc),
+			BreakEvenBps: backtest.BreakEvenCost(gross[i], strategy.Positions),
+			Validation:   &validation,
+			MonteCarlo:   monteCarlo,
+		})
+		if err != nil {
+			log.Fatalf("Failed to store backtest run: %v", err)
+		}
+		stored = append(stored, run)
+	}
+
+	fmt.Printf("\n🧪 Backtested %d strategies over %s to %s (%d bars)\n", len(stored),
+		bts.Data[0].Timestamp.Format("2006-01-02"), bts.Data[len(bts.Data)-1].Timestamp.Format("2006-01-02"), len(bts.Data))
+	if costs.FeeBps+costs.SlippageBps > 0 {
+		fmt.Printf("Metrics are net of %.1f bps fees and %.1f bps slippage per trade\n", costs.FeeBps, costs.SlippageBps)
+	}
+	printBacktestRuns(stored)
+	printBacktestValidation(stored)
+	printCostSweep(stored)
+	printMonteCarlo(stored)
+	printMeanReversion(meanReversion)
+	fmt.Printf("💾 Runs saved to %s\n", *storePath)
+
+	if cfg.HTMLReport {
+		if err := generateBacktestReport(bts, stored, returns, costs, meanReversion, *sharpeWindow, rc, cfg.OutputDir, cfg.Messages); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	}
+
+	if cfg.Chart && len(sweep) > 0 {
+		if err := generateCostSensitivityChart(stored, cfg.OutputDir); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	}
+	if cfg.Chart {
+		for _, strategy := range strategies {
+			// Spread trades are not trades of the price series
+			if strategy.Name == backtest.BuyAndHold || strategy.Returns != nil {
+				continue
+			}
+			if err := generateTradesChart(bts, strategy, priceChartConfig(cfg, "backtest_trades"), cfg.OutputDir); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+			if *replay {
+				if err := generateTradesReplay(bts, strategy, *replayFrames, *replayDelay, priceChartConfig(cfg, "backtest_trades"), cfg.OutputDir); err != nil {
+					log.Printf("⚠️  %v", err)
+				}
+			}
+		}
+		for _, run := range stored {
+			if sims, ok := simulations[run.Strategy]; ok {
+				if err := generateMonteCarloCharts(run, sims[0], sims[1], cfg.OutputDir); err != nil {
+					log.Printf("⚠️  %v", err)
+				}
+			}
+		}
+	}
+}
+
+// generateTradesReplay saves the animated replay of a strategy's trades
+func generateTradesReplay(bts *types.BTCTimeSeries, strategy backtest.Strategy, frames int, delay time.Duration, config visualizer.ChartConfig, outputDir string) error {
+	config.Title = fmt.Sprintf("%s: Trades Replay", strategy.Name)
+	config.XLabel = "Bar"
+	config.Width, config.Height = 800, 450
+
+	chartData, err := visualizer.DrawTradesReplay(bts, strategy.Positions, frames, delay, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate trades replay: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, fmt.Sprintf("replay_%s.gif", strategy.Name), chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save trades replay: %w", err)
+	}
+	fmt.Printf("✅ Trades replay saved: %s\n", chartPath)
+	return nil
+}
+
+// generateBacktestReport writes the backtest HTML report with the equity
+// curve of each stored run against buy-and-hold, its drawdowns, rolling
+// Sharpe ratio and monthly returns, after the mean-reversion lines of the
+// series. returns are the net returns of the runs in order, buy-and-hold
+// first. The report is written in the locale of msgs.
+func generateBacktestReport(bts *types.BTCTimeSeries, runs []types.BacktestRun, returns [][]float64, costs types.BacktestCosts, meanReversion []string, sharpeWindow int, rc types.RiskConfig, outputDir string, msgs *i18n.Catalog) error {
+	report := &reporter.BacktestReport{
+		Symbol:        bts.Symbol,
+		Denomination:  bts.Denomination,
+		Start:         bts.Data[0].Timestamp,
+		End:           bts.Data[len(bts.Data)-1].Timestamp,
+		Bars:          len(bts.Data),
+		Costs:         costs,
+		MeanReversion: meanReversion,
+	}
+	hold := backtest.Equity(returns[0])
+	for i, run := range runs {
+		section := reporter.BacktestStrategyReport{Run: run, CAGR: runCAGR(run), Monthly: backtest.MonthlyReturns(bts, returns[i])}
+
+		config := visualizer.DefaultChartConfig()
+		config.Title = run.Strategy + ": Equity vs Buy and Hold"
+		config.XLabel = "Bar"
+		config.YLabel = "Equity (start = 100)"
+		baseline := hold
+		if i == 0 {
+			config.Title, baseline = run.Strategy+": Equity", nil
+		}
+		equity := backtest.Equity(returns[i])
+		chartData, err := visualizer.DrawEquityChart(run.Strategy, equity, baseline, config)
+		if err != nil {
+			return fmt.Errorf("failed to generate equity chart: %w", err)
+		}
+		alt := msgs.T("alt.equity", run.Strategy, equity[len(equity)-1]*100, len(equity))
+		if baseline != nil {
+			alt += msgs.T("alt.equity.baseline", baseline[len(baseline)-1]*100)
+		}
+		section.Charts = append(section.Charts, reporter.BacktestChart{Title: msgs.T("backtest.chart.equity"), Alt: alt, PNG: chartData})
+
+		config.Title = run.Strategy + ": Drawdown from Peak"
+		config.YLabel = "Drawdown (%)"
+		drawdowns := backtest.Underwater(returns[i])
+		chartData, err = visualizer.DrawUnderwaterChart(drawdowns, config)
+		if err != nil {
+			return fmt.Errorf("failed to generate drawdown chart: %w", err)
+		}
+		alt = msgs.T("alt.drawdown", run.Strategy, numfmt.Pct(slices.Min(drawdowns)), numfmt.Pct(drawdowns[len(drawdowns)-1]))
+		section.Charts = append(section.Charts, reporter.BacktestChart{Title: msgs.T("backtest.chart.drawdown"), Alt: alt, PNG: chartData})
+
+		if sharpes := backtest.RollingSharpe(returns[i], sharpeWindow, rc); len(sharpes) > 0 {
+			config.Title = fmt.Sprintf("%s: Rolling %d-Bar Sharpe Ratio", run.Strategy, sharpeWindow)
+			config.YLabel = "Sharpe ratio (annualized)"
+			chartData, err = visualizer.DrawRollingSharpeChart(sharpes, len(bts.Data), config)
+			if err != nil {
+				return fmt.Errorf("failed to generate rolling Sharpe chart: %w", err)
+			}
+			alt = msgs.T("alt.rolling_sharpe", run.Strategy, sharpeWindow, slices.Min(sharpes), slices.Max(sharpes), sharpes[len(sharpes)-1])
+			section.Charts = append(section.Charts, reporter.BacktestChart{Title: msgs.T("backtest.chart.rolling_sharpe", sharpeWindow), Alt: alt, PNG: chartData})
+		}
+		report.Strategies = append(report.Strategies, section)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	reportPath := filepath.Join(outputDir, "backtest_report.html")
+	if err := reporter.GenerateBacktestReport(report, reportPath, msgs); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Backtest report saved: %s\n", reportPath)
+	return nil
+}
+
+// printMonteCarlo prints the distribution of resampled trade sequences of
+// each run that has one
+func printMonteCarlo(runs []types.BacktestRun) {
+	header := false
+	for _, run := range runs {
+		mc := run.MonteCarlo
+		if mc == nil {
+			continue
+		}
+		if !header {
+			fmt.Printf("\n🎲 Monte Carlo: %d resampled sequences of each strategy's trades (ruin = %.0f%% drawdown)\n", mc.Simulations, mc.RuinDrawdown*100)
+			fmt.Printf("%-20s %7s %10s %10s %10s %8s %9s %9s %8s\n",
+				"Strategy", "Trades", "Return P5", "Median", "P95", "P(loss)", "MedianDD", "DD P95", "Ruin")
+			header = true
+		}
+		fmt.Printf("%-20s %7d %10s %10s %10s %8s %9s %9s %8s\n", run.Strategy, mc.Trades,
+			numfmt.Pct(mc.FinalReturnP5), numfmt.Pct(mc.FinalReturnMedian), numfmt.Pct(mc.FinalReturnP95), numfmt.Pct(mc.ProbabilityOfLoss),
+			numfmt.Pct(mc.DrawdownMedian), numfmt.Pct(mc.DrawdownP95), numfmt.Pct(mc.RiskOfRuin))
+	}
+	if header {
+		fmt.Printf("Strategies with fewer than %d trades are not resampled.\n", backtest.MinMonteCarloTrades)
+	}
+}
+
+// printMeanReversion prints the mean-reversion half-life of the series and
+// the lookbacks it suggests for mean-reversion rules
+func printMeanReversion(lines []string) {
+	fmt.Printf("\n🔁 Mean reversion of the series (Ornstein-Uhlenbeck fit)\n")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// generateMonteCarloCharts saves histograms of the simulated final returns
+// and drawdowns of a run, marking the backtest's own result
+func generateMonteCarloCharts(run types.BacktestRun, finals, drawdowns []float64, outputDir string) error {
+	charts := []struct {
+		file, title, label string
+		values             []float64
+		actual             float64
+	}{
+		{"returns", "Final Return", "Final return (%)", finals, run.Metrics.TotalReturn},
+		{"drawdowns", "Maximum Drawdown", "Maximum drawdown (%)", drawdowns, run.Metrics.MaxDrawdown},
+	}
+	for _, chart := range charts {
+		config := visualizer.DefaultChartConfig()
+		config.Title = fmt.Sprintf("%s: Monte Carlo %s", run.Strategy, chart.title)
+		config.XLabel = chart.label
+		config.YLabel = "Simulations"
+
+		percent := make([]float64, len(chart.values))
+		for i, v := range chart.values {
+			percent[i] = v * 100
+		}
+		chartData, err := visualizer.DrawHistogram(percent, 40, chart.actual*100, "Backtest", config)
+		if err != nil {
+			return fmt.Errorf("failed to generate Monte Carlo chart: %w", err)
+		}
+
+		chartPath, err := saveChartFile(outputDir, fmt.Sprintf("montecarlo_%s_%s.png", run.Strategy, chart.file), chartData)
+		if err != nil {
+			return fmt.Errorf("failed to save Monte Carlo chart: %w", err)
+		}
+		fmt.Printf("✅ Monte Carlo chart saved: %s\n", chartPath)
+	}
+	return nil
+}
+
+// generateTradesChart saves the price chart of a strategy with its holding
+// periods shaded
+func generateTradesChart(bts *types.BTCTimeSeries, strategy backtest.Strategy, config visualizer.ChartConfig, outputDir string) error {
+	config.Title = fmt.Sprintf("%s: Trades", strategy.Name)
+	config.XLabel = "Bar"
+
+	chartData, err := visualizer.DrawTradesChart(bts, strategy.Positions, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate trades chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, fmt.Sprintf("backtest_trades_%s.png", strategy.Name), chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save trades chart: %w", err)
+	}
+	fmt.Printf("✅ Trades chart saved: %s\n", chartPath)
+	return nil
+}
+
+// parseCostSweep parses a comma-separated list of costs in basis points
+func parseCostSweep(spec string) ([]float64, error) {
+	var costs []float64
+	for _, item := range splitList(spec) {
+		cost, err := strconv.ParseFloat(item, 64)
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("invalid cost %q in -cost-sweep: use basis points of at least 0", item)
+		}
+		costs = append(costs, cost)
+	}
+	sort.Float64s(costs)
+	return costs, nil
+}
+
+// printCostSweep prints the net total return of each run at every cost of
+// the sweep and the cost at which it breaks even
+func printCostSweep(runs []types.BacktestRun) {
+	if len(runs) == 0 || len(runs[0].CostSweep) == 0 {
+		return
+	}
+	fmt.Printf("\n💸 Net total return by cost per trade (fees + slippage)\n%-20s", "Strategy")
+	for _, cp := range runs[0].CostSweep {
+		fmt.Printf(" %8s", fmt.Sprintf("%gbps", cp.CostBps))
+	}
+	fmt.Printf(" %11s\n", "Break-even")
+	for _, run := range runs {
+		fmt.Printf("%-20s", run.Strategy)
+		for _, cp := range run.CostSweep {
+			fmt.Printf(" %8s", numfmt.Pct(cp.TotalReturn))
+		}
+		fmt.Printf(" %11s\n", describeBreakEven(run))
+	}
+}
+
+// describeBreakEven describes the cost per trade at which a run breaks even
+func describeBreakEven(run types.BacktestRun) string {
+	if run.Metrics.Trades == 0 {
+		return "no trades"
+	}
+	if run.BreakEvenBps == 0 {
+		return "loses gross"
+	}
+	return fmt.Sprintf("%.1fbps", run.BreakEvenBps)
+}
+
+// generateCostSensitivityChart saves the chart of net return against costs
+func generateCostSensitivityChart(runs []types.BacktestRun, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = "Net Return vs Trading Costs"
+	config.XLabel = "Cost per trade (bps)"
+	config.YLabel = "Net total return (%)"
+
+	chartData, err := visualizer.DrawCostSensitivityChart(runs, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate cost sensitivity chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "backtest_costs.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save cost sensitivity chart: %w", err)
+	}
+
+	fmt.Printf("✅ Cost sensitivity chart saved: %s\n", chartPath)
+	return nil
+}
+
+// printBacktestValidation prints the generalization assessment of runs
+// tested together
+func printBacktestValidation(runs []types.BacktestRun) {
+	if len(runs) == 0 || runs[0].Validation == nil {
+		return
+	}
+	v := runs[0].Validation
+	fmt.Printf("\n📏 Out-of-sample check (first %.0f%% in sample, %d folds purged by %d bars, %d trials)\n",
+		v.TrainFraction*100, len(v.Folds), v.Purge, v.Trials)
+	fmt.Printf("%-20s %10s %10s %10s %11s %10s\n", "Strategy", "IS Sharpe", "OOS Sharpe", "OOS Return", "Fold Sharpe", "Deflated")
+	for _, run := range runs {
+		v := run.Validation
+		fmt.Printf("%-20s %10.3f %10.3f %10s %11.3f %10s\n", run.Strategy, v.InSample.SharpeRatio,
+			v.OutOfSample.SharpeRatio, numfmt.Pct(v.OutOfSample.TotalReturn), v.MeanTestSharpe, numfmt.Pct(v.DeflatedSharpe))
+	}
+	if v.PBOSplits > 0 {
+		fmt.Printf("Probability of backtest overfitting: %s (the in-sample best strategy ranks in the bottom half out of sample in %d of %d splits)\n",
+			numfmt.Pct(v.PBO), int(math.Round(v.PBO*float64(v.PBOSplits))), v.PBOSplits)
+	}
+	fmt.Println("Deflated: probability the Sharpe ratio is real after testing every strategy above; below 95% the edge may be luck.")
+}
+
+// backtestStrategies returns buy-and-hold and a strategy for each custom
+// indicator with trading rules
+func backtestStrategies(inputs *runInputs, cfg *runConfig) ([]backtest.Strategy, error) {
+	bars := len(inputs.Series.Data)
+	strategies := []backtest.Strategy{{Name: backtest.BuyAndHold, Positions: backtest.HoldPositions(bars)}}
+	if inputs.Pair != nil {
+		if pa := comparison.AnalyzePair(inputs.Series, inputs.Pair, cfg.Pair, pairConfig(cfg)); pa != nil {
+			positions, returns := comparison.PairStrategy(inputs.Series, inputs.Pair, pa)
+			strategies = append(strategies, backtest.Strategy{
+				Name: backtest.PairsTrade,
+				Params: map[string]string{
+					"pair":        cfg.Pair,
+					"hedge_ratio": strconv.FormatFloat(pa.HedgeRatio, 'f', 4, 64),
+					"window":      strconv.Itoa(pa.Config.Window),
+					"entry_z":     strconv.FormatFloat(pa.Config.EntryZ, 'g', -1, 64),
+					"exit_z":      strconv.FormatFloat(pa.Config.ExitZ, 'g', -1, 64),
+				},
+				Positions: positions,
+				Returns:   returns,
+			})
+		} else {
+			fmt.Printf("⚠️  Too few aligned bars with %s for a pairs strategy (need %d)\n", cfg.Pair, comparison.MinPairBars)
+		}
+	}
+	if len(inputs.Indicators) == 0 {
+		return strategies, nil
+	}
+
+	definitions := make(map[string]plugins.Definition)
+	for _, ind := range inputs.Indicators {
+		if script, ok := ind.(*plugins.Script); ok {
+			definitions[ind.Name()] = script.Definition()
+		}
+	}
+	// rules warm up on the bars before the period but only trade within it
+	custom, err := plugins.Evaluate(analyzer.WithWarmup(inputs.Series, inputs.Warmup), inputs.Indicators, splitList(cfg.Indicators))
+	custom = plugins.DropBars(custom, len(inputs.Warmup))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate custom indicators: %w", err)
+	}
+	for _, ci := range custom {
+		def, isScript := definitions[ci.Name]
+		if isScript && def.Buy == "" {
+			continue
+		}
+		if !isScript && len(ci.Buys) == 0 {
+			continue
+		}
+		params := map[string]string{"source": ci.Source}
+		if isScript {
+			params = map[string]string{"formula": def.Formula, "buy": def.Buy, "sell": def.Sell}
+		}
+		strategies = append(strategies, backtest.Strategy{
+			Name:      ci.Name,
+			Params:    params,
+			Positions: backtest.RulePositions(bars, ci.Buys, ci.Sells),
+		})
+	}
+	return strategies, nil
+}
+
+// runBacktestList prints stored runs ranked by a metric
+func runBacktestList(args []string) {
+	fs := flag.NewFlagSet("backtest list", flag.ExitOnError)
+	storePath := fs.String("store", "backtests.json", "Backtest run store file")
+	sortBy := fs.String("sort", "sharpe", "Metric to rank runs by: return, cagr, annual (mean return annualized), sharpe, drawdown or winrate")
+	strategy := fs.String("strategy", "", "Only list runs of this strategy")
+	limit := fs.Int("limit", 20, "Number of runs to print (0 = all)")
+	fs.Parse(args)
+
+	metric, ok := backtestSorts[*sortBy]
+	if !ok {
+		log.Fatalf("invalid sort %q: use return, cagr, annual, sharpe, drawdown or winrate", *sortBy)
+	}
+	runs, err := store.OpenRuns(*storePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var listed []types.BacktestRun
+	for _, run := range runs.List() {
+		if *strategy == "" || run.Strategy == *strategy {
+			listed = append(listed, run)
+		}
+	}
+	if len(listed) == 0 {
+		fmt.Println("No backtest runs stored")
+		return
+	}
+	sort.SliceStable(listed, func(i, j int) bool {
+		return metric(listed[i]) > metric(listed[j])
+	})
+	if *limit > 0 && len(listed) > *limit {
+		listed = listed[:*limit]
+	}
+
+	fmt.Printf("\n🏆 Backtest runs ranked by %s\n", *sortBy)
+	printBacktestRuns(listed)
+}
+
+// runBacktestCompare prints stored runs side by side, with the rank of each
+// run on every metric
+func runBacktestCompare(args []string) {
+	fs := flag.NewFlagSet("backtest compare", flag.ExitOnError)
+	storePath := fs.String("store", "backtests.json", "Backtest run store file")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		log.Fatal("usage: btc-analyzer backtest compare [flags] <run-id> <run-id> [run-id...]")
+	}
+	runs, err := store.OpenRuns(*storePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var compared []types.BacktestRun
+	for _, id := range fs.Args() {
+		run, ok := runs.Get(id)
+		if !ok {
+			log.Fatalf("Backtest run %s not found in %s", id, *storePath)
+		}
+		compared = append(compared, run)
+	}
+
+	row := func(label string, cell func(run types.BacktestRun) string) {
+		fmt.Printf("%-18s", label)
+		for _, run := range compared {
+			fmt.Printf(" %22s", cell(run))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	row("Run", func(run types.BacktestRun) string { return run.ID })
+	row("Strategy", func(run types.BacktestRun) string { return run.Strategy })
+	row("Period", func(run types.BacktestRun) string {
+		return run.Start.Format("2006-01-02") + ".." + run.End.Format("06-01-02")
+	})
+	row("Bars", func(run types.BacktestRun) string { return fmt.Sprintf("%d", run.Bars) })
+	row("Total return", func(run types.BacktestRun) string { return numfmt.Pct(run.Metrics.TotalReturn) })
+	row("CAGR", func(run types.BacktestRun) string { return numfmt.Pct(runCAGR(run)) })
+	row("Mean return (ann.)", func(run types.BacktestRun) string { return numfmt.Pct(run.Metrics.AnnualizedReturn) })
+	row("Volatility", func(run types.BacktestRun) string { return numfmt.Pct(run.Metrics.Volatility) })
+	row("Sharpe", func(run types.BacktestRun) string { return fmt.Sprintf("%.3f", run.Metrics.SharpeRatio) })
+	row("Max drawdown", func(run types.BacktestRun) string { return numfmt.Pct(run.Metrics.MaxDrawdown) })
+	row("Trades", func(run types.BacktestRun) string { return fmt.Sprintf("%d", run.Metrics.Trades) })
+	row("Win rate", func(run types.BacktestRun) string { return numfmt.Pct(run.Metrics.WinRate) })
+	row("Exposure", func(run types.BacktestRun) string { return numfmt.Pct(run.Metrics.Exposure) })
+	row("Costs", func(run types.BacktestRun) string {
+		return fmt.Sprintf("%gbps", run.Costs.FeeBps+run.Costs.SlippageBps)
+	})
+	row("Break-even cost", describeBreakEven)
+	row("MC median return", func(run types.BacktestRun) string {
+		if run.MonteCarlo == nil {
+			return "-"
+		}
+		return numfmt.Pct(run.MonteCarlo.FinalReturnMedian)
+	})
+	row("MC risk of ruin", func(run types.BacktestRun) string {
+		if run.MonteCarlo == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%s at %.0f%%", numfmt.Pct(run.MonteCarlo.RiskOfRuin), run.MonteCarlo.RuinDrawdown*100)
+	})
+	validated := func(cell func(v *types.BacktestValidation) string) func(run types.BacktestRun) string {
+		return func(run types.BacktestRun) string {
+			if run.Validation == nil {
+				return "-"
+			}
+			return cell(run.Validation)
+		}
+	}
+	row("OOS Sharpe", validated(func(v *types.BacktestValidation) string { return fmt.Sprintf("%.3f", v.OutOfSample.SharpeRatio) }))
+	row("OOS return", validated(func(v *types.BacktestValidation) string {
+		return numfmt.Pct(v.OutOfSample.TotalReturn)
+	}))
+	row("Fold Sharpe", validated(func(v *types.BacktestValidation) string { return fmt.Sprintf("%.3f", v.MeanTestSharpe) }))
+	row("Deflated Sharpe", validated(func(v *types.BacktestValidation) string {
+		return fmt.Sprintf("%s of %d", numfmt.Pct(v.DeflatedSharpe), v.Trials)
+	}))
+	row("PBO", validated(func(v *types.BacktestValidation) string {
+		if v.PBOSplits == 0 {
+			return "-"
+		}
+		return numfmt.Pct(v.PBO)
+	}))
+
+	names := make([]string, 0, len(backtestSorts))
+	for name := range backtestSorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("\nRanks (1 = best):")
+	for _, name := range names {
+		metric := backtestSorts[name]
+		row(name, func(run types.BacktestRun) string {
+			rank := 1
+			for _, other := range compared {
+				if metric(other) > metric(run) {
+					rank++
+				}
+			}
+			return fmt.Sprintf("%d", rank)
+		})
+	}
+
+	for _, run := range compared {
+		if len(run.Params) == 0 {
+			continue
+		}
+		var params []string
+		for key, value := range run.Params {
+			params = append(params, key+"="+value)
+		}
+		sort.Strings(params)
+		fmt.Printf("\n%s (%s): %s", run.ID, run.Strategy, strings.Join(params, ", "))
+	}
+	fmt.Println()
+}
+
+// printBacktestRuns prints one line of metrics per run
+func printBacktestRuns(runs []types.BacktestRun) {
+	fmt.Printf("%-16s %-20s %-23s %10s %10s %8s %9s %6s %7s\n",
+		"ID", "Strategy", "Period", "Return", "CAGR", "Sharpe", "MaxDD", "Trades", "WinRate")
+	for _, run := range runs {
+		fmt.Printf("%-16s %-20s %-23s %10s %10s %8.3f %9s %6d %7s\n",
+			run.ID, run.Strategy, run.Start.Format("2006-01-02")+".."+run.End.Format("2006-01-02"),
+			numfmt.Pct(run.Metrics.TotalReturn), numfmt.Pct(runCAGR(run)), run.Metrics.SharpeRatio,
+			numfmt.Pct(run.Metrics.MaxDrawdown), run.Metrics.Trades, numfmt.Pct(run.Metrics.WinRate))
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"btc-analyzer/internal/bundle"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/plugins"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bundle layout, relative to the bundle root
+const (
+	bundleConfigFile    = "config.json"
+	bundleSeriesFile    = "data/series.json"
+	bundleTrendsFile    = "data/trends.json"
+	bundleCPIFile       = "data/cpi.json"
+	bundleATHFile       = "data/ath.json"
+	bundleTradesFile    = "data/trades.json"
+	bundleOrderBookFile = "data/orderbook.json"
+	bundleReferenceFile = "data/reference.json"
+	bundleBenchmarkFile = "data/benchmark.json"
+	bundlePairFile      = "data/pair.json"
+	bundleOnChainFile   = "data/onchain.json"
+	bundleDominanceFile = "data/dominance.json"
+	bundleStableFile    = "data/stablecoins.json"
+	bundleDVOLFile      = "data/dvol.json"
+	bundleIVTermFile    = "data/iv_term.json"
+	bundleWarmupFile    = "data/warmup.json"
+	bundleIndicatorFile = "indicators.json"
+)
+
+// runBundleCommand runs an analysis and packages the raw data, config,
+// reports and charts into a single tarball
+func runBundleCommand(args []string) {
+	fs := flag.NewFlagSet("bundle", flag.ExitOnError)
+	cfg := registerRunFlags(fs)
+	archivePath := fs.String("out", "btc_analysis_bundle.tar.gz", "Bundle file to write")
+	fs.Parse(args)
+	if err := validateRunConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
+	configureCache(cfg)
+
+	inputs, err := loadInputs(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	staging, err := os.MkdirTemp("", "btc-analyzer-bundle-*")
+	if err != nil {
+		log.Fatalf("Failed to create staging directory: %v", err)
+	}
+	defer os.RemoveAll(staging)
+
+	runCfg := *cfg
+	runCfg.OutputDir = staging
+	result := runPipeline(inputs, &runCfg)
+
+	if err := saveBundleInputs(staging, inputs, cfg); err != nil {
+		log.Fatalf("Failed to save bundle inputs: %v", err)
+	}
+
+	if err := bundle.Create(*archivePath, staging); err != nil {
+		log.Fatalf("Failed to create bundle: %v", err)
+	}
+
+	fmt.Printf("📦 Analysis bundle written: %s\n", *archivePath)
+	exitOnFailure(result)
+}
+
+// runOpenBundleCommand extracts a bundle and regenerates its reports from the
+// bundled data without refetching anything
+func runOpenBundleCommand(args []string) {
+	fs := flag.NewFlagSet("open-bundle", flag.ExitOnError)
+	outputDir := fs.String("output", "", "Directory to extract into (default: bundle name without extension)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatal("usage: btc-analyzer open-bundle [-output dir] <bundle.tar.gz>")
+	}
+	archivePath := fs.Arg(0)
+
+	dir := *outputDir
+	if dir == "" {
+		dir = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(archivePath), ".gz"), ".tar")
+	}
+
+	fmt.Printf("📦 Opening analysis bundle: %s\n", archivePath)
+	if err := bundle.Extract(archivePath, dir); err != nil {
+		log.Fatalf("Failed to extract bundle: %v", err)
+	}
+
+	cfg, inputs, err := loadBundle(dir)
+	if err != nil {
+		log.Fatalf("Failed to load bundle: %v", err)
+	}
+	cfg.OutputDir = dir
+	// Reports keep the decimals of the run that created the bundle
+	if cfg.Precision != nil {
+		if err := numfmt.Configure(*cfg.Precision); err != nil {
+			log.Fatalf("Failed to load bundle: %v", err)
+		}
+	}
+
+	result := runPipeline(inputs, cfg)
+
+	fmt.Printf("🎉 Reports regenerated in %s\n", dir)
+	exitOnFailure(result)
+}
+
+// saveBundleInputs writes the run config and every loaded input into dir
+func saveBundleInputs(dir string, inputs *runInputs, cfg *runConfig) error {
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+		return err
+	}
+
+	bundledCfg := *cfg
+	bundledCfg.OutputDir = ""
+	precision := numfmt.Current()
+	bundledCfg.Precision = &precision
+	if err := writeJSONFile(filepath.Join(dir, bundleConfigFile), bundledCfg); err != nil {
+		return err
+	}
+
+	if err := dataloader.SaveToJSON(inputs.Series, filepath.Join(dir, bundleSeriesFile)); err != nil {
+		return err
+	}
+	if len(inputs.Indicators) > 0 {
+		var indicators bundledIndicators
+		for _, ind := range inputs.Indicators {
+			if script, ok := ind.(*plugins.Script); ok {
+				indicators.Scripts = append(indicators.Scripts, script.Definition())
+			} else {
+				indicators.Registered = append(indicators.Registered, ind.Name())
+			}
+		}
+		if err := writeJSONFile(filepath.Join(dir, bundleIndicatorFile), indicators); err != nil {
+			return err
+		}
+	}
+	if inputs.WarmupInfo != nil {
+		warmup := bundledWarmup{Info: *inputs.WarmupInfo, Bars: inputs.Warmup}
+		if err := writeJSONFile(filepath.Join(dir, bundleWarmupFile), warmup); err != nil {
+			return err
+		}
+	}
+	if inputs.Trends != nil {
+		if err := writeJSONFile(filepath.Join(dir, bundleTrendsFile), inputs.Trends); err != nil {
+			return err
+		}
+	}
+	if inputs.CPI != nil {
+		if err := writeJSONFile(filepath.Join(dir, bundleCPIFile), inputs.CPI); err != nil {
+			return err
+		}
+	}
+	if inputs.ATH != nil {
+		if err := writeJSONFile(filepath.Join(dir, bundleATHFile), inputs.ATH); err != nil {
+			return err
+		}
+	}
+	if len(inputs.OnChain) > 0 {
+		if err := writeJSONFile(filepath.Join(dir, bundleOnChainFile), inputs.OnChain); err != nil {
+			return err
+		}
+	}
+	if len(inputs.Trades) > 0 {
+		if err := writeJSONFile(filepath.Join(dir, bundleTradesFile), inputs.Trades); err != nil {
+			return err
+		}
+	}
+	if inputs.OrderBook != nil {
+		if err := writeJSONFile(filepath.Join(dir, bundleOrderBookFile), inputs.OrderBook); err != nil {
+			return err
+		}
+	}
+	if inputs.Dominance != nil {
+		if err := writeJSONFile(filepath.Join(dir, bundleDominanceFile), inputs.Dominance); err != nil {
+			return err
+		}
+	}
+	if inputs.Stablecoins != nil {
+		if err := writeJSONFile(filepath.Join(dir, bundleStableFile), inputs.Stablecoins); err != nil {
+			return err
+		}
+	}
+	if inputs.DVOL != nil {
+		if err := writeJSONFile(filepath.Join(dir, bundleDVOLFile), inputs.DVOL); err != nil {
+			return err
+		}
+	}
+	if len(inputs.IVTerm) > 0 {
+		if err := writeJSONFile(filepath.Join(dir, bundleIVTermFile), inputs.IVTerm); err != nil {
+			return err
+		}
+	}
+	if inputs.Reference != nil {
+		if err := dataloader.SaveToJSON(inputs.Reference, filepath.Join(dir, bundleReferenceFile)); err != nil {
+			return err
+		}
+	}
+	if inputs.Benchmark != nil {
+		if err := dataloader.SaveToJSON(inputs.Benchmark, filepath.Join(dir, bundleBenchmarkFile)); err != nil {
+			return err
+		}
+	}
+	if inputs.Pair != nil {
+		if err := dataloader.SaveToJSON(inputs.Pair, filepath.Join(dir, bundlePairFile)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bundledWarmup is the warm-up file of a bundle: the bars before the series
+// that warmed up the indicators and how they were chosen
+type bundledWarmup struct {
+	Info types.WarmupInfo `json:"info"`
+	Bars []types.BTCPrice `json:"bars"`
+}
+
+// bundledIndicators is the custom indicator file of a bundle: the script
+// indicators with their definitions, and the names of the Go indicators
+// compiled into the binary that created it
+type bundledIndicators struct {
+	Scripts    []plugins.Definition `json:"scripts,omitempty"`
+	Registered []string             `json:"registered,omitempty"`
+}
+
+// loadBundle reads the run config and inputs from an extracted bundle
+func loadBundle(dir string) (*runConfig, *runInputs, error) {
+	// Options added after the bundle was created keep their defaults
+	cfg := registerRunFlags(flag.NewFlagSet("bundle", flag.ContinueOnError))
+	if err := readJSONFile(filepath.Join(dir, bundleConfigFile), cfg); err != nil {
+		return nil, nil, err
+	}
+
+	series, err := dataloader.LoadFromJSON(filepath.Join(dir, bundleSeriesFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs := &runInputs{Series: series, Indicators: plugins.Registered()}
+
+	// The script indicators come from the bundle rather than -plugins, which
+	// may have changed since; compiled-in ones must be in this binary too
+	var indicators bundledIndicators
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleIndicatorFile), &indicators); err != nil {
+		return nil, nil, err
+	}
+	for _, def := range indicators.Scripts {
+		script, err := plugins.Compile(def)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bundled indicator %s: %w", def.Name, err)
+		}
+		inputs.Indicators = append(inputs.Indicators, script)
+	}
+	registered := make(map[string]bool)
+	for _, ind := range plugins.Registered() {
+		registered[ind.Name()] = true
+	}
+	for _, name := range indicators.Registered {
+		if !registered[name] {
+			fmt.Printf("⚠️  Indicator %s of the bundle is not compiled into this binary; its values and signal are left out\n", name)
+		}
+	}
+
+	var warmup bundledWarmup
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleWarmupFile), &warmup); err != nil {
+		return nil, nil, err
+	} else if warmup.Info.Policy != "" {
+		inputs.Warmup, inputs.WarmupInfo = warmup.Bars, &warmup.Info
+	}
+
+	var trends types.AuxSeries
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleTrendsFile), &trends); err != nil {
+		return nil, nil, err
+	} else if len(trends.Points) > 0 {
+		inputs.Trends = &trends
+	}
+
+	var cpi types.AuxSeries
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleCPIFile), &cpi); err != nil {
+		return nil, nil, err
+	} else if len(cpi.Points) > 0 {
+		inputs.CPI = &cpi
+	}
+
+	var ath types.AuxPoint
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleATHFile), &ath); err != nil {
+		return nil, nil, err
+	} else if ath.Value > 0 {
+		inputs.ATH = &ath
+	}
+
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleOnChainFile), &inputs.OnChain); err != nil {
+		return nil, nil, err
+	}
+
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleTradesFile), &inputs.Trades); err != nil {
+		return nil, nil, err
+	}
+
+	var book types.OrderBook
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleOrderBookFile), &book); err != nil {
+		return nil, nil, err
+	} else if len(book.Bids) > 0 || len(book.Asks) > 0 {
+		inputs.OrderBook = &book
+	}
+
+	var dominance, stablecoins types.AuxSeries
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleDominanceFile), &dominance); err != nil {
+		return nil, nil, err
+	} else if len(dominance.Points) > 0 {
+		inputs.Dominance = &dominance
+	}
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleStableFile), &stablecoins); err != nil {
+		return nil, nil, err
+	} else if len(stablecoins.Points) > 0 {
+		inputs.Stablecoins = &stablecoins
+	}
+
+	var dvol types.AuxSeries
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleDVOLFile), &dvol); err != nil {
+		return nil, nil, err
+	} else if len(dvol.Points) > 0 {
+		inputs.DVOL = &dvol
+	}
+	if err := readOptionalJSONFile(filepath.Join(dir, bundleIVTermFile), &inputs.IVTerm); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, bundleReferenceFile)); err == nil {
+		inputs.Reference, err = dataloader.LoadFromJSON(filepath.Join(dir, bundleReferenceFile))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, bundleBenchmarkFile)); err == nil {
+		inputs.Benchmark, err = dataloader.LoadFromJSON(filepath.Join(dir, bundleBenchmarkFile))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, bundlePairFile)); err == nil {
+		inputs.Pair, err = dataloader.LoadFromJSON(filepath.Join(dir, bundlePairFile))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return cfg, inputs, nil
+}
+
+// writeJSONFile encodes v as indented JSON into filename
+func writeJSONFile(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(filename), err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(filename), err)
+	}
+	return nil
+}
+
+// readJSONFile decodes the JSON in filename into v
+func readJSONFile(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(filename), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", filepath.Base(filename), err)
+	}
+	return nil
+}
+
+// readOptionalJSONFile is readJSONFile that ignores a missing file
+func readOptionalJSONFile(filename string, v interface{}) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+	return readJSONFile(filename, v)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// chunkedTail is the number of latest bars a chunked analysis keeps with
+// their indicator values for the reports
+const chunkedTail = 500
+
+// runChunkedAnalysis streams a CSV file through the incremental analyzer so
+// datasets larger than memory can be summarized, reporting progress as it goes
+func runChunkedAnalysis(cfg *runConfig) error {
+	if cfg.Source != "csv" || cfg.CSVFile == "" {
+		return fmt.Errorf("chunked mode requires -source=csv and -csv")
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	fmt.Printf("📄 Streaming CSV file in chunks of %d bars: %s\n", cfg.ChunkSize, cfg.CSVFile)
+	analysis := analyzer.NewStreamingAnalysis(dataloader.CSVSymbol, chunkedTail, riskConfig(cfg))
+	started := time.Now()
+
+	err := dataloader.StreamCSV(cfg.CSVFile, cfg.ChunkSize, func(chunk []types.BTCPrice) error {
+		analysis.AddChunk(chunk)
+		return nil
+	}, func(read, total int64) {
+		percent := 100.0
+		if total > 0 {
+			percent = float64(read) / float64(total) * 100
+		}
+		fmt.Printf("\r⏳ %5.1f%% (%d bars, %s elapsed)", percent, analysis.Bars(), time.Since(started).Round(time.Second))
+	})
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("chunked analysis failed: %w", err)
+	}
+
+	tail, analytics := analysis.Result()
+	result := analyzer.NewResult(tail, analytics, cfg.Signals)
+	// The result covers the whole file, its series only the tail kept
+	result.Metadata.DataPoints = analysis.Bars()
+	result.Metadata.Start = analysis.Start()
+	result.Metadata.ComputeSeconds = time.Since(started).Seconds()
+	result.Metadata.Parameters = &types.AnalysisParams{Source: cfg.Source, Risk: riskConfig(cfg), Signals: cfg.Signals}
+	result.Metadata.Warnings = append(result.Metadata.Warnings,
+		fmt.Sprintf("indicator series cover only the last %d of %d bars of %s", len(tail.Data), analysis.Bars(), cfg.CSVFile))
+	if analysis.OutOfOrder() > 0 {
+		warning := fmt.Sprintf("skipped %d out-of-order bars (chunked mode needs chronological input)", analysis.OutOfOrder())
+		fmt.Printf("⚠️  %s\n", warning)
+		result.Metadata.Warnings = append(result.Metadata.Warnings, warning)
+	}
+	reporter.PrintSummary(result, cfg.Messages)
+
+	if cfg.JSONReport {
+		jsonPath := filepath.Join(cfg.OutputDir, "btc_chunked_analysis.json")
+		if err := writeJSONFile(jsonPath, result); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Chunked analysis written: %s\n", jsonPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"btc-analyzer/internal/comparison"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/pool"
+	"btc-analyzer/internal/types"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runCompareCommand loads the same period from two or more sources, reports
+// how far they diverge bar by bar and recommends the cleanest one
+func runCompareCommand(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	days := fs.Int("days", 30, "Number of days to load from API sources")
+	symbol := fs.String("symbol", "BTCUSDT", "Binance symbol for the binance source")
+	interval := fs.String("interval", "1d", "Binance kline interval for the binance source")
+	outputDir := fs.String("output", ".", "Output directory for the comparison JSON")
+	top := fs.Int("top", 10, "Number of most divergent bars to print")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		log.Fatal("usage: btc-analyzer compare [flags] <source> <source> [source...]\n" +
+			"sources: api, binance, sample, csv:<file>, json:<file>")
+	}
+
+	names, series := loadComparisonSources(fs.Args(), *days, *symbol, *interval)
+
+	result := comparison.CompareSources(names, series)
+	if result.AlignedBars == 0 {
+		log.Fatal("Sources do not overlap in time")
+	}
+
+	fmt.Printf("\n🔍 Compared %d sources over %s to %s (%d aligned %s bars)\n",
+		len(names), result.Start.Format("2006-01-02 15:04"), result.End.Format("2006-01-02 15:04"),
+		result.AlignedBars, result.Interval)
+	fmt.Printf("%-24s %6s %8s %7s %10s %10s %8s %14s %7s\n",
+		"Source", "Bars", "Missing", "Issues", "MeanDiv", "MaxDiv", "VolRatio", "Benford", "Score")
+	for _, q := range result.Sources {
+		fmt.Printf("%-24s %6d %8d %7d %10s %10s %8.2f %14s %7.3f\n",
+			q.Source, q.Bars, q.MissingBars, q.ValidationIssues, numfmt.Pct(q.MeanDivergence),
+			numfmt.Pct(q.MaxDivergence), q.VolumeRatio, q.BenfordConformity, q.Score)
+	}
+
+	fmt.Printf("\n📐 Largest price divergences:\n")
+	for _, bar := range comparison.LargestDivergences(result, *top) {
+		var closes []string
+		for _, name := range names {
+			if price, ok := bar.Closes[name]; ok {
+				closes = append(closes, name+" "+denom.Price(nil, price))
+			} else {
+				closes = append(closes, fmt.Sprintf("%s missing", name))
+			}
+		}
+		fmt.Printf("  %s  spread %s  (%s)\n", bar.Timestamp.Format("2006-01-02 15:04"), numfmt.Pct(bar.Spread), strings.Join(closes, ", "))
+	}
+
+	fmt.Printf("\n✅ Recommended source: %s\n", result.Recommended)
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+	path := filepath.Join(*outputDir, "btc_source_comparison.json")
+	if err := writeJSONFile(path, result); err != nil {
+		log.Fatalf("Failed to save comparison: %v", err)
+	}
+	fmt.Printf("💾 Comparison saved: %s\n", path)
+}
+
+// loadComparisonSources loads the sources concurrently, printing each as it
+// finishes. A source that fails is left out of the comparison, which only
+// stops when fewer than two sources are left.
+func loadComparisonSources(specs []string, days int, symbol, interval string) ([]string, []*types.BTCTimeSeries) {
+	loaded := make([]*types.BTCTimeSeries, len(specs))
+	errs := pool.Run(len(specs), pool.DefaultWorkers, func(i int) error {
+		bts, err := loadComparisonSource(specs[i], days, symbol, interval)
+		if err == nil && len(bts.Data) == 0 {
+			err = fmt.Errorf("no data returned")
+		}
+		loaded[i] = bts
+		return err
+	}, func(p pool.Progress) {
+		if p.Err != nil {
+			fmt.Printf("❌ [%d/%d] %s: %v\n", p.Done, p.Total, specs[p.Index], p.Err)
+			return
+		}
+		fmt.Printf("📥 [%d/%d] %s: %d bars\n", p.Done, p.Total, specs[p.Index], len(loaded[p.Index].Data))
+	})
+
+	var names []string
+	var series []*types.BTCTimeSeries
+	for i, spec := range specs {
+		if errs[i] == nil {
+			names = append(names, spec)
+			series = append(series, loaded[i])
+		}
+	}
+	if len(names) < 2 {
+		log.Fatalf("Only %d of %d sources loaded; at least 2 are needed", len(names), len(specs))
+	}
+	if failed := pool.Failed(errs); failed > 0 {
+		fmt.Printf("⚠️  Comparing without %d failed source(s)\n", failed)
+	}
+	return names, series
+}
+
+// loadComparisonSource loads one source spec: api, binance, sample,
+// csv:<file> or json:<file>
+func loadComparisonSource(spec string, days int, symbol, interval string) (*types.BTCTimeSeries, error) {
+	kind, file, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "binance":
+		end := time.Now()
+		fmt.Printf("📡 Fetching %d days of %s %s klines from Binance...\n", days, symbol, interval)
+		bts, err := dataloader.LoadKlinesFromBinance(symbol, interval, end.AddDate(0, 0, -days), end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load data from Binance: %w", err)
+		}
+		return bts, nil
+	case "api", "sample", "csv", "json":
+		cfg := &runConfig{Source: kind, Days: days}
+		if kind == "csv" {
+			cfg.CSVFile = file
+		}
+		if kind == "json" {
+			cfg.JSONFile = file
+		}
+		return loadSeries(cfg)
+	}
+	return nil, fmt.Errorf("unknown source %q", spec)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"btc-analyzer/internal/snapshot"
+	"btc-analyzer/internal/types"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// diffFile is the machine-readable delta written by the diff command and by
+// the server after every re-analysis
+const diffFile = "btc_analysis_diff.json"
+
+// runDiffCommand compares two JSON reports and prints a changelog of what
+// changed from the older to the newer one
+func runDiffCommand(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	outputDir := fs.String("output", ".", "Output directory for the delta JSON")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		log.Fatal("usage: btc-analyzer diff [flags] <old_report.json> <new_report.json>")
+	}
+	older, err := snapshot.LoadReport(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	newer, err := snapshot.LoadReport(fs.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	diff := snapshot.Diff(older, newer)
+	printChangelog(diff)
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+	path := filepath.Join(*outputDir, diffFile)
+	if err := writeJSONFile(path, diff); err != nil {
+		log.Fatalf("Failed to save diff: %v", err)
+	}
+	fmt.Printf("💾 Diff saved: %s\n", path)
+}
+
+// printChangelog prints the changes of diff, one per line
+func printChangelog(diff types.SnapshotDiff) {
+	fmt.Printf("\n🔀 Changes from %s to %s:\n",
+		diff.OldGeneratedAt.Format("2006-01-02 15:04"), diff.NewGeneratedAt.Format("2006-01-02 15:04"))
+	if len(diff.Changelog) == 0 {
+		fmt.Println("  No changes")
+	}
+	for _, line := range diff.Changelog {
+		fmt.Printf("  • %s\n", line)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"btc-analyzer/internal/candlelog"
+	"btc-analyzer/internal/config"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/doctor"
+	"btc-analyzer/internal/execution"
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/notify"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/resultcache"
+	"btc-analyzer/internal/scheduler"
+	"btc-analyzer/internal/store"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// doctorEndpoint is a public API checked for reachability, without going
+// through the HTTP cache
+type doctorEndpoint struct {
+	name, url string
+}
+
+// doctorEndpoints are the APIs the analysis reads
+var doctorEndpoints = []doctorEndpoint{
+	{"CoinGecko", "https://api.coingecko.com/api/v3/ping"},
+	{"Binance", "https://api.binance.com/api/v3/ping"},
+	{"Kraken", "https://api.kraken.com/0/public/Time"},
+	{"Deribit", "https://www.deribit.com/api/v2/public/get_time"},
+}
+
+// runDoctorCommand checks the configuration, API reachability, clock skew,
+// caches, stores and output paths a run or server would use, prints a
+// diagnostic report and exits with 1 when a check failed
+func runDoctorCommand(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	cfg := registerRunFlags(fs)
+	configPath := fs.String("config", "", "JSON config file to validate")
+	watchlistsPath := fs.String("watchlists", "watchlists.json", "Watchlist store file (empty = skip)")
+	paperPath := fs.String("paper-store", "paper.json", "Paper trading account file (empty = skip)")
+	backtestPath := fs.String("backtest-store", "backtests.json", "Backtest run store file (empty = skip)")
+	candleLogPath := fs.String("candle-log", "", "Candle log to verify (empty = skip)")
+	publishTarget := registerPublishFlag(fs)
+	offline := fs.Bool("offline", false, "Skip the network checks")
+	timeout := fs.Duration("timeout", 10*time.Second, "Timeout of each network check")
+	fs.Parse(args)
+	if err := applyEnvFlags(fs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("🩺 btc-analyzer doctor")
+	report := &doctor.Report{}
+	appConfig := doctorConfig(report, cfg, *configPath, *publishTarget)
+	if !*offline {
+		doctorNetwork(report, cfg, appConfig, &http.Client{Timeout: *timeout})
+	}
+	doctorStorage(report, cfg, *watchlistsPath, *paperPath, *backtestPath, *candleLogPath)
+	doctorPaths(report, cfg, *watchlistsPath, *paperPath, *backtestPath, *candleLogPath)
+
+	report.Print(os.Stdout)
+	if report.Count(doctor.Fail) > 0 {
+		os.Exit(1)
+	}
+}
+
+// doctorConfig validates the flags, config file, publish target and the
+// credentials they need, and returns the config when it is valid
+func doctorConfig(report *doctor.Report, cfg *runConfig, configPath, publishTarget string) *config.Config {
+	const group = "Configuration"
+	report.Check(group, "Run flags", validateRunConfig(cfg), "valid")
+	if cfg.Source == "csv" || cfg.Source == "json" || cfg.Source == "ndjson" {
+		bts, err := loadSeries(cfg)
+		if err == nil && len(bts.Data) == 0 {
+			err = fmt.Errorf("no data")
+		}
+		detail := ""
+		if err == nil {
+			detail = fmt.Sprintf("%d bars, last %s", len(bts.Data), bts.Data[len(bts.Data)-1].Timestamp.Format("2006-01-02 15:04"))
+		}
+		report.Check(group, "Input data", err, detail)
+	}
+
+	appConfig, err := config.LoadWithEnv(configPath)
+	switch {
+	case err != nil:
+		report.Add(group, "Config", doctor.Fail, "%v", err)
+		return nil
+	case appConfig == nil:
+		report.Add(group, "Config", doctor.OK, "no config file or environment config")
+	default:
+		report.Add(group, "Config", doctor.OK, "%d jobs", len(appConfig.Jobs))
+		for _, job := range appConfig.Jobs {
+			if _, err := scheduler.ParseCron(job.Schedule); err != nil {
+				report.Add(group, "Job "+job.Name, doctor.Fail, "invalid schedule %q: %v", job.Schedule, err)
+			}
+		}
+		if appConfig.Precision != nil {
+			report.Check(group, "Precision", numfmt.Validate(*appConfig.Precision), "valid")
+		}
+	}
+
+	if publishTarget != "" {
+		publisher, err := parsePublishTarget(publishTarget)
+		detail := ""
+		if err == nil {
+			detail = publisher.String()
+		}
+		report.Check(group, "Publish target", err, detail)
+	}
+	if appConfig != nil && appConfig.Execution != nil {
+		ex := *appConfig.Execution
+		if ex.Live() {
+			_, err := execution.NewExchange(ex)
+			report.Check(group, "Execution credentials", err, fmt.Sprintf("LIVE orders on %s", ex.Exchange))
+		} else {
+			report.Add(group, "Execution", doctor.OK, "dry run on %s, no credentials needed", ex.Exchange)
+		}
+	}
+	if appConfig != nil && appConfig.Notify != nil {
+		_, err := notify.NewTelegram(os.Getenv(notify.TelegramTokenEnv), appConfig.Notify.TelegramChatID)
+		report.Check(group, "Telegram credentials", err, "set")
+	}
+	return appConfig
+}
+
+// doctorNetwork checks that the APIs answer and that the local clock agrees
+// with the exchange. An unreachable API fails the check only when the
+// configured run depends on it.
+func doctorNetwork(report *doctor.Report, cfg *runConfig, appConfig *config.Config, client *http.Client) {
+	const group = "Network"
+	required := map[string]bool{"CoinGecko": cfg.Source == "api", "Binance": cfg.Source == "binance", "Kraken": cfg.Source == "kraken", "Deribit": cfg.DVOL}
+	if appConfig != nil && appConfig.Execution != nil {
+		required["Binance"] = required["Binance"] || appConfig.Execution.Exchange == "binance"
+		required["Coinbase"] = appConfig.Execution.Exchange == "coinbase"
+	}
+	if appConfig != nil && appConfig.Notify != nil {
+		required["Telegram"] = true
+	}
+	endpoints := append([]doctorEndpoint(nil), doctorEndpoints...)
+	if appConfig != nil && appConfig.Execution != nil && appConfig.Execution.Exchange == "coinbase" {
+		endpoints = append(endpoints, doctorEndpoint{"Coinbase", "https://api.coinbase.com/api/v3/brokerage/time"})
+	}
+	if appConfig != nil && appConfig.Notify != nil {
+		endpoints = append(endpoints, doctorEndpoint{"Telegram", "https://api.telegram.org"})
+	}
+	for _, endpoint := range endpoints {
+		rtt, err := doctor.Reachable(client, endpoint.url)
+		if err != nil && !required[endpoint.name] {
+			report.Add(group, endpoint.name, doctor.Warn, "%v (not needed by this configuration)", err)
+			continue
+		}
+		report.Check(group, endpoint.name, err, fmt.Sprintf("reachable in %d ms", rtt.Milliseconds()))
+	}
+
+	skew, err := doctor.ClockSkew(client, "https://api.binance.com/api/v3/time")
+	if err != nil {
+		report.Add(group, "Clock skew", doctor.Warn, "not measured: %v", err)
+		return
+	}
+	status := doctor.SkewStatus(skew)
+	advice := ""
+	if status != doctor.OK {
+		advice = "; enable NTP time sync"
+	}
+	report.Add(group, "Clock skew", status, "local clock %+d ms from Binance%s", skew.Milliseconds(), advice)
+}
+
+// doctorStorage checks that the caches and existing stores can be read
+func doctorStorage(report *doctor.Report, cfg *runConfig, watchlistsPath, paperPath, backtestPath, candleLogPath string) {
+	const group = "Caches and stores"
+
+	httpStats, err := httpcache.Verify(cfg.CacheDir)
+	reportCache(report, group, "HTTP cache", err, httpStats.Entries, httpStats.Corrupt, httpStats.Bytes)
+	resultStats, err := resultcache.Verify(cfg.ResultCacheDir)
+	reportCache(report, group, "Result cache", err, resultStats.Entries, resultStats.Corrupt, resultStats.Bytes)
+
+	if exists(watchlistsPath) {
+		st, err := store.Open(watchlistsPath)
+		detail := ""
+		if err == nil {
+			detail = fmt.Sprintf("%d watchlists", len(st.List("")))
+		}
+		report.Check(group, "Watchlists", err, detail)
+	}
+	if exists(paperPath) {
+		st, err := store.OpenPaper(paperPath)
+		detail := ""
+		if err == nil {
+			account, _ := st.Account()
+			detail = fmt.Sprintf("%s account, equity %s, %d fills", account.Strategy, denom.Price(nil, account.Equity), len(account.Fills))
+		}
+		report.Check(group, "Paper account", err, detail)
+	}
+	if exists(backtestPath) {
+		st, err := store.OpenRuns(backtestPath)
+		detail := ""
+		if err == nil {
+			detail = fmt.Sprintf("%d runs", len(st.List()))
+		}
+		report.Check(group, "Backtest runs", err, detail)
+	}
+	if candleLogPath != "" {
+		bts, err := candlelog.Load(candleLogPath, "BTC")
+		detail := "empty"
+		if err == nil && len(bts.Data) > 0 {
+			detail = fmt.Sprintf("%d candles, last %s", len(bts.Data), bts.Data[len(bts.Data)-1].Timestamp.Format(time.RFC3339))
+		}
+		report.Check(group, "Candle log", err, detail)
+	}
+}
+
+// reportCache records the outcome of a cache verification. Corrupt entries
+// are only a warning: they are refetched or recomputed.
+func reportCache(report *doctor.Report, group, name string, err error, entries, corrupt int, bytes int64) {
+	switch {
+	case err != nil:
+		report.Add(group, name, doctor.Fail, "%v", err)
+	case corrupt > 0:
+		report.Add(group, name, doctor.Warn, "%d entries (%s), %d corrupt; they are refetched or recomputed", entries, doctor.Size(bytes), corrupt)
+	default:
+		report.Add(group, name, doctor.OK, "%d entries (%s)", entries, doctor.Size(bytes))
+	}
+}
+
+// doctorPaths checks that every directory a run writes to is writable
+func doctorPaths(report *doctor.Report, cfg *runConfig, files ...string) {
+	const group = "Writable paths"
+	dirs := []struct{ name, dir string }{
+		{"Output directory", cfg.OutputDir},
+		{"HTTP cache", cfg.CacheDir},
+		{"Result cache", cfg.ResultCacheDir},
+	}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if file != "" {
+			dirs = append(dirs, struct{ name, dir string }{"Directory of " + file, filepath.Dir(file)})
+		}
+	}
+	for _, d := range dirs {
+		abs, err := filepath.Abs(d.dir)
+		if err == nil && seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		detail, err := doctor.Writable(d.dir)
+		report.Check(group, d.name, err, fmt.Sprintf("%s (%s)", d.dir, detail))
+	}
+}
+
+// exists reports whether path is set and names an existing file
+func exists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"btc-analyzer/internal/config"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// applyEnvFlags sets every flag of fs not given on the command line from
+// its environment variable, if present: BTC_ANALYZER_ followed by the flag
+// name in upper case with dashes as underscores, e.g. BTC_ANALYZER_DAYS for
+// -days. Command-line flags win over the environment.
+func applyEnvFlags(fs *flag.FlagSet) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if given[f.Name] || err != nil {
+			return
+		}
+		name := flagEnvName(f.Name)
+		if value, ok := os.LookupEnv(name); ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// flagEnvName returns the environment variable of a flag
+func flagEnvName(flagName string) string {
+	return config.EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
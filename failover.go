@@ -0,0 +1,110 @@
+package main
+
+import (
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// networkSources are the sources fetched from an API, whose series are
+// kept for the 'cache' source
+var networkSources = map[string]bool{"api": true, "binance": true, "kraken": true}
+
+// loadSeriesWithFailover loads the price series from -source and, when it
+// fails, from each -failover source in turn. It returns the series, the
+// source that served it and the failures before it. When the chain includes
+// 'cache', every series fetched from an API is kept for it.
+func loadSeriesWithFailover(cfg *runConfig) (*types.BTCTimeSeries, string, []string, error) {
+	chain := append([]string{cfg.Source}, splitList(cfg.Failover)...)
+	keep := false
+	for _, spec := range chain {
+		if spec == "cache" {
+			keep = true
+		}
+	}
+
+	var failures []string
+	for i, spec := range chain {
+		sourceCfg := cfg
+		if i > 0 {
+			var err error
+			if sourceCfg, err = sourceSpecConfig(cfg, spec); err != nil {
+				return nil, "", nil, err
+			}
+			fmt.Printf("🔀 Failing over to %s\n", spec)
+		}
+
+		bts, err := loadSeries(sourceCfg)
+		if err == nil && len(bts.Data) == 0 && i < len(chain)-1 {
+			err = fmt.Errorf("no data returned")
+		}
+		if err != nil {
+			if i == len(chain)-1 {
+				if len(failures) > 0 {
+					return nil, "", nil, fmt.Errorf("every source failed: %s; %s: %w", strings.Join(failures, "; "), spec, err)
+				}
+				return nil, "", nil, err
+			}
+			log.Printf("Source %s failed: %v", spec, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", spec, err))
+			continue
+		}
+
+		if keep && networkSources[sourceCfg.Source] {
+			if err := saveLastSeries(cfg, bts); err != nil {
+				log.Printf("Failed to keep the series for the cache source: %v", err)
+			}
+		}
+		if len(failures) > 0 {
+			fmt.Printf("⚠️  Price series served by %s after %d failed source(s)\n", spec, len(failures))
+		}
+		return bts, spec, failures, nil
+	}
+	return nil, "", nil, fmt.Errorf("no source configured")
+}
+
+// sourceSpecConfig returns a copy of cfg that loads from spec: a source name
+// or csv:<file>, json:<file> or ndjson:<file>
+func sourceSpecConfig(cfg *runConfig, spec string) (*runConfig, error) {
+	kind, file, hasFile := strings.Cut(spec, ":")
+	next := *cfg
+	next.Source = kind
+	switch kind {
+	case "csv":
+		next.CSVFile = file
+	case "json":
+		next.JSONFile = file
+	case "ndjson":
+		next.NDJSONFile = file
+	case "api", "binance", "kraken", "cache", "sample":
+		if hasFile {
+			return nil, fmt.Errorf("invalid source %q: %s takes no file", spec, kind)
+		}
+		return &next, nil
+	default:
+		return nil, fmt.Errorf("invalid source %q: use api, binance, kraken, cache, sample, csv:<file>, json:<file> or ndjson:<file>", spec)
+	}
+	if file == "" {
+		return nil, fmt.Errorf("invalid source %q: missing file", spec)
+	}
+	return &next, nil
+}
+
+// lastSeriesPath is where the series kept for the 'cache' source is stored,
+// next to the cached API responses
+func lastSeriesPath(cfg *runConfig) string {
+	return filepath.Join(cfg.CacheDir, "series", "last.json")
+}
+
+// saveLastSeries keeps bts for the 'cache' source
+func saveLastSeries(cfg *runConfig, bts *types.BTCTimeSeries) error {
+	path := lastSeriesPath(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return dataloader.SaveToJSON(bts, path)
+}
@@ -1,372 +1,1260 @@
-package analyzer
-
-import (
-	"btc-analyzer/internal/indicators"
-	"btc-analyzer/internal/patterns"
-	"btc-analyzer/internal/statistics"
-	"btc-analyzer/internal/timeseries"
-	"btc-analyzer/internal/types"
-	"fmt"
-	"time"
-	"math"
-)
-
-// PerformComprehensiveAnalysis runs a full analysis on Bitcoin data
-func PerformComprehensiveAnalysis(bts *types.BTCTimeSeries) types.BTCAnalytics {
-	analytics := types.BTCAnalytics{}
-	
-	if len(bts.Data) < 2 {
-		return analytics
-	}
-	
-	// Basic price and volume statistics
-	prices := timeseries.GetClosePrices(bts)
-	volumes := timeseries.GetVolumeData(bts)
-	
-	analytics.PriceStats = statistics.Calculate(prices)
-	analytics.VolumeStats = statistics.Calculate(volumes)
-	
-	// Calculate returns
-	returns, logReturns := statistics.CalculateReturns(bts)
-	analytics.Returns = returns
-	analytics.LogReturns = logReturns
-	
-	// Risk metrics
-	if len(returns) > 0 {
-		analytics.Volatility = statistics.CalculateVolatility(returns, 365)
-		analytics.SharpeRatio = statistics.CalculateSharpeRatio(returns, 0.0, 365)
-		analytics.MaxDrawdown = statistics.CalculateMaxDrawdown(bts)
-	}
-	
-	// Technical indicators
-	if len(bts.Data) >= 14 {
-		analytics.RSI = indicators.CalculateRSI(bts, 14)
-	}
-	
-	if len(bts.Data) >= 26 {
-		analytics.MACD = indicators.CalculateMACD(bts, 12, 26, 9)
-	}
-	
-	if len(bts.Data) >= 20 {
-		analytics.BollingerBands = indicators.CalculateBollingerBands(bts, 20, 2.0)
-	}
-	
-	// Pattern analysis
-	if len(bts.Data) >= 10 {
-		analytics.SupportResistance = patterns.FindSupportResistanceLevels(bts, 5, 0.02)
-	}
-	
-	return analytics
-}
-
-// GenerateReport creates a comprehensive text report
-func GenerateReport(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) string {
-	var report string
-	
-	report += "=== BITCOIN MARKET ANALYSIS REPORT ===\n\n"
-	
-	// Basic information
-	report += fmt.Sprintf("Symbol: %s\n", bts.Symbol)
-	report += fmt.Sprintf("Data Points: %d\n", len(bts.Data))
-	
-	if len(bts.Data) > 0 {
-		start, end := timeseries.GetTimeRange(bts)
-		report += fmt.Sprintf("Time Range: %s to %s\n", 
-			start.Format("2006-01-02"), 
-			end.Format("2006-01-02"))
-		
-		latest := timeseries.GetLatestPrice(bts)
-		report += fmt.Sprintf("Latest Price: $%.2f\n", latest.Close)
-		report += fmt.Sprintf("Latest Volume: %.0f\n\n", latest.Volume)
-	}
-	
-	// Price statistics
-	report += "=== PRICE STATISTICS ===\n"
-	report += fmt.Sprintf("Mean Price: $%.2f\n", analytics.PriceStats.Mean)
-	report += fmt.Sprintf("Median Price: $%.2f\n", analytics.PriceStats.Median)
-	report += fmt.Sprintf("Price Range: $%.2f - $%.2f\n", analytics.PriceStats.Min, analytics.PriceStats.Max)
-	report += fmt.Sprintf("Standard Deviation: $%.2f\n", analytics.PriceStats.StdDev)
-	report += fmt.Sprintf("Price Variance: %.2f\n", analytics.PriceStats.Variance)
-	
-	if analytics.PriceStats.Skewness != 0 {
-		report += fmt.Sprintf("Skewness: %.3f\n", analytics.PriceStats.Skewness)
-		report += fmt.Sprintf("Kurtosis: %.3f\n", analytics.PriceStats.Kurtosis)
-	}
-	report += "\n"
-	
-	// Risk metrics
-	if analytics.Volatility > 0 {
-		report += "=== RISK METRICS ===\n"
-		report += fmt.Sprintf("Annualized Volatility: %.2f%%\n", analytics.Volatility*100)
-		report += fmt.Sprintf("Sharpe Ratio: %.3f\n", analytics.SharpeRatio)
-		report += fmt.Sprintf("Maximum Drawdown: %.2f%%\n", analytics.MaxDrawdown*100)
-		report += "\n"
-	}
-	
-	// Volume statistics
-	report += "=== VOLUME STATISTICS ===\n"
-	report += fmt.Sprintf("Mean Volume: %.0f\n", analytics.VolumeStats.Mean)
-	report += fmt.Sprintf("Median Volume: %.0f\n", analytics.VolumeStats.Median)
-	report += fmt.Sprintf("Volume Range: %.0f - %.0f\n", analytics.VolumeStats.Min, analytics.VolumeStats.Max)
-	report += fmt.Sprintf("Volume Std Dev: %.0f\n", analytics.VolumeStats.StdDev)
-	report += "\n"
-	
-	// Technical indicators
-	if len(analytics.RSI) > 0 {
-		report += "=== TECHNICAL INDICATORS ===\n"
-		latestRSI := analytics.RSI[len(analytics.RSI)-1]
-		report += fmt.Sprintf("Latest RSI (14): %.2f", latestRSI)
-		
-		if latestRSI > 70 {
-			report += " (Overbought)\n"
-		} else if latestRSI < 30 {
-			report += " (Oversold)\n"
-		} else {
-			report += " (Neutral)\n"
-		}
-	}
-	
-	if len(analytics.MACD.MACD) > 0 {
-		latestMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
-		latestSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-1]
-		report += fmt.Sprintf("Latest MACD: %.4f\n", latestMACD)
-		report += fmt.Sprintf("MACD Signal: %.4f", latestSignal)
-		
-		if latestMACD > latestSignal {
-			report += " (Bullish)\n"
-		} else {
-			report += " (Bearish)\n"
-		}
-	}
-	
-	if len(analytics.BollingerBands.Middle) > 0 {
-		latest := len(analytics.BollingerBands.Middle) - 1
-		latestPrice := timeseries.GetLatestPrice(bts).Close
-		upper := analytics.BollingerBands.Upper[latest]
-		middle := analytics.BollingerBands.Middle[latest]
-		lower := analytics.BollingerBands.Lower[latest]
-		
-		report += fmt.Sprintf("Bollinger Bands - Upper: %.2f, Middle: %.2f, Lower: %.2f\n", upper, middle, lower)
-		
-		if latestPrice > upper {
-			report += "Price is above upper band (potentially overbought)\n"
-		} else if latestPrice < lower {
-			report += "Price is below lower band (potentially oversold)\n"
-		} else {
-			report += "Price is within normal range\n"
-		}
-	}
-	report += "\n"
-	
-	// Support and resistance
-	if len(analytics.SupportResistance.SupportLevels) > 0 || len(analytics.SupportResistance.ResistanceLevels) > 0 {
-		report += "=== SUPPORT & RESISTANCE LEVELS ===\n"
-		
-		if len(analytics.SupportResistance.SupportLevels) > 0 {
-			report += "Support Levels: "
-			for i, level := range analytics.SupportResistance.SupportLevels {
-				if i > 0 {
-					report += ", "
-				}
-				report += fmt.Sprintf("$%.2f", level)
-			}
-			report += "\n"
-		}
-		
-		if len(analytics.SupportResistance.ResistanceLevels) > 0 {
-			report += "Resistance Levels: "
-			for i, level := range analytics.SupportResistance.ResistanceLevels {
-				if i > 0 {
-					report += ", "
-				}
-				report += fmt.Sprintf("$%.2f", level)
-			}
-			report += "\n"
-		}
-		report += "\n"
-	}
-	
-	// Trend analysis
-	trend := patterns.DetectTrend(bts, 30)
-	report += "=== TREND ANALYSIS ===\n"
-	report += fmt.Sprintf("30-Day Trend: %s\n", trend)
-	
-	// Pattern detection
-	candlestickPatterns := patterns.DetectCandlestickPatterns(bts)
-	volumePatterns := patterns.DetectVolumePatterns(bts)
-	
-	if len(candlestickPatterns) > 0 {
-		report += "\n=== RECENT CANDLESTICK PATTERNS ===\n"
-		for pattern, indices := range candlestickPatterns {
-			if len(indices) > 0 {
-				// Show only recent patterns (last 10 occurrences)
-				recent := indices
-				if len(indices) > 10 {
-					recent = indices[len(indices)-10:]
-				}
-				report += fmt.Sprintf("%s: %d recent occurrences\n", pattern, len(recent))
-			}
-		}
-	}
-	
-	if len(volumePatterns) > 0 {
-		report += "\n=== RECENT VOLUME PATTERNS ===\n"
-		for pattern, indices := range volumePatterns {
-			if len(indices) > 0 {
-				recent := indices
-				if len(indices) > 5 {
-					recent = indices[len(indices)-5:]
-				}
-				report += fmt.Sprintf("%s: %d recent occurrences\n", pattern, len(recent))
-			}
-		}
-	}
-	
-	// Pivot points
-	pivots := patterns.FindPivotPoints(bts)
-	if len(pivots) > 0 {
-		report += "\n=== PIVOT POINTS ===\n"
-		if pivot, exists := pivots["pivot"]; exists {
-			report += fmt.Sprintf("Pivot Point: $%.2f\n", pivot)
-		}
-		if r1, exists := pivots["r1"]; exists {
-			report += fmt.Sprintf("Resistance 1: $%.2f\n", r1)
-		}
-		if s1, exists := pivots["s1"]; exists {
-			report += fmt.Sprintf("Support 1: $%.2f\n", s1)
-		}
-	}
-	
-	// Fibonacci retracements
-	fibs := patterns.CalculateFibonacciRetracements(bts, 30)
-	if len(fibs) > 0 {
-		report += "\n=== FIBONACCI RETRACEMENTS (30-day) ===\n"
-		fibLevels := []string{"high", "fib_23_6", "fib_38_2", "fib_50", "fib_61_8", "fib_76_4", "low"}
-		for _, level := range fibLevels {
-			if price, exists := fibs[level]; exists {
-				report += fmt.Sprintf("%s: $%.2f\n", level, price)
-			}
-		}
-	}
-	
-	report += "\n=== END OF REPORT ===\n"
-	report += fmt.Sprintf("Generated at: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	
-	return report
-}
-
-// GetTradingSignals analyzes data and provides trading signals
-func GetTradingSignals(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) map[string]string {
-	signals := make(map[string]string)
-	
-	// RSI signals
-	if len(analytics.RSI) > 0 {
-		latestRSI := analytics.RSI[len(analytics.RSI)-1]
-		if latestRSI > 70 {
-			signals["RSI"] = "SELL - Overbought"
-		} else if latestRSI < 30 {
-			signals["RSI"] = "BUY - Oversold"
-		} else {
-			signals["RSI"] = "HOLD - Neutral"
-		}
-	}
-	
-	// MACD signals
-	if len(analytics.MACD.MACD) > 1 && len(analytics.MACD.Signal) > 1 {
-		latestMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
-		prevMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-2]
-		latestSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-1]
-		prevSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-2]
-		
-		// Check for crossovers
-		if prevMACD <= prevSignal && latestMACD > latestSignal {
-			signals["MACD"] = "BUY - Bullish crossover"
-		} else if prevMACD >= prevSignal && latestMACD < latestSignal {
-			signals["MACD"] = "SELL - Bearish crossover"
-		} else if latestMACD > latestSignal {
-			signals["MACD"] = "HOLD - Bullish"
-		} else {
-			signals["MACD"] = "HOLD - Bearish"
-		}
-	}
-	
-	// Bollinger Bands signals
-	if len(analytics.BollingerBands.Upper) > 0 {
-		latestPrice := timeseries.GetLatestPrice(bts).Close
-		latest := len(analytics.BollingerBands.Upper) - 1
-		upper := analytics.BollingerBands.Upper[latest]
-		lower := analytics.BollingerBands.Lower[latest]
-		
-		if latestPrice > upper {
-			signals["Bollinger"] = "SELL - Price above upper band"
-		} else if latestPrice < lower {
-			signals["Bollinger"] = "BUY - Price below lower band"
-		} else {
-			signals["Bollinger"] = "HOLD - Price in normal range"
-		}
-	}
-	
-	// Trend signals
-	trend := patterns.DetectTrend(bts, 30)
-	switch trend {
-	case "uptrend":
-		signals["Trend"] = "BUY - Uptrend detected"
-	case "downtrend":
-		signals["Trend"] = "SELL - Downtrend detected"
-	default:
-		signals["Trend"] = "HOLD - Sideways movement"
-	}
-	
-	// Support/Resistance signals
-	if len(analytics.SupportResistance.SupportLevels) > 0 || len(analytics.SupportResistance.ResistanceLevels) > 0 {
-		latestPrice := timeseries.GetLatestPrice(bts).Close
-		
-		// Check if price is near support (buy signal)
-		for _, support := range analytics.SupportResistance.SupportLevels {
-			if math.Abs(latestPrice-support)/support < 0.02 { // Within 2%
-				signals["Support"] = "BUY - Near support level"
-				break
-			}
-		}
-		
-		// Check if price is near resistance (sell signal)
-		for _, resistance := range analytics.SupportResistance.ResistanceLevels {
-			if math.Abs(latestPrice-resistance)/resistance < 0.02 { // Within 2%
-				signals["Resistance"] = "SELL - Near resistance level"
-				break
-			}
-		}
-	}
-	
-	return signals
-}
-
-// CalculatePortfolioMetrics calculates portfolio-level metrics
-func CalculatePortfolioMetrics(bts *types.BTCTimeSeries, initialInvestment float64) map[string]interface{} {
-	metrics := make(map[string]interface{})
-	
-	if len(bts.Data) < 2 {
-		return metrics
-	}
-	
-	// Basic portfolio metrics
-	backtest := statistics.PerformBacktest(bts, initialInvestment)
-	for key, value := range backtest {
-		metrics[key] = value
-	}
-	
-	// Risk metrics
-	riskMetrics := statistics.GetRiskMetrics(bts)
-	for key, value := range riskMetrics {
-		metrics[key] = value
-	}
-	
-	// Performance ratios
-	if volatility, exists := riskMetrics["volatility_annual"]; exists && volatility > 0 {
-		if totalReturn, exists := backtest["annualized_return"]; exists {
-			metrics["information_ratio"] = totalReturn / volatility
-		}
-	}
-	
-	return metrics
-}
\ No newline at end of file
+package analyzer
+
+import (
+	"btc-analyzer/internal/comparison"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/forensics"
+	"btc-analyzer/internal/indicators"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/orderbook"
+	"btc-analyzer/internal/orderflow"
+	"btc-analyzer/internal/patterns"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/version"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PerformComprehensiveAnalysis runs a full analysis on Bitcoin data. Ratios
+// follow the risk conventions in rc, with periods per year detected from the
+// data when not set. Analyses the series is too short for, per Requirements,
+// are listed in Skipped.
+func PerformComprehensiveAnalysis(bts *types.BTCTimeSeries, rc types.RiskConfig) types.BTCAnalytics {
+	return AnalyzeWithWarmup(bts, nil, rc)
+}
+
+// AnalyzeWithWarmup runs PerformComprehensiveAnalysis on bts with the
+// technical indicators computed from the warm-up bars just before it on, so
+// they have a value on every bar of bts when there are WarmupBars of them.
+// Indicator values are cut to the bars of bts; nothing else reads warmup.
+func AnalyzeWithWarmup(bts *types.BTCTimeSeries, warmup []types.BTCPrice, rc types.RiskConfig) types.BTCAnalytics {
+	analytics := types.BTCAnalytics{}
+	analytics.RiskConvention = statistics.ResolveRiskConfig(bts, rc)
+	analytics.Skipped = CheckCapabilities(len(bts.Data))
+	can := func(analysis string) bool { return len(bts.Data) >= MinBars(analysis) }
+	full := WithWarmup(bts, warmup)
+	canIndicator := func(analysis string) bool { return len(full.Data) >= MinBars(analysis) }
+	if len(warmup) > 0 {
+		analytics.Skipped = slices.DeleteFunc(analytics.Skipped, func(s types.SkippedAnalysis) bool {
+			return slices.Contains(warmedIndicators, s.Analysis) && canIndicator(s.Analysis)
+		})
+	}
+	
+	if !can(AnalysisStatistics) {
+		return analytics
+	}
+	
+	// Basic price and volume statistics
+	prices := timeseries.GetClosePrices(bts)
+	volumes := timeseries.GetVolumeData(bts)
+	
+	analytics.PriceStats = statistics.Calculate(prices)
+	analytics.VolumeStats = statistics.Calculate(volumes)
+	
+	analytics.Performance = PerformanceTable(bts)
+	analytics.AllTime = TrackAllTimeExtremes(bts, nil, "")
+	
+	// Calculate returns
+	returns, logReturns := statistics.CalculateReturns(bts)
+	analytics.Returns = returns
+	analytics.LogReturns = logReturns
+	
+	// Risk metrics
+	if len(returns) > 0 {
+		rv := statistics.RealizedVolatilities(bts, returns, analytics.RiskConvention.VolEstimator, analytics.RiskConvention.PeriodsPerYear)
+		analytics.RealizedVol = &rv
+		analytics.Volatility = rv.Selected
+		analytics.SharpeRatio = statistics.SharpeRatioWithVolatility(returns, rv.Selected, analytics.RiskConvention)
+		analytics.MaxDrawdown = statistics.CalculateMaxDrawdown(bts)
+		if can(AnalysisTailRisk) {
+			tr := statistics.TailRiskMetrics(bts, returns, analytics.SharpeRatio, analytics.RiskConvention)
+			analytics.TailRisk = &tr
+		}
+		if can(AnalysisConfidence) {
+			// A fixed seed keeps the intervals of a series the same on every run
+			ci := statistics.BootstrapConfidence(returns, rv.Selected, analytics.RiskConvention,
+				statistics.DefaultBootstrapResamples, statistics.DefaultConfidenceLevel, rand.New(rand.NewSource(1)))
+			analytics.Confidence = &ci
+		}
+	}
+	
+	if can(AnalysisStationarity) {
+		st := statistics.TestStationarity(prices)
+		analytics.Stationarity = &st
+	}
+	if can(AnalysisMeanReversion) {
+		ou := statistics.FitOULogPrices(prices, float64(analytics.RiskConvention.PeriodsPerYear)/365)
+		analytics.MeanReversion = &ou
+	}
+	if can(AnalysisAttribution) {
+		analytics.Attribution = AnalyzeReturnAttribution(bts)
+	}
+	
+	// Technical indicators, evaluated through the indicator graph so the
+	// closes and moving averages they share are computed once, over the
+	// warm-up bars too and then cut to the bars of bts
+	var requested []string
+	if canIndicator(AnalysisRSI) {
+		requested = append(requested, indicators.NodeRSI)
+	}
+	if canIndicator(AnalysisMACD) {
+		requested = append(requested, indicators.NodeMACD)
+	}
+	if canIndicator(AnalysisBollinger) {
+		requested = append(requested, indicators.NodeBollinger)
+	}
+	if canIndicator(AnalysisROC) {
+		requested = append(requested, indicators.NodeROC)
+	}
+	bars := len(bts.Data)
+	if computed, err := indicators.Compute(full, requested...); err == nil {
+		if rsi, ok := computed[indicators.NodeRSI].([]float64); ok {
+			analytics.RSI = lastValues(rsi, bars)
+		}
+		if macd, ok := computed[indicators.NodeMACD].(types.MACDData); ok {
+			analytics.MACD = types.MACDData{MACD: lastValues(macd.MACD, bars), Signal: lastValues(macd.Signal, bars), Histogram: lastValues(macd.Histogram, bars)}
+		}
+		if bands, ok := computed[indicators.NodeBollinger].(types.BollingerBandsData); ok {
+			analytics.BollingerBands = types.BollingerBandsData{Upper: lastValues(bands.Upper, bars), Middle: lastValues(bands.Middle, bars), Lower: lastValues(bands.Lower, bars)}
+		}
+		if roc, ok := computed[indicators.NodeROC].([]float64); ok {
+			analytics.ROC = lastValues(roc, bars)
+		}
+	}
+	
+	// Pattern analysis
+	if can(AnalysisLevels) {
+		analytics.SupportResistance = patterns.FindSupportResistanceLevels(bts, 5, 0.02)
+		analytics.LevelMap = patterns.BuildLevelMap(bts, analytics.SupportResistance.Levels, 0.01)
+		analytics.LiquidityZones = patterns.FindLiquidityZones(bts, 3, 0.003)
+		analytics.SessionGaps = patterns.AnalyzeSessionGaps(bts, patterns.DefaultMinSessionGap)
+	}
+	
+	if can(AnalysisElliott) {
+		elliott := patterns.DetectElliottWaves(bts, patterns.DefaultZigZagThreshold, 3)
+		analytics.ElliottWaves = &elliott
+		analytics.Harmonics = patterns.DetectHarmonicPatterns(elliott.Swings, 0.05)
+	}
+	
+	if can(AnalysisMarketStructure) {
+		structure := patterns.AnalyzeMarketStructure(bts, patterns.DefaultZigZagThreshold)
+		analytics.MarketStructure = &structure
+	}
+	
+	if can(AnalysisWyckoff) {
+		analytics.Wyckoff = patterns.DetectWyckoff(bts, 0.2, 20)
+	}
+	
+	if can(AnalysisVolume) {
+		window := timeseries.BarsFor(bts, 7*24*time.Hour)
+		if window < 20 {
+			window = 20
+		}
+		vf := forensics.AnalyzeVolume(bts, window)
+		analytics.VolumeForensics = &vf
+	}
+	
+	// the valuation heuristics and the model read fiat prices, so series
+	// in sats or BTC skip them
+	if !denom.Inverse(bts.Denomination) {
+		analytics.Valuation = AnalyzeLongTermValuation(bts)
+		analytics.StockToFlow = AnalyzeStockToFlow(bts)
+	}
+	
+	if can(AnalysisLiquidity) {
+		le := statistics.EstimateLiquidity(bts, liquidityWindow)
+		analytics.Liquidity = &le
+	}
+	
+	occurrences, bias := collectPatternOccurrences(bts, analytics)
+	analytics.PatternOutcomes = patterns.EvaluatePatternOutcomes(bts, occurrences, bias, patterns.DefaultOutcomeHorizons)
+	
+	return analytics
+}
+
+// NewResult wraps the analytics of bts with their trading signals, generated
+// with the thresholds of sc, and the metadata of the data. The caller fills
+// in run details such as the parameters, input hash and compute time.
+func NewResult(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, sc types.SignalConfig) *types.AnalysisResult {
+	sc = ResolveSignalConfig(sc)
+	signals := GetTradingSignals(bts, analytics, sc)
+	result := &types.AnalysisResult{
+		Metadata: types.ResultMetadata{
+			Symbol:      bts.Symbol,
+			DataPoints:  len(bts.Data),
+			GeneratedAt: time.Now(),
+			Version:     version.String(),
+		},
+		Series:           bts,
+		Analytics:        analytics,
+		Signals:          signals,
+		SignalScore:      SignalScore(signals),
+		SignalThresholds: sc,
+	}
+	if len(bts.Data) > 0 {
+		latest := bts.Data[len(bts.Data)-1]
+		result.Metadata.Start, result.Metadata.End = timeseries.GetTimeRange(bts)
+		result.Metadata.LatestPrice = latest.Close
+		result.Metadata.LatestVolume = latest.Volume
+	}
+	return result
+}
+
+// GenerateReport creates a comprehensive text report with the sections of rc
+func GenerateReport(result *types.AnalysisResult, rc types.ReportConfig) string {
+	bts, analytics := result.Series, result.Analytics
+	d := bts.Denomination
+	thresholds := ResolveSignalConfig(result.SignalThresholds)
+	var report sectionWriter
+	
+	report.WriteString("=== BITCOIN MARKET ANALYSIS REPORT ===\n\n")
+	
+	// Basic information
+	fmt.Fprintf(&report, "Symbol: %s\n", bts.Symbol)
+	fmt.Fprintf(&report, "Data Points: %d\n", len(bts.Data))
+	
+	if len(bts.Data) > 0 {
+		start, end := timeseries.GetTimeRange(bts)
+		fmt.Fprintf(&report, "Time Range: %s to %s\n", 
+			start.Format("2006-01-02"), 
+			end.Format("2006-01-02"))
+		frequency := timeseries.DetectFrequency(bts)
+		fmt.Fprintf(&report, "Data Frequency: %s (%s bars, %d per year)\n",
+			frequency.Name, frequency.Interval, frequency.PeriodsPerYear)
+		if w := result.Metadata.Warmup; w != nil {
+			fmt.Fprintf(&report, "Indicator Warm-up: %s\n", DescribeWarmup(*w))
+		}
+		
+		latest := timeseries.GetLatestPrice(bts)
+		fmt.Fprintf(&report, "Latest Price: %s\n", denom.Price(d, latest.Close))
+		fmt.Fprintf(&report, "Latest Volume: %.0f\n\n", latest.Volume)
+	}
+	
+	if len(analytics.Performance) > 0 {
+		report.section(SectionSummary)
+		report.WriteString("=== PERFORMANCE ===\n")
+		report.WriteString(FormatPerformance(analytics.Performance))
+		report.WriteString("\n")
+	}
+	
+	if ate := analytics.AllTime; ate != nil {
+		report.section(SectionSummary)
+		report.WriteString("=== ALL-TIME HIGH / LOW ===\n")
+		for _, line := range DescribeAllTimeExtremes(*ate, d) {
+			fmt.Fprintf(&report, "%s\n", line)
+		}
+		report.WriteString("\n")
+	}
+	
+	if narrative := Narrative(result); len(narrative) > 0 {
+		report.section(SectionSummary)
+		report.WriteString("=== SUMMARY ===\n")
+		for _, paragraph := range narrative {
+			fmt.Fprintf(&report, "%s\n\n", paragraph)
+		}
+	}
+	
+	bars := len(bts.Data)
+	if skipped(analytics, AnalysisStatistics) {
+		report.section(SectionPrice)
+		report.WriteString("=== PRICE STATISTICS ===\n")
+		fmt.Fprintf(&report, "%s\n\n", InsufficientData(AnalysisStatistics, bars))
+	} else {
+		report.section(SectionPrice)
+		// Price statistics
+		report.WriteString("=== PRICE STATISTICS ===\n")
+		fmt.Fprintf(&report, "Mean Price: %s\n", denom.Price(d, analytics.PriceStats.Mean))
+		fmt.Fprintf(&report, "Median Price: %s\n", denom.Price(d, analytics.PriceStats.Median))
+		fmt.Fprintf(&report, "Price Range: %s - %s\n", denom.Price(d, analytics.PriceStats.Min), denom.Price(d, analytics.PriceStats.Max))
+		fmt.Fprintf(&report, "Standard Deviation: %s\n", denom.Price(d, analytics.PriceStats.StdDev))
+		fmt.Fprintf(&report, "Price Variance: %.2f\n", analytics.PriceStats.Variance)
+	
+		if analytics.PriceStats.Skewness != 0 {
+			fmt.Fprintf(&report, "Skewness: %.3f\n", analytics.PriceStats.Skewness)
+			fmt.Fprintf(&report, "Kurtosis: %.3f\n", analytics.PriceStats.Kurtosis)
+		}
+		report.WriteString("\n")
+	
+		if rp := analytics.RealPrices; rp != nil {
+			report.section(SectionPrice)
+			fmt.Fprintf(&report, "=== REAL VS NOMINAL (%s, %s dollars) ===\n", rp.Index, rp.BaseDate.Format("2006-01"))
+			fmt.Fprintf(&report, "Mean Real Price: %s\n", denom.Price(d, rp.RealStats.Mean))
+			fmt.Fprintf(&report, "Real Price Range: %s - %s\n", denom.Price(d, rp.RealStats.Min), denom.Price(d, rp.RealStats.Max))
+			fmt.Fprintf(&report, "Return: nominal %s (%s/yr), real %s (%s/yr)\n",
+				numfmt.SignedPct(rp.NominalReturn), numfmt.SignedPct(rp.NominalAnnualized), numfmt.SignedPct(rp.RealReturn), numfmt.SignedPct(rp.RealAnnualized))
+			fmt.Fprintf(&report, "Max Drawdown: nominal %s, real %s\n", numfmt.Pct(rp.NominalDrawdown), numfmt.Pct(rp.RealDrawdown))
+			fmt.Fprintf(&report, "Inflation over the period: %s\n", numfmt.SignedPct(rp.Inflation))
+			if last := rp.Points[len(rp.Points)-1].Timestamp; last.Sub(rp.BaseDate) > 45*24*time.Hour {
+				fmt.Fprintf(&report, "Index readings end %s; later bars carry the last one forward\n", rp.BaseDate.Format("2006-01"))
+			}
+			report.WriteString("\n")
+		}
+	
+		// Risk metrics
+		if analytics.Volatility > 0 {
+			report.section(SectionRisk)
+			report.WriteString("=== RISK METRICS ===\n")
+			if ci := analytics.Confidence; ci != nil {
+				for _, line := range DescribeConfidence(*ci) {
+					fmt.Fprintf(&report, "%s\n", line)
+				}
+			} else {
+				fmt.Fprintf(&report, "Annualized Volatility: %s\n", numfmt.Pct(analytics.Volatility))
+				fmt.Fprintf(&report, "Sharpe Ratio: %.3f\n", analytics.SharpeRatio)
+			}
+			fmt.Fprintf(&report, "Maximum Drawdown: %s\n", numfmt.Pct(analytics.MaxDrawdown))
+			if analytics.RiskConvention.PeriodsPerYear > 0 {
+				fmt.Fprintf(&report, "Convention: %s\n", statistics.DescribeRiskConfig(analytics.RiskConvention))
+			}
+			report.WriteString("\n")
+		}
+	
+		if rv := analytics.RealizedVol; rv != nil {
+			report.section(SectionRisk)
+			report.WriteString("=== VOLATILITY ESTIMATORS ===\n")
+			for _, estimator := range statistics.VolEstimators {
+				if estimator != statistics.EstimatorCloseToClose && !rv.HasRanges {
+					continue
+				}
+				inUse := ""
+				if estimator == rv.Estimator {
+					inUse = " (in use)"
+				}
+				fmt.Fprintf(&report, "%s: %s%s\n", statistics.EstimatorName(estimator), numfmt.Pct(statistics.EstimatorValue(*rv, estimator)), inUse)
+			}
+			if !rv.HasRanges {
+				report.WriteString("Range-based estimators unavailable: the series has no intrabar high/low ranges\n")
+			}
+			report.WriteString("\n")
+		}
+	
+		if tr := analytics.TailRisk; tr != nil {
+			report.section(SectionRisk)
+			report.WriteString("=== TAIL RISK ===\n")
+			for _, line := range DescribeTailRisk(*tr) {
+				fmt.Fprintf(&report, "%s\n", line)
+			}
+			report.WriteString("\n")
+		}
+	
+		if st := analytics.Stationarity; st != nil {
+			report.section(SectionRisk)
+			report.WriteString("=== DIAGNOSTICS ===\n")
+			for _, line := range DescribeStationarity(*st) {
+				fmt.Fprintf(&report, "%s\n", line)
+			}
+			report.WriteString("\n")
+		}
+	
+		if ou := analytics.MeanReversion; ou != nil {
+			report.section(SectionStatistics)
+			report.WriteString("=== MEAN REVERSION ===\n")
+			for _, line := range DescribeMeanReversion(*ou, d) {
+				fmt.Fprintf(&report, "%s\n", line)
+			}
+			report.WriteString("\n")
+		}
+	
+		if ra := analytics.Attribution; ra != nil {
+			report.section(SectionStatistics)
+			report.WriteString("=== RETURN ATTRIBUTION ===\n")
+			for _, line := range DescribeReturnAttribution(*ra) {
+				fmt.Fprintf(&report, "%s\n", line)
+			}
+			report.WriteString("\n")
+		}
+	
+		// Volume statistics
+		report.section(SectionPrice)
+		report.WriteString("=== VOLUME STATISTICS ===\n")
+		fmt.Fprintf(&report, "Mean Volume: %.0f\n", analytics.VolumeStats.Mean)
+		fmt.Fprintf(&report, "Median Volume: %.0f\n", analytics.VolumeStats.Median)
+		fmt.Fprintf(&report, "Volume Range: %.0f - %.0f\n", analytics.VolumeStats.Min, analytics.VolumeStats.Max)
+		fmt.Fprintf(&report, "Volume Std Dev: %.0f\n", analytics.VolumeStats.StdDev)
+		report.WriteString("\n")
+	}
+	
+	if len(analytics.Percentiles) > 0 {
+		report.section(SectionRisk)
+		report.WriteString("=== PERCENTILE RANKS (trailing year) ===\n")
+		for _, mp := range analytics.Percentiles {
+			fmt.Fprintf(&report, "%s\n", DescribePercentile(mp, d))
+		}
+		report.WriteString("\n")
+	}
+	
+	if ltv := analytics.Valuation; ltv != nil {
+		report.section(SectionValuation)
+		report.WriteString("=== LONG-TERM VALUATION ===\n")
+		for _, line := range DescribeValuation(*ltv, d) {
+			fmt.Fprintf(&report, "%s\n", line)
+		}
+		report.WriteString("\n")
+	}
+	
+	if s2f := analytics.StockToFlow; s2f != nil {
+		report.section(SectionValuation)
+		report.WriteString("=== STOCK-TO-FLOW (reference model, not a prediction) ===\n")
+		fmt.Fprintf(&report, "Estimated Supply: %.0f BTC, stock-to-flow %.1f (next halving ~%s)\n",
+			s2f.Supply, s2f.StockToFlow, s2f.NextHalving.Format("2006-01-02"))
+		fmt.Fprintf(&report, "Model Price: %s, price is %.2fx the model (log residual %+.2f)\n",
+			denom.Price(d, s2f.ModelPrice), math.Exp(s2f.Residual), s2f.Residual)
+		if s2f.ResidualStdDev > 0 {
+			fmt.Fprintf(&report, "Residual over the series: mean %+.2f, std dev %.2f; latest is %+.1f std devs from the mean\n",
+				s2f.MeanResidual, s2f.ResidualStdDev, (s2f.Residual-s2f.MeanResidual)/s2f.ResidualStdDev)
+		}
+		report.WriteString("The model prices BTC from its supply over its annual issuance. It is shown for reference; it has no predictive guarantee and has diverged from the price since 2021.\n\n")
+	}
+	
+	if nvt := analytics.NVT; nvt != nil {
+		report.section(SectionValuation)
+		report.WriteString("=== NVT (on-chain) ===\n")
+		fmt.Fprintf(&report, "NVT Ratio: %.1f (%.0f%% of history at or below)\n", nvt.NVT, nvt.NVTPercentile)
+		if nvt.NVTSignal > 0 {
+			fmt.Fprintf(&report, "NVT Signal (%d-day volume): %.1f (%.0f%% of history at or below)\n", nvt.SignalWindow, nvt.NVTSignal, nvt.SignalPercentile)
+		} else {
+			fmt.Fprintf(&report, "NVT Signal: needs %d days of on-chain data\n", nvt.SignalWindow)
+		}
+		for _, extreme := range nvt.Extremes {
+			fmt.Fprintf(&report, "⚠️  %s\n", extreme)
+		}
+		report.WriteString("\n")
+	}
+	
+	if heat := analytics.MarketHeat; heat != nil {
+		report.section(SectionValuation)
+		report.WriteString("=== MARKET HEAT INDEX ===\n")
+		fmt.Fprintf(&report, "Heat: %+.2f (%s, %.0f%% of history at or below; bands %+.2f / %+.2f)\n",
+			heat.Latest, heat.Zone, heat.Percentile, heat.LowerBand, heat.UpperBand)
+		for _, c := range sortedHeatComponents(heat) {
+			fmt.Fprintf(&report, "  %-10s z %+.2f x weight %.2f\n", c.Name, c.Latest, c.Weight)
+		}
+		report.WriteString("\n")
+	}
+	
+	// Technical indicators
+	report.section(SectionIndicators)
+	report.WriteString("=== TECHNICAL INDICATORS ===\n")
+	if len(analytics.RSI) > 0 {
+		latestRSI := analytics.RSI[len(analytics.RSI)-1]
+		fmt.Fprintf(&report, "Latest RSI (14): %.2f", latestRSI)
+		
+		if latestRSI > thresholds.RSIOverbought {
+			report.WriteString(" (Overbought)\n")
+		} else if latestRSI < thresholds.RSIOversold {
+			report.WriteString(" (Oversold)\n")
+		} else {
+			report.WriteString(" (Neutral)\n")
+		}
+	} else {
+		fmt.Fprintf(&report, "RSI (14): %s\n", InsufficientData(AnalysisRSI, bars))
+	}
+	
+	if len(analytics.ROC) > 0 {
+		fmt.Fprintf(&report, "Latest ROC (12): %s\n", numfmt.SignedPct(analytics.ROC[len(analytics.ROC)-1]))
+	} else {
+		fmt.Fprintf(&report, "ROC (12): %s\n", InsufficientData(AnalysisROC, bars))
+	}
+	
+	if len(analytics.MACD.MACD) > 0 && len(analytics.MACD.Signal) > 0 {
+		latestMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
+		latestSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-1]
+		fmt.Fprintf(&report, "Latest MACD: %s\n", denom.Amount(bts.Denomination, latestMACD))
+		fmt.Fprintf(&report, "MACD Signal: %s", denom.Amount(bts.Denomination, latestSignal))
+		
+		if latestMACD > latestSignal {
+			report.WriteString(" (Bullish)\n")
+		} else {
+			report.WriteString(" (Bearish)\n")
+		}
+	} else {
+		fmt.Fprintf(&report, "MACD: %s\n", InsufficientData(AnalysisMACD, bars))
+	}
+	
+	if len(analytics.BollingerBands.Middle) > 0 {
+		latest := len(analytics.BollingerBands.Middle) - 1
+		latestPrice := timeseries.GetLatestPrice(bts).Close
+		upper := analytics.BollingerBands.Upper[latest]
+		middle := analytics.BollingerBands.Middle[latest]
+		lower := analytics.BollingerBands.Lower[latest]
+		
+		fmt.Fprintf(&report, "Bollinger Bands - Upper: %s, Middle: %s, Lower: %s\n", denom.Price(bts.Denomination, upper), denom.Price(bts.Denomination, middle), denom.Price(bts.Denomination, lower))
+		
+		if latestPrice > upper {
+			report.WriteString("Price is above upper band (potentially overbought)\n")
+		} else if latestPrice < lower {
+			report.WriteString("Price is below lower band (potentially oversold)\n")
+		} else {
+			report.WriteString("Price is within normal range\n")
+		}
+	} else {
+		fmt.Fprintf(&report, "Bollinger Bands: %s\n", InsufficientData(AnalysisBollinger, bars))
+	}
+	report.WriteString("\n")
+	
+	// Support and resistance
+	if skipped(analytics, AnalysisLevels) {
+		report.section(SectionLevels)
+		report.WriteString("=== SUPPORT & RESISTANCE LEVELS ===\n")
+		fmt.Fprintf(&report, "%s\n\n", InsufficientData(AnalysisLevels, bars))
+	} else if len(analytics.SupportResistance.SupportLevels) > 0 || len(analytics.SupportResistance.ResistanceLevels) > 0 {
+		report.section(SectionLevels)
+		report.WriteString("=== SUPPORT & RESISTANCE LEVELS ===\n")
+		
+		if len(analytics.SupportResistance.SupportLevels) > 0 {
+			report.WriteString("Support Levels: ")
+			for i, level := range analytics.SupportResistance.SupportLevels {
+				if i > 0 {
+					report.WriteString(", ")
+				}
+				report.WriteString(denom.Price(d, level))
+			}
+			report.WriteString("\n")
+		}
+		
+		if len(analytics.SupportResistance.ResistanceLevels) > 0 {
+			report.WriteString("Resistance Levels: ")
+			for i, level := range analytics.SupportResistance.ResistanceLevels {
+				if i > 0 {
+					report.WriteString(", ")
+				}
+				report.WriteString(denom.Price(d, level))
+			}
+			report.WriteString("\n")
+		}
+		
+		if levels := analytics.SupportResistance.Levels; len(levels) > 0 {
+			report.WriteString("Strongest Levels:\n")
+			if len(levels) > 8 {
+				levels = levels[:8]
+			}
+			for _, level := range levels {
+				fmt.Fprintf(&report, "  %s %-10s score %.2f, %d touches, last %s, volume x%.2f%s\n",
+					denom.Price(d, level.Price), level.Type, level.Score, level.Touches, level.LastTouch.Format("2006-01-02"), level.TouchVolume, levelStatus(level))
+			}
+		}
+		report.WriteString("\n")
+	}
+	
+	if len(analytics.LevelMap) > 0 {
+		latestPrice := timeseries.GetLatestPrice(bts).Close
+		report.section(SectionLevels)
+		report.WriteString("=== LEVEL MAP (ranked) ===\n")
+		levels := analytics.LevelMap
+		if len(levels) > 10 {
+			levels = levels[:10]
+		}
+		for _, level := range levels {
+			fmt.Fprintf(&report, "  %s (%s) score %.2f: %s\n",
+				denom.Price(d, level.Price), numfmt.SignedPct((level.Price-latestPrice)/latestPrice), level.Score, level.Source)
+		}
+		report.WriteString("\n")
+	}
+	
+	if len(analytics.LiquidityZones) > 0 {
+		report.section(SectionLevels)
+		report.WriteString("=== LIQUIDITY ZONES (likely stop-hunt targets) ===\n")
+		for _, side := range []string{"buy-side", "sell-side"} {
+			shown := 0
+			for _, zone := range analytics.LiquidityZones {
+				if zone.Side != side || shown == 3 {
+					continue
+				}
+				fmt.Fprintf(&report, "  %-9s %s - %s (%s away): %d wicks, %d equal levels, last %s\n",
+					zone.Side, denom.Price(d, zone.Low), denom.Price(d, zone.High), numfmt.Pct(zone.DistancePct), zone.Wicks, zone.EqualLevels, zone.LastSeen.Format("2006-01-02"))
+				shown++
+			}
+		}
+		report.WriteString("\n")
+	}
+	
+	if sg := analytics.SessionGaps; sg != nil {
+		report.section(SectionLevels)
+		report.WriteString("=== SESSION GAPS ===\n")
+		for _, line := range DescribeSessionGaps(*sg, d) {
+			report.WriteString(line + "\n")
+		}
+		report.WriteString("\n")
+	}
+	
+	// Trend analysis
+	trend := patterns.DetectTrend(bts, timeseries.BarsFor(bts, 30*24*time.Hour), thresholds.TrendThreshold)
+	report.section(SectionIndicators)
+	report.WriteString("=== TREND ANALYSIS ===\n")
+	fmt.Fprintf(&report, "Structural Trend: %s\n", trend)
+	if ms := analytics.MarketStructure; ms != nil {
+		recent := ms.Swings
+		labels := ms.Labels
+		if len(recent) > 6 {
+			recent = recent[len(recent)-6:]
+			labels = labels[len(labels)-6:]
+		}
+		if len(recent) > 0 {
+			report.WriteString("Recent Swings:")
+			for i, swing := range recent {
+				label := labels[i]
+				if label == "" {
+					label = swing.Type
+				}
+				fmt.Fprintf(&report, " %s %s", label, denom.Price(d, swing.Price))
+				if i < len(recent)-1 {
+					report.WriteString(",")
+				}
+			}
+			report.WriteString("\n")
+		}
+		events := ms.Events
+		if len(events) > 3 {
+			events = events[len(events)-3:]
+		}
+		for _, event := range events {
+			fmt.Fprintf(&report, "  %s %s %s through %s\n", event.Timestamp.Format("2006-01-02"), event.Direction, event.Type, denom.Price(d, event.Level))
+		}
+	} else if skipped(analytics, AnalysisMarketStructure) {
+		fmt.Fprintf(&report, "Market Structure: %s\n", InsufficientData(AnalysisMarketStructure, bars))
+	}
+	
+	// Pattern detection
+	candlestickPatterns := patterns.DetectCandlestickPatterns(bts)
+	volumePatterns := patterns.DetectVolumePatterns(bts)
+	
+	if len(candlestickPatterns) > 0 {
+		report.section(SectionPatterns)
+		report.WriteString("\n=== RECENT CANDLESTICK PATTERNS ===\n")
+		for pattern, indices := range candlestickPatterns {
+			if len(indices) > 0 {
+				// Show only recent patterns (last 10 occurrences)
+				recent := indices
+				if len(indices) > 10 {
+					recent = indices[len(indices)-10:]
+				}
+				fmt.Fprintf(&report, "%s: %d recent occurrences\n", pattern, len(recent))
+			}
+		}
+	}
+	
+	if len(volumePatterns) > 0 {
+		report.section(SectionPatterns)
+		report.WriteString("\n=== RECENT VOLUME PATTERNS ===\n")
+		for pattern, indices := range volumePatterns {
+			if len(indices) > 0 {
+				recent := indices
+				if len(indices) > 5 {
+					recent = indices[len(indices)-5:]
+				}
+				fmt.Fprintf(&report, "%s: %d recent occurrences\n", pattern, len(recent))
+			}
+		}
+	}
+	
+	if len(analytics.PatternOutcomes) > 0 {
+		report.section(SectionBacktest)
+		report.WriteString("\n=== PATTERN OUTCOMES (win rate / avg forward return) ===\n")
+		fmt.Fprintf(&report, "%-28s %5s", "Pattern", "N")
+		for _, horizon := range analytics.PatternOutcomes[0].Outcomes {
+			fmt.Fprintf(&report, " %16s", fmt.Sprintf("%d-bar", horizon.Bars))
+		}
+		report.WriteString("\n")
+		for _, outcome := range analytics.PatternOutcomes {
+			fmt.Fprintf(&report, "%-28s %5d", outcome.Pattern+" ("+outcome.Bias+")", outcome.Occurrences)
+			for _, horizon := range outcome.Outcomes {
+				if horizon.Samples == 0 {
+					fmt.Fprintf(&report, " %16s", "-")
+					continue
+				}
+				fmt.Fprintf(&report, " %16s", numfmt.Pct(horizon.WinRate)+" / "+numfmt.SignedPct(horizon.AvgReturn))
+			}
+			report.WriteString("\n")
+		}
+	}
+	
+	// Pivot points
+	pivots := patterns.FindPivotPoints(bts)
+	if len(pivots) > 0 {
+		report.section(SectionLevels)
+		report.WriteString("\n=== PIVOT POINTS ===\n")
+		if pivot, exists := pivots["pivot"]; exists {
+			fmt.Fprintf(&report, "Pivot Point: %s\n", denom.Price(d, pivot))
+		}
+		if r1, exists := pivots["r1"]; exists {
+			fmt.Fprintf(&report, "Resistance 1: %s\n", denom.Price(d, r1))
+		}
+		if s1, exists := pivots["s1"]; exists {
+			fmt.Fprintf(&report, "Support 1: %s\n", denom.Price(d, s1))
+		}
+	}
+	
+	// Fibonacci retracements
+	fibs := patterns.CalculateFibonacciRetracements(bts, timeseries.BarsFor(bts, 30*24*time.Hour))
+	if len(fibs) > 0 {
+		report.section(SectionLevels)
+		report.WriteString("\n=== FIBONACCI RETRACEMENTS (30-day) ===\n")
+		fibLevels := []string{"high", "fib_23_6", "fib_38_2", "fib_50", "fib_61_8", "fib_76_4", "low"}
+		for _, level := range fibLevels {
+			if price, exists := fibs[level]; exists {
+				fmt.Fprintf(&report, "%s: %s\n", level, denom.Price(d, price))
+			}
+		}
+	}
+	
+	if ew := analytics.ElliottWaves; ew != nil {
+		report.section(SectionPatterns)
+		fmt.Fprintf(&report, "\n=== ELLIOTT WAVE CANDIDATES (%.0f%% swings) ===\n", ew.Threshold*100)
+		fmt.Fprintf(&report, "Swings Identified: %d\n", len(ew.Swings))
+		if len(ew.Counts) == 0 {
+			report.WriteString("No count satisfies the impulse or corrective rules\n")
+		}
+		for i, count := range ew.Counts {
+			start := count.Points[0]
+			end := count.Points[len(count.Points)-1]
+			fmt.Fprintf(&report, "%d. %s %s (confidence %.0f%%): %s %s -> %s %s\n",
+				i+1, count.Direction, count.Kind, count.Confidence*100,
+				start.Timestamp.Format("2006-01-02"), denom.Price(d, start.Price), end.Timestamp.Format("2006-01-02"), denom.Price(d, end.Price))
+			for _, note := range count.Notes {
+				fmt.Fprintf(&report, "   %s\n", note)
+			}
+		}
+	} else if skipped(analytics, AnalysisElliott) {
+		report.section(SectionPatterns)
+		report.WriteString("\n=== ELLIOTT WAVE CANDIDATES ===\n")
+		fmt.Fprintf(&report, "%s\n", InsufficientData(AnalysisElliott, bars))
+	}
+	
+	if len(analytics.Harmonics) > 0 {
+		report.section(SectionPatterns)
+		report.WriteString("\n=== HARMONIC PATTERNS ===\n")
+		recent := analytics.Harmonics
+		if len(recent) > 5 {
+			recent = recent[len(recent)-5:]
+		}
+		for _, pattern := range recent {
+			status := "pending, D not yet formed"
+			if pattern.Complete {
+				point := pattern.Points[len(pattern.Points)-1]
+				status = fmt.Sprintf("completed %s at %s", point.Timestamp.Format("2006-01-02"), denom.Price(d, point.Price))
+			}
+			fmt.Fprintf(&report, "%s %s (%s)\n", pattern.Direction, pattern.Name, status)
+			fmt.Fprintf(&report, "   PRZ: %s - %s | AB/XA %.3f, BC/AB %.3f\n",
+				denom.Price(d, pattern.PRZLow), denom.Price(d, pattern.PRZHigh), pattern.Ratios["AB/XA"], pattern.Ratios["BC/AB"])
+		}
+	}
+	
+	if wa := analytics.Wyckoff; wa != nil {
+		report.section(SectionPatterns)
+		report.WriteString("\n=== WYCKOFF ANALYSIS ===\n")
+		fmt.Fprintf(&report, "Schematic: %s\n", wa.Schematic)
+		fmt.Fprintf(&report, "Phase: %s (%s)\n", wa.Phase, patterns.WyckoffPhaseDescription(wa.Schematic, wa.Phase))
+		fmt.Fprintf(&report, "Trading Range: %s - %s (%s to %s)\n", denom.Price(d, wa.Support), denom.Price(d, wa.Resistance),
+			bts.Data[wa.RangeStart].Timestamp.Format("2006-01-02"), bts.Data[wa.RangeEnd].Timestamp.Format("2006-01-02"))
+		fmt.Fprintf(&report, "Range Volume: %s, up/down bar volume ratio %.2f\n", wa.VolumeTrend, wa.UpDownVolumeRatio)
+		recent := wa.Events
+		if len(recent) > 5 {
+			recent = recent[len(recent)-5:]
+		}
+		for _, event := range recent {
+			fmt.Fprintf(&report, "  %s %-6s %s (volume %.0f)\n", event.Timestamp.Format("2006-01-02"), event.Event, denom.Price(d, event.Price), event.Volume)
+		}
+	} else if skipped(analytics, AnalysisWyckoff) {
+		report.section(SectionPatterns)
+		report.WriteString("\n=== WYCKOFF ANALYSIS ===\n")
+		fmt.Fprintf(&report, "%s\n", InsufficientData(AnalysisWyckoff, bars))
+	}
+	
+	if analytics.OrderBook != nil {
+		ob := analytics.OrderBook
+		report.section(SectionMarket)
+		report.WriteString("\n=== ORDER BOOK SNAPSHOT ===\n")
+		fmt.Fprintf(&report, "Symbol: %s at %s\n", ob.Symbol, ob.Timestamp.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(&report, "Best Bid/Ask: %s / %s (spread %s)\n", denom.Price(nil, ob.BestBid), denom.Price(nil, ob.BestAsk), numfmt.Pct(ob.SpreadPct))
+		fmt.Fprintf(&report, "Depth within ±%.0f%%: bids %.2f, asks %.2f\n", ob.DepthBand*100, ob.BidDepth, ob.AskDepth)
+		fmt.Fprintf(&report, "Imbalance: %+.3f\n", ob.Imbalance)
+		for _, wall := range ob.BidWalls {
+			fmt.Fprintf(&report, "Bid wall: %.2f @ %s\n", wall.Quantity, denom.Price(nil, wall.Price))
+		}
+		for _, wall := range ob.AskWalls {
+			fmt.Fprintf(&report, "Ask wall: %.2f @ %s\n", wall.Quantity, denom.Price(nil, wall.Price))
+		}
+	}
+	
+	if flow := analytics.OrderFlow; flow != nil && len(flow.CVD) > 0 {
+		report.section(SectionMarket)
+		report.WriteString("\n=== ORDER FLOW (TRADE TAPE) ===\n")
+		buyTotal, sellTotal := 0.0, 0.0
+		for i := range flow.BuyVolume {
+			buyTotal += flow.BuyVolume[i]
+			sellTotal += flow.SellVolume[i]
+		}
+		fmt.Fprintf(&report, "Aggressive Buy Volume: %.2f\n", buyTotal)
+		fmt.Fprintf(&report, "Aggressive Sell Volume: %.2f\n", sellTotal)
+		fmt.Fprintf(&report, "Latest Candle Delta: %+.2f\n", flow.Delta[len(flow.Delta)-1])
+		fmt.Fprintf(&report, "Cumulative Volume Delta: %+.2f\n", flow.CVD[len(flow.CVD)-1])
+		fmt.Fprintf(&report, "Large Trades (>= %.2f): %d\n", flow.Threshold, len(flow.LargeTrades))
+		recent := flow.LargeTrades
+		if len(recent) > 5 {
+			recent = recent[len(recent)-5:]
+		}
+		for _, trade := range recent {
+			fmt.Fprintf(&report, "  %s %s %.2f @ %s\n", trade.Timestamp.Format("2006-01-02 15:04:05"), trade.Side, trade.Quantity, denom.Price(nil, trade.Price))
+		}
+	}
+	
+	if va := analytics.ImpliedVol; va != nil {
+		report.section(SectionRisk)
+		report.WriteString("\n=== IMPLIED vs REALIZED VOLATILITY ===\n")
+		fmt.Fprintf(&report, "Implied Volatility (DVOL): %s\n", numfmt.Pct(va.ImpliedVol))
+		fmt.Fprintf(&report, "Realized Volatility (%d-day): %s\n", va.WindowDays, numfmt.Pct(va.RealizedVol))
+		fmt.Fprintf(&report, "IV/RV Ratio: %.2f\n", va.Ratio)
+		if len(va.TermStructure) > 0 {
+			report.WriteString("Term Structure (ATM IV):\n")
+			for _, point := range va.TermStructure {
+				fmt.Fprintf(&report, "  %s (%5.1f days): %s\n", point.Expiry.Format("2006-01-02"), point.Days, numfmt.Pct(point.ATMIV))
+			}
+			front, back := va.TermStructure[0], va.TermStructure[len(va.TermStructure)-1]
+			shape := "contango (back month above front)"
+			if front.ATMIV > back.ATMIV {
+				shape = "backwardation (front month above back)"
+			}
+			fmt.Fprintf(&report, "Shape: %s\n", shape)
+		}
+	}
+	
+	if pa := analytics.Premium; pa != nil && len(pa.Points) > 0 {
+		latest := pa.Points[len(pa.Points)-1]
+		report.section(SectionRelative)
+		fmt.Fprintf(&report, "\n=== PREMIUM / DISCOUNT vs %s ===\n", pa.Reference)
+		fmt.Fprintf(&report, "Latest: %s (%s vs %s), z-score %+.2f\n", numfmt.SignedPct(latest.Premium), denom.Price(d, latest.Price), denom.Price(d, latest.Reference), latest.ZScore)
+		fmt.Fprintf(&report, "Average: %s (std dev %s) over %d bars\n", numfmt.SignedPct(pa.Mean), numfmt.Pct(pa.StdDev), len(pa.Points))
+		fmt.Fprintf(&report, "Extreme episodes (|z| >= %.1f over %d bars): %d\n", pa.Threshold, pa.Window, len(pa.Alerts))
+		recent := pa.Alerts
+		if len(recent) > 5 {
+			recent = recent[len(recent)-5:]
+		}
+		for _, alert := range recent {
+			kind := "premium"
+			if alert.ZScore < 0 {
+				kind = "discount"
+			}
+			fmt.Fprintf(&report, "  %s %s %s (z %+.2f)\n", alert.Timestamp.Format("2006-01-02 15:04"), kind, numfmt.SignedPct(alert.Premium), alert.ZScore)
+		}
+	}
+	
+	if ba := analytics.Beta; ba != nil {
+		report.section(SectionRelative)
+		fmt.Fprintf(&report, "\n=== BETA vs %s ===\n", ba.Benchmark)
+		fmt.Fprintf(&report, "Beta: %.3f, alpha %s/yr over %d aligned returns (%.0f per year)\n", ba.Beta, numfmt.SignedPct(ba.Alpha), ba.Observations, ba.PeriodsPerYear)
+		fmt.Fprintf(&report, "Correlation: %.3f (R² %.3f)\n", ba.Correlation, ba.RSquared)
+		if len(ba.Rolling) > 0 {
+			latest := ba.Rolling[len(ba.Rolling)-1]
+			low, high := latest.Beta, latest.Beta
+			for _, point := range ba.Rolling {
+				low, high = math.Min(low, point.Beta), math.Max(high, point.Beta)
+			}
+			fmt.Fprintf(&report, "Rolling %d-return beta: latest %.3f (alpha %s/yr), range %.3f to %.3f\n", ba.Window, latest.Beta, numfmt.SignedPct(latest.Alpha), low, high)
+		}
+	}
+	
+	if pa := analytics.Pair; pa != nil {
+		report.section(SectionRelative)
+		fmt.Fprintf(&report, "\n=== PAIR vs %s ===\n", pa.Pair)
+		for _, line := range DescribePair(pa) {
+			report.WriteString(line + "\n")
+		}
+	}
+	
+	if len(analytics.Custom) > 0 {
+		report.section(SectionIndicators)
+		report.WriteString("\n=== CUSTOM INDICATORS ===\n")
+		for _, ci := range analytics.Custom {
+			fmt.Fprintf(&report, "%s = %s\n", ci.Name, ci.Source)
+			if len(ci.Values) > 0 {
+				fmt.Fprintf(&report, "  Latest: %.4f (from bar %d)\n", ci.Values[len(ci.Values)-1], ci.Offset)
+			}
+			if ci.Signal != "" {
+				fmt.Fprintf(&report, "  Signal: %s (%d buy, %d sell triggers)\n", ci.Signal, len(ci.Buys), len(ci.Sells))
+			}
+		}
+	}
+	
+	if vf := analytics.VolumeForensics; vf != nil && vf.Samples > 0 {
+		report.section(SectionMarket)
+		report.WriteString("\n=== VOLUME FORENSICS ===\n")
+		fmt.Fprintf(&report, "Benford's Law: %s (MAD %.4f, chi-square %.1f over %d volumes)\n",
+			vf.BenfordConformity, vf.BenfordMAD, vf.BenfordChiSquare, vf.Samples)
+		report.WriteString("Leading digits:")
+		for d, share := range vf.BenfordObserved {
+			fmt.Fprintf(&report, " %d:%s", d+1, numfmt.Pct(share))
+		}
+		report.WriteString("\n")
+		fmt.Fprintf(&report, "Round-number volumes: %s\n", numfmt.Pct(vf.RoundShare))
+		fmt.Fprintf(&report, "Suspicious periods: %d\n", len(vf.SuspiciousPeriods))
+		recent := vf.SuspiciousPeriods
+		if len(recent) > 5 {
+			recent = recent[len(recent)-5:]
+		}
+		for _, period := range recent {
+			fmt.Fprintf(&report, "  %s to %s: %s\n", period.Start.Format("2006-01-02 15:04"),
+				period.End.Format("2006-01-02 15:04"), strings.Join(period.Reasons, "; "))
+		}
+	} else if skipped(analytics, AnalysisVolume) {
+		report.section(SectionMarket)
+		report.WriteString("\n=== VOLUME FORENSICS ===\n")
+		fmt.Fprintf(&report, "%s\n", InsufficientData(AnalysisVolume, bars))
+	}
+	
+	if le := analytics.Liquidity; le != nil {
+		report.section(SectionMarket)
+		report.WriteString("\n=== LIQUIDITY (OHLCV ESTIMATES) ===\n")
+		for _, line := range DescribeLiquidity(*le, analytics.OrderBook) {
+			fmt.Fprintf(&report, "%s\n", line)
+		}
+	}
+	
+	report.section(SectionStatistics)
+	if analytics.SearchInterest != nil {
+		report.WriteString(formatLeadLag("SEARCH INTEREST LEAD/LAG", analytics.SearchInterest))
+	}
+	
+	if analytics.Dominance != nil {
+		report.WriteString(formatLeadLag("BTC DOMINANCE LEAD/LAG", analytics.Dominance))
+	}
+	
+	if analytics.Stablecoins != nil {
+		report.WriteString(formatLeadLag("STABLECOIN MARKET CAP LEAD/LAG", analytics.Stablecoins))
+	}
+	
+	for i := range analytics.ReturnDrivers {
+		report.WriteString(formatLeadLag("VOLUME/VOLATILITY LEAD/LAG", &analytics.ReturnDrivers[i]))
+	}
+	if skipped(analytics, AnalysisReturnDrivers) {
+		report.WriteString("\n=== VOLUME/VOLATILITY LEAD/LAG ===\n")
+		fmt.Fprintf(&report, "%s\n", InsufficientData(AnalysisReturnDrivers, bars))
+	}
+	
+	report.section(SectionSignals)
+	report.WriteString("\n=== SIGNAL THRESHOLDS ===\n")
+	fmt.Fprintf(&report, "%s\n", DescribeSignalConfig(thresholds))
+	
+	report.section("")
+	if len(analytics.Skipped) > 0 {
+		report.WriteString("\n=== SKIPPED ANALYSES ===\n")
+		for _, skip := range analytics.Skipped {
+			fmt.Fprintf(&report, "- %s: insufficient data (needs %d bars, have %d)\n", skip.Analysis, skip.Required, skip.Available)
+		}
+	}
+	
+	if len(result.Metadata.Errors) > 0 {
+		report.WriteString("\n=== ERRORS ===\n")
+		for _, err := range result.Metadata.Errors {
+			fmt.Fprintf(&report, "- %s\n", err)
+		}
+	}
+	
+	if len(result.Metadata.Warnings) > 0 {
+		report.WriteString("\n=== WARNINGS ===\n")
+		for _, warning := range result.Metadata.Warnings {
+			fmt.Fprintf(&report, "- %s\n", warning)
+		}
+	}
+	
+	report.WriteString("\n=== END OF REPORT ===\n")
+	fmt.Fprintf(&report, "Generated at: %s by btc-analyzer %s\n", time.Now().Format("2006-01-02 15:04:05"), result.Metadata.Version)
+	
+	return report.String(rc)
+}
+
+// levelStatus describes whether a level was broken and retested
+func levelStatus(level types.SRLevel) string {
+	switch {
+	case level.Retested:
+		return fmt.Sprintf(", broken %s and retested %s", level.BrokenAt.Format("2006-01-02"), level.RetestedAt.Format("2006-01-02"))
+	case level.Broken:
+		return fmt.Sprintf(", broken %s", level.BrokenAt.Format("2006-01-02"))
+	}
+	return ""
+}
+
+// collectPatternOccurrences gathers the bar indices of every candlestick,
+// volume, harmonic and structure pattern along with each pattern's bias
+func collectPatternOccurrences(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) (map[string][]int, map[string]string) {
+	occurrences := make(map[string][]int)
+	bias := make(map[string]string)
+	
+	for name, indices := range patterns.DetectCandlestickPatterns(bts) {
+		occurrences[name] = indices
+		bias[name] = patterns.PatternBias[name]
+	}
+	for name, indices := range patterns.DetectVolumePatterns(bts) {
+		occurrences[name] = indices
+		bias[name] = patterns.PatternBias[name]
+	}
+	
+	for _, harmonic := range analytics.Harmonics {
+		if !harmonic.Complete {
+			continue
+		}
+		name := fmt.Sprintf("%s_%s", harmonic.Direction, harmonic.Name)
+		occurrences[name] = append(occurrences[name], harmonic.Points[len(harmonic.Points)-1].Index)
+		bias[name] = harmonic.Direction
+	}
+	
+	if analytics.MarketStructure != nil {
+		for _, event := range analytics.MarketStructure.Events {
+			name := fmt.Sprintf("%s_%s", event.Direction, event.Type)
+			occurrences[name] = append(occurrences[name], event.Index)
+			bias[name] = event.Direction
+		}
+	}
+	
+	return occurrences, bias
+}
+
+// GetTradingSignals analyzes data and provides trading signals with the
+// thresholds of sc
+func GetTradingSignals(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, sc types.SignalConfig) map[string]string {
+	signals := make(map[string]string)
+	
+	// RSI signals
+	if len(analytics.RSI) > 0 {
+		latestRSI := analytics.RSI[len(analytics.RSI)-1]
+		if latestRSI > sc.RSIOverbought {
+			signals["RSI"] = "SELL - Overbought"
+		} else if latestRSI < sc.RSIOversold {
+			signals["RSI"] = "BUY - Oversold"
+		} else {
+			signals["RSI"] = "HOLD - Neutral"
+		}
+	}
+	
+	// MACD signals
+	if len(analytics.MACD.MACD) > 1 && len(analytics.MACD.Signal) > 1 {
+		latestMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
+		prevMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-2]
+		latestSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-1]
+		prevSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-2]
+		
+		// Check for crossovers
+		if prevMACD <= prevSignal && latestMACD > latestSignal {
+			signals["MACD"] = "BUY - Bullish crossover"
+		} else if prevMACD >= prevSignal && latestMACD < latestSignal {
+			signals["MACD"] = "SELL - Bearish crossover"
+		} else if latestMACD > latestSignal {
+			signals["MACD"] = "HOLD - Bullish"
+		} else {
+			signals["MACD"] = "HOLD - Bearish"
+		}
+	}
+	
+	// Bollinger Bands signals
+	if len(analytics.BollingerBands.Upper) > 0 {
+		latestPrice := timeseries.GetLatestPrice(bts).Close
+		latest := len(analytics.BollingerBands.Upper) - 1
+		upper := analytics.BollingerBands.Upper[latest]
+		lower := analytics.BollingerBands.Lower[latest]
+		
+		if latestPrice > upper {
+			signals["Bollinger"] = "SELL - Price above upper band"
+		} else if latestPrice < lower {
+			signals["Bollinger"] = "BUY - Price below lower band"
+		} else {
+			signals["Bollinger"] = "HOLD - Price in normal range"
+		}
+	}
+	
+	// Trend signals
+	trend := patterns.DetectTrend(bts, timeseries.BarsFor(bts, 30*24*time.Hour), sc.TrendThreshold)
+	switch trend {
+	case "uptrend":
+		signals["Trend"] = "BUY - Bullish market structure"
+	case "downtrend":
+		signals["Trend"] = "SELL - Bearish market structure"
+	default:
+		signals["Trend"] = "HOLD - No structure break yet"
+	}
+	
+	// Support/Resistance signals from the strongest nearby levels of the level map
+	if len(analytics.LevelMap) > 0 {
+		latestPrice := timeseries.GetLatestPrice(bts).Close
+		
+		for _, level := range analytics.LevelMap {
+			if math.Abs(latestPrice-level.Price)/level.Price >= sc.LevelProximity {
+				continue
+			}
+			if level.Price <= latestPrice {
+				if _, exists := signals["Support"]; !exists {
+					signals["Support"] = fmt.Sprintf("BUY - Near support %s (%s, score %.2f)", denom.Price(bts.Denomination, level.Price), level.Source, level.Score)
+				}
+			} else if _, exists := signals["Resistance"]; !exists {
+				signals["Resistance"] = fmt.Sprintf("SELL - Near resistance %s (%s, score %.2f)", denom.Price(bts.Denomination, level.Price), level.Source, level.Score)
+			}
+		}
+	} else if len(analytics.SupportResistance.SupportLevels) > 0 || len(analytics.SupportResistance.ResistanceLevels) > 0 {
+		latestPrice := timeseries.GetLatestPrice(bts).Close
+		
+		// Check if price is near support (buy signal)
+		for _, support := range analytics.SupportResistance.SupportLevels {
+			if math.Abs(latestPrice-support)/support < sc.LevelProximity {
+				signals["Support"] = "BUY - Near support level"
+				break
+			}
+		}
+		
+		// Check if price is near resistance (sell signal)
+		for _, resistance := range analytics.SupportResistance.ResistanceLevels {
+			if math.Abs(latestPrice-resistance)/resistance < sc.LevelProximity {
+				signals["Resistance"] = "SELL - Near resistance level"
+				break
+			}
+		}
+	}
+	
+	// Round-number signal when price approaches a watched round number
+	if latestPrice := timeseries.GetLatestPrice(bts).Close; latestPrice > 0 {
+		if round, ok := patterns.NearestRoundLevel(latestPrice); ok {
+			distance := math.Abs(latestPrice-round.Price) / latestPrice
+			if distance < sc.RoundNumberProximity {
+				side := "below, likely resistance"
+				if latestPrice > round.Price {
+					side = "above, likely support"
+				}
+				signals["RoundNumber"] = fmt.Sprintf("HOLD - Approaching round number %s from %s (%s away)",
+					denom.Price(bts.Denomination, round.Price), side, numfmt.Pct(distance))
+			}
+		}
+	}
+	
+	// Order book imbalance signal
+	if analytics.OrderBook != nil {
+		signals["OrderBook"] = orderbook.ImbalanceSignal(*analytics.OrderBook)
+	}
+	
+	// Cumulative volume delta signal
+	if analytics.OrderFlow != nil {
+		signals["OrderFlow"] = orderflow.CVDSignal(bts, analytics.OrderFlow, 5)
+	}
+	
+	if analytics.ImpliedVol != nil {
+		signals["IV/RV"] = ImpliedVolSignal(analytics.ImpliedVol)
+	}
+	
+	if analytics.Premium != nil && len(analytics.Premium.Points) > 0 {
+		signals["Premium"] = comparison.PremiumSignal(analytics.Premium)
+	}
+	
+	// Plugin indicators with trading rules
+	for _, ci := range analytics.Custom {
+		if ci.Signal != "" {
+			signals["Custom: "+ci.Name] = ci.Signal
+		}
+	}
+	
+	return signals
+}
+
+// SignalScore condenses trading signals into a composite score from -1 (all
+// SELL) to +1 (all BUY); HOLD signals count as neutral
+func SignalScore(signals map[string]string) float64 {
+	if len(signals) == 0 {
+		return 0
+	}
+	score := 0.0
+	for _, signal := range signals {
+		switch {
+		case strings.HasPrefix(signal, "BUY"):
+			score++
+		case strings.HasPrefix(signal, "SELL"):
+			score--
+		}
+	}
+	return score / float64(len(signals))
+}
+
+// CalculatePortfolioMetrics calculates portfolio-level metrics under the given risk conventions
+func CalculatePortfolioMetrics(bts *types.BTCTimeSeries, rc types.RiskConfig, initialInvestment float64) map[string]interface{} {
+	metrics := make(map[string]interface{})
+	
+	if len(bts.Data) < 2 {
+		return metrics
+	}
+	
+	// Basic portfolio metrics
+	backtest := statistics.PerformBacktest(bts, initialInvestment)
+	for key, value := range backtest {
+		metrics[key] = value
+	}
+	
+	// Risk metrics
+	riskMetrics := statistics.GetRiskMetrics(bts, rc)
+	for key, value := range riskMetrics {
+		metrics[key] = value
+	}
+	
+	// Performance ratios
+	if volatility, exists := riskMetrics["volatility_annual"]; exists && volatility > 0 {
+		if totalReturn, exists := backtest["annualized_return"]; exists {
+			metrics["information_ratio"] = totalReturn / volatility
+		}
+	}
+	
+	return metrics
+}
+// AnalyzeLeadLag aligns an auxiliary series with prices and cross-correlates
+// its period-over-period changes with returns over the same periods
+func AnalyzeLeadLag(bts *types.BTCTimeSeries, aux *types.AuxSeries, maxLag int) *types.LeadLagAnalysis {
+	prices, values := timeseries.AlignAux(bts, aux)
+	if len(prices) < 3 {
+		return nil
+	}
+
+	returns := make([]float64, 0, len(prices)-1)
+	changes := make([]float64, 0, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] <= 0 {
+			continue
+		}
+		returns = append(returns, (prices[i]-prices[i-1])/prices[i-1])
+		changes = append(changes, values[i]-values[i-1])
+	}
+
+	return crossCorrelate(aux.Name, "", changes, returns, maxLag)
+}
+
+// formatLeadLag renders a lead/lag analysis as a report section
+func formatLeadLag(title string, leadLag *types.LeadLagAnalysis) string {
+	target := "returns"
+	report := fmt.Sprintf("\n=== %s ===\n", title)
+	if leadLag.Target != "" {
+		target = leadLag.Target
+		report += fmt.Sprintf("Series: %s vs %s (%d aligned periods)\n", leadLag.Series, target, leadLag.Observations)
+	} else {
+		report += fmt.Sprintf("Series: %s (%d aligned periods)\n", leadLag.Series, leadLag.Observations)
+	}
+	for _, lc := range leadLag.Correlations {
+		marker := ""
+		if leadLag.ConfidenceBand > 0 && math.Abs(lc.Correlation) > leadLag.ConfidenceBand {
+			marker = " *"
+		}
+		report += fmt.Sprintf("Lag %+d: %+.3f%s\n", lc.Lag, lc.Correlation, marker)
+	}
+	if leadLag.ConfidenceBand > 0 {
+		report += fmt.Sprintf("* outside the 95%% confidence band of ±%.3f\n", leadLag.ConfidenceBand)
+	}
+
+	switch {
+	case leadLag.BestLag > 0:
+		report += fmt.Sprintf("Strongest relationship: %s leads %s by %d period(s) (r = %.3f)\n",
+			leadLag.Series, target, leadLag.BestLag, leadLag.BestCorrelation)
+	case leadLag.BestLag < 0:
+		report += fmt.Sprintf("Strongest relationship: %s lead %s by %d period(s) (r = %.3f)\n",
+			target, leadLag.Series, -leadLag.BestLag, leadLag.BestCorrelation)
+	default:
+		report += fmt.Sprintf("Strongest relationship: contemporaneous (r = %.3f)\n", leadLag.BestCorrelation)
+	}
+
+	return report
+}
@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"time"
+)
+
+// Sources of the all-time high
+const (
+	ATHSourceData      = "data"
+	ATHSourceCoinGecko = "coingecko"
+	ATHSourceManual    = "manual"
+)
+
+// minSimilarDrawdown is the shallowest current drawdown compared with past
+// ones; shallower dips are matched by nearly every wiggle of the price
+const minSimilarDrawdown = 0.05
+
+// TrackAllTimeExtremes finds the highest and lowest closes of bts and how
+// far the latest close sits from them. historical is a known all-time high
+// from outside the data, labeled source; it replaces the high of the data
+// when it is higher. Past drawdowns of the data at least as deep as the
+// current one, if that is at least minSimilarDrawdown, are listed with the
+// days they took to regain their peak from the current depth. Returns nil
+// for an empty series.
+func TrackAllTimeExtremes(bts *types.BTCTimeSeries, historical *types.AuxPoint, source string) *types.AllTimeExtremes {
+	if len(bts.Data) == 0 {
+		return nil
+	}
+	latest := bts.Data[len(bts.Data)-1]
+	ate := &types.AllTimeExtremes{HighSource: ATHSourceData}
+	for i, bar := range bts.Data {
+		if i == 0 || bar.Close > ate.DataHigh {
+			ate.DataHigh, ate.DataHighAt = bar.Close, bar.Timestamp
+		}
+		if i == 0 || bar.Close < ate.Low {
+			ate.Low, ate.LowAt = bar.Close, bar.Timestamp
+		}
+	}
+	ate.High, ate.HighAt = ate.DataHigh, ate.DataHighAt
+	if historical != nil && historical.Value > ate.DataHigh {
+		ate.High, ate.HighAt, ate.HighSource = historical.Value, historical.Timestamp, source
+	}
+
+	if ate.High > 0 {
+		ate.Drawdown = 1 - latest.Close/ate.High
+	}
+	if ate.Low > 0 {
+		ate.AboveLow = latest.Close/ate.Low - 1
+	}
+	if !ate.HighAt.IsZero() {
+		ate.DaysSinceHigh = daysBetween(ate.HighAt, latest.Timestamp)
+	}
+	ate.DaysSinceLow = daysBetween(ate.LowAt, latest.Timestamp)
+
+	if ate.Drawdown >= minSimilarDrawdown {
+		ate.SimilarDrawdowns = drawdownEpisodes(bts, ate.Drawdown)
+		var days []float64
+		for _, episode := range ate.SimilarDrawdowns {
+			days = append(days, episode.DaysToRecover)
+		}
+		ate.MedianRecoveryDays = statistics.Quantile(days, 0.5)
+	}
+	return ate
+}
+
+// drawdownEpisodes returns the recovered falls below a running peak close
+// that reached depth, with the days from first reaching it to regaining the
+// peak. The ongoing drawdown at the end of the series is left out.
+func drawdownEpisodes(bts *types.BTCTimeSeries, depth float64) []types.DrawdownEpisode {
+	var episodes []types.DrawdownEpisode
+	var current types.DrawdownEpisode
+	peak := bts.Data[0]
+	for _, bar := range bts.Data[1:] {
+		if bar.Close >= peak.Close {
+			if current.Depth >= depth {
+				current.Recovered = true
+				current.RecoveredAt = bar.Timestamp
+				current.DaysToRecover = daysBetween(current.Reached, bar.Timestamp)
+				episodes = append(episodes, current)
+			}
+			current = types.DrawdownEpisode{}
+			peak = bar
+			continue
+		}
+		fall := 1 - bar.Close/peak.Close
+		if current.Peak.IsZero() {
+			current = types.DrawdownEpisode{Peak: peak.Timestamp, PeakPrice: peak.Close}
+		}
+		if fall > current.Depth {
+			current.Depth, current.Trough = fall, bar.Timestamp
+		}
+		if fall >= depth && current.Reached.IsZero() {
+			current.Reached = bar.Timestamp
+		}
+	}
+	return episodes
+}
+
+// DescribeAllTimeExtremes formats the all-time high and low and the current
+// drawdown on one line each, for the text and HTML reports. Prices are
+// formatted in denomination d.
+func DescribeAllTimeExtremes(ate types.AllTimeExtremes, d *types.Denomination) []string {
+	high := fmt.Sprintf("All-Time High: %s", denom.Price(d, ate.High))
+	if !ate.HighAt.IsZero() {
+		high += fmt.Sprintf(" on %s, %.0f days ago", ate.HighAt.Format("2006-01-02"), ate.DaysSinceHigh)
+	}
+	if ate.HighSource != ATHSourceData {
+		high += fmt.Sprintf(" (%s; the loaded data peaks at %s on %s)", ate.HighSource, denom.Price(d, ate.DataHigh), ate.DataHighAt.Format("2006-01-02"))
+	}
+	lines := []string{
+		high,
+		fmt.Sprintf("Low of the Data: %s on %s, %.0f days ago", denom.Price(d, ate.Low), ate.LowAt.Format("2006-01-02"), ate.DaysSinceLow),
+	}
+	if ate.Drawdown <= 0 {
+		lines = append(lines, "Price is at its all-time high")
+	} else {
+		lines = append(lines, fmt.Sprintf("Drawdown from ATH: %s, %s above the low", numfmt.Pct(ate.Drawdown), numfmt.Pct(ate.AboveLow)))
+	}
+
+	switch {
+	case ate.Drawdown < minSimilarDrawdown:
+	case len(ate.SimilarDrawdowns) == 0:
+		lines = append(lines, fmt.Sprintf("No earlier drawdown of %s or more in the data has recovered", numfmt.Pct(ate.Drawdown)))
+	default:
+		longest := 0.0
+		for _, episode := range ate.SimilarDrawdowns {
+			if episode.DaysToRecover > longest {
+				longest = episode.DaysToRecover
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Earlier drawdowns of %s or more: %d, recovered in a median %.0f days from that depth (longest %.0f)",
+			numfmt.Pct(ate.Drawdown), len(ate.SimilarDrawdowns), ate.MedianRecoveryDays, longest))
+	}
+	return lines
+}
+
+// daysBetween returns the days from start to end
+func daysBetween(start, end time.Time) float64 {
+	return end.Sub(start).Hours() / 24
+}
@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// minGap is the smallest move from a close to the next open counted as an
+// overnight return rather than rounding of the data
+const minGap = 1e-6
+
+// AnalyzeReturnAttribution decomposes the log returns of bts into the gaps
+// from a close to the next open and the moves from each open to its close, and
+// buckets the close-to-close returns by the UTC hour and weekday of the bar's
+// open. Hours are bucketed for bars of an hour or less and weekdays for bars
+// of a day or less. Returns nil for fewer than two bars with prices.
+func AnalyzeReturnAttribution(bts *types.BTCTimeSeries) *types.ReturnAttribution {
+	interval := timeseries.DetectFrequency(bts).Interval
+	var overnight, intraday, all []float64
+	hours := make([][]float64, 24)
+	weekdays := make([][]float64, 7)
+	// days on which each bucket had bars, so a bucket's move in the
+	// average cycle is its returns over its days
+	hourDays, weekdayDays := newDayCounter(24), newDayCounter(7)
+	for i := 1; i < len(bts.Data); i++ {
+		prev, bar := bts.Data[i-1], bts.Data[i]
+		if prev.Close <= 0 || bar.Close <= 0 {
+			continue
+		}
+		r := math.Log(bar.Close / prev.Close)
+		all = append(all, r)
+		if bar.Open > 0 {
+			if gap := math.Log(bar.Open / prev.Close); math.Abs(gap) > minGap {
+				overnight = append(overnight, gap)
+			}
+			intraday = append(intraday, math.Log(bar.Close/bar.Open))
+		}
+		open := bar.Timestamp.UTC()
+		if interval > 0 && interval <= time.Hour {
+			hours[open.Hour()] = append(hours[open.Hour()], r)
+			hourDays.add(open.Hour(), open)
+		}
+		if interval > 0 && interval <= 24*time.Hour {
+			day := (int(open.Weekday()) + 6) % 7
+			weekdays[day] = append(weekdays[day], r)
+			weekdayDays.add(day, open)
+		}
+	}
+	if len(all) < 2 {
+		return nil
+	}
+
+	total := 0.0
+	for _, r := range all {
+		total += r
+	}
+	ra := &types.ReturnAttribution{Bars: len(all), TotalReturn: math.Expm1(total)}
+	if len(overnight) > 0 {
+		on := attributionBucket("Overnight", overnight, total)
+		in := attributionBucket("Intraday", intraday, total)
+		ra.Overnight, ra.Intraday = &on, &in
+	}
+	var hourMoves, weekdayMoves []float64
+	for hour, returns := range hours {
+		if len(returns) > 0 {
+			bucket := attributionBucket(fmt.Sprintf("%02d:00", hour), returns, total)
+			ra.Hours = append(ra.Hours, bucket)
+			hourMoves = append(hourMoves, math.Log1p(bucket.TotalReturn)/float64(hourDays.days[hour]))
+		}
+	}
+	for day, returns := range weekdays {
+		if len(returns) > 0 {
+			bucket := attributionBucket(time.Weekday((day+1)%7).String(), returns, total)
+			ra.Weekdays = append(ra.Weekdays, bucket)
+			weekdayMoves = append(weekdayMoves, math.Log1p(bucket.TotalReturn)/float64(weekdayDays.days[day]))
+		}
+	}
+	ra.CheapestHour, ra.CheapestHourDiscount = cheapestBucket(ra.Hours, hourMoves)
+	ra.CheapestWeekday, ra.CheapestWeekdayDiscount = cheapestBucket(ra.Weekdays, weekdayMoves)
+	return ra
+}
+
+// dayCounter counts the distinct days on which each bucket has bars, which
+// arrive in time order
+type dayCounter struct {
+	days []int
+	last []time.Time
+}
+
+func newDayCounter(buckets int) *dayCounter {
+	return &dayCounter{days: make([]int, buckets), last: make([]time.Time, buckets)}
+}
+
+// add counts the day of t for bucket unless it was counted last
+func (dc *dayCounter) add(bucket int, t time.Time) {
+	day := t.Truncate(24 * time.Hour)
+	if !day.Equal(dc.last[bucket]) {
+		dc.days[bucket]++
+		dc.last[bucket] = day
+	}
+}
+
+// attributionBucket summarizes returns under label, with their share of the
+// summed log returns total
+func attributionBucket(label string, returns []float64, total float64) types.AttributionBucket {
+	stats := statistics.Calculate(returns)
+	bucket := types.AttributionBucket{Label: label, Bars: len(returns), MeanReturn: stats.Mean}
+	sum, wins := 0.0, 0
+	for _, r := range returns {
+		sum += r
+		if r > 0 {
+			wins++
+		}
+	}
+	bucket.TotalReturn = math.Expm1(sum)
+	if total != 0 {
+		bucket.Share = sum / total
+	}
+	bucket.WinRate = float64(wins) / float64(len(returns))
+	if stats.StdDev > 0 && len(returns) > 1 {
+		bucket.TStat = stats.Mean / (stats.StdDev / math.Sqrt(float64(len(returns))))
+	}
+	return bucket
+}
+
+// cheapestBucket walks the average cycle of buckets, hours of a day or days
+// of a week, each moving the log price by its entry in moves, removes the
+// cycle's drift and returns the bucket at whose start the price is lowest
+// with its discount to the cycle's average. Empty with fewer than two
+// buckets.
+func cheapestBucket(buckets []types.AttributionBucket, moves []float64) (string, float64) {
+	if len(buckets) < 2 {
+		return "", 0
+	}
+	path := make([]float64, len(buckets))
+	drift := 0.0
+	for i, move := range moves {
+		path[i] = drift
+		drift += move
+	}
+	mean := 0.0
+	for i := range path {
+		path[i] -= drift * float64(i) / float64(len(buckets))
+		mean += path[i] / float64(len(path))
+	}
+	cheapest := 0
+	for i, p := range path {
+		if p < path[cheapest] {
+			cheapest = i
+		}
+	}
+	return buckets[cheapest].Label, math.Expm1(path[cheapest] - mean)
+}
+
+// DescribeReturnAttribution formats where the returns of a series accrue:
+// overnight against intraday, the strongest and weakest hours, every
+// weekday, and the hour and weekday the average cycle is cheapest at, as a
+// hint for scheduling DCA buys, for the reports
+func DescribeReturnAttribution(ra types.ReturnAttribution) []string {
+	lines := []string{fmt.Sprintf("Total return %s over %d bars (UTC buckets; shares are of the summed log returns)", numfmt.SignedPct(ra.TotalReturn), ra.Bars)}
+	if ra.Overnight != nil {
+		lines = append(lines, describeBucket("Overnight (close to next open)", *ra.Overnight), describeBucket("Intraday (open to close)", *ra.Intraday))
+	} else {
+		lines = append(lines, "Overnight: none, every bar opens at the previous close, so all returns accrue intraday")
+	}
+
+	if len(ra.Hours) > 0 {
+		ranked := append([]types.AttributionBucket(nil), ra.Hours...)
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].MeanReturn > ranked[j].MeanReturn })
+		shown := 3
+		if len(ranked) < 2*shown {
+			shown = len(ranked) / 2
+		}
+		var strong, weak []string
+		for i := 0; i < shown; i++ {
+			strong = append(strong, fmt.Sprintf("%s %s (t %.1f)", ranked[i].Label, numfmt.SignedPct(ranked[i].MeanReturn), ranked[i].TStat))
+			last := ranked[len(ranked)-1-i]
+			weak = append(weak, fmt.Sprintf("%s %s (t %.1f)", last.Label, numfmt.SignedPct(last.MeanReturn), last.TStat))
+		}
+		if shown > 0 {
+			lines = append(lines, "Strongest hours: "+strings.Join(strong, ", "), "Weakest hours: "+strings.Join(weak, ", "))
+		}
+	}
+	for _, day := range ra.Weekdays {
+		lines = append(lines, describeBucket(day.Label, day))
+	}
+
+	if ra.CheapestHour != "" {
+		lines = append(lines, fmt.Sprintf("DCA timing: the average day, net of its drift, is cheapest at %s UTC, %s below its average",
+			ra.CheapestHour, numfmt.Pct(-ra.CheapestHourDiscount)))
+	}
+	if ra.CheapestWeekday != "" {
+		lines = append(lines, fmt.Sprintf("DCA timing: the average week, net of its drift, is cheapest at the open on %s, %s below its average",
+			ra.CheapestWeekday, numfmt.Pct(-ra.CheapestWeekdayDiscount)))
+	}
+	significant := false
+	for _, buckets := range [][]types.AttributionBucket{ra.Hours, ra.Weekdays} {
+		for _, bucket := range buckets {
+			significant = significant || math.Abs(bucket.TStat) >= 2
+		}
+	}
+	if (len(ra.Hours) > 0 || len(ra.Weekdays) > 0) && !significant {
+		lines = append(lines, "No hour or weekday has a mean return significant at |t| ≥ 2, so the timing differences may be noise")
+	}
+	return lines
+}
+
+// describeBucket formats one bucket under name
+func describeBucket(name string, bucket types.AttributionBucket) string {
+	return fmt.Sprintf("%s: %s over %d bars, %s per bar (t %.1f), %s up, %s of the total",
+		name, numfmt.SignedPct(bucket.TotalReturn), bucket.Bars, numfmt.SignedPct(bucket.MeanReturn), bucket.TStat, numfmt.Pct(bucket.WinRate), numfmt.Pct(bucket.Share))
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// Analyses of PerformComprehensiveAnalysis with a minimum series length
+const (
+	AnalysisStatistics      = "Price statistics and risk metrics"
+	AnalysisRSI             = "RSI (14)"
+	AnalysisROC             = "ROC (12)"
+	AnalysisMACD            = "MACD (12/26/9)"
+	AnalysisBollinger       = "Bollinger Bands (20, 2)"
+	AnalysisLevels          = "Support/resistance, level map, liquidity zones and session gaps"
+	AnalysisMarketStructure = "Market structure"
+	AnalysisWyckoff         = "Wyckoff phases"
+	AnalysisElliott         = "Elliott waves and harmonic patterns"
+	AnalysisVolume          = "Volume forensics"
+	AnalysisReturnDrivers   = "Volume and volatility lead/lag"
+	AnalysisTailRisk        = "Tail risk"
+	AnalysisConfidence      = "Bootstrap confidence intervals"
+	AnalysisStationarity    = "Stationarity tests (ADF/KPSS)"
+	AnalysisMeanReversion   = "Mean-reversion half-life (OU fit)"
+	AnalysisAttribution     = "Return attribution by session, hour and weekday"
+	AnalysisLiquidity       = "Spread and illiquidity estimates"
+)
+
+// Requirement is the minimum number of bars an analysis needs
+type Requirement struct {
+	Analysis string
+	MinBars  int
+}
+
+// Requirements lists every analysis with a minimum series length, shortest first.
+// RSI needs a change per bar of its period plus one to smooth; the MACD
+// signal line is a 9-bar EMA of the MACD line, which starts at the 26th bar.
+var Requirements = []Requirement{
+	{AnalysisStatistics, 2},
+	{AnalysisLevels, 10},
+	{AnalysisROC, 13},
+	{AnalysisRSI, 16},
+	{AnalysisBollinger, 20},
+	{AnalysisMarketStructure, 30},
+	{AnalysisReturnDrivers, 30},
+	{AnalysisTailRisk, 30},
+	{AnalysisConfidence, 30},
+	{AnalysisMeanReversion, 30},
+	{AnalysisAttribution, 30},
+	{AnalysisLiquidity, 30},
+	{AnalysisMACD, 34},
+	{AnalysisWyckoff, 40},
+	{AnalysisElliott, 50},
+	{AnalysisVolume, 50},
+	{AnalysisStationarity, 50},
+}
+
+// MinBars returns the bars the named analysis needs, 0 when it has no minimum
+func MinBars(analysis string) int {
+	for _, r := range Requirements {
+		if r.Analysis == analysis {
+			return r.MinBars
+		}
+	}
+	return 0
+}
+
+// CheckCapabilities returns the analyses a series of bars bars is too short for
+func CheckCapabilities(bars int) []types.SkippedAnalysis {
+	var skipped []types.SkippedAnalysis
+	for _, r := range Requirements {
+		if bars < r.MinBars {
+			skipped = append(skipped, types.SkippedAnalysis{Analysis: r.Analysis, Required: r.MinBars, Available: bars})
+		}
+	}
+	return skipped
+}
+
+// InsufficientData returns the placeholder reported for an analysis skipped on
+// a series of bars bars
+func InsufficientData(analysis string, bars int) string {
+	return fmt.Sprintf("insufficient data (needs %d bars, have %d)", MinBars(analysis), bars)
+}
+
+// skipped reports whether analytics record the analysis as skipped
+func skipped(analytics types.BTCAnalytics, analysis string) bool {
+	for _, s := range analytics.Skipped {
+		if s.Analysis == analysis {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/indicators"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"math"
+	"time"
+)
+
+// StreamingAnalysis computes analytics over a series delivered in chunks.
+// Memory is bounded by the indicator windows and the tail kept for reporting,
+// independent of the total series length.
+type StreamingAnalysis struct {
+	tailSize int
+	tail     *types.BTCTimeSeries
+
+	priceStats  *statistics.RunningStats
+	volumeStats *statistics.RunningStats
+	returnStats *statistics.RunningStats
+
+	rsi       *indicators.RSIState
+	macd      *indicators.MACDState
+	bollinger *indicators.BollingerState
+
+	analytics types.BTCAnalytics
+	risk      types.RiskConfig
+	logGrowth float64
+
+	first      time.Time
+	prevClose  float64
+	peak       float64
+	bars       int
+	outOfOrder int
+}
+
+// NewStreamingAnalysis creates a streaming analysis keeping the last tailSize
+// bars and indicator values for reporting. When rc leaves periods per year
+// unset it is detected from the first chunk.
+func NewStreamingAnalysis(symbol string, tailSize int, rc types.RiskConfig) *StreamingAnalysis {
+	return &StreamingAnalysis{
+		tailSize:    tailSize,
+		risk:        rc,
+		tail:        timeseries.New(symbol),
+		priceStats:  statistics.NewRunningStats(10000),
+		volumeStats: statistics.NewRunningStats(10000),
+		returnStats: statistics.NewRunningStats(10000),
+		rsi:         indicators.NewRSIState(14),
+		macd:        indicators.NewMACDState(12, 26, 9),
+		bollinger:   indicators.NewBollingerState(20, 2.0),
+	}
+}
+
+// AddChunk processes the next chunk of bars, which must follow the previous
+// chunk chronologically. Bars older than the last processed bar are skipped.
+func (sa *StreamingAnalysis) AddChunk(chunk []types.BTCPrice) {
+	if sa.bars == 0 && len(chunk) > 0 {
+		sa.risk = statistics.ResolveRiskConfig(&types.BTCTimeSeries{Data: chunk}, sa.risk)
+	}
+	for _, bar := range chunk {
+		if sa.bars > 0 && bar.Timestamp.Before(timeseries.GetLatestPrice(sa.tail).Timestamp) {
+			sa.outOfOrder++
+			continue
+		}
+		sa.addBar(bar)
+	}
+}
+
+// addBar updates every accumulator with one bar
+func (sa *StreamingAnalysis) addBar(bar types.BTCPrice) {
+	sa.priceStats.Add(bar.Close)
+	sa.volumeStats.Add(bar.Volume)
+
+	if sa.bars > 0 && sa.prevClose > 0 {
+		ret := (bar.Close - sa.prevClose) / sa.prevClose
+		sa.returnStats.Add(ret)
+		sa.logGrowth += math.Log1p(ret)
+	}
+	sa.prevClose = bar.Close
+	if sa.bars == 0 {
+		sa.first = bar.Timestamp
+	}
+	sa.bars++
+
+	// Maximum drawdown from the running peak
+	if bar.Close > sa.peak {
+		sa.peak = bar.Close
+	}
+	if sa.peak > 0 {
+		if drawdown := (sa.peak - bar.Close) / sa.peak; drawdown > sa.analytics.MaxDrawdown {
+			sa.analytics.MaxDrawdown = drawdown
+		}
+	}
+
+	if value, ok := sa.rsi.Update(bar.Close); ok {
+		sa.analytics.RSI = appendTail(sa.analytics.RSI, value, sa.tailSize)
+	}
+
+	if macd, signal, histogram, macdOK, signalOK := sa.macd.Update(bar.Close); macdOK {
+		sa.analytics.MACD.MACD = appendTail(sa.analytics.MACD.MACD, macd, sa.tailSize)
+		if signalOK {
+			sa.analytics.MACD.Signal = appendTail(sa.analytics.MACD.Signal, signal, sa.tailSize)
+			sa.analytics.MACD.Histogram = appendTail(sa.analytics.MACD.Histogram, histogram, sa.tailSize)
+		}
+	}
+
+	if upper, middle, lower, ok := sa.bollinger.Update(bar.Close); ok {
+		bands := &sa.analytics.BollingerBands
+		bands.Upper = appendTail(bands.Upper, upper, sa.tailSize)
+		bands.Middle = appendTail(bands.Middle, middle, sa.tailSize)
+		bands.Lower = appendTail(bands.Lower, lower, sa.tailSize)
+	}
+
+	timeseries.AddPrice(sa.tail, bar)
+	if len(sa.tail.Data) >= 2*sa.tailSize {
+		sa.tail.Data = append(sa.tail.Data[:0], sa.tail.Data[len(sa.tail.Data)-sa.tailSize:]...)
+	}
+}
+
+// Bars returns the number of bars processed
+func (sa *StreamingAnalysis) Bars() int {
+	return sa.bars
+}
+
+// Start returns the timestamp of the first bar processed
+func (sa *StreamingAnalysis) Start() time.Time {
+	return sa.first
+}
+
+// OutOfOrder returns the number of bars skipped for arriving out of order
+func (sa *StreamingAnalysis) OutOfOrder() int {
+	return sa.outOfOrder
+}
+
+// Result returns the analytics over everything processed, together with the
+// tail of the series. Indicator slices only cover the tail, and per-bar
+// returns are not retained.
+func (sa *StreamingAnalysis) Result() (*types.BTCTimeSeries, types.BTCAnalytics) {
+	analytics := sa.analytics
+	analytics.RSI = lastValues(analytics.RSI, sa.tailSize)
+	analytics.MACD.MACD = lastValues(analytics.MACD.MACD, sa.tailSize)
+	analytics.MACD.Signal = lastValues(analytics.MACD.Signal, sa.tailSize)
+	analytics.MACD.Histogram = lastValues(analytics.MACD.Histogram, sa.tailSize)
+	analytics.BollingerBands.Upper = lastValues(analytics.BollingerBands.Upper, sa.tailSize)
+	analytics.BollingerBands.Middle = lastValues(analytics.BollingerBands.Middle, sa.tailSize)
+	analytics.BollingerBands.Lower = lastValues(analytics.BollingerBands.Lower, sa.tailSize)
+	analytics.PriceStats = sa.priceStats.Statistics()
+	analytics.VolumeStats = sa.volumeStats.Statistics()
+
+	analytics.RiskConvention = sa.risk
+
+	if n := sa.returnStats.Count(); n > 0 {
+		periods := float64(sa.risk.PeriodsPerYear)
+		analytics.Volatility = sa.returnStats.StdDev() * math.Sqrt(periods)
+
+		annualReturn := sa.returnStats.Mean() * periods
+		if sa.risk.Compounding == "geometric" {
+			annualReturn = math.Expm1(sa.logGrowth / float64(n) * periods)
+		}
+		if analytics.Volatility > 0 {
+			analytics.SharpeRatio = (annualReturn - sa.risk.RiskFreeRate - sa.risk.FundingRate) / analytics.Volatility
+		}
+	}
+
+	tail := timeseries.Clone(sa.tail)
+	tail.Data = timeseries.LastN(tail, sa.tailSize)
+
+	return tail, analytics
+}
+
+// appendTail appends value, compacting to the last size values whenever the
+// slice reaches twice that length so appends stay amortized O(1)
+func appendTail(values []float64, value float64, size int) []float64 {
+	values = append(values, value)
+	if len(values) >= 2*size {
+		values = append(values[:0], values[len(values)-size:]...)
+	}
+	return values
+}
+
+// lastValues returns a copy of the last size values
+func lastValues(values []float64, size int) []float64 {
+	if len(values) > size {
+		values = values[len(values)-size:]
+	}
+	return append([]float64(nil), values...)
+}
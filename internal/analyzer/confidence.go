@@ -0,0 +1,30 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// DescribeConfidence formats the mean return, volatility and Sharpe ratio
+// with their confidence intervals, one per line, and a closing line on how
+// far the sample pins them down, for the reports
+func DescribeConfidence(ci types.BootstrapIntervals) []string {
+	level := ci.Level * 100
+	lines := []string{
+		fmt.Sprintf("Mean Return (annualized): %s (%.0f%% CI %s to %s)",
+			numfmt.SignedPct(ci.MeanReturn.Estimate), level, numfmt.SignedPct(ci.MeanReturn.Lower), numfmt.SignedPct(ci.MeanReturn.Upper)),
+		fmt.Sprintf("Annualized Volatility: %s (%.0f%% CI %s to %s)",
+			numfmt.Pct(ci.Volatility.Estimate), level, numfmt.Pct(ci.Volatility.Lower), numfmt.Pct(ci.Volatility.Upper)),
+		fmt.Sprintf("Sharpe Ratio: %.3f (%.0f%% CI %.3f to %.3f)",
+			ci.SharpeRatio.Estimate, level, ci.SharpeRatio.Lower, ci.SharpeRatio.Upper),
+	}
+
+	note := "the Sharpe ratio is distinguishable from zero"
+	if ci.SharpeRatio.Lower <= 0 && ci.SharpeRatio.Upper >= 0 {
+		note = "the Sharpe ratio is not distinguishable from zero on this sample"
+	}
+	lines = append(lines, fmt.Sprintf("Intervals from %d block-bootstrap resamples of %d returns in blocks of %d; %s",
+		ci.Resamples, ci.Bars, ci.BlockSize, note))
+	return lines
+}
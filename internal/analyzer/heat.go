@@ -0,0 +1,232 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/indicators"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Components of the market heat index
+const (
+	HeatPriceMA    = "price-ma"
+	HeatRSI        = "rsi"
+	HeatFunding    = "funding"
+	HeatVolume     = "volume"
+	HeatVolatility = "volatility"
+)
+
+// HeatComponents lists the heat index components in the order they are reported
+var HeatComponents = []string{HeatPriceMA, HeatRSI, HeatFunding, HeatVolume, HeatVolatility}
+
+// heatBandQuantile is the share of the index history outside each extreme band
+const heatBandQuantile = 0.05
+
+// ParseHeatWeights parses comma-separated name=weight pairs, e.g.
+// "price-ma=2,volume=0". Components not listed keep a weight of 1.
+func ParseHeatWeights(spec string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(HeatComponents))
+	for _, name := range HeatComponents {
+		weights[name] = 1
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid heat weight %q: use name=weight", pair)
+		}
+		name = strings.TrimSpace(name)
+		if _, known := weights[name]; !known {
+			return nil, fmt.Errorf("unknown heat component %q: use %s", name, strings.Join(HeatComponents, ", "))
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for %s: %w", name, err)
+		}
+		weights[name] = weight
+	}
+	return weights, nil
+}
+
+// MarketHeat combines the z-scores of the price against its 200-day moving
+// average, RSI, premium over the reference index, volume and 30-day realized
+// volatility into one index, weighted by weights. Each component is
+// standardized over its whole history; the index starts at the first bar
+// every weighted component with data has a value, and later bars average the
+// components they have. The extreme bands are the 5th and 95th percentiles
+// of the index. Returns nil when no component has data.
+func MarketHeat(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, weights map[string]float64) *types.HeatIndex {
+	n := len(bts.Data)
+	if n == 0 {
+		return nil
+	}
+
+	components := heatComponentSeries(bts, analytics)
+	heat := &types.HeatIndex{}
+	var active [][]float64
+	var activeWeights []float64
+	for _, name := range HeatComponents {
+		values, weight := components[name], weights[name]
+		if weight == 0 || values == nil || !standardize(values) {
+			continue
+		}
+		active = append(active, values)
+		activeWeights = append(activeWeights, weight)
+		latest := 0.0
+		for i := n - 1; i >= 0; i-- {
+			if !math.IsNaN(values[i]) {
+				latest = values[i]
+				break
+			}
+		}
+		heat.Components = append(heat.Components, types.HeatComponent{Name: name, Weight: weight, Latest: latest})
+	}
+	if len(active) == 0 {
+		return nil
+	}
+
+	// Start once every component has a value
+	start := 0
+	for _, values := range active {
+		for start < n && math.IsNaN(values[start]) {
+			start++
+		}
+	}
+
+	var history []float64
+	for i := start; i < n; i++ {
+		sum, total := 0.0, 0.0
+		for c, values := range active {
+			if !math.IsNaN(values[i]) {
+				sum += activeWeights[c] * values[i]
+				total += math.Abs(activeWeights[c])
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		heat.Points = append(heat.Points, types.HeatPoint{Timestamp: bts.Data[i].Timestamp, Value: sum / total})
+		history = append(history, sum/total)
+	}
+	if len(history) == 0 {
+		return nil
+	}
+
+	heat.Latest = history[len(history)-1]
+	heat.Percentile = statistics.PercentileRank(history, heat.Latest)
+	heat.LowerBand = statistics.Quantile(history, heatBandQuantile)
+	heat.UpperBand = statistics.Quantile(history, 1-heatBandQuantile)
+	heat.Zone = "neutral"
+	switch {
+	case heat.Latest >= heat.UpperBand:
+		heat.Zone = "overheated"
+	case heat.Latest <= heat.LowerBand:
+		heat.Zone = "cold"
+	}
+	return heat
+}
+
+// heatComponentSeries returns the raw value of each component at every bar,
+// NaN where it is undefined. Components without any data are left out.
+func heatComponentSeries(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) map[string][]float64 {
+	n := len(bts.Data)
+	series := make(map[string][]float64)
+	// endAligned places values that end at the last bar
+	endAligned := func(values []float64, transform func(i int, v float64) float64) []float64 {
+		if len(values) == 0 {
+			return nil
+		}
+		aligned := make([]float64, n)
+		for i := range aligned {
+			aligned[i] = math.NaN()
+		}
+		offset := n - len(values)
+		for j, v := range values {
+			aligned[offset+j] = transform(offset+j, v)
+		}
+		return aligned
+	}
+	identity := func(_ int, v float64) float64 { return v }
+
+	if period := timeseries.BarsFor(bts, 200*24*time.Hour); period >= 2 {
+		series[HeatPriceMA] = endAligned(indicators.CalculateMovingAverage(bts, period), func(i int, ma float64) float64 {
+			if ma <= 0 || bts.Data[i].Close <= 0 {
+				return math.NaN()
+			}
+			return math.Log(bts.Data[i].Close / ma)
+		})
+	}
+
+	series[HeatRSI] = endAligned(analytics.RSI, identity)
+
+	if pa := analytics.Premium; pa != nil && len(pa.Points) > 0 {
+		premiums := make([]float64, n)
+		bars := make(map[int64]int, n)
+		for i, bar := range bts.Data {
+			premiums[i] = math.NaN()
+			bars[bar.Timestamp.Unix()] = i
+		}
+		for _, point := range pa.Points {
+			if i, ok := bars[point.Timestamp.Unix()]; ok {
+				premiums[i] = point.Premium
+			}
+		}
+		series[HeatFunding] = premiums
+	}
+
+	series[HeatVolume] = endAligned(timeseries.GetVolumeData(bts), func(_ int, volume float64) float64 {
+		if volume <= 0 {
+			return math.NaN()
+		}
+		return math.Log(volume)
+	})
+
+	returns, _ := statistics.CalculateReturns(bts)
+	if window := timeseries.BarsFor(bts, 30*24*time.Hour); window >= 2 && len(returns) > window {
+		rolling := statistics.RollingVolatility(returns, window, analytics.RiskConvention.PeriodsPerYear)
+		series[HeatVolatility] = endAligned(rolling[window-1:], identity)
+	}
+	return series
+}
+
+// standardize replaces the defined values with their z-scores in place. It
+// reports false when fewer than two values are defined or they do not vary.
+func standardize(values []float64) bool {
+	var defined []float64
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			defined = append(defined, v)
+		}
+	}
+	if len(defined) < 2 {
+		return false
+	}
+	stats := statistics.Calculate(defined)
+	if stats.StdDev == 0 {
+		return false
+	}
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			values[i] = (v - stats.Mean) / stats.StdDev
+		}
+	}
+	return true
+}
+
+// sortedHeatComponents returns the components of heat by their contribution
+// to the latest reading, largest first
+func sortedHeatComponents(heat *types.HeatIndex) []types.HeatComponent {
+	components := append([]types.HeatComponent(nil), heat.Components...)
+	sort.SliceStable(components, func(i, j int) bool {
+		return math.Abs(components[i].Weight*components[i].Latest) > math.Abs(components[j].Weight*components[j].Latest)
+	})
+	return components
+}
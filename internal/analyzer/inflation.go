@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// AnalyzeRealPrices deflates the closes of bts by the price index cpi into
+// dollars of the latest reading used. Each bar takes the latest reading at
+// or before it, so bars after the last published month carry it forward and
+// bars before the first reading are left out. Returns nil when no bar has a
+// reading.
+func AnalyzeRealPrices(bts *types.BTCTimeSeries, cpi *types.AuxSeries) *types.RealPriceAnalysis {
+	if cpi == nil || len(cpi.Points) == 0 {
+		return nil
+	}
+
+	var points []types.RealPricePoint
+	next := 0
+	for _, bar := range bts.Data {
+		for next < len(cpi.Points) && !cpi.Points[next].Timestamp.After(bar.Timestamp) {
+			next++
+		}
+		if next == 0 || bar.Close <= 0 {
+			continue
+		}
+		points = append(points, types.RealPricePoint{Timestamp: bar.Timestamp, Nominal: bar.Close, Index: cpi.Points[next-1].Value})
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	base := points[len(points)-1].Index
+	nominals := make([]float64, len(points))
+	reals := make([]float64, len(points))
+	for i := range points {
+		points[i].Real = points[i].Nominal * base / points[i].Index
+		nominals[i], reals[i] = points[i].Nominal, points[i].Real
+	}
+
+	first, last := points[0], points[len(points)-1]
+	rp := &types.RealPriceAnalysis{
+		Index:           cpi.Name,
+		Points:          points,
+		RealStats:       statistics.Calculate(reals),
+		NominalReturn:   last.Nominal/first.Nominal - 1,
+		RealReturn:      last.Real/first.Real - 1,
+		Inflation:       base/first.Index - 1,
+		NominalDrawdown: maxDrawdown(nominals),
+		RealDrawdown:    maxDrawdown(reals),
+	}
+	for i := len(cpi.Points) - 1; i >= 0; i-- {
+		if !cpi.Points[i].Timestamp.After(last.Timestamp) {
+			rp.BaseDate = cpi.Points[i].Timestamp
+			break
+		}
+	}
+	if years := last.Timestamp.Sub(first.Timestamp).Hours() / 24 / 365.25; years > 0 {
+		rp.NominalAnnualized = math.Pow(1+rp.NominalReturn, 1/years) - 1
+		rp.RealAnnualized = math.Pow(1+rp.RealReturn, 1/years) - 1
+	}
+	return rp
+}
+
+// maxDrawdown returns the largest fall of values from a running peak
+func maxDrawdown(values []float64) float64 {
+	drawdown, peak := 0.0, values[0]
+	for _, v := range values {
+		peak = math.Max(peak, v)
+		drawdown = math.Max(drawdown, (peak-v)/peak)
+	}
+	return drawdown
+}
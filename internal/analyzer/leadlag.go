@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+)
+
+// realizedVolWindow is the trailing window, in bars, of the realized
+// volatility correlated with forward returns
+const realizedVolWindow = 10
+
+// AnalyzeReturnDrivers cross-correlates volume changes and realized
+// volatility with returns and with absolute returns, to show whether volume
+// spikes or volatility precede price moves. Volume change is the log change
+// from the previous bar; realized volatility is the standard deviation of
+// the trailing realizedVolWindow returns, including the current one, so only
+// its positive lags are free of overlap with absolute returns.
+func AnalyzeReturnDrivers(bts *types.BTCTimeSeries, maxLag int) []types.LeadLagAnalysis {
+	returns, _ := statistics.CalculateReturns(bts)
+	if len(returns) <= realizedVolWindow {
+		return nil
+	}
+
+	absReturns := make([]float64, len(returns))
+	volumeChanges := make([]float64, len(returns))
+	for i, r := range returns {
+		absReturns[i] = math.Abs(r)
+		prev, curr := bts.Data[i].Volume, bts.Data[i+1].Volume
+		if prev > 0 && curr > 0 {
+			volumeChanges[i] = math.Log(curr / prev)
+		}
+	}
+
+	// Realized volatility is defined from the first full window on
+	first := realizedVolWindow - 1
+	realized := statistics.RollingVolatility(returns, realizedVolWindow, 1)[first:]
+
+	volatility := fmt.Sprintf("Realized volatility (%d-bar)", realizedVolWindow)
+	var drivers []types.LeadLagAnalysis
+	for _, d := range []struct {
+		series, target string
+		x, y           []float64
+	}{
+		{"Volume change", "returns", volumeChanges, returns},
+		{"Volume change", "absolute returns", volumeChanges, absReturns},
+		{volatility, "returns", realized, returns[first:]},
+		{volatility, "absolute returns", realized, absReturns[first:]},
+	} {
+		if analysis := crossCorrelate(d.series, d.target, d.x, d.y, maxLag); analysis != nil {
+			drivers = append(drivers, *analysis)
+		}
+	}
+	return drivers
+}
+
+// crossCorrelate correlates x with y at every lag up to maxLag and picks the
+// strongest lag. Target names y, empty for returns. Returns nil when no lag
+// has enough observations.
+func crossCorrelate(series, target string, x, y []float64, maxLag int) *types.LeadLagAnalysis {
+	result := &types.LeadLagAnalysis{
+		Series:       series,
+		Target:       target,
+		Observations: len(y),
+		Correlations: statistics.CalculateCrossCorrelation(x, y, maxLag),
+	}
+	if len(result.Correlations) == 0 {
+		return nil
+	}
+	if result.Observations > 0 {
+		result.ConfidenceBand = 1.96 / math.Sqrt(float64(result.Observations))
+	}
+
+	for _, lc := range result.Correlations {
+		if math.Abs(lc.Correlation) > math.Abs(result.BestCorrelation) {
+			result.BestLag = lc.Lag
+			result.BestCorrelation = lc.Correlation
+		}
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// liquidityWindow is the number of recent bars the liquidity estimates are
+// compared over
+const liquidityWindow = 30
+
+// DescribeLiquidity formats the spread and illiquidity estimates, comparing
+// the last bars with the whole series and, when a snapshot was loaded, with
+// the quoted order book spread
+func DescribeLiquidity(le types.LiquidityEstimates, ob *types.OrderBookMetrics) []string {
+	var lines []string
+	if le.HasRanges {
+		lines = append(lines,
+			fmt.Sprintf("Corwin-Schultz Spread: %s (last %d bars %s, %s)",
+				numfmt.Pct(le.CorwinSchultz), le.Window, numfmt.Pct(le.RecentCorwinSchultz), compareLiquidity(le.RecentCorwinSchultz, le.CorwinSchultz)),
+			fmt.Sprintf("Abdi-Ranaldo Spread: %s (last %d bars %s, %s)",
+				numfmt.Pct(le.AbdiRanaldo), le.Window, numfmt.Pct(le.RecentAbdiRanaldo), compareLiquidity(le.RecentAbdiRanaldo, le.AbdiRanaldo)))
+	} else {
+		lines = append(lines, "Spread estimates unavailable: the series has no intrabar high/low ranges")
+	}
+	lines = append(lines, fmt.Sprintf("Amihud Illiquidity: %.4g per million volume (last %d bars %.4g, %s)",
+		le.Amihud, le.Window, le.RecentAmihud, compareLiquidity(le.RecentAmihud, le.Amihud)))
+	if ob != nil && le.HasRanges {
+		lines = append(lines, fmt.Sprintf("Quoted order book spread: %s", numfmt.Pct(ob.SpreadPct)))
+	}
+	return lines
+}
+
+// compareLiquidity describes a recent spread or illiquidity against the
+// series average; higher means less liquid
+func compareLiquidity(recent, average float64) string {
+	switch {
+	case average <= 0:
+		return "no average to compare"
+	case recent > average*1.2:
+		return "less liquid than usual"
+	case recent < average*0.8:
+		return "more liquid than usual"
+	}
+	return "about as liquid as usual"
+}
@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DescribeMeanReversion formats the Ornstein-Uhlenbeck fit of log prices:
+// the half-life with the level prices revert to, whether the reversion is
+// significant, and the lookbacks it suggests for mean-reversion rules.
+// Prices are formatted in denomination d.
+func DescribeMeanReversion(ou types.OUFit, d *types.Denomination) []string {
+	if !ou.MeanReverting {
+		return []string{fmt.Sprintf("%s: no mean reversion (AR(1) coefficient %.4f), so there is no half-life and no level for mean-reversion rules to revert to", ou.Series, ou.Phi)}
+	}
+	lines := []string{fmt.Sprintf("%s: half-life %.1f bars (%.1f days), reverting toward %s at %s of the deviation per bar",
+		ou.Series, ou.HalfLifeBars, ou.HalfLifeDays, denom.Price(d, math.Exp(ou.Mean)), numfmt.Pct(ou.Theta))}
+	if ou.Significant {
+		lines = append(lines, fmt.Sprintf("Reversion is significant (Dickey-Fuller %.2f below its 5%% critical value)", ou.TStat))
+	} else {
+		lines = append(lines, fmt.Sprintf("Reversion is not significant (Dickey-Fuller %.2f): a random walk fits as well, so the half-life is a rough guide", ou.TStat))
+	}
+	if len(ou.Lookbacks) > 0 {
+		lookbacks := make([]string, len(ou.Lookbacks))
+		for i, lb := range ou.Lookbacks {
+			lookbacks[i] = fmt.Sprint(lb)
+		}
+		lines = append(lines, fmt.Sprintf("Suggested lookbacks for mean-reversion rules: %s bars", strings.Join(lookbacks, ", ")))
+	}
+	return lines
+}
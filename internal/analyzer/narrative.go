@@ -0,0 +1,238 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Narrative describes the analysis in plain sentences for readers who do
+// not follow the individual metrics: the price action of the last week and
+// the whole series, the risk regime, momentum, structure and nearby levels,
+// and the balance of the trading signals. Each paragraph is built from fixed
+// rules over the analytics; paragraphs without data are left out.
+func Narrative(result *types.AnalysisResult) []string {
+	bts := result.Series
+	if len(bts.Data) < 2 {
+		return nil
+	}
+	var paragraphs []string
+	for _, paragraph := range []string{
+		priceNarrative(bts, result.Analytics),
+		riskNarrative(result.Analytics),
+		momentumNarrative(bts, result.Analytics),
+		structureNarrative(bts, result.Analytics),
+		signalNarrative(result),
+	} {
+		if paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return paragraphs
+}
+
+// priceNarrative describes the range and move of the last seven days and
+// the move and drawdown over the series
+func priceNarrative(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) string {
+	d := bts.Denomination
+	first, latest := bts.Data[0], bts.Data[len(bts.Data)-1]
+	start := latest.Timestamp.Add(-7 * 24 * time.Hour)
+	week := timeseries.Between(bts, start, latest.Timestamp)
+
+	var text strings.Builder
+	low, high := week[0].Low, week[0].High
+	for _, bar := range week {
+		low, high = math.Min(low, bar.Low), math.Max(high, bar.High)
+	}
+	fmt.Fprintf(&text, "Bitcoin ranged between %s and %s over the last week and closed at %s",
+		denom.Price(d, low), denom.Price(d, high), denom.Price(d, latest.Close))
+	if before, ok := timeseries.At(bts, start); ok && before.Close > 0 {
+		fmt.Fprintf(&text, ", %s on the week", describeMove(latest.Close/before.Close-1))
+	}
+	text.WriteString(". ")
+
+	fmt.Fprintf(&text, "Since %s it has moved from %s, %s",
+		first.Timestamp.Format("January 2, 2006"), denom.Price(d, first.Close), describeMove(latest.Close/first.Close-1))
+	if analytics.MaxDrawdown > 0 {
+		fmt.Fprintf(&text, ", with a deepest fall of %s from a prior high", numfmt.Pct(analytics.MaxDrawdown))
+	}
+	text.WriteString(".")
+	return text.String()
+}
+
+// riskNarrative describes volatility against its trailing year and the
+// risk-adjusted return
+func riskNarrative(analytics types.BTCAnalytics) string {
+	if analytics.Volatility <= 0 {
+		return ""
+	}
+	var text strings.Builder
+	if mp, ok := findPercentile(analytics.Percentiles, MetricVolatility); ok {
+		fmt.Fprintf(&text, "Volatility over the last 30 days is %s annualized, at the %s percentile of the past year (%s).",
+			numfmt.Pct(mp.Value), ordinal(int(mp.Percentile+0.5)), mp.Label)
+	} else {
+		fmt.Fprintf(&text, "Volatility over the whole period is %s annualized.", numfmt.Pct(analytics.Volatility))
+	}
+
+	switch sharpe := analytics.SharpeRatio; {
+	case sharpe >= 1:
+		fmt.Fprintf(&text, " Returns have been strong for the risk taken (Sharpe ratio %.2f).", sharpe)
+	case sharpe >= 0.01:
+		fmt.Fprintf(&text, " Returns have been positive but modest for the risk taken (Sharpe ratio %.2f).", sharpe)
+	default:
+		fmt.Fprintf(&text, " Returns have not paid for the risk taken (Sharpe ratio %.2f).", sharpe)
+	}
+	return text.String()
+}
+
+// momentumNarrative describes RSI and its direction, MACD against its signal
+// line and the price against the Bollinger Bands
+func momentumNarrative(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) string {
+	var sentences []string
+	if rsi := analytics.RSI; len(rsi) > 0 {
+		latest := rsi[len(rsi)-1]
+		sentence := fmt.Sprintf("RSI is %.0f, %s", latest, rsiZone(latest))
+		if len(rsi) > 5 {
+			switch change := latest - rsi[len(rsi)-6]; {
+			case change >= 5:
+				sentence += ", and rising"
+			case change <= -5:
+				sentence += ", and falling"
+			}
+		}
+		sentences = append(sentences, sentence+".")
+	}
+
+	if hist := analytics.MACD.Histogram; len(hist) > 0 {
+		latest := hist[len(hist)-1]
+		crossed := len(hist) > 5 && (hist[len(hist)-6] > 0) != (latest > 0)
+		switch {
+		case latest > 0 && crossed:
+			sentences = append(sentences, "MACD has recently crossed above its signal line, a bullish turn.")
+		case latest <= 0 && crossed:
+			sentences = append(sentences, "MACD has recently crossed below its signal line, a bearish turn.")
+		case latest > 0:
+			sentences = append(sentences, "MACD remains above its signal line.")
+		default:
+			sentences = append(sentences, "MACD remains below its signal line.")
+		}
+	}
+
+	bb := analytics.BollingerBands
+	if len(bb.Upper) > 0 && len(bb.Lower) > 0 {
+		price := bts.Data[len(bts.Data)-1].Close
+		switch {
+		case price > bb.Upper[len(bb.Upper)-1]:
+			sentences = append(sentences, "Price is above its upper Bollinger Band, stretched to the upside.")
+		case price < bb.Lower[len(bb.Lower)-1]:
+			sentences = append(sentences, "Price is below its lower Bollinger Band, stretched to the downside.")
+		}
+	}
+	return strings.Join(sentences, " ")
+}
+
+// structureNarrative describes the market structure bias and the nearest
+// support below and resistance above the price
+func structureNarrative(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) string {
+	d := bts.Denomination
+	price := bts.Data[len(bts.Data)-1].Close
+	var sentences []string
+	if ms := analytics.MarketStructure; ms != nil && ms.Bias != "" {
+		if ms.Bias == "neutral" {
+			sentences = append(sentences, "The swing structure shows no clear trend.")
+		} else {
+			sentences = append(sentences, fmt.Sprintf("The swing structure is %s.", ms.Bias))
+		}
+	}
+
+	support, resistance := math.Inf(-1), math.Inf(1)
+	for _, level := range analytics.SupportResistance.SupportLevels {
+		if level < price {
+			support = math.Max(support, level)
+		}
+	}
+	for _, level := range analytics.SupportResistance.ResistanceLevels {
+		if level > price {
+			resistance = math.Min(resistance, level)
+		}
+	}
+	switch {
+	case !math.IsInf(support, 0) && !math.IsInf(resistance, 0):
+		sentences = append(sentences, fmt.Sprintf("The nearest support is %s (%s below) and the nearest resistance %s (%s above).",
+			denom.Price(d, support), numfmt.Pct(1-support/price), denom.Price(d, resistance), numfmt.Pct(resistance/price-1)))
+	case !math.IsInf(support, 0):
+		sentences = append(sentences, fmt.Sprintf("The nearest support is %s (%s below), with no resistance overhead.",
+			denom.Price(d, support), numfmt.Pct(1-support/price)))
+	case !math.IsInf(resistance, 0):
+		sentences = append(sentences, fmt.Sprintf("The nearest resistance is %s (%s above), with no support below.",
+			denom.Price(d, resistance), numfmt.Pct(resistance/price-1)))
+	}
+	return strings.Join(sentences, " ")
+}
+
+// signalNarrative describes how the trading signals split and where the
+// composite score leans
+func signalNarrative(result *types.AnalysisResult) string {
+	if len(result.Signals) == 0 {
+		return ""
+	}
+	buys, sells := 0, 0
+	for _, signal := range result.Signals {
+		switch {
+		case strings.HasPrefix(signal, "BUY"):
+			buys++
+		case strings.HasPrefix(signal, "SELL"):
+			sells++
+		}
+	}
+	lean := "Overall the picture is mixed"
+	switch {
+	case result.SignalScore >= 0.3:
+		lean = "Overall the signals lean bullish"
+	case result.SignalScore <= -0.3:
+		lean = "Overall the signals lean bearish"
+	}
+	return fmt.Sprintf("%s: the %d trading signals split %d buy, %d sell and %d hold.",
+		lean, len(result.Signals), buys, sells, len(result.Signals)-buys-sells)
+}
+
+// describeMove words a fractional change, e.g. "up 4.25%"
+func describeMove(change float64) string {
+	switch {
+	case math.Abs(change) < 0.0005:
+		return "roughly unchanged"
+	case change > 0:
+		return "up " + numfmt.Pct(change)
+	}
+	return "down " + numfmt.Pct(-change)
+}
+
+// rsiZone words an RSI reading
+func rsiZone(rsi float64) string {
+	switch {
+	case rsi >= 70:
+		return "in overbought territory"
+	case rsi <= 30:
+		return "in oversold territory"
+	case rsi >= 55:
+		return "leaning bullish"
+	case rsi <= 45:
+		return "leaning bearish"
+	}
+	return "neutral"
+}
+
+// findPercentile returns the percentile rank of metric
+func findPercentile(ranks []types.MetricPercentile, metric string) (types.MetricPercentile, bool) {
+	for _, mp := range ranks {
+		if mp.Metric == metric {
+			return mp, true
+		}
+	}
+	return types.MetricPercentile{}, false
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"time"
+)
+
+// nvtSignalWindow is the number of days of transaction volume averaged in the
+// NVT signal
+const nvtSignalWindow = 90
+
+// nvtExtreme is the percentile distance from either end of the history at
+// which an NVT reading is flagged as extreme
+const nvtExtreme = 5
+
+// AnalyzeNVT computes the NVT ratio, market cap over the day's on-chain
+// transaction volume, and the NVT signal, which averages the volume over
+// nvtSignalWindow days to smooth it. Market cap is the day's close times the
+// on-chain supply. Returns nil when no on-chain day has a close.
+func AnalyzeNVT(bts *types.BTCTimeSeries, onchain []types.OnChainPoint) *types.NVTAnalysis {
+	if len(bts.Data) == 0 || len(onchain) == 0 {
+		return nil
+	}
+	closes := make(map[int64]float64)
+	for _, bar := range timeseries.Resample(bts, 24*time.Hour).Data {
+		closes[bar.Timestamp.Unix()] = bar.Close
+	}
+
+	nvt := &types.NVTAnalysis{SignalWindow: nvtSignalWindow}
+	var volumes, ratios, signals []float64
+	volumeSum := 0.0
+	for _, point := range onchain {
+		price, ok := closes[point.Timestamp.Truncate(24*time.Hour).Unix()]
+		if !ok || price <= 0 || point.TxVolume <= 0 || point.Supply <= 0 {
+			continue
+		}
+		marketCap := price * point.Supply
+
+		volumes = append(volumes, point.TxVolume)
+		volumeSum += point.TxVolume
+		if len(volumes) > nvtSignalWindow {
+			volumeSum -= volumes[len(volumes)-nvtSignalWindow-1]
+		}
+
+		np := types.NVTPoint{Timestamp: point.Timestamp, NVT: marketCap / point.TxVolume}
+		if len(volumes) >= nvtSignalWindow {
+			np.NVTSignal = marketCap / (volumeSum / nvtSignalWindow)
+			signals = append(signals, np.NVTSignal)
+		}
+		ratios = append(ratios, np.NVT)
+		nvt.Points = append(nvt.Points, np)
+	}
+	if len(nvt.Points) == 0 {
+		return nil
+	}
+
+	latest := nvt.Points[len(nvt.Points)-1]
+	nvt.NVT, nvt.NVTSignal = latest.NVT, latest.NVTSignal
+	nvt.NVTPercentile = statistics.PercentileRank(ratios, nvt.NVT)
+	if len(signals) > 0 {
+		nvt.SignalPercentile = statistics.PercentileRank(signals, nvt.NVTSignal)
+	}
+
+	for _, reading := range []struct {
+		name       string
+		percentile float64
+		samples    int
+	}{
+		{"NVT", nvt.NVTPercentile, len(ratios)},
+		{"NVT signal", nvt.SignalPercentile, len(signals)},
+	} {
+		if reading.samples < minPercentileSamples {
+			continue
+		}
+		switch {
+		case reading.percentile >= 100-nvtExtreme:
+			nvt.Extremes = append(nvt.Extremes, fmt.Sprintf("%s at the %s percentile of %d days: price far ahead of on-chain use, as near past tops",
+				reading.name, ordinal(int(reading.percentile+0.5)), reading.samples))
+		case reading.percentile <= nvtExtreme:
+			nvt.Extremes = append(nvt.Extremes, fmt.Sprintf("%s at the %s percentile of %d days: on-chain use high for the price, as near past bottoms",
+				reading.name, ordinal(int(reading.percentile+0.5)), reading.samples))
+		}
+	}
+	return nvt
+}
@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// DescribePair formats the hedge ratio, the Engle-Granger test of the spread
+// and the state of the z-score template of a pairs analysis, for the reports
+func DescribePair(pa *types.PairAnalysis) []string {
+	lines := []string{
+		fmt.Sprintf("Hedge ratio: %.3f (log price = %.4f + %.3f × log %s) over %d aligned bars",
+			pa.HedgeRatio, pa.Intercept, pa.HedgeRatio, pa.Pair, pa.Observations),
+		fmt.Sprintf("Return correlation: %.3f", pa.Correlation),
+	}
+	eg := pa.Cointegration
+	if eg.Observations == 0 {
+		lines = append(lines, "Engle-Granger: not testable (too few bars, or no random variation)")
+	} else {
+		outcome := "PASS: cointegrated, the spread reverts to a level"
+		if !eg.Stationary {
+			outcome = "FAIL: not cointegrated, the spread may drift without reverting"
+		}
+		lines = append(lines, fmt.Sprintf("Engle-Granger: %.3f (5%% critical %.3f, lags %d) %s", eg.Statistic, eg.Critical5, eg.Lags, outcome))
+	}
+	if pa.HalfLife.MeanReverting {
+		lines = append(lines, fmt.Sprintf("Spread half-life: %.1f bars (%.1f days)", pa.HalfLife.HalfLifeBars, pa.HalfLife.HalfLifeDays))
+	}
+
+	position := "flat"
+	switch {
+	case pa.Position > 0:
+		position = "long the spread (buy this asset, sell the pair)"
+	case pa.Position < 0:
+		position = "short the spread (sell this asset, buy the pair)"
+	}
+	lines = append(lines, fmt.Sprintf("Spread z-score over %d bars: %+.2f, template (entry ±%.2f, exit ±%.2f) is %s",
+		pa.Config.Window, pa.ZScore, pa.Config.EntryZ, pa.Config.ExitZ, position))
+	return lines
+}
@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"time"
+)
+
+// Headline metrics ranked by RankHeadlineMetrics
+const (
+	MetricPrice      = "Price"
+	MetricRSI        = "RSI (14)"
+	MetricVolatility = "Volatility (30-day)"
+	MetricVolume     = "Volume"
+	MetricPremium    = "Premium/Funding"
+)
+
+// minPercentileSamples is the shortest history a metric is ranked against
+const minPercentileSamples = 20
+
+// percentileWords are the words for a low and a high reading of each metric
+var percentileWords = map[string][2]string{
+	MetricPrice:      {"cheap", "expensive"},
+	MetricRSI:        {"weak", "strong"},
+	MetricVolatility: {"quiet", "turbulent"},
+	MetricVolume:     {"thin", "heavy"},
+	MetricPremium:    {"discounted", "rich"},
+}
+
+// RankHeadlineMetrics ranks the latest price, RSI, 30-day realized
+// volatility, volume and, when a reference was loaded, premium against their
+// trailing year of values. Metrics with fewer than minPercentileSamples
+// values are left out.
+func RankHeadlineMetrics(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) []types.MetricPercentile {
+	year := timeseries.BarsFor(bts, 365*24*time.Hour)
+
+	type metricSeries struct {
+		metric string
+		values []float64
+	}
+	series := []metricSeries{
+		{MetricPrice, timeseries.GetClosePrices(bts)},
+		{MetricRSI, analytics.RSI},
+	}
+
+	returns, _ := statistics.CalculateReturns(bts)
+	if window := timeseries.BarsFor(bts, 30*24*time.Hour); window >= 2 && len(returns) > window {
+		rolling := statistics.RollingVolatility(returns, window, analytics.RiskConvention.PeriodsPerYear)
+		series = append(series, metricSeries{MetricVolatility, rolling[window-1:]})
+	}
+
+	series = append(series, metricSeries{MetricVolume, timeseries.GetVolumeData(bts)})
+
+	if pa := analytics.Premium; pa != nil {
+		premiums := make([]float64, len(pa.Points))
+		for i, point := range pa.Points {
+			premiums[i] = point.Premium
+		}
+		series = append(series, metricSeries{MetricPremium, premiums})
+	}
+
+	var ranks []types.MetricPercentile
+	for _, s := range series {
+		history := s.values
+		if len(history) > year {
+			history = history[len(history)-year:]
+		}
+		if len(history) < minPercentileSamples {
+			continue
+		}
+		latest := history[len(history)-1]
+		pct := statistics.PercentileRank(history, latest)
+		ranks = append(ranks, types.MetricPercentile{
+			Metric:     s.metric,
+			Value:      latest,
+			Percentile: pct,
+			Samples:    len(history),
+			Label:      percentileLabel(s.metric, pct),
+		})
+	}
+	return ranks
+}
+
+// percentileLabel grades a percentile with the metric's words for low and
+// high readings
+func percentileLabel(metric string, pct float64) string {
+	words, ok := percentileWords[metric]
+	if !ok {
+		words = [2]string{"low", "high"}
+	}
+	switch {
+	case pct <= 10:
+		return "unusually " + words[0]
+	case pct <= 25:
+		return words[0]
+	case pct >= 90:
+		return "unusually " + words[1]
+	case pct >= 75:
+		return words[1]
+	}
+	return "typical"
+}
+
+// DescribePercentile formats a ranked metric on one line, e.g.
+// "Volatility (30-day): 41.20%, 8th percentile of 365 (unusually quiet)".
+// Prices are formatted in denomination d.
+func DescribePercentile(mp types.MetricPercentile, d *types.Denomination) string {
+	var value string
+	switch mp.Metric {
+	case MetricPrice:
+		value = denom.Price(d, mp.Value)
+	case MetricRSI:
+		value = fmt.Sprintf("%.2f", mp.Value)
+	case MetricVolatility:
+		value = numfmt.Pct(mp.Value)
+	case MetricPremium:
+		value = numfmt.SignedPct(mp.Value)
+	default:
+		value = fmt.Sprintf("%.0f", mp.Value)
+	}
+	return fmt.Sprintf("%s: %s, %s percentile of %d (%s)", mp.Metric, value, ordinal(int(mp.Percentile+0.5)), mp.Samples, mp.Label)
+}
+
+// ordinal formats n with its English suffix, e.g. 1st, 12th, 23rd
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
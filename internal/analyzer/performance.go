@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Horizons of the performance table
+const (
+	Horizon1D  = "1d"
+	Horizon7D  = "7d"
+	Horizon30D = "30d"
+	Horizon90D = "90d"
+	HorizonYTD = "YTD"
+	Horizon1Y  = "1y"
+)
+
+// PerformanceHorizons lists the horizons of the performance table in the
+// order they are reported
+var PerformanceHorizons = []string{Horizon1D, Horizon7D, Horizon30D, Horizon90D, HorizonYTD, Horizon1Y}
+
+// horizonDurations are the lookbacks of the fixed-length horizons
+var horizonDurations = map[string]time.Duration{
+	Horizon1D:  24 * time.Hour,
+	Horizon7D:  7 * 24 * time.Hour,
+	Horizon30D: 30 * 24 * time.Hour,
+	Horizon90D: 90 * 24 * time.Hour,
+	Horizon1Y:  365 * 24 * time.Hour,
+}
+
+// PerformanceTable returns the change of the latest close over every
+// horizon of PerformanceHorizons. Each return is measured from the close in
+// effect at the start of the horizon, the last bar at or before it; YTD
+// starts at midnight UTC on January 1 of the latest bar's year, so it is
+// measured from the last close of the previous year. Horizons the series
+// does not reach back to are listed as unavailable.
+func PerformanceTable(bts *types.BTCTimeSeries) []types.HorizonReturn {
+	if len(bts.Data) == 0 {
+		return nil
+	}
+	latest := bts.Data[len(bts.Data)-1]
+	table := make([]types.HorizonReturn, 0, len(PerformanceHorizons))
+	for _, horizon := range PerformanceHorizons {
+		start := latest.Timestamp.Add(-horizonDurations[horizon])
+		if horizon == HorizonYTD {
+			start = time.Date(latest.Timestamp.UTC().Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+		hr := types.HorizonReturn{Horizon: horizon}
+		if bar, ok := timeseries.At(bts, start); ok && bar.Close > 0 {
+			hr.Since = bar.Timestamp
+			hr.StartPrice = bar.Close
+			hr.Return = latest.Close/bar.Close - 1
+			hr.Available = true
+		}
+		table = append(table, hr)
+	}
+	return table
+}
+
+// FormatPerformance renders the performance table on two aligned lines, one
+// of horizons and one of returns, with "n/a" for unavailable horizons
+func FormatPerformance(table []types.HorizonReturn) string {
+	var header, values strings.Builder
+	for _, hr := range table {
+		value := "n/a"
+		if hr.Available {
+			value = numfmt.SignedPct(hr.Return)
+		}
+		fmt.Fprintf(&header, "%10s", hr.Horizon)
+		fmt.Fprintf(&values, "%10s", value)
+	}
+	return header.String() + "\n" + values.String() + "\n"
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/supply"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"math"
+	"time"
+)
+
+// Coefficients of the widely cited 2019 stock-to-flow fit:
+// price = exp(s2fIntercept) * SF^s2fExponent
+const (
+	s2fIntercept = -1.84
+	s2fExponent  = 3.36
+)
+
+// AnalyzeStockToFlow evaluates the stock-to-flow model at each daily close,
+// with the supply and the trailing year of issuance estimated from the
+// halving schedule. Intraday series are resampled to daily and model prices
+// are scaled to the fiat unit of the series. Returns nil for a series with
+// no bars after the first year of issuance.
+func AnalyzeStockToFlow(bts *types.BTCTimeSeries) *types.StockToFlowModel {
+	daily := bts
+	if len(bts.Data) > 0 && timeseries.DetectFrequency(bts).Interval < 24*time.Hour {
+		daily = timeseries.Resample(bts, 24*time.Hour)
+	}
+
+	model := &types.StockToFlowModel{}
+	var residuals []float64
+	for _, bar := range daily.Data {
+		stock := supply.SupplyAt(bar.Timestamp)
+		flow := stock - supply.SupplyAt(bar.Timestamp.AddDate(-1, 0, 0))
+		if flow <= 0 || bar.Close <= 0 || bar.Timestamp.Before(supply.Genesis.AddDate(1, 0, 0)) {
+			continue
+		}
+		sf := stock / flow
+		modelPrice := math.Exp(s2fIntercept) * math.Pow(sf, s2fExponent) * denom.FiatScale(bts.Denomination)
+		residual := math.Log(bar.Close / modelPrice)
+		model.Points = append(model.Points, types.StockToFlowPoint{
+			Timestamp:   bar.Timestamp,
+			Price:       bar.Close,
+			StockToFlow: sf,
+			ModelPrice:  modelPrice,
+			Residual:    residual,
+		})
+		residuals = append(residuals, residual)
+		model.Supply = stock
+	}
+	if len(model.Points) == 0 {
+		return nil
+	}
+
+	latest := model.Points[len(model.Points)-1]
+	model.StockToFlow, model.ModelPrice, model.Residual = latest.StockToFlow, latest.ModelPrice, latest.Residual
+	stats := statistics.Calculate(residuals)
+	model.MeanResidual, model.ResidualStdDev = stats.Mean, stats.StdDev
+	model.NextHalving = supply.NextHalving(latest.Timestamp)
+	return model
+}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Sections of the analysis reports, which a report config enables, hides
+// and orders
+const (
+	SectionSummary    = "summary"    // performance, all-time high and low, narrative summary
+	SectionPrice      = "price"      // current price, price and volume statistics, real prices
+	SectionRisk       = "risk"       // risk metrics, volatility estimators, tail risk, diagnostics, percentiles, implied volatility
+	SectionValuation  = "valuation"  // long-term valuation, stock-to-flow, NVT, market heat
+	SectionStatistics = "statistics" // mean reversion, return attribution, lead/lag
+	SectionMarket     = "market"     // order book, order flow, volume forensics, liquidity
+	SectionLevels     = "levels"     // support and resistance, level map, liquidity zones, session gaps, pivots, Fibonacci
+	SectionSignals    = "signals"    // trading signals and their thresholds
+	SectionIndicators = "indicators" // technical indicators, trend analysis, custom indicators
+	SectionPatterns   = "patterns"   // candlestick and volume patterns, Elliott waves, harmonics, Wyckoff
+	SectionBacktest   = "backtest"   // pattern outcomes, the backtest of the patterns' forward returns
+	SectionRelative   = "relative"   // premium, beta and pair against other series
+	SectionTables     = "tables"     // data tables of the technical indicators page
+	SectionText       = "text"       // full text report of the HTML report
+	SectionGlossary   = "glossary"   // glossary of the HTML report
+)
+
+// ReportSections are the sections of the reports in their default order
+var ReportSections = []string{
+	SectionSummary, SectionPrice, SectionRisk, SectionValuation, SectionStatistics,
+	SectionMarket, SectionLevels, SectionSignals, SectionIndicators, SectionPatterns,
+	SectionBacktest, SectionRelative, SectionTables, SectionText, SectionGlossary,
+}
+
+// DefaultTableRows is the number of latest records in a data table unless a
+// report config sets it
+const DefaultTableRows = 20
+
+// ValidateReportConfig checks that rc names known sections, each once, and a
+// table size that is not negative
+func ValidateReportConfig(rc types.ReportConfig) error {
+	for _, list := range []struct {
+		name     string
+		sections []string
+	}{{"sections", rc.Sections}, {"hide", rc.Hide}} {
+		seen := make(map[string]bool)
+		for _, section := range list.sections {
+			if !slices.Contains(ReportSections, section) {
+				return fmt.Errorf("unknown report section %q in %s: use %s", section, list.name, strings.Join(ReportSections, ", "))
+			}
+			if seen[section] {
+				return fmt.Errorf("report section %q is listed twice in %s", section, list.name)
+			}
+			seen[section] = true
+		}
+	}
+	if rc.TableRows < 0 {
+		return fmt.Errorf("invalid report table_rows %d: must not be negative", rc.TableRows)
+	}
+	return nil
+}
+
+// SectionOrder returns the sections rc shows, in order: its sections, or all
+// of ReportSections, without the hidden ones
+func SectionOrder(rc types.ReportConfig) []string {
+	sections := rc.Sections
+	if len(sections) == 0 {
+		sections = ReportSections
+	}
+	var order []string
+	for _, section := range sections {
+		if !slices.Contains(rc.Hide, section) {
+			order = append(order, section)
+		}
+	}
+	return order
+}
+
+// ShowsSection reports whether rc shows section
+func ShowsSection(rc types.ReportConfig, section string) bool {
+	return slices.Contains(SectionOrder(rc), section)
+}
+
+// TableRows returns the number of latest records in each data table of rc
+func TableRows(rc types.ReportConfig) int {
+	if rc.TableRows == 0 {
+		return DefaultTableRows
+	}
+	return rc.TableRows
+}
+
+// sectionWriter collects a report as chunks, each belonging to the section
+// started before it was written. Chunks written before the first section
+// head the report and those of section "" after it end it.
+type sectionWriter struct {
+	chunks []*sectionChunk
+}
+
+type sectionChunk struct {
+	section string
+	text    strings.Builder
+}
+
+// section starts a chunk of section; "" starts the closing chunk
+func (w *sectionWriter) section(section string) {
+	w.chunks = append(w.chunks, &sectionChunk{section: section})
+}
+
+func (w *sectionWriter) current() *strings.Builder {
+	if len(w.chunks) == 0 {
+		w.section("")
+	}
+	return &w.chunks[len(w.chunks)-1].text
+}
+
+func (w *sectionWriter) Write(p []byte) (int, error) {
+	return w.current().Write(p)
+}
+
+func (w *sectionWriter) WriteString(s string) (int, error) {
+	return w.current().WriteString(s)
+}
+
+// String returns the report with the sections of rc. Without sections or
+// hidden ones the chunks keep the order they were written in; otherwise the
+// chunks of each section follow each other in the order of rc, separated by
+// one blank line.
+func (w *sectionWriter) String(rc types.ReportConfig) string {
+	var head, tail strings.Builder
+	bySection := make(map[string][]string)
+	started := false
+	for _, chunk := range w.chunks {
+		switch {
+		case chunk.section == "" && !started:
+			head.WriteString(chunk.text.String())
+		case chunk.section == "":
+			tail.WriteString(chunk.text.String())
+		default:
+			started = true
+			bySection[chunk.section] = append(bySection[chunk.section], chunk.text.String())
+		}
+	}
+
+	var report strings.Builder
+	report.WriteString(head.String())
+	closing := tail.String()
+	if len(rc.Sections) == 0 && len(rc.Hide) == 0 {
+		for _, chunk := range w.chunks {
+			if chunk.section != "" {
+				report.WriteString(chunk.text.String())
+			}
+		}
+	} else {
+		for _, section := range SectionOrder(rc) {
+			for _, text := range bySection[section] {
+				if text = strings.Trim(text, "\n"); text != "" {
+					report.WriteString(text + "\n\n")
+				}
+			}
+		}
+		closing = strings.TrimLeft(closing, "\n")
+	}
+	report.WriteString(closing)
+	return report.String()
+}
@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// DescribeSessionGaps formats the gap-fill statistics of a series with
+// trading breaks and up to three unfilled gaps nearest the latest close, for
+// the text and HTML reports. Prices are formatted in denomination d.
+func DescribeSessionGaps(sg types.SessionGapStats, d *types.Denomination) []string {
+	if len(sg.Gaps) == 0 {
+		return []string{fmt.Sprintf("No gaps between the %d trading sessions", sg.Sessions)}
+	}
+	lines := []string{fmt.Sprintf("Gaps: %d across %d trading sessions (%d up, %d down)", len(sg.Gaps), sg.Sessions, sg.Up, sg.Down)}
+	if sg.Filled == 0 {
+		lines = append(lines, "None of the gaps has filled")
+	} else {
+		lines = append(lines, fmt.Sprintf("Filled: %s, %s within the session they opened; median %.0f hours to fill",
+			numfmt.Pct(sg.FillRate), numfmt.Pct(sg.FilledInSession), sg.MedianHoursToFill))
+	}
+	for i, gap := range sg.Unfilled {
+		if i == 3 {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("Unfilled gap %s at %s, opened %s", numfmt.SignedPct(gap.Size), denom.Price(d, gap.Close), gap.Opened.Format("2006-01-02")))
+	}
+	if len(sg.Unfilled) > 3 {
+		lines = append(lines, fmt.Sprintf("%d more unfilled gaps", len(sg.Unfilled)-3))
+	}
+	return lines
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/patterns"
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// DefaultSignalConfig holds the thresholds of the trading signals unless a
+// config file or the environment overrides them
+var DefaultSignalConfig = types.SignalConfig{
+	RSIOverbought:        70,
+	RSIOversold:          30,
+	TrendThreshold:       patterns.DefaultZigZagThreshold,
+	LevelProximity:       0.02,
+	RoundNumberProximity: 0.02,
+}
+
+// ResolveSignalConfig fills the unset thresholds of sc with the defaults
+func ResolveSignalConfig(sc types.SignalConfig) types.SignalConfig {
+	if sc.RSIOverbought == 0 {
+		sc.RSIOverbought = DefaultSignalConfig.RSIOverbought
+	}
+	if sc.RSIOversold == 0 {
+		sc.RSIOversold = DefaultSignalConfig.RSIOversold
+	}
+	if sc.TrendThreshold == 0 {
+		sc.TrendThreshold = DefaultSignalConfig.TrendThreshold
+	}
+	if sc.LevelProximity == 0 {
+		sc.LevelProximity = DefaultSignalConfig.LevelProximity
+	}
+	if sc.RoundNumberProximity == 0 {
+		sc.RoundNumberProximity = DefaultSignalConfig.RoundNumberProximity
+	}
+	return sc
+}
+
+// ValidateSignalConfig checks the thresholds of sc once the unset ones take
+// their defaults
+func ValidateSignalConfig(sc types.SignalConfig) error {
+	sc = ResolveSignalConfig(sc)
+	switch {
+	case sc.RSIOversold < 0 || sc.RSIOverbought > 100 || sc.RSIOversold >= sc.RSIOverbought:
+		return fmt.Errorf("invalid RSI thresholds (overbought %g, oversold %g): need 0 <= oversold < overbought <= 100", sc.RSIOverbought, sc.RSIOversold)
+	case sc.TrendThreshold < 0 || sc.TrendThreshold >= 1:
+		return fmt.Errorf("invalid trend threshold %g: use a fraction between 0 and 1, e.g. 0.05", sc.TrendThreshold)
+	case sc.LevelProximity < 0 || sc.LevelProximity >= 1:
+		return fmt.Errorf("invalid level proximity %g: use a fraction between 0 and 1, e.g. 0.02", sc.LevelProximity)
+	case sc.RoundNumberProximity < 0 || sc.RoundNumberProximity >= 1:
+		return fmt.Errorf("invalid round number proximity %g: use a fraction between 0 and 1, e.g. 0.02", sc.RoundNumberProximity)
+	}
+	return nil
+}
+
+// DescribeSignalConfig documents the thresholds in one line for reports
+func DescribeSignalConfig(sc types.SignalConfig) string {
+	return fmt.Sprintf("RSI overbought > %g, oversold < %g; trend swings %.1f%%; levels within %.1f%%; round numbers within %.1f%%",
+		sc.RSIOverbought, sc.RSIOversold, sc.TrendThreshold*100, sc.LevelProximity*100, sc.RoundNumberProximity*100)
+}
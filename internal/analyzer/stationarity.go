@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// DescribeStationarity formats the ADF and KPSS tests of the log prices and
+// log returns with a pass or fail for each, followed by what the joint
+// verdict means for trend regressions and mean-reversion strategies
+func DescribeStationarity(st types.Stationarity) []string {
+	var lines []string
+	for _, check := range []types.StationarityCheck{st.Prices, st.Returns} {
+		lines = append(lines, describeStationarityTest(check.Series, check.ADF), describeStationarityTest(check.Series, check.KPSS))
+		lines = append(lines, fmt.Sprintf("%s: %s, %s", check.Series, check.Verdict, stationarityMeaning(check)))
+	}
+	return lines
+}
+
+// describeStationarityTest formats one test on series as PASS when it finds
+// the series stationary and FAIL when it does not
+func describeStationarityTest(series string, test types.StationarityTest) string {
+	if test.Observations == 0 {
+		return fmt.Sprintf("%s %s: not testable (too few bars, or no random variation)", series, test.Test)
+	}
+	outcome := "PASS"
+	finding := "unit root rejected"
+	if test.Test == "KPSS" {
+		finding = "stationarity not rejected"
+	}
+	if !test.Stationary {
+		outcome = "FAIL"
+		finding = "unit root not rejected"
+		if test.Test == "KPSS" {
+			finding = "stationarity rejected"
+		}
+	}
+	return fmt.Sprintf("%s %s: %.3f (5%% critical %.3f, lags %d) %s: %s",
+		series, test.Test, test.Statistic, test.Critical5, test.Lags, outcome, finding)
+}
+
+// stationarityMeaning interprets the verdict of a check for readers of the
+// report
+func stationarityMeaning(check types.StationarityCheck) string {
+	switch check.Verdict {
+	case statistics.VerdictStationary:
+		if check.Series == statistics.SeriesLogPrices {
+			return "the price reverts to a level, which supports mean-reversion strategies"
+		}
+		return "statistics and models of these returns are on solid ground"
+	case statistics.VerdictUnitRoot:
+		if check.Series == statistics.SeriesLogPrices {
+			return "the price wanders without a level to revert to, so regressions on price levels can be spurious; use returns"
+		}
+		return "the returns drift, so their averages and volatility may not carry over to other periods"
+	}
+	return "the tests disagree or the sample is too short, so treat trend and mean-reversion results with caution"
+}
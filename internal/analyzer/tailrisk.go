@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+)
+
+// DescribeTailRisk formats each tail-risk measure on one line with a short
+// interpretation, for the text and HTML reports
+func DescribeTailRisk(tr types.TailRisk) []string {
+	var lines []string
+
+	if tr.MaxLossStreak > 0 {
+		lines = append(lines, fmt.Sprintf("Longest Losing Streak: %d bars (%.1f days, %s, %s to %s)",
+			tr.MaxLossStreak, tr.MaxLossStreakDays, numfmt.SignedPct(tr.LossStreakReturn),
+			tr.LossStreakStart.Format("2006-01-02"), tr.LossStreakEnd.Format("2006-01-02")))
+	} else {
+		lines = append(lines, "Longest Losing Streak: none (no losing bars)")
+	}
+
+	lines = append(lines, fmt.Sprintf("Conditional Drawdown at Risk (95%%): %s (average depth of the worst 5%% of drawdowns)", numfmt.Pct(tr.CDaR95)))
+
+	normal, adjusted := -tr.VaR95, -tr.CornishFisherVaR95
+	tail := "close to normal"
+	switch {
+	case adjusted > normal*1.1:
+		tail = "fatter left tail than a normal distribution"
+	case adjusted < normal*0.9:
+		tail = "thinner left tail than a normal distribution"
+	}
+	lines = append(lines, fmt.Sprintf("VaR 95%% (per bar): normal %s, Cornish-Fisher %s (%s)", numfmt.Pct(normal), numfmt.Pct(adjusted), tail))
+	lines = append(lines, fmt.Sprintf("Return Skewness: %.3f, Excess Kurtosis: %.3f (%s, %s)",
+		tr.Skewness, tr.ExcessKurtosis, describeSkew(tr.Skewness), describeKurtosis(tr.ExcessKurtosis)))
+
+	penalty := "tails barely change it"
+	if diff := tr.AdjustedSharpeRatio - tr.SharpeRatio; math.Abs(diff) >= 0.05*math.Max(math.Abs(tr.SharpeRatio), 0.1) {
+		if diff < 0 {
+			penalty = "skew and fat tails make returns riskier than volatility shows"
+		} else {
+			penalty = "positive skew makes returns safer than volatility shows"
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Skew/Kurtosis-Adjusted Sharpe: %.3f vs %.3f (%s)", tr.AdjustedSharpeRatio, tr.SharpeRatio, penalty))
+
+	return lines
+}
+
+// describeSkew interprets the skewness of returns
+func describeSkew(skew float64) string {
+	switch {
+	case skew < -0.5:
+		return "large losses more likely than large gains"
+	case skew > 0.5:
+		return "large gains more likely than large losses"
+	}
+	return "roughly symmetric"
+}
+
+// describeKurtosis interprets the excess kurtosis of returns
+func describeKurtosis(kurtosis float64) string {
+	switch {
+	case kurtosis > 1:
+		return "extreme moves more frequent than normal"
+	case kurtosis < -0.5:
+		return "extreme moves rarer than normal"
+	}
+	return "near-normal tails"
+}
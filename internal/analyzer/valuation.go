@@ -0,0 +1,177 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"time"
+)
+
+// Names of the long-term valuation heuristics
+const (
+	ValuationMayer   = "Mayer Multiple"
+	ValuationPiCycle = "Pi-Cycle Top"
+	ValuationWMA200  = "200-Week MA"
+)
+
+// Mayer Multiple thresholds: historically rare overheated and undervalued readings
+const (
+	mayerOverheated  = 2.4
+	mayerUndervalued = 0.8
+)
+
+// Ratios a heuristic must return to before it can trigger again, so a
+// price hovering at a threshold records one trigger rather than many
+const (
+	mayerRearm   = 1.0
+	piCycleRearm = 0.9
+	wma200Rearm  = 1.2
+)
+
+// AnalyzeLongTermValuation computes the Mayer Multiple (price over the
+// 200-day moving average), the Pi-Cycle top indicator (111-day moving
+// average crossing twice the 350-day one) and the 200-week moving average
+// floor on daily closes, resampling intraday series. Each heuristic lists
+// the days it crossed its threshold; heuristics the history is too short
+// for are listed as unavailable.
+func AnalyzeLongTermValuation(bts *types.BTCTimeSeries) *types.LongTermValuation {
+	if len(bts.Data) == 0 {
+		return nil
+	}
+	daily := bts
+	if timeseries.DetectFrequency(bts).Interval < 24*time.Hour {
+		daily = timeseries.Resample(bts, 24*time.Hour)
+	}
+	bars := daily.Data
+	days := len(bars)
+	ltv := &types.LongTermValuation{}
+	unavailable := func(name string, needed int) {
+		ltv.Unavailable = append(ltv.Unavailable, fmt.Sprintf("%s: needs %d days, have %d", name, needed, days))
+	}
+
+	if ma := trailingSMA(bars, 200); ma != nil {
+		mayer := types.ValuationHeuristic{Name: ValuationMayer}
+		// Armed until the first reading past a threshold
+		highArmed, lowArmed := true, true
+		ratio := 0.0
+		for i := 199; i < days; i++ {
+			ratio = bars[i].Close / ma[i]
+			switch {
+			case highArmed && ratio >= mayerOverheated:
+				mayer.Triggers = append(mayer.Triggers, valuationTrigger(bars[i], fmt.Sprintf("rose above %.1f (overheated)", mayerOverheated)))
+				highArmed = false
+			case lowArmed && ratio <= mayerUndervalued:
+				mayer.Triggers = append(mayer.Triggers, valuationTrigger(bars[i], fmt.Sprintf("fell below %.1f (undervalued)", mayerUndervalued)))
+				lowArmed = false
+			}
+			highArmed = highArmed || ratio <= mayerRearm
+			lowArmed = lowArmed || ratio >= mayerRearm
+		}
+		mayer.Ratio, mayer.Level = ratio, ma[days-1]
+		ltv.Heuristics = append(ltv.Heuristics, mayer)
+	} else {
+		unavailable(ValuationMayer, 200)
+	}
+
+	short, long := trailingSMA(bars, 111), trailingSMA(bars, 350)
+	if short != nil && long != nil {
+		pi := types.ValuationHeuristic{Name: ValuationPiCycle}
+		// A series starting above the cross has not crossed
+		armed := false
+		ratio := 0.0
+		for i := 349; i < days; i++ {
+			ratio = short[i] / (2 * long[i])
+			if armed && ratio >= 1 {
+				pi.Triggers = append(pi.Triggers, valuationTrigger(bars[i], "111-day MA crossed above 2x the 350-day MA (cycle top signal)"))
+				armed = false
+			}
+			armed = armed || ratio <= piCycleRearm
+		}
+		pi.Ratio, pi.Level = ratio, 2*long[days-1]
+		ltv.Heuristics = append(ltv.Heuristics, pi)
+	} else {
+		unavailable(ValuationPiCycle, 350)
+	}
+
+	if ma := trailingSMA(bars, 1400); ma != nil {
+		wma := types.ValuationHeuristic{Name: ValuationWMA200}
+		armed := false
+		ratio := 0.0
+		for i := 1399; i < days; i++ {
+			ratio = bars[i].Close / ma[i]
+			if armed && ratio < 1 {
+				wma.Triggers = append(wma.Triggers, valuationTrigger(bars[i], "closed below the 200-week MA (historical floor)"))
+				armed = false
+			}
+			armed = armed || ratio >= wma200Rearm
+		}
+		wma.Ratio, wma.Level = ratio, ma[days-1]
+		ltv.Heuristics = append(ltv.Heuristics, wma)
+	} else {
+		unavailable(ValuationWMA200, 1400)
+	}
+
+	return ltv
+}
+
+// trailingSMA returns the simple moving average of the closes of bars over
+// period bars, indexed like bars and 0 before the first full window, or nil
+// when there are fewer than period bars
+func trailingSMA(bars []types.BTCPrice, period int) []float64 {
+	if len(bars) < period {
+		return nil
+	}
+	ma := make([]float64, len(bars))
+	sum := 0.0
+	for i, bar := range bars {
+		sum += bar.Close
+		if i >= period {
+			sum -= bars[i-period].Close
+		}
+		if i >= period-1 {
+			ma[i] = sum / float64(period)
+		}
+	}
+	return ma
+}
+
+// valuationTrigger records bar as the day a heuristic crossed its threshold
+func valuationTrigger(bar types.BTCPrice, description string) types.ValuationTrigger {
+	return types.ValuationTrigger{Timestamp: bar.Timestamp, Price: bar.Close, Description: description}
+}
+
+// DescribeValuation formats each heuristic with its latest reading and most
+// recent triggers, followed by the unavailable ones, with prices in
+// denomination d
+func DescribeValuation(ltv types.LongTermValuation, d *types.Denomination) []string {
+	var lines []string
+	for _, h := range ltv.Heuristics {
+		switch h.Name {
+		case ValuationMayer:
+			lines = append(lines, fmt.Sprintf("%s: %.2f (200-day MA %s; above %.1f overheated, below %.1f undervalued)",
+				h.Name, h.Ratio, denom.Price(d, h.Level), mayerOverheated, mayerUndervalued))
+		case ValuationPiCycle:
+			lines = append(lines, fmt.Sprintf("%s: 111-day MA at %s of 2x 350-day MA (%s; a cross above 100%% has marked cycle tops)",
+				h.Name, numfmt.Pct(h.Ratio), denom.Price(d, h.Level)))
+		case ValuationWMA200:
+			lines = append(lines, fmt.Sprintf("%s: price %.2fx the 200-week MA (%s; closes below it have marked cycle floors)",
+				h.Name, h.Ratio, denom.Price(d, h.Level)))
+		}
+		triggers := h.Triggers
+		if len(triggers) > 5 {
+			triggers = triggers[len(triggers)-5:]
+		}
+		if len(triggers) == 0 {
+			lines = append(lines, "  No triggers in the history")
+		}
+		for _, t := range triggers {
+			lines = append(lines, fmt.Sprintf("  %s at %s: %s", t.Timestamp.Format("2006-01-02"), denom.Price(d, t.Price), t.Description))
+		}
+	}
+	for _, u := range ltv.Unavailable {
+		lines = append(lines, u)
+	}
+	return lines
+}
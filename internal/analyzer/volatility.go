@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AnalyzeImpliedVolatility compares an implied volatility index (annualized
+// percentages, e.g. DVOL) with the realized volatility of the trailing
+// windowDays, annualized at the series' periods per year. Returns nil when
+// the two series do not overlap.
+func AnalyzeImpliedVolatility(bts *types.BTCTimeSeries, implied *types.AuxSeries, term []types.TermPoint, windowDays, periodsPerYear int) *types.VolatilityAnalysis {
+	if implied == nil || len(implied.Points) == 0 || len(bts.Data) < 3 {
+		return nil
+	}
+
+	returns, _ := statistics.CalculateReturns(bts)
+	window := timeseries.BarsFor(bts, time.Duration(windowDays)*24*time.Hour)
+	realized := statistics.RollingVolatility(returns, window, periodsPerYear)
+
+	va := &types.VolatilityAnalysis{WindowDays: windowDays, TermStructure: term}
+	for _, point := range implied.Points {
+		// Last bar at or before the implied volatility observation
+		bar := sort.Search(len(bts.Data), func(i int) bool {
+			return bts.Data[i].Timestamp.After(point.Timestamp)
+		}) - 1
+		if bar < 1 || realized[bar-1] <= 0 {
+			continue
+		}
+		va.History = append(va.History, types.IVRVPoint{
+			Timestamp: point.Timestamp,
+			Implied:   point.Value / 100,
+			Realized:  realized[bar-1],
+		})
+	}
+	if len(va.History) == 0 {
+		return nil
+	}
+
+	latest := va.History[len(va.History)-1]
+	va.ImpliedVol, va.RealizedVol = latest.Implied, latest.Realized
+	va.Ratio = va.ImpliedVol / va.RealizedVol
+
+	return va
+}
+
+// ImpliedVolSignal reads the IV/RV ratio contrarian-style: options pricing far
+// more movement than realized signals fear, far less signals complacency
+func ImpliedVolSignal(va *types.VolatilityAnalysis) string {
+	switch {
+	case va.Ratio > 1.5:
+		return fmt.Sprintf("BUY - Fear premium in options (IV/RV %.2f)", va.Ratio)
+	case va.Ratio < 0.8:
+		return fmt.Sprintf("SELL - Complacent options market (IV/RV %.2f)", va.Ratio)
+	}
+	return fmt.Sprintf("HOLD - Implied volatility in line with realized (IV/RV %.2f)", va.Ratio)
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Warm-up policies of the technical indicators of a run
+const (
+	WarmupFetch    = "fetch"    // load extra bars before the requested period to warm up the indicators
+	WarmupTruncate = "truncate" // leave the first loaded bars out of the reported period to warm up the indicators
+	WarmupNone     = "none"     // report every loaded bar; the indicators are undefined on the first ones
+)
+
+// WarmupPolicies are the warm-up policies in the order they are documented
+var WarmupPolicies = []string{WarmupFetch, WarmupTruncate, WarmupNone}
+
+// warmedIndicators are the technical indicators computed from the warm-up
+// bars; every other analysis reads the reported period only
+var warmedIndicators = []string{AnalysisROC, AnalysisRSI, AnalysisBollinger, AnalysisMACD}
+
+// WarmupBars returns the bars before the first value of the technical
+// indicator that needs the most, the MACD signal line. With as many bars
+// before a period every indicator is defined on its first bar.
+func WarmupBars() int {
+	bars := 0
+	for _, analysis := range warmedIndicators {
+		bars = max(bars, MinBars(analysis)-1)
+	}
+	return bars
+}
+
+// ValidateWarmupPolicy checks that policy is one of WarmupPolicies
+func ValidateWarmupPolicy(policy string) error {
+	if !slices.Contains(WarmupPolicies, policy) {
+		return fmt.Errorf("invalid warm-up policy %q: use %s", policy, strings.Join(WarmupPolicies, ", "))
+	}
+	return nil
+}
+
+// SplitWarmup splits bts under policy into the reported period, the bars
+// after start (all of them for a zero start), and the warm-up bars before
+// it. When fewer than WarmupBars bars come before start, the first bars of
+// the period make up the difference and are left out of it, as long as the
+// period keeps WarmupBars bars; a shorter series leaves the indicators
+// undefined on the first bars of its period. Under WarmupNone every bar is
+// reported and none warms up.
+func SplitWarmup(bts *types.BTCTimeSeries, policy string, start time.Time) (*types.BTCTimeSeries, []types.BTCPrice, types.WarmupInfo) {
+	required := WarmupBars()
+	info := types.WarmupInfo{Policy: policy, Required: required}
+	if policy == WarmupNone {
+		return bts, nil, info
+	}
+
+	split := 0
+	if !start.IsZero() {
+		split = sort.Search(len(bts.Data), func(i int) bool { return bts.Data[i].Timestamp.After(start) })
+	}
+	info.Bars = split
+	if short := required - split; short > 0 {
+		info.Truncated = max(min(short, len(bts.Data)-split-required), 0)
+		split += info.Truncated
+	}
+
+	period := *bts
+	period.Data = bts.Data[split:]
+	return &period, bts.Data[:split], info
+}
+
+// DescribeWarmup describes how the indicators of a result were warmed up
+func DescribeWarmup(w types.WarmupInfo) string {
+	if w.Policy == WarmupNone {
+		return fmt.Sprintf("none, indicators are undefined on the first %d bars", w.Required)
+	}
+	var parts []string
+	if w.Bars > 0 {
+		parts = append(parts, fmt.Sprintf("%d bars before the period", w.Bars))
+	}
+	if w.Truncated > 0 {
+		parts = append(parts, fmt.Sprintf("the first %d loaded bars, left out of the period", w.Truncated))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no bars")
+	}
+	description := w.Policy + ", " + strings.Join(parts, " and ")
+	if w.Bars+w.Truncated < w.Required {
+		description += fmt.Sprintf("; too few for the %d bars needed, so indicators start late", w.Required)
+	}
+	return description
+}
+
+// WithWarmup returns bts preceded by the warm-up bars, bts itself without any
+func WithWarmup(bts *types.BTCTimeSeries, warmup []types.BTCPrice) *types.BTCTimeSeries {
+	if len(warmup) == 0 {
+		return bts
+	}
+	full := *bts
+	full.Data = append(append(make([]types.BTCPrice, 0, len(warmup)+len(bts.Data)), warmup...), bts.Data...)
+	return &full
+}
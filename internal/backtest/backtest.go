@@ -0,0 +1,153 @@
+// Package backtest evaluates trading strategies on a price series, long-only
+// except for spread strategies. A strategy is a position per bar, held from
+// that bar's close to the next.
+package backtest
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+	"time"
+)
+
+// BuyAndHold is the name of the benchmark strategy that is always long
+const BuyAndHold = "buy-and-hold"
+
+// PairsTrade is the name of the z-score template on the spread of a pair
+const PairsTrade = "pairs"
+
+// Strategy is a named position series with the parameters that define it
+type Strategy struct {
+	Name      string
+	Params    map[string]string
+	Positions []float64 // position after the close of each bar: 1 long, 0 flat, -1 short for spreads
+	Returns   []float64 // gross per-period returns when not those of Positions in the series, e.g. of a spread
+}
+
+// HoldPositions returns the positions of buy-and-hold over bars bars
+func HoldPositions(bars int) []float64 {
+	positions := make([]float64, bars)
+	for i := range positions {
+		positions[i] = 1
+	}
+	return positions
+}
+
+// RulePositions returns the positions of a strategy that goes long at the
+// close of each buy bar and flat at the close of the next sell bar. A bar
+// with both a buy and a sell ends flat.
+func RulePositions(bars int, buys, sells []int) []float64 {
+	type event struct {
+		bar int
+		buy bool
+	}
+	var events []event
+	for _, bar := range buys {
+		events = append(events, event{bar, true})
+	}
+	for _, bar := range sells {
+		events = append(events, event{bar, false})
+	}
+	// Buys first, so a sell on the same bar wins
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].bar != events[j].bar {
+			return events[i].bar < events[j].bar
+		}
+		return events[i].buy && !events[j].buy
+	})
+
+	positions := make([]float64, bars)
+	position, next := 0.0, 0
+	for i := range positions {
+		for next < len(events) && events[next].bar == i {
+			if events[next].buy {
+				position = 1
+			} else {
+				position = 0
+			}
+			next++
+		}
+		positions[i] = position
+	}
+	return positions
+}
+
+// Returns returns the per-period returns of holding positions over bts:
+// period i runs from the close of bar i to the close of bar i+1
+func Returns(bts *types.BTCTimeSeries, positions []float64) []float64 {
+	if len(bts.Data) < 2 {
+		return nil
+	}
+	returns := make([]float64, len(bts.Data)-1)
+	for i := range returns {
+		prev := bts.Data[i].Close
+		if prev > 0 && i < len(positions) {
+			returns[i] = positions[i] * (bts.Data[i+1].Close/prev - 1)
+		}
+	}
+	return returns
+}
+
+// CAGR returns the compound annual growth rate of a total return earned from
+// start to end. Unlike the annualized mean return it always has the sign of
+// the total return. Periods under a day are not annualized.
+func CAGR(totalReturn float64, start, end time.Time) float64 {
+	years := end.Sub(start).Hours() / 24 / 365.25
+	if years < 1.0/365.25 || totalReturn <= -1 {
+		return math.Max(totalReturn, -1)
+	}
+	return math.Pow(1+totalReturn, 1/years) - 1
+}
+
+// Evaluate computes the metrics of per-period strategy returns, where
+// positions[i] was held over returns[i]. Trades are the entries into a
+// position; a trade still open at the end is closed at the last period.
+func Evaluate(returns, positions []float64, rc types.RiskConfig) types.BacktestMetrics {
+	var m types.BacktestMetrics
+	if len(returns) == 0 {
+		return m
+	}
+
+	equity, peak := 1.0, 1.0
+	tradeGrowth, wins, held := 1.0, 0, 0
+	inTrade := false
+	for i, r := range returns {
+		position := 0.0
+		if i < len(positions) {
+			position = positions[i]
+		}
+		if position != 0 {
+			held++
+			if !inTrade {
+				inTrade, tradeGrowth = true, 1
+				m.Trades++
+			}
+			tradeGrowth *= 1 + r
+		} else if inTrade {
+			inTrade = false
+			if tradeGrowth > 1 {
+				wins++
+			}
+		}
+
+		equity *= 1 + r
+		peak = math.Max(peak, equity)
+		if drawdown := (peak - equity) / peak; drawdown > m.MaxDrawdown {
+			m.MaxDrawdown = drawdown
+		}
+	}
+	if inTrade && tradeGrowth > 1 {
+		wins++
+	}
+
+	m.TotalReturn = equity - 1
+	m.AnnualizedReturn = statistics.AnnualizeReturns(returns, rc)
+	m.Volatility = statistics.CalculateVolatility(returns, rc.PeriodsPerYear)
+	m.SharpeRatio = statistics.SharpeRatio(returns, rc)
+	m.Exposure = float64(held) / float64(len(returns))
+	if m.Trades > 0 {
+		m.WinRate = float64(wins) / float64(m.Trades)
+	}
+	return m
+}
@@ -0,0 +1,75 @@
+package backtest
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// DefaultCostSweep is the range of costs per trade, in basis points, that a
+// cost sensitivity sweep covers
+var DefaultCostSweep = []float64{0, 5, 10, 15, 20, 25, 30, 40, 50}
+
+// NetReturns returns the returns net of a cost of costBps basis points of
+// every change of position. A change at the close of bar i is charged to
+// period i; the position still open at the end is not charged for closing.
+func NetReturns(returns, positions []float64, costBps float64) []float64 {
+	cost := costBps / 10000
+	net := make([]float64, len(returns))
+	previous := 0.0
+	for i, r := range returns {
+		position := 0.0
+		if i < len(positions) {
+			position = positions[i]
+		}
+		turnover := math.Abs(position - previous)
+		previous = position
+		net[i] = (1+r)*(1-cost*turnover) - 1
+	}
+	return net
+}
+
+// CostSensitivity evaluates gross returns at each cost in costs, in basis
+// points per trade
+func CostSensitivity(returns, positions []float64, costs []float64, rc types.RiskConfig) []types.CostPoint {
+	points := make([]types.CostPoint, 0, len(costs))
+	for _, cost := range costs {
+		net := NetReturns(returns, positions, cost)
+		points = append(points, types.CostPoint{
+			CostBps:          cost,
+			TotalReturn:      totalReturn(net),
+			AnnualizedReturn: statistics.AnnualizeReturns(net, rc),
+			SharpeRatio:      statistics.SharpeRatio(net, rc),
+		})
+	}
+	return points
+}
+
+// BreakEvenCost returns the cost per trade, in basis points, at which the
+// total return of gross returns falls to zero, or 0 when the strategy does
+// not gain before costs or never trades
+func BreakEvenCost(returns, positions []float64) float64 {
+	if totalReturn(returns) <= 0 || totalReturn(NetReturns(returns, positions, 10000)) >= 0 {
+		return 0
+	}
+	// The total return falls as the cost rises, and a cost of 100% loses everything
+	low, high := 0.0, 10000.0
+	for high-low > 0.01 {
+		mid := (low + high) / 2
+		if totalReturn(NetReturns(returns, positions, mid)) > 0 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// totalReturn compounds per-period returns
+func totalReturn(returns []float64) float64 {
+	growth := 1.0
+	for _, r := range returns {
+		growth *= 1 + r
+	}
+	return growth - 1
+}
@@ -0,0 +1,64 @@
+package backtest
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// Equity returns the growth of 1 under per-period returns: the value before
+// the first period, then after each
+func Equity(returns []float64) []float64 {
+	equity := make([]float64, len(returns)+1)
+	equity[0] = 1
+	for i, r := range returns {
+		equity[i+1] = equity[i] * (1 + r)
+	}
+	return equity
+}
+
+// Underwater returns the drawdown of the equity of returns from its running
+// peak at every point of Equity, as a fraction at or below zero
+func Underwater(returns []float64) []float64 {
+	equity := Equity(returns)
+	drawdowns := make([]float64, len(equity))
+	peak := equity[0]
+	for i, v := range equity {
+		peak = math.Max(peak, v)
+		if peak > 0 {
+			drawdowns[i] = v/peak - 1
+		}
+	}
+	return drawdowns
+}
+
+// RollingSharpe returns the annualized Sharpe ratio of every window of
+// window consecutive returns, the first ending at returns[window-1]
+func RollingSharpe(returns []float64, window int, rc types.RiskConfig) []float64 {
+	if window < 2 || len(returns) < window {
+		return nil
+	}
+	sharpes := make([]float64, len(returns)-window+1)
+	for i := range sharpes {
+		sharpes[i] = statistics.SharpeRatio(returns[i:i+window], rc)
+	}
+	return sharpes
+}
+
+// MonthlyReturns compounds the per-period returns over bts by the calendar
+// month of the bar each period ends at, in time order
+func MonthlyReturns(bts *types.BTCTimeSeries, returns []float64) []types.MonthlyReturn {
+	var months []types.MonthlyReturn
+	for i, r := range returns {
+		if i+1 >= len(bts.Data) {
+			break
+		}
+		end := bts.Data[i+1].Timestamp
+		if n := len(months); n == 0 || months[n-1].Year != end.Year() || months[n-1].Month != end.Month() {
+			months = append(months, types.MonthlyReturn{Year: end.Year(), Month: end.Month()})
+		}
+		last := &months[len(months)-1]
+		last.Return = (1+last.Return)*(1+r) - 1
+	}
+	return months
+}
@@ -0,0 +1,85 @@
+package backtest
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"math"
+	"math/rand"
+)
+
+// MinMonteCarloTrades is the fewest trades worth resampling
+const MinMonteCarloTrades = 5
+
+// TradeReturns returns the compounded return of each trade, a run of
+// periods in a position. returns and positions are per period, as for
+// Evaluate.
+func TradeReturns(returns, positions []float64) []float64 {
+	var trades []float64
+	growth, inTrade := 1.0, false
+	for i, r := range returns {
+		if i < len(positions) && positions[i] != 0 {
+			if !inTrade {
+				growth, inTrade = 1, true
+			}
+			growth *= 1 + r
+		} else if inTrade {
+			trades = append(trades, growth-1)
+			inTrade = false
+		}
+	}
+	if inTrade {
+		trades = append(trades, growth-1)
+	}
+	return trades
+}
+
+// MonteCarlo draws sims sequences of as many trades as trades, sampled from
+// them with replacement, and returns the final return and the maximum
+// drawdown of the equity after each trade of every sequence. Drawdowns
+// within a trade are not seen, so they are a lower bound.
+func MonteCarlo(trades []float64, sims int, rng *rand.Rand) (finals, drawdowns []float64) {
+	if len(trades) == 0 {
+		return nil, nil
+	}
+	finals = make([]float64, sims)
+	drawdowns = make([]float64, sims)
+	for s := 0; s < sims; s++ {
+		equity, peak, maxDrawdown := 1.0, 1.0, 0.0
+		for range trades {
+			equity *= 1 + trades[rng.Intn(len(trades))]
+			peak = math.Max(peak, equity)
+			maxDrawdown = math.Max(maxDrawdown, (peak-equity)/peak)
+		}
+		finals[s] = equity - 1
+		drawdowns[s] = maxDrawdown
+	}
+	return finals, drawdowns
+}
+
+// SummarizeMonteCarlo summarizes simulated final returns and drawdowns. A
+// sequence is ruined when its drawdown reaches ruin.
+func SummarizeMonteCarlo(finals, drawdowns []float64, trades int, ruin float64) types.MonteCarloSummary {
+	summary := types.MonteCarloSummary{Simulations: len(finals), Trades: trades, RuinDrawdown: ruin}
+	if len(finals) == 0 {
+		return summary
+	}
+
+	summary.FinalReturnP5 = statistics.Quantile(finals, 0.05)
+	summary.FinalReturnMedian = statistics.Quantile(finals, 0.5)
+	summary.FinalReturnP95 = statistics.Quantile(finals, 0.95)
+	summary.DrawdownMedian = statistics.Quantile(drawdowns, 0.5)
+	summary.DrawdownP95 = statistics.Quantile(drawdowns, 0.95)
+
+	var losses, ruined int
+	for i := range finals {
+		if finals[i] < 0 {
+			losses++
+		}
+		if drawdowns[i] >= ruin {
+			ruined++
+		}
+	}
+	summary.ProbabilityOfLoss = float64(losses) / float64(len(finals))
+	summary.RiskOfRuin = float64(ruined) / float64(len(finals))
+	return summary
+}
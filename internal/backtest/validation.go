@@ -0,0 +1,203 @@
+package backtest
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+)
+
+// eulerGamma is the Euler-Mascheroni constant used in the expected maximum
+// Sharpe ratio of the deflated Sharpe ratio
+const eulerGamma = 0.5772156649015329
+
+// ValidationConfig controls how a backtest is split for validation
+type ValidationConfig struct {
+	TrainFraction float64 // leading share of the periods in the train/test split
+	Folds         int     // purged k-fold splits (0 or 1 = none)
+	Purge         int     // periods dropped from training on each side of a test fold
+	PBOBlocks     int     // blocks of the combinatorial split behind PBO (even)
+}
+
+// DefaultValidationConfig returns a 70/30 split, 5 folds purged by 10
+// periods and 8 PBO blocks
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{TrainFraction: 0.7, Folds: 5, Purge: 10, PBOBlocks: 8}
+}
+
+// Validate splits the returns of a strategy on bts into a chronological
+// train/test split and purged k-fold splits. returns and positions are per
+// period, as for Evaluate. The training periods of a fold are evaluated as
+// one concatenated series.
+func Validate(bts *types.BTCTimeSeries, returns, positions []float64, cfg ValidationConfig, rc types.RiskConfig) types.BacktestValidation {
+	v := types.BacktestValidation{TrainFraction: cfg.TrainFraction, Purge: cfg.Purge}
+	n := len(returns)
+	if n < 2 {
+		return v
+	}
+
+	split := int(float64(n) * cfg.TrainFraction)
+	if split > 0 && split < n {
+		v.InSample = Evaluate(returns[:split], positions[:split], rc)
+		v.OutOfSample = Evaluate(returns[split:], positions[split:], rc)
+	}
+
+	if cfg.Folds < 2 || n < cfg.Folds*2 {
+		return v
+	}
+	var testSharpe float64
+	for k := 0; k < cfg.Folds; k++ {
+		start, end := k*n/cfg.Folds, (k+1)*n/cfg.Folds
+
+		var trainReturns, trainPositions []float64
+		for i := 0; i < n; i++ {
+			if i >= start-cfg.Purge && i < end+cfg.Purge {
+				continue
+			}
+			trainReturns = append(trainReturns, returns[i])
+			trainPositions = append(trainPositions, positions[i])
+		}
+
+		fold := types.BacktestFold{
+			TestStart: bts.Data[start].Timestamp,
+			TestEnd:   bts.Data[end].Timestamp,
+			Train:     Evaluate(trainReturns, trainPositions, rc),
+			Test:      Evaluate(returns[start:end], positions[start:end], rc),
+		}
+		testSharpe += fold.Test.SharpeRatio
+		v.Folds = append(v.Folds, fold)
+	}
+	v.MeanTestSharpe = testSharpe / float64(len(v.Folds))
+	return v
+}
+
+// DeflatedSharpe returns the probability that the true Sharpe ratio of
+// returns exceeds the highest Sharpe ratio expected by chance among trials
+// strategies whose per-period Sharpe ratios have variance trialVariance
+// (Bailey and López de Prado). With one trial it is the probabilistic Sharpe
+// ratio against zero. Skewness and fat tails of the returns widen the
+// uncertainty of the estimate.
+func DeflatedSharpe(returns []float64, trials int, trialVariance float64, rc types.RiskConfig) float64 {
+	if len(returns) < 3 {
+		return 0
+	}
+	stats := statistics.Calculate(returns)
+	if stats.StdDev == 0 {
+		return 0
+	}
+	sr := (stats.Mean - statistics.PeriodHurdle(rc)) / stats.StdDev
+
+	var benchmark float64
+	if trials > 1 && trialVariance > 0 {
+		n := float64(trials)
+		benchmark = math.Sqrt(trialVariance) *
+			((1-eulerGamma)*normalQuantile(1-1/n) + eulerGamma*normalQuantile(1-1/(n*math.E)))
+	}
+
+	// Kurtosis is excess kurtosis, so (k+3-1)/4
+	variance := 1 - stats.Skewness*sr + (stats.Kurtosis+2)/4*sr*sr
+	if variance <= 0 {
+		return 0
+	}
+	z := (sr - benchmark) * math.Sqrt(float64(len(returns)-1)) / math.Sqrt(variance)
+	return normalCDF(z)
+}
+
+// PeriodSharpe is the per-period Sharpe ratio of returns, the unit in which
+// DeflatedSharpe takes the variance of the trials
+func PeriodSharpe(returns []float64, rc types.RiskConfig) float64 {
+	stats := statistics.Calculate(returns)
+	if stats.StdDev == 0 {
+		return 0
+	}
+	return (stats.Mean - statistics.PeriodHurdle(rc)) / stats.StdDev
+}
+
+// ProbabilityOfOverfitting estimates the probability of backtest overfitting
+// of selecting the best of several strategies by Sharpe ratio, with
+// combinatorially symmetric cross-validation: the periods are cut into
+// blocks, and for every way of choosing half the blocks for training, the
+// strategy that is best in training is ranked on the other half. PBO is the
+// share of combinations where it ranks in the bottom half. It returns the
+// estimate and the number of combinations, 0 when there are fewer than two
+// strategies or too few periods.
+func ProbabilityOfOverfitting(returns [][]float64, blocks int, rc types.RiskConfig) (float64, int) {
+	if len(returns) < 2 || blocks < 2 || blocks%2 != 0 {
+		return 0, 0
+	}
+	n := len(returns[0])
+	for _, r := range returns {
+		n = min(n, len(r))
+	}
+	if n < blocks*2 {
+		return 0, 0
+	}
+
+	var overfit, splits int
+	for _, train := range combinations(blocks, blocks/2) {
+		inTrain := make([]bool, blocks)
+		for _, b := range train {
+			inTrain[b] = true
+		}
+
+		trainSharpe := make([]float64, len(returns))
+		testSharpe := make([]float64, len(returns))
+		for s, r := range returns {
+			var trainReturns, testReturns []float64
+			for b := 0; b < blocks; b++ {
+				block := r[b*n/blocks : (b+1)*n/blocks]
+				if inTrain[b] {
+					trainReturns = append(trainReturns, block...)
+				} else {
+					testReturns = append(testReturns, block...)
+				}
+			}
+			trainSharpe[s] = PeriodSharpe(trainReturns, rc)
+			testSharpe[s] = PeriodSharpe(testReturns, rc)
+		}
+
+		best := 0
+		for s := range trainSharpe {
+			if trainSharpe[s] > trainSharpe[best] {
+				best = s
+			}
+		}
+		// Relative rank of the selected strategy out of sample, in (0, 1)
+		ranked := append([]float64(nil), testSharpe...)
+		sort.Float64s(ranked)
+		rank := sort.SearchFloat64s(ranked, testSharpe[best]) + 1
+		omega := float64(rank) / float64(len(returns)+1)
+		if math.Log(omega/(1-omega)) <= 0 {
+			overfit++
+		}
+		splits++
+	}
+	return float64(overfit) / float64(splits), splits
+}
+
+// combinations returns every k-element subset of 0..n-1
+func combinations(n, k int) [][]int {
+	var result [][]int
+	var pick func(start int, chosen []int)
+	pick = func(start int, chosen []int) {
+		if len(chosen) == k {
+			result = append(result, append([]int(nil), chosen...))
+			return
+		}
+		for i := start; i <= n-(k-len(chosen)); i++ {
+			pick(i+1, append(chosen, i))
+		}
+	}
+	pick(0, nil)
+	return result
+}
+
+// normalCDF is the standard normal cumulative distribution function
+func normalCDF(z float64) float64 {
+	return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+}
+
+// normalQuantile is the inverse of normalCDF
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
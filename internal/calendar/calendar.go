@@ -0,0 +1,165 @@
+package calendar
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/indicators"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Event is one calendar entry. All-day events use only the date of Start.
+type Event struct {
+	UID         string
+	Start       time.Time
+	Duration    time.Duration
+	AllDay      bool
+	Summary     string
+	Description string
+}
+
+// ProjectCrossover extrapolates the gap between the 50- and 200-day SMAs of
+// the daily closes over the last lookback days and returns the day the gap
+// would close: a golden cross when the 50-day is rising through the 200-day,
+// a death cross when falling through it. It returns nil when there is too
+// little data, the gap is widening, or the cross lies beyond horizon days.
+func ProjectCrossover(bts *types.BTCTimeSeries, lookback, horizon int) *Event {
+	daily := bts
+	if timeseries.DetectFrequency(bts).Interval < 24*time.Hour {
+		daily = timeseries.ResampleToDaily(bts)
+	}
+
+	fast := indicators.CalculateMovingAverage(daily, 50)
+	slow := indicators.CalculateMovingAverage(daily, 200)
+	if len(slow) <= lookback || lookback < 1 {
+		return nil
+	}
+	// Align the fast SMA with the shorter slow SMA, both end on the last bar
+	fast = fast[len(fast)-len(slow):]
+
+	gap := fast[len(fast)-1] - slow[len(slow)-1]
+	pastGap := fast[len(fast)-1-lookback] - slow[len(slow)-1-lookback]
+	slope := (gap - pastGap) / float64(lookback)
+	if slope == 0 || math.Signbit(slope) == math.Signbit(gap) {
+		return nil
+	}
+
+	days := -gap / slope
+	if days > float64(horizon) {
+		return nil
+	}
+
+	kind, direction := "Golden cross", "above"
+	if gap > 0 {
+		kind, direction = "Death cross", "below"
+	}
+	last := daily.Data[len(daily.Data)-1].Timestamp
+	day := last.Add(time.Duration(math.Ceil(days)) * 24 * time.Hour)
+
+	return &Event{
+		UID:     fmt.Sprintf("%s-%s@btc-analyzer", strings.ToLower(strings.ReplaceAll(kind, " ", "-")), day.Format("20060102")),
+		Start:   day,
+		AllDay:  true,
+		Summary: fmt.Sprintf("%s %s (projected)", bts.Symbol, kind),
+		Description: fmt.Sprintf("SMA50 %.2f would cross %s SMA200 %.2f in about %.0f days if the gap keeps closing at %.2f per day (trend of the last %d days).",
+			fast[len(fast)-1], direction, slow[len(slow)-1], days, math.Abs(slope), lookback),
+	}
+}
+
+// DCASchedule returns count recurring buys of amount, every interval from start
+func DCASchedule(symbol string, start time.Time, every time.Duration, count int, amount float64) []Event {
+	var events []Event
+	for i := 0; i < count; i++ {
+		day := start.Add(time.Duration(i) * every)
+		events = append(events, Event{
+			UID:         fmt.Sprintf("dca-%s@btc-analyzer", day.Format("20060102T1504")),
+			Start:       day,
+			Duration:    30 * time.Minute,
+			Summary:     fmt.Sprintf("DCA buy %s: %s", symbol, denom.Price(nil, amount)),
+			Description: fmt.Sprintf("Scheduled buy %d of %d, every %s.", i+1, count, every),
+		})
+	}
+	return events
+}
+
+// OptionExpiries returns one event per option expiry of the term structure
+func OptionExpiries(symbol string, term []types.TermPoint) []Event {
+	var events []Event
+	for _, point := range term {
+		events = append(events, Event{
+			UID:         fmt.Sprintf("expiry-%s@btc-analyzer", point.Expiry.Format("20060102")),
+			Start:       point.Expiry,
+			Duration:    time.Hour,
+			Summary:     fmt.Sprintf("%s option expiry", symbol),
+			Description: fmt.Sprintf("Deribit options expire at 08:00 UTC. ATM implied volatility %s.", numfmt.Pct(point.ATMIV)),
+		})
+	}
+	return events
+}
+
+// WriteICS writes events to filename as an iCalendar (RFC 5545) file
+func WriteICS(filename string, events []Event) error {
+	if err := os.WriteFile(filename, []byte(FormatICS(events)), 0644); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	return nil
+}
+
+// FormatICS renders events as an iCalendar document
+func FormatICS(events []Event) string {
+	var b strings.Builder
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//btc-analyzer//Predicted Events//EN")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "X-WR-CALNAME:Bitcoin Analyzer Events")
+	for _, event := range events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+event.UID)
+		writeLine(&b, "DTSTAMP:"+stamp)
+		if event.AllDay {
+			writeLine(&b, "DTSTART;VALUE=DATE:"+event.Start.Format("20060102"))
+			writeLine(&b, "DTEND;VALUE=DATE:"+event.Start.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			writeLine(&b, "DTSTART:"+event.Start.UTC().Format("20060102T150405Z"))
+			writeLine(&b, "DTEND:"+event.Start.Add(event.Duration).UTC().Format("20060102T150405Z"))
+		}
+		writeLine(&b, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+	writeLine(&b, "END:VCALENDAR")
+
+	return b.String()
+}
+
+// writeLine writes a content line terminated by CRLF, folded at 75 octets
+// (the leading space of a continuation line counts towards them)
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Do not split a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// escapeText escapes backslashes, semicolons, commas and newlines in a TEXT value
+func escapeText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
@@ -0,0 +1,276 @@
+// Package candlelog keeps an append-only NDJSON record of closed candles.
+// Each candle is one line written with a single append and synced to disk,
+// so a crash can at most cut off the last line, which Open removes. The log
+// rotates into segment files by size or by UTC day.
+package candlelog
+
+import (
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures a candle log
+type Config struct {
+	Path     string // active log file, e.g. data/candles.ndjson
+	MaxBytes int64  // rotate before the active file grows past this; 0 = no limit
+	Daily    bool   // rotate when a candle starts a new UTC day
+}
+
+// Log appends closed candles to the active file of a Config
+type Log struct {
+	mu    sync.Mutex
+	cfg   Config
+	file  *os.File
+	size  int64
+	first time.Time // first candle in the active file, zero when it is empty
+	last  time.Time // last candle logged in any file
+}
+
+// Open opens the log, creating the active file if needed. A partial last
+// line left by a crash is truncated. Logging resumes after the last candle
+// of the active file or, when it is empty, of the newest segment.
+func Open(cfg Config) (*Log, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("candle log path is empty")
+	}
+	if dir := filepath.Dir(cfg.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create candle log directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(cfg.Path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open candle log: %w", err)
+	}
+	l := &Log{cfg: cfg, file: file}
+	bars, size, err := readCandles(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to repair candle log: %w", err)
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open candle log: %w", err)
+	}
+	l.size = size
+
+	if len(bars) > 0 {
+		l.first, l.last = bars[0].Timestamp, bars[len(bars)-1].Timestamp
+	} else if segments, err := Segments(cfg.Path); err == nil && len(segments) > 0 {
+		bars, err := readFile(segments[len(segments)-1])
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if len(bars) > 0 {
+			l.last = bars[len(bars)-1].Timestamp
+		}
+	}
+	return l, nil
+}
+
+// Last returns the time of the last logged candle, zero when the log is empty
+func (l *Log) Last() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
+
+// Append logs the candles after the last logged one, in time order, and
+// returns how many were written
+func (l *Log) Append(bars []types.BTCPrice) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	written := 0
+	for _, bar := range bars {
+		if !bar.Timestamp.After(l.last) {
+			continue
+		}
+		line, err := json.Marshal(bar)
+		if err != nil {
+			return written, fmt.Errorf("failed to encode candle: %w", err)
+		}
+		line = append(line, '\n')
+
+		if l.shouldRotate(bar.Timestamp, int64(len(line))) {
+			if err := l.rotate(); err != nil {
+				return written, err
+			}
+		}
+		if _, err := l.file.Write(line); err != nil {
+			return written, fmt.Errorf("failed to append candle: %w", err)
+		}
+		if l.size == 0 {
+			l.first = bar.Timestamp
+		}
+		l.size += int64(len(line))
+		l.last = bar.Timestamp
+		written++
+	}
+	if written > 0 {
+		if err := l.file.Sync(); err != nil {
+			return written, fmt.Errorf("failed to sync candle log: %w", err)
+		}
+	}
+	return written, nil
+}
+
+// shouldRotate reports whether the candle at t, encoded in n bytes, starts a
+// new segment
+func (l *Log) shouldRotate(t time.Time, n int64) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.cfg.MaxBytes > 0 && l.size+n > l.cfg.MaxBytes {
+		return true
+	}
+	return l.cfg.Daily && !sameDay(l.first, t)
+}
+
+// rotate renames the active file to a segment named after its first candle
+// and starts a new, empty active file
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close candle log: %w", err)
+	}
+	if err := os.Rename(l.cfg.Path, segmentPath(l.cfg.Path, l.first)); err != nil {
+		return fmt.Errorf("failed to rotate candle log: %w", err)
+	}
+	file, err := os.OpenFile(l.cfg.Path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open candle log: %w", err)
+	}
+	l.file, l.size, l.first = file, 0, time.Time{}
+	return nil
+}
+
+// Close closes the active file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Closed returns the candles of bts that have closed by now: all but a last
+// candle whose interval, as detected from the series, has not ended yet
+func Closed(bts *types.BTCTimeSeries, now time.Time) []types.BTCPrice {
+	if len(bts.Data) == 0 {
+		return nil
+	}
+	interval := timeseries.DetectFrequency(bts).Interval
+	last := bts.Data[len(bts.Data)-1]
+	if interval > 0 && last.Timestamp.Add(interval).After(now) {
+		return bts.Data[:len(bts.Data)-1]
+	}
+	return bts.Data
+}
+
+// Load reads every segment of the log at path and its active file into one
+// series, skipping a partial last line
+func Load(path, symbol string) (*types.BTCTimeSeries, error) {
+	segments, err := Segments(path)
+	if err != nil {
+		return nil, err
+	}
+	bts := timeseries.New(symbol)
+	for _, file := range append(segments, path) {
+		bars, err := readFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, bar := range bars {
+			timeseries.AddPrice(bts, bar)
+		}
+	}
+	return bts, nil
+}
+
+// Segments returns the rotated segment files of the log at path, oldest first
+func Segments(path string) ([]string, error) {
+	base, ext := splitExt(path)
+	segments, err := filepath.Glob(base + "-*" + ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list candle log segments: %w", err)
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// segmentPath names the segment whose first candle is at first; names sort
+// in time order
+func segmentPath(path string, first time.Time) string {
+	base, ext := splitExt(path)
+	return fmt.Sprintf("%s-%s%s", base, first.UTC().Format("20060102T150405Z"), ext)
+}
+
+// splitExt splits path into the part before its extension and the extension
+func splitExt(path string) (string, string) {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext), ext
+}
+
+// sameDay reports whether a and b fall on the same UTC day
+func sameDay(a, b time.Time) bool {
+	ya, ma, da := a.UTC().Date()
+	yb, mb, db := b.UTC().Date()
+	return ya == yb && ma == mb && da == db
+}
+
+// readFile reads the complete candle lines of a log file
+func readFile(path string) ([]types.BTCPrice, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	bars, _, err := readCandles(file)
+	return bars, err
+}
+
+// readCandles decodes the complete lines of r and returns them with the
+// number of bytes they span. A last line without a newline is a write cut
+// off by a crash and is left out; any other undecodable line is an error.
+func readCandles(r io.Reader) ([]types.BTCPrice, int64, error) {
+	var bars []types.BTCPrice
+	var size int64
+	reader := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return bars, size, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read candle log: %w", err)
+		}
+		size += int64(len(line))
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var bar types.BTCPrice
+		if err := json.Unmarshal(line, &bar); err != nil {
+			return nil, 0, fmt.Errorf("invalid candle on line %d of the candle log: %w", n, err)
+		}
+		bars = append(bars, bar)
+	}
+}
@@ -0,0 +1,96 @@
+package comparison
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"time"
+)
+
+// EstimateBeta regresses the returns of bts on the returns of a benchmark
+// after resampling both to the coarser interval. Returns are taken between
+// consecutive bars both series have, so a benchmark that does not trade on
+// weekends is compared with BTC's move over the whole weekend. Alpha is
+// annualized at the number of aligned returns per year. A rolling regression
+// over window returns is added when there are more than window returns.
+// Returns nil when fewer than three returns align.
+func EstimateBeta(bts, benchmark *types.BTCTimeSeries, name string, window int) *types.BetaAnalysis {
+	if len(bts.Data) == 0 || len(benchmark.Data) == 0 {
+		return nil
+	}
+
+	interval := timeseries.DetectFrequency(bts).Interval
+	if benchInterval := timeseries.DetectFrequency(benchmark).Interval; benchInterval > interval {
+		interval = benchInterval
+	}
+
+	// Keyed by Unix time: time.Time keys also compare location and monotonic
+	// readings, which generated series carry
+	closes := make(map[int64]float64)
+	for _, bar := range timeseries.Resample(benchmark, interval).Data {
+		closes[bar.Timestamp.Unix()] = bar.Close
+	}
+
+	var times []time.Time
+	var assetReturns, benchReturns []float64
+	prevAsset, prevBench := 0.0, 0.0
+	for _, bar := range timeseries.Resample(bts, interval).Data {
+		bench, ok := closes[bar.Timestamp.Unix()]
+		if !ok || bench <= 0 || bar.Close <= 0 {
+			continue
+		}
+		if prevAsset > 0 {
+			times = append(times, bar.Timestamp)
+			assetReturns = append(assetReturns, bar.Close/prevAsset-1)
+			benchReturns = append(benchReturns, bench/prevBench-1)
+		}
+		prevAsset, prevBench = bar.Close, bench
+	}
+	if len(assetReturns) < 3 {
+		return nil
+	}
+
+	ba := &types.BetaAnalysis{Benchmark: name, Observations: len(assetReturns), Window: window}
+	if years := times[len(times)-1].Sub(times[0]).Hours() / (24 * 365); years > 0 {
+		ba.PeriodsPerYear = float64(len(times)-1) / years
+	}
+
+	beta, alpha := regress(benchReturns, assetReturns)
+	ba.Beta, ba.Alpha = beta, alpha*ba.PeriodsPerYear
+	ba.Correlation = statistics.CalculateCorrelation(benchReturns, assetReturns)
+	ba.RSquared = ba.Correlation * ba.Correlation
+
+	if window >= 3 && len(assetReturns) > window {
+		for end := window; end <= len(assetReturns); end++ {
+			beta, alpha := regress(benchReturns[end-window:end], assetReturns[end-window:end])
+			ba.Rolling = append(ba.Rolling, types.BetaPoint{
+				Timestamp: times[end-1],
+				Beta:      beta,
+				Alpha:     alpha * ba.PeriodsPerYear,
+			})
+		}
+	}
+	return ba
+}
+
+// regress fits y = alpha + beta*x by least squares; beta is 0 when x does
+// not vary
+func regress(x, y []float64) (beta, alpha float64) {
+	n := float64(len(x))
+	meanX, meanY := 0.0, 0.0
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX, meanY = meanX/n, meanY/n
+
+	cov, varX := 0.0, 0.0
+	for i := range x {
+		cov += (x[i] - meanX) * (y[i] - meanY)
+		varX += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if varX > 0 {
+		beta = cov / varX
+	}
+	return beta, meanY - beta*meanX
+}
@@ -0,0 +1,191 @@
+package comparison
+
+import (
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/forensics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+	"time"
+)
+
+// benfordPenalty adds to the score of sources whose volumes fail Benford's law
+var benfordPenalty = map[string]float64{
+	"marginal":      0.05,
+	"nonconforming": 0.1,
+}
+
+// CompareSources aligns the same period loaded from several sources and
+// measures how each one deviates from the per-bar consensus (the median
+// across sources). Sources are resampled to the coarsest native interval and
+// trimmed to the period they all cover. names[i] labels series[i].
+func CompareSources(names []string, series []*types.BTCTimeSeries) types.SourceComparison {
+	var result types.SourceComparison
+	if len(series) == 0 || len(names) != len(series) {
+		return result
+	}
+
+	for _, bts := range series {
+		if len(bts.Data) == 0 {
+			return result
+		}
+		if interval := timeseries.DetectFrequency(bts).Interval; interval > result.Interval {
+			result.Interval = interval
+		}
+		start, end := timeseries.GetTimeRange(bts)
+		if result.Start.IsZero() || start.After(result.Start) {
+			result.Start = start
+		}
+		if result.End.IsZero() || end.Before(result.End) {
+			result.End = end
+		}
+	}
+	result.Start = result.Start.Truncate(result.Interval)
+	result.End = result.End.Truncate(result.Interval)
+	if result.End.Before(result.Start) {
+		return result
+	}
+
+	// Bars of every source keyed by their aligned timestamp
+	aligned := make([]map[time.Time]types.BTCPrice, len(series))
+	keys := make(map[time.Time]bool)
+	for i, bts := range series {
+		aligned[i] = make(map[time.Time]types.BTCPrice)
+		for _, bar := range timeseries.Resample(bts, result.Interval).Data {
+			if bar.Timestamp.Before(result.Start) || bar.Timestamp.After(result.End) {
+				continue
+			}
+			aligned[i][bar.Timestamp] = bar
+			keys[bar.Timestamp] = true
+		}
+	}
+
+	timestamps := make([]time.Time, 0, len(keys))
+	for ts := range keys {
+		timestamps = append(timestamps, ts)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Before(timestamps[j]) })
+	result.AlignedBars = len(timestamps)
+
+	qualities := make([]types.SourceQuality, len(series))
+	divergenceSums := make([]float64, len(series))
+	divergenceCounts := make([]int, len(series))
+	volumeRatios := make([][]float64, len(series))
+
+	for _, ts := range timestamps {
+		bar := types.BarDivergence{
+			Timestamp: ts,
+			Closes:    make(map[string]float64),
+			Volumes:   make(map[string]float64),
+		}
+		var closes, volumes []float64
+		for i := range series {
+			if price, ok := aligned[i][ts]; ok {
+				bar.Closes[names[i]] = price.Close
+				bar.Volumes[names[i]] = price.Volume
+				closes = append(closes, price.Close)
+				volumes = append(volumes, price.Volume)
+			}
+		}
+
+		consensus := median(closes)
+		consensusVolume := median(volumes)
+		if consensus > 0 {
+			low, high := closes[0], closes[0]
+			for _, c := range closes {
+				low, high = math.Min(low, c), math.Max(high, c)
+			}
+			bar.Spread = (high - low) / consensus
+		}
+
+		for i := range series {
+			price, ok := aligned[i][ts]
+			if !ok {
+				qualities[i].MissingBars++
+				continue
+			}
+			if consensus > 0 && len(closes) > 1 {
+				divergence := math.Abs(price.Close-consensus) / consensus
+				divergenceSums[i] += divergence
+				divergenceCounts[i]++
+				if divergence > qualities[i].MaxDivergence {
+					qualities[i].MaxDivergence = divergence
+					qualities[i].MaxDivergenceAt = ts
+				}
+			}
+			if consensusVolume > 0 {
+				volumeRatios[i] = append(volumeRatios[i], price.Volume/consensusVolume)
+			}
+		}
+
+		result.Bars = append(result.Bars, bar)
+	}
+
+	for i, bts := range series {
+		q := &qualities[i]
+		q.Source = names[i]
+		q.Bars = len(aligned[i])
+		if divergenceCounts[i] > 0 {
+			q.MeanDivergence = divergenceSums[i] / float64(divergenceCounts[i])
+		}
+		q.VolumeRatio = median(volumeRatios[i])
+
+		period := timeseries.FilterByDateRange(bts, result.Start, result.End.Add(result.Interval-time.Nanosecond))
+		q.ValidationIssues = len(dataloader.ValidateData(period))
+		vf := forensics.AnalyzeVolume(period, 0)
+		q.BenfordConformity = vf.BenfordConformity
+		q.RoundShare = vf.RoundShare
+
+		q.Score = qualityScore(*q, result.AlignedBars, len(period.Data))
+	}
+
+	result.Sources = qualities
+	best := 0
+	for i := range qualities {
+		if qualities[i].Score < qualities[best].Score {
+			best = i
+		}
+	}
+	result.Recommended = qualities[best].Source
+
+	return result
+}
+
+// LargestDivergences returns up to n aligned bars with the widest spread between sources
+func LargestDivergences(comparison types.SourceComparison, n int) []types.BarDivergence {
+	bars := append([]types.BarDivergence(nil), comparison.Bars...)
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Spread > bars[j].Spread })
+	if len(bars) > n {
+		bars = bars[:n]
+	}
+	return bars
+}
+
+// qualityScore combines the share of missing bars and validation issues, the
+// mean price divergence (weighted so 1% counts like 10% missing bars), round
+// number clustering and a Benford penalty. Lower is cleaner.
+func qualityScore(q types.SourceQuality, alignedBars, periodBars int) float64 {
+	score := 10*q.MeanDivergence + q.RoundShare + benfordPenalty[q.BenfordConformity]
+	if alignedBars > 0 {
+		score += float64(q.MissingBars) / float64(alignedBars)
+	}
+	if periodBars > 0 {
+		score += float64(q.ValidationIssues) / float64(periodBars)
+	}
+	return score
+}
+
+// median returns the median of values without modifying them
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,178 @@
+package comparison
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// MinPairBars is the fewest aligned bars a pairs analysis needs
+const MinPairBars = 30
+
+// DefaultPairConfig trades the spread beyond 2 standard deviations of its
+// rolling mean and closes within 0.5, over a window of the spread's
+// half-life
+var DefaultPairConfig = types.PairConfig{EntryZ: 2, ExitZ: 0.5}
+
+// fallbackPairWindow is the z-score window when the spread does not mean
+// revert, so it has no half-life
+const fallbackPairWindow = 20
+
+// minPairWindow is the shortest z-score window. A value's z-score within a
+// window of n values that include it is at most (n-1)/√n, under 2 for n < 6,
+// so shorter windows could never reach the usual entry thresholds.
+const minPairWindow = 10
+
+// alignPair returns the close of pair in effect at each bar of bts, 0 before
+// its first bar. A pair finer than bts is first resampled to the interval of
+// bts, so both closes end at the same time.
+func alignPair(bts, pair *types.BTCTimeSeries) []float64 {
+	interval := timeseries.DetectFrequency(bts).Interval
+	if pairInterval := timeseries.DetectFrequency(pair).Interval; interval > 0 && pairInterval < interval {
+		pair = timeseries.Resample(pair, interval)
+	}
+	closes := make([]float64, len(bts.Data))
+	j, last := 0, 0.0
+	for i, bar := range bts.Data {
+		for j < len(pair.Data) && !pair.Data[j].Timestamp.After(bar.Timestamp) {
+			if pair.Data[j].Close > 0 {
+				last = pair.Data[j].Close
+			}
+			j++
+		}
+		closes[i] = last
+	}
+	return closes
+}
+
+// AnalyzePair tests the log prices of bts and pair, named name, for
+// cointegration and computes the z-score of their spread over pc.Window
+// bars, or the spread's half-life when 0, kept between minPairWindow and half
+// the aligned bars, with the position of the z-score template at every bar. Returns nil when fewer than MinPairBars bars align
+// or the pair's price does not vary.
+func AnalyzePair(bts, pair *types.BTCTimeSeries, name string, pc types.PairConfig) *types.PairAnalysis {
+	closes := alignPair(bts, pair)
+	start := 0
+	for start < len(closes) && (closes[start] <= 0 || bts.Data[start].Close <= 0) {
+		start++
+	}
+	var logPrices, logPair, returns, pairReturns []float64
+	for i := start; i < len(bts.Data); i++ {
+		if bts.Data[i].Close <= 0 {
+			return nil
+		}
+		logPrices = append(logPrices, math.Log(bts.Data[i].Close))
+		logPair = append(logPair, math.Log(closes[i]))
+		if i > start {
+			returns = append(returns, bts.Data[i].Close/bts.Data[i-1].Close-1)
+			pairReturns = append(pairReturns, closes[i]/closes[i-1]-1)
+		}
+	}
+	if len(logPrices) < MinPairBars {
+		return nil
+	}
+
+	hedge, intercept, spread, test, ok := statistics.EngleGranger(logPrices, logPair)
+	if !ok {
+		return nil
+	}
+	barsPerDay := float64(timeseries.DetectFrequency(bts).PeriodsPerYear) / 365
+	pa := &types.PairAnalysis{
+		Pair:          name,
+		Observations:  len(spread),
+		HedgeRatio:    hedge,
+		Intercept:     intercept,
+		Correlation:   statistics.CalculateCorrelation(returns, pairReturns),
+		Cointegration: test,
+		HalfLife:      statistics.FitOU("Spread", spread, barsPerDay),
+		Config:        pc,
+	}
+	if pa.Config.Window <= 0 {
+		pa.Config.Window = fallbackPairWindow
+		if pa.HalfLife.MeanReverting {
+			pa.Config.Window = int(math.Round(pa.HalfLife.HalfLifeBars))
+		}
+	}
+	if pa.Config.Window < minPairWindow {
+		pa.Config.Window = minPairWindow
+	}
+	if pa.Config.Window > len(spread)/2 {
+		pa.Config.Window = len(spread) / 2
+	}
+
+	zscores := SpreadZScores(spread, pa.Config.Window)
+	positions := PairPositions(zscores, pa.Config.EntryZ, pa.Config.ExitZ)
+	for i := range spread {
+		pa.Points = append(pa.Points, types.SpreadPoint{Timestamp: bts.Data[start+i].Timestamp, Spread: spread[i], ZScore: zscores[i]})
+	}
+	pa.ZScore = zscores[len(zscores)-1]
+	pa.Position = positions[len(positions)-1]
+	return pa
+}
+
+// SpreadZScores returns the deviation of each spread value from the mean of
+// the window values ending at it, in their standard deviations; 0 before the
+// first full window and where the window does not vary
+func SpreadZScores(spread []float64, window int) []float64 {
+	zscores := make([]float64, len(spread))
+	if window < 2 {
+		return zscores
+	}
+	for i := window - 1; i < len(spread); i++ {
+		stats := statistics.Calculate(spread[i-window+1 : i+1])
+		if stats.StdDev > 0 {
+			zscores[i] = (spread[i] - stats.Mean) / stats.StdDev
+		}
+	}
+	return zscores
+}
+
+// PairPositions applies the z-score template: short the spread while the
+// z-score is above entry, long while it is below -entry, and flat once it
+// returns within exit of zero; in between the position is kept
+func PairPositions(zscores []float64, entry, exit float64) []float64 {
+	positions := make([]float64, len(zscores))
+	position := 0.0
+	for i, z := range zscores {
+		switch {
+		case z > entry:
+			position = -1
+		case z < -entry:
+			position = 1
+		case math.Abs(z) < exit:
+			position = 0
+		}
+		positions[i] = position
+	}
+	return positions
+}
+
+// PairStrategy returns the positions of the z-score template of pa at every
+// bar of bts and the per-period returns of holding them, for the backtester:
+// a position of 1 earns the return of bts less HedgeRatio times the return
+// of the pair. Bars before the pair's first bar are flat. The hedge ratio
+// is estimated over the whole period, so the result is in-sample.
+func PairStrategy(bts, pair *types.BTCTimeSeries, pa *types.PairAnalysis) (positions, returns []float64) {
+	closes := alignPair(bts, pair)
+	positions = make([]float64, len(bts.Data))
+	zscores := make([]float64, len(pa.Points))
+	for i, point := range pa.Points {
+		zscores[i] = point.ZScore
+	}
+	templated := PairPositions(zscores, pa.Config.EntryZ, pa.Config.ExitZ)
+	offset := len(bts.Data) - len(templated)
+	for i, position := range templated {
+		positions[offset+i] = position
+	}
+
+	returns = make([]float64, len(bts.Data)-1)
+	for i := range returns {
+		prev, prevPair := bts.Data[i].Close, closes[i]
+		if positions[i] == 0 || prev <= 0 || prevPair <= 0 {
+			continue
+		}
+		returns[i] = positions[i] * ((bts.Data[i+1].Close/prev - 1) - pa.HedgeRatio*(closes[i+1]/prevPair-1))
+	}
+	return positions, returns
+}
@@ -0,0 +1,84 @@
+package comparison
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"time"
+)
+
+// minPremiumStdDev is the smallest trailing premium deviation worth z-scoring
+const minPremiumStdDev = 1e-6
+
+// TrackPremium computes the premium (positive) or discount (negative) of the
+// analyzed closes over a reference index after resampling both to the coarser
+// interval. Each premium is z-scored against the previous window bars, and a
+// bar whose |z| first reaches threshold is recorded as an alert. Returns nil
+// when the series share no bars.
+func TrackPremium(bts, reference *types.BTCTimeSeries, name string, window int, threshold float64) *types.PremiumAnalysis {
+	if len(bts.Data) == 0 || len(reference.Data) == 0 {
+		return nil
+	}
+
+	interval := timeseries.DetectFrequency(bts).Interval
+	if refInterval := timeseries.DetectFrequency(reference).Interval; refInterval > interval {
+		interval = refInterval
+	}
+
+	references := make(map[time.Time]float64)
+	for _, bar := range timeseries.Resample(reference, interval).Data {
+		references[bar.Timestamp] = bar.Close
+	}
+
+	pa := &types.PremiumAnalysis{Reference: name, Window: window, Threshold: threshold}
+	var premiums []float64
+	for _, bar := range timeseries.Resample(bts, interval).Data {
+		ref, ok := references[bar.Timestamp]
+		if !ok || ref <= 0 {
+			continue
+		}
+		premium := bar.Close/ref - 1
+		point := types.PremiumPoint{Timestamp: bar.Timestamp, Price: bar.Close, Reference: ref, Premium: premium}
+
+		if window > 1 && len(premiums) >= window {
+			trailing := statistics.Calculate(premiums[len(premiums)-window:])
+			// A flat window (e.g. identical feeds) has no meaningful z-score
+			if trailing.StdDev > minPremiumStdDev {
+				point.ZScore = (premium - trailing.Mean) / trailing.StdDev
+			}
+		}
+
+		if n := len(pa.Points); math.Abs(point.ZScore) >= threshold && (n == 0 || math.Abs(pa.Points[n-1].ZScore) < threshold) {
+			pa.Alerts = append(pa.Alerts, point)
+		}
+
+		premiums = append(premiums, premium)
+		pa.Points = append(pa.Points, point)
+	}
+
+	if len(pa.Points) == 0 {
+		return nil
+	}
+
+	overall := statistics.Calculate(premiums)
+	pa.Mean, pa.StdDev = overall.Mean, overall.StdDev
+
+	return pa
+}
+
+// PremiumSignal turns the latest premium z-score into a trading signal: an
+// extreme premium suggests overheated local demand, an extreme discount
+// local selling pressure likely to mean-revert
+func PremiumSignal(pa *types.PremiumAnalysis) string {
+	latest := pa.Points[len(pa.Points)-1]
+	switch {
+	case latest.ZScore >= pa.Threshold:
+		return fmt.Sprintf("SELL - Extreme premium %s vs %s (z %+.1f)", numfmt.SignedPct(latest.Premium), pa.Reference, latest.ZScore)
+	case latest.ZScore <= -pa.Threshold:
+		return fmt.Sprintf("BUY - Extreme discount %s vs %s (z %+.1f)", numfmt.SignedPct(latest.Premium), pa.Reference, latest.ZScore)
+	}
+	return fmt.Sprintf("HOLD - Premium %s vs %s within normal range (z %+.1f)", numfmt.SignedPct(latest.Premium), pa.Reference, latest.ZScore)
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Tasks are the job tasks the scheduler knows
+var Tasks = []string{"fetch", "analyze", "report", "alerts", "publish"}
+
+// Job schedules a task on a cron expression
+type Job struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"` // five-field cron expression or @hourly, @daily, ...
+	Task     string `json:"task"`     // one of Tasks
+}
+
+// Execution places real orders on an exchange from a trading signal. It is
+// only enabled by this section of the config file, and orders are simulated
+// unless DryRun is explicitly false.
+type Execution struct {
+	Exchange       string  `json:"exchange"`         // "binance" or "coinbase"
+	Symbol         string  `json:"symbol"`           // exchange symbol, e.g. BTCUSDT on Binance or BTC-USD on Coinbase
+	BaseAsset      string  `json:"base_asset"`       // asset whose balance is the position (default BTC)
+	Strategy       string  `json:"strategy"`         // trading signal that drives the orders, e.g. "RSI" or "Custom: trend"
+	OrderType      string  `json:"order_type"`       // "market" (default) or "limit"
+	LimitOffsetBps float64 `json:"limit_offset_bps"` // limit price below (buy) or above (sell) the last price
+	OrderSize      float64 `json:"order_size"`       // base quantity of each order
+	MaxPosition    float64 `json:"max_position"`     // base quantity the position may never exceed
+	DryRun         *bool   `json:"dry_run"`          // simulate orders (default true)
+	KillSwitch     string  `json:"kill_switch"`      // file whose existence stops all orders (default execution.kill)
+	AuditLog       string  `json:"audit_log"`        // NDJSON log of every order (default execution_orders.ndjson)
+}
+
+// Notify sends the triggered watchlist alerts to a Telegram chat. The bot
+// token is read from the environment.
+type Notify struct {
+	TelegramChatID string `json:"telegram_chat_id"`
+	Digest         string `json:"digest"` // "hourly" or "daily" digest of normal-urgency alerts ("" sends every alert at once)
+}
+
+// DigestSchedules are the cron schedules of the digest settings
+var DigestSchedules = map[string]string{"hourly": "@hourly", "daily": "@daily"}
+
+// Live reports whether orders are sent to the exchange
+func (e *Execution) Live() bool {
+	return e.DryRun != nil && !*e.DryRun
+}
+
+// Config is the configuration file of the analyzer. Signals overrides the
+// trading-signal thresholds, SignalState the damping of the server's signals,
+// Report the sections of the reports and Precision the decimals money and
+// percentages are shown with; unset fields keep their defaults.
+type Config struct {
+	Jobs        []Job                    `json:"jobs"`
+	Execution   *Execution               `json:"execution,omitempty"`
+	Notify      *Notify                  `json:"notify,omitempty"`
+	Signals     *types.SignalConfig      `json:"signals,omitempty"`
+	SignalState *types.SignalStateConfig `json:"signal_state,omitempty"`
+	Report      *types.ReportConfig      `json:"report,omitempty"`
+	Precision   *types.PrecisionConfig   `json:"precision,omitempty"`
+}
+
+// Load reads and validates the JSON configuration file at path
+func Load(path string) (*Config, error) {
+	cfg, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// read decodes the JSON configuration file at path without validating it
+func read(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks that job names are set and unique and tasks are known.
+// Cron expressions are checked when the jobs are scheduled.
+func (cfg *Config) Validate() error {
+	names := make(map[string]bool)
+	for i, job := range cfg.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("job %d has no name", i+1)
+		}
+		if names[job.Name] {
+			return fmt.Errorf("duplicate job name %q", job.Name)
+		}
+		names[job.Name] = true
+		if !knownTask(job.Task) {
+			return fmt.Errorf("job %q has unknown task %q (use one of %v)", job.Name, job.Task, Tasks)
+		}
+	}
+	if cfg.Execution != nil {
+		if err := cfg.Execution.validate(); err != nil {
+			return fmt.Errorf("execution: %w", err)
+		}
+	}
+	if cfg.Notify != nil {
+		if cfg.Notify.TelegramChatID == "" {
+			return fmt.Errorf("notify: telegram_chat_id is required")
+		}
+		if _, ok := DigestSchedules[cfg.Notify.Digest]; !ok && cfg.Notify.Digest != "" {
+			return fmt.Errorf("notify: unknown digest %q: use 'hourly', 'daily' or leave it empty", cfg.Notify.Digest)
+		}
+	}
+	return nil
+}
+
+// validate checks the execution settings and fills in defaults
+func (e *Execution) validate() error {
+	if e.BaseAsset == "" {
+		e.BaseAsset = "BTC"
+	}
+	if e.OrderType == "" {
+		e.OrderType = "market"
+	}
+	if e.KillSwitch == "" {
+		e.KillSwitch = "execution.kill"
+	}
+	if e.AuditLog == "" {
+		e.AuditLog = "execution_orders.ndjson"
+	}
+
+	switch {
+	case e.Exchange != "binance" && e.Exchange != "coinbase":
+		return fmt.Errorf("unknown exchange %q: use 'binance' or 'coinbase'", e.Exchange)
+	case e.Symbol == "":
+		return fmt.Errorf("symbol is required")
+	case e.Strategy == "":
+		return fmt.Errorf("strategy is required")
+	case e.OrderType != "market" && e.OrderType != "limit":
+		return fmt.Errorf("unknown order type %q: use 'market' or 'limit'", e.OrderType)
+	case e.LimitOffsetBps < 0:
+		return fmt.Errorf("limit_offset_bps must not be negative")
+	case e.OrderSize <= 0:
+		return fmt.Errorf("order_size must be positive")
+	case e.MaxPosition <= 0:
+		return fmt.Errorf("max_position must be positive")
+	}
+	return nil
+}
+
+// knownTask reports whether task is one of Tasks
+func knownTask(task string) bool {
+	for _, t := range Tasks {
+		if t == task {
+			return true
+		}
+	}
+	return false
+}
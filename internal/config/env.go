@@ -0,0 +1,168 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the name of every environment variable of the analyzer
+const EnvPrefix = "BTC_ANALYZER_"
+
+// JobsEnv holds the jobs as a JSON array, in the format of the config file
+const JobsEnv = EnvPrefix + "JOBS"
+
+// LoadWithEnv reads the config file at path, when set, and applies the
+// environment variables mirroring it on top: JobsEnv replaces the jobs and
+// BTC_ANALYZER_<SECTION>_<FIELD> sets one field of a section, e.g.
+// BTC_ANALYZER_NOTIFY_DIGEST or BTC_ANALYZER_EXECUTION_ORDER_SIZE. It
+// returns nil when there is no file and no such variable.
+func LoadWithEnv(path string) (*Config, error) {
+	cfg := &Config{}
+	source := "from the environment"
+	if path != "" {
+		var err error
+		if cfg, err = read(path); err != nil {
+			return nil, err
+		}
+		source = path
+	}
+
+	set, err := applyEnv(cfg, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" && !set {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", source, err)
+	}
+	return cfg, nil
+}
+
+// applyEnv sets the fields of cfg named by environment variables, creating
+// the sections they belong to, and reports whether any was set
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) (bool, error) {
+	set := false
+	if value, ok := lookup(JobsEnv); ok {
+		var jobs []Job
+		if err := json.Unmarshal([]byte(value), &jobs); err != nil {
+			return false, fmt.Errorf("invalid %s: %w", JobsEnv, err)
+		}
+		cfg.Jobs = jobs
+		set = true
+	}
+
+	var err error
+	root := reflect.ValueOf(cfg).Elem()
+	forEachEnvField(func(section reflect.StructField, field reflect.StructField, name string) {
+		value, ok := lookup(name)
+		if !ok || err != nil {
+			return
+		}
+		sectionValue := root.FieldByIndex(section.Index)
+		if sectionValue.IsNil() {
+			sectionValue.Set(reflect.New(section.Type.Elem()))
+		}
+		if setErr := setField(sectionValue.Elem().FieldByIndex(field.Index), value); setErr != nil {
+			err = fmt.Errorf("invalid %s: %w", name, setErr)
+			return
+		}
+		set = true
+	})
+	return set, err
+}
+
+// forEachEnvField calls fn with the environment variable name of every field
+// of the optional sections of Config
+func forEachEnvField(fn func(section reflect.StructField, field reflect.StructField, name string)) {
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		section := configType.Field(i)
+		if section.Type.Kind() != reflect.Pointer || section.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		sectionType := section.Type.Elem()
+		for j := 0; j < sectionType.NumField(); j++ {
+			field := sectionType.Field(j)
+			fn(section, field, EnvPrefix+envName(jsonName(section))+"_"+envName(jsonName(field)))
+		}
+	}
+}
+
+// jsonName returns the JSON key of a struct field
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// envName turns a JSON key into the upper-case form of an environment variable
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// setField parses value into a string, number or boolean field, a pointer to
+// one, a list of strings given comma-separated or a list of structs given
+// as a JSON array
+func setField(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		target := reflect.New(field.Type().Elem())
+		if err := setField(target.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Struct {
+			target := reflect.New(field.Type())
+			if err := json.Unmarshal([]byte(value), target.Interface()); err != nil {
+				return err
+			}
+			field.Set(target.Elem())
+			return nil
+		}
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type %s", field.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
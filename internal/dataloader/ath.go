@@ -0,0 +1,58 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// coinGeckoCoin represents the /coins/{id} response, reduced to the
+// all-time high
+type coinGeckoCoin struct {
+	MarketData struct {
+		ATH     map[string]float64   `json:"ath"`
+		ATHDate map[string]time.Time `json:"ath_date"`
+	} `json:"market_data"`
+}
+
+// LoadATHFromCoinGecko fetches the all-time high of a coin in USD, e.g. for
+// "bitcoin", with the time it was set
+func LoadATHFromCoinGecko(id string) (*types.AuxPoint, error) {
+	url := fmt.Sprintf("https://api.coingecko.com/api/v3/coins/%s?localization=false&tickers=false&community_data=false&developer_data=false", id)
+	body, err := httpcache.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s all-time high from CoinGecko: %w", id, err)
+	}
+
+	var coin coinGeckoCoin
+	if err := json.Unmarshal(body, &coin); err != nil {
+		return nil, fmt.Errorf("failed to decode CoinGecko %s response: %w", id, err)
+	}
+	price, ok := coin.MarketData.ATH["usd"]
+	if !ok || price <= 0 {
+		return nil, fmt.Errorf("CoinGecko has no USD all-time high for %s", id)
+	}
+	return &types.AuxPoint{Timestamp: coin.MarketData.ATHDate["usd"], Value: price}, nil
+}
+
+// ParseATH parses an all-time high given as price@date, e.g.
+// "69000@2021-11-10". The date is optional; without it the high has a zero
+// timestamp.
+func ParseATH(s string) (*types.AuxPoint, error) {
+	priceText, dateText, hasDate := strings.Cut(s, "@")
+	price, err := strconv.ParseFloat(strings.TrimSpace(priceText), 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("invalid all-time high %q: use price[@YYYY-MM-DD]", s)
+	}
+	ath := &types.AuxPoint{Value: price}
+	if hasDate {
+		if ath.Timestamp, err = time.Parse("2006-01-02", strings.TrimSpace(dateText)); err != nil {
+			return nil, fmt.Errorf("invalid all-time high date %q: use YYYY-MM-DD", dateText)
+		}
+	}
+	return ath, nil
+}
@@ -0,0 +1,278 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const binanceBaseURL = "https://api.binance.com"
+
+// MaxKlinesPerRequest is the most candles Binance returns for one klines request
+const MaxKlinesPerRequest = 1000
+
+// binanceIntervals are the fixed-length Binance kline intervals
+var binanceIntervals = map[string]time.Duration{
+	"1m": time.Minute, "3m": 3 * time.Minute, "5m": 5 * time.Minute, "15m": 15 * time.Minute,
+	"30m": 30 * time.Minute, "1h": time.Hour, "2h": 2 * time.Hour, "4h": 4 * time.Hour,
+	"6h": 6 * time.Hour, "8h": 8 * time.Hour, "12h": 12 * time.Hour,
+	"1d": 24 * time.Hour, "3d": 3 * 24 * time.Hour, "1w": 7 * 24 * time.Hour,
+}
+
+// BinanceInterval returns the length of a Binance kline interval. Monthly
+// candles have no fixed length and are not supported.
+func BinanceInterval(interval string) (time.Duration, error) {
+	d, ok := binanceIntervals[interval]
+	if !ok {
+		return 0, fmt.Errorf("unsupported Binance kline interval %q", interval)
+	}
+	return d, nil
+}
+
+// FirstKlineFromBinance returns the open time of the first candle at or after
+// from, which is later than from when the symbol was listed afterwards. It
+// returns the zero time when there is none.
+func FirstKlineFromBinance(symbol, interval string, from time.Time) (time.Time, error) {
+	url := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&startTime=%d&limit=1",
+		binanceBaseURL, symbol, interval, from.UnixMilli())
+
+	body, err := httpcache.Get(url)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch klines from Binance: %w", err)
+	}
+	var page [][]interface{}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode Binance klines response: %w", err)
+	}
+	if len(page) == 0 {
+		return time.Time{}, nil
+	}
+	_, openMs, err := parseKline(page[0])
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(openMs), nil
+}
+
+// binanceDepthResponse represents the /api/v3/depth response
+type binanceDepthResponse struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// LoadOrderBookFromBinance fetches the current order book depth for a symbol (e.g. BTCUSDT)
+func LoadOrderBookFromBinance(symbol string, limit int) (*types.OrderBook, error) {
+	url := fmt.Sprintf("%s/api/v3/depth?symbol=%s&limit=%d", binanceBaseURL, symbol, limit)
+
+	// the book changes by the second, so it never comes from the cache
+	body, err := httpcache.GetLive(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order book from Binance: %w", err)
+	}
+
+	var depth binanceDepthResponse
+	if err := json.Unmarshal(body, &depth); err != nil {
+		return nil, fmt.Errorf("failed to decode Binance depth response: %w", err)
+	}
+
+	book := &types.OrderBook{
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+		Bids:      parseBinanceLevels(depth.Bids),
+		Asks:      parseBinanceLevels(depth.Asks),
+	}
+
+	sort.Slice(book.Bids, func(i, j int) bool { return book.Bids[i].Price > book.Bids[j].Price })
+	sort.Slice(book.Asks, func(i, j int) bool { return book.Asks[i].Price < book.Asks[j].Price })
+
+	return book, nil
+}
+
+// parseBinanceLevels converts Binance [price, quantity] string pairs to order book levels
+func parseBinanceLevels(raw [][2]string) []types.OrderBookLevel {
+	levels := make([]types.OrderBookLevel, 0, len(raw))
+	for _, pair := range raw {
+		price, err := strconv.ParseFloat(pair[0], 64)
+		if err != nil {
+			continue
+		}
+		quantity, err := strconv.ParseFloat(pair[1], 64)
+		if err != nil {
+			continue
+		}
+		levels = append(levels, types.OrderBookLevel{Price: price, Quantity: quantity})
+	}
+	return levels
+}
+
+// binanceAggTrade represents a single entry of the /api/v3/aggTrades response
+type binanceAggTrade struct {
+	ID           int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	Timestamp    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+}
+
+// LoadAggTradesFromBinance fetches aggregated trades from start up to, not
+// including, end. Binance limits each request to 1000 trades within one hour,
+// so the range is paged in windows that advance past the last trade
+// received. Both bounds of a request are inclusive, so each window ends 1ms
+// before the next starts, and trades repeated across pages are dropped by
+// their aggregate trade ID.
+func LoadAggTradesFromBinance(symbol string, start, end time.Time) ([]types.Trade, error) {
+	var trades []types.Trade
+
+	lastID := int64(-1)
+	cursor := start
+	for cursor.Before(end) {
+		windowEnd := cursor.Add(time.Hour)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		url := fmt.Sprintf("%s/api/v3/aggTrades?symbol=%s&startTime=%d&endTime=%d&limit=1000",
+			binanceBaseURL, symbol, cursor.UnixMilli(), windowEnd.UnixMilli()-1)
+
+		// A window still open gains trades, so it is not served from the cache
+		get := httpcache.Get
+		if windowEnd.After(time.Now()) {
+			get = httpcache.GetLive
+		}
+		body, err := get(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch aggTrades from Binance: %w", err)
+		}
+
+		var page []binanceAggTrade
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode Binance aggTrades response: %w", err)
+		}
+
+		for _, raw := range page {
+			if raw.ID <= lastID {
+				continue
+			}
+			lastID = raw.ID
+			trade, err := parseAggTrade(raw.Price, raw.Quantity, raw.Timestamp, raw.IsBuyerMaker)
+			if err != nil {
+				continue
+			}
+			trades = append(trades, trade)
+		}
+
+		// A full page may not cover the whole window; continue from its last
+		// trade, whose millisecond may hold more trades than the page did
+		if len(page) == 1000 {
+			next := time.UnixMilli(page[len(page)-1].Timestamp)
+			if !next.After(cursor) {
+				next = cursor.Add(time.Millisecond)
+			}
+			cursor = next
+		} else {
+			cursor = windowEnd
+		}
+	}
+
+	return trades, nil
+}
+
+// parseAggTrade converts raw aggTrade fields to a Trade
+func parseAggTrade(priceStr, quantityStr string, timestampMs int64, isBuyerMaker bool) (types.Trade, error) {
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		return types.Trade{}, fmt.Errorf("invalid trade price: %w", err)
+	}
+	quantity, err := strconv.ParseFloat(quantityStr, 64)
+	if err != nil {
+		return types.Trade{}, fmt.Errorf("invalid trade quantity: %w", err)
+	}
+	return types.Trade{
+		Timestamp:    time.UnixMilli(timestampMs),
+		Price:        price,
+		Quantity:     quantity,
+		IsBuyerMaker: isBuyerMaker,
+	}, nil
+}
+
+// LoadKlinesFromBinance fetches OHLCV candles of the given interval (e.g. 1h,
+// 1d) between start and end. Binance returns at most 1000 candles per
+// request, so the range is paged from the open time after the last candle.
+func LoadKlinesFromBinance(symbol, interval string, start, end time.Time) (*types.BTCTimeSeries, error) {
+	bts := timeseries.New(symbol)
+
+	cursor := start
+	for cursor.Before(end) {
+		url := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&startTime=%d&endTime=%d&limit=%d",
+			binanceBaseURL, symbol, interval, cursor.UnixMilli(), end.UnixMilli(), MaxKlinesPerRequest)
+
+		body, err := httpcache.Get(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch klines from Binance: %w", err)
+		}
+
+		var page [][]interface{}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode Binance klines response: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		lastOpen := int64(0)
+		for _, raw := range page {
+			bar, openMs, err := parseKline(raw)
+			if err != nil {
+				continue
+			}
+			timeseries.AddPrice(bts, bar)
+			lastOpen = openMs
+		}
+
+		if len(page) < MaxKlinesPerRequest || lastOpen == 0 {
+			break
+		}
+		cursor = time.UnixMilli(lastOpen + 1)
+	}
+
+	return bts, nil
+}
+
+// parseKline converts a raw kline array [openTime, open, high, low, close, volume, ...] to a bar
+func parseKline(raw []interface{}) (types.BTCPrice, int64, error) {
+	if len(raw) < 6 {
+		return types.BTCPrice{}, 0, fmt.Errorf("kline has %d fields, expected at least 6", len(raw))
+	}
+	openMs, ok := raw[0].(float64)
+	if !ok {
+		return types.BTCPrice{}, 0, fmt.Errorf("invalid kline open time")
+	}
+
+	var values [5]float64
+	for i := range values {
+		str, ok := raw[i+1].(string)
+		if !ok {
+			return types.BTCPrice{}, 0, fmt.Errorf("invalid kline field %d", i+1)
+		}
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return types.BTCPrice{}, 0, fmt.Errorf("invalid kline field %d: %w", i+1, err)
+		}
+		values[i] = value
+	}
+
+	return types.BTCPrice{
+		Timestamp: time.UnixMilli(int64(openMs)),
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+	}, int64(openMs), nil
+}
@@ -0,0 +1,131 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/types"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fredBaseURL = "https://api.stlouisfed.org/fred"
+
+// FREDKeyEnv names the environment variable holding the FRED API key
+const FREDKeyEnv = "BTC_ANALYZER_FRED_API_KEY"
+
+// DefaultCPISeries is the FRED series of the US consumer price index for
+// all urban consumers, seasonally adjusted
+const DefaultCPISeries = "CPIAUCSL"
+
+// fredObservationsResponse represents the /series/observations response.
+// Values are strings, "." for a missing observation.
+type fredObservationsResponse struct {
+	Observations []struct {
+		Date  string `json:"date"`
+		Value string `json:"value"`
+	} `json:"observations"`
+}
+
+// LoadCPICSV loads a price index from a CSV of date and value columns, such
+// as a FRED download ("observation_date,CPIAUCSL"). The header names the
+// series; rows whose date or value do not parse, like FRED's "." for a
+// missing month, are skipped.
+func LoadCPICSV(filename string) (*types.AuxSeries, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CPI file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CPI CSV: %w", err)
+	}
+
+	series := &types.AuxSeries{Name: "cpi"}
+	for i, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		timestamp, ok := parseCPIDate(record[0])
+		if !ok {
+			if name := strings.TrimSpace(record[1]); i == 0 && name != "" {
+				series.Name = name
+			}
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || value <= 0 {
+			continue
+		}
+		series.Points = append(series.Points, types.AuxPoint{Timestamp: timestamp, Value: value})
+	}
+
+	if len(series.Points) == 0 {
+		return nil, fmt.Errorf("no CPI data found in %s", filename)
+	}
+	sortAuxPoints(series)
+
+	return series, nil
+}
+
+// LoadCPIFromFRED fetches the observations of a FRED series, e.g. CPIAUCSL,
+// from start on. FRED requires a free API key.
+func LoadCPIFromFRED(seriesID, apiKey string, start time.Time) (*types.AuxSeries, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("FRED API key missing: set %s", FREDKeyEnv)
+	}
+	query := url.Values{}
+	query.Set("series_id", seriesID)
+	query.Set("api_key", apiKey)
+	query.Set("file_type", "json")
+	query.Set("observation_start", start.Format("2006-01-02"))
+
+	body, err := httpcache.Get(fredBaseURL + "/series/observations?" + query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from FRED: %w", seriesID, err)
+	}
+
+	var resp fredObservationsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode FRED response: %w", err)
+	}
+
+	series := &types.AuxSeries{Name: seriesID}
+	for _, obs := range resp.Observations {
+		timestamp, ok := parseCPIDate(obs.Date)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(obs.Value, 64)
+		if err != nil || value <= 0 {
+			continue
+		}
+		series.Points = append(series.Points, types.AuxPoint{Timestamp: timestamp, Value: value})
+	}
+
+	if len(series.Points) == 0 {
+		return nil, fmt.Errorf("no %s observations returned by FRED", seriesID)
+	}
+	sortAuxPoints(series)
+
+	return series, nil
+}
+
+// parseCPIDate parses the daily and monthly date formats of index exports
+func parseCPIDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,161 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const deribitBaseURL = "https://www.deribit.com/api/v2/public"
+
+// deribitVolatilityResponse represents the get_volatility_index_data response
+type deribitVolatilityResponse struct {
+	Result struct {
+		Data         [][]float64 `json:"data"` // [timestamp, open, high, low, close]
+		Continuation *int64      `json:"continuation"`
+	} `json:"result"`
+}
+
+// deribitBookSummary represents one instrument of the get_book_summary_by_currency response
+type deribitBookSummary struct {
+	InstrumentName  string  `json:"instrument_name"`
+	MarkIV          float64 `json:"mark_iv"`
+	UnderlyingPrice float64 `json:"underlying_price"`
+}
+
+// LoadDVOLFromDeribit fetches the Deribit implied volatility index (DVOL) for
+// a currency between start and end at the given resolution ("60", "3600" or
+// "43200" seconds, or "1D"). Values are annualized percentages. Deribit pages
+// the history backwards from end through a continuation timestamp.
+func LoadDVOLFromDeribit(currency string, start, end time.Time, resolution string) (*types.AuxSeries, error) {
+	series := &types.AuxSeries{Name: currency + "_dvol"}
+
+	cursor := end.UnixMilli()
+	for cursor > start.UnixMilli() {
+		url := fmt.Sprintf("%s/get_volatility_index_data?currency=%s&start_timestamp=%d&end_timestamp=%d&resolution=%s",
+			deribitBaseURL, currency, start.UnixMilli(), cursor, resolution)
+
+		body, err := httpcache.Get(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch DVOL from Deribit: %w", err)
+		}
+
+		var resp deribitVolatilityResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode Deribit DVOL response: %w", err)
+		}
+
+		for _, candle := range resp.Result.Data {
+			if len(candle) < 5 {
+				continue
+			}
+			series.Points = append(series.Points, types.AuxPoint{
+				Timestamp: time.UnixMilli(int64(candle[0])),
+				Value:     candle[4],
+			})
+		}
+
+		if resp.Result.Continuation == nil || *resp.Result.Continuation >= cursor || len(resp.Result.Data) == 0 {
+			break
+		}
+		cursor = *resp.Result.Continuation
+	}
+
+	if len(series.Points) == 0 {
+		return nil, fmt.Errorf("no DVOL data returned for %s", currency)
+	}
+	sortAuxPoints(series)
+
+	return series, nil
+}
+
+// LoadOptionTermStructureFromDeribit builds the at-the-money implied
+// volatility term structure from the current option book summaries. For each
+// expiry the strike closest to the underlying is used, averaging its call and
+// put mark IVs.
+func LoadOptionTermStructureFromDeribit(currency string) ([]types.TermPoint, error) {
+	url := fmt.Sprintf("%s/get_book_summary_by_currency?currency=%s&kind=option", deribitBaseURL, currency)
+
+	// marks are a live snapshot, so they never come from the cache
+	body, err := httpcache.GetLive(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch option summaries from Deribit: %w", err)
+	}
+
+	var resp struct {
+		Result []deribitBookSummary `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode Deribit option summaries: %w", err)
+	}
+
+	type atmCandidate struct {
+		distance float64
+		ivSum    float64
+		ivCount  int
+	}
+	candidates := make(map[time.Time]map[float64]*atmCandidate)
+	now := time.Now()
+
+	for _, option := range resp.Result {
+		expiry, strike, ok := parseDeribitOption(option.InstrumentName)
+		if !ok || option.MarkIV <= 0 || option.UnderlyingPrice <= 0 || !expiry.After(now) {
+			continue
+		}
+		if candidates[expiry] == nil {
+			candidates[expiry] = make(map[float64]*atmCandidate)
+		}
+		candidate := candidates[expiry][strike]
+		if candidate == nil {
+			candidate = &atmCandidate{distance: math.Abs(strike-option.UnderlyingPrice) / option.UnderlyingPrice}
+			candidates[expiry][strike] = candidate
+		}
+		candidate.ivSum += option.MarkIV
+		candidate.ivCount++
+	}
+
+	var term []types.TermPoint
+	for expiry, strikes := range candidates {
+		var best *atmCandidate
+		for _, candidate := range strikes {
+			if best == nil || candidate.distance < best.distance {
+				best = candidate
+			}
+		}
+		term = append(term, types.TermPoint{
+			Expiry: expiry,
+			Days:   expiry.Sub(now).Hours() / 24,
+			ATMIV:  best.ivSum / float64(best.ivCount) / 100,
+		})
+	}
+	if len(term) == 0 {
+		return nil, fmt.Errorf("no %s option summaries found", currency)
+	}
+
+	sort.Slice(term, func(i, j int) bool { return term[i].Expiry.Before(term[j].Expiry) })
+	return term, nil
+}
+
+// parseDeribitOption parses an option instrument name such as
+// BTC-27DEC24-60000-C into its expiry (08:00 UTC) and strike
+func parseDeribitOption(name string) (time.Time, float64, bool) {
+	parts := strings.Split(name, "-")
+	if len(parts) != 4 {
+		return time.Time{}, 0, false
+	}
+	expiry, err := time.Parse("2Jan06", parts[1])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	strike, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return expiry.Add(8 * time.Hour), strike, true
+}
@@ -0,0 +1,188 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Export formats of the processed series
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// SaveSeries exports bts to filename in format, one of the Format constants,
+// with the prices rounded by the precision policy of its denomination
+func SaveSeries(bts *types.BTCTimeSeries, filename, format string) error {
+	switch format {
+	case FormatCSV:
+		return SaveToCSV(bts, filename)
+	case FormatJSON:
+		return saveFile(filename, "JSON", func(w io.Writer) error { return writeJSON(w, bts, true) })
+	case FormatNDJSON:
+		return SaveToNDJSON(bts, filename)
+	}
+	return fmt.Errorf("invalid export format %q: use 'csv', 'json' or 'ndjson'", format)
+}
+
+// SaveToNDJSON exports Bitcoin time series data as newline-delimited JSON,
+// one bar per line
+func SaveToNDJSON(bts *types.BTCTimeSeries, filename string) error {
+	return saveFile(filename, "NDJSON", func(w io.Writer) error { return WriteNDJSON(w, bts) })
+}
+
+// saveFile creates filename and writes it through a buffer with write
+func saveFile(filename, kind string, write func(w io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create %s file: %w", kind, err)
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriterSize(file, 64*1024)
+	if err := write(buffered); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", kind, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", kind, err)
+	}
+	return nil
+}
+
+// roundPrices returns p with its prices rounded to the precision of kind;
+// volumes are left as they are
+func roundPrices(kind string, p types.BTCPrice) types.BTCPrice {
+	p.Open = numfmt.Round(kind, p.Open)
+	p.High = numfmt.Round(kind, p.High)
+	p.Low = numfmt.Round(kind, p.Low)
+	p.Close = numfmt.Round(kind, p.Close)
+	return p
+}
+
+// WriteCSV writes the series as CSV, with the date, the prices rounded by the
+// precision policy of its denomination and whole volumes. Fields are
+// formatted into one reused line buffer, so exporting millions of bars
+// allocates almost nothing per bar.
+func WriteCSV(w io.Writer, bts *types.BTCTimeSeries) error {
+	if _, err := io.WriteString(w, "Date,Open,High,Low,Close,Volume\n"); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+
+	kind := denom.Kind(bts.Denomination)
+	var line []byte
+	for _, p := range bts.Data {
+		line = p.Timestamp.AppendFormat(line[:0], "2006-01-02")
+		for _, price := range []float64{p.Open, p.High, p.Low, p.Close} {
+			line = append(line, ',')
+			line = numfmt.AppendFormat(line, kind, price)
+		}
+		line = append(line, ',')
+		line = strconv.AppendFloat(line, p.Volume, 'f', 0, 64)
+		line = append(line, '\n')
+		if _, err := w.Write(line); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the series in the indented layout LoadFromJSON reads,
+// encoding one bar at a time instead of the whole series at once. Prices
+// keep full precision, as bundles and failover snapshots replay them.
+func WriteJSON(w io.Writer, bts *types.BTCTimeSeries) error {
+	return writeJSON(w, bts, false)
+}
+
+// writeJSON is WriteJSON, with the prices rounded by the precision policy of
+// the denomination when round
+func writeJSON(w io.Writer, bts *types.BTCTimeSeries, round bool) error {
+	symbol, err := json.Marshal(bts.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "{\n  \"Symbol\": %s,\n", symbol); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	if bts.Denomination != nil {
+		denomination, err := json.Marshal(bts.Denomination)
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "  \"Denomination\": %s,\n", denomination); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
+		}
+	}
+	if _, err := io.WriteString(w, "  \"Data\": "); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	switch {
+	case bts.Data == nil:
+		_, err = io.WriteString(w, "null")
+	case len(bts.Data) == 0:
+		_, err = io.WriteString(w, "[]")
+	default:
+		// Each bar is encoded and indented into buffers reused across bars
+		var raw, indented bytes.Buffer
+		encoder := json.NewEncoder(&raw)
+		kind := denom.Kind(bts.Denomination)
+		_, err = io.WriteString(w, "[\n")
+		for i, p := range bts.Data {
+			if err != nil {
+				break
+			}
+			if round {
+				p = roundPrices(kind, p)
+			}
+			raw.Reset()
+			if err := encoder.Encode(p); err != nil {
+				return fmt.Errorf("failed to encode JSON: %w", err)
+			}
+			indented.Reset()
+			indented.WriteString("    ")
+			if err := json.Indent(&indented, bytes.TrimSuffix(raw.Bytes(), []byte("\n")), "    ", "  "); err != nil {
+				return fmt.Errorf("failed to encode JSON: %w", err)
+			}
+			if i < len(bts.Data)-1 {
+				indented.WriteByte(',')
+			}
+			indented.WriteByte('\n')
+			_, err = w.Write(indented.Bytes())
+		}
+		if err == nil {
+			_, err = io.WriteString(w, "  ]")
+		}
+	}
+	if err == nil {
+		_, err = io.WriteString(w, "\n}\n")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteNDJSON writes one JSON object per bar and line, with the prices
+// rounded by the precision policy of the denomination
+func WriteNDJSON(w io.Writer, bts *types.BTCTimeSeries) error {
+	encoder := json.NewEncoder(w)
+	kind := denom.Kind(bts.Denomination)
+	for _, p := range bts.Data {
+		if err := encoder.Encode(roundPrices(kind, p)); err != nil {
+			return fmt.Errorf("failed to write NDJSON record: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/types"
+	"io"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// benchmarkBars is the length of the series the export writers are
+// benchmarked on
+const benchmarkBars = 1_000_000
+
+var (
+	benchmarkOnce   sync.Once
+	benchmarkSeries *types.BTCTimeSeries
+)
+
+// millionBars returns a series of benchmarkBars hourly bars with prices
+// that need rounding, built once for all benchmarks
+func millionBars() *types.BTCTimeSeries {
+	benchmarkOnce.Do(func() {
+		start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+		data := make([]types.BTCPrice, benchmarkBars)
+		for i := range data {
+			price := 30000 + 10000*math.Sin(float64(i)/1000) + float64(i%97)/7
+			data[i] = types.BTCPrice{
+				Timestamp: start.Add(time.Duration(i) * time.Hour),
+				Open:      price,
+				High:      price * 1.01,
+				Low:       price * 0.99,
+				Close:     price * 1.001,
+				Volume:    1000 + float64(i%1000),
+			}
+		}
+		benchmarkSeries = &types.BTCTimeSeries{Symbol: "BTC-USD", Data: data}
+	})
+	return benchmarkSeries
+}
+
+func benchmarkWriter(b *testing.B, write func(w io.Writer, bts *types.BTCTimeSeries) error) {
+	bts := millionBars()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := write(io.Discard, bts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteCSV(b *testing.B) {
+	benchmarkWriter(b, WriteCSV)
+}
+
+// BenchmarkWriteJSON measures the full-precision JSON of bundles
+func BenchmarkWriteJSON(b *testing.B) {
+	benchmarkWriter(b, WriteJSON)
+}
+
+// BenchmarkExportJSON measures the rounded JSON of -export-format=json
+func BenchmarkExportJSON(b *testing.B) {
+	benchmarkWriter(b, func(w io.Writer, bts *types.BTCTimeSeries) error { return writeJSON(w, bts, true) })
+}
+
+func BenchmarkWriteNDJSON(b *testing.B) {
+	benchmarkWriter(b, WriteNDJSON)
+}
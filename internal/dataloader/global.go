@@ -0,0 +1,122 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultStablecoinIDs are the CoinGecko ids summed into the stablecoin market cap
+var DefaultStablecoinIDs = []string{"tether", "usd-coin", "dai"}
+
+// coinGeckoGlobalChart represents the /global/market_cap_chart response
+type coinGeckoGlobalChart struct {
+	MarketCapChart struct {
+		MarketCap [][]float64 `json:"market_cap"`
+	} `json:"market_cap_chart"`
+}
+
+// LoadStablecoinMarketCap sums the market cap history of the given stablecoins
+// over the last days. Points are matched by hour and kept only where every
+// coin has a value, so a missing coin cannot masquerade as a supply drop.
+func LoadStablecoinMarketCap(days int, ids []string) (*types.AuxSeries, error) {
+	totals := make(map[time.Time]float64)
+	counts := make(map[time.Time]int)
+
+	for _, id := range ids {
+		caps, err := loadCoinGeckoMarketCaps(id, days)
+		if err != nil {
+			return nil, err
+		}
+		for hour, value := range caps {
+			totals[hour] += value
+			counts[hour]++
+		}
+	}
+
+	series := &types.AuxSeries{Name: "stablecoin_market_cap"}
+	for hour, total := range totals {
+		if counts[hour] == len(ids) {
+			series.Points = append(series.Points, types.AuxPoint{Timestamp: hour, Value: total})
+		}
+	}
+	if len(series.Points) == 0 {
+		return nil, fmt.Errorf("no overlapping stablecoin market cap data")
+	}
+	sortAuxPoints(series)
+
+	return series, nil
+}
+
+// LoadBTCDominance computes Bitcoin's share (in percent) of the total crypto
+// market cap over the last days. The global market cap chart endpoint
+// requires a CoinGecko API plan; without one the request fails.
+func LoadBTCDominance(days int) (*types.AuxSeries, error) {
+	btcCaps, err := loadCoinGeckoMarketCaps("bitcoin", days)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://api.coingecko.com/api/v3/global/market_cap_chart?vs_currency=usd&days=%d", days)
+	body, err := httpcache.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch global market cap from CoinGecko: %w", err)
+	}
+
+	var chart coinGeckoGlobalChart
+	if err := json.Unmarshal(body, &chart); err != nil {
+		return nil, fmt.Errorf("failed to decode CoinGecko global market cap response: %w", err)
+	}
+
+	series := &types.AuxSeries{Name: "btc_dominance"}
+	for _, point := range chart.MarketCapChart.MarketCap {
+		if len(point) < 2 || point[1] <= 0 {
+			continue
+		}
+		hour := time.UnixMilli(int64(point[0])).Truncate(time.Hour)
+		if btcCap, ok := btcCaps[hour]; ok {
+			series.Points = append(series.Points, types.AuxPoint{Timestamp: hour, Value: btcCap / point[1] * 100})
+		}
+	}
+	if len(series.Points) == 0 {
+		return nil, fmt.Errorf("no overlapping Bitcoin and global market cap data")
+	}
+	sortAuxPoints(series)
+
+	return series, nil
+}
+
+// loadCoinGeckoMarketCaps returns the market cap history of a coin keyed by hour
+func loadCoinGeckoMarketCaps(id string, days int) (map[time.Time]float64, error) {
+	url := fmt.Sprintf("https://api.coingecko.com/api/v3/coins/%s/market_chart?vs_currency=usd&days=%d", id, days)
+
+	body, err := httpcache.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s market cap from CoinGecko: %w", id, err)
+	}
+
+	var resp types.CoinGeckoResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode CoinGecko %s response: %w", id, err)
+	}
+
+	caps := make(map[time.Time]float64, len(resp.MarketCaps))
+	for _, point := range resp.MarketCaps {
+		if len(point) < 2 || point[1] <= 0 {
+			continue
+		}
+		caps[time.UnixMilli(int64(point[0])).Truncate(time.Hour)] = point[1]
+	}
+
+	return caps, nil
+}
+
+// sortAuxPoints orders the points of an auxiliary series by timestamp
+func sortAuxPoints(series *types.AuxSeries) {
+	sort.Slice(series.Points, func(i, j int) bool {
+		return series.Points[i].Timestamp.Before(series.Points[j].Timestamp)
+	})
+}
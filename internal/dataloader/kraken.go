@@ -0,0 +1,100 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const krakenBaseURL = "https://api.kraken.com/0/public"
+
+// KrakenPair is the Kraken pair of Bitcoin against the US dollar
+const KrakenPair = "XBTUSD"
+
+// MaxKrakenCandles is the most candles Kraken returns: only the latest 720
+// of an interval are available, whatever the start time
+const MaxKrakenCandles = 720
+
+// krakenOHLCResponse represents the /OHLC response. Result maps the
+// canonical pair name to its candles and "last" to the next since value.
+type krakenOHLCResponse struct {
+	Error  []string                   `json:"error"`
+	Result map[string]json.RawMessage `json:"result"`
+}
+
+// LoadOHLCFromKraken fetches the candles of pair (e.g. XBTUSD) with an
+// interval in minutes since the given time
+func LoadOHLCFromKraken(pair string, intervalMinutes int, since time.Time) (*types.BTCTimeSeries, error) {
+	url := fmt.Sprintf("%s/OHLC?pair=%s&interval=%d&since=%d", krakenBaseURL, pair, intervalMinutes, since.Unix())
+
+	body, err := httpcache.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OHLC from Kraken: %w", err)
+	}
+
+	var resp krakenOHLCResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode Kraken OHLC response: %w", err)
+	}
+	if len(resp.Error) > 0 {
+		return nil, fmt.Errorf("Kraken error: %s", strings.Join(resp.Error, ", "))
+	}
+
+	bts := timeseries.New("BTC-USD")
+	for name, raw := range resp.Result {
+		if name == "last" {
+			continue
+		}
+		var rows [][]interface{}
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("failed to decode Kraken candles: %w", err)
+		}
+		for _, row := range rows {
+			bar, err := parseKrakenCandle(row)
+			if err != nil {
+				continue
+			}
+			timeseries.AddPrice(bts, bar)
+		}
+	}
+	return bts, nil
+}
+
+// parseKrakenCandle converts a raw candle [time, open, high, low, close, vwap, volume, count]
+func parseKrakenCandle(row []interface{}) (types.BTCPrice, error) {
+	if len(row) < 7 {
+		return types.BTCPrice{}, fmt.Errorf("candle has %d fields, expected at least 7", len(row))
+	}
+	seconds, ok := row[0].(float64)
+	if !ok {
+		return types.BTCPrice{}, fmt.Errorf("invalid candle time")
+	}
+
+	// open, high, low, close, then volume after the vwap
+	var values [5]float64
+	for i, field := range []int{1, 2, 3, 4, 6} {
+		str, ok := row[field].(string)
+		if !ok {
+			return types.BTCPrice{}, fmt.Errorf("invalid candle field %d", field)
+		}
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return types.BTCPrice{}, fmt.Errorf("invalid candle field %d: %w", field, err)
+		}
+		values[i] = value
+	}
+
+	return types.BTCPrice{
+		Timestamp: time.Unix(int64(seconds), 0),
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+	}, nil
+}
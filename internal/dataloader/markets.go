@@ -0,0 +1,42 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+)
+
+// coinGeckoMarketsPage is the most coins one /coins/markets request returns
+const coinGeckoMarketsPage = 250
+
+// LoadTopCoinsFromCoinGecko fetches the n coins with the largest market cap,
+// largest first
+func LoadTopCoinsFromCoinGecko(n int) ([]types.CoinMarket, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of coins %d: must be at least 1", n)
+	}
+
+	var coins []types.CoinMarket
+	perPage := min(n, coinGeckoMarketsPage)
+	for page := 1; len(coins) < n; page++ {
+		url := fmt.Sprintf("https://api.coingecko.com/api/v3/coins/markets?vs_currency=usd&order=market_cap_desc&per_page=%d&page=%d", perPage, page)
+		body, err := httpcache.Get(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch coin markets from CoinGecko: %w", err)
+		}
+
+		var batch []types.CoinMarket
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return nil, fmt.Errorf("failed to decode CoinGecko coin markets: %w", err)
+		}
+		coins = append(coins, batch...)
+		if len(batch) < perPage {
+			break
+		}
+	}
+	if len(coins) > n {
+		coins = coins[:n]
+	}
+	return coins, nil
+}
@@ -0,0 +1,92 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadOnChainCSV loads daily on-chain metrics from a CSV with a date column
+// ("date" or "time"), a USD transaction volume column (a header containing
+// "volume", or Coin Metrics' TxTfrValAdjUSD) and a supply column (a header
+// containing "supply", or Coin Metrics' SplyCur). Rows missing either value
+// are skipped.
+func LoadOnChainCSV(filename string) ([]types.OnChainPoint, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open on-chain file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read on-chain CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty on-chain file: %s", filename)
+	}
+
+	dateCol, volumeCol, supplyCol := -1, -1, -1
+	for i, header := range records[0] {
+		header = strings.ToLower(strings.TrimSpace(header))
+		switch {
+		case dateCol < 0 && (header == "date" || header == "time" || header == "timestamp"):
+			dateCol = i
+		case volumeCol < 0 && (strings.Contains(header, "volume") || strings.HasPrefix(header, "txtfrval")):
+			volumeCol = i
+		case supplyCol < 0 && (strings.Contains(header, "supply") || strings.HasPrefix(header, "splycur")):
+			supplyCol = i
+		}
+	}
+	if dateCol < 0 || volumeCol < 0 || supplyCol < 0 {
+		return nil, fmt.Errorf("on-chain CSV needs date, transaction volume and supply columns")
+	}
+
+	var points []types.OnChainPoint
+	for _, record := range records[1:] {
+		if len(record) <= dateCol || len(record) <= volumeCol || len(record) <= supplyCol {
+			continue
+		}
+		timestamp, ok := parseOnChainDate(record[dateCol])
+		if !ok {
+			continue
+		}
+		volume, err := strconv.ParseFloat(strings.TrimSpace(record[volumeCol]), 64)
+		if err != nil {
+			continue
+		}
+		supply, err := strconv.ParseFloat(strings.TrimSpace(record[supplyCol]), 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, types.OnChainPoint{Timestamp: timestamp, TxVolume: volume, Supply: supply})
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no on-chain data found in %s", filename)
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+	return points, nil
+}
+
+// parseOnChainDate parses a plain date or an RFC 3339 timestamp, as exported
+// by Coin Metrics
+func parseOnChainDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,90 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// countingReader counts the bytes read through it for progress reporting
+type countingReader struct {
+	r     io.Reader
+	count int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.count += int64(n)
+	return n, err
+}
+
+// StreamCSV reads a price CSV record by record and hands it to handle in
+// chunks of chunkSize, so files larger than memory can be processed.
+// progress, if not nil, is called after every chunk with bytes read and file size.
+func StreamCSV(filename string, chunkSize int, handle func([]types.BTCPrice) error, progress func(read, total int64)) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat CSV file: %w", err)
+	}
+
+	counter := &countingReader{r: file}
+	reader := csv.NewReader(counter)
+	reader.ReuseRecord = true
+
+	headers, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	format := detectCSVFormat(headers)
+
+	chunk := make([]types.BTCPrice, 0, chunkSize)
+	line := 1
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if err := handle(chunk); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+		if progress != nil {
+			progress(counter.count, info.Size())
+		}
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		line++
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV at line %d: %w", line, err)
+		}
+
+		btcPrice, err := parseCSVRecord(record, format)
+		if err != nil {
+			fmt.Printf("Warning: skipping invalid record at line %d: %v\n", line, err)
+			continue
+		}
+
+		chunk = append(chunk, btcPrice)
+		if len(chunk) == chunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
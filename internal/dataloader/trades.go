@@ -0,0 +1,68 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadTradesFromCSV loads trades from a Binance aggTrades dump
+// (agg_trade_id, price, quantity, first_trade_id, last_trade_id, transact_time,
+// is_buyer_maker[, is_best_match]). The header row is optional.
+func LoadTradesFromCSV(filename string) ([]types.Trade, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trades file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trades CSV: %w", err)
+	}
+
+	var trades []types.Trade
+	for i, record := range records {
+		if len(record) < 7 {
+			continue
+		}
+
+		timestampMs, err := strconv.ParseInt(strings.TrimSpace(record[5]), 10, 64)
+		if err != nil {
+			if i == 0 {
+				continue // header
+			}
+			fmt.Printf("Warning: skipping invalid trade at line %d: %v\n", i+1, err)
+			continue
+		}
+
+		// Newer dumps use microsecond timestamps
+		if timestampMs > 1e14 {
+			timestampMs /= 1000
+		}
+
+		isBuyerMaker := strings.EqualFold(strings.TrimSpace(record[6]), "true")
+		trade, err := parseAggTrade(strings.TrimSpace(record[1]), strings.TrimSpace(record[2]), timestampMs, isBuyerMaker)
+		if err != nil {
+			fmt.Printf("Warning: skipping invalid trade at line %d: %v\n", i+1, err)
+			continue
+		}
+		trades = append(trades, trade)
+	}
+
+	if len(trades) == 0 {
+		return nil, fmt.Errorf("no trades found in %s", filename)
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Timestamp.Before(trades[j].Timestamp)
+	})
+
+	return trades, nil
+}
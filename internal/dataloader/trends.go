@@ -0,0 +1,78 @@
+package dataloader
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadGoogleTrendsCSV loads a Google Trends "Interest over time" CSV export.
+// The export starts with a category line and a blank line before the
+// "Day/Week/Month,<term>: (<region>)" header, so rows are accepted only once
+// their first column parses as a date.
+func LoadGoogleTrendsCSV(filename string) (*types.AuxSeries, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Google Trends file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google Trends CSV: %w", err)
+	}
+
+	series := &types.AuxSeries{Name: "search_interest"}
+
+	for _, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+
+		timestamp, ok := parseTrendsDate(record[0])
+		if !ok {
+			// Header line: keep the search term as the series name
+			if term := strings.TrimSpace(record[1]); term != "" {
+				series.Name = strings.TrimSpace(strings.Split(term, ":")[0])
+			}
+			continue
+		}
+
+		// Google reports values below 1 as "<1"
+		valueStr := strings.TrimPrefix(strings.TrimSpace(record[1]), "<")
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			continue
+		}
+
+		series.Points = append(series.Points, types.AuxPoint{Timestamp: timestamp, Value: value})
+	}
+
+	if len(series.Points) == 0 {
+		return nil, fmt.Errorf("no search interest data found in %s", filename)
+	}
+
+	sort.Slice(series.Points, func(i, j int) bool {
+		return series.Points[i].Timestamp.Before(series.Points[j].Timestamp)
+	})
+
+	return series, nil
+}
+
+// parseTrendsDate parses the daily, weekly and monthly date formats used by Google Trends
+func parseTrendsDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15", "2006-01"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
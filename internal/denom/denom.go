@@ -0,0 +1,146 @@
+// Package denom converts BTC price series between denominations and
+// formats prices in the denomination of their series: fiat per amount of
+// BTC, or sats or BTC per amount of fiat
+package denom
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Denomination kinds
+const (
+	Fiat = "fiat" // fiat price of Unit BTC, e.g. per sat with Unit 1e-8
+	Sats = "sats" // sats bought with Unit of fiat
+	BTC  = "btc"  // BTC bought with Unit of fiat
+)
+
+// SatsPerBTC is the number of satoshis in one bitcoin
+const SatsPerBTC = 1e8
+
+// Parse returns the denomination of kind and unit, or nil for fiat per
+// BTC, the denomination series are loaded in
+func Parse(kind string, unit float64) (*types.Denomination, error) {
+	if unit <= 0 || math.IsInf(unit, 0) || math.IsNaN(unit) {
+		return nil, fmt.Errorf("denomination unit must be positive")
+	}
+	switch kind {
+	case Fiat:
+		if unit == 1 {
+			return nil, nil
+		}
+	case Sats, BTC:
+	default:
+		return nil, fmt.Errorf("invalid denomination %q: use 'fiat', 'sats' or 'btc'", kind)
+	}
+	return &types.Denomination{Kind: kind, Unit: unit}, nil
+}
+
+// Inverse reports whether prices in d fall when the fiat price of BTC rises
+func Inverse(d *types.Denomination) bool {
+	return d != nil && d.Kind != Fiat
+}
+
+// FiatScale returns the factor from fiat per BTC to a fiat denomination:
+// Unit for fiat, 1 for fiat per BTC and the inverse denominations
+func FiatScale(d *types.Denomination) float64 {
+	if d == nil || d.Kind != Fiat {
+		return 1
+	}
+	return d.Unit
+}
+
+// Convert returns the value of a fiat price of one BTC in d
+func Convert(d *types.Denomination, price float64) float64 {
+	switch {
+	case d == nil:
+		return price
+	case d.Kind == Fiat:
+		return price * d.Unit
+	case price == 0:
+		return 0
+	case d.Kind == Sats:
+		return SatsPerBTC * d.Unit / price
+	}
+	return d.Unit / price
+}
+
+// Apply returns a copy of a fiat-per-BTC series in d. An inverse
+// denomination swaps the highs and lows; volumes are kept.
+func Apply(bts *types.BTCTimeSeries, d *types.Denomination) *types.BTCTimeSeries {
+	converted := &types.BTCTimeSeries{Symbol: bts.Symbol, Denomination: d, Data: make([]types.BTCPrice, len(bts.Data))}
+	for i, bar := range bts.Data {
+		high, low := Convert(d, bar.High), Convert(d, bar.Low)
+		if Inverse(d) {
+			high, low = low, high
+		}
+		converted.Data[i] = types.BTCPrice{
+			Timestamp: bar.Timestamp,
+			Open:      Convert(d, bar.Open),
+			High:      high,
+			Low:       low,
+			Close:     Convert(d, bar.Close),
+			Volume:    bar.Volume,
+		}
+	}
+	return converted
+}
+
+// Label names the denomination, e.g. "USD per BTC" or "sats per $1"
+func Label(d *types.Denomination) string {
+	switch {
+	case d == nil:
+		return "USD per BTC"
+	case d.Kind == Fiat && d.Unit < 1 && isWhole(d.Unit*SatsPerBTC):
+		if math.Round(d.Unit*SatsPerBTC) == 1 {
+			return "USD per sat"
+		}
+		return "USD per " + number(math.Round(d.Unit*SatsPerBTC)) + " sats"
+	case d.Kind == Fiat:
+		return "USD per " + number(d.Unit) + " BTC"
+	case d.Kind == Sats:
+		return "sats per $" + number(d.Unit)
+	}
+	return "BTC per $" + number(d.Unit)
+}
+
+// Price formats a price of a series in denomination d
+func Price(d *types.Denomination, v float64) string {
+	switch kind := Kind(d); kind {
+	case numfmt.Sats:
+		return numfmt.Format(kind, v) + " sats"
+	case numfmt.BTC:
+		return numfmt.Format(kind, v) + " BTC"
+	}
+	return "$" + numfmt.Format(numfmt.USD, v)
+}
+
+// Amount formats a price difference of a series in denomination d without
+// its unit, e.g. a MACD value, with the precision of its prices
+func Amount(d *types.Denomination, v float64) string {
+	return numfmt.Format(Kind(d), v)
+}
+
+// Kind returns the numfmt kind of the prices of denomination d
+func Kind(d *types.Denomination) string {
+	switch {
+	case d == nil || d.Kind == Fiat:
+		return numfmt.USD
+	case d.Kind == Sats:
+		return numfmt.Sats
+	}
+	return numfmt.BTC
+}
+
+// isWhole reports whether v is an integer, allowing for float rounding
+func isWhole(v float64) bool {
+	return math.Abs(v-math.Round(v)) < 1e-6
+}
+
+// number formats a unit without trailing zeros
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
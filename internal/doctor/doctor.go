@@ -0,0 +1,190 @@
+// Package doctor runs self-diagnostics and collects their outcome in a
+// report, to check a machine before setting up scheduled runs on it.
+package doctor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Status is the outcome of one check
+type Status int
+
+const (
+	OK Status = iota
+	Warn
+	Fail
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Group  string
+	Name   string
+	Status Status
+	Detail string
+}
+
+// Report collects the results of every check in order
+type Report struct {
+	Results []Result
+}
+
+// Add records the outcome of a check
+func (r *Report) Add(group, name string, status Status, format string, args ...interface{}) {
+	r.Results = append(r.Results, Result{Group: group, Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Check records err as a failure, or detail as success when err is nil
+func (r *Report) Check(group, name string, err error, detail string) {
+	if err != nil {
+		r.Add(group, name, Fail, "%v", err)
+		return
+	}
+	r.Add(group, name, OK, "%s", detail)
+}
+
+// Count returns how many checks ended with status
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the results grouped under their headings and a summary line
+func (r *Report) Print(w io.Writer) {
+	group := ""
+	for _, result := range r.Results {
+		if result.Group != group {
+			group = result.Group
+			fmt.Fprintf(w, "\n%s\n", group)
+		}
+		fmt.Fprintf(w, "  %s %s: %s\n", icon(result.Status), result.Name, result.Detail)
+	}
+	fmt.Fprintf(w, "\n%d checks: %d ok, %d warnings, %d failed\n",
+		len(r.Results), r.Count(OK), r.Count(Warn), r.Count(Fail))
+}
+
+// icon returns the console marker of a status
+func icon(status Status) string {
+	switch status {
+	case Warn:
+		return "⚠️ "
+	case Fail:
+		return "❌"
+	}
+	return "✅"
+}
+
+// Reachable requests url and returns the round-trip time. Any response
+// below 500 counts: the API answered, even if it rejected the request.
+func Reachable(client *http.Client, url string) (time.Duration, error) {
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 500 {
+		return 0, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+	return time.Since(start), nil
+}
+
+// ClockSkew compares the local clock with the server time of a Binance
+// /api/v3/time endpoint, taken at the midpoint of the request. A positive
+// skew means the local clock is ahead.
+func ClockSkew(client *http.Client, url string) (time.Duration, error) {
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	end := time.Now()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.ServerTime == 0 {
+		return 0, fmt.Errorf("unexpected server time response")
+	}
+	local := start.Add(end.Sub(start) / 2)
+	return local.Sub(time.UnixMilli(body.ServerTime)), nil
+}
+
+// SkewStatus rates a clock skew: signed exchange requests are rejected
+// beyond a few seconds
+func SkewStatus(skew time.Duration) Status {
+	switch abs := time.Duration(math.Abs(float64(skew))); {
+	case abs > 5*time.Second:
+		return Fail
+	case abs > time.Second:
+		return Warn
+	}
+	return OK
+}
+
+// Writable checks that files can be created in dir. A missing directory is
+// writable when its nearest existing parent is, since runs create it.
+func Writable(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	target := dir
+	for {
+		info, err := os.Stat(target)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s is not a directory", target)
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(target)
+		if parent == target {
+			return "", err
+		}
+		target = parent
+	}
+
+	file, err := os.CreateTemp(target, ".btc-analyzer-doctor-*")
+	if err != nil {
+		return "", fmt.Errorf("cannot write to %s: %w", target, err)
+	}
+	file.Close()
+	os.Remove(file.Name())
+	if target != dir {
+		return fmt.Sprintf("will be created in %s", target), nil
+	}
+	return "writable", nil
+}
+
+// Size formats a byte count
+func Size(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", bytes)
+}
@@ -0,0 +1,152 @@
+package execution
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables holding the Binance API credentials, kept out of
+// flags and the config file
+const (
+	BinanceKeyEnv    = "BTC_ANALYZER_BINANCE_API_KEY"
+	BinanceSecretEnv = "BTC_ANALYZER_BINANCE_API_SECRET"
+)
+
+// binanceAPI is the Binance spot REST endpoint
+const binanceAPI = "https://api.binance.com"
+
+// Binance places spot orders through the signed Binance REST API
+type Binance struct {
+	key, secret string
+	baseURL     string
+	client      *http.Client
+}
+
+// NewBinance returns a Binance client with the given API key and secret
+func NewBinance(key, secret string) (*Binance, error) {
+	if key == "" || secret == "" {
+		return nil, fmt.Errorf("Binance credentials missing: set %s and %s", BinanceKeyEnv, BinanceSecretEnv)
+	}
+	return &Binance{key: key, secret: secret, baseURL: binanceAPI, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+// Name returns "binance"
+func (b *Binance) Name() string {
+	return "binance"
+}
+
+// PlaceOrder sends a market or GTC limit order
+func (b *Binance) PlaceOrder(ctx context.Context, order Order) (Placed, error) {
+	params := url.Values{}
+	params.Set("symbol", order.Symbol)
+	params.Set("side", strings.ToUpper(order.Side))
+	params.Set("type", strings.ToUpper(order.Type))
+	params.Set("quantity", formatDecimal(order.Quantity))
+	params.Set("newClientOrderId", order.ClientID)
+	if order.Type == "limit" {
+		params.Set("timeInForce", "GTC")
+		params.Set("price", formatDecimal(order.Price))
+	}
+
+	var resp struct {
+		OrderID int64  `json:"orderId"`
+		Status  string `json:"status"`
+	}
+	if err := b.signed(ctx, http.MethodPost, "/api/v3/order", params, &resp); err != nil {
+		// A 4xx answer refused the order, except -1007, a timeout after
+		// which the order may still have been executed
+		var apiErr *binanceError
+		if errors.As(err, &apiErr) && apiErr.status < 500 && apiErr.Code != -1007 {
+			return Placed{Status: "rejected"}, err
+		}
+		return Placed{}, err
+	}
+	return Placed{OrderID: strconv.FormatInt(resp.OrderID, 10), Status: resp.Status}, nil
+}
+
+// Balance returns the free and locked balance of asset
+func (b *Binance) Balance(ctx context.Context, asset string) (float64, error) {
+	var resp struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := b.signed(ctx, http.MethodGet, "/api/v3/account", url.Values{}, &resp); err != nil {
+		return 0, err
+	}
+	for _, balance := range resp.Balances {
+		if balance.Asset == asset {
+			free, _ := strconv.ParseFloat(balance.Free, 64)
+			locked, _ := strconv.ParseFloat(balance.Locked, 64)
+			return free + locked, nil
+		}
+	}
+	return 0, nil
+}
+
+// signed sends a request signed with HMAC-SHA256 of its query string and
+// decodes the JSON response into out
+func (b *Binance) signed(ctx context.Context, method, path string, params url.Values, out interface{}) error {
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	params.Set("recvWindow", "5000")
+	query := params.Encode()
+	mac := hmac.New(sha256.New, []byte(b.secret))
+	mac.Write([]byte(query))
+	query += "&signature=" + hex.EncodeToString(mac.Sum(nil))
+
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path+"?"+query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-MBX-APIKEY", b.key)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Binance request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Binance response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &binanceError{status: resp.StatusCode}
+		if json.Unmarshal(body, apiErr) == nil && apiErr.Msg != "" {
+			return apiErr
+		}
+		return fmt.Errorf("Binance returned %s", resp.Status)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode Binance response: %w", err)
+	}
+	return nil
+}
+
+// binanceError is an error answer of the Binance API
+type binanceError struct {
+	status int    // HTTP status
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+}
+
+func (e *binanceError) Error() string {
+	return fmt.Sprintf("Binance error %d: %s", e.Code, e.Msg)
+}
+
+// formatDecimal formats a quantity or price without exponent or trailing zeros
+func formatDecimal(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
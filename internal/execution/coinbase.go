@@ -0,0 +1,203 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables holding the Coinbase CDP API key name and its EC
+// private key in PEM form
+const (
+	CoinbaseKeyEnv    = "BTC_ANALYZER_COINBASE_API_KEY"
+	CoinbaseSecretEnv = "BTC_ANALYZER_COINBASE_API_SECRET"
+)
+
+// coinbaseHost is the Coinbase Advanced Trade API host
+const coinbaseHost = "api.coinbase.com"
+
+// Coinbase places orders through the Coinbase Advanced Trade API,
+// authenticated with an ES256 JWT per request
+type Coinbase struct {
+	keyName string
+	key     *ecdsa.PrivateKey
+	client  *http.Client
+}
+
+// NewCoinbase returns a Coinbase client for the API key keyName with its
+// PEM-encoded EC private key
+func NewCoinbase(keyName, privateKey string) (*Coinbase, error) {
+	if keyName == "" || privateKey == "" {
+		return nil, fmt.Errorf("Coinbase credentials missing: set %s and %s", CoinbaseKeyEnv, CoinbaseSecretEnv)
+	}
+	// Keys are often stored with escaped newlines
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(privateKey, `\n`, "\n")))
+	if block == nil {
+		return nil, fmt.Errorf("Coinbase private key is not PEM encoded")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Coinbase private key: %w", err)
+	}
+	return &Coinbase{keyName: keyName, key: key, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+// Name returns "coinbase"
+func (c *Coinbase) Name() string {
+	return "coinbase"
+}
+
+// PlaceOrder sends a market IOC or GTC limit order
+func (c *Coinbase) PlaceOrder(ctx context.Context, order Order) (Placed, error) {
+	configuration := map[string]interface{}{
+		"market_market_ioc": map[string]string{"base_size": formatDecimal(order.Quantity)},
+	}
+	if order.Type == "limit" {
+		configuration = map[string]interface{}{
+			"limit_limit_gtc": map[string]interface{}{
+				"base_size":   formatDecimal(order.Quantity),
+				"limit_price": formatDecimal(order.Price),
+				"post_only":   false,
+			},
+		}
+	}
+	request := map[string]interface{}{
+		"client_order_id":     order.ClientID,
+		"product_id":          order.Symbol,
+		"side":                strings.ToUpper(order.Side),
+		"order_configuration": configuration,
+	}
+
+	var resp struct {
+		Success         bool `json:"success"`
+		SuccessResponse struct {
+			OrderID string `json:"order_id"`
+		} `json:"success_response"`
+		ErrorResponse struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		} `json:"error_response"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v3/brokerage/orders", request, &resp); err != nil {
+		return Placed{}, err
+	}
+	if !resp.Success {
+		return Placed{Status: "rejected"}, fmt.Errorf("Coinbase rejected the order: %s %s", resp.ErrorResponse.Error, resp.ErrorResponse.Message)
+	}
+	return Placed{OrderID: resp.SuccessResponse.OrderID, Status: "submitted"}, nil
+}
+
+// Balance returns the available and held balance of asset
+func (c *Coinbase) Balance(ctx context.Context, asset string) (float64, error) {
+	var resp struct {
+		Accounts []struct {
+			Currency         string `json:"currency"`
+			AvailableBalance struct {
+				Value string `json:"value"`
+			} `json:"available_balance"`
+			Hold struct {
+				Value string `json:"value"`
+			} `json:"hold"`
+		} `json:"accounts"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v3/brokerage/accounts?limit=250", nil, &resp); err != nil {
+		return 0, err
+	}
+	for _, account := range resp.Accounts {
+		if account.Currency == asset {
+			available, _ := strconv.ParseFloat(account.AvailableBalance.Value, 64)
+			hold, _ := strconv.ParseFloat(account.Hold.Value, 64)
+			return available + hold, nil
+		}
+	}
+	return 0, nil
+}
+
+// do sends an authenticated request with an optional JSON body and decodes
+// the JSON response into out
+func (c *Coinbase) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "https://"+coinbaseHost+path, reader)
+	if err != nil {
+		return err
+	}
+	// The JWT covers the path without the query
+	token, err := c.token(method, strings.SplitN(path, "?", 2)[0])
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Coinbase request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Coinbase response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Coinbase returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode Coinbase response: %w", err)
+	}
+	return nil
+}
+
+// token returns a JWT for one request, valid for two minutes
+func (c *Coinbase) token(method, path string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+	header, err := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT", "kid": c.keyName, "nonce": hex.EncodeToString(nonce)})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"sub": c.keyName,
+		"iss": "cdp",
+		"nbf": now,
+		"exp": now + 120,
+		"uri": method + " " + coinbaseHost + path,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign Coinbase token: %w", err)
+	}
+	// ES256 signatures are r and s as two 32-byte big-endian integers
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
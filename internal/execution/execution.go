@@ -0,0 +1,376 @@
+// Package execution places exchange orders from trading signals. It is
+// opt-in through the execution section of the config file: orders are
+// simulated unless dry_run is false, buys never take the position past
+// max_position, sells never sell more than the executor bought, and a kill
+// switch file stops all orders.
+package execution
+
+import (
+	"btc-analyzer/internal/config"
+	"btc-analyzer/internal/types"
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Order is an order to send to an exchange. Price is set for limit orders.
+type Order struct {
+	Symbol   string
+	Side     string // "buy" or "sell"
+	Type     string // "market" or "limit"
+	Quantity float64
+	Price    float64
+	ClientID string
+}
+
+// Placed is the exchange's answer to an order
+type Placed struct {
+	OrderID string
+	Status  string
+}
+
+// Exchange is an exchange that takes orders
+type Exchange interface {
+	Name() string
+	// PlaceOrder sends an order
+	PlaceOrder(ctx context.Context, order Order) (Placed, error)
+	// Balance returns the total balance of asset
+	Balance(ctx context.Context, asset string) (float64, error)
+}
+
+// maxRecent is the number of orders kept for the status API
+const maxRecent = 100
+
+// Status is the state of an executor
+type Status struct {
+	Exchange    string                 `json:"exchange"`
+	Symbol      string                 `json:"symbol"`
+	Strategy    string                 `json:"strategy"`
+	DryRun      bool                   `json:"dry_run"`
+	Killed      bool                   `json:"killed"`
+	KillSwitch  string                 `json:"kill_switch"`
+	Position    float64                `json:"position"`          // bought by the executor's orders and not sold
+	Balance     *float64               `json:"balance,omitempty"` // balance of the base asset on the exchange, in live mode
+	MaxPosition float64                `json:"max_position"`
+	LastSignal  string                 `json:"last_signal"`
+	Orders      []types.ExecutionOrder `json:"orders"`
+}
+
+// Executor turns changes of a trading signal into orders. A signal that
+// turns to BUY buys order_size, capped so the position stays within
+// max_position; one that turns to SELL sells order_size, at most the
+// position, so it never goes short. The position is what the executor's own
+// orders bought and did not sell, never the rest of the account. A signal
+// that stays the same places no further orders once its order went through;
+// one blocked by the kill switch or failed is tried again on the next result.
+type Executor struct {
+	exchange Exchange
+	cfg      config.Execution
+
+	mu         sync.Mutex
+	lastAction string
+	lastSignal string
+	symbol     string  // symbol of the analyzed series the executor follows
+	position   float64 // bought by the executor's orders and not sold
+	killed     bool    // kill switch engaged without its file, until restart
+	pending    *Order  // live order that failed without a rejection, retried as is
+	recent     []types.ExecutionOrder
+}
+
+// New returns an executor for cfg. exchange may be nil in dry-run mode. The
+// position is restored from the orders of the same mode, exchange and symbol
+// in the audit log.
+func New(cfg config.Execution, exchange Exchange) (*Executor, error) {
+	if cfg.Live() && exchange == nil {
+		return nil, fmt.Errorf("live execution needs an exchange")
+	}
+	e := &Executor{exchange: exchange, cfg: cfg}
+	if err := e.restore(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// restore sums the position of the orders in the audit log that were placed
+// in the current mode on the configured exchange and symbol, without error
+func (e *Executor) restore() error {
+	f, err := os.Open(e.cfg.AuditLog)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open execution audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var record types.ExecutionOrder
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("execution audit log line %d: %w", line, err)
+		}
+		if record.Error != "" || record.DryRun == e.cfg.Live() || record.Exchange != e.cfg.Exchange || record.Symbol != e.cfg.Symbol {
+			continue
+		}
+		if record.Side == "buy" {
+			e.position += record.Quantity
+		} else {
+			e.position -= record.Quantity
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read execution audit log: %w", err)
+	}
+	e.position = math.Max(roundQuantity(e.position), 0)
+	return nil
+}
+
+// Live reports whether the executor sends orders to the exchange
+func (e *Executor) Live() bool {
+	return e.cfg.Live()
+}
+
+// NewExchange returns the exchange named by cfg with credentials from the
+// environment
+func NewExchange(cfg config.Execution) (Exchange, error) {
+	switch cfg.Exchange {
+	case "binance":
+		return NewBinance(os.Getenv(BinanceKeyEnv), os.Getenv(BinanceSecretEnv))
+	case "coinbase":
+		return NewCoinbase(os.Getenv(CoinbaseKeyEnv), os.Getenv(CoinbaseSecretEnv))
+	}
+	return nil, fmt.Errorf("unknown exchange %q", cfg.Exchange)
+}
+
+// Kill engages the kill switch by creating its file, so no order is placed
+// until the file is removed, across restarts too. When the file cannot be
+// created, orders stop until the process restarts.
+func (e *Executor) Kill() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	f, err := os.OpenFile(e.cfg.KillSwitch, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		e.killed = true
+		return fmt.Errorf("kill switch engaged until restart, failed to create %s: %w", e.cfg.KillSwitch, err)
+	}
+	return f.Close()
+}
+
+// killSwitchOn reports whether orders are stopped. The caller holds the lock.
+func (e *Executor) killSwitchOn() bool {
+	if e.killed {
+		return true
+	}
+	_, err := os.Stat(e.cfg.KillSwitch)
+	return err == nil
+}
+
+// Status returns the state of the executor. The balance is read from the
+// exchange in live mode.
+func (e *Executor) Status(ctx context.Context) (Status, error) {
+	e.mu.Lock()
+	status := Status{
+		Exchange:    e.cfg.Exchange,
+		Symbol:      e.cfg.Symbol,
+		Strategy:    e.cfg.Strategy,
+		DryRun:      !e.cfg.Live(),
+		Killed:      e.killSwitchOn(),
+		KillSwitch:  e.cfg.KillSwitch,
+		Position:    e.position,
+		MaxPosition: e.cfg.MaxPosition,
+		LastSignal:  e.lastSignal,
+		Orders:      append([]types.ExecutionOrder{}, e.recent...),
+	}
+	e.mu.Unlock()
+
+	if e.cfg.Live() {
+		balance, err := e.exchange.Balance(ctx, e.cfg.BaseAsset)
+		if err != nil {
+			return status, err
+		}
+		status.Balance = &balance
+	}
+	return status, nil
+}
+
+// OnResult places the order the strategy's signal in result calls for. It
+// returns the order, nil when none was due. Live orders are refused on
+// sample data and on a series other than the first one seen. A signal is
+// acted on until its order is placed, so an order blocked by the kill switch
+// or failed is retried on the next result with the same signal; one that
+// failed without being rejected is retried unchanged, with its client ID,
+// until it is placed or rejected or the signal changes.
+func (e *Executor) OnResult(ctx context.Context, result *types.AnalysisResult) (*types.ExecutionOrder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bts := result.Series
+	if bts == nil || len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no price to trade at")
+	}
+	if e.symbol == "" {
+		e.symbol = bts.Symbol
+	}
+	if bts.Symbol != e.symbol {
+		return nil, fmt.Errorf("execution follows %s, not %s", e.symbol, bts.Symbol)
+	}
+	if e.cfg.Live() && result.Metadata.Parameters != nil && result.Metadata.Parameters.Source == "sample" {
+		return nil, fmt.Errorf("refusing live orders on sample data")
+	}
+
+	signal := result.Signals[e.cfg.Strategy]
+	e.lastSignal = signal
+	action := ""
+	switch {
+	case strings.HasPrefix(signal, "BUY"):
+		action = "buy"
+	case strings.HasPrefix(signal, "SELL"):
+		action = "sell"
+	}
+	if e.pending != nil && e.pending.Side != action {
+		e.pending = nil
+	}
+	if action == "" {
+		e.lastAction = ""
+		return nil, nil
+	}
+	if action == e.lastAction {
+		return nil, nil
+	}
+	if e.killSwitchOn() {
+		return nil, fmt.Errorf("kill switch %s is on, %s signal held until it is removed", e.cfg.KillSwitch, action)
+	}
+
+	// An order that failed without a rejection may still have been placed, so
+	// it is retried with its client ID, which the exchange refuses to fill twice
+	var order Order
+	if e.pending != nil {
+		order = *e.pending
+	} else {
+		quantity, err := e.quantity(ctx, action)
+		if err != nil {
+			return nil, err
+		}
+		if quantity <= 0 {
+			e.lastAction = action
+			return nil, nil
+		}
+
+		price := bts.Data[len(bts.Data)-1].Close
+		order = Order{Symbol: e.cfg.Symbol, Side: action, Type: e.cfg.OrderType, Quantity: quantity, ClientID: newClientID()}
+		if order.Type == "limit" {
+			offset := e.cfg.LimitOffsetBps / 10000
+			if action == "buy" {
+				order.Price = math.Round(price*(1-offset)*100) / 100
+			} else {
+				order.Price = math.Round(price*(1+offset)*100) / 100
+			}
+		}
+	}
+	quantity := order.Quantity
+
+	record := types.ExecutionOrder{
+		Time:     time.Now().UTC(),
+		Exchange: e.cfg.Exchange,
+		Symbol:   order.Symbol,
+		Side:     order.Side,
+		Type:     order.Type,
+		Quantity: order.Quantity,
+		Price:    order.Price,
+		ClientID: order.ClientID,
+		Signal:   signal,
+		DryRun:   !e.cfg.Live(),
+	}
+	var placeErr error
+	if e.cfg.Live() {
+		placed, err := e.exchange.PlaceOrder(ctx, order)
+		record.OrderID, record.Status = placed.OrderID, placed.Status
+		e.pending = nil
+		if err != nil {
+			record.Error = err.Error()
+			placeErr = fmt.Errorf("failed to place %s order: %w", action, err)
+			if placed.Status != "rejected" {
+				e.pending = &order
+			}
+		}
+	} else {
+		record.Status = "simulated"
+	}
+	if placeErr == nil {
+		e.lastAction = action
+		if action == "buy" {
+			e.position = roundQuantity(e.position + quantity)
+		} else {
+			e.position = roundQuantity(e.position - quantity)
+		}
+	}
+
+	e.recent = append(e.recent, record)
+	if len(e.recent) > maxRecent {
+		e.recent = e.recent[len(e.recent)-maxRecent:]
+	}
+	if err := e.audit(record); err != nil {
+		placeErr = errors.Join(placeErr, err)
+	}
+	return &record, placeErr
+}
+
+// quantity returns the quantity of the next order of action: order_size,
+// capped by max_position for a buy and by the position for a sell, and in
+// live mode also by the exchange balance, in case part of the position was
+// sold outside the executor. The caller holds the lock.
+func (e *Executor) quantity(ctx context.Context, action string) (float64, error) {
+	quantity := e.cfg.OrderSize
+	if action == "buy" {
+		return roundQuantity(math.Min(quantity, e.cfg.MaxPosition-e.position)), nil
+	}
+	quantity = math.Min(quantity, e.position)
+	if e.cfg.Live() && quantity > 0 {
+		balance, err := e.exchange.Balance(ctx, e.cfg.BaseAsset)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read balance: %w", err)
+		}
+		quantity = math.Min(quantity, balance)
+	}
+	return roundQuantity(quantity), nil
+}
+
+// roundQuantity rounds a quantity down to the 8 decimals exchanges take; the
+// epsilon absorbs float residue so 0.2 does not become 0.19999999
+func roundQuantity(quantity float64) float64 {
+	return math.Floor(quantity*1e8+1e-6) / 1e8
+}
+
+// audit appends an order to the audit log. The caller holds the lock.
+func (e *Executor) audit(record types.ExecutionOrder) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode order: %w", err)
+	}
+	f, err := os.OpenFile(e.cfg.AuditLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open execution audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write execution audit log: %w", err)
+	}
+	return nil
+}
+
+// newClientID returns a random client order ID, which exchanges use to
+// reject duplicates
+func newClientID() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return "btca-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,212 @@
+package execution
+
+import (
+	"btc-analyzer/internal/config"
+	"btc-analyzer/internal/types"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeExchange records the orders it is sent, rejects the next failures of
+// them and times out on the next timeouts after those
+type fakeExchange struct {
+	balance  float64
+	failures int
+	timeouts int
+	orders   []Order
+	clientID []string // of every order sent, placed or not
+}
+
+func (f *fakeExchange) Name() string { return "fake" }
+
+func (f *fakeExchange) PlaceOrder(ctx context.Context, order Order) (Placed, error) {
+	f.clientID = append(f.clientID, order.ClientID)
+	if f.failures > 0 {
+		f.failures--
+		return Placed{Status: "rejected"}, errors.New("insufficient funds")
+	}
+	if f.timeouts > 0 {
+		f.timeouts--
+		return Placed{}, context.DeadlineExceeded
+	}
+	f.orders = append(f.orders, order)
+	if order.Side == "buy" {
+		f.balance += order.Quantity
+	} else {
+		f.balance -= order.Quantity
+	}
+	return Placed{OrderID: "1", Status: "FILLED"}, nil
+}
+
+func (f *fakeExchange) Balance(ctx context.Context, asset string) (float64, error) {
+	return f.balance, nil
+}
+
+func testConfig(t *testing.T, live bool) config.Execution {
+	dir := t.TempDir()
+	dryRun := !live
+	return config.Execution{
+		Exchange:    "binance",
+		Symbol:      "BTCUSDT",
+		BaseAsset:   "BTC",
+		Strategy:    "RSI",
+		OrderType:   "market",
+		OrderSize:   0.03,
+		MaxPosition: 0.05,
+		DryRun:      &dryRun,
+		KillSwitch:  filepath.Join(dir, "execution.kill"),
+		AuditLog:    filepath.Join(dir, "orders.ndjson"),
+	}
+}
+
+func resultWith(signal string) *types.AnalysisResult {
+	return &types.AnalysisResult{
+		Series:  &types.BTCTimeSeries{Symbol: "BTC-USD", Data: []types.BTCPrice{{Close: 50000}}},
+		Signals: map[string]string{"RSI": signal},
+	}
+}
+
+// run passes the signals to e in turn and returns the quantities ordered,
+// 0 for no order
+func run(t *testing.T, e *Executor, signals ...string) []float64 {
+	t.Helper()
+	var quantities []float64
+	for _, signal := range signals {
+		order, err := e.OnResult(context.Background(), resultWith(signal))
+		if err != nil {
+			t.Fatalf("%s: %v", signal, err)
+		}
+		quantity := 0.0
+		if order != nil {
+			quantity = order.Quantity
+			if order.Side == "sell" {
+				quantity = -quantity
+			}
+		}
+		quantities = append(quantities, quantity)
+	}
+	return quantities
+}
+
+func TestOrderSizing(t *testing.T) {
+	e, err := New(testConfig(t, false), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := run(t, e, "BUY", "BUY", "HOLD", "BUY", "HOLD", "BUY", "SELL", "SELL", "HOLD", "SELL", "HOLD", "SELL")
+	// buys stop at max_position and sells at the position
+	want := []float64{0.03, 0, 0, 0.02, 0, 0, -0.03, 0, 0, -0.02, 0, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("orders = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSellsOnlyOwnPosition(t *testing.T) {
+	exchange := &fakeExchange{balance: 10}
+	e, err := New(testConfig(t, true), exchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := run(t, e, "SELL"); got[0] != 0 {
+		t.Fatalf("sold %g of an account the executor never bought into", -got[0])
+	}
+	if got := run(t, e, "BUY", "SELL"); got[0] != 0.03 || got[1] != -0.03 {
+		t.Fatalf("orders = %v, want [0.03 -0.03]", got)
+	}
+
+	// a sell is capped by the balance when part of the position was sold elsewhere
+	run(t, e, "BUY")
+	exchange.balance = 0.01
+	if got := run(t, e, "SELL"); got[0] != -0.01 {
+		t.Fatalf("sold %g with a balance of 0.01", -got[0])
+	}
+}
+
+func TestKillSwitchHoldsSignal(t *testing.T) {
+	cfg := testConfig(t, true)
+	exchange := &fakeExchange{}
+	e, err := New(cfg, exchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	if order, err := e.OnResult(context.Background(), resultWith("BUY")); order != nil || err == nil {
+		t.Fatalf("OnResult with the kill switch on = %v, %v; want no order and an error", order, err)
+	}
+	if len(exchange.orders) != 0 {
+		t.Fatalf("placed %d orders with the kill switch on", len(exchange.orders))
+	}
+
+	// the held signal is acted on once the switch is removed
+	if err := os.Remove(cfg.KillSwitch); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(t, e, "BUY"); got[0] != 0.03 {
+		t.Fatalf("bought %g after the kill switch was removed, want 0.03", got[0])
+	}
+}
+
+func TestFailedOrderIsRetried(t *testing.T) {
+	cfg := testConfig(t, true)
+	exchange := &fakeExchange{failures: 1}
+	e, err := New(cfg, exchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := e.OnResult(context.Background(), resultWith("BUY"))
+	if err == nil || order == nil || order.Error == "" {
+		t.Fatalf("OnResult with a failing exchange = %v, %v; want the failed order and an error", order, err)
+	}
+	if status, _ := e.Status(context.Background()); status.Position != 0 {
+		t.Fatalf("position after a failed order = %g, want 0", status.Position)
+	}
+	if got := run(t, e, "BUY"); got[0] != 0.03 {
+		t.Fatalf("retry bought %g, want 0.03", got[0])
+	}
+
+	// the position is restored from the audit log, without the failed order
+	restored, err := New(cfg, exchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := restored.Status(context.Background()); status.Position != 0.03 {
+		t.Fatalf("restored position = %g, want 0.03", status.Position)
+	}
+}
+
+func TestUncertainOrderKeepsClientID(t *testing.T) {
+	exchange := &fakeExchange{failures: 1, timeouts: 3}
+	e, err := New(testConfig(t, true), exchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, signal := range []string{"BUY", "BUY", "BUY", "HOLD", "BUY", "BUY"} {
+		e.OnResult(context.Background(), resultWith(signal))
+	}
+
+	// rejected; then timed out and retried with the same ID, dropped on HOLD;
+	// then a new order that timed out and went through on its retry
+	ids := exchange.clientID
+	if len(ids) != 5 {
+		t.Fatalf("sent %d orders, want 5", len(ids))
+	}
+	if ids[1] == ids[0] {
+		t.Error("order after a rejection reused the rejected client ID")
+	}
+	if ids[2] != ids[1] {
+		t.Error("retry after a timeout has a new client ID")
+	}
+	if ids[3] == ids[2] || ids[4] != ids[3] {
+		t.Errorf("client IDs after the signal changed = %v", ids[3:])
+	}
+	if len(exchange.orders) != 1 {
+		t.Errorf("placed %d orders, want 1", len(exchange.orders))
+	}
+}
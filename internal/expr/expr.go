@@ -0,0 +1,528 @@
+// Package expr is a small expression language for custom indicators. An
+// expression is evaluated over whole series at once: every variable is a
+// series with one value per bar, and the result is another such series.
+// Values are NaN where they are undefined, e.g. during an indicator's warm-up.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Env holds the series an expression can reference
+type Env struct {
+	Len  int                  // number of bars
+	Vars map[string][]float64 // each series has Len values
+}
+
+// Program is a parsed expression
+type Program struct {
+	source string
+	root   node
+}
+
+// Parse parses an expression. Syntax, from lowest to highest precedence:
+//
+//	a || b    a && b                      logical, 1 is true and 0 false
+//	== != < <= > >=                       comparisons
+//	+ -    * / %                          arithmetic
+//	-a  !a                                negation
+//	x[n]                                  x as of n bars ago
+//	f(a, ...)                             function call, see Functions
+//
+// Periods of windowed functions such as sma(close, 20) must be number literals.
+func Parse(source string) (*Program, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.source
+}
+
+// Vars returns the variables the expression references, sorted
+func (p *Program) Vars() []string {
+	seen := make(map[string]bool)
+	p.root.vars(seen)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Eval evaluates the expression on every bar of env
+func (p *Program) Eval(env *Env) ([]float64, error) {
+	return p.root.eval(env)
+}
+
+// Truthy reports whether v counts as true: defined and non-zero
+func Truthy(v float64) bool {
+	return !math.IsNaN(v) && v != 0
+}
+
+// IsIdent reports whether name can be used as a variable name
+func IsIdent(name string) bool {
+	if name == "" || functions[name].fn != nil {
+		return false
+	}
+	for i, r := range name {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// Functions returns the names of the built-in functions, sorted
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tokenizer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "[", "]", ","}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(source) && (unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: source[start:i], pos: start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(source) && (source[i] == '_' || unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: source[start:i], pos: start})
+		default:
+			matched := ""
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: matched, pos: i})
+			i += len(matched)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(source)}), nil
+}
+
+// Parser
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the operators ops
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected %q but found %q at offset %d", op, tok.text, tok.pos)
+	}
+	return nil
+}
+
+// binaryLevel parses a left-associative chain of ops over operands from next
+func (p *parser) binaryLevel(next func() (node, error), ops ...string) (node, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.binaryLevel(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.binaryLevel(p.parseComparison, "&&")
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.binaryLevel(p.parseAdditive, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.binaryLevel(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.binaryLevel(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("-", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("["); !ok {
+			return n, nil
+		}
+		bars, err := p.parsePeriod(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		n = &shiftNode{operand: n, bars: bars}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", tok.text, tok.pos)
+		}
+		return numberNode(v), nil
+	case tokIdent:
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok)
+		}
+		return varNode(tok.text), nil
+	case tokOp:
+		if tok.text == "(" {
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+}
+
+// parseCall parses the arguments of a call to the function named by tok
+func (p *parser) parseCall(tok token) (node, error) {
+	fn, ok := functions[tok.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", tok.text, tok.pos)
+	}
+
+	call := &callNode{name: tok.text, fn: fn}
+	if _, ok := p.accept(")"); !ok {
+		for i := 0; ; i++ {
+			if fn.period && i == fn.args-1 {
+				period, err := p.parsePeriod(1)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", tok.text, err)
+				}
+				call.period = period
+			} else {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+			}
+			if _, ok := p.accept(")"); ok {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	got := len(call.args)
+	if fn.period && call.period > 0 {
+		got++
+	}
+	if got != fn.args {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", tok.text, fn.args, got)
+	}
+	return call, nil
+}
+
+// parsePeriod parses a whole number literal of at least min
+func (p *parser) parsePeriod(min int) (int, error) {
+	tok := p.next()
+	n, err := strconv.Atoi(tok.text)
+	if tok.kind != tokNumber || err != nil || n < min {
+		return 0, fmt.Errorf("expected a whole number of at least %d but found %q at offset %d", min, tok.text, tok.pos)
+	}
+	return n, nil
+}
+
+// Evaluation
+
+type node interface {
+	eval(env *Env) ([]float64, error)
+	vars(seen map[string]bool)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(env *Env) ([]float64, error) {
+	out := make([]float64, env.Len)
+	for i := range out {
+		out[i] = float64(n)
+	}
+	return out, nil
+}
+
+func (n numberNode) vars(map[string]bool) {}
+
+type varNode string
+
+func (n varNode) eval(env *Env) ([]float64, error) {
+	values, ok := env.Vars[string(n)]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", string(n))
+	}
+	if len(values) != env.Len {
+		return nil, fmt.Errorf("variable %q has %d values, want %d", string(n), len(values), env.Len)
+	}
+	return values, nil
+}
+
+func (n varNode) vars(seen map[string]bool) {
+	seen[string(n)] = true
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env *Env) ([]float64, error) {
+	x, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(x))
+	for i, v := range x {
+		switch {
+		case n.op == "-":
+			out[i] = -v
+		case math.IsNaN(v):
+			out[i] = math.NaN()
+		default:
+			out[i] = boolValue(v == 0)
+		}
+	}
+	return out, nil
+}
+
+func (n *unaryNode) vars(seen map[string]bool) {
+	n.operand.vars(seen)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env *Env) ([]float64, error) {
+	a, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(a))
+	for i := range a {
+		x, y := a[i], b[i]
+		if math.IsNaN(x) || math.IsNaN(y) {
+			out[i] = math.NaN()
+			continue
+		}
+		switch n.op {
+		case "+":
+			out[i] = x + y
+		case "-":
+			out[i] = x - y
+		case "*":
+			out[i] = x * y
+		case "/":
+			out[i] = x / y
+		case "%":
+			out[i] = math.Mod(x, y)
+		case "==":
+			out[i] = boolValue(x == y)
+		case "!=":
+			out[i] = boolValue(x != y)
+		case "<":
+			out[i] = boolValue(x < y)
+		case "<=":
+			out[i] = boolValue(x <= y)
+		case ">":
+			out[i] = boolValue(x > y)
+		case ">=":
+			out[i] = boolValue(x >= y)
+		case "&&":
+			out[i] = boolValue(x != 0 && y != 0)
+		case "||":
+			out[i] = boolValue(x != 0 || y != 0)
+		}
+	}
+	return out, nil
+}
+
+func (n *binaryNode) vars(seen map[string]bool) {
+	n.left.vars(seen)
+	n.right.vars(seen)
+}
+
+type shiftNode struct {
+	operand node
+	bars    int
+}
+
+func (n *shiftNode) eval(env *Env) ([]float64, error) {
+	x, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(x))
+	for i := range out {
+		if i < n.bars {
+			out[i] = math.NaN()
+		} else {
+			out[i] = x[i-n.bars]
+		}
+	}
+	return out, nil
+}
+
+func (n *shiftNode) vars(seen map[string]bool) {
+	n.operand.vars(seen)
+}
+
+type callNode struct {
+	name   string
+	fn     function
+	args   []node
+	period int
+}
+
+func (n *callNode) eval(env *Env) ([]float64, error) {
+	args := make([][]float64, len(n.args))
+	for i, arg := range n.args {
+		values, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = values
+	}
+	return n.fn.fn(args, n.period), nil
+}
+
+func (n *callNode) vars(seen map[string]bool) {
+	for _, arg := range n.args {
+		arg.vars(seen)
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,217 @@
+package expr
+
+import "math"
+
+// function is a built-in. Windowed functions take the period as their last
+// argument, a number literal passed separately from the series arguments.
+type function struct {
+	args   int
+	period bool
+	fn     func(args [][]float64, period int) []float64
+}
+
+var functions = map[string]function{
+	"sma":        {args: 2, period: true, fn: windowFunc(mean)},
+	"sum":        {args: 2, period: true, fn: windowFunc(sum)},
+	"stdev":      {args: 2, period: true, fn: windowFunc(stdev)},
+	"highest":    {args: 2, period: true, fn: windowFunc(highest)},
+	"lowest":     {args: 2, period: true, fn: windowFunc(lowest)},
+	"ema":        {args: 2, period: true, fn: ema},
+	"rsi":        {args: 2, period: true, fn: rsi},
+	"abs":        {args: 1, fn: mapFunc(math.Abs)},
+	"sqrt":       {args: 1, fn: mapFunc(math.Sqrt)},
+	"log":        {args: 1, fn: mapFunc(math.Log)},
+	"nz":         {args: 1, fn: mapFunc(nz)},
+	"min":        {args: 2, fn: pairFunc(math.Min)},
+	"max":        {args: 2, fn: pairFunc(math.Max)},
+	"if":         {args: 3, fn: ifFunc},
+	"crossover":  {args: 2, fn: crossFunc(1)},
+	"crossunder": {args: 2, fn: crossFunc(-1)},
+}
+
+// mapFunc applies f to each value
+func mapFunc(f func(float64) float64) func([][]float64, int) []float64 {
+	return func(args [][]float64, _ int) []float64 {
+		out := make([]float64, len(args[0]))
+		for i, v := range args[0] {
+			out[i] = f(v)
+		}
+		return out
+	}
+}
+
+// pairFunc applies f to the values of two series bar by bar
+func pairFunc(f func(float64, float64) float64) func([][]float64, int) []float64 {
+	return func(args [][]float64, _ int) []float64 {
+		out := make([]float64, len(args[0]))
+		for i := range out {
+			if math.IsNaN(args[0][i]) || math.IsNaN(args[1][i]) {
+				out[i] = math.NaN()
+			} else {
+				out[i] = f(args[0][i], args[1][i])
+			}
+		}
+		return out
+	}
+}
+
+// windowFunc applies f to the trailing window of period values at each bar.
+// The result is NaN until the window is full or while it holds a NaN.
+func windowFunc(f func([]float64) float64) func([][]float64, int) []float64 {
+	return func(args [][]float64, period int) []float64 {
+		x := args[0]
+		out := make([]float64, len(x))
+		valid := 0 // consecutive defined values up to i
+		for i, v := range x {
+			if math.IsNaN(v) {
+				valid = 0
+			} else {
+				valid++
+			}
+			if valid < period {
+				out[i] = math.NaN()
+				continue
+			}
+			out[i] = f(x[i-period+1 : i+1])
+		}
+		return out
+	}
+}
+
+func sum(window []float64) float64 {
+	total := 0.0
+	for _, v := range window {
+		total += v
+	}
+	return total
+}
+
+func mean(window []float64) float64 {
+	return sum(window) / float64(len(window))
+}
+
+// stdev is the population standard deviation, as used by Bollinger Bands
+func stdev(window []float64) float64 {
+	m := mean(window)
+	variance := 0.0
+	for _, v := range window {
+		variance += (v - m) * (v - m)
+	}
+	return math.Sqrt(variance / float64(len(window)))
+}
+
+func highest(window []float64) float64 {
+	h := window[0]
+	for _, v := range window[1:] {
+		h = math.Max(h, v)
+	}
+	return h
+}
+
+func lowest(window []float64) float64 {
+	l := window[0]
+	for _, v := range window[1:] {
+		l = math.Min(l, v)
+	}
+	return l
+}
+
+// ema is seeded with the SMA of the first period values and restarts after a NaN
+func ema(args [][]float64, period int) []float64 {
+	x := args[0]
+	out := make([]float64, len(x))
+	k := 2.0 / float64(period+1)
+	valid := 0
+	for i, v := range x {
+		switch {
+		case math.IsNaN(v):
+			valid = 0
+			out[i] = math.NaN()
+		case valid+1 < period:
+			valid++
+			out[i] = math.NaN()
+		case valid+1 == period:
+			valid++
+			out[i] = mean(x[i-period+1 : i+1])
+		default:
+			out[i] = (v-out[i-1])*k + out[i-1]
+		}
+	}
+	return out
+}
+
+// rsi is Wilder's relative strength index over period changes
+func rsi(args [][]float64, period int) []float64 {
+	x := args[0]
+	out := make([]float64, len(x))
+	var avgGain, avgLoss float64
+	changes := 0
+	for i := range x {
+		out[i] = math.NaN()
+		if i == 0 || math.IsNaN(x[i]) || math.IsNaN(x[i-1]) {
+			changes, avgGain, avgLoss = 0, 0, 0
+			continue
+		}
+
+		change := x[i] - x[i-1]
+		gain, loss := math.Max(change, 0), math.Max(-change, 0)
+		changes++
+		if changes <= period {
+			avgGain += gain / float64(period)
+			avgLoss += loss / float64(period)
+			if changes < period {
+				continue
+			}
+		} else {
+			avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+		}
+
+		if avgLoss == 0 {
+			out[i] = 100
+		} else {
+			out[i] = 100 - 100/(1+avgGain/avgLoss)
+		}
+	}
+	return out
+}
+
+func nz(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
+
+// ifFunc picks the second or third argument by the truth of the first
+func ifFunc(args [][]float64, _ int) []float64 {
+	out := make([]float64, len(args[0]))
+	for i, c := range args[0] {
+		switch {
+		case math.IsNaN(c):
+			out[i] = math.NaN()
+		case c != 0:
+			out[i] = args[1][i]
+		default:
+			out[i] = args[2][i]
+		}
+	}
+	return out
+}
+
+// crossFunc returns 1 on bars where a crosses b upwards (direction 1) or
+// downwards (direction -1)
+func crossFunc(direction float64) func([][]float64, int) []float64 {
+	return func(args [][]float64, _ int) []float64 {
+		a, b := args[0], args[1]
+		out := make([]float64, len(a))
+		for i := range out {
+			if i == 0 || math.IsNaN(a[i]) || math.IsNaN(b[i]) || math.IsNaN(a[i-1]) || math.IsNaN(b[i-1]) {
+				out[i] = math.NaN()
+				continue
+			}
+			out[i] = boolValue(direction*(a[i]-b[i]) > 0 && direction*(a[i-1]-b[i-1]) <= 0)
+		}
+		return out
+	}
+}
@@ -0,0 +1,194 @@
+package forensics
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// minBenfordSamples is the fewest volumes a Benford test is meaningful on
+const minBenfordSamples = 50
+
+// benfordCriticalChiSquare is the 0.1% critical value of chi-square with 8
+// degrees of freedom, used for windows too small for the MAD thresholds
+const benfordCriticalChiSquare = 26.12
+
+// AnalyzeVolume tests the volume series against Benford's law and for round
+// number clustering, then scans non-overlapping windows of window bars for
+// periods that look like wash trading or fabricated volume
+func AnalyzeVolume(bts *types.BTCTimeSeries, window int) types.VolumeForensics {
+	volumes := make([]float64, 0, len(bts.Data))
+	for _, bar := range bts.Data {
+		if bar.Volume > 0 {
+			volumes = append(volumes, bar.Volume)
+		}
+	}
+
+	vf := types.VolumeForensics{Samples: len(volumes)}
+	if len(volumes) == 0 {
+		return vf
+	}
+
+	vf.BenfordObserved, vf.BenfordChiSquare, vf.BenfordMAD = BenfordTest(volumes)
+	vf.BenfordConformity = BenfordConformity(vf.BenfordMAD)
+	vf.RoundShare = roundShare(volumes)
+
+	if window > 0 && len(bts.Data) >= window {
+		vf.SuspiciousPeriods = findSuspiciousPeriods(bts, window, vf.RoundShare, vf.BenfordMAD)
+	}
+
+	return vf
+}
+
+// BenfordTest compares the leading digits of values with Benford's law,
+// returning the observed digit shares, the chi-square statistic and the mean
+// absolute deviation. Non-positive values are ignored.
+func BenfordTest(values []float64) ([9]float64, float64, float64) {
+	var observed [9]float64
+	n := 0
+	for _, value := range values {
+		if digit := leadingDigit(value); digit > 0 {
+			observed[digit-1]++
+			n++
+		}
+	}
+	if n == 0 {
+		return observed, 0, 0
+	}
+
+	chiSquare, mad := 0.0, 0.0
+	for d := 1; d <= 9; d++ {
+		expected := math.Log10(1 + 1/float64(d))
+		observed[d-1] /= float64(n)
+		chiSquare += float64(n) * math.Pow(observed[d-1]-expected, 2) / expected
+		mad += math.Abs(observed[d-1]-expected) / 9
+	}
+
+	return observed, chiSquare, mad
+}
+
+// BenfordConformity grades a first-digit mean absolute deviation using
+// Nigrini's thresholds
+func BenfordConformity(mad float64) string {
+	switch {
+	case mad < 0.006:
+		return "close"
+	case mad < 0.012:
+		return "acceptable"
+	case mad < 0.015:
+		return "marginal"
+	}
+	return "nonconforming"
+}
+
+// findSuspiciousPeriods flags windows with clustered round volumes, heavy
+// volume that did not move price, or leading digits far from Benford's law.
+// Round-number and Benford checks are relative to the whole-series baseline,
+// so a series that never conforms does not flag every window.
+func findSuspiciousPeriods(bts *types.BTCTimeSeries, window int, baselineRound, baselineMAD float64) []types.SuspiciousPeriod {
+	var volumes, moves []float64
+	for _, bar := range bts.Data {
+		volumes = append(volumes, bar.Volume)
+		if bar.Open > 0 {
+			moves = append(moves, math.Abs(bar.Close-bar.Open)/bar.Open)
+		}
+	}
+	medianVolume := median(volumes)
+	medianMove := median(moves)
+
+	var periods []types.SuspiciousPeriod
+	for start := 0; start+window <= len(bts.Data); start += window {
+		bars := bts.Data[start : start+window]
+
+		var windowVolumes []float64
+		flat := 0
+		for _, bar := range bars {
+			if bar.Volume <= 0 {
+				continue
+			}
+			windowVolumes = append(windowVolumes, bar.Volume)
+			if bar.Open > 0 && bar.Volume > 3*medianVolume && math.Abs(bar.Close-bar.Open)/bar.Open < 0.1*medianMove {
+				flat++
+			}
+		}
+
+		period := types.SuspiciousPeriod{
+			Start:      bars[0].Timestamp,
+			End:        bars[len(bars)-1].Timestamp,
+			RoundShare: roundShare(windowVolumes),
+			FlatBars:   flat,
+		}
+		if period.RoundShare > math.Max(0.1, 3*baselineRound) {
+			period.Reasons = append(period.Reasons, numfmt.Pct(period.RoundShare)+" round-number volumes")
+		}
+		if flat >= 3 && flat >= window/10 {
+			period.Reasons = append(period.Reasons, fmt.Sprintf("%d high-volume bars without price movement", flat))
+		}
+		if len(windowVolumes) >= minBenfordSamples {
+			if _, chiSquare, mad := BenfordTest(windowVolumes); chiSquare > benfordCriticalChiSquare && mad > 2*baselineMAD {
+				period.Reasons = append(period.Reasons, fmt.Sprintf("leading digits deviate from Benford's law (chi-square %.1f)", chiSquare))
+			}
+		}
+
+		if len(period.Reasons) > 0 {
+			periods = append(periods, period)
+		}
+	}
+
+	return periods
+}
+
+// leadingDigit returns the first significant digit of value, or 0 when value is not positive
+func leadingDigit(value float64) int {
+	if value <= 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0
+	}
+	digit := int(value / math.Pow(10, math.Floor(math.Log10(value))))
+	// Guard against floating-point error at exact powers of ten
+	if digit < 1 {
+		digit = 1
+	}
+	if digit > 9 {
+		digit = 9
+	}
+	return digit
+}
+
+// isRound reports whether value has at most two significant digits, e.g. 1500 or 0.25
+func isRound(value float64) bool {
+	if value <= 0 {
+		return false
+	}
+	scaled := value / math.Pow(10, math.Floor(math.Log10(value))-1)
+	return math.Abs(scaled-math.Round(scaled)) < 1e-6
+}
+
+// roundShare returns the share of values that are round numbers
+func roundShare(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	round := 0
+	for _, value := range values {
+		if isRound(value) {
+			round++
+		}
+	}
+	return float64(round) / float64(len(values))
+}
+
+// median returns the median of values without modifying them
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,167 @@
+// Package glossary is the registry of the metrics shown in reports: what each
+// one tells a reader, how this analyzer computes it and the parameters it is
+// computed with, so reports can explain themselves to newcomers.
+package glossary
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// Metric keys of the registry
+const (
+	Volatility         = "volatility"
+	SharpeRatio        = "sharpe_ratio"
+	MaxDrawdown        = "max_drawdown"
+	ValueAtRisk        = "var_95"
+	ConditionalDaR     = "cdar_95"
+	AdjustedSharpe     = "adjusted_sharpe"
+	RSI                = "rsi"
+	MACD               = "macd"
+	BollingerBands     = "bollinger_bands"
+	SupportResistance  = "support_resistance"
+	PercentileRank     = "percentile_rank"
+	SignalScore        = "signal_score"
+	Performance        = "performance"
+	RateOfChange       = "roc"
+	DrawdownFromATH    = "drawdown_from_ath"
+	SessionGaps        = "session_gaps"
+	ConfidenceInterval = "confidence_interval"
+	Stationarity       = "stationarity"
+	HalfLife           = "half_life"
+	ReturnAttribution  = "return_attribution"
+)
+
+// Entry explains one metric
+type Entry struct {
+	Key         string
+	Name        string
+	Meaning     string // what the metric tells a reader
+	Computation string // how this analyzer computes it
+	Parameters  string // the parameters of the run, empty when it has none
+}
+
+// definition is a registry entry whose parameters depend on the run's risk
+// convention
+type definition struct {
+	key, name, meaning, computation string
+	parameters                      func(rc types.RiskConfig) string
+}
+
+// fixed returns parameters that do not depend on the run
+func fixed(parameters string) func(types.RiskConfig) string {
+	return func(types.RiskConfig) string { return parameters }
+}
+
+var registry = []definition{
+	{Volatility, "Volatility",
+		"How much the price swings, as the typical size of a year's move. Higher volatility means larger moves in either direction and a wider range of outcomes.",
+		"The standard deviation of per-bar returns, or a range-based estimate from the bars' highs and lows, scaled to a year by the square root of the bars per year.",
+		func(rc types.RiskConfig) string {
+			return fmt.Sprintf("%s estimator, %d bars per year", statistics.EstimatorName(rc.VolEstimator), rc.PeriodsPerYear)
+		}},
+	{SharpeRatio, "Sharpe Ratio",
+		"Return earned per unit of risk taken. Above 1 is generally considered good; below 0 means the asset did worse than the risk-free rate.",
+		"The annualized return minus the risk-free rate and funding cost, divided by the annualized volatility.",
+		func(rc types.RiskConfig) string {
+			return fmt.Sprintf("risk-free %.2f%%/yr, funding %.2f%%/yr, %s compounding, %d bars per year",
+				rc.RiskFreeRate*100, rc.FundingRate*100, rc.Compounding, rc.PeriodsPerYear)
+		}},
+	{MaxDrawdown, "Max Drawdown",
+		"The largest fall from a high to a later low: the worst loss a holder who bought at the top would have sat through.",
+		"The largest decline of a close below the highest close before it, as a fraction of that high.",
+		fixed("closes over the whole series")},
+	{ValueAtRisk, "Value at Risk (VaR 95%)",
+		"A loss that a single bar should exceed only one time in twenty. It describes an ordinary bad day, not the worst case.",
+		"The 5% quantile of per-bar returns assuming a normal distribution, and a Cornish-Fisher version corrected for the skew and fat tails of the actual returns.",
+		fixed("95% confidence, one bar")},
+	{ConditionalDaR, "Conditional Drawdown at Risk (CDaR 95%)",
+		"How deep the worst stretches below a prior high have been on average, a measure of the pain of the bad periods rather than a single day.",
+		"The mean of the worst 5% of the drawdowns from the running peak, taken at every bar.",
+		fixed("worst 5% of drawdowns")},
+	{AdjustedSharpe, "Skew/Kurtosis-Adjusted Sharpe",
+		"The Sharpe ratio corrected for returns that are not normally distributed. Crash-prone returns make it lower than the plain Sharpe ratio.",
+		"The per-bar Sharpe ratio SR adjusted as SR x (1 + skew/6 x SR - excess kurtosis/24 x SR²), then annualized.",
+		func(rc types.RiskConfig) string { return fmt.Sprintf("%d bars per year", rc.PeriodsPerYear) }},
+	{RSI, "RSI (Relative Strength Index)",
+		"Momentum on a 0 to 100 scale. Readings above 70 suggest the price has risen too far too fast (overbought), below 30 that it has fallen too far (oversold).",
+		"100 - 100 / (1 + average gain / average loss) of close-to-close changes, with Wilder's smoothing of the averages.",
+		fixed("14 bars; overbought above 70, oversold below 30")},
+	{MACD, "MACD (Moving Average Convergence Divergence)",
+		"Trend momentum. MACD above its signal line suggests rising momentum, below it falling momentum; crossings mark turns.",
+		"The 12-bar minus the 26-bar exponential moving average of closes; the signal line is the 9-bar EMA of MACD and the histogram their difference.",
+		fixed("12/26/9 bars")},
+	{BollingerBands, "Bollinger Bands",
+		"A channel around the average price that widens when the market is volatile. Closes outside the bands are unusually stretched.",
+		"The 20-bar simple moving average of closes, plus and minus two standard deviations of those closes.",
+		fixed("20 bars, 2 standard deviations")},
+	{SupportResistance, "Support and Resistance",
+		"Prices where the market has repeatedly turned: support below the price where buyers stepped in, resistance above where sellers did.",
+		"Swing highs and lows confirmed by the bars on both sides, with nearby levels merged and scored by touches, recency and volume.",
+		fixed("5 bars either side of a swing, levels within 2% merged")},
+	{Performance, "Performance",
+		"How much the price has moved over the last day, week, month, quarter, calendar year to date and year.",
+		"The latest close over the close in effect at the start of each horizon, minus one. YTD starts from the last close of the previous year.",
+		fixed("1d, 7d, 30d, 90d, YTD and 1y")},
+	{RateOfChange, "ROC (Rate of Change)",
+		"Momentum as the percentage move over a fixed number of bars. Positive readings mean the price is above where it was, and a falling ROC means the move is slowing.",
+		"The close over the close the given number of bars earlier, minus one.",
+		fixed("12 bars")},
+	{DrawdownFromATH, "Drawdown from All-Time High",
+		"How far the price sits below its highest close, and how long earlier falls of the same depth took to make the high back.",
+		"One minus the latest close over the all-time high. Earlier drawdowns count from a running peak close to the close that regains it; recovery days run from the first close at the current depth.",
+		fixed("closes of the loaded data, or a known historical high when it is higher; compared at drawdowns of 5% or more")},
+	{SessionGaps, "Session Gaps",
+		"Jumps between the close of one trading session and the open of the next, such as CME futures weekend gaps. Traders expect price to come back and fill them, so unfilled gaps act as support or resistance targets.",
+		"Sessions break where bars are more than one and a half bar intervals apart. A gap is filled once a later bar trades back to the close before it; unfilled gaps join the level map.",
+		fixed("gaps of 0.2% or more; continuous series have none")},
+	{ConfidenceInterval, "Confidence Interval",
+		"The range the true mean return, volatility or Sharpe ratio plausibly lies in given how much data there is. Short samples give wide ranges, so a high Sharpe ratio whose range includes zero may be luck.",
+		"A moving block bootstrap: the returns are resampled with replacement in runs of consecutive bars, keeping volatility clustering, and each figure is recomputed on every resample. The interval spans the middle percentiles of the recomputed figures.",
+		fixed(fmt.Sprintf("%d resamples, %.0f%% confidence, blocks of the cube root of the number of returns", statistics.DefaultBootstrapResamples, statistics.DefaultConfidenceLevel*100))},
+	{Stationarity, "Stationarity (ADF/KPSS)",
+		"Whether a series keeps a stable mean and variance. Prices that are not stationary wander without a level to return to, so regressions on price levels can find trends that are not there and mean-reversion bets on price have no anchor.",
+		"The augmented Dickey-Fuller test regresses each change on the previous level and passes when it rejects a unit root. The KPSS test compares the partial sums of deviations from the mean with the long-run variance and passes when it does not reject stationarity. Both passing means stationary, both failing means a unit root.",
+		fixed("5% significance, with a constant; lags up to 12·(n/100)^¼, chosen by AIC for ADF")},
+	{HalfLife, "Mean-Reversion Half-Life",
+		"How many bars it takes a move away from the long-run level to shrink by half if the price mean reverts. It sets the time scale of mean-reversion rules: a lookback much shorter chases noise, one much longer waits out the reversion.",
+		"An Ornstein-Uhlenbeck process fitted by regressing each log price on the previous one. A coefficient φ between 0 and 1 gives a reversion speed of -ln φ per bar and a half-life of ln 2 over it; the Dickey-Fuller statistic of φ tells whether the reversion is significant.",
+		fixed("log closes; lookbacks of half, one and two half-lives")},
+	{ReturnAttribution, "Return Attribution",
+		"Where the returns of a series accrue: in the gaps between a close and the next open or within the bars, and in which hours and weekdays. A steady pattern suggests when to schedule buys; without significant t-statistics it is more likely noise.",
+		"Each bar's log return splits into the move from the previous close to its open and from its open to its close. Close-to-close log returns are bucketed by the UTC hour and weekday of the bar's open; the cheapest hour or weekday is where the average cycle of bucket means, net of its drift, is lowest.",
+		fixed("UTC; hours for bars of an hour or less, weekdays for bars of a day or less; significance at |t| ≥ 2")},
+	{PercentileRank, "Percentile Rank",
+		"Where the latest reading sits in its own recent history: the 90th percentile means higher than 90% of the past year.",
+		"The share of the trailing values at or below the latest one.",
+		fixed("trailing year of bars, at least 20 values")},
+	{SignalScore, "Signal Score",
+		"The balance of all trading signals, from -100 (every signal says sell) to +100 (every signal says buy).",
+		"The number of BUY signals minus the number of SELL signals, divided by the number of signals.",
+		fixed("")},
+}
+
+// Entries returns every entry of the registry with the parameters of rc
+func Entries(rc types.RiskConfig) []Entry {
+	entries := make([]Entry, len(registry))
+	for i, def := range registry {
+		entries[i] = entryOf(def, rc)
+	}
+	return entries
+}
+
+// Lookup returns the entry of key with the parameters of rc
+func Lookup(key string, rc types.RiskConfig) (Entry, bool) {
+	for _, def := range registry {
+		if def.key == key {
+			return entryOf(def, rc), true
+		}
+	}
+	return Entry{}, false
+}
+
+// entryOf fills in the parameters of def
+func entryOf(def definition, rc types.RiskConfig) Entry {
+	return Entry{Key: def.key, Name: def.name, Meaning: def.meaning, Computation: def.computation, Parameters: def.parameters(rc)}
+}
@@ -0,0 +1,170 @@
+// Package graph evaluates named computations that depend on each other. Each
+// node is computed at most once per evaluation, only when a requested node
+// needs it, with independent dependencies computed in parallel.
+package graph
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Func computes a node from the values of its dependencies, keyed by name
+type Func func(deps map[string]interface{}) (interface{}, error)
+
+// node is a computation and the names it depends on
+type node struct {
+	deps    []string
+	compute Func
+}
+
+// Graph is a set of nodes. It is not safe to add nodes during an evaluation.
+type Graph struct {
+	nodes map[string]*node
+	order []string // insertion order
+}
+
+// New returns an empty graph
+func New() *Graph {
+	return &Graph{nodes: make(map[string]*node)}
+}
+
+// Add adds a node computed by compute from the nodes named in deps. The
+// dependencies may be added later.
+func (g *Graph) Add(name string, deps []string, compute Func) error {
+	if _, exists := g.nodes[name]; exists {
+		return fmt.Errorf("duplicate node %q", name)
+	}
+	g.nodes[name] = &node{deps: deps, compute: compute}
+	g.order = append(g.order, name)
+	return nil
+}
+
+// Names returns the node names in the order they were added
+func (g *Graph) Names() []string {
+	return append([]string(nil), g.order...)
+}
+
+// Plan returns the nodes needed to compute targets, each after its
+// dependencies. It fails on unknown nodes and dependency cycles.
+func (g *Graph) Plan(targets ...string) ([]string, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var plan []string
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		n, ok := g.nodes[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("%s depends on unknown node %q", path[len(path)-1], name)
+			}
+			return fmt.Errorf("unknown node %q", name)
+		}
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v", append(path, name))
+		}
+		state[name] = visiting
+		for _, dep := range n.deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		plan = append(plan, name)
+		return nil
+	}
+
+	for _, target := range targets {
+		if err := visit(target, nil); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// Evaluation memoizes the node values of one evaluation of a graph
+type Evaluation struct {
+	graph   *Graph
+	planned map[string]bool
+	mu      sync.Mutex
+	results map[string]*result
+}
+
+// result is the value of a node, computed once
+type result struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// Evaluate plans targets and returns an evaluation that computes them and
+// their dependencies on demand. Other nodes are never computed.
+func (g *Graph) Evaluate(targets ...string) (*Evaluation, error) {
+	plan, err := g.Plan(targets...)
+	if err != nil {
+		return nil, err
+	}
+	planned := make(map[string]bool, len(plan))
+	for _, name := range plan {
+		planned[name] = true
+	}
+	return &Evaluation{graph: g, planned: planned, results: make(map[string]*result)}, nil
+}
+
+// Get returns the value of a planned node, computing it and its dependencies
+// first if needed. A node whose dependency failed fails too.
+func (e *Evaluation) Get(name string) (interface{}, error) {
+	if !e.planned[name] {
+		return nil, fmt.Errorf("node %q is not part of the evaluation", name)
+	}
+	n := e.graph.nodes[name]
+
+	e.mu.Lock()
+	r, ok := e.results[name]
+	if !ok {
+		r = &result{}
+		e.results[name] = r
+	}
+	e.mu.Unlock()
+
+	r.once.Do(func() {
+		deps, err := e.GetAll(n.deps...)
+		if err != nil {
+			r.err = err
+			return
+		}
+		r.value, r.err = n.compute(deps)
+	})
+	return r.value, r.err
+}
+
+// GetAll returns the values of the named nodes, computed in parallel. It
+// fails with the first error in names order.
+func (e *Evaluation) GetAll(names ...string) (map[string]interface{}, error) {
+	values := make([]interface{}, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			values[i], errs[i] = e.Get(name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	deps := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("%s: %w", name, errs[i])
+		}
+		deps[name] = values[i]
+	}
+	return deps, nil
+}
@@ -0,0 +1,301 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field is one selected field of a query, with its optional alias, arguments
+// and sub-selection
+type Field struct {
+	Alias      string
+	Name       string
+	Args       map[string]interface{}
+	Selections []Field
+}
+
+// Parse parses the subset of GraphQL the report endpoint supports: a single
+// anonymous or named query of nested field selections with aliases and
+// literal arguments (numbers, strings, booleans). Fragments, variables and
+// directives are not supported.
+func Parse(query string) ([]Field, error) {
+	p := &parser{tokens: tokenize(query)}
+
+	if p.peek() == "query" {
+		p.next()
+		// Optional operation name
+		if tok := p.peek(); tok != "{" && tok != "" {
+			p.next()
+		}
+	}
+
+	fields, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok != "" {
+		return nil, fmt.Errorf("unexpected %q after query", tok)
+	}
+	return fields, nil
+}
+
+// Execute resolves fields against root, a tree of JSON-decoded values.
+// Field names match case-insensitively, so rsi selects RSI. On lists,
+// `last: n` and `first: n` keep only the last or first n elements, and a
+// selection set is applied to every element.
+func Execute(root interface{}, fields []Field) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		value, err := resolve(root, field)
+		if err != nil {
+			return nil, err
+		}
+		key := field.Name
+		if field.Alias != "" {
+			key = field.Alias
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// resolve looks up field on parent and applies its arguments and selections
+func resolve(parent interface{}, field Field) (interface{}, error) {
+	object, ok := parent.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot select %q on a non-object value", field.Name)
+	}
+
+	value, found := object[field.Name]
+	if !found {
+		for key, candidate := range object {
+			if strings.EqualFold(key, field.Name) {
+				value, found = candidate, true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown field %q", field.Name)
+	}
+
+	if list, ok := value.([]interface{}); ok {
+		var err error
+		if list, err = sliceList(list, field); err != nil {
+			return nil, err
+		}
+		if len(field.Selections) == 0 {
+			return list, nil
+		}
+		items := make([]interface{}, len(list))
+		for i, item := range list {
+			if items[i], err = Execute(item, field.Selections); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+
+	if len(field.Args) > 0 {
+		return nil, fmt.Errorf("field %q takes no arguments", field.Name)
+	}
+	if len(field.Selections) > 0 && value != nil {
+		return Execute(value, field.Selections)
+	}
+	return value, nil
+}
+
+// sliceList applies the first/last arguments of field to list
+func sliceList(list []interface{}, field Field) ([]interface{}, error) {
+	for name, arg := range field.Args {
+		n, ok := arg.(int)
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("argument %q of %q must be a non-negative integer", name, field.Name)
+		}
+		if n > len(list) {
+			n = len(list)
+		}
+		switch name {
+		case "first":
+			list = list[:n]
+		case "last":
+			list = list[len(list)-n:]
+		default:
+			return nil, fmt.Errorf("unknown argument %q of %q", name, field.Name)
+		}
+	}
+	return list, nil
+}
+
+// parser walks the token stream of a query
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *parser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		if got == "" {
+			return fmt.Errorf("expected %q, got end of query", tok)
+		}
+		return fmt.Errorf("expected %q, got %q", tok, got)
+	}
+	return nil
+}
+
+// selectionSet parses { field field ... }
+func (p *parser) selectionSet() ([]Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []Field
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return nil, fmt.Errorf("unterminated selection set")
+		}
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	p.next()
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return fields, nil
+}
+
+// field parses [alias:] name [(args)] [{ selections }]
+func (p *parser) field() (Field, error) {
+	name := p.next()
+	if !isName(name) {
+		return Field{}, fmt.Errorf("expected field name, got %q", name)
+	}
+	field := Field{Name: name}
+
+	if p.peek() == ":" {
+		p.next()
+		field.Alias = name
+		if field.Name = p.next(); !isName(field.Name) {
+			return Field{}, fmt.Errorf("expected field name after alias %q", name)
+		}
+	}
+
+	if p.peek() == "(" {
+		p.next()
+		field.Args = make(map[string]interface{})
+		for p.peek() != ")" {
+			argName := p.next()
+			if !isName(argName) {
+				return Field{}, fmt.Errorf("expected argument name in %q, got %q", field.Name, argName)
+			}
+			if err := p.expect(":"); err != nil {
+				return Field{}, err
+			}
+			value, err := literal(p.next())
+			if err != nil {
+				return Field{}, err
+			}
+			field.Args[argName] = value
+		}
+		p.next()
+	}
+
+	if p.peek() == "{" {
+		selections, err := p.selectionSet()
+		if err != nil {
+			return Field{}, err
+		}
+		field.Selections = selections
+	}
+
+	return field, nil
+}
+
+// literal converts an argument token to an int, float, bool or string
+func literal(tok string) (interface{}, error) {
+	switch {
+	case tok == "true" || tok == "false":
+		return tok == "true", nil
+	case strings.HasPrefix(tok, `"`):
+		return strconv.Unquote(tok)
+	}
+	if n, err := strconv.Atoi(tok); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(tok, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid argument value %q", tok)
+}
+
+// tokenize splits a query into names, numbers, strings and punctuation.
+// Commas are insignificant in GraphQL and comments run from # to end of line.
+func tokenize(query string) []string {
+	var tokens []string
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r) || r == ',':
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case strings.ContainsRune("{}():", r):
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(runes) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("{}():,#\"", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens
+}
+
+// isName reports whether tok is a valid GraphQL name
+func isName(tok string) bool {
+	if tok == "" {
+		return false
+	}
+	for i, r := range tok {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+// Package grpcapi serves the analysis of a server over gRPC with the typed
+// messages of api/proto/btc_analyzer.proto, for clients generated from the
+// schema rather than scraping the JSON API
+package grpcapi
+
+import (
+	"btc-analyzer/api/btcanalyzerpb"
+	"btc-analyzer/internal/server"
+	"btc-analyzer/internal/types"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements the Analyzer service over the analysis srv serves
+type Service struct {
+	btcanalyzerpb.UnimplementedAnalyzerServer
+	srv  *server.Server
+	done chan struct{}
+}
+
+// New returns the Analyzer service of srv
+func New(srv *server.Server) *Service {
+	return &Service{srv: srv, done: make(chan struct{})}
+}
+
+// Server is a gRPC server with the Analyzer service
+type Server struct {
+	*grpc.Server
+	service *Service
+}
+
+// NewServer returns a gRPC server with the Analyzer service of srv
+func NewServer(srv *server.Server) *Server {
+	g := &Server{Server: grpc.NewServer(), service: New(srv)}
+	btcanalyzerpb.RegisterAnalyzerServer(g.Server, g.service)
+	return g
+}
+
+// Shutdown ends the open streams and stops the server after its in-flight
+// calls, or at once when ctx is done first
+func (g *Server) Shutdown(ctx context.Context) {
+	close(g.service.done)
+	stopped := make(chan struct{})
+	go func() {
+		g.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		g.Stop()
+	}
+}
+
+// current returns the served result, Unavailable before the first analysis
+func (s *Service) current() (*types.AnalysisResult, error) {
+	result, ok := s.srv.Current()
+	if !ok || result.Series == nil {
+		return nil, status.Error(codes.Unavailable, "no analysis available yet")
+	}
+	return result, nil
+}
+
+// GetTimeSeries returns the bars of the served series within the requested
+// range, the last n of them when last is positive
+func (s *Service) GetTimeSeries(ctx context.Context, req *btcanalyzerpb.TimeSeriesRequest) (*btcanalyzerpb.TimeSeries, error) {
+	if _, err := s.current(); err != nil {
+		return nil, err
+	}
+	if req.GetLast() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "last must not be negative")
+	}
+
+	series := s.srv.Series()
+	start, end := series.TimeRange()
+	if req.Start != nil {
+		start = req.Start.AsTime()
+	}
+	if req.End != nil {
+		end = req.End.AsTime()
+	}
+	var bars []*btcanalyzerpb.Price
+	for _, p := range series.Between(start, end) {
+		// end is exclusive, unlike Between
+		if req.End != nil && p.Timestamp.Equal(end) {
+			continue
+		}
+		bars = append(bars, toPrice(p))
+	}
+	if last := int(req.GetLast()); last > 0 && len(bars) > last {
+		bars = bars[len(bars)-last:]
+	}
+	return &btcanalyzerpb.TimeSeries{Symbol: series.Symbol(), Data: bars}, nil
+}
+
+// GetAnalytics returns the analytics of the served analysis, with only the
+// latest value of each indicator unless include_series
+func (s *Service) GetAnalytics(ctx context.Context, req *btcanalyzerpb.AnalyticsRequest) (*btcanalyzerpb.Analytics, error) {
+	result, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return toAnalytics(result.Analytics, req.GetIncludeSeries())
+}
+
+// GetSignals returns the trading signals and their composite score
+func (s *Service) GetSignals(ctx context.Context, req *btcanalyzerpb.SignalsRequest) (*btcanalyzerpb.Signals, error) {
+	result, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return toSignals(result), nil
+}
+
+// StreamUpdates sends the current analysis, then every new one until the
+// client goes away
+func (s *Service) StreamUpdates(req *btcanalyzerpb.StreamRequest, stream btcanalyzerpb.Analyzer_StreamUpdatesServer) error {
+	updates, stop := s.srv.Subscribe()
+	defer stop()
+
+	if result, ok := s.srv.Current(); ok {
+		if err := s.send(stream, result, req.GetIncludeAnalytics()); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return nil
+		case result := <-updates:
+			if err := s.send(stream, result, req.GetIncludeAnalytics()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send sends the update of result on stream
+func (s *Service) send(stream btcanalyzerpb.Analyzer_StreamUpdatesServer, result *types.AnalysisResult, includeAnalytics bool) error {
+	if result.Series == nil || len(result.Series.Data) == 0 {
+		return nil
+	}
+	update := &btcanalyzerpb.Update{
+		Bar:     toPrice(result.Series.Data[len(result.Series.Data)-1]),
+		Signals: toSignals(result),
+	}
+	if includeAnalytics {
+		analytics, err := toAnalytics(result.Analytics, false)
+		if err != nil {
+			return err
+		}
+		update.Analytics = analytics
+	}
+	return stream.Send(update)
+}
+
+func toPrice(p types.BTCPrice) *btcanalyzerpb.Price {
+	return &btcanalyzerpb.Price{
+		Timestamp: timestamppb.New(p.Timestamp),
+		Open:      p.Open,
+		High:      p.High,
+		Low:       p.Low,
+		Close:     p.Close,
+		Volume:    p.Volume,
+	}
+}
+
+func toSignals(result *types.AnalysisResult) *btcanalyzerpb.Signals {
+	return &btcanalyzerpb.Signals{Signals: result.Signals, Score: result.SignalScore}
+}
+
+func toStatistics(s types.Statistics) *btcanalyzerpb.Statistics {
+	return &btcanalyzerpb.Statistics{
+		Count:    int32(s.Count),
+		Mean:     s.Mean,
+		Median:   s.Median,
+		StdDev:   s.StdDev,
+		Min:      s.Min,
+		Max:      s.Max,
+		Variance: s.Variance,
+		Skewness: s.Skewness,
+		Kurtosis: s.Kurtosis,
+	}
+}
+
+// toAnalytics converts analytics; the analyses without a message of their
+// own go into details_json, without the series the message already carries
+func toAnalytics(a types.BTCAnalytics, includeSeries bool) (*btcanalyzerpb.Analytics, error) {
+	series := func(values []float64) []float64 {
+		if includeSeries || len(values) == 0 {
+			return values
+		}
+		return values[len(values)-1:]
+	}
+
+	levels := make([]*btcanalyzerpb.SRLevel, 0, len(a.SupportResistance.Levels))
+	for _, l := range a.SupportResistance.Levels {
+		levels = append(levels, &btcanalyzerpb.SRLevel{Price: l.Price, Type: l.Type, Source: l.Source})
+	}
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].Price < levels[j].Price })
+
+	details := a
+	details.PriceStats, details.VolumeStats = types.Statistics{}, types.Statistics{}
+	details.Returns, details.LogReturns, details.RSI = nil, nil, nil
+	details.MACD, details.BollingerBands = types.MACDData{}, types.BollingerBandsData{}
+	details.SupportResistance = types.SupportResistanceData{}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode analytics details: %v", err)
+	}
+
+	return &btcanalyzerpb.Analytics{
+		PriceStats:  toStatistics(a.PriceStats),
+		VolumeStats: toStatistics(a.VolumeStats),
+		Volatility:  a.Volatility,
+		SharpeRatio: a.SharpeRatio,
+		MaxDrawdown: a.MaxDrawdown,
+		Returns:     series(a.Returns),
+		LogReturns:  series(a.LogReturns),
+		Rsi:         series(a.RSI),
+		Macd: &btcanalyzerpb.MACD{
+			Macd:      series(a.MACD.MACD),
+			Signal:    series(a.MACD.Signal),
+			Histogram: series(a.MACD.Histogram),
+		},
+		BollingerBands: &btcanalyzerpb.BollingerBands{
+			Upper:  series(a.BollingerBands.Upper),
+			Middle: series(a.BollingerBands.Middle),
+			Lower:  series(a.BollingerBands.Lower),
+		},
+		SupportResistance: &btcanalyzerpb.SupportResistance{
+			SupportLevels:    a.SupportResistance.SupportLevels,
+			ResistanceLevels: a.SupportResistance.ResistanceLevels,
+			Levels:           levels,
+		},
+		RiskConvention: &btcanalyzerpb.RiskConfig{
+			RiskFreeRate:   a.RiskConvention.RiskFreeRate,
+			FundingRate:    a.RiskConvention.FundingRate,
+			PeriodsPerYear: int32(a.RiskConvention.PeriodsPerYear),
+			Compounding:    a.RiskConvention.Compounding,
+		},
+		DetailsJson: detailsJSON,
+	}, nil
+}
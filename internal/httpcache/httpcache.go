@@ -0,0 +1,323 @@
+package httpcache
+
+import (
+	"btc-analyzer/internal/ratelimit"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds configuration for the on-disk HTTP cache. MaxStale is the
+// age up to which a cached response stands in for a failed fetch; 0 never
+// serves one.
+type Config struct {
+	Dir      string
+	TTL      time.Duration
+	MaxStale time.Duration
+	Disabled bool
+}
+
+// DefaultConfig returns default cache configuration
+func DefaultConfig() Config {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return Config{
+		Dir:      filepath.Join(dir, "btc-analyzer", "http"),
+		TTL:      15 * time.Minute,
+		MaxStale: time.Hour,
+	}
+}
+
+// entry is the metadata stored next to each cached response body
+type entry struct {
+	URL          string // without its query, which may hold an API key
+	ETag         string
+	LastModified string
+	FetchedAt    time.Time
+}
+
+// Stale is a cached response served in place of a failed fetch
+type Stale struct {
+	URL       string // without its query, which may hold an API key
+	FetchedAt time.Time
+	Err       string // why the fetch failed
+}
+
+// String describes the stale response for run warnings
+func (s Stale) String() string {
+	return fmt.Sprintf("served a cached response of %s from %s: %s", s.URL, s.FetchedAt.Format(time.RFC3339), s.Err)
+}
+
+var (
+	mu     sync.RWMutex
+	config = DefaultConfig()
+	client = &http.Client{Timeout: 30 * time.Second}
+
+	staleMu sync.Mutex
+	stale   = make(map[string]Stale) // by URL, since the last TakeStale
+)
+
+// Configure replaces the cache configuration used by Get
+func Configure(cfg Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	config = cfg
+}
+
+// CurrentConfig returns the active cache configuration
+func CurrentConfig() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config
+}
+
+// TakeStale returns the stale responses Get served since the last call, so
+// a run can report that part of its data is out of date
+func TakeStale() []Stale {
+	staleMu.Lock()
+	defer staleMu.Unlock()
+	hits := make([]Stale, 0, len(stale))
+	for _, hit := range stale {
+		hits = append(hits, hit)
+	}
+	clear(stale)
+	sort.Slice(hits, func(i, j int) bool { return hits[i].URL < hits[j].URL })
+	return hits
+}
+
+// Get fetches url through the cache. Fresh entries (younger than the TTL) are
+// served from disk, stale entries are revalidated with ETag/Last-Modified, and
+// when the network or server fails an entry younger than MaxStale is served
+// instead of an error and recorded for TakeStale.
+func Get(url string) ([]byte, error) {
+	cfg := CurrentConfig()
+	if cfg.Disabled {
+		body, _, err := fetch(url, nil)
+		return body, err
+	}
+
+	bodyPath, metaPath := paths(cfg.Dir, url)
+	cached, cachedBody := load(bodyPath, metaPath)
+
+	if cached != nil && time.Since(cached.FetchedAt) < cfg.TTL {
+		return cachedBody, nil
+	}
+
+	body, notModified, err := fetch(url, cached)
+	if err != nil {
+		if cached != nil && time.Since(cached.FetchedAt) < cfg.MaxStale {
+			fmt.Printf("Warning: %v; using cached response from %s\n", err, cached.FetchedAt.Format(time.RFC3339))
+			recordStale(url, cached.FetchedAt, err)
+			return cachedBody, nil
+		}
+		return nil, err
+	}
+
+	if notModified {
+		cached.FetchedAt = time.Now()
+		save(bodyPath, metaPath, cached, nil)
+		return cachedBody, nil
+	}
+
+	return body, nil
+}
+
+// GetLive fetches url without the cache, for live data such as an order
+// book that must never be served from disk
+func GetLive(url string) ([]byte, error) {
+	body, _, _, err := request(url, nil)
+	return body, err
+}
+
+// redact returns url without its query, so API keys such as FRED's api_key
+// are not written to the cache metadata or the run warnings
+func redact(url string) string {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = ""
+	return u.String()
+}
+
+// recordStale records a cached response served in place of a failed fetch
+func recordStale(url string, fetchedAt time.Time, err error) {
+	url = redact(url)
+	staleMu.Lock()
+	defer staleMu.Unlock()
+	stale[url] = Stale{URL: url, FetchedAt: fetchedAt, Err: err.Error()}
+}
+
+// fetch performs the HTTP request, revalidating against cached when present.
+// Successful responses are written to the cache unless it is disabled.
+func fetch(url string, cached *entry) ([]byte, bool, error) {
+	body, header, notModified, err := request(url, cached)
+	if err != nil || notModified {
+		return nil, notModified, err
+	}
+
+	cfg := CurrentConfig()
+	if !cfg.Disabled {
+		bodyPath, metaPath := paths(cfg.Dir, url)
+		save(bodyPath, metaPath, &entry{
+			URL:          redact(url),
+			ETag:         header.Get("ETag"),
+			LastModified: header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+		}, body)
+	}
+
+	return body, false, nil
+}
+
+// request performs the HTTP request, conditional on cached when present,
+// and returns the body and headers of the response, or whether the server
+// answered that cached is not modified
+func request(url string, cached *entry) ([]byte, http.Header, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("invalid request: %w", err)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	// Cache hits never get here, so only network requests count against the limit
+	ratelimit.For(req.URL.Host).Wait()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return nil, nil, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, false, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.Header, false, nil
+}
+
+// paths returns the body and metadata file paths for a URL
+func paths(dir, url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(dir, key+".body"), filepath.Join(dir, key+".json")
+}
+
+// load reads a cache entry, returning nil if it is missing or unreadable
+func load(bodyPath, metaPath string) (*entry, []byte) {
+	metaData, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+	var meta entry
+	if err := json.Unmarshal(metaData, &meta); err != nil {
+		return nil, nil
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil
+	}
+	return &meta, body
+}
+
+// save writes a cache entry; a nil body only refreshes the metadata.
+// Failures are reported but never break the request.
+func save(bodyPath, metaPath string, meta *entry, body []byte) {
+	// entries written before URLs were redacted lose their query when rewritten
+	meta.URL = redact(meta.URL)
+	if err := os.MkdirAll(filepath.Dir(bodyPath), 0755); err != nil {
+		fmt.Printf("Warning: failed to create cache directory: %v\n", err)
+		return
+	}
+
+	if body != nil {
+		if err := writeAtomic(bodyPath, body); err != nil {
+			fmt.Printf("Warning: failed to write cache entry: %v\n", err)
+			return
+		}
+	}
+
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return
+	}
+	if err := writeAtomic(metaPath, metaData); err != nil {
+		fmt.Printf("Warning: failed to write cache metadata: %v\n", err)
+	}
+}
+
+// writeAtomic writes data to a temporary file and renames it into place
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Stats summarizes the entries of a cache directory
+type Stats struct {
+	Entries int   // entries with readable metadata and body
+	Corrupt int   // entries whose metadata does not decode or whose body is missing
+	Bytes   int64 // size of the files of every entry
+}
+
+// Verify checks every entry in dir. A missing directory is an empty cache.
+func Verify(dir string) (Stats, error) {
+	var stats Stats
+	if _, err := os.ReadDir(dir); err != nil && !os.IsNotExist(err) {
+		return stats, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	metas, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return stats, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+	for _, metaPath := range metas {
+		bodyPath := strings.TrimSuffix(metaPath, ".json") + ".body"
+		for _, file := range []string{metaPath, bodyPath} {
+			if info, err := os.Stat(file); err == nil {
+				stats.Bytes += info.Size()
+			}
+		}
+		if meta, _ := load(bodyPath, metaPath); meta == nil {
+			stats.Corrupt++
+		} else {
+			stats.Entries++
+		}
+	}
+	return stats, nil
+}
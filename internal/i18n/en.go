@@ -0,0 +1,269 @@
+package i18n
+
+// english is the built-in catalog and the reference of every message key
+var english = map[string]string{
+	// HTML report
+	"report.page_title":         "Bitcoin Analysis Report",
+	"report.title":              "Bitcoin Market Analysis Report",
+	"report.header.symbol":      "Symbol: %s | Generated: %s",
+	"report.header.data":        "Data Points: %d | Time Range: %s",
+	"report.header.analyzer":    "Analyzer: %s | Computed in %.2fs",
+	"report.header.cached":      " (cached result)",
+	"report.header.warmup":      "Indicator warm-up (%s): %d bars before the period",
+	"report.header.truncated":   " and %d loaded bars left out of it",
+	"report.header.warmup_none": "Indicator warm-up: none, indicators are undefined on the first %d bars",
+	"report.time_range":         "%s to %s",
+	"report.errors":             "Errors",
+	"report.errors.note":        "These stages failed; their outputs are missing or incomplete.",
+	"report.warnings":           "Warnings",
+	"report.summary":            "Summary",
+	"report.performance":        "Performance",
+	"report.performance.note":   "Change of the latest close over each horizon",
+	"report.all_time":           "All-Time High / Low",
+	"report.price_info":         "Current Price Information",
+	"report.latest_price":       "Latest Price: %s",
+	"report.latest_volume":      "Latest Volume: %s",
+	"report.price_stats":        "Price Statistics",
+	"report.mean":               "Mean: %s",
+	"report.median":             "Median: %s",
+	"report.min":                "Min: %s",
+	"report.max":                "Max: %s",
+	"report.std_dev":            "Std Dev: %s",
+	"report.risk":               "Risk Metrics",
+	"report.volatility":         "Volatility: %s",
+	"report.sharpe":             "Sharpe Ratio: %.3f",
+	"report.max_drawdown":       "Max Drawdown: %s",
+	"report.vol_estimators":     "Volatility Estimators",
+	"report.vol_estimator":      "%s: %s",
+	"report.in_use":             " (in use)",
+	"report.no_ranges":          "Range-based estimators are unavailable: the series has no intrabar high/low ranges.",
+	"report.percentiles":        "Percentile Ranks (trailing year)",
+	"report.valuation":          "Long-term Valuation",
+	"report.tail_risk":          "Tail Risk",
+	"report.diagnostics":        "Diagnostics",
+	"report.mean_reversion":     "Mean Reversion",
+	"report.attribution":        "Return Attribution",
+	"report.liquidity":          "Liquidity (OHLCV Estimates)",
+	"report.session_gaps":       "Session Gaps",
+	"report.signals":            "Trading Signals",
+	"report.signals.caption":    "Trading signal of each indicator",
+	"report.signals.thresholds": "Thresholds: %s",
+	"report.indicator":          "Indicator",
+	"report.signal":             "Signal",
+	"report.technical":          "Technical Indicators",
+	"report.rsi":                "RSI (14): %s",
+	"report.macd":               "MACD: %s",
+	"report.roc":                "ROC (12): %s",
+	"report.skipped":            "Skipped Analyses",
+	"report.skipped.item":       "%s: insufficient data (needs %d bars, have %d)",
+	"report.text":               "Full Text Report",
+	"report.text.label":         "Plain-text version of the report",
+	"report.glossary":           "Glossary",
+	"report.explain.computed":   "How it is computed:",
+	"report.explain.parameters": "Parameters:",
+
+	// Shared
+	"common.not_available": "n/a",
+	"col.open":             "Open",
+	"col.high":             "High",
+	"col.low":              "Low",
+	"col.close":            "Close",
+	"col.volume":           "Volume",
+	"col.date":             "Date",
+	"month.jan":            "Jan",
+	"month.feb":            "Feb",
+	"month.mar":            "Mar",
+	"month.apr":            "Apr",
+	"month.may":            "May",
+	"month.jun":            "Jun",
+	"month.jul":            "Jul",
+	"month.aug":            "Aug",
+	"month.sep":            "Sep",
+	"month.oct":            "Oct",
+	"month.nov":            "Nov",
+	"month.dec":            "Dec",
+
+	// Weekly report
+	"weekly.page_title":     "Weekly Bitcoin Report",
+	"weekly.title":          "%s Weekly Report",
+	"weekly.header":         "Week of %s to %s",
+	"weekly.in_progress":    " (in progress)",
+	"weekly.generated":      "Generated: %s",
+	"weekly.close":          "Close: %s",
+	"weekly.change":         "Week over week:",
+	"weekly.volume":         "Volume:",
+	"weekly.signal_score":   "Signal score: %+.0f",
+	"weekly.candle":         "Weekly Candle",
+	"weekly.col.week":       "Week",
+	"weekly.col.bars":       "Bars",
+	"weekly.prior":          "Prior (%s)",
+	"weekly.this":           "This (%s)",
+	"weekly.events":         "What Fired This Week",
+	"weekly.no_events":      "No patterns or signals fired.",
+	"weekly.deltas":         "Week-over-Week Deltas",
+	"weekly.col.metric":     "Metric",
+	"weekly.col.prior":      "Prior week",
+	"weekly.col.this":       "This week",
+	"weekly.col.change":     "Change",
+	"weekly.signal_changes": "Signal Changes",
+	"weekly.signals":        "Signals at Week End",
+
+	// Risk dashboard
+	"dashboard.title":           "Bitcoin Risk Dashboard",
+	"dashboard.subtitle":        "%s · %s · updated %s",
+	"dashboard.vol":             "Volatility Percentile",
+	"dashboard.vol.note":        "30-day realized vs history",
+	"dashboard.vol.current":     "30-day vol %s",
+	"dashboard.rsi":             "RSI (14)",
+	"dashboard.rsi.note":        "overbought > 70, oversold < 30",
+	"dashboard.drawdown":        "Drawdown",
+	"dashboard.drawdown.note":   "from the running peak",
+	"dashboard.drawdown.max":    "max %s",
+	"dashboard.var":             "VaR 95% (1 day)",
+	"dashboard.var.note":        "parametric",
+	"dashboard.var.loss":        "expected worst daily loss",
+	"dashboard.premium":         "Premium / Funding",
+	"dashboard.premium.note":    "load a reference index with -reference",
+	"dashboard.premium.current": "%s vs %s",
+	"dashboard.signal":          "Composite Signal",
+	"dashboard.signal.note":     "%d signals, -100 all SELL to +100 all BUY",
+
+	// Backtest report
+	"backtest.title":                "Backtest Report",
+	"backtest.header":               "Symbol: %s | %s to %s (%d bars)",
+	"backtest.costs":                "Costs per trade: %.1f bps fees, %.1f bps slippage; all results are net of costs",
+	"backtest.run":                  "Run %s",
+	"backtest.mean_reversion":       "Mean Reversion",
+	"backtest.total_return":         "Total return: %s",
+	"backtest.cagr":                 "CAGR: %s",
+	"backtest.annualized":           "Annualized mean return: %s",
+	"backtest.sharpe":               "Sharpe: %.3f",
+	"backtest.max_drawdown":         "Max drawdown: %s",
+	"backtest.trades":               "Trades: %d",
+	"backtest.win_rate":             "Win rate: %s",
+	"backtest.exposure":             "Exposure: %s",
+	"backtest.monthly":              "Monthly Returns",
+	"backtest.monthly.caption":      "Net return of %s by calendar month",
+	"backtest.col.year":             "Year",
+	"backtest.chart.equity":         "Equity Curve",
+	"backtest.chart.drawdown":       "Drawdown",
+	"backtest.chart.rolling_sharpe": "Rolling Sharpe Ratio (%d bars)",
+
+	// Embeddable widgets
+	"widget.signal":          "Signal score",
+	"widget.signal.label":    "%s signal score %+.0f, %s",
+	"widget.signal.counts":   "%d buy · %d sell · %d hold",
+	"widget.lean.bullish":    "bullish",
+	"widget.lean.bearish":    "bearish",
+	"widget.lean.neutral":    "neutral",
+	"widget.sparkline":       "Closes of the last 30 days",
+	"widget.rsi":             "RSI %.1f",
+	"widget.zone.overbought": "overbought",
+	"widget.zone.oversold":   "oversold",
+	"widget.zone.neutral":    "neutral",
+	"widget.levels":          "Levels around %s",
+	"widget.col.level":       "Level",
+	"widget.col.price":       "Price",
+	"widget.col.distance":    "Distance",
+	"widget.kind.support":    "support",
+	"widget.kind.resistance": "resistance",
+	"widget.no_levels":       "No levels found",
+
+	// Multi-symbol overview
+	"overview.title":           "Market Overview",
+	"overview.subtitle":        "%d assets · updated %s",
+	"overview.caption":         "Latest price, changes, RSI, trend and signal score of each asset",
+	"overview.col.asset":       "Asset",
+	"overview.col.price":       "Price",
+	"overview.col.24h":         "24h",
+	"overview.col.7d":          "7d",
+	"overview.col.rsi":         "RSI (14)",
+	"overview.col.trend":       "Trend",
+	"overview.col.score":       "Signal Score",
+	"overview.col.as_of":       "Latest Bar",
+	"overview.trend.uptrend":   "Uptrend",
+	"overview.trend.downtrend": "Downtrend",
+	"overview.trend.sideways":  "Sideways",
+	"overview.pending":         "Not analyzed yet",
+	"overview.note":            "The signal score runs from -100 when every signal sells to +100 when every signal buys. Each asset links to its detailed report.",
+
+	// Technical analysis page
+	"tech.page_title":     "Bitcoin Technical Indicators Analysis",
+	"tech.title":          "Bitcoin Technical Analysis",
+	"tech.subtitle":       "RSI & MACD Indicators with Raw Data",
+	"tech.data_points":    "Data Points",
+	"tech.avg_price":      "Average Price",
+	"tech.volatility":     "Volatility",
+	"tech.current_rsi":    "Current RSI",
+	"tech.performance":    "Performance",
+	"tech.chart":          "Technical Indicators Chart",
+	"tech.price_data":     "Price Data (Last %d Records)",
+	"tech.rsi_values":     "RSI Values (Last %d Records)",
+	"tech.total_rsi":      "Total RSI Points",
+	"tech.avg_rsi":        "Average RSI",
+	"tech.col.index":      "Index",
+	"tech.col.rsi":        "RSI Value",
+	"tech.col.status":     "Status",
+	"tech.neutral":        "Neutral",
+	"tech.oversold":       "Oversold",
+	"tech.overbought":     "Overbought",
+	"tech.macd_values":    "MACD Values (Last %d Records)",
+	"tech.current_macd":   "Current MACD",
+	"tech.current_signal": "Current Signal",
+	"tech.total_macd":     "Total MACD Points",
+	"tech.col.macd":       "MACD",
+	"tech.col.signal":     "Signal",
+	"tech.col.histogram":  "Histogram",
+	"tech.col.trend":      "Trend",
+	"tech.bullish":        "Bullish",
+	"tech.bearish":        "Bearish",
+	"tech.status":         "Current Indicator Status",
+	"tech.rsi_status":     "RSI (%.1f):",
+	"tech.rsi.oversold":   "Oversold (Buy Signal)",
+	"tech.rsi.overbought": "Overbought (Sell Signal)",
+	"tech.macd_status":    "MACD:",
+	"tech.macd.bullish":   "Bullish Trend",
+	"tech.macd.bearish":   "Bearish Trend",
+
+	// Scheduled email
+	"email.subject.daily":  "%s daily report %s",
+	"email.subject.weekly": "%s weekly report %s",
+
+	// Console summary
+	"summary.title":         "=== BITCOIN ANALYSIS SUMMARY ===",
+	"summary.latest_price":  "Latest Price: %s",
+	"summary.data_points":   "Data Points: %d",
+	"summary.mean_price":    "Mean Price: %s",
+	"summary.price_range":   "Price Range: %s - %s",
+	"summary.volatility":    "Volatility: %s",
+	"summary.volatility_ci": "Volatility: %s (%.0f%% CI %s to %s)",
+	"summary.sharpe":        "Sharpe Ratio: %.3f",
+	"summary.sharpe_ci":     "Sharpe Ratio: %.3f (%.0f%% CI %.3f to %.3f)",
+	"summary.rsi":           "Latest RSI: %.2f",
+	"summary.skipped":       "Skipped %d analyses for insufficient data (%d bars)",
+	"summary.signals":       "=== KEY SIGNALS ===",
+	"summary.signal":        "%s: %s",
+	"summary.thresholds":    "Thresholds: %s",
+	"summary.end":           "================================",
+
+	// Chart descriptions
+	"alt.chart":           "%s chart",
+	"alt.period":          "%s of %s from %s to %s",
+	"alt.price_change":    "price %s, %s over the period",
+	"alt.rsi":             "RSI currently %.0f, %s",
+	"alt.trend.short":     "with too little history for a trend",
+	"alt.trend.up":        "trending up",
+	"alt.trend.down":      "trending down",
+	"alt.trend.flat":      "flat",
+	"alt.macd.above":      "MACD above its signal line",
+	"alt.macd.below":      "MACD below its signal line",
+	"alt.levels":          "price %s with %d support and %d resistance levels",
+	"alt.elliott":         "%d swings of at least %.0f%% and %d candidate wave counts",
+	"alt.harmonics":       "%d harmonic patterns",
+	"alt.heat":            "heat currently %+.2f, %s",
+	"alt.stock_to_flow":   "price %s against a model price of %s",
+	"alt.equity":          "Equity of %s from 100 to %.1f over %d bars",
+	"alt.equity.baseline": ", against %.1f for buy and hold",
+	"alt.drawdown":        "Drawdown of %s from its running peak, deepest %s, currently %s",
+	"alt.rolling_sharpe":  "Sharpe ratio of %s over rolling %d-bar windows, between %.2f and %.2f, currently %.2f",
+}
@@ -0,0 +1,134 @@
+// Package i18n is the message catalog of the user-facing strings of the
+// reports: titles, headings, labels, table headers and notes. English is
+// built in; other locales are registered from Go or loaded from JSON files,
+// and fall back to English for messages they do not translate.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// DefaultLocale is the built-in locale every other one falls back to
+const DefaultLocale = "en"
+
+// Catalog holds the messages of one locale by key. Messages are fmt formats;
+// translations may reorder their arguments with explicit indexes, e.g. %[2]s.
+type Catalog struct {
+	Locale   string            `json:"locale"`
+	Messages map[string]string `json:"messages"`
+}
+
+var catalogs = map[string]*Catalog{DefaultLocale: {Locale: DefaultLocale, Messages: english}}
+
+// English returns the built-in English catalog
+func English() *Catalog {
+	return catalogs[DefaultLocale]
+}
+
+// Register makes c available by its locale, replacing an earlier catalog of
+// the same locale. Keys unknown to the English catalog are rejected, as they
+// are usually misspelled.
+func Register(c *Catalog) error {
+	if c.Locale == "" {
+		return fmt.Errorf("catalog has no locale")
+	}
+	if c.Locale == DefaultLocale {
+		return fmt.Errorf("the %s catalog is built in", DefaultLocale)
+	}
+	var unknown []string
+	for key := range c.Messages {
+		if _, ok := english[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("catalog %s has unknown messages: %v", c.Locale, unknown)
+	}
+	catalogs[c.Locale] = c
+	return nil
+}
+
+// Load reads a catalog from a JSON file of the form
+// {"locale": "de", "messages": {"report.title": "..."}} and registers it
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message catalog: %w", err)
+	}
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse message catalog %s: %w", path, err)
+	}
+	if err := Register(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Lookup returns the registered catalog of locale, English for ""
+func Lookup(locale string) (*Catalog, error) {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	c, ok := catalogs[locale]
+	if !ok {
+		return nil, fmt.Errorf("unknown locale %q (available: %v)", locale, Locales())
+	}
+	return c, nil
+}
+
+// Locales lists the registered locales
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// T formats the message key with args. A nil catalog is English; messages
+// missing from c are taken from English, and unknown keys are returned as
+// they are.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	format, ok := "", false
+	if c != nil {
+		format, ok = c.Messages[key]
+	}
+	if !ok {
+		if format, ok = english[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Lang returns the locale of c for the lang attribute of HTML pages
+func (c *Catalog) Lang() string {
+	if c == nil {
+		return DefaultLocale
+	}
+	return c.Locale
+}
+
+// Missing lists the English messages c does not translate
+func (c *Catalog) Missing() []string {
+	if c == nil {
+		return nil
+	}
+	var missing []string
+	for key := range english {
+		if _, ok := c.Messages[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,70 @@
+package indicators
+
+import (
+	"btc-analyzer/internal/graph"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+)
+
+// Nodes of the indicator graph built by NewGraph
+const (
+	NodeClose     = "close"     // []float64 closing prices
+	NodeEMA12     = "ema12"     // []float64
+	NodeEMA26     = "ema26"     // []float64
+	NodeSMA20     = "sma20"     // []float64
+	NodeRSI       = "rsi"       // []float64, RSI 14
+	NodeMACD      = "macd"      // types.MACDData, MACD 12/26/9 from ema12 and ema26
+	NodeBollinger = "bollinger" // types.BollingerBandsData, 20 bars and 2 std dev around sma20
+	NodeROC       = "roc"       // []float64, 12-bar rate of change as a fraction
+)
+
+// NewGraph returns the analyzer's indicators on bts as a dependency graph,
+// so shared intermediates such as the closing prices and moving averages are
+// computed once and only the requested indicators run
+func NewGraph(bts *types.BTCTimeSeries) *graph.Graph {
+	g := graph.New()
+	add := func(name string, deps []string, compute graph.Func) {
+		if err := g.Add(name, deps, compute); err != nil {
+			panic(err)
+		}
+	}
+	series := func(deps map[string]interface{}, name string) []float64 {
+		return deps[name].([]float64)
+	}
+
+	add(NodeClose, nil, func(map[string]interface{}) (interface{}, error) {
+		return timeseries.GetClosePrices(bts), nil
+	})
+	add(NodeEMA12, []string{NodeClose}, func(deps map[string]interface{}) (interface{}, error) {
+		return calculateEMA(series(deps, NodeClose), 12), nil
+	})
+	add(NodeEMA26, []string{NodeClose}, func(deps map[string]interface{}) (interface{}, error) {
+		return calculateEMA(series(deps, NodeClose), 26), nil
+	})
+	add(NodeSMA20, []string{NodeClose}, func(deps map[string]interface{}) (interface{}, error) {
+		return calculateSMA(series(deps, NodeClose), 20), nil
+	})
+	add(NodeRSI, []string{NodeClose}, func(deps map[string]interface{}) (interface{}, error) {
+		return calculateRSI(series(deps, NodeClose), 14), nil
+	})
+	add(NodeMACD, []string{NodeEMA12, NodeEMA26}, func(deps map[string]interface{}) (interface{}, error) {
+		return macdFromEMAs(series(deps, NodeEMA12), series(deps, NodeEMA26), 9), nil
+	})
+	add(NodeBollinger, []string{NodeClose, NodeSMA20}, func(deps map[string]interface{}) (interface{}, error) {
+		return bollingerFromSMA(series(deps, NodeClose), series(deps, NodeSMA20), 20, 2.0), nil
+	})
+	add(NodeROC, []string{NodeClose}, func(deps map[string]interface{}) (interface{}, error) {
+		return calculateROC(series(deps, NodeClose), 12), nil
+	})
+	return g
+}
+
+// Compute evaluates the named nodes of the indicator graph on bts and their
+// dependencies, nothing else
+func Compute(bts *types.BTCTimeSeries, names ...string) (map[string]interface{}, error) {
+	evaluation, err := NewGraph(bts).Evaluate(names...)
+	if err != nil {
+		return nil, err
+	}
+	return evaluation.GetAll(names...)
+}
@@ -0,0 +1,167 @@
+package indicators
+
+import (
+	"math"
+)
+
+// EMAState computes an exponential moving average one value at a time. It is
+// seeded with the SMA of the first period values, matching calculateEMA.
+type EMAState struct {
+	period     int
+	multiplier float64
+	count      int
+	sum        float64
+	value      float64
+}
+
+// NewEMAState creates an incremental EMA
+func NewEMAState(period int) *EMAState {
+	return &EMAState{
+		period:     period,
+		multiplier: 2.0 / (float64(period) + 1.0),
+	}
+}
+
+// Update adds a value and returns the EMA once period values have been seen
+func (e *EMAState) Update(value float64) (float64, bool) {
+	e.count++
+	if e.count < e.period {
+		e.sum += value
+		return 0, false
+	}
+	if e.count == e.period {
+		e.value = (e.sum + value) / float64(e.period)
+		return e.value, true
+	}
+	e.value = value*e.multiplier + e.value*(1-e.multiplier)
+	return e.value, true
+}
+
+// RSIState computes Wilder's RSI one price at a time, matching CalculateRSI
+type RSIState struct {
+	period    int
+	prev      float64
+	changes   int
+	avgGain   float64
+	avgLoss   float64
+	havePrice bool
+}
+
+// NewRSIState creates an incremental RSI
+func NewRSIState(period int) *RSIState {
+	return &RSIState{period: period}
+}
+
+// Update adds a closing price and returns the RSI once it is defined
+func (r *RSIState) Update(price float64) (float64, bool) {
+	if !r.havePrice {
+		r.prev = price
+		r.havePrice = true
+		return 0, false
+	}
+
+	change := price - r.prev
+	r.prev = price
+	r.changes++
+
+	gain, loss := 0.0, 0.0
+	if change > 0 {
+		gain = change
+	} else {
+		loss = math.Abs(change)
+	}
+
+	// The first period changes only seed the averages
+	if r.changes <= r.period {
+		r.avgGain += gain / float64(r.period)
+		r.avgLoss += loss / float64(r.period)
+		return 0, false
+	}
+
+	r.avgGain = (r.avgGain*float64(r.period-1) + gain) / float64(r.period)
+	r.avgLoss = (r.avgLoss*float64(r.period-1) + loss) / float64(r.period)
+
+	if r.avgLoss == 0 {
+		return 100, true
+	}
+	return 100 - (100 / (1 + r.avgGain/r.avgLoss)), true
+}
+
+// MACDState computes MACD, signal and histogram one price at a time
+type MACDState struct {
+	fast   *EMAState
+	slow   *EMAState
+	signal *EMAState
+}
+
+// NewMACDState creates an incremental MACD
+func NewMACDState(fastPeriod, slowPeriod, signalPeriod int) *MACDState {
+	return &MACDState{
+		fast:   NewEMAState(fastPeriod),
+		slow:   NewEMAState(slowPeriod),
+		signal: NewEMAState(signalPeriod),
+	}
+}
+
+// Update adds a closing price and returns MACD, signal and histogram once the
+// signal line is defined. macdReady reports whether the MACD line itself is.
+func (m *MACDState) Update(price float64) (macd, signal, histogram float64, macdReady, signalReady bool) {
+	fast, fastOK := m.fast.Update(price)
+	slow, slowOK := m.slow.Update(price)
+	if !fastOK || !slowOK {
+		return 0, 0, 0, false, false
+	}
+
+	macd = fast - slow
+	signal, signalReady = m.signal.Update(macd)
+	if signalReady {
+		histogram = macd - signal
+	}
+	return macd, signal, histogram, true, signalReady
+}
+
+// BollingerState computes Bollinger Bands over a sliding window
+type BollingerState struct {
+	period       int
+	stdDevFactor float64
+	window       []float64
+	next         int
+	filled       bool
+}
+
+// NewBollingerState creates incremental Bollinger Bands
+func NewBollingerState(period int, stdDevFactor float64) *BollingerState {
+	return &BollingerState{
+		period:       period,
+		stdDevFactor: stdDevFactor,
+		window:       make([]float64, period),
+	}
+}
+
+// Update adds a closing price and returns the bands once the window is full
+func (b *BollingerState) Update(price float64) (upper, middle, lower float64, ok bool) {
+	b.window[b.next] = price
+	b.next = (b.next + 1) % b.period
+	if b.next == 0 {
+		b.filled = true
+	}
+	if !b.filled {
+		return 0, 0, 0, false
+	}
+
+	// Recomputed over the window (O(period)) to avoid running-sum drift
+	sum := 0.0
+	for _, v := range b.window {
+		sum += v
+	}
+	middle = sum / float64(b.period)
+
+	sumSquaredDiff := 0.0
+	for _, v := range b.window {
+		diff := v - middle
+		sumSquaredDiff += diff * diff
+	}
+	stdDev := math.Sqrt(sumSquaredDiff / float64(b.period))
+
+	return middle + b.stdDevFactor*stdDev, middle, middle - b.stdDevFactor*stdDev, true
+}
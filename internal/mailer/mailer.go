@@ -0,0 +1,152 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Config holds the SMTP server and envelope settings
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// InlineImage is an image embedded in the HTML body and referenced from it as cid:<ContentID>
+type InlineImage struct {
+	ContentID string
+	Filename  string
+	Data      []byte
+}
+
+// Message is an HTML email with inline images
+type Message struct {
+	Subject string
+	HTML    string
+	Images  []InlineImage
+}
+
+// Validate checks that cfg can be used to send mail
+func (cfg Config) Validate() error {
+	if cfg.Host == "" {
+		return fmt.Errorf("SMTP host is required")
+	}
+	if cfg.From == "" {
+		return fmt.Errorf("sender address is required")
+	}
+	if len(cfg.To) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	return nil
+}
+
+// Build encodes msg as a multipart/related MIME message from cfg.From to cfg.To
+func Build(cfg Config, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/related; boundary=%s\r\n\r\n", writer.Boundary())
+
+	htmlPart, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTML part: %w", err)
+	}
+	if err := writeBase64(htmlPart, []byte(msg.HTML)); err != nil {
+		return nil, err
+	}
+
+	for _, image := range msg.Images {
+		contentType := mime.TypeByExtension(filepath.Ext(image.Filename))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + image.ContentID + ">"},
+			"Content-Disposition":       {fmt.Sprintf("inline; filename=%q", image.Filename)},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create image part: %w", err)
+		}
+		if err := writeBase64(part, image.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Send delivers a built message through the SMTP server of cfg. PLAIN
+// authentication is used when a username is set; net/smtp upgrades to TLS
+// with STARTTLS when the server offers it.
+func Send(cfg Config, message []byte) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	if err := smtp.SendMail(addr, auth, cfg.From, cfg.To, message); err != nil {
+		return fmt.Errorf("failed to send mail via %s: %w", addr, err)
+	}
+	return nil
+}
+
+// SendWithRetry calls Send up to attempts times, doubling delay after each failure
+func SendWithRetry(cfg Config, message []byte, attempts int, delay time.Duration) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = Send(cfg, message); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			fmt.Printf("⚠️  Send attempt %d/%d failed: %v (retrying in %s)\n", attempt, attempts, err, delay)
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return fmt.Errorf("failed to write message part: %w", err)
+		}
+		encoded = encoded[76:]
+	}
+	if _, err := fmt.Fprintf(w, "%s\r\n", encoded); err != nil {
+		return fmt.Errorf("failed to write message part: %w", err)
+	}
+	return nil
+}
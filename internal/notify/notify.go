@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Urgency levels of an alert rule
+const (
+	UrgencyHigh   = "high"   // notified as soon as the rule triggers
+	UrgencyNormal = "normal" // collected into the digest
+)
+
+// sendTimeout bounds the delivery of one message
+const sendTimeout = 30 * time.Second
+
+// Sender delivers a text message
+type Sender interface {
+	Send(ctx context.Context, text string) error
+}
+
+// ValidUrgency reports whether urgency is a known level; empty means normal
+func ValidUrgency(urgency string) bool {
+	return urgency == "" || urgency == UrgencyHigh || urgency == UrgencyNormal
+}
+
+// Notifier delivers the triggered alerts of watchlists. High-urgency alerts
+// are sent at once; with a digest, the others are collected and summarized
+// in one message by Flush, otherwise they are sent at once too.
+type Notifier struct {
+	sender Sender
+	digest bool
+
+	mu      sync.Mutex
+	since   time.Time
+	pending map[string]*digestEntry
+	order   []string
+}
+
+// digestEntry summarizes the triggers of one rule since the last digest
+type digestEntry struct {
+	watchlist   types.Watchlist
+	alert       types.PriceAlert
+	count       int
+	first, last time.Time
+	low, high   float64
+	firstClose  float64
+	lastClose   float64
+}
+
+// New returns a notifier sending through sender, collecting normal-urgency
+// alerts into a digest when digest is true
+func New(sender Sender, digest bool) *Notifier {
+	return &Notifier{sender: sender, digest: digest, since: time.Now(), pending: make(map[string]*digestEntry)}
+}
+
+// Notify handles the alerts of w triggered by a bar closing at close. It
+// returns the error of an immediate send; digested alerts cannot fail here.
+func (n *Notifier) Notify(w types.Watchlist, triggered []types.PriceAlert, close float64) error {
+	var immediate []string
+	for _, alert := range triggered {
+		if alert.Urgency == UrgencyHigh || !n.digest {
+			immediate = append(immediate, formatAlert(w, alert, close))
+			continue
+		}
+		n.collect(w, alert, close)
+	}
+	if len(immediate) == 0 {
+		return nil
+	}
+	return n.send("🔔 " + strings.Join(immediate, "\n🔔 "))
+}
+
+// collect adds one triggered alert to the pending digest
+func (n *Notifier) collect(w types.Watchlist, alert types.PriceAlert, close float64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%g", w.ID, alert.Type, alert.Threshold)
+	entry, ok := n.pending[key]
+	if !ok {
+		entry = &digestEntry{watchlist: w, alert: alert, first: alert.Timestamp, low: close, high: close, firstClose: close}
+		n.pending[key] = entry
+		n.order = append(n.order, key)
+	}
+	entry.count++
+	entry.last = alert.Timestamp
+	entry.lastClose = close
+	if close < entry.low {
+		entry.low = close
+	}
+	if close > entry.high {
+		entry.high = close
+	}
+}
+
+// Pending returns how many rules triggered since the last digest
+func (n *Notifier) Pending() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.order)
+}
+
+// Flush sends the digest of the alerts collected since the previous one and
+// starts a new period. Nothing is sent when no alert triggered; a digest that
+// fails to send is kept and merged into the next one.
+func (n *Notifier) Flush() error {
+	n.mu.Lock()
+	if len(n.order) == 0 {
+		n.since = time.Now()
+		n.mu.Unlock()
+		return nil
+	}
+	since, pending, order := n.since, n.pending, n.order
+	n.since, n.pending, n.order = time.Now(), make(map[string]*digestEntry), nil
+	n.mu.Unlock()
+
+	err := n.send(formatDigest(since, time.Now(), pending, order))
+	if err != nil {
+		n.restore(since, pending, order)
+	}
+	return err
+}
+
+// restore merges an unsent digest back into the pending one
+func (n *Notifier) restore(since time.Time, pending map[string]*digestEntry, order []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.since = since
+	for _, key := range n.order {
+		newer := n.pending[key]
+		older, ok := pending[key]
+		if !ok {
+			pending[key] = newer
+			order = append(order, key)
+			continue
+		}
+		older.count += newer.count
+		older.last, older.lastClose = newer.last, newer.lastClose
+		older.low = min(older.low, newer.low)
+		older.high = max(older.high, newer.high)
+	}
+	n.pending, n.order = pending, order
+}
+
+// send delivers text, cut to the Telegram message limit
+func (n *Notifier) send(text string) error {
+	if len(text) > MaxMessageLength {
+		text = truncate(text, MaxMessageLength)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	return n.sender.Send(ctx, text)
+}
+
+// formatAlert describes one triggered alert on one line
+func formatAlert(w types.Watchlist, alert types.PriceAlert, close float64) string {
+	return fmt.Sprintf("%s %s: %s, close %.2f (watchlist %s)", w.Symbol, w.Timeframe, describeRule(alert), close, w.ID)
+}
+
+// describeRule describes the condition of an alert rule
+func describeRule(alert types.PriceAlert) string {
+	if alert.Type == "change" {
+		return fmt.Sprintf("moved ≥ %g%%", alert.Threshold)
+	}
+	return fmt.Sprintf("%s %g", alert.Type, alert.Threshold)
+}
+
+// formatDigest summarizes the pending rules, in the order they first
+// triggered, with their trigger count, time span and close range
+func formatDigest(since, until time.Time, pending map[string]*digestEntry, order []string) string {
+	triggers := 0
+	for _, key := range order {
+		triggers += pending[key].count
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🗞️ Alert digest %s – %s: %d rules triggered %d times\n",
+		since.Format("Jan 2 15:04"), until.Format("Jan 2 15:04 MST"), len(order), triggers)
+	for _, key := range order {
+		entry := pending[key]
+		change := 0.0
+		if entry.firstClose > 0 {
+			change = entry.lastClose/entry.firstClose - 1
+		}
+		fmt.Fprintf(&b, "\n• %s %s: %s\n  %d× %s – %s, close %s–%s, last %s (%s)",
+			entry.watchlist.Symbol, entry.watchlist.Timeframe, describeRule(entry.alert),
+			entry.count, entry.first.Format("Jan 2 15:04"), entry.last.Format("Jan 2 15:04"),
+			numfmt.Format(numfmt.USD, entry.low), numfmt.Format(numfmt.USD, entry.high), numfmt.Format(numfmt.USD, entry.lastClose), numfmt.SignedPct(change))
+	}
+	return b.String()
+}
+
+// truncate cuts text at a line boundary so that it fits in limit bytes with
+// a note about the omitted lines
+func truncate(text string, limit int) string {
+	lines := strings.Split(text, "\n")
+	kept := 0
+	size := 0
+	for kept < len(lines) && size+len(lines[kept])+1 <= limit-40 {
+		size += len(lines[kept]) + 1
+		kept++
+	}
+	return strings.Join(lines[:kept], "\n") + fmt.Sprintf("\n… %d more lines", len(lines)-kept)
+}
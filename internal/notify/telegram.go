@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TelegramTokenEnv names the environment variable holding the Telegram bot
+// token, kept out of flags and the config file
+const TelegramTokenEnv = "BTC_ANALYZER_TELEGRAM_TOKEN"
+
+// telegramAPI is the Telegram Bot API endpoint
+const telegramAPI = "https://api.telegram.org"
+
+// MaxMessageLength is the longest text Telegram accepts in one message
+const MaxMessageLength = 4096
+
+// Telegram sends messages to one chat through a Telegram bot
+type Telegram struct {
+	token, chatID string
+	baseURL       string
+	client        *http.Client
+}
+
+// NewTelegram returns a sender posting to chatID as the bot with token
+func NewTelegram(token, chatID string) (*Telegram, error) {
+	if token == "" {
+		return nil, fmt.Errorf("Telegram bot token missing: set %s", TelegramTokenEnv)
+	}
+	if chatID == "" {
+		return nil, fmt.Errorf("Telegram chat ID is required")
+	}
+	return &Telegram{token: token, chatID: chatID, baseURL: telegramAPI, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+// Send posts text as a plain-text message
+func (t *Telegram) Send(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  t.chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode Telegram message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/bot"+t.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The request URL embeds the token; report the failure without it
+		return fmt.Errorf("failed to reach Telegram: %w", stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(data, &result); err != nil || !result.OK {
+		if result.Description == "" {
+			result.Description = resp.Status
+		}
+		return fmt.Errorf("Telegram rejected the message: %s", result.Description)
+	}
+	return nil
+}
+
+// stripURL unwraps the *url.Error of a failed request, whose message
+// contains the request URL
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,215 @@
+// Package numfmt formats money and percentages under one precision policy,
+// so a value reads the same in the console, HTML and text reports and the
+// exported series: its decimals follow its kind and magnitude, and it is
+// rounded half away from zero. The JSON report, bundles and store exports
+// keep full precision, as they are read back for further analysis.
+package numfmt
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// Kinds of formatted values
+const (
+	USD     = "usd"     // fiat amounts
+	Sats    = "sats"    // amounts in sats
+	BTC     = "btc"     // amounts in BTC
+	Percent = "percent" // percentages
+)
+
+// Kinds are the kinds of values a precision config sets
+var Kinds = []string{USD, Sats, BTC, Percent}
+
+// maxDigits bounds the decimals and significant digits of a step; a float64
+// carries no more
+const maxDigits = 15
+
+// DefaultPrecision is the precision of the kinds a config leaves unset:
+// whole dollars from a million, such as market caps, cents from a dollar and
+// four significant digits below, whole sats from a hundred, eight decimals
+// of BTC, and two decimals of a percentage from 1% with two significant
+// digits below, e.g. 0.053%
+var DefaultPrecision = types.PrecisionConfig{
+	USD:     []types.PrecisionStep{{From: 1e6, Decimals: 0}, {From: 1, Decimals: 2}, {From: 0, Significant: 4}},
+	Sats:    []types.PrecisionStep{{From: 100, Decimals: 0}, {From: 0, Decimals: 2}},
+	BTC:     []types.PrecisionStep{{From: 0, Decimals: 8}},
+	Percent: []types.PrecisionStep{{From: 1, Decimals: 2}, {From: 0, Significant: 2}},
+}
+
+var (
+	mu     sync.RWMutex
+	policy = DefaultPrecision
+)
+
+// Configure validates cfg and makes it the precision of every value
+// formatted afterwards, with the defaults for the kinds it leaves unset
+func Configure(cfg types.PrecisionConfig) error {
+	if err := Validate(cfg); err != nil {
+		return err
+	}
+	resolved := Resolve(cfg)
+	mu.Lock()
+	defer mu.Unlock()
+	policy = resolved
+	return nil
+}
+
+// Current returns the precision in use
+func Current() types.PrecisionConfig {
+	mu.RLock()
+	defer mu.RUnlock()
+	return policy
+}
+
+// Resolve returns cfg with the kinds it leaves unset taken from
+// DefaultPrecision
+func Resolve(cfg types.PrecisionConfig) types.PrecisionConfig {
+	if len(cfg.USD) == 0 {
+		cfg.USD = DefaultPrecision.USD
+	}
+	if len(cfg.Sats) == 0 {
+		cfg.Sats = DefaultPrecision.Sats
+	}
+	if len(cfg.BTC) == 0 {
+		cfg.BTC = DefaultPrecision.BTC
+	}
+	if len(cfg.Percent) == 0 {
+		cfg.Percent = DefaultPrecision.Percent
+	}
+	return cfg
+}
+
+// Validate checks that the steps of cfg start at different magnitudes that
+// are not negative and set either decimals or significant digits, up to 15
+func Validate(cfg types.PrecisionConfig) error {
+	for _, kind := range Kinds {
+		seen := make(map[float64]bool)
+		for i, step := range steps(cfg, kind) {
+			switch {
+			case step.From < 0 || math.IsNaN(step.From) || math.IsInf(step.From, 0):
+				return fmt.Errorf("precision %s step %d: from must be a magnitude of 0 or more", kind, i+1)
+			case seen[step.From]:
+				return fmt.Errorf("precision %s step %d: another step starts from %g", kind, i+1, step.From)
+			case step.Decimals < 0 || step.Decimals > maxDigits:
+				return fmt.Errorf("precision %s step %d: decimals must be from 0 to %d", kind, i+1, maxDigits)
+			case step.Significant < 0 || step.Significant > maxDigits:
+				return fmt.Errorf("precision %s step %d: significant must be from 0 to %d", kind, i+1, maxDigits)
+			case step.Decimals > 0 && step.Significant > 0:
+				return fmt.Errorf("precision %s step %d: set decimals or significant, not both", kind, i+1)
+			}
+			seen[step.From] = true
+		}
+	}
+	return nil
+}
+
+// steps returns the steps cfg sets for kind
+func steps(cfg types.PrecisionConfig, kind string) []types.PrecisionStep {
+	switch kind {
+	case Sats:
+		return cfg.Sats
+	case BTC:
+		return cfg.BTC
+	case Percent:
+		return cfg.Percent
+	}
+	return cfg.USD
+}
+
+// Decimals returns the decimals a value of kind is shown with: those of the
+// step with the largest From at or below its magnitude, or of the smallest
+// step when it is below all of them. Zero is shown like 1.
+func Decimals(kind string, v float64) int {
+	magnitude := math.Abs(v)
+	if magnitude == 0 {
+		magnitude = 1
+	}
+	// a scan rather than a sort, so exports of millions of values do not
+	// allocate for each
+	var step, smallest *types.PrecisionStep
+	kindSteps := steps(Current(), kind)
+	for i := range kindSteps {
+		s := &kindSteps[i]
+		if smallest == nil || s.From < smallest.From {
+			smallest = s
+		}
+		if magnitude >= s.From && (step == nil || s.From > step.From) {
+			step = s
+		}
+	}
+	if step == nil {
+		step = smallest
+	}
+	if step.Significant == 0 {
+		return step.Decimals
+	}
+	decimals := step.Significant - 1 - int(math.Floor(math.Log10(magnitude)))
+	return min(max(decimals, 0), maxDigits)
+}
+
+// Round rounds v half away from zero to the decimals of kind. NaN and
+// infinite values are returned as they are.
+func Round(kind string, v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	scale := math.Pow10(Decimals(kind, v))
+	rounded := math.Round(v*scale) / scale
+	if rounded == 0 {
+		return 0 // no negative zero
+	}
+	return rounded
+}
+
+// RoundPct rounds fraction to the decimals Pct shows it with, e.g. 0.123456
+// to 0.1235
+func RoundPct(fraction float64) float64 {
+	if math.IsNaN(fraction) || math.IsInf(fraction, 0) {
+		return fraction
+	}
+	// parse the decimal digits rather than divide, which would add float noise
+	rounded, _ := strconv.ParseFloat(Format(Percent, fraction*100)+"e-2", 64)
+	if rounded == 0 {
+		return 0
+	}
+	return rounded
+}
+
+// Format formats v, a value of kind, rounded to its decimals
+func Format(kind string, v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(Round(kind, v), 'f', Decimals(kind, v), 64)
+}
+
+// AppendFormat appends v formatted like Format to dst, for writers of many
+// values that reuse one buffer
+func AppendFormat(dst []byte, kind string, v float64) []byte {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.AppendFloat(dst, v, 'f', -1, 64)
+	}
+	return strconv.AppendFloat(dst, Round(kind, v), 'f', Decimals(kind, v), 64)
+}
+
+// Signed formats v like Format with a + sign unless it rounds below zero
+func Signed(kind string, v float64) string {
+	if Round(kind, v) < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Format(kind, v)
+	}
+	return "+" + Format(kind, v)
+}
+
+// Pct formats fraction as a percentage, e.g. 0.1234 as 12.34%
+func Pct(fraction float64) string {
+	return Format(Percent, fraction*100) + "%"
+}
+
+// SignedPct formats fraction as a signed percentage, e.g. 0.1234 as +12.34%
+func SignedPct(fraction float64) string {
+	return Signed(Percent, fraction*100) + "%"
+}
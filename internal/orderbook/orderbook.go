@@ -0,0 +1,102 @@
+package orderbook
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// Analyze computes spread, depth within ±band of the mid price, bid/ask
+// imbalance and walls (levels at least wallMultiple times the average level
+// size within the band)
+func Analyze(book *types.OrderBook, band float64, wallMultiple float64) types.OrderBookMetrics {
+	metrics := types.OrderBookMetrics{
+		Symbol:    book.Symbol,
+		Timestamp: book.Timestamp,
+		DepthBand: band,
+	}
+
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return metrics
+	}
+
+	metrics.BestBid = book.Bids[0].Price
+	metrics.BestAsk = book.Asks[0].Price
+	metrics.MidPrice = (metrics.BestBid + metrics.BestAsk) / 2
+	metrics.SpreadPct = (metrics.BestAsk - metrics.BestBid) / metrics.MidPrice
+
+	lowerBound := metrics.MidPrice * (1 - band)
+	upperBound := metrics.MidPrice * (1 + band)
+
+	bids := levelsWithin(book.Bids, lowerBound, upperBound)
+	asks := levelsWithin(book.Asks, lowerBound, upperBound)
+
+	metrics.BidDepth = totalQuantity(bids)
+	metrics.AskDepth = totalQuantity(asks)
+
+	if total := metrics.BidDepth + metrics.AskDepth; total > 0 {
+		metrics.Imbalance = (metrics.BidDepth - metrics.AskDepth) / total
+	}
+
+	levelCount := len(bids) + len(asks)
+	if levelCount > 0 {
+		avgLevel := (metrics.BidDepth + metrics.AskDepth) / float64(levelCount)
+		metrics.BidWalls = findWalls(bids, avgLevel*wallMultiple)
+		metrics.AskWalls = findWalls(asks, avgLevel*wallMultiple)
+	}
+
+	return metrics
+}
+
+// CumulativeDepth returns cumulative quantity by price for one side of the book
+func CumulativeDepth(levels []types.OrderBookLevel) []types.OrderBookLevel {
+	cumulative := make([]types.OrderBookLevel, len(levels))
+	total := 0.0
+	for i, level := range levels {
+		total += level.Quantity
+		cumulative[i] = types.OrderBookLevel{Price: level.Price, Quantity: total}
+	}
+	return cumulative
+}
+
+// ImbalanceSignal turns the book imbalance into a trading signal
+func ImbalanceSignal(metrics types.OrderBookMetrics) string {
+	switch {
+	case metrics.Imbalance > 0.2:
+		return fmt.Sprintf("BUY - Bid-heavy order book (imbalance %+.2f)", metrics.Imbalance)
+	case metrics.Imbalance < -0.2:
+		return fmt.Sprintf("SELL - Ask-heavy order book (imbalance %+.2f)", metrics.Imbalance)
+	default:
+		return fmt.Sprintf("HOLD - Balanced order book (imbalance %+.2f)", metrics.Imbalance)
+	}
+}
+
+// levelsWithin returns the levels priced inside [lower, upper]
+func levelsWithin(levels []types.OrderBookLevel, lower, upper float64) []types.OrderBookLevel {
+	var within []types.OrderBookLevel
+	for _, level := range levels {
+		if level.Price >= lower && level.Price <= upper {
+			within = append(within, level)
+		}
+	}
+	return within
+}
+
+// totalQuantity sums the quantity of the given levels
+func totalQuantity(levels []types.OrderBookLevel) float64 {
+	total := 0.0
+	for _, level := range levels {
+		total += level.Quantity
+	}
+	return total
+}
+
+// findWalls returns the levels whose quantity is at least threshold
+func findWalls(levels []types.OrderBookLevel, threshold float64) []types.OrderBookLevel {
+	var walls []types.OrderBookLevel
+	for _, level := range levels {
+		if level.Quantity >= threshold {
+			walls = append(walls, level)
+		}
+	}
+	return walls
+}
@@ -0,0 +1,98 @@
+package orderflow
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+)
+
+// Compute buckets trades into the candles of the series (candle i covers
+// [Timestamp_i, Timestamp_i+1), the last one a bar interval) and derives buy/sell volume, delta, CVD and
+// large trades. A largeTradeQty <= 0 uses mean + 3 standard deviations of
+// the trade sizes.
+func Compute(bts *types.BTCTimeSeries, trades []types.Trade, largeTradeQty float64) *types.OrderFlowData {
+	n := len(bts.Data)
+	if n == 0 || len(trades) == 0 {
+		return nil
+	}
+
+	flow := &types.OrderFlowData{
+		BuyVolume:  make([]float64, n),
+		SellVolume: make([]float64, n),
+		Delta:      make([]float64, n),
+		CVD:        make([]float64, n),
+		Threshold:  largeTradeQty,
+	}
+
+	if flow.Threshold <= 0 {
+		quantities := make([]float64, len(trades))
+		for i, trade := range trades {
+			quantities[i] = trade.Quantity
+		}
+		stats := statistics.Calculate(quantities)
+		flow.Threshold = stats.Mean + 3*stats.StdDev
+	}
+
+	// Trades after the close of the last candle belong to none
+	end := bts.Data[n-1].Timestamp.Add(timeseries.DetectFrequency(bts).Interval)
+	candle := 0
+	for _, trade := range trades {
+		if trade.Timestamp.Before(bts.Data[0].Timestamp) || !trade.Timestamp.Before(end) {
+			continue
+		}
+		for candle+1 < n && !trade.Timestamp.Before(bts.Data[candle+1].Timestamp) {
+			candle++
+		}
+
+		side := "buy"
+		if trade.IsBuyerMaker {
+			side = "sell"
+			flow.SellVolume[candle] += trade.Quantity
+		} else {
+			flow.BuyVolume[candle] += trade.Quantity
+		}
+
+		if trade.Quantity >= flow.Threshold {
+			flow.LargeTrades = append(flow.LargeTrades, types.LargeTrade{
+				Timestamp:   trade.Timestamp,
+				Price:       trade.Price,
+				Quantity:    trade.Quantity,
+				Side:        side,
+				CandleIndex: candle,
+			})
+		}
+	}
+
+	cumulative := 0.0
+	for i := 0; i < n; i++ {
+		flow.Delta[i] = flow.BuyVolume[i] - flow.SellVolume[i]
+		cumulative += flow.Delta[i]
+		flow.CVD[i] = cumulative
+	}
+
+	return flow
+}
+
+// CVDSignal compares the CVD trend with the price trend over the last
+// lookback candles, flagging absorption/divergence when they disagree
+func CVDSignal(bts *types.BTCTimeSeries, flow *types.OrderFlowData, lookback int) string {
+	n := len(flow.CVD)
+	if n <= lookback || len(bts.Data) != n {
+		return "HOLD - Insufficient order flow data"
+	}
+
+	cvdChange := flow.CVD[n-1] - flow.CVD[n-1-lookback]
+	priceChange := bts.Data[n-1].Close - bts.Data[n-1-lookback].Close
+
+	switch {
+	case cvdChange > 0 && priceChange < 0:
+		return fmt.Sprintf("BUY - Bullish CVD divergence (CVD %+.2f while price fell)", cvdChange)
+	case cvdChange < 0 && priceChange > 0:
+		return fmt.Sprintf("SELL - Bearish CVD divergence (CVD %+.2f while price rose)", cvdChange)
+	case cvdChange > 0:
+		return fmt.Sprintf("HOLD - Buyers in control (CVD %+.2f)", cvdChange)
+	default:
+		return fmt.Sprintf("HOLD - Sellers in control (CVD %+.2f)", cvdChange)
+	}
+}
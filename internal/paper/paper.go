@@ -0,0 +1,134 @@
+// Package paper trades the live signals of one strategy with a virtual
+// balance, so a strategy can be followed in real time without real orders.
+package paper
+
+import (
+	"btc-analyzer/internal/store"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHistory is the number of equity points kept in the account
+const maxHistory = 1000
+
+// Trader executes the trading signal of one strategy against the latest
+// price of every analysis. A BUY signal while in cash buys with all of it; a
+// SELL signal while long sells everything. Fills are at the latest close
+// with a fee, and the account is persisted after every update.
+type Trader struct {
+	mu    sync.Mutex
+	store *store.PaperStore
+}
+
+// NewTrader returns a trader for the account in st, opening one with balance
+// that follows strategy when st is empty. A stored account must follow the
+// same strategy.
+func NewTrader(st *store.PaperStore, strategy string, balance, feeBps float64) (*Trader, error) {
+	if account, ok := st.Account(); ok {
+		if account.Strategy != strategy {
+			return nil, fmt.Errorf("paper account follows %q, not %q: use another paper store to start over", account.Strategy, strategy)
+		}
+		return &Trader{store: st}, nil
+	}
+
+	if balance <= 0 {
+		return nil, fmt.Errorf("paper balance must be positive")
+	}
+	now := time.Now().UTC()
+	account := types.PaperAccount{
+		Strategy:        strategy,
+		FeeBps:          feeBps,
+		StartingBalance: balance,
+		Cash:            balance,
+		Equity:          balance,
+		Fills:           []types.PaperFill{},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := st.Put(account); err != nil {
+		return nil, err
+	}
+	return &Trader{store: st}, nil
+}
+
+// Account returns the current state of the account
+func (t *Trader) Account() types.PaperAccount {
+	account, _ := t.store.Account()
+	return account
+}
+
+// Update marks the account to the latest price of result and executes the
+// strategy's signal. It returns the fill, nil when the signal did not
+// trade. Results of another symbol than the account's first are rejected.
+func (t *Trader) Update(result *types.AnalysisResult) (*types.PaperFill, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	account, ok := t.store.Account()
+	if !ok {
+		return nil, fmt.Errorf("no paper account")
+	}
+	bts := result.Series
+	if bts == nil || len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no price to trade at")
+	}
+	if account.Symbol == "" {
+		account.Symbol = bts.Symbol
+	}
+	if bts.Symbol != account.Symbol {
+		return nil, fmt.Errorf("paper account trades %s, not %s", account.Symbol, bts.Symbol)
+	}
+
+	price := bts.Data[len(bts.Data)-1].Close
+	if price <= 0 {
+		return nil, fmt.Errorf("invalid price %.2f", price)
+	}
+	now := time.Now().UTC()
+	signal, ok := result.Signals[account.Strategy]
+	if !ok {
+		signal = "HOLD - no signal"
+	}
+
+	var fill *types.PaperFill
+	fee := account.FeeBps / 10000
+	switch {
+	case strings.HasPrefix(signal, "BUY") && account.Quantity == 0 && account.Cash > 0:
+		quantity := account.Cash * (1 - fee) / price
+		fill = &types.PaperFill{Time: now, Side: "buy", Price: price, Quantity: quantity, Fee: account.Cash * fee, Signal: signal}
+		account.CostBasis = account.Cash
+		account.Quantity = quantity
+		account.Cash = 0
+	case strings.HasPrefix(signal, "SELL") && account.Quantity > 0:
+		gross := account.Quantity * price
+		proceeds := gross * (1 - fee)
+		fill = &types.PaperFill{Time: now, Side: "sell", Price: price, Quantity: account.Quantity, Fee: gross * fee,
+			PnL: proceeds - account.CostBasis, Signal: signal}
+		account.RealizedPnL += fill.PnL
+		account.Cash += proceeds
+		account.Quantity = 0
+		account.CostBasis = 0
+	}
+	if fill != nil {
+		account.Fills = append(account.Fills, *fill)
+	}
+
+	account.LastPrice = price
+	account.Equity = account.Cash + account.Quantity*price
+	account.UnrealizedPnL = 0
+	if account.Quantity > 0 {
+		account.UnrealizedPnL = account.Quantity*price - account.CostBasis
+	}
+	account.UpdatedAt = now
+	account.History = append(account.History, types.EquityPoint{Time: now, Equity: account.Equity})
+	if len(account.History) > maxHistory {
+		account.History = account.History[len(account.History)-maxHistory:]
+	}
+
+	if err := t.store.Put(account); err != nil {
+		return nil, err
+	}
+	return fill, nil
+}
@@ -0,0 +1,164 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// elliottRecentSwings limits candidate counts to those ending near the latest swings
+const elliottRecentSwings = 4
+
+// DetectElliottWaves builds the ZigZag swing skeleton and proposes the best
+// impulse and corrective wave counts ending near the most recent swings
+func DetectElliottWaves(bts *types.BTCTimeSeries, threshold float64, maxCounts int) types.ElliottAnalysis {
+	swings := FindZigZagSwings(bts, threshold)
+	return types.ElliottAnalysis{
+		Threshold: threshold,
+		Swings:    swings,
+		Counts:    CountElliottWaves(swings, maxCounts),
+	}
+}
+
+// CountElliottWaves proposes wave counts over consecutive swings. Counts that
+// break a hard rule are discarded; the rest are ranked by how closely they
+// follow the Fibonacci guidelines.
+func CountElliottWaves(swings []types.SwingPoint, maxCounts int) []types.WaveCount {
+	var counts []types.WaveCount
+
+	for end := len(swings) - 1; end >= 0 && end >= len(swings)-elliottRecentSwings; end-- {
+		if end >= 5 {
+			if count, ok := validateImpulse(swings[end-5 : end+1]); ok {
+				counts = append(counts, count)
+			}
+		}
+		if end >= 3 {
+			if count, ok := validateCorrection(swings[end-3 : end+1]); ok {
+				counts = append(counts, count)
+			}
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Confidence > counts[j].Confidence
+	})
+	if maxCounts > 0 && len(counts) > maxCounts {
+		counts = counts[:maxCounts]
+	}
+
+	return counts
+}
+
+// validateImpulse checks six swings (origin and waves 1-5) against the impulse rules
+func validateImpulse(points []types.SwingPoint) (types.WaveCount, bool) {
+	sign, direction := waveDirection(points[0])
+	w1 := sign * (points[1].Price - points[0].Price)
+	w2 := sign * (points[1].Price - points[2].Price)
+	w3 := sign * (points[3].Price - points[2].Price)
+	w4 := sign * (points[3].Price - points[4].Price)
+	w5 := sign * (points[5].Price - points[4].Price)
+
+	// Hard rules
+	if w1 <= 0 || w2 >= w1 {
+		return types.WaveCount{}, false // wave 2 retraced beyond the start of wave 1
+	}
+	if w3 < w1 && w3 < w5 {
+		return types.WaveCount{}, false // wave 3 is the shortest
+	}
+	if sign*(points[3].Price-points[1].Price) <= 0 {
+		return types.WaveCount{}, false // wave 3 did not exceed wave 1
+	}
+	if sign*(points[4].Price-points[1].Price) <= 0 {
+		return types.WaveCount{}, false // wave 4 entered wave 1 territory
+	}
+
+	r2, r3, r4 := w2/w1, w3/w1, w4/w3
+	scores := []float64{
+		ratioScore(r2, 0.25, 0.5, 0.618),
+		ratioScore(r3, 0.6, 1.618, 2.618),
+		ratioScore(r4, 0.2, 0.236, 0.382),
+		math.Max(ratioScore(w5/w1, 0.3, 1.0), ratioScore(w5/w3, 0.3, 0.618)),
+	}
+	// Alternation: one of the corrective waves 2 and 4 is deep, the other shallow
+	alternation := 0.5
+	if (r2 > 0.5) != (w4/w3 > 0.5) {
+		alternation = 1
+	}
+	scores = append(scores, alternation)
+
+	return types.WaveCount{
+		Kind:       "impulse",
+		Direction:  direction,
+		Labels:     []string{"0", "1", "2", "3", "4", "5"},
+		Points:     append([]types.SwingPoint(nil), points...),
+		Confidence: meanScore(scores),
+		Notes: []string{
+			fmt.Sprintf("Wave 2 retraced %s of wave 1", numfmt.Pct(r2)),
+			fmt.Sprintf("Wave 3 = %.2fx wave 1", r3),
+			fmt.Sprintf("Wave 4 retraced %s of wave 3", numfmt.Pct(r4)),
+			fmt.Sprintf("Wave 5 = %.2fx wave 1", w5/w1),
+		},
+	}, true
+}
+
+// validateCorrection checks four swings (origin and waves A-C) against the zigzag correction rules
+func validateCorrection(points []types.SwingPoint) (types.WaveCount, bool) {
+	sign, direction := waveDirection(points[0])
+	a := sign * (points[1].Price - points[0].Price)
+	b := sign * (points[1].Price - points[2].Price)
+	c := sign * (points[3].Price - points[2].Price)
+
+	// Hard rules: B stays within A, C moves beyond the end of A
+	if a <= 0 || b >= a {
+		return types.WaveCount{}, false
+	}
+	if sign*(points[3].Price-points[1].Price) <= 0 {
+		return types.WaveCount{}, false
+	}
+
+	scores := []float64{
+		ratioScore(b/a, 0.3, 0.5, 0.618),
+		ratioScore(c/a, 0.4, 1.0, 1.618),
+	}
+
+	return types.WaveCount{
+		Kind:       "corrective",
+		Direction:  direction,
+		Labels:     []string{"0", "A", "B", "C"},
+		Points:     append([]types.SwingPoint(nil), points...),
+		Confidence: meanScore(scores),
+		Notes: []string{
+			fmt.Sprintf("Wave B retraced %s of wave A", numfmt.Pct(b/a)),
+			fmt.Sprintf("Wave C = %.2fx wave A", c/a),
+		},
+	}, true
+}
+
+// waveDirection returns the sign and direction of a count starting at origin
+func waveDirection(origin types.SwingPoint) (float64, string) {
+	if origin.Type == "low" {
+		return 1, "up"
+	}
+	return -1, "down"
+}
+
+// ratioScore scores how close ratio is to the nearest target, falling
+// linearly to zero at tolerance away
+func ratioScore(ratio, tolerance float64, targets ...float64) float64 {
+	best := 0.0
+	for _, target := range targets {
+		best = math.Max(best, 1-math.Abs(ratio-target)/tolerance)
+	}
+	return best
+}
+
+// meanScore averages guideline scores
+func meanScore(scores []float64) float64 {
+	sum := 0.0
+	for _, score := range scores {
+		sum += score
+	}
+	return sum / float64(len(scores))
+}
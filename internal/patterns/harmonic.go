@@ -0,0 +1,104 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// harmonicSpec holds the Fibonacci ratio ranges defining a harmonic pattern
+type harmonicSpec struct {
+	name string
+	ab   [2]float64 // AB as a retracement of XA
+	bc   [2]float64 // BC as a retracement of AB
+	cd   [2]float64 // CD as an extension of BC
+	ad   [2]float64 // AD as a retracement of XA, locating D
+}
+
+var harmonicSpecs = []harmonicSpec{
+	{"Gartley", [2]float64{0.618, 0.618}, [2]float64{0.382, 0.886}, [2]float64{1.272, 1.618}, [2]float64{0.786, 0.786}},
+	{"Bat", [2]float64{0.382, 0.5}, [2]float64{0.382, 0.886}, [2]float64{1.618, 2.618}, [2]float64{0.886, 0.886}},
+	{"Butterfly", [2]float64{0.786, 0.786}, [2]float64{0.382, 0.886}, [2]float64{1.618, 2.24}, [2]float64{1.272, 1.618}},
+	{"Crab", [2]float64{0.382, 0.618}, [2]float64{0.382, 0.886}, [2]float64{2.24, 3.618}, [2]float64{1.618, 1.618}},
+}
+
+// DetectHarmonicPatterns finds completed XABCD patterns over consecutive swings
+// and pending XABC patterns at the end of the swing sequence. tolerance widens
+// every ratio range, e.g. 0.05 accepts ratios within 5% of the ideal range.
+func DetectHarmonicPatterns(swings []types.SwingPoint, tolerance float64) []types.HarmonicPattern {
+	var found []types.HarmonicPattern
+
+	for i := 0; i+5 <= len(swings); i++ {
+		found = append(found, matchHarmonic(swings[i:i+5], tolerance)...)
+	}
+
+	// The last four swings may still complete a pattern at a future D
+	if len(swings) >= 4 {
+		found = append(found, matchHarmonic(swings[len(swings)-4:], tolerance)...)
+	}
+
+	return found
+}
+
+// matchHarmonic tests four (pending) or five (complete) swings against every spec
+func matchHarmonic(points []types.SwingPoint, tolerance float64) []types.HarmonicPattern {
+	var matches []types.HarmonicPattern
+
+	x, a, b, c := points[0].Price, points[1].Price, points[2].Price, points[3].Price
+	sign, direction := 1.0, "bullish"
+	if points[0].Type == "high" {
+		sign, direction = -1.0, "bearish"
+	}
+
+	xa := sign * (a - x)
+	ab := sign * (a - b)
+	bc := sign * (c - b)
+	if xa <= 0 || ab <= 0 || bc <= 0 {
+		return matches
+	}
+
+	for _, spec := range harmonicSpecs {
+		ratios := map[string]float64{"AB/XA": ab / xa, "BC/AB": bc / ab}
+		if !inRatioRange(ratios["AB/XA"], spec.ab, tolerance) || !inRatioRange(ratios["BC/AB"], spec.bc, tolerance) {
+			continue
+		}
+
+		// D must satisfy both the AD retracement of XA and the CD extension of BC
+		adLow, adHigh := a-sign*spec.ad[1]*xa*(1+tolerance), a-sign*spec.ad[0]*xa*(1-tolerance)
+		cdLow, cdHigh := c-sign*spec.cd[1]*bc*(1+tolerance), c-sign*spec.cd[0]*bc*(1-tolerance)
+		przLow := math.Max(math.Min(adLow, adHigh), math.Min(cdLow, cdHigh))
+		przHigh := math.Min(math.Max(adLow, adHigh), math.Max(cdLow, cdHigh))
+		if przLow > przHigh {
+			continue
+		}
+
+		pattern := types.HarmonicPattern{
+			Name:      spec.name,
+			Direction: direction,
+			Points:    append([]types.SwingPoint(nil), points...),
+			Ratios:    ratios,
+			PRZLow:    przLow,
+			PRZHigh:   przHigh,
+		}
+
+		if len(points) == 5 {
+			d := points[4].Price
+			cd := sign * (c - d)
+			ad := sign * (a - d)
+			ratios["CD/BC"] = cd / bc
+			ratios["AD/XA"] = ad / xa
+			if !inRatioRange(ratios["CD/BC"], spec.cd, tolerance) || !inRatioRange(ratios["AD/XA"], spec.ad, tolerance) {
+				continue
+			}
+			pattern.Complete = true
+		}
+
+		matches = append(matches, pattern)
+	}
+
+	return matches
+}
+
+// inRatioRange reports whether ratio lies within bounds widened by tolerance
+func inRatioRange(ratio float64, bounds [2]float64, tolerance float64) bool {
+	return ratio >= bounds[0]*(1-tolerance) && ratio <= bounds[1]*(1+tolerance)
+}
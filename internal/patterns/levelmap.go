@@ -0,0 +1,230 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/indicators"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// levelSourceWeights rank level sources by how widely they are watched
+var levelSourceWeights = map[string]float64{
+	"swing": 0.6,
+	"MA50":  0.5,
+	"MA100": 0.7,
+	"MA200": 1.0,
+	"day":   0.4,
+	"week":  0.7,
+	"month": 1.0,
+	"gap":   0.5,
+	// Unfilled gaps between trading sessions, e.g. CME weekend gaps
+	"session_gap": 0.8,
+	// Round numbers, by tier of RoundLevels
+	"round_major": 0.9,
+	"round_mid":   0.6,
+	"round_minor": 0.3,
+}
+
+// dynamicLevel is a candidate level before scoring
+type dynamicLevel struct {
+	price  float64
+	source string
+	weight float64
+}
+
+// BuildLevelMap merges the scored swing levels with dynamic levels (50/100/200
+// SMA and EMA, previous day/week/month high/low/close, untested gaps and the
+// unfilled gaps between trading sessions) and
+// the round numbers around the latest close into one map ranked by score.
+// Round numbers have type RoundLevelType. Levels within tolerance of each
+// other are merged, and the confluence of several sources raises the score.
+func BuildLevelMap(bts *types.BTCTimeSeries, swingLevels []types.SRLevel, tolerance float64) []types.SRLevel {
+	if len(bts.Data) == 0 {
+		return nil
+	}
+	current := bts.Data[len(bts.Data)-1].Close
+
+	var levels []types.SRLevel
+	for _, level := range swingLevels {
+		level.Score = 0.7*level.Score + 0.3*levelSourceWeights["swing"]
+		levels = append(levels, level)
+	}
+
+	for _, candidate := range dynamicLevels(bts) {
+		levelType := "support"
+		if candidate.price > current {
+			levelType = "resistance"
+		}
+		scored := ScoreLevels(bts, []float64{candidate.price}, levelType, candidate.source, tolerance)
+		level := scored[0]
+		level.Score = 0.7*level.Score + 0.3*candidate.weight
+		// Dynamic levels are only valid now, so past closes through them are not breaks
+		level.Broken, level.Retested = false, false
+		level.BrokenAt, level.RetestedAt = time.Time{}, time.Time{}
+		levels = append(levels, level)
+	}
+
+	for _, round := range RoundLevels(current) {
+		levelType := "support"
+		if round.Price > current {
+			levelType = "resistance"
+		}
+		scored := ScoreLevels(bts, []float64{round.Price}, levelType, "round", tolerance)
+		level := scored[0]
+		level.Type = RoundLevelType
+		level.Score = 0.7*level.Score + 0.3*levelSourceWeights["round_"+round.Tier]
+		level.Broken, level.Retested = false, false
+		level.BrokenAt, level.RetestedAt = time.Time{}, time.Time{}
+		levels = append(levels, level)
+	}
+
+	return mergeLevels(levels, tolerance)
+}
+
+// dynamicLevels collects moving average, prior period, gap and session gap
+// levels
+func dynamicLevels(bts *types.BTCTimeSeries) []dynamicLevel {
+	var levels []dynamicLevel
+
+	for _, period := range []int{50, 100, 200} {
+		weight := levelSourceWeights[fmt.Sprintf("MA%d", period)]
+		if sma := indicators.CalculateMovingAverage(bts, period); len(sma) > 0 {
+			levels = append(levels, dynamicLevel{sma[len(sma)-1], fmt.Sprintf("SMA%d", period), weight})
+		}
+		if ema := indicators.CalculateEMA(bts, period); len(ema) > 0 {
+			levels = append(levels, dynamicLevel{ema[len(ema)-1], fmt.Sprintf("EMA%d", period), weight})
+		}
+	}
+
+	periods := []struct {
+		name string
+		key  func(time.Time) time.Time
+	}{
+		{"day", func(t time.Time) time.Time { return t.UTC().Truncate(24 * time.Hour) }},
+		{"week", func(t time.Time) time.Time {
+			day := t.UTC().Truncate(24 * time.Hour)
+			return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		}},
+		{"month", func(t time.Time) time.Time {
+			return time.Date(t.UTC().Year(), t.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+		}},
+	}
+	for _, period := range periods {
+		high, low, close, ok := previousPeriod(bts, period.key)
+		if !ok {
+			continue
+		}
+		weight := levelSourceWeights[period.name]
+		levels = append(levels,
+			dynamicLevel{high, "prev_" + period.name + "_high", weight},
+			dynamicLevel{low, "prev_" + period.name + "_low", weight},
+			dynamicLevel{close, "prev_" + period.name + "_close", weight},
+		)
+	}
+
+	for _, gap := range untestedGaps(bts) {
+		levels = append(levels, dynamicLevel{gap, "gap", levelSourceWeights["gap"]})
+	}
+
+	if sessions := AnalyzeSessionGaps(bts, DefaultMinSessionGap); sessions != nil {
+		for _, gap := range sessions.Unfilled {
+			levels = append(levels, dynamicLevel{gap.Close, "session_gap", levelSourceWeights["session_gap"]})
+		}
+	}
+
+	return levels
+}
+
+// previousPeriod returns the high, low and close of the last complete period
+// before the one containing the latest bar
+func previousPeriod(bts *types.BTCTimeSeries, key func(time.Time) time.Time) (float64, float64, float64, bool) {
+	currentKey := key(bts.Data[len(bts.Data)-1].Timestamp)
+
+	end := len(bts.Data) - 1
+	for end >= 0 && key(bts.Data[end].Timestamp).Equal(currentKey) {
+		end--
+	}
+	if end < 0 {
+		return 0, 0, 0, false
+	}
+
+	periodKey := key(bts.Data[end].Timestamp)
+	high, low, close := bts.Data[end].High, bts.Data[end].Low, bts.Data[end].Close
+	for i := end; i >= 0 && key(bts.Data[i].Timestamp).Equal(periodKey); i-- {
+		high = math.Max(high, bts.Data[i].High)
+		low = math.Min(low, bts.Data[i].Low)
+	}
+
+	return high, low, close, true
+}
+
+// untestedGaps returns the origin edge of every price gap that later bars
+// never traded back to
+func untestedGaps(bts *types.BTCTimeSeries) []float64 {
+	var gaps []float64
+
+	for i := 1; i < len(bts.Data); i++ {
+		prev, curr := bts.Data[i-1], bts.Data[i]
+		var level float64
+		switch {
+		case curr.Low > prev.High:
+			level = prev.High
+		case curr.High < prev.Low:
+			level = prev.Low
+		default:
+			continue
+		}
+
+		tested := false
+		for _, later := range bts.Data[i+1:] {
+			if later.Low <= level && later.High >= level {
+				tested = true
+				break
+			}
+		}
+		if !tested {
+			gaps = append(gaps, level)
+		}
+	}
+
+	return gaps
+}
+
+// mergeLevels combines levels within tolerance of each other, keeping the
+// strongest level's history and adding a confluence bonus per extra source
+func mergeLevels(levels []types.SRLevel, tolerance float64) []types.SRLevel {
+	sort.SliceStable(levels, func(i, j int) bool {
+		return levels[i].Score > levels[j].Score
+	})
+
+	var merged []types.SRLevel
+	var sources [][]string
+	for _, level := range levels {
+		found := false
+		for i := range merged {
+			if math.Abs(level.Price-merged[i].Price)/merged[i].Price <= tolerance {
+				sources[i] = append(sources[i], level.Source)
+				merged[i].Score = math.Min(1, merged[i].Score+0.05)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, level)
+			sources = append(sources, []string{level.Source})
+		}
+	}
+
+	for i := range merged {
+		merged[i].Source = strings.Join(sources[i], ", ")
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Score > merged[j].Score
+	})
+
+	return merged
+}
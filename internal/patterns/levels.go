@@ -0,0 +1,83 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+)
+
+// ScoreLevels scores each level by touches, recency of the last touch and the
+// relative volume traded at the level, and tracks whether it was broken by a
+// close beyond tolerance and then retested from the other side. Levels are
+// returned strongest first.
+func ScoreLevels(bts *types.BTCTimeSeries, levels []float64, levelType, source string, tolerance float64) []types.SRLevel {
+	scored := make([]types.SRLevel, 0, len(levels))
+	if len(bts.Data) == 0 {
+		return scored
+	}
+
+	avgVolume := 0.0
+	for _, bar := range bts.Data {
+		avgVolume += bar.Volume
+	}
+	avgVolume /= float64(len(bts.Data))
+
+	for _, price := range levels {
+		level := types.SRLevel{Price: price, Type: levelType, Source: source}
+		lower, upper := price*(1-tolerance), price*(1+tolerance)
+
+		touching := false
+		lastTouch := -1
+		touchVolume, touchBars := 0.0, 0
+		for i, bar := range bts.Data {
+			touches := bar.Low <= upper && bar.High >= lower
+			if touches {
+				if !touching {
+					level.Touches++
+				}
+				lastTouch = i
+				touchVolume += bar.Volume
+				touchBars++
+			}
+			touching = touches
+
+			// Break: a close through the level after it was first touched
+			if !level.Broken && level.Touches > 0 {
+				if (levelType == "support" && bar.Close < lower) || (levelType == "resistance" && bar.Close > upper) {
+					level.Broken = true
+					level.BrokenAt = bar.Timestamp
+					continue
+				}
+			}
+
+			// Retest: after the break, price returns to the level and closes back beyond it
+			if level.Broken && !level.Retested && touches && bar.Timestamp.After(level.BrokenAt) {
+				if (levelType == "support" && bar.Close < price) || (levelType == "resistance" && bar.Close > price) {
+					level.Retested = true
+					level.RetestedAt = bar.Timestamp
+				}
+			}
+		}
+
+		recency := 0.0
+		if lastTouch >= 0 {
+			level.LastTouch = bts.Data[lastTouch].Timestamp
+			recency = float64(lastTouch+1) / float64(len(bts.Data))
+		}
+		if touchBars > 0 && avgVolume > 0 {
+			level.TouchVolume = touchVolume / float64(touchBars) / avgVolume
+		}
+
+		level.Score = 0.4*math.Min(float64(level.Touches)/5, 1) +
+			0.3*recency +
+			0.3*math.Min(level.TouchVolume/2, 1)
+
+		scored = append(scored, level)
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	return scored
+}
@@ -0,0 +1,156 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+)
+
+// liquidityPoint is a single price where stops are likely to rest
+type liquidityPoint struct {
+	price float64
+	index int
+	equal bool // part of equal highs/lows rather than a wick rejection
+}
+
+// FindLiquidityZones estimates liquidity pools from long-wick rejections and
+// equal highs/lows. Points are clustered into zones at most twice tolerance
+// wide; single wicks and zones that price has since traded through (swept)
+// are dropped. Zones are returned nearest first.
+func FindLiquidityZones(bts *types.BTCTimeSeries, lookback int, tolerance float64) []types.LiquidityZone {
+	if len(bts.Data) < 2*lookback+1 {
+		return nil
+	}
+
+	var highs, lows []liquidityPoint
+	for i, bar := range bts.Data {
+		body := math.Abs(bar.Close - bar.Open)
+		barRange := bar.High - bar.Low
+		if barRange <= 0 {
+			continue
+		}
+		upperWick := bar.High - math.Max(bar.Open, bar.Close)
+		lowerWick := math.Min(bar.Open, bar.Close) - bar.Low
+		if upperWick >= 2*body && upperWick >= 0.5*barRange {
+			highs = append(highs, liquidityPoint{price: bar.High, index: i})
+		}
+		if lowerWick >= 2*body && lowerWick >= 0.5*barRange {
+			lows = append(lows, liquidityPoint{price: bar.Low, index: i})
+		}
+	}
+
+	highs = append(highs, equalPivots(bts, lookback, tolerance, true)...)
+	lows = append(lows, equalPivots(bts, lookback, tolerance, false)...)
+
+	latest := bts.Data[len(bts.Data)-1].Close
+	var zones []types.LiquidityZone
+	for _, zone := range clusterLiquidity(bts, highs, tolerance, "buy-side") {
+		if zone.Low > latest {
+			zone.DistancePct = (zone.Low - latest) / latest
+			zones = append(zones, zone)
+		}
+	}
+	for _, zone := range clusterLiquidity(bts, lows, tolerance, "sell-side") {
+		if zone.High < latest {
+			zone.DistancePct = (latest - zone.High) / latest
+			zones = append(zones, zone)
+		}
+	}
+
+	sort.SliceStable(zones, func(i, j int) bool {
+		return zones[i].DistancePct < zones[j].DistancePct
+	})
+
+	return zones
+}
+
+// equalPivots returns the swing highs (or lows) that have another swing of
+// the same type within tolerance
+func equalPivots(bts *types.BTCTimeSeries, lookback int, tolerance float64, highs bool) []liquidityPoint {
+	var pivots []liquidityPoint
+	for i := lookback; i < len(bts.Data)-lookback; i++ {
+		isPivot := true
+		for j := i - lookback; j <= i+lookback && isPivot; j++ {
+			if j == i {
+				continue
+			}
+			if highs && bts.Data[j].High > bts.Data[i].High {
+				isPivot = false
+			}
+			if !highs && bts.Data[j].Low < bts.Data[i].Low {
+				isPivot = false
+			}
+		}
+		if isPivot {
+			price := bts.Data[i].Low
+			if highs {
+				price = bts.Data[i].High
+			}
+			pivots = append(pivots, liquidityPoint{price: price, index: i, equal: true})
+		}
+	}
+
+	var equal []liquidityPoint
+	for i, pivot := range pivots {
+		for j, other := range pivots {
+			if i != j && math.Abs(pivot.price-other.price)/pivot.price <= tolerance {
+				equal = append(equal, pivot)
+				break
+			}
+		}
+	}
+	return equal
+}
+
+// clusterLiquidity groups nearby points into zones and drops weak zones and
+// zones that a later bar traded through
+func clusterLiquidity(bts *types.BTCTimeSeries, points []liquidityPoint, tolerance float64, side string) []types.LiquidityZone {
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].price < points[j].price
+	})
+
+	var zones []types.LiquidityZone
+	for start := 0; start < len(points); {
+		end := start + 1
+		for end < len(points) && (points[end].price-points[start].price)/points[start].price <= 2*tolerance {
+			end++
+		}
+		cluster := points[start:end]
+		start = end
+
+		zone := types.LiquidityZone{Side: side, Low: cluster[0].price, High: cluster[len(cluster)-1].price}
+		last := 0
+		for _, point := range cluster {
+			if point.equal {
+				zone.EqualLevels++
+			} else {
+				zone.Wicks++
+			}
+			if point.index > last {
+				last = point.index
+			}
+		}
+		zone.LastSeen = bts.Data[last].Timestamp
+		zone.Strength = float64(zone.Wicks) + 2*float64(zone.EqualLevels)
+
+		// A lone wick is not a pool; require some clustering
+		if zone.Strength >= 2 && !liquiditySwept(bts, zone, last) {
+			zones = append(zones, zone)
+		}
+	}
+
+	return zones
+}
+
+// liquiditySwept reports whether any bar after index traded beyond the zone
+func liquiditySwept(bts *types.BTCTimeSeries, zone types.LiquidityZone, index int) bool {
+	for _, bar := range bts.Data[index+1:] {
+		if zone.Side == "buy-side" && bar.High > zone.High {
+			return true
+		}
+		if zone.Side == "sell-side" && bar.Low < zone.Low {
+			return true
+		}
+	}
+	return false
+}
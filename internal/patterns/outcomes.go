@@ -0,0 +1,78 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/types"
+	"sort"
+)
+
+// DefaultOutcomeHorizons are the forward horizons, in bars, used to score patterns
+var DefaultOutcomeHorizons = []int{1, 3, 5, 10}
+
+// PatternBias maps the built-in candlestick and volume patterns to the move
+// they are expected to precede
+var PatternBias = map[string]string{
+	"doji":              "neutral",
+	"hammer":            "bullish",
+	"shooting_star":     "bearish",
+	"bullish_engulfing": "bullish",
+	"bearish_engulfing": "bearish",
+	"morning_star":      "bullish",
+	"evening_star":      "bearish",
+	"volume_breakout":   "bullish",
+	"volume_selloff":    "bearish",
+	"low_volume":        "neutral",
+}
+
+// EvaluatePatternOutcomes measures close-to-close returns over each horizon
+// after every occurrence of every pattern. A win is a move in the pattern's
+// bias direction; neutral patterns count up moves. Occurrences too close to
+// the end of the series for a horizon are left out of that horizon.
+func EvaluatePatternOutcomes(bts *types.BTCTimeSeries, occurrences map[string][]int, bias map[string]string, horizons []int) []types.PatternOutcome {
+	var outcomes []types.PatternOutcome
+
+	for pattern, indices := range occurrences {
+		if len(indices) == 0 {
+			continue
+		}
+		direction := bias[pattern]
+		if direction == "" {
+			direction = "neutral"
+		}
+
+		outcome := types.PatternOutcome{
+			Pattern:     pattern,
+			Bias:        direction,
+			Occurrences: len(indices),
+		}
+
+		for _, horizon := range horizons {
+			result := types.HorizonOutcome{Bars: horizon}
+			wins := 0
+			sum := 0.0
+			for _, i := range indices {
+				if i < 0 || i+horizon >= len(bts.Data) || bts.Data[i].Close == 0 {
+					continue
+				}
+				ret := (bts.Data[i+horizon].Close - bts.Data[i].Close) / bts.Data[i].Close
+				if (direction == "bearish" && ret < 0) || (direction != "bearish" && ret > 0) {
+					wins++
+				}
+				sum += ret
+				result.Samples++
+			}
+			if result.Samples > 0 {
+				result.WinRate = float64(wins) / float64(result.Samples)
+				result.AvgReturn = sum / float64(result.Samples)
+			}
+			outcome.Outcomes = append(outcome.Outcomes, result)
+		}
+
+		outcomes = append(outcomes, outcome)
+	}
+
+	sort.Slice(outcomes, func(i, j int) bool {
+		return outcomes[i].Pattern < outcomes[j].Pattern
+	})
+
+	return outcomes
+}
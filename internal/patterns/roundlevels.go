@@ -0,0 +1,83 @@
+package patterns
+
+import (
+	"math"
+	"sort"
+)
+
+// RoundLevelType marks round-number levels in the level map, in place of
+// "support" or "resistance"
+const RoundLevelType = "round"
+
+// RoundLevel is a round-number price with the largest step it is a multiple
+// of, e.g. 60000 with step 10000 when price is in the tens of thousands
+type RoundLevel struct {
+	Price float64
+	Step  float64
+	Tier  string // "major", "mid" or "minor" for multiples of the magnitude, half of it and a tenth of it
+}
+
+// roundLevelTiers are the steps of round levels as fractions of the price
+// magnitude, largest first: at 63,000 they are the 10k, 5k and 1k multiples
+var roundLevelTiers = []struct {
+	name     string
+	fraction float64
+}{
+	{"major", 1},
+	{"mid", 0.5},
+	{"minor", 0.1},
+}
+
+// RoundLevels returns the nearest round numbers below and above price for
+// every tier of its magnitude, ordered by price. A level that is a multiple
+// of several steps is listed once, under the largest.
+func RoundLevels(price float64) []RoundLevel {
+	if price <= 0 || math.IsInf(price, 0) || math.IsNaN(price) {
+		return nil
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(price)))
+
+	var levels []RoundLevel
+	for _, tier := range roundLevelTiers {
+		step := magnitude * tier.fraction
+		below := math.Floor(price / step)
+		for _, multiple := range []float64{below, below + 1} {
+			level := multiple * step
+			if level <= 0 || hasRoundLevel(levels, level, step) {
+				continue
+			}
+			levels = append(levels, RoundLevel{Price: level, Step: step, Tier: tier.name})
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Price < levels[j].Price
+	})
+	return levels
+}
+
+// NearestRoundLevel returns the round level closest to price among the major
+// and mid tiers, the ones traders watch, and false for a non-positive price
+func NearestRoundLevel(price float64) (RoundLevel, bool) {
+	var nearest RoundLevel
+	found := false
+	for _, level := range RoundLevels(price) {
+		if level.Tier == "minor" {
+			continue
+		}
+		if !found || math.Abs(level.Price-price) < math.Abs(nearest.Price-price) {
+			nearest, found = level, true
+		}
+	}
+	return nearest, found
+}
+
+// hasRoundLevel reports whether levels already holds price, compared to a
+// small fraction of step to absorb floating point error
+func hasRoundLevel(levels []RoundLevel, price, step float64) bool {
+	for _, level := range levels {
+		if math.Abs(level.Price-price) < step*1e-6 {
+			return true
+		}
+	}
+	return false
+}
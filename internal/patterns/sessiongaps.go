@@ -0,0 +1,98 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+)
+
+// DefaultMinSessionGap is the smallest move from one session's close to the
+// next session's open counted as a gap
+const DefaultMinSessionGap = 0.002
+
+// sessionBreaks returns the index of the first bar of every session after
+// the first: bars more than one and a half bar intervals after the previous
+// one, such as the Monday open of a series without weekend bars. Continuous
+// series have none.
+func sessionBreaks(bts *types.BTCTimeSeries) []int {
+	interval := timeseries.DetectFrequency(bts).Interval
+	var breaks []int
+	for i := 1; i < len(bts.Data); i++ {
+		if bts.Data[i].Timestamp.Sub(bts.Data[i-1].Timestamp) > interval*3/2 {
+			breaks = append(breaks, i)
+		}
+	}
+	return breaks
+}
+
+// AnalyzeSessionGaps finds the gaps of at least minSize between the close
+// of a session and the open of the next in a series with trading breaks,
+// e.g. CME Bitcoin futures, and whether later bars traded back to the close
+// to fill them. Unfilled gaps are listed nearest the latest close first.
+// Returns nil for a continuous series.
+func AnalyzeSessionGaps(bts *types.BTCTimeSeries, minSize float64) *types.SessionGapStats {
+	if len(bts.Data) < 2 {
+		return nil
+	}
+	breaks := sessionBreaks(bts)
+	if len(breaks) == 0 {
+		return nil
+	}
+
+	stats := &types.SessionGapStats{Sessions: len(breaks) + 1}
+	var hours []float64
+	inSession := 0
+	for n, start := range breaks {
+		prev, bar := bts.Data[start-1], bts.Data[start]
+		open := bar.Open
+		if open <= 0 {
+			open = bar.Close
+		}
+		if prev.Close <= 0 || math.Abs(open/prev.Close-1) < minSize {
+			continue
+		}
+		gap := types.SessionGap{Closed: prev.Timestamp, Opened: bar.Timestamp, Close: prev.Close, Open: open, Size: open/prev.Close - 1}
+		if gap.Size > 0 {
+			stats.Up++
+		} else {
+			stats.Down++
+		}
+
+		end := len(bts.Data)
+		if n+1 < len(breaks) {
+			end = breaks[n+1]
+		}
+		for i := start; i < len(bts.Data); i++ {
+			later := bts.Data[i]
+			if (gap.Size > 0 && later.Low <= gap.Close) || (gap.Size < 0 && later.High >= gap.Close) {
+				gap.Filled, gap.FilledAt = true, later.Timestamp
+				gap.HoursToFill = later.Timestamp.Sub(bar.Timestamp).Hours()
+				hours = append(hours, gap.HoursToFill)
+				stats.Filled++
+				if i < end {
+					inSession++
+				}
+				break
+			}
+		}
+		stats.Gaps = append(stats.Gaps, gap)
+		if !gap.Filled {
+			stats.Unfilled = append(stats.Unfilled, gap)
+		}
+	}
+
+	if len(stats.Gaps) > 0 {
+		stats.FillRate = float64(stats.Filled) / float64(len(stats.Gaps))
+		stats.FilledInSession = float64(inSession) / float64(len(stats.Gaps))
+	}
+	if len(hours) > 0 {
+		stats.MedianHoursToFill = statistics.Quantile(hours, 0.5)
+	}
+	current := bts.Data[len(bts.Data)-1].Close
+	sort.SliceStable(stats.Unfilled, func(i, j int) bool {
+		return math.Abs(stats.Unfilled[i].Close-current) < math.Abs(stats.Unfilled[j].Close-current)
+	})
+	return stats
+}
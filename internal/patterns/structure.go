@@ -0,0 +1,107 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/types"
+)
+
+// AnalyzeMarketStructure labels ZigZag swings as higher/lower highs and lows
+// and replays the series to find closes through the latest confirmed swing
+// levels. Breaks in the direction of the bias are BOS events, breaks against
+// it are CHOCH events that flip the bias.
+func AnalyzeMarketStructure(bts *types.BTCTimeSeries, threshold float64) types.MarketStructure {
+	swings := FindZigZagSwings(bts, threshold)
+	structure := types.MarketStructure{
+		Swings: swings,
+		Labels: labelSwings(swings),
+		Bias:   "neutral",
+	}
+
+	// Swings only become known once the reversal confirming them has happened
+	confirmed := make([]int, len(swings))
+	for i, swing := range swings {
+		confirmed[i] = swingConfirmation(bts, swing, threshold)
+	}
+
+	next := 0
+	var lastHigh, lastLow *types.SwingPoint
+	for i, bar := range bts.Data {
+		for next < len(swings) && confirmed[next] <= i {
+			swing := swings[next]
+			if swing.Type == "high" {
+				lastHigh = &swing
+			} else {
+				lastLow = &swing
+			}
+			next++
+		}
+
+		if lastHigh != nil && bar.Close > lastHigh.Price {
+			structure.Events = append(structure.Events, structureBreak(bar, i, "bullish", lastHigh.Price, structure.Bias))
+			structure.Bias = "bullish"
+			lastHigh = nil
+		} else if lastLow != nil && bar.Close < lastLow.Price {
+			structure.Events = append(structure.Events, structureBreak(bar, i, "bearish", lastLow.Price, structure.Bias))
+			structure.Bias = "bearish"
+			lastLow = nil
+		}
+	}
+
+	return structure
+}
+
+// labelSwings compares every swing with the previous swing of the same type
+func labelSwings(swings []types.SwingPoint) []string {
+	labels := make([]string, len(swings))
+	var prevHigh, prevLow *types.SwingPoint
+
+	for i := range swings {
+		swing := &swings[i]
+		if swing.Type == "high" {
+			if prevHigh != nil {
+				labels[i] = "LH"
+				if swing.Price > prevHigh.Price {
+					labels[i] = "HH"
+				}
+			}
+			prevHigh = swing
+		} else {
+			if prevLow != nil {
+				labels[i] = "LL"
+				if swing.Price > prevLow.Price {
+					labels[i] = "HL"
+				}
+			}
+			prevLow = swing
+		}
+	}
+
+	return labels
+}
+
+// swingConfirmation returns the index of the bar that confirmed the swing
+func swingConfirmation(bts *types.BTCTimeSeries, swing types.SwingPoint, threshold float64) int {
+	for i := swing.Index + 1; i < len(bts.Data); i++ {
+		if swing.Type == "high" && bts.Data[i].Low <= swing.Price*(1-threshold) {
+			return i
+		}
+		if swing.Type == "low" && bts.Data[i].High >= swing.Price*(1+threshold) {
+			return i
+		}
+	}
+	return len(bts.Data)
+}
+
+// structureBreak classifies a break as BOS or CHOCH relative to the prior bias
+func structureBreak(bar types.BTCPrice, index int, direction string, level float64, bias string) types.StructureEvent {
+	eventType := "BOS"
+	if bias != "neutral" && bias != direction {
+		eventType = "CHOCH"
+	}
+	return types.StructureEvent{
+		Index:     index,
+		Timestamp: bar.Timestamp,
+		Type:      eventType,
+		Direction: direction,
+		Level:     level,
+	}
+}
@@ -0,0 +1,67 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/types"
+)
+
+// DefaultZigZagThreshold is the minimum reversal used to confirm a swing
+const DefaultZigZagThreshold = 0.05
+
+// FindZigZagSwings returns the alternating swing highs and lows of the series.
+// A swing is confirmed once price reverses from it by at least threshold
+// (e.g. 0.05 for 5%); the final, still developing leg is not included.
+func FindZigZagSwings(bts *types.BTCTimeSeries, threshold float64) []types.SwingPoint {
+	var swings []types.SwingPoint
+	if len(bts.Data) < 2 || threshold <= 0 {
+		return swings
+	}
+
+	swing := func(i int, kind string) types.SwingPoint {
+		price := bts.Data[i].High
+		if kind == "low" {
+			price = bts.Data[i].Low
+		}
+		return types.SwingPoint{Index: i, Timestamp: bts.Data[i].Timestamp, Price: price, Type: kind}
+	}
+
+	// direction is 1 while looking for a swing high, -1 for a swing low
+	direction := 0
+	highIdx, lowIdx := 0, 0
+
+	for i := 1; i < len(bts.Data); i++ {
+		bar := bts.Data[i]
+
+		switch direction {
+		case 0:
+			if bar.High > bts.Data[highIdx].High {
+				highIdx = i
+			}
+			if bar.Low < bts.Data[lowIdx].Low {
+				lowIdx = i
+			}
+			if bar.Low <= bts.Data[highIdx].High*(1-threshold) && highIdx < i {
+				swings = append(swings, swing(highIdx, "high"))
+				direction, lowIdx = -1, i
+			} else if bar.High >= bts.Data[lowIdx].Low*(1+threshold) && lowIdx < i {
+				swings = append(swings, swing(lowIdx, "low"))
+				direction, highIdx = 1, i
+			}
+		case 1:
+			if bar.High > bts.Data[highIdx].High {
+				highIdx = i
+			} else if bar.Low <= bts.Data[highIdx].High*(1-threshold) {
+				swings = append(swings, swing(highIdx, "high"))
+				direction, lowIdx = -1, i
+			}
+		case -1:
+			if bar.Low < bts.Data[lowIdx].Low {
+				lowIdx = i
+			} else if bar.High >= bts.Data[lowIdx].Low*(1+threshold) {
+				swings = append(swings, swing(lowIdx, "low"))
+				direction, highIdx = 1, i
+			}
+		}
+	}
+
+	return swings
+}
@@ -0,0 +1,301 @@
+package patterns
+
+import (
+	"btc-analyzer/internal/types"
+	"sort"
+)
+
+// wyckoffMaxBreakoutBars is how many of the latest bars may lie outside the range
+const wyckoffMaxBreakoutBars = 10
+
+// DetectWyckoff identifies the latest trading range (closes within maxWidth of
+// each other for at least minBars bars) and labels its Wyckoff schematic,
+// phase and events. Returns nil when no trading range is found.
+func DetectWyckoff(bts *types.BTCTimeSeries, maxWidth float64, minBars int) *types.WyckoffAnalysis {
+	start, end, ok := findTradingRange(bts, maxWidth, minBars)
+	if !ok {
+		return nil
+	}
+
+	rangeBars := bts.Data[start : end+1]
+	avgVolume := 0.0
+	for _, bar := range rangeBars {
+		avgVolume += bar.Volume
+	}
+	avgVolume /= float64(len(rangeBars))
+
+	// Support and resistance are set while the range is established, so
+	// later probes beyond them show up as springs and upthrusts
+	established := rangeBars[:len(rangeBars)*2/3]
+	support, resistance := established[0].Low, established[0].High
+	for _, bar := range established {
+		if bar.Low < support {
+			support = bar.Low
+		}
+		if bar.High > resistance {
+			resistance = bar.High
+		}
+	}
+
+	wa := &types.WyckoffAnalysis{
+		RangeStart:        start,
+		RangeEnd:          end,
+		Support:           support,
+		Resistance:        resistance,
+		VolumeTrend:       rangeVolumeTrend(rangeBars),
+		UpDownVolumeRatio: upDownVolumeRatio(rangeBars),
+	}
+
+	// Climaxes and automatic rally/reaction in the first quarter of the range
+	opening := start + len(rangeBars)/4
+	lowIdx, highIdx := start, start
+	for i := start; i <= opening; i++ {
+		if bts.Data[i].Low < bts.Data[lowIdx].Low {
+			lowIdx = i
+		}
+		if bts.Data[i].High > bts.Data[highIdx].High {
+			highIdx = i
+		}
+	}
+
+	// Springs and upthrusts: probes beyond the range reclaimed within three
+	// bars. Consecutive probes count once, at their most extreme bar.
+	var springs, upthrusts []types.WyckoffEvent
+	for i := start + len(established); i < len(bts.Data); i++ {
+		bar := bts.Data[i]
+		if bar.Low < support && reclaimed(bts, i, func(close float64) bool { return close > support }) {
+			springs = addProbe(springs, wyckoffEvent(bts, i, "Spring", bar.Low), func(a, b float64) bool { return a < b })
+		}
+		if bar.High > resistance && reclaimed(bts, i, func(close float64) bool { return close < resistance }) {
+			upthrusts = addProbe(upthrusts, wyckoffEvent(bts, i, "UT", bar.High), func(a, b float64) bool { return a > b })
+		}
+	}
+
+	// Signs of strength/weakness: high-volume closes beyond the range
+	var sos, sow []types.WyckoffEvent
+	for i := start; i < len(bts.Data); i++ {
+		bar := bts.Data[i]
+		if bar.Volume < avgVolume*1.5 {
+			continue
+		}
+		if bar.Close > resistance {
+			sos = append(sos, wyckoffEvent(bts, i, "SOS", bar.Close))
+		} else if bar.Close < support {
+			sow = append(sow, wyckoffEvent(bts, i, "SOW", bar.Close))
+		}
+	}
+
+	wa.Schematic = wyckoffSchematic(bts, start, minBars, len(springs), len(upthrusts))
+
+	latest := bts.Data[len(bts.Data)-1].Close
+	switch wa.Schematic {
+	case "accumulation":
+		wa.Events = append(wa.Events, climaxEvent(bts, lowIdx, "SC", "Low", bts.Data[lowIdx].Low, avgVolume))
+		if ar := extremeAfter(bts, lowIdx, opening, true); ar > lowIdx {
+			wa.Events = append(wa.Events, wyckoffEvent(bts, ar, "AR", bts.Data[ar].High))
+		}
+		wa.Events = append(wa.Events, springs...)
+		wa.Events = append(wa.Events, sos...)
+		wa.Phase = wyckoffPhase(latest > resistance, len(springs) > 0, len(sos) > 0, len(rangeBars), minBars)
+	case "distribution":
+		wa.Events = append(wa.Events, climaxEvent(bts, highIdx, "BC", "High", bts.Data[highIdx].High, avgVolume))
+		if ar := extremeAfter(bts, highIdx, opening, false); ar > highIdx {
+			wa.Events = append(wa.Events, wyckoffEvent(bts, ar, "AR", bts.Data[ar].Low))
+		}
+		wa.Events = append(wa.Events, upthrusts...)
+		wa.Events = append(wa.Events, sow...)
+		wa.Phase = wyckoffPhase(latest < support, len(upthrusts) > 0, len(sow) > 0, len(rangeBars), minBars)
+	default:
+		wa.Events = append(wa.Events, springs...)
+		wa.Events = append(wa.Events, upthrusts...)
+		wa.Phase = wyckoffPhase(false, false, false, len(rangeBars), minBars)
+	}
+
+	sort.SliceStable(wa.Events, func(i, j int) bool {
+		return wa.Events[i].Index < wa.Events[j].Index
+	})
+
+	return wa
+}
+
+// WyckoffPhaseDescription explains a phase of the given schematic
+func WyckoffPhaseDescription(schematic, phase string) string {
+	descriptions := map[string]string{
+		"A": "stopping the prior trend",
+		"B": "building a cause inside the range",
+		"C": "testing supply/demand beyond the range",
+		"D": "trending toward the range edge",
+	}
+	if phase == "E" {
+		if schematic == "distribution" {
+			return "markdown below the range"
+		}
+		return "markup above the range"
+	}
+	return descriptions[phase]
+}
+
+// findTradingRange returns the longest run of bars ending within the last few
+// bars whose closes stay within maxWidth of each other
+func findTradingRange(bts *types.BTCTimeSeries, maxWidth float64, minBars int) (int, int, bool) {
+	bestStart, bestEnd := 0, -1
+
+	for offset := 0; offset <= wyckoffMaxBreakoutBars && offset < len(bts.Data); offset++ {
+		end := len(bts.Data) - 1 - offset
+		low, high := bts.Data[end].Close, bts.Data[end].Close
+		start := end
+		for start > 0 {
+			close := bts.Data[start-1].Close
+			newLow, newHigh := low, high
+			if close < newLow {
+				newLow = close
+			}
+			if close > newHigh {
+				newHigh = close
+			}
+			if (newHigh-newLow)/newLow > maxWidth {
+				break
+			}
+			low, high = newLow, newHigh
+			start--
+		}
+		if end-start > bestEnd-bestStart {
+			bestStart, bestEnd = start, end
+		}
+	}
+
+	return bestStart, bestEnd, bestEnd-bestStart+1 >= minBars
+}
+
+// wyckoffSchematic infers accumulation after a decline and distribution after
+// an advance, falling back to the balance of springs and upthrusts
+func wyckoffSchematic(bts *types.BTCTimeSeries, start, lookback, springs, upthrusts int) string {
+	if start > 0 {
+		before := start - lookback
+		if before < 0 {
+			before = 0
+		}
+		change := (bts.Data[start].Close - bts.Data[before].Close) / bts.Data[before].Close
+		if change < -0.05 {
+			return "accumulation"
+		}
+		if change > 0.05 {
+			return "distribution"
+		}
+	}
+	if springs > upthrusts {
+		return "accumulation"
+	}
+	if upthrusts > springs {
+		return "distribution"
+	}
+	return "undetermined"
+}
+
+// wyckoffPhase maps the events seen so far to a phase letter
+func wyckoffPhase(brokeOut, tested, strength bool, rangeLength, minBars int) string {
+	switch {
+	case brokeOut:
+		return "E"
+	case tested && strength:
+		return "D"
+	case tested:
+		return "C"
+	case rangeLength >= 2*minBars:
+		return "B"
+	}
+	return "A"
+}
+
+// wyckoffEvent creates an event for bar i
+func wyckoffEvent(bts *types.BTCTimeSeries, i int, name string, price float64) types.WyckoffEvent {
+	return types.WyckoffEvent{Index: i, Timestamp: bts.Data[i].Timestamp, Price: price, Event: name, Volume: bts.Data[i].Volume}
+}
+
+// climaxEvent labels the extreme bar as a climax only when it came on heavy volume
+func climaxEvent(bts *types.BTCTimeSeries, i int, climax, fallback string, price, avgVolume float64) types.WyckoffEvent {
+	if bts.Data[i].Volume < avgVolume*1.5 {
+		climax = fallback
+	}
+	return wyckoffEvent(bts, i, climax, price)
+}
+
+// extremeAfter returns the highest high (or lowest low) between from and to
+func extremeAfter(bts *types.BTCTimeSeries, from, to int, highest bool) int {
+	best := from
+	for i := from + 1; i <= to && i < len(bts.Data); i++ {
+		if highest && bts.Data[i].High > bts.Data[best].High {
+			best = i
+		}
+		if !highest && bts.Data[i].Low < bts.Data[best].Low {
+			best = i
+		}
+	}
+	return best
+}
+
+// addProbe appends a probe event, or replaces the previous one when it was
+// within three bars and the new probe went further
+func addProbe(probes []types.WyckoffEvent, probe types.WyckoffEvent, further func(a, b float64) bool) []types.WyckoffEvent {
+	if n := len(probes); n > 0 && probe.Index-probes[n-1].Index <= 3 {
+		if further(probe.Price, probes[n-1].Price) {
+			probes[n-1] = probe
+		}
+		return probes
+	}
+	return append(probes, probe)
+}
+
+// reclaimed reports whether a close within three bars of i satisfies inside
+func reclaimed(bts *types.BTCTimeSeries, i int, inside func(float64) bool) bool {
+	for j := i; j <= i+3 && j < len(bts.Data); j++ {
+		if inside(bts.Data[j].Close) {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeVolumeTrend compares the average volume of the two halves of the range
+func rangeVolumeTrend(bars []types.BTCPrice) string {
+	half := len(bars) / 2
+	first, second := 0.0, 0.0
+	for i, bar := range bars {
+		if i < half {
+			first += bar.Volume
+		} else {
+			second += bar.Volume
+		}
+	}
+	first /= float64(half)
+	second /= float64(len(bars) - half)
+
+	switch {
+	case first == 0:
+		return "flat"
+	case second/first < 0.85:
+		return "declining"
+	case second/first > 1.15:
+		return "rising"
+	}
+	return "flat"
+}
+
+// upDownVolumeRatio returns the average volume of up bars over that of down bars
+func upDownVolumeRatio(bars []types.BTCPrice) float64 {
+	upVolume, downVolume := 0.0, 0.0
+	upBars, downBars := 0, 0
+	for _, bar := range bars {
+		if bar.Close > bar.Open {
+			upVolume += bar.Volume
+			upBars++
+		} else if bar.Close < bar.Open {
+			downVolume += bar.Volume
+			downBars++
+		}
+	}
+	if upBars == 0 || downBars == 0 || downVolume == 0 {
+		return 0
+	}
+	return (upVolume / float64(upBars)) / (downVolume / float64(downBars))
+}
@@ -0,0 +1,248 @@
+// Package plugins lets users add indicators without changing the analyzer:
+// Go indicators compiled in with Register, and script indicators written in
+// the expr language and loaded from a plugins directory.
+package plugins
+
+import (
+	"btc-analyzer/internal/graph"
+	"btc-analyzer/internal/types"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Indicator computes one value per bar of the series in ctx. Bars without a
+// value, such as the warm-up, are NaN and may only come before the first value.
+type Indicator interface {
+	Name() string
+	Compute(ctx *Context) ([]float64, error)
+}
+
+// Signaler is implemented by indicators with trading rules. It returns, per
+// bar, whether the buy and the sell rule hold; a nil slice means no rule.
+type Signaler interface {
+	Rules(ctx *Context, values []float64) (buy, sell []bool, err error)
+}
+
+// Dependent is implemented by indicators that use the outputs of other
+// indicators. They are computed after their dependencies.
+type Dependent interface {
+	Dependencies() []string
+}
+
+// Context gives an indicator the series being analyzed and the outputs of
+// the indicators it depends on
+type Context struct {
+	Series *types.BTCTimeSeries
+	values map[string][]float64
+}
+
+// Value returns the output of a dependency
+func (ctx *Context) Value(name string) ([]float64, bool) {
+	values, ok := ctx.values[name]
+	return values, ok
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Indicator)
+)
+
+// Register makes a Go indicator available to every analysis. It is meant to
+// be called from an init function and panics on a duplicate name.
+func Register(ind Indicator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[ind.Name()]; dup {
+		panic(fmt.Sprintf("plugins: indicator %q registered twice", ind.Name()))
+	}
+	registry[ind.Name()] = ind
+}
+
+// Registered returns the registered Go indicators, sorted by name
+func Registered() []Indicator {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	indicators := make([]Indicator, 0, len(registry))
+	for _, ind := range registry {
+		indicators = append(indicators, ind)
+	}
+	sort.Slice(indicators, func(i, k int) bool { return indicators[i].Name() < indicators[k].Name() })
+	return indicators
+}
+
+// evaluated is an indicator's report entry and its output on every bar
+type evaluated struct {
+	result types.CustomIndicator
+	values []float64
+}
+
+// Evaluate computes the requested indicators, or all of them when requested
+// is empty, and the indicators they depend on. Independent indicators run in
+// parallel. An indicator that fails, or whose dependency fails, is left out
+// and its error joined into the returned one. Results keep the order of
+// indicators.
+func Evaluate(bts *types.BTCTimeSeries, indicators []Indicator, requested []string) ([]types.CustomIndicator, error) {
+	var errs []error
+	g := graph.New()
+	for _, ind := range indicators {
+		ind := ind
+		var deps []string
+		if dependent, ok := ind.(Dependent); ok {
+			deps = dependent.Dependencies()
+		}
+		err := g.Add(ind.Name(), deps, func(values map[string]interface{}) (interface{}, error) {
+			ctx := &Context{Series: bts, values: make(map[string][]float64, len(values))}
+			for name, value := range values {
+				ctx.values[name] = value.(evaluated).values
+			}
+			return evaluate(ctx, ind)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("indicator %s: %w", ind.Name(), err))
+		}
+	}
+
+	targets := requested
+	if len(targets) == 0 {
+		targets = g.Names()
+	}
+	var valid []string
+	for _, name := range targets {
+		if _, err := g.Plan(name); err != nil {
+			errs = append(errs, fmt.Errorf("indicator %s: %w", name, err))
+			continue
+		}
+		valid = append(valid, name)
+	}
+
+	plan, err := g.Plan(valid...)
+	if err != nil {
+		return nil, err
+	}
+	evaluation, err := g.Evaluate(valid...)
+	if err != nil {
+		return nil, err
+	}
+	evaluation.GetAll(plan...)
+
+	planned := make(map[string]bool, len(plan))
+	for _, name := range plan {
+		planned[name] = true
+	}
+	var results []types.CustomIndicator
+	for _, name := range g.Names() {
+		if !planned[name] {
+			continue
+		}
+		value, err := evaluation.Get(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("indicator %s: %w", name, err))
+			continue
+		}
+		results = append(results, value.(evaluated).result)
+	}
+	return results, errors.Join(errs...)
+}
+
+// DropBars returns indicators evaluated on a series as evaluated on the
+// series without its first n bars, which only warmed them up: offsets and
+// rule bars count from bar n and values before it are dropped
+func DropBars(results []types.CustomIndicator, n int) []types.CustomIndicator {
+	if n == 0 {
+		return results
+	}
+	shift := func(bars []int) []int {
+		var shifted []int
+		for _, bar := range bars {
+			if bar >= n {
+				shifted = append(shifted, bar-n)
+			}
+		}
+		return shifted
+	}
+	dropped := make([]types.CustomIndicator, len(results))
+	for i, result := range results {
+		if drop := n - result.Offset; drop > 0 {
+			result.Values = result.Values[min(drop, len(result.Values)):]
+		}
+		result.Offset = max(result.Offset-n, 0)
+		result.Buys, result.Sells = shift(result.Buys), shift(result.Sells)
+		dropped[i] = result
+	}
+	return dropped
+}
+
+// evaluate computes one indicator and its signal
+func evaluate(ctx *Context, ind Indicator) (evaluated, error) {
+	values, err := ind.Compute(ctx)
+	if err != nil {
+		return evaluated{}, err
+	}
+	if len(values) != len(ctx.Series.Data) {
+		return evaluated{}, fmt.Errorf("got %d values for %d bars", len(values), len(ctx.Series.Data))
+	}
+
+	offset := 0
+	for offset < len(values) && math.IsNaN(values[offset]) {
+		offset++
+	}
+	if offset == len(values) {
+		return evaluated{}, fmt.Errorf("no bar has a value")
+	}
+	for i := offset; i < len(values); i++ {
+		if math.IsNaN(values[i]) || math.IsInf(values[i], 0) {
+			return evaluated{}, fmt.Errorf("undefined value at bar %d (wrap the formula in nz() to use 0)", i)
+		}
+	}
+
+	result := types.CustomIndicator{
+		Name:   ind.Name(),
+		Source: "go",
+		Offset: offset,
+		Values: append([]float64(nil), values[offset:]...),
+	}
+	if script, ok := ind.(*Script); ok {
+		result.Source = script.def.Formula
+	}
+
+	if signaler, ok := ind.(Signaler); ok {
+		buy, sell, err := signaler.Rules(ctx, values)
+		if err != nil {
+			return evaluated{}, err
+		}
+		if buy != nil || sell != nil {
+			result.Buys = risingEdges(buy)
+			result.Sells = risingEdges(sell)
+			result.Signal = ruleSignal(buy, sell)
+		}
+	}
+	return evaluated{result: result, values: values}, nil
+}
+
+// risingEdges returns the bars where a rule becomes true
+func risingEdges(rule []bool) []int {
+	var bars []int
+	for i, holds := range rule {
+		if holds && (i == 0 || !rule[i-1]) {
+			bars = append(bars, i)
+		}
+	}
+	return bars
+}
+
+// ruleSignal turns the rules at the latest bar into a trading signal
+func ruleSignal(buy, sell []bool) string {
+	last := func(rule []bool) bool { return len(rule) > 0 && rule[len(rule)-1] }
+	switch {
+	case last(buy) && last(sell):
+		return "HOLD - Buy and sell rules both hold"
+	case last(buy):
+		return "BUY - Buy rule holds"
+	case last(sell):
+		return "SELL - Sell rule holds"
+	}
+	return "HOLD - No rule holds"
+}
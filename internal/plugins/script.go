@@ -0,0 +1,186 @@
+package plugins
+
+import (
+	"btc-analyzer/internal/expr"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Definition is a script indicator as stored in a plugins directory file
+type Definition struct {
+	Name    string `json:"name"`
+	Formula string `json:"formula"`        // value of the indicator on each bar
+	Buy     string `json:"buy,omitempty"`  // optional buy rule
+	Sell    string `json:"sell,omitempty"` // optional sell rule
+}
+
+// Script is an indicator defined by expr formulas. Formulas can use the
+// open, high, low, close and volume series and other indicators by name,
+// which become its dependencies; the rules can also use the indicator itself.
+type Script struct {
+	def                Definition
+	formula, buy, sell *expr.Program
+}
+
+// baseVars are the bar series every script can use
+var baseVars = []string{"open", "high", "low", "close", "volume"}
+
+// Compile parses the formulas of def
+func Compile(def Definition) (*Script, error) {
+	if !expr.IsIdent(def.Name) {
+		return nil, fmt.Errorf("invalid indicator name %q: use letters, digits and _", def.Name)
+	}
+	for _, name := range baseVars {
+		if def.Name == name {
+			return nil, fmt.Errorf("indicator name %q is reserved", def.Name)
+		}
+	}
+	if def.Formula == "" {
+		return nil, fmt.Errorf("indicator %s has no formula", def.Name)
+	}
+
+	s := &Script{def: def}
+	for _, part := range []struct {
+		label, source string
+		program       **expr.Program
+	}{
+		{"formula", def.Formula, &s.formula},
+		{"buy rule", def.Buy, &s.buy},
+		{"sell rule", def.Sell, &s.sell},
+	} {
+		if part.source == "" {
+			continue
+		}
+		program, err := expr.Parse(part.source)
+		if err != nil {
+			return nil, fmt.Errorf("indicator %s: invalid %s: %w", def.Name, part.label, err)
+		}
+		*part.program = program
+	}
+	for _, name := range s.formula.Vars() {
+		if name == def.Name {
+			return nil, fmt.Errorf("indicator %s: formula refers to itself", def.Name)
+		}
+	}
+	return s, nil
+}
+
+// LoadDir compiles the script indicators of every *.json file in dir, in
+// file name order. Each file holds one Definition.
+func LoadDir(dir string) ([]*Script, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plugins: %w", err)
+	}
+	sort.Strings(files)
+
+	var scripts []*Script
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plugin: %w", err)
+		}
+		var def Definition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return nil, fmt.Errorf("failed to decode plugin %s: %w", file, err)
+		}
+		script, err := Compile(def)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plugin %s: %w", file, err)
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// Name returns the indicator name
+func (s *Script) Name() string {
+	return s.def.Name
+}
+
+// Definition returns the definition the script was compiled from
+func (s *Script) Definition() Definition {
+	return s.def
+}
+
+// Dependencies returns the indicators the formula and rules refer to
+func (s *Script) Dependencies() []string {
+	seen := map[string]bool{s.def.Name: true}
+	for _, name := range baseVars {
+		seen[name] = true
+	}
+	var deps []string
+	for _, program := range []*expr.Program{s.formula, s.buy, s.sell} {
+		if program == nil {
+			continue
+		}
+		for _, name := range program.Vars() {
+			if !seen[name] {
+				seen[name] = true
+				deps = append(deps, name)
+			}
+		}
+	}
+	return deps
+}
+
+// Compute evaluates the formula
+func (s *Script) Compute(ctx *Context) ([]float64, error) {
+	return s.formula.Eval(env(ctx, nil))
+}
+
+// Rules evaluates the buy and sell rules, with the indicator's own values
+// available under its name
+func (s *Script) Rules(ctx *Context, values []float64) (buy, sell []bool, err error) {
+	e := env(ctx, map[string][]float64{s.def.Name: values})
+	if buy, err = evalRule(s.buy, e); err != nil {
+		return nil, nil, fmt.Errorf("buy rule: %w", err)
+	}
+	if sell, err = evalRule(s.sell, e); err != nil {
+		return nil, nil, fmt.Errorf("sell rule: %w", err)
+	}
+	return buy, sell, nil
+}
+
+// evalRule evaluates a rule to whether it holds on each bar, or nil without a rule
+func evalRule(rule *expr.Program, e *expr.Env) ([]bool, error) {
+	if rule == nil {
+		return nil, nil
+	}
+	values, err := rule.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	holds := make([]bool, len(values))
+	for i, v := range values {
+		holds[i] = expr.Truthy(v)
+	}
+	return holds, nil
+}
+
+// env returns the variables of a script: the bar series, the outputs of its
+// dependencies and extra
+func env(ctx *Context, extra map[string][]float64) *expr.Env {
+	n := len(ctx.Series.Data)
+	vars := make(map[string][]float64, len(baseVars)+len(ctx.values)+len(extra))
+	for _, name := range baseVars {
+		vars[name] = make([]float64, n)
+	}
+	for i, p := range ctx.Series.Data {
+		vars["open"][i] = p.Open
+		vars["high"][i] = p.High
+		vars["low"][i] = p.Low
+		vars["close"][i] = p.Close
+		vars["volume"][i] = p.Volume
+	}
+	for name, values := range ctx.values {
+		vars[name] = values
+	}
+	for name, values := range extra {
+		vars[name] = values
+	}
+	return &expr.Env{Len: n, Vars: vars}
+}
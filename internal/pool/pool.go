@@ -0,0 +1,83 @@
+// Package pool runs independent jobs on a bounded number of goroutines,
+// isolating the failure of each job from the others
+package pool
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultWorkers is the number of jobs run at the same time when the caller
+// does not choose. Requests are paced per API by the rate limiter anyway, so
+// more workers mostly add queueing.
+const DefaultWorkers = 4
+
+// Progress describes one finished job
+type Progress struct {
+	Index int   // index of the job
+	Done  int   // jobs finished so far, including this one
+	Total int   // number of jobs
+	Err   error // error of the job, nil on success
+}
+
+// Run calls job for every index below n on at most workers goroutines and
+// returns the error of each job by index. A panicking job is reported as an
+// error instead of stopping the others. progress, when set, is called after
+// every job, one call at a time.
+func Run(n, workers int, job func(i int) error, progress func(Progress)) []error {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	if workers > n {
+		workers = n
+	}
+
+	errs := make([]error, n)
+	indexes := make(chan int)
+	var mu sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				err := call(job, i)
+				mu.Lock()
+				errs[i] = err
+				done++
+				if progress != nil {
+					progress(Progress{Index: i, Done: done, Total: n, Err: err})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return errs
+}
+
+// call runs one job and turns a panic into an error
+func call(job func(i int) error, i int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job(i)
+}
+
+// Failed counts the non-nil errors
+func Failed(errs []error) int {
+	n := 0
+	for _, err := range errs {
+		if err != nil {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+package publish
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PagesBranch is the branch GitHub Pages serves
+const PagesBranch = "gh-pages"
+
+// GitPages commits snapshots to the gh-pages branch of a git repository and
+// pushes them, using the git command line and its configured credentials
+type GitPages struct {
+	repo string
+}
+
+// NewGitPages returns a publisher to the gh-pages branch of repo, a local
+// path or a remote URL
+func NewGitPages(repo string) (*GitPages, error) {
+	if repo == "" {
+		return nil, fmt.Errorf("git repository is required: use gh-pages:<path or URL>")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("publishing to %s requires git: %w", PagesBranch, err)
+	}
+	// The clone runs in a temporary directory: resolve local paths first
+	if _, err := os.Stat(repo); err == nil {
+		if abs, err := filepath.Abs(repo); err == nil {
+			repo = abs
+		}
+	}
+	return &GitPages{repo: repo}, nil
+}
+
+// String describes the target
+func (g *GitPages) String() string {
+	return PagesBranch + " of " + g.repo
+}
+
+// Publish replaces the content of the branch with files in a new commit on
+// a shallow clone and pushes it. The branch is created when missing.
+func (g *GitPages) Publish(dir string, files []string) error {
+	work, err := os.MkdirTemp("", "btc-analyzer-pages-*")
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	exists, err := g.branchExists()
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := git("", "clone", "--quiet", "--depth", "1", "--branch", PagesBranch, g.repo, work); err != nil {
+			return err
+		}
+	} else {
+		// Start the branch without history
+		if err := git("", "init", "--quiet", work); err != nil {
+			return err
+		}
+		if err := git(work, "checkout", "--quiet", "--orphan", PagesBranch); err != nil {
+			return err
+		}
+		if err := git(work, "remote", "add", "origin", g.repo); err != nil {
+			return err
+		}
+	}
+
+	if err := git(work, "rm", "-r", "--quiet", "--ignore-unmatch", "."); err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := readFile(dir, file)
+		if err != nil {
+			return err
+		}
+		for _, name := range names(file) {
+			target := filepath.Join(work, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(name), err)
+			}
+			if err := os.WriteFile(target, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+	}
+	// Serve the files as they are, without a Jekyll build
+	if err := os.WriteFile(filepath.Join(work, ".nojekyll"), nil, 0644); err != nil {
+		return fmt.Errorf("failed to write .nojekyll: %w", err)
+	}
+
+	if err := git(work, "add", "--all"); err != nil {
+		return err
+	}
+	message := "Update analysis snapshot " + time.Now().UTC().Format("2006-01-02 15:04 UTC")
+	if err := git(work, "-c", "user.name=btc-analyzer", "-c", "user.email=btc-analyzer@localhost",
+		"commit", "--quiet", "--allow-empty", "-m", message); err != nil {
+		return err
+	}
+	return git(work, "push", "--quiet", "origin", PagesBranch)
+}
+
+// branchExists reports whether the repository has a gh-pages branch
+func (g *GitPages) branchExists() (bool, error) {
+	err := git("", "ls-remote", "--exit-code", "--heads", g.repo, PagesBranch)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// git runs a git command in dir, or the current directory when dir is
+// empty, and returns its output in the error when it fails
+func git(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", subcommand(args), err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// subcommand returns the git subcommand of args, skipping -c options
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-c" {
+			i++
+			continue
+		}
+		return args[i]
+	}
+	return ""
+}
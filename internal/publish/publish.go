@@ -0,0 +1,79 @@
+// Package publish uploads the generated reports and charts to a public
+// location, an S3 bucket or the gh-pages branch of a git repository, so a
+// scheduled run keeps a read-only analysis page up to date.
+package publish
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IndexPage is the report also published as the index of the site
+const IndexPage = "btc_analysis_report.html"
+
+// Publisher uploads files of a directory to one target
+type Publisher interface {
+	// Publish uploads files, given relative to dir with forward slashes,
+	// replacing the previous snapshot
+	Publish(dir string, files []string) error
+	// String describes the target
+	String() string
+}
+
+// Parse returns the publisher of a target: s3://bucket/prefix or
+// gh-pages:<git repository>, a local path or remote URL
+func Parse(target string) (Publisher, error) {
+	switch {
+	case strings.HasPrefix(target, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(target, "s3://"), "/")
+		return NewS3(bucket, prefix)
+	case strings.HasPrefix(target, "gh-pages:"):
+		return NewGitPages(strings.TrimPrefix(target, "gh-pages:"))
+	}
+	return nil, fmt.Errorf("unknown publish target %q: use s3://bucket/prefix or gh-pages:<git repository>", target)
+}
+
+// Collect lists the files of a snapshot in dir: the HTML pages, the JSON
+// report and the charts. Exported data, logs and other files are left out.
+func Collect(dir string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{"*.html", "btc_analysis_report.json", "charts/*.png"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reports: %w", err)
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(dir, match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list reports: %w", err)
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no reports or charts in %s", dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readFile reads a snapshot file given relative to dir
+func readFile(dir, file string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return data, nil
+}
+
+// names returns the names a snapshot file is published under: its own, and
+// index.html for IndexPage so the site root shows the report
+func names(file string) []string {
+	if file == IndexPage {
+		return []string{file, "index.html"}
+	}
+	return []string{file}
+}
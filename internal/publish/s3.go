@@ -0,0 +1,190 @@
+package publish
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Environment variables of the S3 credentials and endpoint, the names the
+// AWS tools use
+const (
+	AccessKeyEnv    = "AWS_ACCESS_KEY_ID"
+	SecretKeyEnv    = "AWS_SECRET_ACCESS_KEY"
+	SessionTokenEnv = "AWS_SESSION_TOKEN"
+	RegionEnv       = "AWS_REGION"
+	EndpointEnv     = "AWS_ENDPOINT_URL" // S3-compatible endpoint, addressed path-style
+)
+
+// cacheControl lets browsers and CDNs cache a snapshot for a few minutes
+const cacheControl = "public, max-age=300"
+
+// S3 uploads snapshots to a bucket with SigV4-signed PUT requests. Objects
+// are not given an ACL; make them public with a bucket policy.
+type S3 struct {
+	bucket, prefix string
+	region         string
+	endpoint       string // empty for AWS
+	accessKey      string
+	secretKey      string
+	sessionToken   string
+	client         *http.Client
+}
+
+// NewS3 returns a publisher to bucket under prefix, with credentials and
+// region from the environment
+func NewS3(bucket, prefix string) (*S3, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required: use s3://bucket/prefix")
+	}
+	s := &S3{
+		bucket:       bucket,
+		prefix:       strings.Trim(prefix, "/"),
+		region:       os.Getenv(RegionEnv),
+		endpoint:     strings.TrimSuffix(os.Getenv(EndpointEnv), "/"),
+		accessKey:    os.Getenv(AccessKeyEnv),
+		secretKey:    os.Getenv(SecretKeyEnv),
+		sessionToken: os.Getenv(SessionTokenEnv),
+		client:       &http.Client{Timeout: time.Minute},
+	}
+	if s.region == "" {
+		s.region = "us-east-1"
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("S3 credentials missing: set %s and %s", AccessKeyEnv, SecretKeyEnv)
+	}
+	return s, nil
+}
+
+// String returns the s3:// URL of the target
+func (s *S3) String() string {
+	return "s3://" + path.Join(s.bucket, s.prefix)
+}
+
+// Publish uploads every file, overwriting the objects of the previous snapshot
+func (s *S3) Publish(dir string, files []string) error {
+	for _, file := range files {
+		data, err := readFile(dir, file)
+		if err != nil {
+			return err
+		}
+		for _, name := range names(file) {
+			if err := s.put(path.Join(s.prefix, name), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// put uploads one object
+func (s *S3) put(key string, data []byte) error {
+	objectURL := s.objectURL(key)
+	req, err := http.NewRequest(http.MethodPut, objectURL.String(), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", cacheControl)
+	s.sign(req, data, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("failed to upload %s: %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// objectURL returns the virtual-hosted AWS URL of key, or the path-style URL
+// on a custom endpoint
+func (s *S3) objectURL(key string) *url.URL {
+	if s.endpoint != "" {
+		u, err := url.Parse(s.endpoint)
+		if err == nil && u.Host != "" {
+			u.Path = "/" + s.bucket + "/" + key
+			return u
+		}
+	}
+	return &url.URL{Scheme: "https", Host: s.bucket + ".s3." + s.region + ".amazonaws.com", Path: "/" + key}
+}
+
+// sign adds the AWS Signature Version 4 headers of a request without a
+// query string to req
+func (s *S3) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	// Every header set above is signed, along with the host
+	signed := []string{"cache-control", "content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	if s.sessionToken != "" {
+		signed = append(signed, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range signed {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(value))
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// sha256Hex returns the hex-encoded SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,91 @@
+// Package ratelimit paces the requests sent to each API host with a token
+// bucket shared by every goroutine, so concurrent fetches stay within the
+// public rate limits
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limit is the sustained request rate of a host and the burst allowed above it
+type Limit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Limits are the request rates of the APIs the analyzer reads, below their
+// public limits. Hosts not listed use DefaultLimit.
+var Limits = map[string]Limit{
+	"api.binance.com":   {PerSecond: 10, Burst: 10},
+	"api.coingecko.com": {PerSecond: 0.5, Burst: 3}, // about 30 calls a minute on the public API
+	"api.kraken.com":    {PerSecond: 1, Burst: 3},
+	"www.deribit.com":   {PerSecond: 10, Burst: 10},
+}
+
+// DefaultLimit is the request rate of hosts without an entry in Limits
+var DefaultLimit = Limit{PerSecond: 5, Burst: 5}
+
+// Limiter is a token bucket
+type Limiter struct {
+	mu     sync.Mutex
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// New returns a limiter that starts with a full burst
+func New(limit Limit) *Limiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &Limiter{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// Reserve takes a token and returns how long the caller must wait before
+// using it. Waiting callers queue in the order they reserved.
+func (l *Limiter) Reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.limit.PerSecond
+	if burst := float64(l.limit.Burst); l.tokens > burst {
+		l.tokens = burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.limit.PerSecond * float64(time.Second))
+}
+
+// Wait blocks until a request may be sent
+func (l *Limiter) Wait() {
+	if delay := l.Reserve(); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+var (
+	mu       sync.Mutex
+	limiters = make(map[string]*Limiter)
+)
+
+// For returns the limiter shared by every request to host
+func For(host string) *Limiter {
+	mu.Lock()
+	defer mu.Unlock()
+	limiter, ok := limiters[host]
+	if !ok {
+		limit, known := Limits[host]
+		if !known {
+			limit = DefaultLimit
+		}
+		limiter = New(limit)
+		limiters[host] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,101 @@
+package reporter
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"path/filepath"
+	"strings"
+)
+
+// PrintStyles is the print stylesheet shared by the HTML reports: black on
+// white, no boxes split across pages, and table headers repeated on each page
+const PrintStyles = `
+        .visually-hidden { position: absolute; width: 1px; height: 1px; overflow: hidden; clip: rect(0 0 0 0); white-space: nowrap; }
+        a:focus, summary:focus { outline: 2px solid #0d6efd; outline-offset: 2px; }
+        @media print {
+            @page { margin: 15mm; }
+            body { margin: 0; font-size: 11pt; color: #000; background: #fff; }
+            .header, .section, .metric, .stat-card, .chart-container, .data-section { background: none !important; box-shadow: none !important; }
+            .section, .metric, figure, img, tr { break-inside: avoid; }
+            h1, h2, h3 { break-after: avoid; }
+            thead { display: table-header-group; }
+            .scrollable { max-height: none !important; overflow: visible !important; }
+            .no-print { display: none !important; }
+            details.explain summary { color: #000; }
+            a[href^="http"]::after { content: " (" attr(href) ")"; }
+        }`
+
+// ExpandOnPrint opens every collapsed explanation while the page prints
+const ExpandOnPrint = `<script>
+    window.addEventListener("beforeprint", function () {
+        document.querySelectorAll("details").forEach(function (d) { d.open = true; });
+    });
+</script>`
+
+// ChartAltText describes what the chart in file shows, from the analysis it
+// was drawn from, for readers who cannot see it, e.g. "RSI currently 43,
+// trending down", in the locale of msgs. Other charts are described by their
+// name and period.
+func ChartAltText(file string, result *types.AnalysisResult, msgs *i18n.Catalog) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	bts, analytics := result.Series, result.Analytics
+	title := msgs.T("alt.chart", strings.ToUpper(name[:1])+strings.ReplaceAll(name[1:], "_", " "))
+	if len(bts.Data) < 2 {
+		return title
+	}
+	d := bts.Denomination
+	first, latest := bts.Data[0], bts.Data[len(bts.Data)-1]
+	parts := []string{msgs.T("alt.period", title, bts.Symbol,
+		first.Timestamp.Format("Jan 2, 2006"), latest.Timestamp.Format("Jan 2, 2006"))}
+
+	switch name {
+	case "technical_indicators":
+		parts = append(parts, msgs.T("alt.price_change", denom.Price(d, latest.Close), numfmt.SignedPct(latest.Close/first.Close-1)))
+		if rsi := analytics.RSI; len(rsi) > 0 {
+			parts = append(parts, msgs.T("alt.rsi", rsi[len(rsi)-1], msgs.T(trendKey(rsi))))
+		}
+		if hist := analytics.MACD.Histogram; len(hist) > 0 {
+			key := "alt.macd.above"
+			if hist[len(hist)-1] < 0 {
+				key = "alt.macd.below"
+			}
+			parts = append(parts, msgs.T(key))
+		}
+	case "support_resistance":
+		sr := analytics.SupportResistance
+		parts = append(parts, msgs.T("alt.levels",
+			denom.Price(d, latest.Close), len(sr.SupportLevels), len(sr.ResistanceLevels)))
+	case "elliott_waves":
+		if ew := analytics.ElliottWaves; ew != nil {
+			parts = append(parts, msgs.T("alt.elliott", len(ew.Swings), ew.Threshold*100, len(ew.Counts)))
+		}
+	case "harmonic_patterns":
+		parts = append(parts, msgs.T("alt.harmonics", len(analytics.Harmonics)))
+	case "market_heat":
+		if heat := analytics.MarketHeat; heat != nil {
+			parts = append(parts, msgs.T("alt.heat", heat.Latest, heat.Zone))
+		}
+	case "stock_to_flow":
+		if s2f := analytics.StockToFlow; s2f != nil {
+			parts = append(parts, msgs.T("alt.stock_to_flow", denom.Price(d, latest.Close), denom.Price(d, s2f.ModelPrice)))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// trendKey is the message describing the direction of the last five values
+// of a series
+func trendKey(values []float64) string {
+	if len(values) < 6 {
+		return "alt.trend.short"
+	}
+	switch change := values[len(values)-1] - values[len(values)-6]; {
+	case change >= 2:
+		return "alt.trend.up"
+	case change <= -2:
+		return "alt.trend.down"
+	}
+	return "alt.trend.flat"
+}
@@ -0,0 +1,198 @@
+package reporter
+
+import (
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/types"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// BacktestChart is a PNG chart embedded in the backtest report, with a
+// text description of what it shows
+type BacktestChart struct {
+	Title string
+	Alt   string
+	PNG   []byte
+}
+
+// BacktestStrategyReport is the section of one strategy in the backtest
+// report: its stored run, charts and returns by calendar month
+type BacktestStrategyReport struct {
+	Run     types.BacktestRun
+	CAGR    float64 // compound annual growth rate of the run's total return
+	Charts  []BacktestChart
+	Monthly []types.MonthlyReturn
+}
+
+// BacktestReport is the content of the backtest HTML report
+type BacktestReport struct {
+	Symbol        string
+	Denomination  *types.Denomination // of the prices
+	Start         time.Time
+	End           time.Time
+	Bars          int
+	Costs         types.BacktestCosts
+	MeanReversion []string // half-life of the series and the lookbacks it suggests
+	Strategies    []BacktestStrategyReport
+}
+
+// heatRow is one year of the monthly return heat table
+type heatRow struct {
+	Year   int
+	Months [12]heatCell
+	Total  heatCell
+}
+
+// heatCell is one month of the heat table, colored by its return
+type heatCell struct {
+	Set    bool
+	Return float64
+	Color  string
+}
+
+const backtestTemplate = `<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{t "backtest.title"}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; }
+        .header { background-color: #f8f9fa; padding: 20px; border-radius: 5px; }
+        .section { margin: 20px 0; padding: 15px; border: 1px solid #ddd; border-radius: 5px; }
+        .metric { display: inline-block; margin: 10px; padding: 10px; background-color: #e9ecef; border-radius: 3px; }
+        table { border-collapse: collapse; margin: 10px 0; }
+        th, td { border: 1px solid #ddd; padding: 6px 8px; text-align: right; }
+        th { background-color: #f2f2f2; }
+        figure { margin: 10px 0; }
+        img { max-width: 100%; }` + PrintStyles + `
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>{{t "backtest.title"}}</h1>
+        <p>{{t "backtest.header" .Symbol (date .Start) (date .End) .Bars}}</p>
+        <p>{{t "backtest.costs" .Costs.FeeBps .Costs.SlippageBps}}</p>
+    </header>
+
+    <main>
+    {{if .MeanReversion}}
+    <section class="section" aria-labelledby="mean-reversion">
+        <h2 id="mean-reversion">{{t "backtest.mean_reversion"}}</h2>
+        {{range .MeanReversion}}<p>{{.}}</p>{{end}}
+    </section>
+    {{end}}
+    {{range .Strategies}}
+    <section class="section" aria-labelledby="run-{{.Run.ID}}">
+        <h2 id="run-{{.Run.ID}}">{{.Run.Strategy}}</h2>
+        <p>{{t "backtest.run" .Run.ID}}</p>
+        <div class="metric">{{t "backtest.total_return" (percent .Run.Metrics.TotalReturn)}}</div>
+        <div class="metric">{{t "backtest.cagr" (percent .CAGR)}}</div>
+        <div class="metric">{{t "backtest.annualized" (percent .Run.Metrics.AnnualizedReturn)}}</div>
+        <div class="metric">{{t "backtest.sharpe" .Run.Metrics.SharpeRatio}}</div>
+        <div class="metric">{{t "backtest.max_drawdown" (percent .Run.Metrics.MaxDrawdown)}}</div>
+        <div class="metric">{{t "backtest.trades" .Run.Metrics.Trades}}</div>
+        <div class="metric">{{t "backtest.win_rate" (percent .Run.Metrics.WinRate)}}</div>
+        <div class="metric">{{t "backtest.exposure" (percent .Run.Metrics.Exposure)}}</div>
+        {{range .Charts}}
+        <figure>
+            <img src="{{png .PNG}}" alt="{{.Alt}}">
+            <figcaption class="visually-hidden">{{.Title}}</figcaption>
+        </figure>
+        {{end}}
+        {{with heat .Monthly}}
+        <h3>{{t "backtest.monthly"}}</h3>
+        <table>
+            <caption class="visually-hidden">{{t "backtest.monthly.caption" $.Symbol}}</caption>
+            <tr><th scope="col">{{t "backtest.col.year"}}</th>{{range months}}<th scope="col">{{.}}</th>{{end}}<th scope="col">{{t "backtest.col.year"}}</th></tr>
+            {{range .}}
+            <tr>
+                <th scope="row">{{.Year}}</th>
+                {{range .Months}}<td{{if .Set}} style="background-color: {{.Color}}"{{end}}>{{if .Set}}{{percent .Return}}{{end}}</td>{{end}}
+                <td style="background-color: {{.Total.Color}}"><b>{{percent .Total.Return}}</b></td>
+            </tr>
+            {{end}}
+        </table>
+        {{end}}
+    </section>
+    {{end}}
+    </main>
+</body>
+</html>`
+
+// GenerateBacktestReport writes the backtest HTML report to filename in the
+// locale of msgs
+func GenerateBacktestReport(report *BacktestReport, filename string, msgs *i18n.Catalog) error {
+	tmpl, err := template.New("backtest").Funcs(templateFuncs(report.Denomination, msgs)).Funcs(template.FuncMap{
+		"png": func(data []byte) template.URL {
+			return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(data))
+		},
+		"heat": heatTable,
+		"months": func() []string {
+			months := make([]string, 12)
+			for i := range months {
+				months[i] = msgs.T("month." + strings.ToLower(time.Month(i + 1).String()[:3]))
+			}
+			return months
+		},
+	}).Parse(backtestTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse backtest report template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return fmt.Errorf("failed to render backtest report: %w", err)
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write backtest report: %w", err)
+	}
+	return nil
+}
+
+// heatTable arranges monthly returns by year, coloring each month on one
+// scale from the largest loss to the largest gain among them
+func heatTable(monthly []types.MonthlyReturn) []heatRow {
+	scale := 0.0
+	for _, m := range monthly {
+		scale = math.Max(scale, math.Abs(m.Return))
+	}
+
+	var rows []heatRow
+	for _, m := range monthly {
+		if len(rows) == 0 || rows[len(rows)-1].Year != m.Year {
+			rows = append(rows, heatRow{Year: m.Year, Total: heatCell{Set: true}})
+		}
+		row := &rows[len(rows)-1]
+		row.Months[m.Month-1] = heatCell{Set: true, Return: m.Return, Color: heatColor(m.Return, scale)}
+		row.Total.Return = (1+row.Total.Return)*(1+m.Return) - 1
+	}
+	for i := range rows {
+		rows[i].Total.Color = heatColor(rows[i].Total.Return, scale)
+	}
+	return rows
+}
+
+// heatColor blends white toward green for gains and red for losses by the
+// size of r relative to scale
+func heatColor(r, scale float64) string {
+	weight := 0.0
+	if scale > 0 {
+		weight = math.Min(math.Abs(r)/scale, 1) * 0.7
+	}
+	target := [3]float64{40, 167, 69}
+	if r < 0 {
+		target = [3]float64{220, 53, 69}
+	}
+	var rgb [3]int
+	for i, c := range target {
+		rgb[i] = int(math.Round(255 + (c-255)*weight))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
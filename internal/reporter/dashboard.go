@@ -0,0 +1,210 @@
+package reporter
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"time"
+)
+
+// Gauge is one dial of the risk dashboard. Fraction positions the needle
+// between Min and Max; Level ("ok", "warn" or "alert") colors it.
+type Gauge struct {
+	Label     string
+	Display   string
+	Note      string
+	Fraction  float64
+	Level     string
+	Available bool
+	NeedleX   float64
+	NeedleY   float64
+}
+
+// dashboardTemplate is a dark, large-type layout meant for a wall monitor
+const dashboardTemplate = `<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+    <meta charset="UTF-8">
+    <title>{{t "dashboard.title"}}</title>
+    {{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">{{end}}
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 30px; background: #111418; color: #e9ecef; }
+        h1 { margin: 0; font-size: 2.4em; }
+        .subtitle { color: #8a939c; font-size: 1.2em; margin-bottom: 30px; }
+        .grid { display: grid; grid-template-columns: repeat(3, 1fr); gap: 30px; }
+        .gauge { background: #1c2127; border-radius: 12px; padding: 20px; text-align: center; }
+        .gauge h2 { margin: 0 0 10px 0; font-size: 1.5em; color: #adb5bd; }
+        .value { font-size: 2.8em; font-weight: bold; margin-top: -10px; }
+        .note { color: #8a939c; font-size: 1.1em; margin-top: 6px; }
+        .ok { color: #28a745; stroke: #28a745; }
+        .warn { color: #ffc107; stroke: #ffc107; }
+        .alert { color: #dc3545; stroke: #dc3545; }
+        .na { color: #495057; }
+    </style>
+</head>
+<body>
+    <h1>{{t "dashboard.title"}}</h1>
+    <div class="subtitle">{{.Subtitle}}</div>
+    <main class="grid">
+    {{range .Gauges}}
+        <section class="gauge">
+            <h2>{{.Label}}</h2>
+            <svg viewBox="0 0 200 110" width="100%" height="180" aria-hidden="true">
+                <path d="M 20 100 A 80 80 0 0 1 180 100" fill="none" stroke="#343a40" stroke-width="18"/>
+                {{if .Available}}
+                <line x1="100" y1="100" x2="{{printf "%.1f" .NeedleX}}" y2="{{printf "%.1f" .NeedleY}}" class="{{.Level}}" stroke-width="6" stroke-linecap="round"/>
+                <circle cx="100" cy="100" r="8" fill="#adb5bd"/>
+                {{end}}
+            </svg>
+            {{if .Available}}
+            <div class="value {{.Level}}">{{.Display}}</div>
+            {{else}}
+            <div class="value na">{{$.NotAvailable}}</div>
+            {{end}}
+            <div class="note">{{.Note}}</div>
+        </section>
+    {{end}}
+    </main>
+</body>
+</html>`
+
+// GenerateRiskDashboard writes the risk dashboard HTML page to filename in
+// the locale of msgs. refreshSeconds > 0 makes the page reload itself, e.g.
+// when served live.
+func GenerateRiskDashboard(result *types.AnalysisResult, filename string, refreshSeconds int, msgs *i18n.Catalog) error {
+	page, err := RenderRiskDashboard(result, refreshSeconds, msgs)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, page, 0644); err != nil {
+		return fmt.Errorf("failed to write risk dashboard: %w", err)
+	}
+	return nil
+}
+
+// RenderRiskDashboard renders the risk dashboard HTML page in the locale of
+// msgs
+func RenderRiskDashboard(result *types.AnalysisResult, refreshSeconds int, msgs *i18n.Catalog) ([]byte, error) {
+	bts := result.Series
+	tmpl, err := template.New("dashboard").Funcs(templateFuncs(bts.Denomination, msgs)).Parse(dashboardTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dashboard template: %w", err)
+	}
+
+	latestPrice := 0.0
+	if len(bts.Data) > 0 {
+		latestPrice = timeseries.GetLatestPrice(bts).Close
+	}
+	data := map[string]interface{}{
+		"Subtitle":     msgs.T("dashboard.subtitle", bts.Symbol, denom.Price(bts.Denomination, latestPrice), time.Now().Format("2006-01-02 15:04:05")),
+		"NotAvailable": msgs.T("common.not_available"),
+		"Refresh":      refreshSeconds,
+		"Gauges":       RiskGauges(result, msgs),
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render risk dashboard: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RiskGauges computes the dashboard gauges: volatility percentile, RSI,
+// current drawdown, 1-day VaR, premium/funding extremes and the composite
+// signal score, labeled in the locale of msgs. Gauges without data are
+// marked unavailable.
+func RiskGauges(result *types.AnalysisResult, msgs *i18n.Catalog) []Gauge {
+	bts, analytics := result.Series, result.Analytics
+	var gauges []Gauge
+
+	// Volatility percentile: the trailing 30-day volatility ranked against its own history
+	volGauge := Gauge{Label: msgs.T("dashboard.vol"), Note: msgs.T("dashboard.vol.note")}
+	returns, _ := statistics.CalculateReturns(bts)
+	window := timeseries.BarsFor(bts, 30*24*time.Hour)
+	if len(returns) > window {
+		rolling := statistics.RollingVolatility(returns, window, analytics.RiskConvention.PeriodsPerYear)
+		history := rolling[window-1:]
+		current := history[len(history)-1]
+		pct := statistics.PercentileRank(history, current)
+		volGauge = newGauge(volGauge.Label, fmt.Sprintf("%.0f%%", pct), msgs.T("dashboard.vol.current", numfmt.Pct(current)), pct/100, levelFor(pct, 50, 80))
+	}
+	gauges = append(gauges, volGauge)
+
+	rsiGauge := Gauge{Label: msgs.T("dashboard.rsi"), Note: msgs.T("dashboard.rsi.note")}
+	if len(analytics.RSI) > 0 {
+		rsi := analytics.RSI[len(analytics.RSI)-1]
+		level := levelFor(math.Abs(rsi-50), 15, 20)
+		rsiGauge = newGauge(rsiGauge.Label, fmt.Sprintf("%.1f", rsi), rsiGauge.Note, rsi/100, level)
+	}
+	gauges = append(gauges, rsiGauge)
+
+	ddGauge := Gauge{Label: msgs.T("dashboard.drawdown"), Note: msgs.T("dashboard.drawdown.note")}
+	if len(bts.Data) > 0 {
+		peak := 0.0
+		for _, bar := range bts.Data {
+			peak = math.Max(peak, bar.Close)
+		}
+		drawdown := (peak - timeseries.GetLatestPrice(bts).Close) / peak * 100
+		scale := math.Max(50, analytics.MaxDrawdown*100)
+		note := msgs.T("dashboard.drawdown.max", numfmt.Pct(analytics.MaxDrawdown))
+		ddGauge = newGauge(ddGauge.Label, numfmt.Pct(drawdown/100), note, drawdown/scale, levelFor(drawdown, 10, 25))
+	}
+	gauges = append(gauges, ddGauge)
+
+	varGauge := Gauge{Label: msgs.T("dashboard.var"), Note: msgs.T("dashboard.var.note")}
+	if len(returns) > 1 {
+		loss := -statistics.GetRiskMetrics(bts, analytics.RiskConvention)["var_95_daily"] * 100
+		varGauge = newGauge(varGauge.Label, numfmt.Pct(loss/100), msgs.T("dashboard.var.loss"), loss/15, levelFor(loss, 3, 6))
+	}
+	gauges = append(gauges, varGauge)
+
+	premiumGauge := Gauge{Label: msgs.T("dashboard.premium"), Note: msgs.T("dashboard.premium.note")}
+	if pa := analytics.Premium; pa != nil && len(pa.Points) > 0 {
+		latest := pa.Points[len(pa.Points)-1]
+		note := msgs.T("dashboard.premium.current", numfmt.SignedPct(latest.Premium), pa.Reference)
+		premiumGauge = newGauge(premiumGauge.Label, fmt.Sprintf("z %+.1f", latest.ZScore), note, (latest.ZScore+4)/8, levelFor(math.Abs(latest.ZScore), 1.5, pa.Threshold))
+	}
+	gauges = append(gauges, premiumGauge)
+
+	score := result.SignalScore * 100
+	level := levelFor(math.Abs(score), 30, 60)
+	note := msgs.T("dashboard.signal.note", len(result.Signals))
+	gauges = append(gauges, newGauge(msgs.T("dashboard.signal"), fmt.Sprintf("%+.0f", score), note, (score+100)/200, level))
+
+	return gauges
+}
+
+// newGauge builds an available gauge with its needle placed on the dial
+func newGauge(label, display, note string, fraction float64, level string) Gauge {
+	fraction = math.Max(0, math.Min(1, fraction))
+	angle := math.Pi * (1 - fraction)
+	return Gauge{
+		Label:     label,
+		Display:   display,
+		Note:      note,
+		Fraction:  fraction,
+		Level:     level,
+		Available: true,
+		NeedleX:   100 + 70*math.Cos(angle),
+		NeedleY:   100 - 70*math.Sin(angle),
+	}
+}
+
+// levelFor grades value against warn and alert thresholds
+func levelFor(value, warn, alert float64) string {
+	switch {
+	case value >= alert:
+		return "alert"
+	case value >= warn:
+		return "warn"
+	}
+	return "ok"
+}
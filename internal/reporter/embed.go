@@ -0,0 +1,255 @@
+package reporter
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Embeddable widgets, named by their file names
+const (
+	WidgetSignalBadge       = "signal_badge"
+	WidgetPriceRSI          = "price_rsi"
+	WidgetSupportResistance = "support_resistance"
+)
+
+// Widgets lists the embeddable widgets in output order
+var Widgets = []string{WidgetSignalBadge, WidgetPriceRSI, WidgetSupportResistance}
+
+// SignalBadge is the data of the signal badge widget
+type SignalBadge struct {
+	Symbol string    `json:"symbol"`
+	Score  float64   `json:"score"` // -100 (all SELL) to +100 (all BUY)
+	Lean   string    `json:"lean"`  // "bullish", "bearish" or "neutral"
+	Buy    int       `json:"buy"`
+	Sell   int       `json:"sell"`
+	Hold   int       `json:"hold"`
+	AsOf   time.Time `json:"as_of"`
+}
+
+// PriceRSI is the data of the price and RSI widget
+type PriceRSI struct {
+	Symbol       string    `json:"symbol"`
+	Denomination string    `json:"denomination"` // e.g. "USD per BTC"
+	Price        float64   `json:"price"`
+	Change24h    *float64  `json:"change_24h"` // nil when the series is shorter than a day
+	RSI          *float64  `json:"rsi"`
+	RSIZone      string    `json:"rsi_zone,omitempty"` // "overbought", "oversold" or "neutral"
+	Sparkline    []float64 `json:"sparkline"`          // closes of the last 30 days
+	AsOf         time.Time `json:"as_of"`
+}
+
+// SupportResistanceTable is the data of the support and resistance widget
+type SupportResistanceTable struct {
+	Symbol       string        `json:"symbol"`
+	Denomination string        `json:"denomination"`
+	Price        float64       `json:"price"`
+	Levels       []WidgetLevel `json:"levels"`
+	AsOf         time.Time     `json:"as_of"`
+}
+
+// WidgetLevel is one row of the support and resistance widget
+type WidgetLevel struct {
+	Kind     string  `json:"kind"` // "support" or "resistance" relative to the price
+	Price    float64 `json:"price"`
+	Distance float64 `json:"distance"` // from the price, as a fraction of it
+}
+
+const widgetHead = `<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 8px; background: transparent; color: #212529; font-size: 14px; }
+        .widget { display: inline-block; padding: 10px 14px; border: 1px solid #ddd; border-radius: 6px; background: #fff; }
+        .label { color: #6c757d; font-size: 0.85em; }
+        .big { font-size: 1.6em; font-weight: bold; }
+        .bullish, .up { color: #1e7e34; }
+        .bearish, .down { color: #c82333; }
+        .neutral { color: #8a6d00; }
+        table { border-collapse: collapse; }
+        th, td { padding: 3px 8px; text-align: right; border-bottom: 1px solid #eee; }
+        th:first-child, td:first-child { text-align: left; }
+        .asof { color: #6c757d; font-size: 0.75em; margin-top: 6px; }
+    </style>
+</head>
+<body>
+`
+
+const widgetFoot = `
+    <div class="asof">{{.Symbol}} · {{.AsOf.Format "2006-01-02 15:04 MST"}}</div>
+    </div>
+</body>
+</html>`
+
+var widgetTemplates = map[string]string{
+	WidgetSignalBadge: `    <div class="widget" role="status" aria-label="{{t "widget.signal.label" .Symbol .Score (t (print "widget.lean." .Lean))}}">
+    <div class="label">{{t "widget.signal"}}</div>
+    <div class="big {{.Lean}}">{{printf "%+.0f" .Score}} {{t (print "widget.lean." .Lean)}}</div>
+    <div class="label">{{t "widget.signal.counts" .Buy .Sell .Hold}}</div>`,
+	WidgetPriceRSI: `    <div class="widget">
+    <div class="label">{{.Symbol}}</div>
+    <div class="big">{{price .Price}}{{with .Change24h}} <span class="{{updown .}}">{{pct .}}</span>{{end}}</div>
+    {{with .Sparkline}}<svg viewBox="0 0 200 40" width="200" height="40" role="img" aria-label="{{t "widget.sparkline"}}"><polyline fill="none" stroke="#667eea" stroke-width="2" points="{{sparkline .}}"/></svg>{{end}}
+    {{with .RSI}}<div class="label">{{t "widget.rsi" (deref .)}} <span class="{{$.RSIZone}}">{{t (print "widget.zone." $.RSIZone)}}</span></div>{{end}}`,
+	WidgetSupportResistance: `    <div class="widget">
+    <table>
+        <caption class="label">{{t "widget.levels" (price .Price)}}</caption>
+        <tr><th scope="col">{{t "widget.col.level"}}</th><th scope="col">{{t "widget.col.price"}}</th><th scope="col">{{t "widget.col.distance"}}</th></tr>
+        {{range .Levels}}<tr><td class="{{if eq .Kind "support"}}up{{else}}down{{end}}">{{t (print "widget.kind." .Kind)}}</td><td>{{price .Price}}</td><td>{{pct .Distance}}</td></tr>
+        {{else}}<tr><td colspan="3">{{t "widget.no_levels"}}</td></tr>
+        {{end}}
+    </table>`,
+}
+
+// WidgetData returns the data behind the widget name, as it is rendered and
+// exported as JSON
+func WidgetData(name string, result *types.AnalysisResult) (interface{}, error) {
+	bts := result.Series
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data for the %s widget", name)
+	}
+	latest := bts.Data[len(bts.Data)-1]
+	switch name {
+	case WidgetSignalBadge:
+		badge := SignalBadge{Symbol: bts.Symbol, Score: result.SignalScore * 100, Lean: "neutral", AsOf: latest.Timestamp}
+		for _, signal := range result.Signals {
+			switch {
+			case strings.HasPrefix(signal, "BUY"):
+				badge.Buy++
+			case strings.HasPrefix(signal, "SELL"):
+				badge.Sell++
+			default:
+				badge.Hold++
+			}
+		}
+		switch {
+		case result.SignalScore >= 0.3:
+			badge.Lean = "bullish"
+		case result.SignalScore <= -0.3:
+			badge.Lean = "bearish"
+		}
+		return badge, nil
+	case WidgetPriceRSI:
+		widget := PriceRSI{
+			Symbol:       bts.Symbol,
+			Denomination: denom.Label(bts.Denomination),
+			Price:        latest.Close,
+			Change24h:    timeseries.ChangeOver(bts, 24*time.Hour),
+			AsOf:         latest.Timestamp,
+		}
+		if rsi := result.Analytics.RSI; len(rsi) > 0 {
+			widget.RSI = &rsi[len(rsi)-1]
+			widget.RSIZone = "neutral"
+			if *widget.RSI > 70 {
+				widget.RSIZone = "overbought"
+			} else if *widget.RSI < 30 {
+				widget.RSIZone = "oversold"
+			}
+		}
+		for _, bar := range timeseries.Between(bts, latest.Timestamp.Add(-30*24*time.Hour), latest.Timestamp) {
+			widget.Sparkline = append(widget.Sparkline, bar.Close)
+		}
+		return widget, nil
+	case WidgetSupportResistance:
+		table := SupportResistanceTable{
+			Symbol:       bts.Symbol,
+			Denomination: denom.Label(bts.Denomination),
+			Price:        latest.Close,
+			Levels:       []WidgetLevel{},
+			AsOf:         latest.Timestamp,
+		}
+		sr := result.Analytics.SupportResistance
+		for _, level := range append(append([]float64(nil), sr.SupportLevels...), sr.ResistanceLevels...) {
+			kind := "support"
+			if level > latest.Close {
+				kind = "resistance"
+			}
+			table.Levels = append(table.Levels, WidgetLevel{Kind: kind, Price: level, Distance: level/latest.Close - 1})
+		}
+		sort.Slice(table.Levels, func(i, j int) bool { return table.Levels[i].Price > table.Levels[j].Price })
+		return table, nil
+	}
+	return nil, fmt.Errorf("unknown widget: %s", name)
+}
+
+// RenderWidget renders the widget name as a standalone HTML fragment in the
+// locale of msgs, small enough to be embedded in another site with an iframe.
+// The JSON data of WidgetData is not translated.
+func RenderWidget(name string, result *types.AnalysisResult, msgs *i18n.Catalog) ([]byte, error) {
+	data, err := WidgetData(name, result)
+	if err != nil {
+		return nil, err
+	}
+	d := result.Series.Denomination
+	tmpl, err := template.New(name).Funcs(templateFuncs(d, msgs)).Funcs(template.FuncMap{
+		"sparkline": sparklinePoints,
+		"deref":     func(v *float64) float64 { return *v },
+	}).Parse(widgetHead + widgetTemplates[name] + widgetFoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s widget template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s widget: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateWidgets writes every widget to dir as name.html in the locale of
+// msgs, with the data behind it as name.json
+func GenerateWidgets(result *types.AnalysisResult, dir string, msgs *i18n.Catalog) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create widget directory: %w", err)
+	}
+	for _, name := range Widgets {
+		page, err := RenderWidget(name, result, msgs)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".html"), page, 0644); err != nil {
+			return fmt.Errorf("failed to write %s widget: %w", name, err)
+		}
+		data, _ := WidgetData(name, result)
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s widget data: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), append(encoded, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write %s widget data: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// sparklinePoints scales values to the points of a 200x40 SVG polyline
+func sparklinePoints(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	points := make([]string, len(values))
+	for i, v := range values {
+		x, y := 0.0, 20.0
+		if len(values) > 1 {
+			x = float64(i) / float64(len(values)-1) * 200
+		}
+		if hi > lo {
+			y = 38 - (v-lo)/(hi-lo)*36
+		}
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return strings.Join(points, " ")
+}
@@ -0,0 +1,94 @@
+package reporter
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+// templateFuncs returns the functions every HTML template of the reporter
+// can call, with prices in denomination d and messages in the locale of
+// msgs. Templates add their own functions on top.
+//
+//	t, lang              messages and language of msgs
+//	contains s substr    whether s contains substr
+//	price v              v in denomination d
+//	amount v             price difference v in denomination d, without unit
+//	pct v                fraction v as a signed percentage, e.g. +1.25%
+//	percent v            fraction v as a percentage, e.g. 1.25%
+//	humanize v           v with a K, M, B or T suffix, e.g. 1.23M
+//	mul a b              a times b
+//	updown v             "down" for a negative v, "up" otherwise
+//	signal s             "buy", "sell" or "hold", the class of signal s
+//	date t, datetime t   t as 2006-01-02 or 2006-01-02 15:04
+//	now                  the current time as 2006-01-02 15:04
+func templateFuncs(d *types.Denomination, msgs *i18n.Catalog) template.FuncMap {
+	return template.FuncMap{
+		"t":        msgs.T,
+		"lang":     msgs.Lang,
+		"contains": strings.Contains,
+		"price":    func(v float64) string { return denom.Price(d, v) },
+		"amount":   func(v float64) string { return denom.Amount(d, v) },
+		"pct":      numfmt.SignedPct,
+		"percent":  numfmt.Pct,
+		"humanize": humanize,
+		"mul":      func(a, b float64) float64 { return a * b },
+		"updown": func(v float64) string {
+			if v < 0 {
+				return "down"
+			}
+			return "up"
+		},
+		"signal":   signalClass,
+		"date":     func(t time.Time) string { return t.Format("2006-01-02") },
+		"datetime": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+		"now":      func() string { return time.Now().Format("2006-01-02 15:04") },
+	}
+}
+
+// humanize formats v with a K, M, B or T suffix from a thousand up, to about
+// three significant digits, e.g. 1234567 as 1.23M and 45678 as 45.7K
+func humanize(v float64) string {
+	suffix := ""
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if math.Abs(v) >= unit.size {
+			v, suffix = v/unit.size, unit.suffix
+			break
+		}
+	}
+	switch abs := math.Abs(v); {
+	case abs >= 100:
+		return fmt.Sprintf("%.0f%s", v, suffix)
+	case abs >= 10:
+		return fmt.Sprintf("%.1f%s", v, suffix)
+	}
+	return fmt.Sprintf("%.2f%s", v, suffix)
+}
+
+// signalClass classifies a trading signal such as "BUY - Oversold", "STRONG
+// SELL" or "HOLD - Bullish" as "buy", "sell" or "hold": by its leading
+// action when it has one, otherwise by the action it mentions
+func signalClass(signal string) string {
+	upper := strings.ToUpper(strings.TrimSpace(signal))
+	for _, action := range []string{"BUY", "SELL", "HOLD"} {
+		if strings.HasPrefix(upper, action) {
+			return strings.ToLower(action)
+		}
+	}
+	switch {
+	case strings.Contains(upper, "BUY"):
+		return "buy"
+	case strings.Contains(upper, "SELL"):
+		return "sell"
+	}
+	return "hold"
+}
@@ -0,0 +1,183 @@
+package reporter
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// OverviewRow is one asset of the multi-symbol overview: its latest price,
+// changes, RSI, trend and composite signal score, with a link to its
+// detailed report. Prices and changes are rounded to the precision they are
+// shown with; values a short series cannot provide are nil.
+type OverviewRow struct {
+	Name      string    `json:"name"`
+	Symbol    string    `json:"symbol"`
+	Link      string    `json:"link"`            // detailed report of the asset
+	Price     *float64  `json:"price"`           // latest close
+	PriceText string    `json:"price_text"`      // latest close in its denomination
+	Change24h *float64  `json:"change_24h"`      // change of the latest close over a day
+	Change7d  *float64  `json:"change_7d"`       // change of the latest close over a week
+	RSI       *float64  `json:"rsi"`             // latest RSI (14)
+	Trend     string    `json:"trend,omitempty"` // "uptrend", "downtrend" or "sideways" from the market structure
+	Score     *float64  `json:"score"`           // composite signal score, -100 (all SELL) to +100 (all BUY)
+	AsOf      time.Time `json:"as_of"`           // latest bar
+	Error     string    `json:"error,omitempty"` // why the asset has no analysis
+}
+
+// NewOverviewRow summarizes result as the overview row name linking to
+// link. A nil result gives a row with only err, or pending analysis.
+func NewOverviewRow(name, link string, result *types.AnalysisResult, err error) OverviewRow {
+	row := OverviewRow{Name: name, Link: link}
+	if result == nil {
+		if err != nil {
+			row.Error = err.Error()
+		}
+		return row
+	}
+	row.Symbol = result.Metadata.Symbol
+	if err != nil {
+		row.Error = err.Error()
+	}
+	bts, analytics := result.Series, result.Analytics
+	if bts == nil || len(bts.Data) == 0 {
+		return row
+	}
+
+	latest := bts.Data[len(bts.Data)-1]
+	price := numfmt.Round(denom.Kind(bts.Denomination), latest.Close)
+	row.Price = &price
+	row.PriceText = denom.Price(bts.Denomination, latest.Close)
+	row.AsOf = latest.Timestamp
+	row.Change24h = roundPct(timeseries.ChangeOver(bts, 24*time.Hour))
+	row.Change7d = roundPct(timeseries.ChangeOver(bts, 7*24*time.Hour))
+	if len(analytics.RSI) > 0 {
+		row.RSI = &analytics.RSI[len(analytics.RSI)-1]
+	}
+	if ms := analytics.MarketStructure; ms != nil {
+		switch ms.Bias {
+		case "bullish":
+			row.Trend = "uptrend"
+		case "bearish":
+			row.Trend = "downtrend"
+		default:
+			row.Trend = "sideways"
+		}
+	}
+	if len(result.Signals) > 0 {
+		score := result.SignalScore * 100
+		row.Score = &score
+	}
+	return row
+}
+
+// roundPct rounds an optional change to the precision it is shown with
+func roundPct(change *float64) *float64 {
+	if change == nil {
+		return nil
+	}
+	rounded := numfmt.RoundPct(*change)
+	return &rounded
+}
+
+// overviewTemplate lays out the overview as one table row per asset
+const overviewTemplate = `<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{t "overview.title"}}</title>
+    {{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">{{end}}
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; }
+        table { width: 100%; border-collapse: collapse; margin: 10px 0; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
+        th { background-color: #f2f2f2; }
+        td.number { text-align: right; font-family: monospace; }
+        td.up, td.buy { color: #1e7e34; }
+        td.down, td.sell { color: #c82333; }
+        td.hold { color: #8a6d00; }
+        td.error { color: #6c757d; font-style: italic; }` + PrintStyles + `
+    </style>
+</head>
+<body>
+    <header>
+        <h1>{{t "overview.title"}}</h1>
+        <p>{{t "overview.subtitle" (len .Rows) (now)}}</p>
+    </header>
+    <main>
+        <table>
+            <caption class="visually-hidden">{{t "overview.caption"}}</caption>
+            <thead>
+                <tr>
+                    <th scope="col">{{t "overview.col.asset"}}</th>
+                    <th scope="col">{{t "overview.col.price"}}</th>
+                    <th scope="col">{{t "overview.col.24h"}}</th>
+                    <th scope="col">{{t "overview.col.7d"}}</th>
+                    <th scope="col">{{t "overview.col.rsi"}}</th>
+                    <th scope="col">{{t "overview.col.trend"}}</th>
+                    <th scope="col">{{t "overview.col.score"}}</th>
+                    <th scope="col">{{t "overview.col.as_of"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+            {{range .Rows}}
+                <tr>
+                    <th scope="row">{{if .Link}}<a href="{{.Link}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</th>
+                    {{if .Price}}
+                    <td class="number">{{.PriceText}}</td>
+                    {{with .Change24h}}<td class="number {{updown .}}">{{pct .}}</td>{{else}}<td class="number">{{$.NotAvailable}}</td>{{end}}
+                    {{with .Change7d}}<td class="number {{updown .}}">{{pct .}}</td>{{else}}<td class="number">{{$.NotAvailable}}</td>{{end}}
+                    {{with .RSI}}<td class="number">{{printf "%.1f" (deref .)}}</td>{{else}}<td class="number">{{$.NotAvailable}}</td>{{end}}
+                    <td>{{if .Trend}}{{t (printf "overview.trend.%s" .Trend)}}{{else}}{{$.NotAvailable}}{{end}}</td>
+                    {{with .Score}}<td class="number {{scoreClass .}}">{{printf "%+.0f" (deref .)}}</td>{{else}}<td class="number">{{$.NotAvailable}}</td>{{end}}
+                    <td>{{datetime .AsOf}}</td>
+                    {{else}}
+                    <td class="error" colspan="7">{{if .Error}}{{.Error}}{{else}}{{t "overview.pending"}}{{end}}</td>
+                    {{end}}
+                </tr>
+            {{end}}
+            </tbody>
+        </table>
+        <p>{{t "overview.note"}}</p>
+    </main>
+</body>
+</html>`
+
+// RenderOverview renders the overview page of rows in the locale of msgs.
+// refreshSeconds > 0 makes the page reload itself.
+func RenderOverview(rows []OverviewRow, refreshSeconds int, msgs *i18n.Catalog) ([]byte, error) {
+	tmpl, err := template.New("overview").Funcs(templateFuncs(nil, msgs)).Funcs(template.FuncMap{
+		"deref": func(v *float64) float64 { return *v },
+		// the leaning of the signal badge widget
+		"scoreClass": func(score float64) string {
+			switch {
+			case score >= 30:
+				return "buy"
+			case score <= -30:
+				return "sell"
+			}
+			return "hold"
+		},
+	}).Parse(overviewTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse overview template: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"Rows":         rows,
+		"Refresh":      refreshSeconds,
+		"NotAvailable": msgs.T("common.not_available"),
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render overview: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -1,230 +1,533 @@
-package reporter
-
-import (
-	"btc-analyzer/internal/analyzer"
-	"btc-analyzer/internal/types"
-	"encoding/json"
-	"fmt"
-	"html/template"
-	"os"
-	"time"
-)
-
-// GenerateHTMLReport creates an HTML report
-func GenerateHTMLReport(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, filename string) error {
-	tmpl := `<!DOCTYPE html>
-<html>
-<head>
-    <title>Bitcoin Analysis Report</title>
-    <style>
-        body { font-family: Arial, sans-serif; margin: 40px; }
-        .header { background-color: #f8f9fa; padding: 20px; border-radius: 5px; }
-        .section { margin: 20px 0; padding: 15px; border: 1px solid #ddd; border-radius: 5px; }
-        .metric { display: inline-block; margin: 10px; padding: 10px; background-color: #e9ecef; border-radius: 3px; }
-        .signal-buy { color: #28a745; font-weight: bold; }
-        .signal-sell { color: #dc3545; font-weight: bold; }
-        .signal-hold { color: #ffc107; font-weight: bold; }
-        table { width: 100%; border-collapse: collapse; margin: 10px 0; }
-        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
-        th { background-color: #f2f2f2; }
-    </style>
-</head>
-<body>
-    <div class="header">
-        <h1>Bitcoin Market Analysis Report</h1>
-        <p>Symbol: {{.Symbol}} | Generated: {{.GeneratedAt}}</p>
-        <p>Data Points: {{.DataPoints}} | Time Range: {{.TimeRange}}</p>
-    </div>
-
-    <div class="section">
-        <h2>Current Price Information</h2>
-        <div class="metric">Latest Price: ${{printf "%.2f" .LatestPrice}}</div>
-        <div class="metric">Latest Volume: {{printf "%.0f" .LatestVolume}}</div>
-    </div>
-
-    <div class="section">
-        <h2>Price Statistics</h2>
-        <div class="metric">Mean: ${{printf "%.2f" .PriceStats.Mean}}</div>
-        <div class="metric">Median: ${{printf "%.2f" .PriceStats.Median}}</div>
-        <div class="metric">Min: ${{printf "%.2f" .PriceStats.Min}}</div>
-        <div class="metric">Max: ${{printf "%.2f" .PriceStats.Max}}</div>
-        <div class="metric">Std Dev: ${{printf "%.2f" .PriceStats.StdDev}}</div>
-    </div>
-
-    <div class="section">
-        <h2>Risk Metrics</h2>
-        <div class="metric">Volatility: {{printf "%.2f" .Volatility}}%</div>
-        <div class="metric">Sharpe Ratio: {{printf "%.3f" .SharpeRatio}}</div>
-        <div class="metric">Max Drawdown: {{printf "%.2f" .MaxDrawdown}}%</div>
-    </div>
-
-    {{if .Signals}}
-    <div class="section">
-        <h2>Trading Signals</h2>
-        <table>
-            <tr><th>Indicator</th><th>Signal</th></tr>
-            {{range $indicator, $signal := .Signals}}
-            <tr>
-                <td>{{$indicator}}</td>
-                <td class="{{if contains $signal "BUY"}}signal-buy{{else if contains $signal "SELL"}}signal-sell{{else}}signal-hold{{end}}">{{$signal}}</td>
-            </tr>
-            {{end}}
-        </table>
-    </div>
-    {{end}}
-
-    <div class="section">
-        <h2>Technical Indicators</h2>
-        {{if .LatestRSI}}
-        <div class="metric">RSI (14): {{printf "%.2f" .LatestRSI}}</div>
-        {{end}}
-        {{if .LatestMACD}}
-        <div class="metric">MACD: {{printf "%.4f" .LatestMACD}}</div>
-        {{end}}
-    </div>
-
-    <div class="section">
-        <h2>Full Text Report</h2>
-        <pre>{{.TextReport}}</pre>
-    </div>
-</body>
-</html>`
-
-	// Prepare template data
-	data := prepareTemplateData(bts, analytics)
-	
-	// Create template
-	t, err := template.New("report").Funcs(template.FuncMap{
-		"contains": func(s, substr string) bool {
-			return fmt.Sprintf("%s", s) != fmt.Sprintf("%s", substr) // Simplified for template
-		},
-	}).Parse(tmpl)
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
-	}
-	
-	// Create file
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create HTML file: %w", err)
-	}
-	defer file.Close()
-	
-	// Execute template
-	if err := t.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
-	}
-	
-	return nil
-}
-
-// prepareTemplateData prepares data for HTML template
-func prepareTemplateData(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) map[string]interface{} {
-	data := make(map[string]interface{})
-	
-	data["Symbol"] = bts.Symbol
-	data["GeneratedAt"] = time.Now().Format("2006-01-02 15:04:05")
-	data["DataPoints"] = len(bts.Data)
-	
-	if len(bts.Data) > 0 {
-		latest := bts.Data[len(bts.Data)-1]
-		data["LatestPrice"] = latest.Close
-		data["LatestVolume"] = latest.Volume
-		data["TimeRange"] = fmt.Sprintf("%s to %s", 
-			bts.Data[0].Timestamp.Format("2006-01-02"),
-			latest.Timestamp.Format("2006-01-02"))
-	}
-	
-	data["PriceStats"] = analytics.PriceStats
-	data["Volatility"] = analytics.Volatility * 100
-	data["SharpeRatio"] = analytics.SharpeRatio
-	data["MaxDrawdown"] = analytics.MaxDrawdown * 100
-	
-	if len(analytics.RSI) > 0 {
-		data["LatestRSI"] = analytics.RSI[len(analytics.RSI)-1]
-	}
-	
-	if len(analytics.MACD.MACD) > 0 {
-		data["LatestMACD"] = analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
-	}
-	
-	// Get trading signals
-	signals := analyzer.GetTradingSignals(bts, analytics)
-	data["Signals"] = signals
-	
-	// Generate full text report
-	data["TextReport"] = analyzer.GenerateReport(bts, analytics)
-	
-	return data
-}
-
-// GenerateJSONReport creates a JSON report
-func GenerateJSONReport(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, filename string) error {
-	report := map[string]interface{}{
-		"metadata": map[string]interface{}{
-			"symbol":        bts.Symbol,
-			"generated_at":  time.Now().Format(time.RFC3339),
-			"data_points":   len(bts.Data),
-		},
-		"analytics":     analytics,
-		"trading_signals": analyzer.GetTradingSignals(bts, analytics),
-		"portfolio_metrics": analyzer.CalculatePortfolioMetrics(bts, 10000), // $10k initial
-	}
-	
-	if len(bts.Data) > 0 {
-		latest := bts.Data[len(bts.Data)-1]
-		report["metadata"].(map[string]interface{})["latest_price"] = latest.Close
-		report["metadata"].(map[string]interface{})["latest_volume"] = latest.Volume
-		report["metadata"].(map[string]interface{})["time_range"] = map[string]string{
-			"start": bts.Data[0].Timestamp.Format("2006-01-02"),
-			"end":   latest.Timestamp.Format("2006-01-02"),
-		}
-	}
-	
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create JSON report file: %w", err)
-	}
-	defer file.Close()
-	
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	
-	if err := encoder.Encode(report); err != nil {
-		return fmt.Errorf("failed to encode JSON report: %w", err)
-	}
-	
-	return nil
-}
-
-// PrintSummary prints a brief summary to console
-func PrintSummary(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) {
-	fmt.Println("=== BITCOIN ANALYSIS SUMMARY ===")
-	
-	if len(bts.Data) > 0 {
-		latest := bts.Data[len(bts.Data)-1]
-		fmt.Printf("Latest Price: $%.2f\n", latest.Close)
-		fmt.Printf("Data Points: %d\n", len(bts.Data))
-	}
-	
-	fmt.Printf("Mean Price: $%.2f\n", analytics.PriceStats.Mean)
-	fmt.Printf("Price Range: $%.2f - $%.2f\n", analytics.PriceStats.Min, analytics.PriceStats.Max)
-	
-	if analytics.Volatility > 0 {
-		fmt.Printf("Volatility: %.2f%%\n", analytics.Volatility*100)
-		fmt.Printf("Sharpe Ratio: %.3f\n", analytics.SharpeRatio)
-	}
-	
-	if len(analytics.RSI) > 0 {
-		fmt.Printf("Latest RSI: %.2f\n", analytics.RSI[len(analytics.RSI)-1])
-	}
-	
-	// Show key signals
-	signals := analyzer.GetTradingSignals(bts, analytics)
-	fmt.Println("\n=== KEY SIGNALS ===")
-	for indicator, signal := range signals {
-		fmt.Printf("%s: %s\n", indicator, signal)
-	}
-	
-	fmt.Println("================================")
+package reporter
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/glossary"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/types"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"time"
+)
+
+// GenerateHTMLReport creates an HTML report with the sections of rc in the
+// locale of msgs
+func GenerateHTMLReport(result *types.AnalysisResult, filename string, rc types.ReportConfig, msgs *i18n.Catalog) error {
+	page, err := RenderHTMLReport(result, rc, msgs)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, page, 0644); err != nil {
+		return fmt.Errorf("failed to create HTML file: %w", err)
+	}
+	return nil
+}
+
+// RenderHTMLReport renders the HTML report with the sections of rc in the
+// locale of msgs. Each section is a template named after it; errors and
+// warnings always come first.
+func RenderHTMLReport(result *types.AnalysisResult, rc types.ReportConfig, msgs *i18n.Catalog) ([]byte, error) {
+	tmpl := `<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{t "report.page_title"}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; }
+        .header { background-color: #f8f9fa; padding: 20px; border-radius: 5px; }
+        .section { margin: 20px 0; padding: 15px; border: 1px solid #ddd; border-radius: 5px; }
+        .metric { display: inline-block; margin: 10px; padding: 10px; background-color: #e9ecef; border-radius: 3px; }
+        .signal-buy { color: #1e7e34; font-weight: bold; }
+        .signal-sell { color: #c82333; font-weight: bold; }
+        .signal-hold { color: #8a6d00; font-weight: bold; }
+        table { width: 100%; border-collapse: collapse; margin: 10px 0; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
+        th { background-color: #f2f2f2; }
+        td.up { color: #1e7e34; }
+        td.down { color: #c82333; }
+        details.explain { margin: 8px 10px; color: #495057; }
+        details.explain summary { cursor: pointer; color: #0d6efd; }
+        details.explain p { margin: 6px 0 6px 16px; }` + PrintStyles + `
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>{{t "report.title"}}</h1>
+        <p>{{t "report.header.symbol" .Symbol .GeneratedAt}}</p>
+        <p>{{t "report.header.data" .DataPoints .TimeRange}}</p>
+        {{with .Warmup}}<p>{{if eq .Policy "none"}}{{t "report.header.warmup_none" .Required}}{{else}}{{t "report.header.warmup" .Policy .Bars}}{{if .Truncated}}{{t "report.header.truncated" .Truncated}}{{end}}{{end}}</p>{{end}}
+        <p>{{t "report.header.analyzer" .Version .ComputeSeconds}}{{if .Cached}}{{t "report.header.cached"}}{{end}}</p>
+    </header>
+
+    <main>
+    {{if .Errors}}
+    <section class="section" aria-labelledby="errors">
+        <h2 id="errors">{{t "report.errors"}}</h2>
+        <p>{{t "report.errors.note"}}</p>
+        <ul>
+            {{range .Errors}}<li>{{.}}</li>{{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{if .Warnings}}
+    <section class="section" aria-labelledby="warnings">
+        <h2 id="warnings">{{t "report.warnings"}}</h2>
+        <ul>
+            {{range .Warnings}}<li>{{.}}</li>{{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{range .Sections}}{{.}}{{end}}
+    </main>
+    ` + ExpandOnPrint + `
+</body>
+</html>
+{{define "summary"}}
+    {{if .Performance}}
+    <section class="section" aria-labelledby="performance">
+        <h2 id="performance">{{t "report.performance"}}</h2>
+        <table>
+            <caption class="visually-hidden">{{t "report.performance.note"}}</caption>
+            <tr>{{range .Performance}}<th scope="col">{{.Horizon}}</th>{{end}}</tr>
+            <tr>{{range .Performance}}<td class="{{.Class}}">{{.Value}}</td>{{end}}</tr>
+        </table>
+        {{template "explain" explain "performance"}}
+    </section>
+    {{end}}
+
+    {{if .AllTime}}
+    <section class="section" aria-labelledby="all-time-high-low">
+        <h2 id="all-time-high-low">{{t "report.all_time"}}</h2>
+        {{range .AllTime}}<p>{{.}}</p>{{end}}
+        {{template "explain" explain "drawdown_from_ath"}}
+    </section>
+    {{end}}
+
+    {{if .Narrative}}
+    <section class="section" aria-labelledby="summary">
+        <h2 id="summary">{{t "report.summary"}}</h2>
+        {{range .Narrative}}<p>{{.}}</p>{{end}}
+    </section>
+    {{end}}
+{{end}}
+{{define "price"}}
+    <section class="section" aria-labelledby="current-price-information">
+        <h2 id="current-price-information">{{t "report.price_info"}}</h2>
+        <div class="metric">{{t "report.latest_price" (price .LatestPrice)}}</div>
+        <div class="metric">{{t "report.latest_volume" (humanize .LatestVolume)}}</div>
+    </section>
+
+    <section class="section" aria-labelledby="price-statistics">
+        <h2 id="price-statistics">{{t "report.price_stats"}}</h2>
+        <div class="metric">{{t "report.mean" (price .PriceStats.Mean)}}</div>
+        <div class="metric">{{t "report.median" (price .PriceStats.Median)}}</div>
+        <div class="metric">{{t "report.min" (price .PriceStats.Min)}}</div>
+        <div class="metric">{{t "report.max" (price .PriceStats.Max)}}</div>
+        <div class="metric">{{t "report.std_dev" (price .PriceStats.StdDev)}}</div>
+    </section>
+{{end}}
+{{define "risk"}}
+    <section class="section" aria-labelledby="risk-metrics">
+        <h2 id="risk-metrics">{{t "report.risk"}}</h2>
+        {{if .Confidence}}
+        {{range .Confidence}}<div class="metric">{{.}}</div>{{end}}
+        {{else}}
+        <div class="metric">{{t "report.volatility" (percent .Volatility)}}</div>
+        <div class="metric">{{t "report.sharpe" .SharpeRatio}}</div>
+        {{end}}
+        <div class="metric">{{t "report.max_drawdown" (percent .MaxDrawdown)}}</div>
+        {{template "explain" explain "volatility"}}
+        {{template "explain" explain "sharpe_ratio"}}
+        {{template "explain" explain "max_drawdown"}}
+        {{if .Confidence}}{{template "explain" explain "confidence_interval"}}{{end}}
+    </section>
+
+    {{if .VolEstimators}}
+    <section class="section" aria-labelledby="volatility-estimators">
+        <h2 id="volatility-estimators">{{t "report.vol_estimators"}}</h2>
+        {{range .VolEstimators}}<div class="metric">{{t "report.vol_estimator" .Name (percent .Value)}}{{if .InUse}}{{t "report.in_use"}}{{end}}</div>{{end}}
+        {{if .NoRanges}}<p>{{t "report.no_ranges"}}</p>{{end}}
+    </section>
+    {{end}}
+
+    {{if .Percentiles}}
+    <section class="section" aria-labelledby="percentile-ranks-trailing-year">
+        <h2 id="percentile-ranks-trailing-year">{{t "report.percentiles"}}</h2>
+        {{range .Percentiles}}<p>{{.}}</p>{{end}}
+        {{template "explain" explain "percentile_rank"}}
+    </section>
+    {{end}}
+
+    {{if .TailRisk}}
+    <section class="section" aria-labelledby="tail-risk">
+        <h2 id="tail-risk">{{t "report.tail_risk"}}</h2>
+        {{range .TailRisk}}<p>{{.}}</p>{{end}}
+        {{template "explain" explain "var_95"}}
+        {{template "explain" explain "cdar_95"}}
+        {{template "explain" explain "adjusted_sharpe"}}
+    </section>
+    {{end}}
+
+    {{if .Diagnostics}}
+    <section class="section" aria-labelledby="diagnostics">
+        <h2 id="diagnostics">{{t "report.diagnostics"}}</h2>
+        {{range .Diagnostics}}<p>{{.}}</p>{{end}}
+        {{template "explain" explain "stationarity"}}
+    </section>
+    {{end}}
+{{end}}
+{{define "valuation"}}
+    {{if .Valuation}}
+    <section class="section" aria-labelledby="long-term-valuation">
+        <h2 id="long-term-valuation">{{t "report.valuation"}}</h2>
+        {{range .Valuation}}<p>{{.}}</p>{{end}}
+    </section>
+    {{end}}
+{{end}}
+{{define "statistics"}}
+    {{if .MeanReversion}}
+    <section class="section" aria-labelledby="mean-reversion">
+        <h2 id="mean-reversion">{{t "report.mean_reversion"}}</h2>
+        {{range .MeanReversion}}<p>{{.}}</p>{{end}}
+        {{template "explain" explain "half_life"}}
+    </section>
+    {{end}}
+
+    {{if .Attribution}}
+    <section class="section" aria-labelledby="return-attribution">
+        <h2 id="return-attribution">{{t "report.attribution"}}</h2>
+        {{range .Attribution}}<p>{{.}}</p>{{end}}
+        {{template "explain" explain "return_attribution"}}
+    </section>
+    {{end}}
+{{end}}
+{{define "market"}}
+    {{if .Liquidity}}
+    <section class="section" aria-labelledby="liquidity-ohlcv-estimates">
+        <h2 id="liquidity-ohlcv-estimates">{{t "report.liquidity"}}</h2>
+        {{range .Liquidity}}<p>{{.}}</p>{{end}}
+    </section>
+    {{end}}
+{{end}}
+{{define "levels"}}
+    {{if .SessionGaps}}
+    <section class="section" aria-labelledby="session-gaps">
+        <h2 id="session-gaps">{{t "report.session_gaps"}}</h2>
+        {{range .SessionGaps}}<p>{{.}}</p>{{end}}
+        {{template "explain" explain "session_gaps"}}
+    </section>
+    {{end}}
+{{end}}
+{{define "signals"}}
+    {{if .Signals}}
+    <section class="section" aria-labelledby="trading-signals">
+        <h2 id="trading-signals">{{t "report.signals"}}</h2>
+        <table>
+            <caption class="visually-hidden">{{t "report.signals.caption"}}</caption>
+            <tr><th scope="col">{{t "report.indicator"}}</th><th scope="col">{{t "report.signal"}}</th></tr>
+            {{range $indicator, $signal := .Signals}}
+            <tr>
+                <th scope="row">{{$indicator}}</th>
+                <td class="signal-{{signal $signal}}">{{$signal}}</td>
+            </tr>
+            {{end}}
+        </table>
+        <p>{{t "report.signals.thresholds" .SignalThresholds}}</p>
+        {{template "explain" explain "signal_score"}}
+        {{template "explain" explain "bollinger_bands"}}
+        {{template "explain" explain "support_resistance"}}
+    </section>
+    {{end}}
+{{end}}
+{{define "indicators"}}
+    <section class="section" aria-labelledby="technical-indicators">
+        <h2 id="technical-indicators">{{t "report.technical"}}</h2>
+        {{if .LatestRSI}}
+        <div class="metric">{{t "report.rsi" (printf "%.2f" .LatestRSI)}}</div>
+        {{else}}
+        <div class="metric">{{t "report.rsi" .RSIUnavailable}}</div>
+        {{end}}
+        {{if .LatestROC}}
+        <div class="metric">{{t "report.roc" (pct .LatestROC)}}</div>
+        {{else}}
+        <div class="metric">{{t "report.roc" .ROCUnavailable}}</div>
+        {{end}}
+        {{if .LatestMACD}}
+        <div class="metric">{{t "report.macd" (amount .LatestMACD)}}</div>
+        {{else}}
+        <div class="metric">{{t "report.macd" .MACDUnavailable}}</div>
+        {{end}}
+        {{template "explain" explain "rsi"}}
+        {{template "explain" explain "macd"}}
+        {{template "explain" explain "roc"}}
+        {{if .Skipped}}
+        <h3>{{t "report.skipped"}}</h3>
+        <ul>
+            {{range .Skipped}}<li>{{t "report.skipped.item" .Analysis .Required .Available}}</li>{{end}}
+        </ul>
+        {{end}}
+    </section>
+{{end}}
+{{define "text"}}
+    <section class="section" aria-labelledby="full-text-report">
+        <h2 id="full-text-report">{{t "report.text"}}</h2>
+        <pre aria-label="{{t "report.text.label"}}">{{.TextReport}}</pre>
+    </section>
+{{end}}
+{{define "glossary"}}
+    <section class="section" aria-labelledby="glossary">
+        <h2 id="glossary">{{t "report.glossary"}}</h2>
+        {{range .Glossary}}{{template "explain" .}}{{end}}
+    </section>
+{{end}}
+{{define "explain"}}<details class="explain"><summary>{{.Name}}</summary>
+        <p>{{.Meaning}}</p>
+        <p><b>{{t "report.explain.computed"}}</b> {{.Computation}}</p>
+        {{if .Parameters}}<p><b>{{t "report.explain.parameters"}}</b> {{.Parameters}}</p>{{end}}
+        </details>{{end}}`
+
+	// Prepare template data
+	data := prepareTemplateData(result, rc, msgs)
+	
+	// Create template
+	t, err := template.New("report").Funcs(templateFuncs(result.Series.Denomination, msgs)).Funcs(template.FuncMap{
+		"explain": func(key string) (glossary.Entry, error) {
+			entry, ok := glossary.Lookup(key, result.Analytics.RiskConvention)
+			if !ok {
+				return entry, fmt.Errorf("no glossary entry %q", key)
+			}
+			return entry, nil
+		},
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	
+	// Render the sections in order; those without a template have no part
+	// in this report
+	var sections []template.HTML
+	for _, section := range analyzer.SectionOrder(rc) {
+		if t.Lookup(section) == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := t.ExecuteTemplate(&buf, section, data); err != nil {
+			return nil, fmt.Errorf("failed to execute template %s: %w", section, err)
+		}
+		sections = append(sections, template.HTML(buf.String()))
+	}
+	data["Sections"] = sections
+	
+	// Execute template
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// prepareTemplateData prepares data for HTML template
+func prepareTemplateData(result *types.AnalysisResult, rc types.ReportConfig, msgs *i18n.Catalog) map[string]interface{} {
+	bts, analytics := result.Series, result.Analytics
+	data := make(map[string]interface{})
+	
+	data["Symbol"] = bts.Symbol
+	data["GeneratedAt"] = time.Now().Format("2006-01-02 15:04:05")
+	data["DataPoints"] = len(bts.Data)
+	data["Version"] = result.Metadata.Version
+	data["ComputeSeconds"] = result.Metadata.ComputeSeconds
+	data["Cached"] = result.Metadata.Cached
+	data["Warmup"] = result.Metadata.Warmup
+	data["Warnings"] = result.Metadata.Warnings
+	data["Errors"] = result.Metadata.Errors
+	data["Narrative"] = analyzer.Narrative(result)
+	data["Glossary"] = glossary.Entries(analytics.RiskConvention)
+	
+	if len(bts.Data) > 0 {
+		latest := bts.Data[len(bts.Data)-1]
+		data["LatestPrice"] = latest.Close
+		data["LatestVolume"] = latest.Volume
+		data["TimeRange"] = msgs.T("report.time_range", 
+			bts.Data[0].Timestamp.Format("2006-01-02"),
+			latest.Timestamp.Format("2006-01-02"))
+	}
+	
+	type horizon struct {
+		Horizon, Value, Class string
+	}
+	var performance []horizon
+	for _, hr := range analytics.Performance {
+		h := horizon{Horizon: hr.Horizon, Value: msgs.T("common.not_available")}
+		if hr.Available {
+			h.Value = numfmt.SignedPct(hr.Return)
+			if hr.Return > 0 {
+				h.Class = "up"
+			} else if hr.Return < 0 {
+				h.Class = "down"
+			}
+		}
+		performance = append(performance, h)
+	}
+	data["Performance"] = performance
+	
+	data["PriceStats"] = analytics.PriceStats
+	data["Volatility"] = analytics.Volatility
+	data["SharpeRatio"] = analytics.SharpeRatio
+	data["MaxDrawdown"] = analytics.MaxDrawdown
+	if ci := analytics.Confidence; ci != nil {
+		data["Confidence"] = analyzer.DescribeConfidence(*ci)
+	}
+	
+	if rv := analytics.RealizedVol; rv != nil {
+		type estimate struct {
+			Name  string
+			Value float64
+			InUse bool
+		}
+		var estimates []estimate
+		for _, estimator := range statistics.VolEstimators {
+			if estimator == statistics.EstimatorCloseToClose || rv.HasRanges {
+				estimates = append(estimates, estimate{statistics.EstimatorName(estimator), statistics.EstimatorValue(*rv, estimator), estimator == rv.Estimator})
+			}
+		}
+		data["VolEstimators"] = estimates
+		data["NoRanges"] = !rv.HasRanges
+	}
+	var percentiles []string
+	for _, mp := range analytics.Percentiles {
+		percentiles = append(percentiles, analyzer.DescribePercentile(mp, bts.Denomination))
+	}
+	data["Percentiles"] = percentiles
+	if ltv := analytics.Valuation; ltv != nil {
+		data["Valuation"] = analyzer.DescribeValuation(*ltv, bts.Denomination)
+	}
+	if ate := analytics.AllTime; ate != nil {
+		data["AllTime"] = analyzer.DescribeAllTimeExtremes(*ate, bts.Denomination)
+	}
+	if tr := analytics.TailRisk; tr != nil {
+		data["TailRisk"] = analyzer.DescribeTailRisk(*tr)
+	}
+	if st := analytics.Stationarity; st != nil {
+		data["Diagnostics"] = analyzer.DescribeStationarity(*st)
+	}
+	if ou := analytics.MeanReversion; ou != nil {
+		data["MeanReversion"] = analyzer.DescribeMeanReversion(*ou, bts.Denomination)
+	}
+	if ra := analytics.Attribution; ra != nil {
+		data["Attribution"] = analyzer.DescribeReturnAttribution(*ra)
+	}
+	if le := analytics.Liquidity; le != nil {
+		data["Liquidity"] = analyzer.DescribeLiquidity(*le, analytics.OrderBook)
+	}
+	if sg := analytics.SessionGaps; sg != nil {
+		data["SessionGaps"] = analyzer.DescribeSessionGaps(*sg, bts.Denomination)
+	}
+	
+	if len(analytics.RSI) > 0 {
+		data["LatestRSI"] = analytics.RSI[len(analytics.RSI)-1]
+	}
+	data["RSIUnavailable"] = analyzer.InsufficientData(analyzer.AnalysisRSI, len(bts.Data))
+	
+	if len(analytics.ROC) > 0 {
+		data["LatestROC"] = analytics.ROC[len(analytics.ROC)-1]
+	}
+	data["ROCUnavailable"] = analyzer.InsufficientData(analyzer.AnalysisROC, len(bts.Data))
+	
+	if len(analytics.MACD.MACD) > 0 {
+		data["LatestMACD"] = analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
+	}
+	data["MACDUnavailable"] = analyzer.InsufficientData(analyzer.AnalysisMACD, len(bts.Data))
+	data["Skipped"] = analytics.Skipped
+	
+	data["Signals"] = result.Signals
+	data["SignalThresholds"] = analyzer.DescribeSignalConfig(analyzer.ResolveSignalConfig(result.SignalThresholds))
+	
+	// Generate full text report
+	data["TextReport"] = analyzer.GenerateReport(result, rc)
+	
+	return data
+}
+
+// GenerateJSONReport creates a JSON report: the analysis result with
+// portfolio metrics for a $10k initial investment. Values keep full
+// precision rather than the rounding of the other reports, for further
+// analysis.
+func GenerateJSONReport(result *types.AnalysisResult, filename string) error {
+	report := struct {
+		*types.AnalysisResult
+		PortfolioMetrics map[string]interface{} `json:"portfolio_metrics"`
+	}{result, analyzer.CalculatePortfolioMetrics(result.Series, result.Analytics.RiskConvention, 10000)}
+	
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create JSON report file: %w", err)
+	}
+	defer file.Close()
+	
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	
+	return nil
+}
+
+// PrintSummary prints a brief summary to console in the locale of msgs
+func PrintSummary(result *types.AnalysisResult, msgs *i18n.Catalog) {
+	bts, analytics := result.Series, result.Analytics
+	fmt.Println(msgs.T("summary.title"))
+	
+	if len(bts.Data) > 0 {
+		latest := bts.Data[len(bts.Data)-1]
+		fmt.Println(msgs.T("summary.latest_price", denom.Price(bts.Denomination, latest.Close)))
+		// a chunked analysis reports the bars of the whole file, not of the tail it keeps
+		points := result.Metadata.DataPoints
+		if points == 0 {
+			points = len(bts.Data)
+		}
+		fmt.Println(msgs.T("summary.data_points", points))
+	}
+	
+	if len(analytics.Performance) > 0 {
+		fmt.Print(analyzer.FormatPerformance(analytics.Performance))
+	}
+	
+	fmt.Println(msgs.T("summary.mean_price", denom.Price(bts.Denomination, analytics.PriceStats.Mean)))
+	fmt.Println(msgs.T("summary.price_range", denom.Price(bts.Denomination, analytics.PriceStats.Min), denom.Price(bts.Denomination, analytics.PriceStats.Max)))
+	
+	if ci := analytics.Confidence; ci != nil {
+		fmt.Println(msgs.T("summary.volatility_ci", numfmt.Pct(ci.Volatility.Estimate), ci.Level*100, numfmt.Pct(ci.Volatility.Lower), numfmt.Pct(ci.Volatility.Upper)))
+		fmt.Println(msgs.T("summary.sharpe_ci", ci.SharpeRatio.Estimate, ci.Level*100, ci.SharpeRatio.Lower, ci.SharpeRatio.Upper))
+	} else if analytics.Volatility > 0 {
+		fmt.Println(msgs.T("summary.volatility", numfmt.Pct(analytics.Volatility)))
+		fmt.Println(msgs.T("summary.sharpe", analytics.SharpeRatio))
+	}
+	
+	if len(analytics.RSI) > 0 {
+		fmt.Println(msgs.T("summary.rsi", analytics.RSI[len(analytics.RSI)-1]))
+	}
+	
+	if len(analytics.Skipped) > 0 {
+		fmt.Println(msgs.T("summary.skipped", len(analytics.Skipped), len(bts.Data)))
+	}
+	
+	// Show key signals
+	fmt.Println("\n" + msgs.T("summary.signals"))
+	for indicator, signal := range result.Signals {
+		fmt.Println(msgs.T("summary.signal", indicator, signal))
+	}
+	fmt.Println(msgs.T("summary.thresholds", analyzer.DescribeSignalConfig(analyzer.ResolveSignalConfig(result.SignalThresholds))))
+	
+	fmt.Println(msgs.T("summary.end"))
 }
\ No newline at end of file
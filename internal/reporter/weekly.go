@@ -0,0 +1,158 @@
+package reporter
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+)
+
+const weeklyTemplate = `<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{t "weekly.page_title"}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; max-width: 760px; }
+        .header { background-color: #f8f9fa; padding: 20px; border-radius: 5px; }
+        .section { margin: 20px 0; padding: 15px; border: 1px solid #ddd; border-radius: 5px; }
+        .metric { display: inline-block; margin: 10px; padding: 10px; background-color: #e9ecef; border-radius: 3px; }
+        .up { color: #1e7e34; font-weight: bold; }
+        .down { color: #c82333; font-weight: bold; }
+        table { width: 100%; border-collapse: collapse; margin: 10px 0; }
+        th, td { border: 1px solid #ddd; padding: 6px 8px; text-align: left; }
+        th { background-color: #f2f2f2; }` + PrintStyles + `
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>{{t "weekly.title" .Symbol}}</h1>
+        <p>{{t "weekly.header" (.Week.Start.Format "Jan 2") ((.Week.End.AddDate 0 0 -1).Format "Jan 2, 2006")}}{{if not .Complete}}{{t "weekly.in_progress"}}{{end}} | {{t "weekly.generated" now}}</p>
+        <div class="metric">{{t "weekly.close" (price .Week.Close)}}</div>
+        <div class="metric">{{t "weekly.change"}} <span class="{{updown .Change}}">{{pct .Change}}</span></div>
+        {{if .PriorWeek}}<div class="metric">{{t "weekly.volume"}} <span class="{{updown .VolumeChange}}">{{pct .VolumeChange}}</span></div>{{end}}
+        <div class="metric">{{t "weekly.signal_score" (mul .SignalScore 100)}}</div>
+    </header>
+
+    <main>
+    {{if .Narrative}}
+    <section class="section" aria-labelledby="summary">
+        <h2 id="summary">{{t "report.summary"}}</h2>
+        {{range .Narrative}}<p>{{.}}</p>{{end}}
+    </section>
+    {{end}}
+
+    <section class="section" aria-labelledby="weekly-candle">
+        <h2 id="weekly-candle">{{t "weekly.candle"}}</h2>
+        <table>
+            <tr><th scope="col">{{t "weekly.col.week"}}</th><th scope="col">{{t "col.open"}}</th><th scope="col">{{t "col.high"}}</th><th scope="col">{{t "col.low"}}</th><th scope="col">{{t "col.close"}}</th><th scope="col">{{t "col.volume"}}</th><th scope="col">{{t "weekly.col.bars"}}</th></tr>
+            {{with .PriorWeek}}<tr><td>{{t "weekly.prior" (.Start.Format "Jan 2")}}</td><td>{{price .Open}}</td><td>{{price .High}}</td><td>{{price .Low}}</td><td>{{price .Close}}</td><td>{{humanize .Volume}}</td><td>{{.Bars}}</td></tr>{{end}}
+            {{with .Week}}<tr><td>{{t "weekly.this" (.Start.Format "Jan 2")}}</td><td>{{price .Open}}</td><td>{{price .High}}</td><td>{{price .Low}}</td><td>{{price .Close}}</td><td>{{humanize .Volume}}</td><td>{{.Bars}}</td></tr>{{end}}
+        </table>
+    </section>
+
+    <section class="section" aria-labelledby="what-fired-this-week">
+        <h2 id="what-fired-this-week">{{t "weekly.events"}}</h2>
+        {{if .Events}}
+        <table>
+            {{range .Events}}<tr><td>{{.Timestamp.Format "Mon Jan 2"}}</td><td>{{.Kind}}</td><td>{{.Description}}</td></tr>
+            {{end}}
+        </table>
+        {{else}}
+        <p>{{t "weekly.no_events"}}</p>
+        {{end}}
+        {{with .Diff}}{{if .Events}}
+        <ul>
+            {{range .Events}}<li>{{.}}</li>{{end}}
+        </ul>
+        {{end}}{{end}}
+    </section>
+
+    {{with .Diff}}
+    <section class="section" aria-labelledby="week-over-week-deltas">
+        <h2 id="week-over-week-deltas">{{t "weekly.deltas"}}</h2>
+        <table>
+            <tr><th scope="col">{{t "weekly.col.metric"}}</th><th scope="col">{{t "weekly.col.prior"}}</th><th scope="col">{{t "weekly.col.this"}}</th><th scope="col">{{t "weekly.col.change"}}</th></tr>
+            {{range .Metrics}}<tr><td>{{.Metric}}</td><td>{{metric .Metric .Old}}</td><td>{{metric .Metric .New}}</td><td>{{delta .Metric .Change}}</td></tr>
+            {{end}}
+        </table>
+        {{if .Signals}}
+        <h3>{{t "weekly.signal_changes"}}</h3>
+        <table>
+            <tr><th scope="col">{{t "report.signal"}}</th><th scope="col">{{t "weekly.col.prior"}}</th><th scope="col">{{t "weekly.col.this"}}</th></tr>
+            {{range .Signals}}<tr><td>{{.Signal}}</td><td>{{or .Old "-"}}</td><td>{{or .New "-"}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+    </section>
+    {{end}}
+
+    <section class="section" aria-labelledby="signals-at-week-end">
+        <h2 id="signals-at-week-end">{{t "weekly.signals"}}</h2>
+        <table>
+            {{range signals .Signals}}<tr><td>{{.}}</td><td>{{index $.Signals .}}</td></tr>
+            {{end}}
+        </table>
+    </section>
+    </main>
+</body>
+</html>`
+
+// percentMetrics are the risk metrics of a snapshot diff that are fractions
+var percentMetrics = map[string]bool{"Volatility": true, "Max Drawdown": true}
+
+// GenerateWeeklyReport writes the one-page weekly HTML report to filename in
+// the locale of msgs
+func GenerateWeeklyReport(report *types.WeeklyReport, filename string, msgs *i18n.Catalog) error {
+	d := report.Denomination
+	tmpl, err := template.New("weekly").Funcs(templateFuncs(d, msgs)).Funcs(template.FuncMap{
+		"metric": func(name string, v float64) string {
+			switch {
+			case name == "Price":
+				return denom.Price(d, v)
+			case percentMetrics[name]:
+				return numfmt.Pct(v)
+			case name == "Average Volume":
+				return humanize(v)
+			}
+			return fmt.Sprintf("%.3f", v)
+		},
+		"delta": func(name string, v float64) string {
+			switch {
+			case name == "Price":
+				return numfmt.Signed(denom.Kind(d), v)
+			case percentMetrics[name]:
+				return numfmt.Signed(numfmt.Percent, v*100) + "pp"
+			case name == "Average Volume":
+				return fmt.Sprintf("%+.0f", v)
+			}
+			return fmt.Sprintf("%+.3f", v)
+		},
+		"signals": func(signals map[string]string) []string {
+			names := make([]string, 0, len(signals))
+			for name := range signals {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return names
+		},
+	}).Parse(weeklyTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse weekly report template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return fmt.Errorf("failed to render weekly report: %w", err)
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write weekly report: %w", err)
+	}
+	return nil
+}
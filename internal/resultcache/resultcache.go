@@ -0,0 +1,120 @@
+// Package resultcache stores computed analytics on disk, keyed by a hash of
+// everything that went into them, so an unchanged analysis is not recomputed
+package resultcache
+
+import (
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/version"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// formatVersion changes when the layout of cached entries changes
+const formatVersion = 2
+
+// DefaultDir returns the default cache directory
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "btc-analyzer", "results")
+}
+
+// Key hashes the JSON encoding of parts together with the cache format and
+// the VCS revision the binary was built from, so a rebuilt analyzer does not
+// reuse results of an older one
+func Key(parts ...interface{}) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d %s %s\n", formatVersion, version.Version, version.Revision())
+	enc := json.NewEncoder(h)
+	for _, part := range parts {
+		if err := enc.Encode(part); err != nil {
+			return "", fmt.Errorf("failed to hash analysis inputs: %w", err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// path returns the file of the entry with the given key
+func path(dir, key string) string {
+	return filepath.Join(dir, key+".json")
+}
+
+// Load returns the result cached under key. The series is not cached and
+// left nil.
+func Load(dir, key string) (*types.AnalysisResult, bool) {
+	data, err := os.ReadFile(path(dir, key))
+	if err != nil {
+		return nil, false
+	}
+	var result types.AnalysisResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// Save caches result under key. The entry is written to a temporary file
+// and renamed, so a concurrent Load never sees a partial entry.
+func Save(dir, key string, result *types.AnalysisResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode analysis result: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create result cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write result cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write result cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write result cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path(dir, key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write result cache: %w", err)
+	}
+	return nil
+}
+
+// Stats summarizes the entries of a result cache directory
+type Stats struct {
+	Entries int   // entries that decode
+	Corrupt int   // entries that are not valid JSON
+	Bytes   int64 // size of every entry
+}
+
+// Verify checks every entry in dir. A missing directory is an empty cache.
+func Verify(dir string) (Stats, error) {
+	var stats Stats
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return stats, fmt.Errorf("failed to list result cache: %w", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return stats, fmt.Errorf("failed to read result cache: %w", err)
+		}
+		stats.Bytes += int64(len(data))
+		if json.Valid(data) {
+			stats.Entries++
+		} else {
+			stats.Corrupt++
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Task is the work of a job
+type Task func() error
+
+// JobStatus reports the schedule and last run of a job
+type JobStatus struct {
+	Name         string
+	Schedule     string
+	Running      bool
+	Next         time.Time
+	LastStart    time.Time
+	LastDuration time.Duration
+	LastError    string `json:",omitempty"`
+	Runs         int
+	Failures     int
+	Skipped      int // runs not started because the previous one was still going
+}
+
+// job is a scheduled task with its run state
+type job struct {
+	schedule *Schedule
+	task     Task
+	status   JobStatus
+}
+
+// Scheduler runs named tasks on cron schedules. A job whose previous run is
+// still going when it comes due again is skipped rather than run twice.
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs map[string]*job
+	wake chan struct{}
+}
+
+// New returns an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*job), wake: make(chan struct{}, 1)}
+}
+
+// Add schedules task under name on the cron expression spec
+func (s *Scheduler) Add(name, spec string, task Task) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("duplicate job %q", name)
+	}
+	s.jobs[name] = &job{
+		schedule: schedule,
+		task:     task,
+		status:   JobStatus{Name: name, Schedule: spec, Next: schedule.Next(time.Now())},
+	}
+
+	// Let a running loop recompute its next wake-up
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run starts due jobs until stop is closed
+func (s *Scheduler) Run(stop <-chan struct{}) {
+	for {
+		now := time.Now()
+		next := now.Add(time.Hour)
+
+		s.mu.Lock()
+		for _, j := range s.jobs {
+			if !j.status.Next.IsZero() && !j.status.Next.After(now) {
+				s.start(j, now)
+				j.status.Next = j.schedule.Next(now)
+			}
+			if !j.status.Next.IsZero() && j.status.Next.Before(next) {
+				next = j.status.Next
+			}
+		}
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// RunNow starts a job immediately, outside its schedule
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("unknown job %q", name)
+	}
+	if j.status.Running {
+		return fmt.Errorf("job %q is already running", name)
+	}
+	s.start(j, time.Now())
+	return nil
+}
+
+// start runs j in the background unless it is still running. The caller holds the lock.
+func (s *Scheduler) start(j *job, now time.Time) {
+	if j.status.Running {
+		j.status.Skipped++
+		log.Printf("⏭️  Job %s still running, skipping this run", j.status.Name)
+		return
+	}
+	j.status.Running = true
+	j.status.LastStart = now
+
+	go func() {
+		err := j.task()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		j.status.Running = false
+		j.status.LastDuration = time.Since(now)
+		j.status.Runs++
+		j.status.LastError = ""
+		if err != nil {
+			j.status.Failures++
+			j.status.LastError = err.Error()
+			log.Printf("Job %s failed: %v", j.status.Name, err)
+		}
+	}()
+}
+
+// Status returns the status of every job, ordered by name
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status)
+	}
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].Name < statuses[k].Name })
+	return statuses
+}
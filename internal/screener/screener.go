@@ -0,0 +1,267 @@
+// Package screener computes a light set of indicators on the daily bars of
+// many coins and keeps the coins whose latest values match a filter
+// expression
+package screener
+
+import (
+	"btc-analyzer/internal/expr"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Variable is a value a filter can use, computed for each coin by an expr
+// formula over its daily bars. Kind is the numfmt kind its values are
+// rounded to in the results, "" for plain numbers.
+type Variable struct {
+	Name        string
+	Formula     string
+	Description string
+	Kind        string
+}
+
+// Variables are computed in order, so a formula can use those before it.
+// Besides them a filter can use the open, high, low, close and volume
+// series, the coin's market cap rank and its market cap in USD.
+var Variables = []Variable{
+	{"price", "close", "latest close in USD", numfmt.USD},
+	{"change_24h", "(close / close[1] - 1) * 100", "change over a day, in percent", numfmt.Percent},
+	{"change_7d", "(close / close[7] - 1) * 100", "change over 7 days, in percent", numfmt.Percent},
+	{"change_30d", "(close / close[30] - 1) * 100", "change over 30 days, in percent", numfmt.Percent},
+	{"rsi", "rsi(close, 14)", "RSI (14)", ""},
+	{"volume_zscore", "(volume - sma(volume, 30)) / stdev(volume, 30)", "standard deviations of the day's volume from its 30-day mean", ""},
+	{"sma_50", "sma(close, 50)", "50-day simple moving average", numfmt.USD},
+	{"sma_200", "sma(close, 200)", "200-day simple moving average", numfmt.USD},
+	{"macd", "ema(close, 12) - ema(close, 26)", "MACD line (12, 26)", numfmt.USD},
+	{"macd_signal", "ema(macd, 9)", "MACD signal line (9)", numfmt.USD},
+	{"macd_hist", "macd - macd_signal", "MACD histogram", numfmt.USD},
+	{"volatility", "stdev(log(close / close[1]), 30) * sqrt(365) * 100", "annualized volatility of 30 daily returns, in percent", numfmt.Percent},
+	{"drawdown", "(close / highest(close, 90) - 1) * 100", "distance below the highest close of 90 days, in percent", numfmt.Percent},
+}
+
+// barVars are the bar series a filter can use
+var barVars = []string{"open", "high", "low", "close", "volume"}
+
+// Scalars of the coin's market listing a filter can use
+const (
+	VarRank      = "rank"
+	VarMarketCap = "market_cap"
+)
+
+// DefaultColumns are the variables every result row shows
+var DefaultColumns = []string{"price", "change_24h", "change_7d", "rsi", "volume_zscore"}
+
+// Coin is one coin of the screened universe with its price history
+type Coin struct {
+	Market types.CoinMarket
+	Series *types.BTCTimeSeries
+}
+
+// Row holds the latest values of the variables of a coin. Values undefined
+// on the latest bar, e.g. a 200-day average of a shorter history, are nil.
+type Row struct {
+	ID        string              `json:"id"`
+	Symbol    string              `json:"symbol"`
+	Name      string              `json:"name"`
+	Rank      int                 `json:"rank"`
+	MarketCap float64             `json:"market_cap"`
+	AsOf      time.Time           `json:"as_of"` // latest daily bar
+	Values    map[string]*float64 `json:"values"`
+}
+
+// Screen is a parsed filter
+type Screen struct {
+	filter   *expr.Program
+	formulas []*expr.Program
+}
+
+// Kind returns the numfmt kind of the values of variable name, "" for plain
+// numbers such as the RSI
+func Kind(name string) string {
+	if name == VarMarketCap {
+		return numfmt.USD
+	}
+	for _, v := range Variables {
+		if v.Name == name {
+			return v.Kind
+		}
+	}
+	return ""
+}
+
+// Rounded returns row with its values and market cap rounded to the
+// precision of their kinds
+func (row Row) Rounded() Row {
+	values := make(map[string]*float64, len(row.Values))
+	for name, v := range row.Values {
+		if v != nil && Kind(name) != "" {
+			rounded := numfmt.Round(Kind(name), *v)
+			v = &rounded
+		}
+		values[name] = v
+	}
+	row.Values = values
+	row.MarketCap = numfmt.Round(numfmt.USD, row.MarketCap)
+	return row
+}
+
+// Names returns every name a filter can use
+func Names() []string {
+	names := append([]string{}, barVars...)
+	for _, v := range Variables {
+		names = append(names, v.Name)
+	}
+	return append(names, VarRank, VarMarketCap)
+}
+
+// New parses filter, an expr expression evaluated on the latest daily bar of
+// each coin. An empty filter matches every coin.
+func New(filter string) (*Screen, error) {
+	s := &Screen{}
+	for _, v := range Variables {
+		program, err := expr.Parse(v.Formula)
+		if err != nil {
+			return nil, fmt.Errorf("invalid formula of %s: %w", v.Name, err)
+		}
+		s.formulas = append(s.formulas, program)
+	}
+	if strings.TrimSpace(filter) == "" {
+		return s, nil
+	}
+
+	program, err := expr.Parse(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	names := Names()
+	for _, name := range program.Vars() {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("unknown filter variable %q: use %s", name, strings.Join(names, ", "))
+		}
+	}
+	s.filter = program
+	return s, nil
+}
+
+// Columns returns the variables the rows show: the default ones followed by
+// the others the filter uses, without the bar series and the rank every row
+// has
+func (s *Screen) Columns() []string {
+	columns := append([]string{}, DefaultColumns...)
+	if s.filter == nil {
+		return columns
+	}
+	for _, name := range s.filter.Vars() {
+		if !slices.Contains(columns, name) && !slices.Contains(barVars, name) && name != VarRank {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// Evaluate computes the variables of coin on its daily bars and reports
+// whether its latest bar matches the filter
+func (s *Screen) Evaluate(coin Coin) (Row, bool, error) {
+	row := Row{
+		ID:        coin.Market.ID,
+		Symbol:    strings.ToUpper(coin.Market.Symbol),
+		Name:      coin.Market.Name,
+		Rank:      coin.Market.MarketCapRank,
+		MarketCap: coin.Market.MarketCap,
+		Values:    make(map[string]*float64),
+	}
+	if coin.Series == nil || len(coin.Series.Data) < 2 {
+		return row, false, fmt.Errorf("fewer than 2 bars of history")
+	}
+	daily := timeseries.Resample(coin.Series, 24*time.Hour)
+	n := len(daily.Data)
+	row.AsOf = daily.Data[n-1].Timestamp
+
+	env := &expr.Env{Len: n, Vars: make(map[string][]float64)}
+	for _, name := range barVars {
+		env.Vars[name] = make([]float64, n)
+	}
+	env.Vars[VarRank] = make([]float64, n)
+	env.Vars[VarMarketCap] = make([]float64, n)
+	for i, p := range daily.Data {
+		env.Vars["open"][i] = p.Open
+		env.Vars["high"][i] = p.High
+		env.Vars["low"][i] = p.Low
+		env.Vars["close"][i] = p.Close
+		env.Vars["volume"][i] = p.Volume
+		env.Vars[VarRank][i] = float64(coin.Market.MarketCapRank)
+		env.Vars[VarMarketCap][i] = coin.Market.MarketCap
+	}
+	for i, v := range Variables {
+		values, err := s.formulas[i].Eval(env)
+		if err != nil {
+			return row, false, fmt.Errorf("failed to compute %s: %w", v.Name, err)
+		}
+		env.Vars[v.Name] = values
+		row.Values[v.Name] = latest(values)
+	}
+
+	if s.filter == nil {
+		return row, true, nil
+	}
+	values, err := s.filter.Eval(env)
+	if err != nil {
+		return row, false, fmt.Errorf("failed to evaluate filter: %w", err)
+	}
+	return row, expr.Truthy(values[n-1]), nil
+}
+
+// latest returns the last value of values, nil when it is undefined
+func latest(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	v := values[len(values)-1]
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
+
+// SortRows sorts rows by variable by, or by rank, market_cap or symbol,
+// ascending unless descending. Rows without a value come last.
+func SortRows(rows []Row, by string, descending bool) error {
+	key := func(r Row) *float64 { return r.Values[by] }
+	switch {
+	case by == VarRank:
+		key = func(r Row) *float64 {
+			rank := float64(r.Rank)
+			return &rank
+		}
+	case by == VarMarketCap:
+		key = func(r Row) *float64 { return &r.MarketCap }
+	case by == "symbol":
+		sort.SliceStable(rows, func(i, j int) bool {
+			if descending {
+				return rows[i].Symbol > rows[j].Symbol
+			}
+			return rows[i].Symbol < rows[j].Symbol
+		})
+		return nil
+	case !slices.ContainsFunc(Variables, func(v Variable) bool { return v.Name == by }):
+		return fmt.Errorf("unknown sort key %q: use symbol, %s, %s or a variable", by, VarRank, VarMarketCap)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := key(rows[i]), key(rows[j])
+		switch {
+		case a == nil || b == nil:
+			return a != nil
+		case descending:
+			return *a > *b
+		}
+		return *a < *b
+	})
+	return nil
+}
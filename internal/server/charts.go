@@ -0,0 +1,203 @@
+package server
+
+import (
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/visualizer"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bounds of the width and height of a chart rendered on request, in pixels
+const (
+	minChartSize = 200
+	maxChartSize = 4000
+)
+
+// chartTitles are the charts served under /charts, with their titles
+var chartTitles = map[string]string{
+	"price":  "Price",
+	"levels": "Support & Resistance Strength",
+}
+
+// registerChartRoutes adds the charts of the served analysis, rendered on
+// request:
+//
+//	GET /charts/price.{png,svg}    the close price with indicators
+//	GET /charts/levels.{png,svg}   the close price with support and resistance levels
+//
+// Query parameters: from and to bound the bars shown (dates, with to
+// including its day, or RFC 3339 times; default the whole series),
+// indicators lists those of the price chart (bb, rsi, macd, volume), width
+// and height set the size in pixels (default 1000x600) and log=true selects
+// a log price scale. Charts may be loaded from any origin.
+func (s *Server) registerChartRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /charts/{file}", s.handleChart)
+}
+
+func (s *Server) handleChart(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+	title, ok := chartTitles[name]
+	if !ok || (ext != ".png" && ext != ".svg") {
+		http.Error(w, "unknown chart: "+file, http.StatusNotFound)
+		return
+	}
+	result, _, ok := s.snapshot()
+	if !ok || result.Series == nil {
+		http.Error(w, "no analysis available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	config, indicators, from, to, err := parseChartQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	config.Title = fmt.Sprintf("%s %s", result.Series.Symbol, title)
+	config.Format = strings.TrimPrefix(ext, ".")
+	bts, analytics, err := chartWindow(result, from, to)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var chart []byte
+	switch name {
+	case "price":
+		chart, err = visualizer.DrawPriceChart(bts, analytics, indicators, config)
+	case "levels":
+		chart, err = visualizer.DrawSupportResistanceChart(bts, analytics.LevelMap, config)
+	}
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "no-cache")
+	if config.Format == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	w.Write(chart)
+}
+
+// parseChartQuery reads the chart config, indicators and time bounds of a
+// chart request; zero bounds are open
+func parseChartQuery(r *http.Request) (config visualizer.ChartConfig, indicators []string, from, to time.Time, err error) {
+	query := r.URL.Query()
+	config = visualizer.DefaultChartConfig()
+	config.XLabel = "Candle"
+	for _, size := range []struct {
+		param string
+		value *int
+	}{
+		{"width", &config.Width},
+		{"height", &config.Height},
+	} {
+		if v := query.Get(size.param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < minChartSize || n > maxChartSize {
+				return config, nil, from, to, fmt.Errorf("invalid %s %q: use %d to %d pixels", size.param, v, minChartSize, maxChartSize)
+			}
+			*size.value = n
+		}
+	}
+	if v := query.Get("log"); v != "" {
+		if config.LogScale, err = strconv.ParseBool(v); err != nil {
+			return config, nil, from, to, fmt.Errorf("invalid log %q: use true or false", v)
+		}
+	}
+	for _, name := range strings.Split(query.Get("indicators"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			continue
+		}
+		if !slices.Contains(visualizer.PriceIndicators, name) {
+			return config, nil, from, to, fmt.Errorf("unknown indicator %q: use %s", name, strings.Join(visualizer.PriceIndicators, ", "))
+		}
+		indicators = append(indicators, name)
+	}
+	if from, err = parseChartTime("from", query.Get("from")); err != nil {
+		return config, nil, from, to, err
+	}
+	if to, err = parseChartTime("to", query.Get("to")); err != nil {
+		return config, nil, from, to, err
+	}
+	if len(query.Get("to")) == len("2006-01-02") {
+		// a date bound includes the whole day
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return config, nil, from, to, fmt.Errorf("to is before from")
+	}
+	return config, indicators, from, to, nil
+}
+
+// parseChartTime parses the time bound param, a date or an RFC 3339 time;
+// zero when empty
+func parseChartTime(param, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q: use a date (2006-01-02) or an RFC 3339 time", param, value)
+}
+
+// chartWindow returns the bars of the served series from from to to,
+// inclusive, with the indicators of its analytics cut to the same bars.
+// Indicators keep the values computed over the whole series, so the first
+// bars of a window do not restart their warm-up.
+func chartWindow(result *types.AnalysisResult, from, to time.Time) (*types.BTCTimeSeries, types.BTCAnalytics, error) {
+	data := result.Series.Data
+	start, end := 0, len(data)
+	for start < end && !from.IsZero() && data[start].Timestamp.Before(from) {
+		start++
+	}
+	for end > start && !to.IsZero() && data[end-1].Timestamp.After(to) {
+		end--
+	}
+	if end-start < 2 {
+		return nil, types.BTCAnalytics{}, fmt.Errorf("fewer than 2 bars between from and to")
+	}
+
+	bts := *result.Series
+	bts.Data = data[start:end]
+	analytics := result.Analytics
+	window := func(values []float64) []float64 {
+		return alignedWindow(values, len(data), start, end)
+	}
+	analytics.RSI = window(analytics.RSI)
+	analytics.MACD = types.MACDData{MACD: window(analytics.MACD.MACD), Signal: window(analytics.MACD.Signal), Histogram: window(analytics.MACD.Histogram)}
+	analytics.BollingerBands = types.BollingerBandsData{
+		Upper:  window(analytics.BollingerBands.Upper),
+		Middle: window(analytics.BollingerBands.Middle),
+		Lower:  window(analytics.BollingerBands.Lower),
+	}
+	return &bts, analytics, nil
+}
+
+// alignedWindow returns the values of an indicator that end at the last of
+// bars bars which fall on the bars from start to end, exclusive; they end at
+// the last bar of the window
+func alignedWindow(values []float64, bars, start, end int) []float64 {
+	offset := bars - len(values)
+	first, last := start-offset, end-offset
+	if first < 0 {
+		first = 0
+	}
+	if last <= first {
+		return nil
+	}
+	return values[first:last]
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"btc-analyzer/internal/reporter"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// registerEmbedRoutes adds the embeddable widgets of the served analysis:
+//
+//	GET /embed/{widget}.html   the widget as a standalone page for an iframe
+//	GET /embed/{widget}.json   the data behind the widget
+//
+// Both may be loaded from any origin.
+func (s *Server) registerEmbedRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /embed/{file}", s.handleEmbed)
+}
+
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	name, ext := strings.TrimSuffix(file, ".json"), "json"
+	if name == file {
+		name, ext = strings.TrimSuffix(file, ".html"), "html"
+	}
+	if !slices.Contains(reporter.Widgets, name) {
+		http.Error(w, "unknown widget: "+file, http.StatusNotFound)
+		return
+	}
+	result, _, ok := s.snapshot()
+	if !ok {
+		http.Error(w, "no analysis available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if ext == "json" {
+		data, err := reporter.WidgetData(name, result)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, data)
+		return
+	}
+	page, err := reporter.RenderWidget(name, result, s.catalog())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"btc-analyzer/internal/execution"
+	"net/http"
+)
+
+// SetExecution enables the execution routes, served from e
+func (s *Server) SetExecution(e *execution.Executor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.execution = e
+}
+
+// Execution returns the order executor, or nil when execution is off
+func (s *Server) Execution() *execution.Executor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.execution
+}
+
+// registerExecutionRoutes adds the execution API:
+//
+//	GET  /api/execution        mode, kill switch, position and recent orders
+//	POST /api/execution/kill   engage the kill switch
+func (s *Server) registerExecutionRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/execution", s.handleExecution)
+	mux.HandleFunc("POST /api/execution/kill", s.handleKill)
+}
+
+func (s *Server) handleExecution(w http.ResponseWriter, r *http.Request) {
+	e := s.Execution()
+	if e == nil {
+		writeError(w, http.StatusNotFound, "execution is not enabled")
+		return
+	}
+	status, err := e.Status(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleKill(w http.ResponseWriter, r *http.Request) {
+	e := s.Execution()
+	if e == nil {
+		writeError(w, http.StatusNotFound, "execution is not enabled")
+		return
+	}
+	if err := e.Kill(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "killed"})
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"time"
+)
+
+// Health is the response of the health check
+type Health struct {
+	Status     string    `json:"status"` // "ok", "starting" or "stale"
+	Updated    time.Time `json:"updated,omitempty"`
+	AgeSeconds float64   `json:"age_seconds,omitempty"`
+}
+
+// SetHealthMaxAge makes the health check fail once the served analysis is
+// older than maxAge (0 = never)
+func (s *Server) SetHealthMaxAge(maxAge time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthMaxAge = maxAge
+}
+
+// registerHealthRoutes adds the health check for container orchestrators:
+//
+//	GET /healthz   200 while an analysis is served and fresh, 503 otherwise
+func (s *Server) registerHealthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	ok, updated, maxAge := s.result != nil, s.updated, s.healthMaxAge
+	s.mu.RUnlock()
+
+	if !ok {
+		writeJSON(w, http.StatusServiceUnavailable, Health{Status: "starting"})
+		return
+	}
+	health := Health{Status: "ok", Updated: updated, AgeSeconds: time.Since(updated).Seconds()}
+	status := http.StatusOK
+	if maxAge > 0 && time.Since(updated) > maxAge {
+		health.Status = "stale"
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"btc-analyzer/internal/scheduler"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SetScheduler enables the job routes, served from sch
+func (s *Server) SetScheduler(sch *scheduler.Scheduler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduler = sch
+}
+
+// registerJobRoutes adds the scheduler API:
+//
+//	GET  /api/jobs              status of every scheduled job
+//	POST /api/jobs/{name}/run   run a job now
+//	GET  /metrics               job status in the Prometheus text format
+func (s *Server) registerJobRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/jobs", s.handleJobs)
+	mux.HandleFunc("POST /api/jobs/{name}/run", s.handleRunJob)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+}
+
+// currentScheduler returns the scheduler, or nil when none is set
+func (s *Server) currentScheduler() *scheduler.Scheduler {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.scheduler
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	statuses := []scheduler.JobStatus{}
+	if sch := s.currentScheduler(); sch != nil {
+		statuses = sch.Status()
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) handleRunJob(w http.ResponseWriter, r *http.Request) {
+	sch := s.currentScheduler()
+	if sch == nil {
+		writeError(w, http.StatusNotFound, "no jobs are scheduled")
+		return
+	}
+	if err := sch.RunNow(r.PathValue("name")); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+
+	s.mu.RLock()
+	ok, updated := s.result != nil, s.updated
+	s.mu.RUnlock()
+	fmt.Fprintf(&b, "# HELP btc_analyzer_up Whether an analysis is being served.\n# TYPE btc_analyzer_up gauge\n")
+	fmt.Fprintf(&b, "btc_analyzer_up %d\n", boolMetric(ok))
+	if ok {
+		fmt.Fprintf(&b, "# HELP btc_analyzer_last_update_timestamp_seconds Time of the last served analysis.\n# TYPE btc_analyzer_last_update_timestamp_seconds gauge\n")
+		fmt.Fprintf(&b, "btc_analyzer_last_update_timestamp_seconds %d\n", updated.Unix())
+	}
+
+	if sch := s.currentScheduler(); sch != nil {
+		statuses := sch.Status()
+		metrics := []struct {
+			name, help, kind string
+			value            func(scheduler.JobStatus) float64
+		}{
+			{"btc_analyzer_job_running", "Whether the job is running.", "gauge",
+				func(j scheduler.JobStatus) float64 { return float64(boolMetric(j.Running)) }},
+			{"btc_analyzer_job_runs_total", "Completed runs of the job.", "counter",
+				func(j scheduler.JobStatus) float64 { return float64(j.Runs) }},
+			{"btc_analyzer_job_failures_total", "Failed runs of the job.", "counter",
+				func(j scheduler.JobStatus) float64 { return float64(j.Failures) }},
+			{"btc_analyzer_job_skipped_total", "Runs skipped because the job was still running.", "counter",
+				func(j scheduler.JobStatus) float64 { return float64(j.Skipped) }},
+			{"btc_analyzer_job_last_success", "Whether the last completed run succeeded.", "gauge",
+				func(j scheduler.JobStatus) float64 { return float64(boolMetric(j.Runs > 0 && j.LastError == "")) }},
+			{"btc_analyzer_job_last_start_timestamp_seconds", "Start time of the last run.", "gauge",
+				func(j scheduler.JobStatus) float64 { return float64(j.LastStart.Unix()) }},
+			{"btc_analyzer_job_last_duration_seconds", "Duration of the last completed run.", "gauge",
+				func(j scheduler.JobStatus) float64 { return j.LastDuration.Seconds() }},
+		}
+		for _, m := range metrics {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+			for _, j := range statuses {
+				if m.name == "btc_analyzer_job_last_start_timestamp_seconds" && j.LastStart.IsZero() {
+					continue
+				}
+				fmt.Fprintf(&b, "%s{job=%q} %g\n", m.name, j.Name, m.value(j))
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+// boolMetric converts a bool to a 0/1 metric value
+func boolMetric(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/watchlist"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// overviewRefreshSeconds is the reload interval of the served overview page
+const overviewRefreshSeconds = 60
+
+// registerOverviewRoutes adds the overview of every analyzed symbol, the
+// served series and each watchlist, and their detailed reports:
+//
+//	GET /overview[?owner=]                the overview page, one row per symbol
+//	GET /overview.json[?owner=]           its rows
+//	GET /report.html                      the HTML report of the served series
+//	GET /watchlists/{id}/report.html      the HTML report of a watchlist
+//
+// owner limits the watchlists to those of one owner.
+func (s *Server) registerOverviewRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /overview", s.handleOverview)
+	mux.HandleFunc("GET /overview.json", s.handleOverview)
+	mux.HandleFunc("GET /report.html", s.handleHTMLReport)
+	mux.HandleFunc("GET /watchlists/{id}/report.html", s.withWatchlists(s.handleWatchlistHTMLReport))
+}
+
+func (s *Server) handleOverview(w http.ResponseWriter, r *http.Request) {
+	rows := s.overviewRows(r.URL.Query().Get("owner"))
+	if r.URL.Path == "/overview.json" {
+		writeJSON(w, http.StatusOK, rows)
+		return
+	}
+	page, err := reporter.RenderOverview(rows, overviewRefreshSeconds, s.catalog())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// overviewRows returns the overview row of the served series followed by
+// those of the watchlists of owner, all of them for "". Links are relative
+// to /overview.
+func (s *Server) overviewRows(owner string) []reporter.OverviewRow {
+	rows := []reporter.OverviewRow{}
+	if result, ok := s.Current(); ok {
+		rows = append(rows, reporter.NewOverviewRow(result.Metadata.Symbol, "report.html", result, nil))
+	}
+
+	s.mu.RLock()
+	m := s.watchlists
+	s.mu.RUnlock()
+	if m == nil {
+		return rows
+	}
+	for _, entry := range m.Store().List(owner) {
+		name := fmt.Sprintf("%s %s", entry.Symbol, entry.Timeframe)
+		var err error
+		if entry.LastError != "" {
+			err = errors.New(entry.LastError)
+		}
+		if result, ok := m.Result(entry.ID); ok {
+			rows = append(rows, reporter.NewOverviewRow(name, "watchlists/"+entry.ID+"/report.html", result.Analysis, err))
+		} else {
+			rows = append(rows, reporter.NewOverviewRow(name, "", nil, err))
+		}
+	}
+	return rows
+}
+
+func (s *Server) handleHTMLReport(w http.ResponseWriter, r *http.Request) {
+	result, ok := s.Current()
+	if !ok {
+		http.Error(w, "no analysis available yet", http.StatusServiceUnavailable)
+		return
+	}
+	s.writeHTMLReport(w, result)
+}
+
+func (s *Server) handleWatchlistHTMLReport(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := m.Store().Get(id); !ok {
+		http.Error(w, "unknown watchlist", http.StatusNotFound)
+		return
+	}
+	result, ok := m.Result(id)
+	if !ok {
+		http.Error(w, "no analysis available yet", http.StatusServiceUnavailable)
+		return
+	}
+	s.writeHTMLReport(w, result.Analysis)
+}
+
+// writeHTMLReport renders the HTML report of result with the served report
+// sections and locale
+func (s *Server) writeHTMLReport(w http.ResponseWriter, result *types.AnalysisResult) {
+	s.mu.RLock()
+	rc := s.report
+	s.mu.RUnlock()
+	page, err := reporter.RenderHTMLReport(result, rc, s.catalog())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"btc-analyzer/internal/paper"
+	"net/http"
+)
+
+// SetPaper enables the paper trading route, served from t
+func (s *Server) SetPaper(t *paper.Trader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paper = t
+}
+
+// Paper returns the paper trader, or nil when paper trading is off
+func (s *Server) Paper() *paper.Trader {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paper
+}
+
+// registerPaperRoutes adds the paper trading API:
+//
+//	GET /api/paper   the paper account with its fills and equity history
+func (s *Server) registerPaperRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/paper", s.handlePaper)
+}
+
+func (s *Server) handlePaper(w http.ResponseWriter, r *http.Request) {
+	t := s.Paper()
+	if t == nil {
+		writeError(w, http.StatusNotFound, "paper trading is not enabled")
+		return
+	}
+	writeJSON(w, http.StatusOK, t.Account())
+}
@@ -0,0 +1,369 @@
+package server
+
+import (
+	"btc-analyzer/internal/execution"
+	"btc-analyzer/internal/graphql"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/paper"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/scheduler"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/watchlist"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//go:embed web/index.html
+var webFiles embed.FS
+
+// maxHistory is the number of signal snapshots kept for the web UI
+const maxHistory = 1000
+
+// SignalSnapshot records the signals of one analysis run
+type SignalSnapshot struct {
+	Time    time.Time
+	Price   float64
+	Score   float64
+	Signals map[string]string
+}
+
+// AnalyzeFunc re-runs the analysis with parameter overrides by flag name,
+// e.g. {"days": "180"}, and publishes the result with Update
+type AnalyzeFunc func(params map[string]string) error
+
+// Server serves the latest analysis of a series over HTTP
+type Server struct {
+	mu         sync.RWMutex
+	result     *types.AnalysisResult
+	root       interface{} // JSON-decoded report that GraphQL queries resolve against
+	updated    time.Time
+	history    []SignalSnapshot
+	analyze    AnalyzeFunc
+	watchlists *watchlist.Manager
+	scheduler  *scheduler.Scheduler
+	paper      *paper.Trader
+	execution  *execution.Executor
+	messages   *i18n.Catalog
+	report     types.ReportConfig
+	subs       map[chan *types.AnalysisResult]struct{}
+	series     *timeseries.SafeSeries // of result, for readers outside the HTTP handlers
+
+	healthMaxAge time.Duration
+}
+
+// New returns a server with no analysis loaded yet
+func New() *Server {
+	return &Server{series: timeseries.NewSafe("")}
+}
+
+// SetMessages sets the locale of the served HTML pages (nil = English)
+func (s *Server) SetMessages(msgs *i18n.Catalog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = msgs
+}
+
+// SetReportConfig sets the sections of the served HTML reports
+func (s *Server) SetReportConfig(rc types.ReportConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report = rc
+}
+
+// catalog returns the message catalog of the served HTML pages
+func (s *Server) catalog() *i18n.Catalog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.messages
+}
+
+// Update replaces the served analysis result
+func (s *Server) Update(result *types.AnalysisResult) error {
+	root, err := buildReport(result)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result = result
+	s.root = root
+	if result.Series != nil {
+		s.series.Replace(result.Series)
+	}
+	s.updated = time.Now()
+
+	snapshot := SignalSnapshot{
+		Time:    s.updated,
+		Price:   result.Metadata.LatestPrice,
+		Score:   result.SignalScore,
+		Signals: result.Signals,
+	}
+	s.history = append(s.history, snapshot)
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+	for ch := range s.subs {
+		// a subscriber still busy with the previous result gets the latest one
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- result
+	}
+	return nil
+}
+
+// Subscribe returns a channel that receives every result published with
+// Update from now on, and a function that stops the subscription. A slow
+// subscriber only receives the latest result.
+func (s *Server) Subscribe() (<-chan *types.AnalysisResult, func()) {
+	ch := make(chan *types.AnalysisResult, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[chan *types.AnalysisResult]struct{})
+	}
+	s.subs[ch] = struct{}{}
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, ch)
+	}
+}
+
+// buildReport returns the report of an analysis as JSON-decoded values, the
+// form GraphQL queries resolve against
+func buildReport(result *types.AnalysisResult) (interface{}, error) {
+	report := map[string]interface{}{
+		"symbol":      result.Metadata.Symbol,
+		"generatedAt": result.Metadata.GeneratedAt.Format(time.RFC3339),
+		"metadata":    result.Metadata,
+		"series":      result.Series.Data,
+		"analytics":   result.Analytics,
+		"signals":     result.Signals,
+		"signalScore": result.SignalScore,
+	}
+	if result.Metadata.DataPoints > 0 {
+		report["latestPrice"] = result.Metadata.LatestPrice
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	var root interface{}
+	if err := json.Unmarshal(encoded, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	return root, nil
+}
+
+// OnAnalyze sets the function the /api/analyze endpoint calls
+func (s *Server) OnAnalyze(fn AnalyzeFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.analyze = fn
+}
+
+// Handler returns the HTTP routes of the server:
+//
+//	/                the embedded web dashboard
+//	/graphql         GraphQL queries over the report (GET ?query= or POST {"query": ...})
+//	/api/report      the full report as JSON
+//	/api/signals     trading signals and their composite score
+//	/api/history     signal snapshots of past analysis runs
+//	/api/analyze     POST parameter overrides to re-run the analysis, refused during live execution
+//	/risk-dashboard  the self-refreshing risk dashboard page
+//
+// and the routes of registerWatchlistRoutes, registerJobRoutes,
+// registerPaperRoutes, registerExecutionRoutes, registerHealthRoutes,
+// registerEmbedRoutes, registerChartRoutes and registerOverviewRoutes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", s.handleIndex)
+	mux.HandleFunc("/graphql", s.handleGraphQL)
+	mux.HandleFunc("/api/report", s.handleReport)
+	mux.HandleFunc("/api/signals", s.handleSignals)
+	mux.HandleFunc("/api/history", s.handleHistory)
+	mux.HandleFunc("/api/analyze", s.handleAnalyze)
+	mux.HandleFunc("/risk-dashboard", s.handleRiskDashboard)
+	s.registerWatchlistRoutes(mux)
+	s.registerJobRoutes(mux)
+	s.registerPaperRoutes(mux)
+	s.registerExecutionRoutes(mux)
+	s.registerHealthRoutes(mux)
+	s.registerEmbedRoutes(mux)
+	s.registerChartRoutes(mux)
+	s.registerOverviewRoutes(mux)
+	return mux
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	page, err := webFiles.ReadFile("web/index.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	history := append([]SignalSnapshot(nil), s.history...)
+	s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.RLock()
+	analyze := s.analyze
+	s.mu.RUnlock()
+	if analyze == nil {
+		writeError(w, http.StatusNotImplemented, "re-analysis is not enabled")
+		return
+	}
+	// The endpoint is unauthenticated, so it must not steer real orders
+	if e := s.Execution(); e != nil && e.Live() {
+		writeError(w, http.StatusForbidden, "re-analysis with overrides is disabled during live execution")
+		return
+	}
+
+	var params map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid parameters: %v", err))
+		return
+	}
+	if err := analyze(params); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Current returns the served analysis result, or ok=false before the first Update
+func (s *Server) Current() (*types.AnalysisResult, bool) {
+	result, _, ok := s.snapshot()
+	return result, ok
+}
+
+// Series returns the served series, replaced on every Update. Its readers
+// get copies, so they never see a series that is being replaced.
+func (s *Server) Series() *timeseries.SafeSeries {
+	return s.series
+}
+
+// snapshot returns the current state, or ok=false before the first Update
+func (s *Server) snapshot() (result *types.AnalysisResult, root interface{}, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.result, s.root, s.result != nil
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	_, root, ok := s.snapshot()
+	if !ok {
+		writeGraphQLError(w, http.StatusServiceUnavailable, "no analysis available yet")
+		return
+	}
+	serveGraphQL(w, r, root)
+}
+
+// serveGraphQL answers a GraphQL request (GET ?query= or POST {"query": ...}) against root
+func serveGraphQL(w http.ResponseWriter, r *http.Request, root interface{}) {
+	var query string
+	switch r.Method {
+	case http.MethodGet:
+		query = r.URL.Query().Get("query")
+	case http.MethodPost:
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeGraphQLError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+		query = body.Query
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fields, err := graphql.Parse(query)
+	if err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := graphql.Execute(root, fields)
+	if err != nil {
+		writeGraphQLError(w, http.StatusOK, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	_, root, ok := s.snapshot()
+	if !ok {
+		http.Error(w, "no analysis available yet", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, root)
+}
+
+func (s *Server) handleSignals(w http.ResponseWriter, r *http.Request) {
+	result, _, ok := s.snapshot()
+	if !ok {
+		http.Error(w, "no analysis available yet", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"signals": result.Signals,
+		"score":   result.SignalScore,
+	})
+}
+
+func (s *Server) handleRiskDashboard(w http.ResponseWriter, r *http.Request) {
+	result, _, ok := s.snapshot()
+	if !ok {
+		http.Error(w, "no analysis available yet", http.StatusServiceUnavailable)
+		return
+	}
+	page, err := reporter.RenderRiskDashboard(result, 60, s.catalog())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an {"error": message} JSON response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeGraphQLError writes a GraphQL error response
+func writeGraphQLError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
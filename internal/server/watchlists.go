@@ -0,0 +1,184 @@
+package server
+
+import (
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/watchlist"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SetWatchlists enables the watchlist routes, served from m
+func (s *Server) SetWatchlists(m *watchlist.Manager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchlists = m
+}
+
+// registerWatchlistRoutes adds the watchlist API:
+//
+//	GET    /api/watchlists[?owner=]         list watchlists
+//	POST   /api/watchlists                  create a watchlist
+//	GET    /api/watchlists/{id}             a watchlist, its triggered alerts and signals
+//	PUT    /api/watchlists/{id}             replace a watchlist
+//	DELETE /api/watchlists/{id}             delete a watchlist
+//	POST   /api/watchlists/{id}/analyze     re-analyze now
+//	GET    /api/watchlists/{id}/report      the full report, as /api/report
+//	POST   /api/watchlists/{id}/graphql     GraphQL queries over the report
+func (s *Server) registerWatchlistRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/watchlists", s.withWatchlists(s.handleListWatchlists))
+	mux.HandleFunc("POST /api/watchlists", s.withWatchlists(s.handleCreateWatchlist))
+	mux.HandleFunc("GET /api/watchlists/{id}", s.withWatchlists(s.handleGetWatchlist))
+	mux.HandleFunc("PUT /api/watchlists/{id}", s.withWatchlists(s.handleReplaceWatchlist))
+	mux.HandleFunc("DELETE /api/watchlists/{id}", s.withWatchlists(s.handleDeleteWatchlist))
+	mux.HandleFunc("POST /api/watchlists/{id}/analyze", s.withWatchlists(s.handleAnalyzeWatchlist))
+	mux.HandleFunc("GET /api/watchlists/{id}/report", s.withWatchlists(s.handleWatchlistReport))
+	mux.HandleFunc("POST /api/watchlists/{id}/graphql", s.withWatchlists(s.handleWatchlistGraphQL))
+}
+
+// watchlistHandler is a watchlist route handler given the enabled manager
+type watchlistHandler func(m *watchlist.Manager, w http.ResponseWriter, r *http.Request)
+
+// withWatchlists answers 404 while watchlists are disabled
+func (s *Server) withWatchlists(handler watchlistHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		m := s.watchlists
+		s.mu.RUnlock()
+		if m == nil {
+			writeError(w, http.StatusNotFound, "watchlists are not enabled")
+			return
+		}
+		handler(m, w, r)
+	}
+}
+
+func (s *Server) handleListWatchlists(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	list := m.Store().List(r.URL.Query().Get("owner"))
+	if list == nil {
+		list = []types.Watchlist{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleCreateWatchlist(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	var entry types.Watchlist
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid watchlist: %v", err))
+		return
+	}
+	entry.ID = ""
+	s.saveWatchlist(m, w, entry, http.StatusCreated)
+}
+
+func (s *Server) handleReplaceWatchlist(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	existing, ok := m.Store().Get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown watchlist")
+		return
+	}
+	var entry types.Watchlist
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid watchlist: %v", err))
+		return
+	}
+	entry.ID = existing.ID
+	entry.LastRun = existing.LastRun
+	entry.LastError = existing.LastError
+	s.saveWatchlist(m, w, entry, http.StatusOK)
+}
+
+// saveWatchlist validates and stores entry, then analyzes it in the background
+func (s *Server) saveWatchlist(m *watchlist.Manager, w http.ResponseWriter, entry types.Watchlist, status int) {
+	if err := watchlist.Validate(&entry); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	stored, err := m.Store().Put(entry)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	go m.Analyze(stored.ID)
+	writeJSON(w, status, stored)
+}
+
+func (s *Server) handleGetWatchlist(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	entry, ok := m.Store().Get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown watchlist")
+		return
+	}
+
+	body := map[string]interface{}{"watchlist": entry}
+	if result, ok := m.Result(entry.ID); ok {
+		body["analyzedAt"] = result.RanAt
+		body["latestPrice"] = result.Analysis.Metadata.LatestPrice
+		body["triggered"] = result.Triggered
+		body["signals"] = result.Analysis.Signals
+		body["signalScore"] = result.Analysis.SignalScore
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func (s *Server) handleDeleteWatchlist(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	deleted, err := m.Store().Delete(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "unknown watchlist")
+		return
+	}
+	m.Forget(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAnalyzeWatchlist(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := m.Store().Get(id); !ok {
+		writeError(w, http.StatusNotFound, "unknown watchlist")
+		return
+	}
+	if err := m.Analyze(id); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleWatchlistReport(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	root, ok := watchlistReport(m, w, r.PathValue("id"))
+	if ok {
+		writeJSON(w, http.StatusOK, root)
+	}
+}
+
+func (s *Server) handleWatchlistGraphQL(m *watchlist.Manager, w http.ResponseWriter, r *http.Request) {
+	root, ok := watchlistReport(m, w, r.PathValue("id"))
+	if ok {
+		serveGraphQL(w, r, root)
+	}
+}
+
+// watchlistReport builds the report of a watchlist's latest result, writing
+// an error response and returning false when there is none
+func watchlistReport(m *watchlist.Manager, w http.ResponseWriter, id string) (interface{}, bool) {
+	if _, ok := m.Store().Get(id); !ok {
+		writeError(w, http.StatusNotFound, "unknown watchlist")
+		return nil, false
+	}
+	result, ok := m.Result(id)
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "no analysis available yet")
+		return nil, false
+	}
+	root, err := buildReport(result.Analysis)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return root, true
+}
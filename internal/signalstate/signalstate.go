@@ -0,0 +1,187 @@
+// Package signalstate damps flapping trading signals across repeated
+// analyses of the same series, so a reading hovering at a threshold does not
+// flip the signal, and the alerts and trades that follow it, on every run.
+package signalstate
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/store"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults are the damping settings unless the config file overrides them
+var Defaults = types.SignalStateConfig{
+	RSIHysteresis:  2,
+	BandHysteresis: 0.005,
+	MinHold:        "2h",
+	Cooldown:       "6h",
+}
+
+// Actions of a signal, the first word of its text
+const (
+	ActionBuy  = "BUY"
+	ActionSell = "SELL"
+	ActionHold = "HOLD"
+)
+
+// Tracker stabilizes the signals of analysis results against the states
+// kept in a store. A signal changes action only when
+//   - an RSI or Bollinger Band signal has retreated past its hysteresis band,
+//   - a BUY or SELL has been held for the minimum holding period, and
+//   - a BUY or SELL entered again is past the cooldown of its last exit.
+//
+// Otherwise the previous signal is reported, marked as held with the reason.
+// Times are the bar times of the results, so replays of the same data
+// behave the same.
+type Tracker struct {
+	mu       sync.Mutex
+	store    *store.SignalStateStore
+	rsiBand  float64
+	bandBand float64
+	minHold  time.Duration
+	cooldown time.Duration
+}
+
+// NewTracker returns a tracker persisting to st with the settings of sc,
+// unset ones taking Defaults
+func NewTracker(st *store.SignalStateStore, sc types.SignalStateConfig) (*Tracker, error) {
+	sc = Resolve(sc)
+	if sc.RSIHysteresis < 0 || sc.BandHysteresis < 0 {
+		return nil, fmt.Errorf("signal hysteresis must not be negative")
+	}
+	minHold, err := time.ParseDuration(sc.MinHold)
+	if err != nil || minHold < 0 {
+		return nil, fmt.Errorf("invalid minimum holding period %q: use a duration such as 2h", sc.MinHold)
+	}
+	cooldown, err := time.ParseDuration(sc.Cooldown)
+	if err != nil || cooldown < 0 {
+		return nil, fmt.Errorf("invalid cooldown %q: use a duration such as 6h", sc.Cooldown)
+	}
+	return &Tracker{store: st, rsiBand: sc.RSIHysteresis, bandBand: sc.BandHysteresis, minHold: minHold, cooldown: cooldown}, nil
+}
+
+// Resolve fills the unset settings of sc with Defaults
+func Resolve(sc types.SignalStateConfig) types.SignalStateConfig {
+	if sc.RSIHysteresis == 0 {
+		sc.RSIHysteresis = Defaults.RSIHysteresis
+	}
+	if sc.BandHysteresis == 0 {
+		sc.BandHysteresis = Defaults.BandHysteresis
+	}
+	if sc.MinHold == "" {
+		sc.MinHold = Defaults.MinHold
+	}
+	if sc.Cooldown == "" {
+		sc.Cooldown = Defaults.Cooldown
+	}
+	return sc
+}
+
+// Apply replaces the signals of result, an analysis of the series key, with
+// their stabilized values, recomputes the signal score and stores the new
+// states
+func (t *Tracker) Apply(key string, result *types.AnalysisResult) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := result.Metadata.End
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	states := t.store.Get(key)
+	names := make(map[string]bool)
+	for name := range result.Signals {
+		names[name] = true
+	}
+	for name := range states {
+		names[name] = true
+	}
+
+	signals := make(map[string]string, len(names))
+	for name := range names {
+		raw, present := result.Signals[name]
+		if !present {
+			raw = ActionHold + " - no signal"
+		}
+		state, known := states[name]
+		if !known {
+			state = types.SignalState{Action: Action(raw), Signal: raw, Since: now}
+		} else if reason := t.holdReason(name, state, Action(raw), result, now); reason != "" {
+			signals[name] = fmt.Sprintf("%s (held: %s)", state.Signal, reason)
+			continue
+		} else if Action(raw) != state.Action {
+			if state.Action != ActionHold {
+				state.ExitedAction, state.ExitedAt = state.Action, now
+			}
+			state.Action, state.Since = Action(raw), now
+		}
+		state.Signal = raw
+		states[name] = state
+		if present || state.Action != ActionHold {
+			signals[name] = raw
+		}
+	}
+
+	result.Signals = signals
+	result.SignalScore = analyzer.SignalScore(signals)
+	return t.store.Put(key, states)
+}
+
+// holdReason explains why the signal name keeps state instead of moving to
+// the action next, empty when it may move
+func (t *Tracker) holdReason(name string, state types.SignalState, next string, result *types.AnalysisResult, now time.Time) string {
+	if next == state.Action {
+		return ""
+	}
+	if reason := t.hysteresis(name, state.Action, result); reason != "" {
+		return reason
+	}
+	if state.Action != ActionHold && now.Sub(state.Since) < t.minHold {
+		return fmt.Sprintf("minimum holding period of %s", t.minHold)
+	}
+	if next != ActionHold && next == state.ExitedAction && now.Sub(state.ExitedAt) < t.cooldown {
+		return fmt.Sprintf("%s cooling down for %s", next, t.cooldown)
+	}
+	return ""
+}
+
+// hysteresis explains why an RSI or Bollinger Band signal in action has not
+// retreated far enough past its threshold to end, empty when it has
+func (t *Tracker) hysteresis(name, action string, result *types.AnalysisResult) string {
+	analytics := result.Analytics
+	switch name {
+	case "RSI":
+		if len(analytics.RSI) == 0 {
+			return ""
+		}
+		rsi := analytics.RSI[len(analytics.RSI)-1]
+		thresholds := analyzer.ResolveSignalConfig(result.SignalThresholds)
+		if (action == ActionSell && rsi > thresholds.RSIOverbought-t.rsiBand) || (action == ActionBuy && rsi < thresholds.RSIOversold+t.rsiBand) {
+			return fmt.Sprintf("RSI within %g points of its threshold", t.rsiBand)
+		}
+	case "Bollinger":
+		bands, price := analytics.BollingerBands, result.Metadata.LatestPrice
+		if len(bands.Upper) == 0 || price <= 0 {
+			return ""
+		}
+		upper, lower := bands.Upper[len(bands.Upper)-1], bands.Lower[len(bands.Lower)-1]
+		if (action == ActionSell && price > upper*(1-t.bandBand)) || (action == ActionBuy && price < lower*(1+t.bandBand)) {
+			return fmt.Sprintf("price within %.1f%% of the band", t.bandBand*100)
+		}
+	}
+	return ""
+}
+
+// Action returns the action a signal text starts with, HOLD for any other
+func Action(signal string) string {
+	for _, action := range []string{ActionBuy, ActionSell} {
+		if strings.HasPrefix(signal, action) {
+			return action
+		}
+	}
+	return ActionHold
+}
@@ -0,0 +1,291 @@
+// Package snapshot compares two analyses of the same market, such as today's
+// JSON report against yesterday's, and describes what changed.
+package snapshot
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// levelTolerance is the relative distance within which two support or
+// resistance levels are considered the same level
+const levelTolerance = 0.005
+
+// metric is one scalar compared between analyses. Unit "$" is a price,
+// "pp" a fraction shown in percent whose change is reported in percentage
+// points.
+type metric struct {
+	name  string
+	unit  string
+	value func(r *types.AnalysisResult) (float64, bool)
+}
+
+var metrics = []metric{
+	{"Price", "$", func(r *types.AnalysisResult) (float64, bool) {
+		return r.Metadata.LatestPrice, r.Metadata.LatestPrice > 0
+	}},
+	{"Volatility", "pp", func(r *types.AnalysisResult) (float64, bool) { return r.Analytics.Volatility, true }},
+	{"Max Drawdown", "pp", func(r *types.AnalysisResult) (float64, bool) { return r.Analytics.MaxDrawdown, true }},
+	{"Sharpe Ratio", "", func(r *types.AnalysisResult) (float64, bool) { return r.Analytics.SharpeRatio, true }},
+	{"RSI", "", func(r *types.AnalysisResult) (float64, bool) { return last(r.Analytics.RSI) }},
+	{"MACD Histogram", "", func(r *types.AnalysisResult) (float64, bool) { return last(r.Analytics.MACD.Histogram) }},
+	{"Average Volume", "", func(r *types.AnalysisResult) (float64, bool) {
+		return r.Analytics.VolumeStats.Mean, r.Analytics.VolumeStats.Count > 0
+	}},
+	{"Signal Score", "", func(r *types.AnalysisResult) (float64, bool) { return r.SignalScore, len(r.Signals) > 0 }},
+}
+
+// LoadReport reads a JSON report written by the analyzer
+func LoadReport(filename string) (*types.AnalysisResult, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var result types.AnalysisResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", filename, err)
+	}
+	return &result, nil
+}
+
+// Diff describes what changed from the older analysis to the newer one
+func Diff(older, newer *types.AnalysisResult) types.SnapshotDiff {
+	diff := types.SnapshotDiff{
+		OldGeneratedAt: older.Metadata.GeneratedAt,
+		NewGeneratedAt: newer.Metadata.GeneratedAt,
+		OldEnd:         older.Metadata.End,
+		NewEnd:         newer.Metadata.End,
+		Metrics:        []types.MetricChange{},
+		Changelog:      []string{},
+	}
+
+	for _, m := range metrics {
+		oldValue, oldOK := m.value(older)
+		newValue, newOK := m.value(newer)
+		if !oldOK || !newOK {
+			continue
+		}
+		change := types.MetricChange{Metric: m.name, Old: oldValue, New: newValue, Change: newValue - oldValue}
+		diff.Metrics = append(diff.Metrics, change)
+		if line := describeMetric(m, change); line != "" {
+			diff.Changelog = append(diff.Changelog, line)
+		}
+	}
+
+	diff.Events = crossings(older, newer)
+	diff.Changelog = append(diff.Changelog, diff.Events...)
+
+	diff.Levels = levelChanges(older.Analytics.SupportResistance, newer.Analytics.SupportResistance)
+	for _, level := range diff.Levels {
+		diff.Changelog = append(diff.Changelog, describeLevel(level))
+	}
+
+	diff.Signals = signalChanges(older.Signals, newer.Signals)
+	for _, signal := range diff.Signals {
+		diff.Changelog = append(diff.Changelog, describeSignal(signal))
+	}
+	return diff
+}
+
+// describeMetric renders a metric change, or "" when the change does not
+// show at the precision the metric is printed with
+func describeMetric(m metric, change types.MetricChange) string {
+	var from, to, delta string
+	switch m.unit {
+	case "$":
+		from, to = denom.Price(nil, change.Old), denom.Price(nil, change.New)
+		delta = numfmt.Pct(math.Abs(change.Change) / change.Old)
+	case "pp":
+		from, to = numfmt.Pct(change.Old), numfmt.Pct(change.New)
+		delta = numfmt.Format(numfmt.Percent, math.Abs(change.Change)*100) + "pp"
+	default:
+		from, to = fmt.Sprintf("%.3f", change.Old), fmt.Sprintf("%.3f", change.New)
+		delta = fmt.Sprintf("%.3f", math.Abs(change.Change))
+	}
+	if from == to {
+		return ""
+	}
+	direction := "up"
+	if change.Change < 0 {
+		direction = "down"
+	}
+	return fmt.Sprintf("%s %s %s (%s → %s)", m.name, direction, delta, from, to)
+}
+
+// crossings lists the thresholds the latest RSI, MACD and price crossed
+// between the analyses
+func crossings(older, newer *types.AnalysisResult) []string {
+	var events []string
+
+	if oldRSI, ok := last(older.Analytics.RSI); ok {
+		if newRSI, ok := last(newer.Analytics.RSI); ok {
+			switch {
+			case oldRSI < 70 && newRSI >= 70:
+				events = append(events, fmt.Sprintf("RSI crossed above 70 into overbought (%.1f → %.1f)", oldRSI, newRSI))
+			case oldRSI >= 70 && newRSI < 70:
+				events = append(events, fmt.Sprintf("RSI fell below 70 out of overbought (%.1f → %.1f)", oldRSI, newRSI))
+			}
+			switch {
+			case oldRSI > 30 && newRSI <= 30:
+				events = append(events, fmt.Sprintf("RSI crossed below 30 into oversold (%.1f → %.1f)", oldRSI, newRSI))
+			case oldRSI <= 30 && newRSI > 30:
+				events = append(events, fmt.Sprintf("RSI rose above 30 out of oversold (%.1f → %.1f)", oldRSI, newRSI))
+			}
+		}
+	}
+
+	if oldHist, ok := last(older.Analytics.MACD.Histogram); ok {
+		if newHist, ok := last(newer.Analytics.MACD.Histogram); ok {
+			switch {
+			case oldHist <= 0 && newHist > 0:
+				events = append(events, "MACD crossed above its signal line")
+			case oldHist >= 0 && newHist < 0:
+				events = append(events, "MACD crossed below its signal line")
+			}
+		}
+	}
+
+	oldBand, newBand := bandPosition(older), bandPosition(newer)
+	if oldBand != "" && newBand != "" && oldBand != newBand {
+		events = append(events, fmt.Sprintf("Price moved %s", newBand))
+	}
+	return events
+}
+
+// bandPosition places the latest price relative to the latest Bollinger
+// Bands, or returns "" when either is missing
+func bandPosition(r *types.AnalysisResult) string {
+	upper, ok := last(r.Analytics.BollingerBands.Upper)
+	lower, ok2 := last(r.Analytics.BollingerBands.Lower)
+	price := r.Metadata.LatestPrice
+	if !ok || !ok2 || price <= 0 {
+		return ""
+	}
+	switch {
+	case price > upper:
+		return "above the upper Bollinger Band"
+	case price < lower:
+		return "below the lower Bollinger Band"
+	}
+	return "inside the Bollinger Bands"
+}
+
+// levelChanges lists support and resistance levels that appeared,
+// disappeared or broke
+func levelChanges(older, newer types.SupportResistanceData) []types.LevelChange {
+	var changes []types.LevelChange
+	for _, side := range []struct {
+		kind       string
+		old, fresh []float64
+	}{
+		{"support", older.SupportLevels, newer.SupportLevels},
+		{"resistance", older.ResistanceLevels, newer.ResistanceLevels},
+	} {
+		for _, price := range side.fresh {
+			if !nearAny(price, side.old) {
+				changes = append(changes, types.LevelChange{Type: side.kind, Price: price, Change: "appeared"})
+			}
+		}
+		for _, price := range side.old {
+			if !nearAny(price, side.fresh) {
+				changes = append(changes, types.LevelChange{Type: side.kind, Price: price, Change: "disappeared"})
+			}
+		}
+	}
+
+	for _, level := range newer.Levels {
+		if !level.Broken {
+			continue
+		}
+		for _, previous := range older.Levels {
+			if !previous.Broken && near(level.Price, previous.Price) {
+				changes = append(changes, types.LevelChange{Type: level.Type, Price: level.Price, Change: "broken"})
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// describeLevel renders a level change
+func describeLevel(level types.LevelChange) string {
+	switch level.Change {
+	case "appeared":
+		return fmt.Sprintf("New %s level at %s", level.Type, denom.Price(nil, level.Price))
+	case "disappeared":
+		return fmt.Sprintf("%s level at %s disappeared", capitalize(level.Type), denom.Price(nil, level.Price))
+	}
+	return fmt.Sprintf("%s level at %s broken", capitalize(level.Type), denom.Price(nil, level.Price))
+}
+
+// signalChanges lists signals that changed, appeared or disappeared, by name
+func signalChanges(older, newer map[string]string) []types.SignalChange {
+	names := make(map[string]bool)
+	for name := range older {
+		names[name] = true
+	}
+	for name := range newer {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []types.SignalChange
+	for _, name := range sorted {
+		if older[name] != newer[name] {
+			changes = append(changes, types.SignalChange{Signal: name, Old: older[name], New: newer[name]})
+		}
+	}
+	return changes
+}
+
+// describeSignal renders a signal change
+func describeSignal(signal types.SignalChange) string {
+	switch {
+	case signal.Old == "":
+		return fmt.Sprintf("New %s signal: %s", signal.Signal, signal.New)
+	case signal.New == "":
+		return fmt.Sprintf("%s signal no longer reported (was %s)", signal.Signal, signal.Old)
+	}
+	return fmt.Sprintf("%s signal: %s → %s", signal.Signal, signal.Old, signal.New)
+}
+
+// last returns the last value of values
+func last(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	return values[len(values)-1], true
+}
+
+// near reports whether two price levels are within levelTolerance of each other
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= levelTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// nearAny reports whether price is near any of levels
+func nearAny(price float64, levels []float64) bool {
+	for _, level := range levels {
+		if near(price, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// capitalize upper-cases the first letter of an ASCII word
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
@@ -0,0 +1,167 @@
+package snapshot
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/patterns"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// week is the length of a calendar week
+const week = 7 * 24 * time.Hour
+
+// WeekStart returns the start of the calendar week of t, Monday 00:00 UTC
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// WeekEnd returns the last instant of the week starting at start
+func WeekEnd(start time.Time) time.Time {
+	return start.Add(week - time.Nanosecond)
+}
+
+// ReportWeek returns the start of the week a weekly report on bts covers:
+// the latest week the series covers to its end, or the week of the last bar
+// with complete false when the series covers no whole week
+func ReportWeek(bts *types.BTCTimeSeries) (start time.Time, complete bool) {
+	last := bts.Data[len(bts.Data)-1].Timestamp
+	start = WeekStart(last)
+	if !last.Add(timeseries.DetectFrequency(bts).Interval).Before(start.Add(week)) {
+		return start, true
+	}
+	if prior := start.Add(-week); !bts.Data[0].Timestamp.After(prior) {
+		return prior, true
+	}
+	return start, false
+}
+
+// Weekly summarizes the week starting at start. current is the analysis of
+// the series up to the end of the week and previous the analysis up to the
+// end of the week before, or nil when the series starts within the week.
+func Weekly(current, previous *types.AnalysisResult, start time.Time, complete bool) types.WeeklyReport {
+	bts := current.Series
+	report := types.WeeklyReport{
+		Symbol:       bts.Symbol,
+		Denomination: bts.Denomination,
+		Complete:     complete,
+		Events:       weekEvents(current, start, WeekEnd(start)),
+		Signals:      current.Signals,
+		SignalScore:  current.SignalScore,
+		Narrative:    analyzer.Narrative(current),
+	}
+	report.Week, _ = weekCandle(bts, start)
+	if report.Week.Open > 0 {
+		report.Change = report.Week.Close/report.Week.Open - 1
+	}
+
+	if previous != nil {
+		if prior, ok := weekCandle(bts, start.Add(-week)); ok {
+			report.PriorWeek = &prior
+			report.Change = report.Week.Close/prior.Close - 1
+			if prior.Volume > 0 {
+				report.VolumeChange = report.Week.Volume/prior.Volume - 1
+			}
+		}
+		diff := Diff(previous, current)
+		report.Diff = &diff
+	}
+	return report
+}
+
+// weekCandle aggregates the bars of the week starting at start. The boolean
+// is false when the series has none.
+func weekCandle(bts *types.BTCTimeSeries, start time.Time) (types.WeeklyCandle, bool) {
+	bars := timeseries.Between(bts, start, WeekEnd(start))
+	candle := types.WeeklyCandle{Start: start, End: start.Add(week), Bars: len(bars)}
+	if len(bars) == 0 {
+		return candle, false
+	}
+	candle.Open, candle.High, candle.Low = bars[0].Open, bars[0].High, bars[0].Low
+	candle.Close = bars[len(bars)-1].Close
+	for _, bar := range bars {
+		candle.High = max(candle.High, bar.High)
+		candle.Low = min(candle.Low, bar.Low)
+		candle.Volume += bar.Volume
+	}
+	return candle, true
+}
+
+// weekEvents lists the directional candlestick and volume patterns,
+// structure breaks, Wyckoff events, completed harmonics, level breaks and
+// retests, and custom indicator signals between start and end, in order
+func weekEvents(result *types.AnalysisResult, start, end time.Time) []types.WeeklyEvent {
+	bts, analytics := result.Series, result.Analytics
+	d := bts.Denomination
+	events := []types.WeeklyEvent{}
+	within := func(t time.Time) bool { return !t.Before(start) && !t.After(end) }
+	add := func(t time.Time, kind, format string, args ...interface{}) {
+		if within(t) {
+			events = append(events, types.WeeklyEvent{Timestamp: t, Kind: kind, Description: fmt.Sprintf(format, args...)})
+		}
+	}
+	addBar := func(index int, kind, format string, args ...interface{}) {
+		if index >= 0 && index < len(bts.Data) {
+			add(bts.Data[index].Timestamp, kind, format, args...)
+		}
+	}
+
+	for _, detected := range []map[string][]int{patterns.DetectCandlestickPatterns(bts), patterns.DetectVolumePatterns(bts)} {
+		for name, indices := range detected {
+			bias := patterns.PatternBias[name]
+			if bias == "neutral" {
+				continue
+			}
+			for _, i := range indices {
+				addBar(i, "pattern", "%s (%s) at %s", capitalize(strings.ReplaceAll(name, "_", " ")), bias, denom.Price(d, bts.Data[i].Close))
+			}
+		}
+	}
+	if ms := analytics.MarketStructure; ms != nil {
+		for _, event := range ms.Events {
+			add(event.Timestamp, "structure", "%s %s through %s", capitalize(event.Direction), event.Type, denom.Price(d, event.Level))
+		}
+	}
+	if wy := analytics.Wyckoff; wy != nil {
+		for _, event := range wy.Events {
+			add(event.Timestamp, "wyckoff", "Wyckoff %s at %s", event.Event, denom.Price(d, event.Price))
+		}
+	}
+	for _, harmonic := range analytics.Harmonics {
+		if harmonic.Complete {
+			point := harmonic.Points[len(harmonic.Points)-1]
+			add(point.Timestamp, "harmonic", "%s %s completed at %s", capitalize(harmonic.Direction), harmonic.Name, denom.Price(d, point.Price))
+		}
+	}
+	for _, level := range analytics.LevelMap {
+		if level.Broken {
+			add(level.BrokenAt, "level", "%s at %s broken", capitalize(level.Type), denom.Price(d, level.Price))
+		}
+		if level.Retested {
+			add(level.RetestedAt, "level", "%s at %s retested after its break", capitalize(level.Type), denom.Price(d, level.Price))
+		}
+	}
+	for _, ci := range analytics.Custom {
+		for _, i := range ci.Buys {
+			addBar(i, "indicator", "%s buy signal", ci.Name)
+		}
+		for _, i := range ci.Sells {
+			addBar(i, "indicator", "%s sell signal", ci.Name)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Timestamp.Equal(events[j].Timestamp) {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		}
+		// Patterns come from maps, so same-bar events are ordered by text
+		return events[i].Description < events[j].Description
+	})
+	return events
+}
@@ -0,0 +1,62 @@
+package statistics
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+	"math/rand"
+)
+
+// Defaults of the bootstrap confidence intervals
+const (
+	DefaultBootstrapResamples = 1000
+	DefaultConfidenceLevel    = 0.95
+)
+
+// BootstrapConfidence estimates confidence intervals at level for the
+// annualized mean return, the Sharpe ratio and volatility, the annualized
+// volatility of the estimator in use, by a moving block bootstrap of
+// returns: resamples series of as many returns, drawn as blocks of about
+// the cube root of their count so volatility clustering is kept. Each
+// resample's volatility scales volatility by its close-to-close volatility
+// over that of returns, so the intervals are centered on the reported
+// figures whatever the estimator. Intervals are the percentiles of the
+// resampled figures.
+func BootstrapConfidence(returns []float64, volatility float64, rc types.RiskConfig, resamples int, level float64, rng *rand.Rand) types.BootstrapIntervals {
+	n := len(returns)
+	block := int(math.Ceil(math.Cbrt(float64(n))))
+	ci := types.BootstrapIntervals{Level: level, Resamples: resamples, BlockSize: block, Bars: n}
+	if n < 2 || resamples <= 0 {
+		return ci
+	}
+
+	excess := rc.RiskFreeRate + rc.FundingRate
+	scale := 1.0
+	if closeToClose := CalculateVolatility(returns, rc.PeriodsPerYear); closeToClose > 0 && volatility > 0 {
+		scale = volatility / closeToClose
+	}
+
+	means := make([]float64, resamples)
+	sharpes := make([]float64, resamples)
+	vols := make([]float64, resamples)
+	sample := make([]float64, n)
+	for b := 0; b < resamples; b++ {
+		for filled := 0; filled < n; {
+			start := rng.Intn(n - block + 1)
+			filled += copy(sample[filled:], returns[start:start+block])
+		}
+		means[b] = AnnualizeReturns(sample, rc)
+		vols[b] = CalculateVolatility(sample, rc.PeriodsPerYear) * scale
+		if vols[b] > 0 {
+			sharpes[b] = (means[b] - excess) / vols[b]
+		}
+	}
+
+	lower, upper := (1-level)/2, (1+level)/2
+	interval := func(estimate float64, resampled []float64) types.ConfidenceInterval {
+		return types.ConfidenceInterval{Estimate: estimate, Lower: Quantile(resampled, lower), Upper: Quantile(resampled, upper)}
+	}
+	ci.MeanReturn = interval(AnnualizeReturns(returns, rc), means)
+	ci.Volatility = interval(volatility, vols)
+	ci.SharpeRatio = interval(SharpeRatioWithVolatility(returns, volatility, rc), sharpes)
+	return ci
+}
@@ -0,0 +1,46 @@
+package statistics
+
+import "btc-analyzer/internal/types"
+
+// egCritical are MacKinnon's (2010) response surface coefficients for the
+// 1%, 5% and 10% critical values of the Engle-Granger test of two series
+// with a constant, used as adfCritical
+var egCritical = [3][3]float64{
+	{-3.89644, -10.9519, -22.527},
+	{-3.33613, -6.1101, -6.823},
+	{-3.04445, -4.2412, -2.720},
+}
+
+// EngleGranger tests whether y and x, of the same length, are cointegrated:
+// it regresses y on x and runs the ADF test on the residuals, the spread,
+// against critical values that allow for the estimated hedge ratio.
+// Stationary in the test means cointegrated at 5%. ok is false when x does
+// not vary.
+func EngleGranger(y, x []float64) (hedge, intercept float64, spread []float64, test types.StationarityTest, ok bool) {
+	ls := newLeastSquares(2)
+	for i := range y {
+		ls.add([]float64{1, x[i]}, y[i])
+	}
+	coef, _, _, ok := ls.fit(2)
+	if !ok {
+		return 0, 0, nil, types.StationarityTest{Test: "Engle-Granger"}, false
+	}
+	intercept, hedge = coef[0], coef[1]
+
+	spread = make([]float64, len(y))
+	for i := range y {
+		spread[i] = y[i] - hedge*x[i] - intercept
+	}
+	test = ADF(spread)
+	test.Test = "Engle-Granger"
+	if test.Observations > 0 {
+		obs := float64(test.Observations)
+		critical := make([]float64, 3)
+		for i, b := range egCritical {
+			critical[i] = b[0] + b[1]/obs + b[2]/(obs*obs)
+		}
+		test.Critical1, test.Critical5, test.Critical10 = critical[0], critical[1], critical[2]
+		test.Stationary = test.Statistic < test.Critical5
+	}
+	return hedge, intercept, spread, test, true
+}
@@ -0,0 +1,189 @@
+package statistics
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// Volatility estimators selectable in RiskConfig.VolEstimator
+const (
+	EstimatorCloseToClose   = "close"
+	EstimatorParkinson      = "parkinson"
+	EstimatorGarmanKlass    = "garman-klass"
+	EstimatorRogersSatchell = "rogers-satchell"
+	EstimatorYangZhang      = "yang-zhang"
+)
+
+// VolEstimators lists the estimator names in the order they are reported
+var VolEstimators = []string{
+	EstimatorCloseToClose, EstimatorParkinson, EstimatorGarmanKlass, EstimatorRogersSatchell, EstimatorYangZhang,
+}
+
+// RealizedVolatilities computes every estimator on bts, annualized at
+// periodsPerYear, and selects the one named by estimator. A range-based
+// estimator falls back to close-to-close when no bar has a high above its
+// low, as with close-only data.
+func RealizedVolatilities(bts *types.BTCTimeSeries, returns []float64, estimator string, periodsPerYear int) types.RealizedVolatility {
+	rv := types.RealizedVolatility{
+		CloseToClose: CalculateVolatility(returns, periodsPerYear),
+		HasRanges:    hasRanges(bts),
+		Estimator:    EstimatorCloseToClose,
+	}
+	if rv.HasRanges {
+		annualize := func(variance float64) float64 {
+			if variance <= 0 {
+				return 0
+			}
+			return math.Sqrt(variance * float64(periodsPerYear))
+		}
+		rv.Parkinson = annualize(parkinsonVariance(bts))
+		rv.GarmanKlass = annualize(garmanKlassVariance(bts))
+		rv.RogersSatchell = annualize(rogersSatchellVariance(bts.Data))
+		rv.YangZhang = annualize(yangZhangVariance(bts))
+		if estimator != "" {
+			rv.Estimator = estimator
+		}
+	}
+	rv.Selected = EstimatorValue(rv, rv.Estimator)
+	return rv
+}
+
+// EstimatorValue returns the volatility of rv under the named estimator,
+// close-to-close for an unknown name
+func EstimatorValue(rv types.RealizedVolatility, estimator string) float64 {
+	switch estimator {
+	case EstimatorParkinson:
+		return rv.Parkinson
+	case EstimatorGarmanKlass:
+		return rv.GarmanKlass
+	case EstimatorRogersSatchell:
+		return rv.RogersSatchell
+	case EstimatorYangZhang:
+		return rv.YangZhang
+	}
+	return rv.CloseToClose
+}
+
+// EstimatorName returns the display name of an estimator
+func EstimatorName(estimator string) string {
+	switch estimator {
+	case EstimatorParkinson:
+		return "Parkinson"
+	case EstimatorGarmanKlass:
+		return "Garman-Klass"
+	case EstimatorRogersSatchell:
+		return "Rogers-Satchell"
+	case EstimatorYangZhang:
+		return "Yang-Zhang"
+	}
+	return "Close-to-close"
+}
+
+// hasRanges reports whether any bar has a high above its low
+func hasRanges(bts *types.BTCTimeSeries) bool {
+	for _, p := range bts.Data {
+		if p.High > p.Low {
+			return true
+		}
+	}
+	return false
+}
+
+// validBar reports whether all prices of p are positive, so their logs exist
+func validBar(p types.BTCPrice) bool {
+	return p.Open > 0 && p.High > 0 && p.Low > 0 && p.Close > 0
+}
+
+// parkinsonVariance is the per-bar variance from the high-low range:
+// mean(ln(H/L)^2) / (4 ln 2)
+func parkinsonVariance(bts *types.BTCTimeSeries) float64 {
+	sum, n := 0.0, 0
+	for _, p := range bts.Data {
+		if !validBar(p) {
+			continue
+		}
+		hl := math.Log(p.High / p.Low)
+		sum += hl * hl
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n) / (4 * math.Ln2)
+}
+
+// garmanKlassVariance is the per-bar variance from the range and the
+// open-to-close move: mean(0.5 ln(H/L)^2 - (2 ln 2 - 1) ln(C/O)^2)
+func garmanKlassVariance(bts *types.BTCTimeSeries) float64 {
+	sum, n := 0.0, 0
+	for _, p := range bts.Data {
+		if !validBar(p) {
+			continue
+		}
+		hl, co := math.Log(p.High/p.Low), math.Log(p.Close/p.Open)
+		sum += 0.5*hl*hl - (2*math.Ln2-1)*co*co
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// rogersSatchellVariance is the per-bar variance that stays unbiased under
+// drift: mean(ln(H/C) ln(H/O) + ln(L/C) ln(L/O))
+func rogersSatchellVariance(bars []types.BTCPrice) float64 {
+	sum, n := 0.0, 0
+	for _, p := range bars {
+		if !validBar(p) {
+			continue
+		}
+		sum += math.Log(p.High/p.Close)*math.Log(p.High/p.Open) + math.Log(p.Low/p.Close)*math.Log(p.Low/p.Open)
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// yangZhangVariance combines the overnight (previous close to open) variance,
+// the open-to-close variance and the Rogers-Satchell variance with the
+// weight k that minimizes the estimator's variance. It handles both drift
+// and opening jumps.
+func yangZhangVariance(bts *types.BTCTimeSeries) float64 {
+	var overnight, openClose []float64
+	var bars []types.BTCPrice
+	for i := 1; i < len(bts.Data); i++ {
+		prev, p := bts.Data[i-1], bts.Data[i]
+		if prev.Close <= 0 || !validBar(p) {
+			continue
+		}
+		overnight = append(overnight, math.Log(p.Open/prev.Close))
+		openClose = append(openClose, math.Log(p.Close/p.Open))
+		bars = append(bars, p)
+	}
+	n := float64(len(bars))
+	if n < 2 {
+		return 0
+	}
+	k := 0.34 / (1.34 + (n+1)/(n-1))
+	return sampleVariance(overnight) + k*sampleVariance(openClose) + (1-k)*rogersSatchellVariance(bars)
+}
+
+// sampleVariance is the unbiased (n-1) variance of values
+func sampleVariance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values)-1)
+}
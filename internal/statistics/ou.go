@@ -0,0 +1,71 @@
+package statistics
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// FitOU fits an Ornstein-Uhlenbeck process to series by regressing each
+// value on the previous one: x[t] = a + φ·x[t-1] + ε. For 0 < φ < 1 the
+// process reverts to a/(1-φ) at θ = -ln φ per bar, so deviations halve in
+// ln 2/θ bars. barsPerDay converts the half-life to days. The suggested
+// lookbacks are half, one and two half-lives, the usual range for the
+// moving average and deviation of a z-score rule.
+func FitOU(name string, series []float64, barsPerDay float64) types.OUFit {
+	fit := types.OUFit{Series: name, Bars: len(series)}
+	if len(series) < 3 {
+		return fit
+	}
+
+	ls := newLeastSquares(2)
+	for t := 1; t < len(series); t++ {
+		ls.add([]float64{1, series[t-1]}, series[t])
+	}
+	coef, se, ssr, ok := ls.fit(2)
+	if !ok || se[1] == 0 {
+		return fit
+	}
+	fit.Phi = coef[1]
+	fit.TStat = (fit.Phi - 1) / se[1]
+	fit.MeanReverting = fit.Phi > 0 && fit.Phi < 1
+	if !fit.MeanReverting {
+		return fit
+	}
+
+	obs := float64(ls.n)
+	fit.Significant = fit.TStat < adfCritical[1][0]+adfCritical[1][1]/obs+adfCritical[1][2]/(obs*obs)
+	fit.Theta = -math.Log(fit.Phi)
+	fit.Mean = coef[0] / (1 - fit.Phi)
+	residualVariance := ssr / (obs - 2)
+	fit.Sigma = math.Sqrt(residualVariance * 2 * fit.Theta / (1 - fit.Phi*fit.Phi))
+	fit.HalfLifeBars = math.Ln2 / fit.Theta
+	if barsPerDay > 0 {
+		fit.HalfLifeDays = fit.HalfLifeBars / barsPerDay
+	}
+
+	for _, multiple := range []float64{0.5, 1, 2} {
+		lookback := int(math.Round(fit.HalfLifeBars * multiple))
+		if lookback < 2 {
+			lookback = 2
+		}
+		if lookback > len(series) {
+			break
+		}
+		if n := len(fit.Lookbacks); n == 0 || fit.Lookbacks[n-1] != lookback {
+			fit.Lookbacks = append(fit.Lookbacks, lookback)
+		}
+	}
+	return fit
+}
+
+// FitOULogPrices fits the Ornstein-Uhlenbeck process to the log of the
+// positive prices
+func FitOULogPrices(prices []float64, barsPerDay float64) types.OUFit {
+	var logPrices []float64
+	for _, p := range prices {
+		if p > 0 {
+			logPrices = append(logPrices, math.Log(p))
+		}
+	}
+	return FitOU(SeriesLogPrices, logPrices, barsPerDay)
+}
@@ -0,0 +1,126 @@
+package statistics
+
+import "math"
+
+// leastSquares accumulates the cross products of a linear regression one
+// observation at a time, so long series need no design matrix in memory and
+// nested models, the first k regressors of the same rows, fit from one pass
+type leastSquares struct {
+	xtx [][]float64
+	xty []float64
+	yty float64
+	n   int
+}
+
+// newLeastSquares returns an accumulator for rows of k regressors
+func newLeastSquares(k int) *leastSquares {
+	ls := &leastSquares{xtx: make([][]float64, k), xty: make([]float64, k)}
+	for i := range ls.xtx {
+		ls.xtx[i] = make([]float64, k)
+	}
+	return ls
+}
+
+// add accumulates the observation y with regressors x
+func (ls *leastSquares) add(x []float64, y float64) {
+	for i, xi := range x {
+		for j := i; j < len(x); j++ {
+			ls.xtx[i][j] += xi * x[j]
+		}
+		ls.xty[i] += xi * y
+	}
+	ls.yty += y * y
+	ls.n++
+}
+
+// fit solves the regression on the first k regressors, returning their
+// coefficients, standard errors and the sum of squared residuals; ok is
+// false when the regressors are collinear or there are no residual degrees
+// of freedom
+func (ls *leastSquares) fit(k int) (coef, se []float64, ssr float64, ok bool) {
+	if k <= 0 || ls.n <= k {
+		return nil, nil, 0, false
+	}
+	inv, ok := invertSymmetric(ls.xtx, k)
+	if !ok {
+		return nil, nil, 0, false
+	}
+
+	coef = make([]float64, k)
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			coef[i] += inv[i][j] * ls.xty[j]
+		}
+	}
+	ssr = ls.yty
+	for i := 0; i < k; i++ {
+		ssr -= coef[i] * ls.xty[i]
+	}
+	ssr = math.Max(ssr, 0)
+
+	sigma2 := ssr / float64(ls.n-k)
+	se = make([]float64, k)
+	for i := range se {
+		se[i] = math.Sqrt(sigma2 * inv[i][i])
+	}
+	return coef, se, ssr, true
+}
+
+// invertSymmetric inverts the leading k by k block of a, whose upper
+// triangle holds a symmetric matrix, by Gauss-Jordan elimination with
+// partial pivoting; ok is false when the block is singular
+func invertSymmetric(a [][]float64, k int) (inv [][]float64, ok bool) {
+	m := make([][]float64, k)
+	inv = make([][]float64, k)
+	for i := 0; i < k; i++ {
+		m[i] = make([]float64, k)
+		inv[i] = make([]float64, k)
+		inv[i][i] = 1
+		for j := 0; j < k; j++ {
+			if j >= i {
+				m[i][j] = a[i][j]
+			} else {
+				m[i][j] = a[j][i]
+			}
+		}
+	}
+
+	// Pivots this much smaller than the largest sum of squares are rounding
+	// error of a singular block
+	tol := 0.0
+	for i := 0; i < k; i++ {
+		tol = math.Max(tol, math.Abs(m[i][i]))
+	}
+	tol *= 1e-12
+
+	for col := 0; col < k; col++ {
+		pivot := col
+		for row := col + 1; row < k; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) <= tol {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		scale := 1 / m[col][col]
+		for j := 0; j < k; j++ {
+			m[col][j] *= scale
+			inv[col][j] *= scale
+		}
+		for row := 0; row < k; row++ {
+			if row == col || m[row][col] == 0 {
+				continue
+			}
+			factor := m[row][col]
+			for j := 0; j < k; j++ {
+				m[row][j] -= factor * m[col][j]
+				inv[row][j] -= factor * inv[col][j]
+			}
+		}
+	}
+	return inv, true
+}
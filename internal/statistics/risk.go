@@ -0,0 +1,116 @@
+package statistics
+
+import (
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+)
+
+// DefaultRiskConfig returns the default conventions: no risk-free rate or
+// funding cost, simple compounding and periods per year detected from the data
+func DefaultRiskConfig() types.RiskConfig {
+	return types.RiskConfig{
+		Compounding: "simple",
+	}
+}
+
+// ResolveRiskConfig fills in the periods per year from the series when it is
+// not set, and defaults the compounding convention and volatility estimator
+func ResolveRiskConfig(bts *types.BTCTimeSeries, rc types.RiskConfig) types.RiskConfig {
+	if rc.PeriodsPerYear <= 0 {
+		rc.PeriodsPerYear = DetectPeriodsPerYear(bts)
+	}
+	if rc.Compounding == "" {
+		rc.Compounding = "simple"
+	}
+	if rc.VolEstimator == "" {
+		rc.VolEstimator = EstimatorCloseToClose
+	}
+	return rc
+}
+
+// DetectPeriodsPerYear returns the number of bars per year at the series'
+// native frequency
+func DetectPeriodsPerYear(bts *types.BTCTimeSeries) int {
+	return timeseries.DetectFrequency(bts).PeriodsPerYear
+}
+
+// DescribeRiskConfig documents the convention in one line for reports
+func DescribeRiskConfig(rc types.RiskConfig) string {
+	estimator := rc.VolEstimator
+	if estimator == "" {
+		estimator = EstimatorCloseToClose
+	}
+	return fmt.Sprintf("risk-free %.2f%%/yr, funding %.2f%%/yr, %d periods/yr, %s compounding, %s volatility",
+		rc.RiskFreeRate*100, rc.FundingRate*100, rc.PeriodsPerYear, rc.Compounding, estimator)
+}
+
+// PeriodHurdle returns the per-period return needed to cover the risk-free
+// rate and funding cost under the configured compounding convention
+func PeriodHurdle(rc types.RiskConfig) float64 {
+	annual := rc.RiskFreeRate + rc.FundingRate
+	if rc.PeriodsPerYear <= 0 {
+		return 0
+	}
+	if rc.Compounding == "geometric" {
+		return math.Pow(1+annual, 1/float64(rc.PeriodsPerYear)) - 1
+	}
+	return annual / float64(rc.PeriodsPerYear)
+}
+
+// AnnualizeReturns converts per-period returns to an annual return: the mean
+// times periods per year for simple compounding, or the compounded growth
+// rate scaled to a year for geometric compounding
+func AnnualizeReturns(returns []float64, rc types.RiskConfig) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+
+	if rc.Compounding == "geometric" {
+		growth := 0.0
+		for _, r := range returns {
+			growth += math.Log1p(r)
+		}
+		return math.Expm1(growth / float64(len(returns)) * float64(rc.PeriodsPerYear))
+	}
+
+	return Calculate(returns).Mean * float64(rc.PeriodsPerYear)
+}
+
+// SharpeRatio calculates the annualized Sharpe ratio in excess of the
+// risk-free rate and funding cost
+func SharpeRatio(returns []float64, rc types.RiskConfig) float64 {
+	return SharpeRatioWithVolatility(returns, CalculateVolatility(returns, rc.PeriodsPerYear), rc)
+}
+
+// SharpeRatioWithVolatility calculates the annualized Sharpe ratio against
+// an annualized volatility from any estimator
+func SharpeRatioWithVolatility(returns []float64, volatility float64, rc types.RiskConfig) float64 {
+	if volatility == 0 {
+		return 0
+	}
+	return (AnnualizeReturns(returns, rc) - rc.RiskFreeRate - rc.FundingRate) / volatility
+}
+
+// SortinoRatio calculates the annualized Sortino ratio, using the downside
+// deviation below the per-period hurdle
+func SortinoRatio(returns []float64, rc types.RiskConfig) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+
+	hurdle := PeriodHurdle(rc)
+	sumSquares := 0.0
+	for _, r := range returns {
+		if r < hurdle {
+			sumSquares += (r - hurdle) * (r - hurdle)
+		}
+	}
+	downsideDeviation := math.Sqrt(sumSquares/float64(len(returns))) * math.Sqrt(float64(rc.PeriodsPerYear))
+	if downsideDeviation == 0 {
+		return 0
+	}
+
+	return (AnnualizeReturns(returns, rc) - rc.RiskFreeRate - rc.FundingRate) / downsideDeviation
+}
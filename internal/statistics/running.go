@@ -0,0 +1,113 @@
+package statistics
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// RunningStats accumulates statistics in a single pass using Welford-style
+// updates for the first four moments, so arbitrarily long series can be
+// summarized in constant memory. The median is estimated from a fixed-size
+// reservoir sample.
+type RunningStats struct {
+	n          int
+	mean       float64
+	m2, m3, m4 float64
+	min, max   float64
+	reservoir  []float64
+	sampleSize int
+	rng        *rand.Rand
+}
+
+// NewRunningStats creates an accumulator keeping sampleSize values for the median estimate
+func NewRunningStats(sampleSize int) *RunningStats {
+	return &RunningStats{
+		sampleSize: sampleSize,
+		rng:        rand.New(rand.NewSource(1)),
+	}
+}
+
+// Add accumulates one value
+func (rs *RunningStats) Add(x float64) {
+	if rs.n == 0 || x < rs.min {
+		rs.min = x
+	}
+	if rs.n == 0 || x > rs.max {
+		rs.max = x
+	}
+
+	n1 := float64(rs.n)
+	rs.n++
+	n := float64(rs.n)
+
+	delta := x - rs.mean
+	deltaN := delta / n
+	deltaN2 := deltaN * deltaN
+	term1 := delta * deltaN * n1
+
+	rs.mean += deltaN
+	rs.m4 += term1*deltaN2*(n*n-3*n+3) + 6*deltaN2*rs.m2 - 4*deltaN*rs.m3
+	rs.m3 += term1*deltaN*(n-2) - 3*deltaN*rs.m2
+	rs.m2 += term1
+
+	// Reservoir sampling (Algorithm R)
+	if len(rs.reservoir) < rs.sampleSize {
+		rs.reservoir = append(rs.reservoir, x)
+	} else if j := rs.rng.Intn(rs.n); j < rs.sampleSize {
+		rs.reservoir[j] = x
+	}
+}
+
+// Count returns the number of values added
+func (rs *RunningStats) Count() int {
+	return rs.n
+}
+
+// Mean returns the running mean
+func (rs *RunningStats) Mean() float64 {
+	return rs.mean
+}
+
+// StdDev returns the running population standard deviation
+func (rs *RunningStats) StdDev() float64 {
+	if rs.n == 0 {
+		return 0
+	}
+	return math.Sqrt(rs.m2 / float64(rs.n))
+}
+
+// Statistics returns the accumulated values in the same form as Calculate
+func (rs *RunningStats) Statistics() types.Statistics {
+	if rs.n == 0 {
+		return types.Statistics{}
+	}
+
+	n := float64(rs.n)
+	variance := rs.m2 / n
+	stats := types.Statistics{
+		Count:    rs.n,
+		Mean:     rs.mean,
+		StdDev:   math.Sqrt(variance),
+		Min:      rs.min,
+		Max:      rs.max,
+		Variance: variance,
+	}
+
+	if rs.m2 > 0 {
+		stats.Skewness = math.Sqrt(n) * rs.m3 / math.Pow(rs.m2, 1.5)
+		stats.Kurtosis = n*rs.m4/(rs.m2*rs.m2) - 3
+	}
+
+	sample := make([]float64, len(rs.reservoir))
+	copy(sample, rs.reservoir)
+	sort.Float64s(sample)
+	if k := len(sample); k%2 == 0 {
+		stats.Median = (sample[k/2-1] + sample[k/2]) / 2
+	} else {
+		stats.Median = sample[k/2]
+	}
+
+	return stats
+}
@@ -0,0 +1,193 @@
+package statistics
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+)
+
+// Verdicts of a StationarityCheck
+const (
+	VerdictStationary   = "stationary"
+	VerdictUnitRoot     = "unit root"
+	VerdictInconclusive = "inconclusive"
+)
+
+// Series of TestStationarity
+const (
+	SeriesLogPrices  = "Log prices"
+	SeriesLogReturns = "Log returns"
+)
+
+// adfCritical are MacKinnon's (2010) response surface coefficients for the
+// 1%, 5% and 10% critical values of the ADF test with a constant: the value
+// at T observations is b0 + b1/T + b2/T²
+var adfCritical = [3][3]float64{
+	{-3.43035, -6.5393, -16.786},
+	{-2.86154, -2.8903, -4.234},
+	{-2.56677, -1.5384, -2.809},
+}
+
+// kpssCritical are the 1%, 5% and 10% critical values of the KPSS test of
+// level stationarity (Kwiatkowski et al., 1992)
+var kpssCritical = [3]float64{0.739, 0.463, 0.347}
+
+// TestStationarity runs the ADF and KPSS tests on the log of prices and on
+// their log returns. Non-positive prices are skipped.
+func TestStationarity(prices []float64) types.Stationarity {
+	var logPrices, logReturns []float64
+	for _, p := range prices {
+		if p <= 0 {
+			continue
+		}
+		if n := len(logPrices); n > 0 {
+			logReturns = append(logReturns, math.Log(p)-logPrices[n-1])
+		}
+		logPrices = append(logPrices, math.Log(p))
+	}
+	return types.Stationarity{
+		Prices:  CheckStationarity(SeriesLogPrices, logPrices),
+		Returns: CheckStationarity(SeriesLogReturns, logReturns),
+	}
+}
+
+// CheckStationarity runs both tests on series and reads them together:
+// stationary when ADF rejects a unit root and KPSS does not reject
+// stationarity, a unit root when ADF does not reject and KPSS does, and
+// inconclusive when they disagree or the series is too short to test
+func CheckStationarity(name string, series []float64) types.StationarityCheck {
+	check := types.StationarityCheck{Series: name, ADF: ADF(series), KPSS: KPSS(series)}
+	switch {
+	case check.ADF.Observations == 0 || check.KPSS.Observations == 0:
+		check.Verdict = VerdictInconclusive
+	case check.ADF.Stationary && check.KPSS.Stationary:
+		check.Verdict = VerdictStationary
+	case !check.ADF.Stationary && !check.KPSS.Stationary:
+		check.Verdict = VerdictUnitRoot
+	default:
+		check.Verdict = VerdictInconclusive
+	}
+	return check
+}
+
+// schwertLags is the rule of thumb for the lag length of unit-root and
+// stationarity tests, 12·(n/100)^¼
+func schwertLags(n int) int {
+	return int(12 * math.Pow(float64(n)/100, 0.25))
+}
+
+// ADF runs the augmented Dickey-Fuller test with a constant on series,
+// regressing each change on the previous level and lagged changes. The lag
+// length minimizes AIC up to the Schwert rule, over a common sample. A
+// statistic below the 5% critical value rejects the unit root.
+func ADF(series []float64) types.StationarityTest {
+	test := types.StationarityTest{Test: "ADF"}
+	n := len(series)
+	maxLag := schwertLags(n)
+	if limit := n/2 - 3; maxLag > limit {
+		maxLag = limit
+	}
+	if maxLag < 0 {
+		return test
+	}
+
+	diffs := make([]float64, n)
+	for t := 1; t < n; t++ {
+		diffs[t] = series[t] - series[t-1]
+	}
+	// regressors of the change at t with lags lagged changes: a constant,
+	// the previous level, then the changes before it
+	row := func(t, lags int) []float64 {
+		x := make([]float64, 2+lags)
+		x[0], x[1] = 1, series[t-1]
+		for i := 1; i <= lags; i++ {
+			x[1+i] = diffs[t-i]
+		}
+		return x
+	}
+
+	common := newLeastSquares(2 + maxLag)
+	for t := maxLag + 1; t < n; t++ {
+		common.add(row(t, maxLag), diffs[t])
+	}
+	lags, bestAIC := 0, math.Inf(1)
+	for p := 0; p <= maxLag; p++ {
+		_, _, ssr, ok := common.fit(2 + p)
+		if !ok || ssr <= 0 {
+			continue
+		}
+		if aic := float64(common.n)*math.Log(ssr/float64(common.n)) + 2*float64(2+p); aic < bestAIC {
+			lags, bestAIC = p, aic
+		}
+	}
+
+	final := newLeastSquares(2 + lags)
+	for t := lags + 1; t < n; t++ {
+		final.add(row(t, lags), diffs[t])
+	}
+	// A regression that leaves no residuals, as on a formula-generated
+	// series, has no sampling error to test against
+	coef, se, ssr, ok := final.fit(2 + lags)
+	if !ok || se[1] == 0 || ssr <= 1e-10*final.yty {
+		return test
+	}
+
+	test.Statistic = coef[1] / se[1]
+	test.Lags = lags
+	test.Observations = final.n
+	obs := float64(final.n)
+	critical := make([]float64, 3)
+	for i, b := range adfCritical {
+		critical[i] = b[0] + b[1]/obs + b[2]/(obs*obs)
+	}
+	test.Critical1, test.Critical5, test.Critical10 = critical[0], critical[1], critical[2]
+	test.Stationary = test.Statistic < test.Critical5
+	return test
+}
+
+// KPSS runs the Kwiatkowski-Phillips-Schmidt-Shin test of level
+// stationarity on series: the squared partial sums of deviations from the
+// mean over the long-run variance, estimated with a Bartlett kernel over
+// the Schwert rule's lags. A statistic above the 5% critical value rejects
+// stationarity.
+func KPSS(series []float64) types.StationarityTest {
+	test := types.StationarityTest{Test: "KPSS", Critical1: kpssCritical[0], Critical5: kpssCritical[1], Critical10: kpssCritical[2]}
+	n := len(series)
+	if n < 3 {
+		return test
+	}
+	lags := schwertLags(n)
+	if lags > n-1 {
+		lags = n - 1
+	}
+
+	mean := Calculate(series).Mean
+	residuals := make([]float64, n)
+	partial, sumSquares := 0.0, 0.0
+	for t, v := range series {
+		residuals[t] = v - mean
+		partial += residuals[t]
+		sumSquares += partial * partial
+	}
+
+	longRun := 0.0
+	for _, e := range residuals {
+		longRun += e * e
+	}
+	for l := 1; l <= lags; l++ {
+		cov := 0.0
+		for t := l; t < n; t++ {
+			cov += residuals[t] * residuals[t-l]
+		}
+		longRun += 2 * (1 - float64(l)/float64(lags+1)) * cov
+	}
+	longRun /= float64(n)
+	if longRun <= 0 {
+		return test
+	}
+
+	test.Statistic = sumSquares / (float64(n) * float64(n) * longRun)
+	test.Lags = lags
+	test.Observations = n
+	test.Stationary = test.Statistic <= test.Critical5
+	return test
+}
@@ -0,0 +1,88 @@
+package statistics
+
+import (
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+)
+
+// z95 is the standard normal quantile of the 5% left tail
+const z95 = -1.645
+
+// TailRiskMetrics computes the tail-risk measures of bts from its per-bar
+// returns. sharpe is the annualized Sharpe ratio to adjust, so it follows
+// the volatility estimator in use; the adjustment is applied per bar and
+// annualized again at rc.PeriodsPerYear.
+func TailRiskMetrics(bts *types.BTCTimeSeries, returns []float64, sharpe float64, rc types.RiskConfig) types.TailRisk {
+	stats := Calculate(returns)
+	tr := types.TailRisk{
+		Skewness:       stats.Skewness,
+		ExcessKurtosis: stats.Kurtosis,
+		SharpeRatio:    sharpe,
+		CDaR95:         conditionalDrawdownAtRisk(timeseries.GetClosePrices(bts), 0.05),
+	}
+
+	// Returns run from the second bar, so return i closes bar i+1
+	start, growth := -1, 1.0
+	for i, r := range returns {
+		if r >= 0 {
+			start, growth = -1, 1.0
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		growth *= 1 + r
+		if streak := i - start + 1; streak > tr.MaxLossStreak {
+			tr.MaxLossStreak = streak
+			tr.LossStreakReturn = growth - 1
+			tr.LossStreakStart = bts.Data[start].Timestamp
+			tr.LossStreakEnd = bts.Data[i+1].Timestamp
+		}
+	}
+	if rc.PeriodsPerYear > 0 {
+		tr.MaxLossStreakDays = float64(tr.MaxLossStreak) * 365 / float64(rc.PeriodsPerYear)
+	}
+
+	// Cornish-Fisher expansion of the normal quantile
+	s, k := stats.Skewness, stats.Kurtosis
+	zcf := z95 + (z95*z95-1)*s/6 + (z95*z95*z95-3*z95)*k/24 - (2*z95*z95*z95-5*z95)*s*s/36
+	tr.VaR95 = stats.Mean + z95*stats.StdDev
+	tr.CornishFisherVaR95 = stats.Mean + zcf*stats.StdDev
+
+	if rc.PeriodsPerYear > 0 {
+		scale := math.Sqrt(float64(rc.PeriodsPerYear))
+		sr := sharpe / scale
+		tr.AdjustedSharpeRatio = sr * (1 + s/6*sr - k/24*sr*sr) * scale
+	}
+	return tr
+}
+
+// conditionalDrawdownAtRisk is the mean of the worst tail fraction of the
+// drawdowns from the running peak, taken over every bar
+func conditionalDrawdownAtRisk(prices []float64, tail float64) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+
+	drawdowns := make([]float64, 0, len(prices))
+	peak := 0.0
+	for _, price := range prices {
+		peak = math.Max(peak, price)
+		if peak > 0 {
+			drawdowns = append(drawdowns, (peak-price)/peak)
+		}
+	}
+	if len(drawdowns) == 0 {
+		return 0
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(drawdowns)))
+
+	worst := int(math.Ceil(tail * float64(len(drawdowns))))
+	sum := 0.0
+	for _, dd := range drawdowns[:worst] {
+		sum += dd
+	}
+	return sum / float64(worst)
+}
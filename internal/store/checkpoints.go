@@ -0,0 +1,77 @@
+package store
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// CheckpointStore persists the checkpoints of interrupted downloads in a
+// JSON file, keyed by ID and rewritten atomically on every change
+type CheckpointStore struct {
+	mu          sync.RWMutex
+	path        string
+	checkpoints map[string]types.DownloadCheckpoint
+}
+
+// OpenCheckpoints loads the checkpoint store at path, starting empty when
+// the file does not exist
+func OpenCheckpoints(path string) (*CheckpointStore, error) {
+	s := &CheckpointStore{path: path, checkpoints: make(map[string]types.DownloadCheckpoint)}
+
+	data, ok, err := readStore(path, KindCheckpoints)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.checkpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoints %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the checkpoint with the given ID
+func (s *CheckpointStore) Get(id string) (types.DownloadCheckpoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cp, ok := s.checkpoints[id]
+	return cp, ok
+}
+
+// Put inserts or replaces the checkpoint of cp.ID
+func (s *CheckpointStore) Put(cp types.DownloadCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.checkpoints[cp.ID]
+	s.checkpoints[cp.ID] = cp
+	if err := writeStore(s.path, KindCheckpoints, s.checkpoints); err != nil {
+		if existed {
+			s.checkpoints[cp.ID] = previous
+		} else {
+			delete(s.checkpoints, cp.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the checkpoint with the given ID, if any
+func (s *CheckpointStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.checkpoints[id]
+	if !ok {
+		return nil
+	}
+	delete(s.checkpoints, id)
+	if err := writeStore(s.path, KindCheckpoints, s.checkpoints); err != nil {
+		s.checkpoints[id] = previous
+		return err
+	}
+	return nil
+}
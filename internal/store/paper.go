@@ -0,0 +1,57 @@
+package store
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// PaperStore persists one paper trading account in a JSON file, rewritten
+// atomically on every change
+type PaperStore struct {
+	mu      sync.RWMutex
+	path    string
+	account *types.PaperAccount
+}
+
+// OpenPaper loads the paper account at path. The store is empty when the
+// file does not exist.
+func OpenPaper(path string) (*PaperStore, error) {
+	s := &PaperStore{path: path}
+
+	data, ok, err := readStore(path, KindPaper)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s, nil
+	}
+	var account types.PaperAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("failed to decode paper account %s: %w", path, err)
+	}
+	s.account = &account
+	return s, nil
+}
+
+// Account returns the stored account, false when there is none yet
+func (s *PaperStore) Account() (types.PaperAccount, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.account == nil {
+		return types.PaperAccount{}, false
+	}
+	return *s.account, true
+}
+
+// Put replaces the stored account
+func (s *PaperStore) Put(account types.PaperAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := writeStore(s.path, KindPaper, account); err != nil {
+		return err
+	}
+	s.account = &account
+	return nil
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// layouts return a value of the current data layout of each kind, to decode
+// imported data into
+var layouts = map[string]func() interface{}{
+	KindWatchlists:   func() interface{} { return &[]types.Watchlist{} },
+	KindRuns:         func() interface{} { return &[]types.BacktestRun{} },
+	KindSignalStates: func() interface{} { return &map[string]map[string]types.SignalState{} },
+	KindPaper:        func() interface{} { return &types.PaperAccount{} },
+	KindCheckpoints:  func() interface{} { return &map[string]types.DownloadCheckpoint{} },
+}
+
+// ReadData returns the data of the store file of kind at path at the
+// current schema version, upgrading the file as opening the store would;
+// ok is false when the file does not exist
+func ReadData(path, kind string) (data json.RawMessage, ok bool, err error) {
+	if _, known := layouts[kind]; !known {
+		return nil, false, fmt.Errorf("unknown store kind %q", kind)
+	}
+	return readStore(path, kind)
+}
+
+// WriteData migrates data of kind from version to the current schema,
+// checks that it decodes as the store's data and writes it to path
+// atomically, replacing the file. It returns the number of records written:
+// the entries of a list or map, or 1.
+func WriteData(path, kind string, version int, data json.RawMessage) (int, error) {
+	if _, known := layouts[kind]; !known {
+		return 0, fmt.Errorf("unknown store kind %q", kind)
+	}
+	if current := SchemaVersion(kind); version > current {
+		return 0, fmt.Errorf("%s has schema version %d, newer than version %d of this build; upgrade btc-analyzer", kind, version, current)
+	}
+	data, err := migrate(kind, version, data)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", kind, err)
+	}
+	records, err := Records(kind, data)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeStore(path, kind, data); err != nil {
+		return 0, err
+	}
+	return records, nil
+}
+
+// Records decodes data of kind at the current schema and returns its number
+// of records: the entries of a list or map, or 1
+func Records(kind string, data json.RawMessage) (int, error) {
+	layout, known := layouts[kind]
+	if !known {
+		return 0, fmt.Errorf("unknown store kind %q", kind)
+	}
+	v := layout()
+	if err := json.Unmarshal(data, v); err != nil {
+		return 0, fmt.Errorf("invalid %s data: %w", kind, err)
+	}
+	switch value := reflect.ValueOf(v).Elem(); value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len(), nil
+	}
+	return 1, nil
+}
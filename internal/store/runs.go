@@ -0,0 +1,79 @@
+package store
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RunStore persists backtest runs in a JSON file, oldest first. Runs are
+// only ever added; every addition rewrites the file atomically.
+type RunStore struct {
+	mu   sync.RWMutex
+	path string
+	runs []types.BacktestRun
+}
+
+// OpenRuns loads the run store at path, starting empty when the file does
+// not exist
+func OpenRuns(path string) (*RunStore, error) {
+	s := &RunStore{path: path}
+
+	data, ok, err := readStore(path, KindRuns)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.runs); err != nil {
+		return nil, fmt.Errorf("failed to decode run store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Add stores a run, assigning its ID and creation time, and returns it as stored
+func (s *RunStore) Add(run types.BacktestRun) (types.BacktestRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := newID()
+	if err != nil {
+		return run, err
+	}
+	run.ID = id
+	run.CreatedAt = time.Now().UTC()
+	s.runs = append(s.runs, run)
+
+	if err := s.save(); err != nil {
+		s.runs = s.runs[:len(s.runs)-1]
+		return run, err
+	}
+	return run, nil
+}
+
+// List returns every run, oldest first
+func (s *RunStore) List() []types.BacktestRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]types.BacktestRun(nil), s.runs...)
+}
+
+// Get returns the run with the given ID
+func (s *RunStore) Get(id string) (types.BacktestRun, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, run := range s.runs {
+		if run.ID == id {
+			return run, true
+		}
+	}
+	return types.BacktestRun{}, false
+}
+
+// save writes all runs to the store file. The caller holds the write lock.
+func (s *RunStore) save() error {
+	return writeStore(s.path, KindRuns, s.runs)
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Kinds of store file, each with its own schema history. The kind names the
+// file in errors.
+const (
+	KindWatchlists   = "watchlist store"
+	KindRuns         = "run store"
+	KindSignalStates = "signal states"
+	KindPaper        = "paper account"
+	KindCheckpoints  = "checkpoints"
+)
+
+// Migration upgrades the data of a store file from the version before
+// Version to Version
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(data json.RawMessage) (json.RawMessage, error)
+}
+
+// versioned is the first migration of every kind: the unversioned layout of
+// earlier releases becomes the data of a versioned file unchanged
+var versioned = Migration{1, "record the schema version", func(data json.RawMessage) (json.RawMessage, error) { return data, nil }}
+
+// migrations are the schema histories of the store files, oldest first. A
+// schema change appends a migration to its kind; a migration that has
+// shipped is never edited, since user files may already be past it.
+var migrations = map[string][]Migration{
+	KindWatchlists:   {versioned},
+	KindRuns:         {versioned},
+	KindSignalStates: {versioned},
+	KindPaper:        {versioned},
+	KindCheckpoints:  {versioned},
+}
+
+// envelope is the layout of a versioned store file
+type envelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// SchemaVersion returns the schema version this build writes for kind
+func SchemaVersion(kind string) int {
+	history := migrations[kind]
+	if len(history) == 0 {
+		return 0
+	}
+	return history[len(history)-1].Version
+}
+
+// readStore reads the store file of kind at path and returns its data at
+// the current schema version; ok is false when the file does not exist. A
+// file of an older version is upgraded by the migrations after it, its
+// original kept as path.v<version>.bak, and rewritten at the current
+// version, so the upgrade runs once. A file of a newer version, written by
+// a later release, is an error rather than being misread.
+func readStore(path, kind string) (data json.RawMessage, ok bool, err error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+
+	version, data, err := decodeEnvelope(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode %s %s: %w", kind, path, err)
+	}
+	current := SchemaVersion(kind)
+	if version > current {
+		return nil, false, fmt.Errorf("%s %s has schema version %d, newer than version %d of this build; upgrade btc-analyzer", kind, path, version, current)
+	}
+	if version == current {
+		return data, true, nil
+	}
+
+	if data, err = migrate(kind, version, data); err != nil {
+		return nil, false, fmt.Errorf("%s %s: %w", kind, path, err)
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backup, raw, 0644); err != nil {
+		return nil, false, fmt.Errorf("failed to back up %s before migrating it: %w", kind, err)
+	}
+	if err := writeJSONAtomic(path, envelope{SchemaVersion: current, Data: data}, kind); err != nil {
+		return nil, false, err
+	}
+	fmt.Printf("🗄️  Migrated %s %s from schema version %d to %d (backup %s)\n", kind, path, version, current, backup)
+	return data, true, nil
+}
+
+// migrate upgrades data of kind from version to the current version
+func migrate(kind string, version int, data json.RawMessage) (json.RawMessage, error) {
+	for _, m := range migrations[kind] {
+		if m.Version <= version {
+			continue
+		}
+		var err error
+		if data, err = m.Up(data); err != nil {
+			return nil, fmt.Errorf("failed to migrate to version %d (%s): %w", m.Version, m.Description, err)
+		}
+	}
+	return data, nil
+}
+
+// decodeEnvelope returns the schema version and data of a store file. A
+// file without an envelope is the unversioned layout, version 0.
+func decodeEnvelope(raw []byte) (int, json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) == nil {
+		if _, versioned := fields["schema_version"]; versioned {
+			var env envelope
+			if err := json.Unmarshal(raw, &env); err != nil {
+				return 0, nil, err
+			}
+			return env.SchemaVersion, env.Data, nil
+		}
+	}
+	if !json.Valid(raw) {
+		return 0, nil, fmt.Errorf("invalid JSON")
+	}
+	return 0, raw, nil
+}
+
+// writeStore writes v as the data of a store file of kind at the current
+// schema version, atomically
+func writeStore(path, kind string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", kind, err)
+	}
+	return writeJSONAtomic(path, envelope{SchemaVersion: SchemaVersion(kind), Data: data}, kind)
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"btc-analyzer/internal/types"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// SignalStateStore persists the stabilized signal states of every tracked
+// series in a JSON file, keyed by series and then by signal name. The file
+// is rewritten atomically on every change.
+type SignalStateStore struct {
+	mu     sync.RWMutex
+	path   string
+	states map[string]map[string]types.SignalState
+}
+
+// OpenSignalStates loads the signal state store at path, starting empty
+// when the file does not exist
+func OpenSignalStates(path string) (*SignalStateStore, error) {
+	s := &SignalStateStore{path: path, states: make(map[string]map[string]types.SignalState)}
+
+	data, ok, err := readStore(path, KindSignalStates)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.states); err != nil {
+		return nil, fmt.Errorf("failed to decode signal states %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns a copy of the signal states of the series key, empty when
+// none are stored
+func (s *SignalStateStore) Get(key string) map[string]types.SignalState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	states := make(map[string]types.SignalState, len(s.states[key]))
+	for name, state := range s.states[key] {
+		states[name] = state
+	}
+	return states
+}
+
+// Put replaces the signal states of the series key
+func (s *SignalStateStore) Put(key string, states map[string]types.SignalState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.states[key]
+	s.states[key] = states
+	if err := writeStore(s.path, KindSignalStates, s.states); err != nil {
+		if existed {
+			s.states[key] = previous
+		} else {
+			delete(s.states, key)
+		}
+		return err
+	}
+	return nil
+}
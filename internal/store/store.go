@@ -0,0 +1,154 @@
+package store
+
+import (
+	"btc-analyzer/internal/types"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store persists watchlists in a JSON file. Every change rewrites the file
+// atomically, so a crash never leaves it half written.
+type Store struct {
+	mu         sync.RWMutex
+	path       string
+	watchlists map[string]types.Watchlist
+}
+
+// Open loads the store at path, starting empty when the file does not exist
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, watchlists: make(map[string]types.Watchlist)}
+
+	data, ok, err := readStore(path, KindWatchlists)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s, nil
+	}
+
+	var watchlists []types.Watchlist
+	if err := json.Unmarshal(data, &watchlists); err != nil {
+		return nil, fmt.Errorf("failed to decode store %s: %w", path, err)
+	}
+	for _, w := range watchlists {
+		s.watchlists[w.ID] = w
+	}
+	return s, nil
+}
+
+// List returns the watchlists of owner ordered by ID, or all of them when owner is empty
+func (s *Store) List(owner string) []types.Watchlist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []types.Watchlist
+	for _, w := range s.watchlists {
+		if owner == "" || w.Owner == owner {
+			list = append(list, w)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Get returns the watchlist with the given ID
+func (s *Store) Get(id string) (types.Watchlist, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	w, ok := s.watchlists[id]
+	return w, ok
+}
+
+// Put inserts or replaces a watchlist, assigning an ID to new ones, and
+// returns it as stored
+func (s *Store) Put(w types.Watchlist) (types.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return w, err
+		}
+		w.ID = id
+	}
+	previous, existed := s.watchlists[w.ID]
+	s.watchlists[w.ID] = w
+
+	if err := s.save(); err != nil {
+		if existed {
+			s.watchlists[w.ID] = previous
+		} else {
+			delete(s.watchlists, w.ID)
+		}
+		return w, err
+	}
+	return w, nil
+}
+
+// Delete removes a watchlist, reporting whether it existed
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.watchlists[id]
+	if !ok {
+		return false, nil
+	}
+	delete(s.watchlists, id)
+	if err := s.save(); err != nil {
+		s.watchlists[id] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// save writes all watchlists to a temporary file and renames it over the
+// store file. The caller holds the write lock.
+func (s *Store) save() error {
+	list := make([]types.Watchlist, 0, len(s.watchlists))
+	for _, w := range s.watchlists {
+		list = append(list, w)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	return writeStore(s.path, KindWatchlists, list)
+}
+
+// writeJSONAtomic writes v as indented JSON to a temporary file and renames
+// it over path. what names the file in errors.
+func writeJSONAtomic(path string, v interface{}, what string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", what, err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s directory: %w", what, err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", what, err)
+	}
+	return nil
+}
+
+// newID returns a random 8-byte hex identifier
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,74 @@
+package supply
+
+import (
+	"math"
+	"time"
+)
+
+// Issuance schedule constants of the Bitcoin protocol
+const (
+	InitialReward   = 50.0   // BTC per block before the first halving
+	HalvingInterval = 210000 // blocks between halvings
+	BlocksPerDay    = 144.0  // one block every ten minutes on average
+)
+
+// Genesis is the timestamp of the genesis block
+var Genesis = time.Date(2009, 1, 3, 18, 15, 5, 0, time.UTC)
+
+// Halvings are the dates the block reward halved, at heights 210000,
+// 420000, 630000 and 840000. Later halvings are projected at BlocksPerDay.
+var Halvings = []time.Time{
+	time.Date(2012, 11, 28, 15, 24, 38, 0, time.UTC),
+	time.Date(2016, 7, 9, 16, 46, 13, 0, time.UTC),
+	time.Date(2020, 5, 11, 19, 23, 43, 0, time.UTC),
+	time.Date(2024, 4, 20, 0, 9, 27, 0, time.UTC),
+}
+
+// BlockHeight estimates the block height at t, interpolating between the
+// genesis block and the known halvings and extrapolating at BlocksPerDay
+// after the last one
+func BlockHeight(t time.Time) float64 {
+	if !t.After(Genesis) {
+		return 0
+	}
+	prevTime, prevHeight := Genesis, 0.0
+	for i, halving := range Halvings {
+		height := float64((i + 1) * HalvingInterval)
+		if t.Before(halving) {
+			frac := t.Sub(prevTime).Seconds() / halving.Sub(prevTime).Seconds()
+			return prevHeight + frac*(height-prevHeight)
+		}
+		prevTime, prevHeight = halving, height
+	}
+	return prevHeight + t.Sub(prevTime).Hours()/24*BlocksPerDay
+}
+
+// Supply returns the coins mined up to height, summing the reward of each era
+func Supply(height float64) float64 {
+	total, reward := 0.0, InitialReward
+	for height > 0 && reward > 1e-8 {
+		blocks := math.Min(height, HalvingInterval)
+		total += blocks * reward
+		height -= blocks
+		reward /= 2
+	}
+	return total
+}
+
+// SupplyAt estimates the circulating supply at t
+func SupplyAt(t time.Time) float64 {
+	return Supply(BlockHeight(t))
+}
+
+// NextHalving projects the date of the first halving after t
+func NextHalving(t time.Time) time.Time {
+	for _, halving := range Halvings {
+		if halving.After(t) {
+			return halving
+		}
+	}
+	height := BlockHeight(t)
+	next := (math.Floor(height/HalvingInterval) + 1) * HalvingInterval
+	days := (next - height) / BlocksPerDay
+	return t.Add(time.Duration(days * 24 * float64(time.Hour)))
+}
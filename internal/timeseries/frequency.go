@@ -0,0 +1,73 @@
+package timeseries
+
+import (
+	"btc-analyzer/internal/types"
+	"math"
+	"sort"
+	"time"
+)
+
+// year is a calendar year of continuous trading, as Bitcoin trades around the clock
+const year = 365 * 24 * time.Hour
+
+// knownFrequencies are the bar intervals recognized by name
+var knownFrequencies = []struct {
+	name     string
+	interval time.Duration
+}{
+	{"minute", time.Minute},
+	{"5-minute", 5 * time.Minute},
+	{"15-minute", 15 * time.Minute},
+	{"30-minute", 30 * time.Minute},
+	{"hourly", time.Hour},
+	{"4-hour", 4 * time.Hour},
+	{"daily", 24 * time.Hour},
+	{"weekly", 7 * 24 * time.Hour},
+}
+
+// DetectFrequency determines the native bar interval of the series from the
+// median spacing between timestamps, which is robust to gaps and duplicates.
+// Series with fewer than two distinct timestamps are assumed daily.
+func DetectFrequency(bts *types.BTCTimeSeries) types.Frequency {
+	deltas := make([]time.Duration, 0, len(bts.Data))
+	for i := 1; i < len(bts.Data); i++ {
+		if delta := bts.Data[i].Timestamp.Sub(bts.Data[i-1].Timestamp); delta > 0 {
+			deltas = append(deltas, delta)
+		}
+	}
+	if len(deltas) == 0 {
+		return newFrequency("daily", 24*time.Hour)
+	}
+
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i] < deltas[j] })
+	median := deltas[len(deltas)/2]
+	if len(deltas)%2 == 0 {
+		median = (deltas[len(deltas)/2-1] + deltas[len(deltas)/2]) / 2
+	}
+
+	for _, known := range knownFrequencies {
+		if math.Abs(float64(median-known.interval)) <= 0.1*float64(known.interval) {
+			return newFrequency(known.name, known.interval)
+		}
+	}
+	return newFrequency("irregular", median)
+}
+
+// BarsFor returns how many bars of the series' frequency span duration, at least one
+func BarsFor(bts *types.BTCTimeSeries, duration time.Duration) int {
+	bars := int(math.Round(float64(duration) / float64(DetectFrequency(bts).Interval)))
+	if bars < 1 {
+		return 1
+	}
+	return bars
+}
+
+// newFrequency builds a Frequency with its derived bar counts
+func newFrequency(name string, interval time.Duration) types.Frequency {
+	return types.Frequency{
+		Name:           name,
+		Interval:       interval,
+		PeriodsPerYear: int(math.Round(float64(year) / float64(interval))),
+		BarsPerDay:     float64(24*time.Hour) / float64(interval),
+	}
+}
@@ -0,0 +1,29 @@
+package timeseries
+
+import (
+	"btc-analyzer/internal/types"
+	"time"
+)
+
+// Gap is a stretch of missing bars between two consecutive bars of a series
+type Gap struct {
+	After   time.Time // last bar before the gap
+	Before  time.Time // first bar after the gap
+	Missing int       // number of bars missing
+}
+
+// Gaps returns the places where consecutive bars of a sorted series are
+// more than one interval apart
+func Gaps(bts *types.BTCTimeSeries, interval time.Duration) []Gap {
+	if interval <= 0 {
+		return nil
+	}
+	var gaps []Gap
+	for i := 1; i < len(bts.Data); i++ {
+		prev, next := bts.Data[i-1].Timestamp, bts.Data[i].Timestamp
+		if step := next.Sub(prev); step > interval {
+			gaps = append(gaps, Gap{After: prev, Before: next, Missing: int((step - 1) / interval)})
+		}
+	}
+	return gaps
+}
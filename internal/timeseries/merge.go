@@ -0,0 +1,137 @@
+package timeseries
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MergePolicy decides which bar is kept when both merged series have a bar
+// at the same time
+type MergePolicy string
+
+const (
+	PreferFirst     MergePolicy = "prefer-first"      // keep the bar of the first series
+	PreferLatest    MergePolicy = "prefer-latest"     // keep the bar of the series merged last
+	Average         MergePolicy = "average"           // average the two bars field by field
+	ErrorOnConflict MergePolicy = "error-on-conflict" // fail when the two bars differ
+)
+
+// MergePolicies lists the valid merge policies
+var MergePolicies = []MergePolicy{PreferFirst, PreferLatest, Average, ErrorOnConflict}
+
+// conflictTolerance is the relative difference below which two values of
+// the same bar are taken as equal, absorbing float formatting round trips
+const conflictTolerance = 1e-9
+
+// ParseMergePolicy returns the policy named s
+func ParseMergePolicy(s string) (MergePolicy, error) {
+	for _, policy := range MergePolicies {
+		if MergePolicy(s) == policy {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid merge policy %q: use prefer-first, prefer-latest, average or error-on-conflict", s)
+}
+
+// Merge combines two sorted series into one, resolving bars present in both
+// with policy. The timestamps are aligned first: a series of a finer
+// interval is resampled to the coarser one, and the bars of both are
+// snapped to the time grid of a, so that e.g. daily bars stamped at local
+// midnight line up with bars stamped at UTC midnight. Bars that snap to the
+// same time within one series keep the later one. The result takes the
+// symbol of a.
+func Merge(a, b *types.BTCTimeSeries, policy MergePolicy) (*types.BTCTimeSeries, error) {
+	if _, err := ParseMergePolicy(string(policy)); err != nil {
+		return nil, err
+	}
+	if len(a.Data) == 0 || len(b.Data) == 0 {
+		merged := Clone(a)
+		merged.Data = append(merged.Data, b.Data...)
+		return merged, nil
+	}
+
+	interval := DetectFrequency(a).Interval
+	if other := DetectFrequency(b).Interval; other > interval {
+		interval = other
+		a = Resample(a, interval)
+	} else if other < interval {
+		b = Resample(b, interval)
+	}
+	origin := a.Data[0].Timestamp
+	first, second := snap(a, origin, interval), snap(b, origin, interval)
+
+	merged := New(a.Symbol)
+	merged.Denomination = a.Denomination
+	merged.Data = make([]types.BTCPrice, 0, len(first)+len(second))
+	i, j := 0, 0
+	for i < len(first) || j < len(second) {
+		switch {
+		case j == len(second) || (i < len(first) && first[i].Timestamp.Before(second[j].Timestamp)):
+			merged.Data = append(merged.Data, first[i])
+			i++
+		case i == len(first) || second[j].Timestamp.Before(first[i].Timestamp):
+			merged.Data = append(merged.Data, second[j])
+			j++
+		default:
+			bar, err := resolve(first[i], second[j], policy)
+			if err != nil {
+				return nil, err
+			}
+			merged.Data = append(merged.Data, bar)
+			i++
+			j++
+		}
+	}
+	return merged, nil
+}
+
+// snap moves every bar of a sorted series to the nearest point of the grid
+// of interval through origin, keeping the later bar when two land on the
+// same point
+func snap(bts *types.BTCTimeSeries, origin time.Time, interval time.Duration) []types.BTCPrice {
+	bars := make([]types.BTCPrice, 0, len(bts.Data))
+	for _, bar := range bts.Data {
+		steps := math.Round(float64(bar.Timestamp.Sub(origin)) / float64(interval))
+		bar.Timestamp = origin.Add(time.Duration(steps) * interval)
+		if n := len(bars); n > 0 && bars[n-1].Timestamp.Equal(bar.Timestamp) {
+			bars[n-1] = bar
+			continue
+		}
+		bars = append(bars, bar)
+	}
+	return bars
+}
+
+// resolve returns the bar kept for two bars at the same time
+func resolve(first, second types.BTCPrice, policy MergePolicy) (types.BTCPrice, error) {
+	switch policy {
+	case PreferLatest:
+		return second, nil
+	case Average:
+		return types.BTCPrice{
+			Timestamp: first.Timestamp,
+			Open:      (first.Open + second.Open) / 2,
+			High:      (first.High + second.High) / 2,
+			Low:       (first.Low + second.Low) / 2,
+			Close:     (first.Close + second.Close) / 2,
+			Volume:    (first.Volume + second.Volume) / 2,
+		}, nil
+	case ErrorOnConflict:
+		fields := []struct {
+			name string
+			x, y float64
+		}{
+			{"open", first.Open, second.Open}, {"high", first.High, second.High}, {"low", first.Low, second.Low},
+			{"close", first.Close, second.Close}, {"volume", first.Volume, second.Volume},
+		}
+		for _, f := range fields {
+			if math.Abs(f.x-f.y) > conflictTolerance*math.Max(math.Abs(f.x), math.Abs(f.y)) {
+				return types.BTCPrice{}, fmt.Errorf("conflicting bars at %s: %s %g vs %g",
+					first.Timestamp.UTC().Format("2006-01-02 15:04"), f.name, f.x, f.y)
+			}
+		}
+	}
+	return first, nil
+}
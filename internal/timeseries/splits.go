@@ -0,0 +1,68 @@
+package timeseries
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Split is a share split of an instrument: from Date on, each old share is
+// Ratio new shares, e.g. 2 for a 2-for-1 split or 0.1 for a 1-for-10 reverse
+// split
+type Split struct {
+	Date  time.Time
+	Ratio float64
+}
+
+// ParseSplits parses comma-separated date:ratio pairs, e.g.
+// "2024-06-10:10,2025-01-02:0.5", sorted by date
+func ParseSplits(list string) ([]Split, error) {
+	var splits []Split
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		dateStr, ratioStr, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid split %q: use date:ratio, e.g. 2024-06-10:10", item)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid split date %q: %w", dateStr, err)
+		}
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(ratioStr), 64)
+		if err != nil || ratio <= 0 {
+			return nil, fmt.Errorf("invalid split ratio %q: must be a positive number", ratioStr)
+		}
+		splits = append(splits, Split{Date: date, Ratio: ratio})
+	}
+	sort.Slice(splits, func(i, j int) bool { return splits[i].Date.Before(splits[j].Date) })
+	return splits, nil
+}
+
+// AdjustForSplits returns a copy of bts with the bars before each split
+// back-adjusted to the share count after it: prices are divided by the
+// ratio and volumes multiplied by it, so the series has no jumps at splits
+func AdjustForSplits(bts *types.BTCTimeSeries, splits []Split) *types.BTCTimeSeries {
+	adjusted := Clone(bts)
+	for i, bar := range adjusted.Data {
+		factor := 1.0
+		for _, split := range splits {
+			if bar.Timestamp.Before(split.Date) {
+				factor *= split.Ratio
+			}
+		}
+		if factor == 1 {
+			continue
+		}
+		adjusted.Data[i].Open /= factor
+		adjusted.Data[i].High /= factor
+		adjusted.Data[i].Low /= factor
+		adjusted.Data[i].Close /= factor
+		adjusted.Data[i].Volume *= factor
+	}
+	return adjusted
+}
@@ -1,148 +1,318 @@
-package timeseries
-
-import (
-	"btc-analyzer/internal/types"
-	"sort"
-	"time"
-)
-
-// New creates a new Bitcoin time series
-func New(symbol string) *types.BTCTimeSeries {
-	return &types.BTCTimeSeries{
-		Symbol: symbol,
-		Data:   make([]types.BTCPrice, 0),
-	}
-}
-
-// AddPrice adds a price point to the series
-func AddPrice(bts *types.BTCTimeSeries, price types.BTCPrice) {
-	bts.Data = append(bts.Data, price)
-}
-
-// Sort sorts the data by timestamp
-func Sort(bts *types.BTCTimeSeries) {
-	sort.Slice(bts.Data, func(i, j int) bool {
-		return bts.Data[i].Timestamp.Before(bts.Data[j].Timestamp)
-	})
-}
-
-// GetClosePrices extracts closing prices for analysis
-func GetClosePrices(bts *types.BTCTimeSeries) []float64 {
-	prices := make([]float64, len(bts.Data))
-	for i, data := range bts.Data {
-		prices[i] = data.Close
-	}
-	return prices
-}
-
-// GetVolumeData extracts volume data
-func GetVolumeData(bts *types.BTCTimeSeries) []float64 {
-	volumes := make([]float64, len(bts.Data))
-	for i, data := range bts.Data {
-		volumes[i] = data.Volume
-	}
-	return volumes
-}
-
-// GetTimeRange returns the time range of the data
-func GetTimeRange(bts *types.BTCTimeSeries) (time.Time, time.Time) {
-	if len(bts.Data) == 0 {
-		return time.Time{}, time.Time{}
-	}
-	Sort(bts)
-	return bts.Data[0].Timestamp, bts.Data[len(bts.Data)-1].Timestamp
-}
-
-// GetLatestPrice returns the most recent price data
-func GetLatestPrice(bts *types.BTCTimeSeries) types.BTCPrice {
-	if len(bts.Data) == 0 {
-		return types.BTCPrice{}
-	}
-	Sort(bts)
-	return bts.Data[len(bts.Data)-1]
-}
-
-// FilterByDateRange filters data within a specific date range
-func FilterByDateRange(bts *types.BTCTimeSeries, start, end time.Time) *types.BTCTimeSeries {
-	filtered := New(bts.Symbol + "_filtered")
-	
-	for _, price := range bts.Data {
-		if (price.Timestamp.Equal(start) || price.Timestamp.After(start)) &&
-		   (price.Timestamp.Equal(end) || price.Timestamp.Before(end)) {
-			AddPrice(filtered, price)
-		}
-	}
-	
-	return filtered
-}
-
-// ResampleToDaily resamples data to daily intervals
-func ResampleToDaily(bts *types.BTCTimeSeries) *types.BTCTimeSeries {
-	if len(bts.Data) == 0 {
-		return New(bts.Symbol + "_daily")
-	}
-
-	Sort(bts)
-	resampled := New(bts.Symbol + "_daily")
-	
-	currentDay := bts.Data[0].Timestamp.Truncate(24 * time.Hour)
-	var dayData []types.BTCPrice
-	
-	for _, price := range bts.Data {
-		priceDay := price.Timestamp.Truncate(24 * time.Hour)
-		
-		if priceDay.Equal(currentDay) {
-			dayData = append(dayData, price)
-		} else {
-			// Process accumulated day data
-			if len(dayData) > 0 {
-				dailyPrice := aggregateDayData(dayData, currentDay)
-				AddPrice(resampled, dailyPrice)
-			}
-			
-			// Start new day
-			currentDay = priceDay
-			dayData = []types.BTCPrice{price}
-		}
-	}
-	
-	// Process last day
-	if len(dayData) > 0 {
-		dailyPrice := aggregateDayData(dayData, currentDay)
-		AddPrice(resampled, dailyPrice)
-	}
-	
-	return resampled
-}
-
-// aggregateDayData aggregates multiple price points into a single daily OHLCV
-func aggregateDayData(dayData []types.BTCPrice, day time.Time) types.BTCPrice {
-	if len(dayData) == 0 {
-		return types.BTCPrice{}
-	}
-	
-	open := dayData[0].Open
-	close := dayData[len(dayData)-1].Close
-	high := dayData[0].High
-	low := dayData[0].Low
-	volume := 0.0
-	
-	for _, price := range dayData {
-		if price.High > high {
-			high = price.High
-		}
-		if price.Low < low {
-			low = price.Low
-		}
-		volume += price.Volume
-	}
-	
-	return types.BTCPrice{
-		Timestamp: day,
-		Open:      open,
-		High:      high,
-		Low:       low,
-		Close:     close,
-		Volume:    volume,
-	}
-}
\ No newline at end of file
+package timeseries
+
+import (
+	"btc-analyzer/internal/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+// New creates a new Bitcoin time series
+func New(symbol string) *types.BTCTimeSeries {
+	return &types.BTCTimeSeries{
+		Symbol: symbol,
+		Data:   make([]types.BTCPrice, 0),
+	}
+}
+
+// AddPrice adds a price point to the series, keeping it sorted by timestamp.
+// Points with an equal timestamp are kept in insertion order.
+func AddPrice(bts *types.BTCTimeSeries, price types.BTCPrice) {
+	n := len(bts.Data)
+	if n == 0 || !price.Timestamp.Before(bts.Data[n-1].Timestamp) {
+		bts.Data = append(bts.Data, price)
+		return
+	}
+
+	idx := searchAfter(bts, price.Timestamp)
+	bts.Data = append(bts.Data, types.BTCPrice{})
+	copy(bts.Data[idx+1:], bts.Data[idx:])
+	bts.Data[idx] = price
+}
+
+// Sort restores the timestamp order of a series whose Data was filled
+// directly (e.g. decoded from JSON) instead of through AddPrice
+func Sort(bts *types.BTCTimeSeries) {
+	sort.SliceStable(bts.Data, func(i, j int) bool {
+		return bts.Data[i].Timestamp.Before(bts.Data[j].Timestamp)
+	})
+}
+
+// Clone returns a copy of the series that shares no memory with the original
+func Clone(bts *types.BTCTimeSeries) *types.BTCTimeSeries {
+	data := make([]types.BTCPrice, len(bts.Data))
+	copy(data, bts.Data)
+	return &types.BTCTimeSeries{Symbol: bts.Symbol, Denomination: bts.Denomination, Data: data}
+}
+
+// GetClosePrices extracts closing prices for analysis
+func GetClosePrices(bts *types.BTCTimeSeries) []float64 {
+	prices := make([]float64, len(bts.Data))
+	for i, data := range bts.Data {
+		prices[i] = data.Close
+	}
+	return prices
+}
+
+// GetVolumeData extracts volume data
+func GetVolumeData(bts *types.BTCTimeSeries) []float64 {
+	volumes := make([]float64, len(bts.Data))
+	for i, data := range bts.Data {
+		volumes[i] = data.Volume
+	}
+	return volumes
+}
+
+// GetTimeRange returns the time range of the data
+func GetTimeRange(bts *types.BTCTimeSeries) (time.Time, time.Time) {
+	if len(bts.Data) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return bts.Data[0].Timestamp, bts.Data[len(bts.Data)-1].Timestamp
+}
+
+// GetLatestPrice returns the most recent price data
+func GetLatestPrice(bts *types.BTCTimeSeries) types.BTCPrice {
+	if len(bts.Data) == 0 {
+		return types.BTCPrice{}
+	}
+	return bts.Data[len(bts.Data)-1]
+}
+
+// FilterByDateRange filters data within a specific date range
+func FilterByDateRange(bts *types.BTCTimeSeries, start, end time.Time) *types.BTCTimeSeries {
+	filtered := New(bts.Symbol + "_filtered")
+	filtered.Denomination = bts.Denomination
+	filtered.Data = append(filtered.Data, Between(bts, start, end)...)
+	return filtered
+}
+
+// At returns the price point in effect at timestamp: the latest point at or
+// before it. The boolean is false when timestamp precedes the series.
+func At(bts *types.BTCTimeSeries, timestamp time.Time) (types.BTCPrice, bool) {
+	idx := searchAfter(bts, timestamp) - 1
+	if idx < 0 {
+		return types.BTCPrice{}, false
+	}
+	return bts.Data[idx], true
+}
+
+// Between returns the points with start <= timestamp <= end. The result is a
+// view into the series and must not be modified.
+func Between(bts *types.BTCTimeSeries, start, end time.Time) []types.BTCPrice {
+	if end.Before(start) {
+		return nil
+	}
+	lo := sort.Search(len(bts.Data), func(i int) bool {
+		return !bts.Data[i].Timestamp.Before(start)
+	})
+	hi := searchAfter(bts, end)
+	return bts.Data[lo:hi:hi]
+}
+
+// LastN returns the most recent n points as a view into the series
+func LastN(bts *types.BTCTimeSeries, n int) []types.BTCPrice {
+	if n <= 0 {
+		return nil
+	}
+	if n > len(bts.Data) {
+		n = len(bts.Data)
+	}
+	return bts.Data[len(bts.Data)-n:]
+}
+
+// ChangeOver returns the change of the latest close since the last point at
+// least d before it, or nil when the series is shorter than d
+func ChangeOver(bts *types.BTCTimeSeries, d time.Duration) *float64 {
+	if len(bts.Data) == 0 {
+		return nil
+	}
+	latest := bts.Data[len(bts.Data)-1]
+	bar, ok := At(bts, latest.Timestamp.Add(-d))
+	if !ok || bar.Close <= 0 {
+		return nil
+	}
+	change := latest.Close/bar.Close - 1
+	return &change
+}
+
+// searchAfter returns the index of the first point after timestamp
+func searchAfter(bts *types.BTCTimeSeries, timestamp time.Time) int {
+	return sort.Search(len(bts.Data), func(i int) bool {
+		return bts.Data[i].Timestamp.After(timestamp)
+	})
+}
+
+// ResampleToDaily resamples data to daily intervals
+func ResampleToDaily(bts *types.BTCTimeSeries) *types.BTCTimeSeries {
+	resampled := Resample(bts, 24*time.Hour)
+	resampled.Symbol = bts.Symbol + "_daily"
+	return resampled
+}
+
+// Resample aggregates sorted data into OHLCV bars of the given interval,
+// each stamped with the start of its interval
+func Resample(bts *types.BTCTimeSeries, interval time.Duration) *types.BTCTimeSeries {
+	resampled := New(bts.Symbol)
+	resampled.Denomination = bts.Denomination
+	if len(bts.Data) == 0 {
+		return resampled
+	}
+	
+	currentBucket := bts.Data[0].Timestamp.Truncate(interval)
+	var bucketData []types.BTCPrice
+	
+	for _, price := range bts.Data {
+		priceBucket := price.Timestamp.Truncate(interval)
+		
+		if priceBucket.Equal(currentBucket) {
+			bucketData = append(bucketData, price)
+		} else {
+			// Process accumulated bucket data
+			if len(bucketData) > 0 {
+				AddPrice(resampled, aggregateDayData(bucketData, currentBucket))
+			}
+			
+			// Start new bucket
+			currentBucket = priceBucket
+			bucketData = []types.BTCPrice{price}
+		}
+	}
+	
+	// Process last bucket
+	if len(bucketData) > 0 {
+		AddPrice(resampled, aggregateDayData(bucketData, currentBucket))
+	}
+	
+	return resampled
+}
+
+// aggregateDayData aggregates multiple price points into a single OHLCV bar
+func aggregateDayData(dayData []types.BTCPrice, day time.Time) types.BTCPrice {
+	if len(dayData) == 0 {
+		return types.BTCPrice{}
+	}
+	
+	open := dayData[0].Open
+	close := dayData[len(dayData)-1].Close
+	high := dayData[0].High
+	low := dayData[0].Low
+	volume := 0.0
+	
+	for _, price := range dayData {
+		if price.High > high {
+			high = price.High
+		}
+		if price.Low < low {
+			low = price.Low
+		}
+		volume += price.Volume
+	}
+	
+	return types.BTCPrice{
+		Timestamp: day,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+	}
+}
+
+// AlignAux pairs each auxiliary observation with the latest close at or before
+// its timestamp. Observations before the first price are dropped.
+func AlignAux(bts *types.BTCTimeSeries, aux *types.AuxSeries) (prices []float64, values []float64) {
+	if aux == nil || len(bts.Data) == 0 {
+		return nil, nil
+	}
+
+	j := -1
+	for _, point := range aux.Points {
+		for j+1 < len(bts.Data) && !bts.Data[j+1].Timestamp.After(point.Timestamp) {
+			j++
+		}
+		if j < 0 {
+			continue
+		}
+		prices = append(prices, bts.Data[j].Close)
+		values = append(values, point.Value)
+	}
+
+	return prices, values
+}
+
+// SafeSeries guards a time series for concurrent use. Writers take the write
+// lock; readers get copies so they never observe a series mid-update.
+type SafeSeries struct {
+	mu     sync.RWMutex
+	series *types.BTCTimeSeries
+}
+
+// NewSafe creates an empty concurrency-safe series
+func NewSafe(symbol string) *SafeSeries {
+	return &SafeSeries{series: New(symbol)}
+}
+
+// WrapSafe wraps an existing series. The caller must not modify bts afterwards.
+func WrapSafe(bts *types.BTCTimeSeries) *SafeSeries {
+	return &SafeSeries{series: bts}
+}
+
+// Add inserts a price point in timestamp order
+func (s *SafeSeries) Add(price types.BTCPrice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	AddPrice(s.series, price)
+}
+
+// Replace swaps the underlying series for a new one
+func (s *SafeSeries) Replace(bts *types.BTCTimeSeries) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = bts
+}
+
+// Symbol returns the symbol of the series
+func (s *SafeSeries) Symbol() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.series.Symbol
+}
+
+// Len returns the number of price points
+func (s *SafeSeries) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.series.Data)
+}
+
+// Latest returns the most recent price point
+func (s *SafeSeries) Latest() types.BTCPrice {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return GetLatestPrice(s.series)
+}
+
+// TimeRange returns the first and last timestamps
+func (s *SafeSeries) TimeRange() (time.Time, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return GetTimeRange(s.series)
+}
+
+// Between returns a copy of the points with start <= timestamp <= end
+func (s *SafeSeries) Between(start, end time.Time) []types.BTCPrice {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	view := Between(s.series, start, end)
+	points := make([]types.BTCPrice, len(view))
+	copy(points, view)
+	return points
+}
+
+// Snapshot returns a private copy of the series for analysis
+func (s *SafeSeries) Snapshot() *types.BTCTimeSeries {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Clone(s.series)
+}
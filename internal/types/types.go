@@ -1,82 +1,1256 @@
-package types
-
-import "time"
-
-// BTCPrice represents Bitcoin price data with OHLCV format
-type BTCPrice struct {
-	Timestamp time.Time
-	Open      float64
-	High      float64
-	Low       float64
-	Close     float64
-	Volume    float64
-}
-
-// BTCTimeSeries represents Bitcoin time series data
-type BTCTimeSeries struct {
-	Symbol string
-	Data   []BTCPrice
-}
-
-// Statistics represents basic statistical measures
-type Statistics struct {
-	Count    int
-	Mean     float64
-	Median   float64
-	StdDev   float64
-	Min      float64
-	Max      float64
-	Variance float64
-	Skewness float64
-	Kurtosis float64
-}
-
-// MACDData holds MACD indicator values
-type MACDData struct {
-	MACD      []float64
-	Signal    []float64
-	Histogram []float64
-}
-
-// BollingerBandsData holds Bollinger Bands values
-type BollingerBandsData struct {
-	Upper  []float64
-	Middle []float64
-	Lower  []float64
-}
-
-// SupportResistanceData holds support and resistance levels
-type SupportResistanceData struct {
-	SupportLevels    []float64
-	ResistanceLevels []float64
-}
-
-// BTCAnalytics holds comprehensive Bitcoin market analytics
-type BTCAnalytics struct {
-	PriceStats        Statistics
-	VolumeStats       Statistics
-	Volatility        float64
-	SharpeRatio       float64
-	MaxDrawdown       float64
-	Returns           []float64
-	LogReturns        []float64
-	RSI               []float64
-	MACD              MACDData
-	BollingerBands    BollingerBandsData
-	SupportResistance SupportResistanceData
-}
-
-// PriceAlert represents a price alert condition
-type PriceAlert struct {
-	Type      string // "above", "below", "change"
-	Threshold float64
-	Triggered bool
-	Timestamp time.Time
-}
-
-// CoinGeckoResponse represents API response from CoinGecko
-type CoinGeckoResponse struct {
-	Prices       [][]float64 `json:"prices"`
-	MarketCaps   [][]float64 `json:"market_caps"`
-	TotalVolumes [][]float64 `json:"total_volumes"`
-}
\ No newline at end of file
+package types
+
+import "time"
+
+// BTCPrice represents Bitcoin price data with OHLCV format
+type BTCPrice struct {
+	Timestamp time.Time
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+}
+
+// BTCTimeSeries represents Bitcoin time series data
+type BTCTimeSeries struct {
+	Symbol       string
+	Denomination *Denomination `json:",omitempty"` // nil for fiat per BTC, as loaded
+	Data         []BTCPrice
+}
+
+// Denomination is the unit the prices of a series are expressed in
+type Denomination struct {
+	Kind string  `json:"kind"` // "fiat", "sats" or "btc"
+	Unit float64 `json:"unit"` // BTC priced for fiat, fiat spent for sats and btc
+}
+
+// Statistics represents basic statistical measures
+type Statistics struct {
+	Count    int
+	Mean     float64
+	Median   float64
+	StdDev   float64
+	Min      float64
+	Max      float64
+	Variance float64
+	Skewness float64
+	Kurtosis float64
+}
+
+// MACDData holds MACD indicator values
+type MACDData struct {
+	MACD      []float64
+	Signal    []float64
+	Histogram []float64
+}
+
+// BollingerBandsData holds Bollinger Bands values
+type BollingerBandsData struct {
+	Upper  []float64
+	Middle []float64
+	Lower  []float64
+}
+
+// SupportResistanceData holds support and resistance levels
+type SupportResistanceData struct {
+	SupportLevels    []float64
+	ResistanceLevels []float64
+	Levels           []SRLevel `json:",omitempty"`
+}
+
+// SRLevel holds a support or resistance level with its strength score and
+// break/retest history
+type SRLevel struct {
+	Price       float64
+	Type        string // "support" or "resistance", as originally identified, or "round" for round numbers
+	Source      string // what produced the level, e.g. "swing", "SMA200", "prev_week_high"; merged levels list all
+	Touches     int    // separate visits to the level
+	LastTouch   time.Time
+	TouchVolume float64 // average volume of touching bars relative to the series average
+	Score       float64 // 0..1 combining touches, recency and volume
+	Broken      bool
+	BrokenAt    time.Time
+	Retested    bool // price came back to the level after the break and was rejected
+	RetestedAt  time.Time
+}
+
+// BTCAnalytics holds comprehensive Bitcoin market analytics
+type BTCAnalytics struct {
+	PriceStats        Statistics
+	VolumeStats       Statistics
+	Volatility        float64
+	RealizedVol       *RealizedVolatility `json:",omitempty"`
+	TailRisk          *TailRisk           `json:",omitempty"`
+	Confidence        *BootstrapIntervals `json:",omitempty"`
+	Stationarity      *Stationarity       `json:",omitempty"`
+	MeanReversion     *OUFit              `json:",omitempty"`
+	Attribution       *ReturnAttribution  `json:",omitempty"`
+	SharpeRatio       float64
+	MaxDrawdown       float64
+	Returns           []float64
+	LogReturns        []float64
+	Performance       []HorizonReturn `json:",omitempty"`
+	RSI               []float64
+	ROC               []float64 `json:",omitempty"` // 12-bar rate of change from bar 12 on
+	MACD              MACDData
+	BollingerBands    BollingerBandsData
+	SupportResistance SupportResistanceData
+	SearchInterest    *LeadLagAnalysis    `json:",omitempty"`
+	Dominance         *LeadLagAnalysis    `json:",omitempty"`
+	Stablecoins       *LeadLagAnalysis    `json:",omitempty"`
+	ReturnDrivers     []LeadLagAnalysis   `json:",omitempty"`
+	OrderBook         *OrderBookMetrics   `json:",omitempty"`
+	OrderFlow         *OrderFlowData      `json:",omitempty"`
+	ElliottWaves      *ElliottAnalysis    `json:",omitempty"`
+	Harmonics         []HarmonicPattern   `json:",omitempty"`
+	Wyckoff           *WyckoffAnalysis    `json:",omitempty"`
+	MarketStructure   *MarketStructure    `json:",omitempty"`
+	PatternOutcomes   []PatternOutcome    `json:",omitempty"`
+	LevelMap          []SRLevel           `json:",omitempty"`
+	LiquidityZones    []LiquidityZone     `json:",omitempty"`
+	SessionGaps       *SessionGapStats    `json:",omitempty"`
+	VolumeForensics   *VolumeForensics    `json:",omitempty"`
+	Liquidity         *LiquidityEstimates `json:",omitempty"`
+	Percentiles       []MetricPercentile  `json:",omitempty"`
+	MarketHeat        *HeatIndex          `json:",omitempty"`
+	Valuation         *LongTermValuation  `json:",omitempty"`
+	StockToFlow       *StockToFlowModel   `json:",omitempty"`
+	NVT               *NVTAnalysis        `json:",omitempty"`
+	RealPrices        *RealPriceAnalysis  `json:",omitempty"`
+	AllTime           *AllTimeExtremes    `json:",omitempty"`
+	Premium           *PremiumAnalysis    `json:",omitempty"`
+	Beta              *BetaAnalysis       `json:",omitempty"`
+	Pair              *PairAnalysis       `json:",omitempty"`
+	ImpliedVol        *VolatilityAnalysis `json:",omitempty"`
+	Custom            []CustomIndicator   `json:",omitempty"`
+	Skipped           []SkippedAnalysis   `json:",omitempty"`
+	RiskConvention    RiskConfig
+}
+
+// HorizonReturn is the change of the latest close over one horizon of the
+// performance table, e.g. 7d or YTD. Available is false when the series does
+// not reach back to the start of the horizon.
+type HorizonReturn struct {
+	Horizon    string
+	Since      time.Time // timestamp of the close the return is measured from
+	StartPrice float64
+	Return     float64 // fraction, e.g. 0.05 for +5%
+	Available  bool
+}
+
+// SkippedAnalysis is an analysis left out because the series is too short
+type SkippedAnalysis struct {
+	Analysis  string
+	Required  int // bars the analysis needs
+	Available int // bars in the series
+}
+
+// CustomIndicator is the output of a plugin indicator. Values start at bar
+// Offset; earlier bars are the indicator's warm-up.
+type CustomIndicator struct {
+	Name   string
+	Source string // formula of a script indicator, "go" for a compiled-in one
+	Offset int
+	Values []float64
+	Buys   []int  `json:",omitempty"` // bars where the buy rule became true
+	Sells  []int  `json:",omitempty"` // bars where the sell rule became true
+	Signal string `json:",omitempty"`
+}
+
+// AnalysisResult is the outcome of an analysis: the analyzed series, its
+// analytics and trading signals, and how they were produced. It is the one
+// payload reporters, the APIs and stores work with.
+type AnalysisResult struct {
+	Metadata         ResultMetadata    `json:"metadata"`
+	Series           *BTCTimeSeries    `json:"-"` // reports carry the data separately
+	Analytics        BTCAnalytics      `json:"analytics"`
+	Signals          map[string]string `json:"trading_signals"`
+	SignalScore      float64           `json:"signal_score"`      // -1 (all SELL) to +1 (all BUY)
+	SignalThresholds SignalConfig      `json:"signal_thresholds"` // thresholds the signals were generated with
+}
+
+// ResultMetadata describes the data and the run behind an AnalysisResult
+type ResultMetadata struct {
+	Symbol         string          `json:"symbol"`
+	DataPoints     int             `json:"data_points"`
+	Start          time.Time       `json:"start"`
+	End            time.Time       `json:"end"`
+	LatestPrice    float64         `json:"latest_price,omitempty"`
+	LatestVolume   float64         `json:"latest_volume,omitempty"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+	ComputeSeconds float64         `json:"compute_seconds"`
+	Version        string          `json:"version"`
+	InputHash      string          `json:"input_hash,omitempty"` // hash of the inputs and parameters, also the result cache key
+	Parameters     *AnalysisParams `json:"parameters,omitempty"`
+	Cached         bool            `json:"cached,omitempty"`      // loaded from the result cache rather than computed
+	DataSource     string          `json:"data_source,omitempty"` // source that served the price series, which differs from the configured one after failover
+	Warnings       []string        `json:"warnings,omitempty"`
+	Errors         []string        `json:"errors,omitempty"` // stages that failed, whose outputs are missing or incomplete
+	Warmup         *WarmupInfo     `json:"warmup,omitempty"` // how the technical indicators were warmed up
+}
+
+// WarmupInfo records how the technical indicators of a result were warmed
+// up: on bars before the reported period, on its first bars, which were
+// left out of it, or not at all
+type WarmupInfo struct {
+	Policy    string `json:"policy"`              // fetch, truncate or none
+	Required  int    `json:"required"`            // bars before the first value of every indicator
+	Bars      int    `json:"bars"`                // bars before the reported period the indicators were computed from
+	Truncated int    `json:"truncated,omitempty"` // loaded bars left out of the start of the period
+}
+
+// AnalysisParams are the options an analysis ran with
+type AnalysisParams struct {
+	Source          string       `json:"source"`
+	Risk            RiskConfig   `json:"risk"`
+	MaxLag          int          `json:"max_lag"`
+	LargeTradeQty   float64      `json:"large_trade_qty,omitempty"`
+	Reference       string       `json:"reference,omitempty"`
+	PremiumWindow   int          `json:"premium_window,omitempty"`
+	PremiumZ        float64      `json:"premium_z,omitempty"`
+	Benchmark       string       `json:"benchmark,omitempty"`
+	BetaWindow      int          `json:"beta_window,omitempty"`
+	Pair            string       `json:"pair,omitempty"`
+	Pairs           *PairConfig  `json:"pairs,omitempty"`            // set with Pair
+	Adjust          string       `json:"adjust,omitempty"`           // split adjustments of the price series
+	BenchmarkAdjust string       `json:"benchmark_adjust,omitempty"` // split adjustments of the benchmark
+	CPI             string       `json:"cpi,omitempty"`              // price index of the real prices
+	ATH             string       `json:"ath,omitempty"`              // historical all-time high
+	HeatWeights     string       `json:"heat_weights,omitempty"`
+	Indicators      []string     `json:"indicators,omitempty"` // custom indicators requested, empty for all
+	Signals         SignalConfig `json:"signals"`
+}
+
+// SignalConfig holds the thresholds of the trading signals. Zero fields take
+// the defaults of analyzer.DefaultSignalConfig.
+type SignalConfig struct {
+	RSIOverbought        float64 `json:"rsi_overbought"`         // RSI above which the RSI signal sells
+	RSIOversold          float64 `json:"rsi_oversold"`           // RSI below which the RSI signal buys
+	TrendThreshold       float64 `json:"trend_threshold"`        // smallest swing of the market structure behind the trend signal, e.g. 0.05 for 5%
+	LevelProximity       float64 `json:"level_proximity"`        // distance to a support or resistance level that signals it, as a fraction of its price
+	RoundNumberProximity float64 `json:"round_number_proximity"` // distance to a round number that signals it, as a fraction of the price
+}
+
+// ReportConfig selects and orders the sections of the analysis reports.
+// Zero fields take the defaults of analyzer.ReportSections.
+type ReportConfig struct {
+	Sections  []string `json:"sections,omitempty"`   // sections shown, in order (default all, in the default order)
+	Hide      []string `json:"hide,omitempty"`       // sections left out
+	TableRows int      `json:"table_rows,omitempty"` // latest records in each data table (default 20)
+}
+
+// PrecisionConfig sets the decimals money and percentages are shown with,
+// per magnitude. Unset kinds take the steps of numfmt.DefaultPrecision.
+type PrecisionConfig struct {
+	USD     []PrecisionStep `json:"usd,omitempty"`     // fiat amounts, e.g. prices per BTC
+	Sats    []PrecisionStep `json:"sats,omitempty"`    // amounts in sats
+	BTC     []PrecisionStep `json:"btc,omitempty"`     // amounts in BTC
+	Percent []PrecisionStep `json:"percent,omitempty"` // percentages, by the magnitude of the percentage shown
+}
+
+// PrecisionStep sets the precision of the values from From up to the From
+// of the next larger step: Decimals decimals or, when set, Significant
+// significant digits
+type PrecisionStep struct {
+	From        float64 `json:"from"`
+	Decimals    int     `json:"decimals,omitempty"`
+	Significant int     `json:"significant,omitempty"`
+}
+
+// SignalStateConfig sets how the stateful signal layer of the server damps
+// flapping signals. Zero fields take the defaults of signalstate.Defaults.
+type SignalStateConfig struct {
+	RSIHysteresis  float64 `json:"rsi_hysteresis"`  // RSI points past its threshold an RSI signal must retreat to end
+	BandHysteresis float64 `json:"band_hysteresis"` // fraction of the price back inside a Bollinger Band a band signal must retreat to end
+	MinHold        string  `json:"min_hold"`        // shortest time a BUY or SELL is held once entered, e.g. "2h"
+	Cooldown       string  `json:"cooldown"`        // time after a BUY or SELL ends before the same signal may enter it again
+}
+
+// SignalState is the stabilized state of one trading signal of a series
+type SignalState struct {
+	Action       string    `json:"action"` // "BUY", "SELL" or "HOLD"
+	Signal       string    `json:"signal"` // signal reported while in the action
+	Since        time.Time `json:"since"`  // bar time the action was entered
+	ExitedAction string    `json:"exited_action,omitempty"`
+	ExitedAt     time.Time `json:"exited_at,omitempty"` // bar time the last BUY or SELL ended
+}
+
+// Frequency describes the native bar interval of a series
+type Frequency struct {
+	Name           string // "minute", "hourly", "daily", ... or "irregular"
+	Interval       time.Duration
+	PeriodsPerYear int
+	BarsPerDay     float64
+}
+
+// RiskConfig holds the conventions used to annualize returns and compute
+// risk-adjusted ratios. Rates are annual fractions, e.g. 0.045 for 4.5%.
+type RiskConfig struct {
+	RiskFreeRate   float64
+	FundingRate    float64 // annual cost of carrying the position, charged on top of the risk-free rate
+	PeriodsPerYear int     // bars per year; 0 detects it from the data
+	Compounding    string  // "simple" (mean x periods) or "geometric" (compounded)
+	VolEstimator   string  // volatility estimator behind Volatility and the ratios, "close" (close-to-close) by default
+}
+
+// RealizedVolatility holds the annualized volatility of a series under each
+// estimator, and the one the analysis uses
+type RealizedVolatility struct {
+	CloseToClose   float64
+	Parkinson      float64 // high-low range
+	GarmanKlass    float64 // range and open-to-close
+	RogersSatchell float64 // range, unbiased under drift
+	YangZhang      float64 // opening gaps, open-to-close and Rogers-Satchell
+	HasRanges      bool    // false for close-only data, which leaves the range estimators at 0
+	Estimator      string  // estimator in use; close-to-close when the series has no ranges
+	Selected       float64
+}
+
+// TailRisk holds loss measures that look past the mean and standard
+// deviation of returns. VaRs are per-bar returns at 95% confidence, so
+// losses are negative.
+type TailRisk struct {
+	MaxLossStreak       int     // longest run of consecutive losing bars
+	MaxLossStreakDays   float64 // the streak in days at the series' frequency
+	LossStreakReturn    float64 // compounded return over the streak
+	LossStreakStart     time.Time
+	LossStreakEnd       time.Time
+	CDaR95              float64 // conditional drawdown at risk: mean of the worst 5% of drawdowns
+	VaR95               float64 // normal VaR
+	CornishFisherVaR95  float64 // VaR adjusted for skewness and excess kurtosis
+	Skewness            float64
+	ExcessKurtosis      float64
+	SharpeRatio         float64
+	AdjustedSharpeRatio float64 // Sharpe penalized for negative skew and fat tails (Pezier-White)
+}
+
+// ConfidenceInterval is a point estimate with the bounds of its confidence
+// interval
+type ConfidenceInterval struct {
+	Estimate float64
+	Lower    float64
+	Upper    float64
+}
+
+// BootstrapIntervals holds confidence intervals of the headline return and
+// risk figures, from resampling the returns in blocks so volatility
+// clustering survives. Returns and volatility are annualized fractions.
+type BootstrapIntervals struct {
+	Level       float64 // confidence level, e.g. 0.95
+	Resamples   int
+	BlockSize   int // consecutive returns per resampled block
+	Bars        int // returns resampled
+	MeanReturn  ConfidenceInterval
+	SharpeRatio ConfidenceInterval
+	Volatility  ConfidenceInterval // of the volatility estimator in use
+}
+
+// StationarityTest is the outcome of one unit-root or stationarity test.
+// ADF's null hypothesis is a unit root and KPSS's is stationarity, so
+// Stationary means ADF rejected its null or KPSS did not, at 5%.
+type StationarityTest struct {
+	Test         string // "ADF" or "KPSS"
+	Statistic    float64
+	Critical1    float64 // critical values at 1%, 5% and 10%
+	Critical5    float64
+	Critical10   float64
+	Lags         int // lagged differences for ADF, autocovariance lags for KPSS
+	Observations int
+	Stationary   bool
+}
+
+// StationarityCheck holds both tests of one series with their joint verdict
+type StationarityCheck struct {
+	Series  string // "Log prices" or "Log returns"
+	ADF     StationarityTest
+	KPSS    StationarityTest
+	Verdict string // "stationary", "unit root" or "inconclusive"
+}
+
+// Stationarity holds the tests of the log prices and log returns of a series
+type Stationarity struct {
+	Prices  StationarityCheck
+	Returns StationarityCheck
+}
+
+// OUFit is an Ornstein-Uhlenbeck process fitted to a series, such as log
+// prices or a spread, through the AR(1) regression of each value on the
+// previous one. Rates and volatility are per bar.
+type OUFit struct {
+	Series        string
+	Bars          int
+	Phi           float64 // AR(1) coefficient
+	Theta         float64 // speed of mean reversion, -ln Phi
+	Mean          float64 // long-run level of the series
+	Sigma         float64 // volatility of the process
+	HalfLifeBars  float64 // bars for a deviation from Mean to halve, 0 when not mean reverting
+	HalfLifeDays  float64
+	TStat         float64 // Dickey-Fuller statistic of Phi - 1
+	MeanReverting bool    // 0 < Phi < 1
+	Significant   bool    // TStat below its 5% critical value, so the reversion is unlikely to be chance
+	Lookbacks     []int   // suggested lookback windows of mean-reversion rules, in bars
+}
+
+// AttributionBucket sums the log returns of the bars in one part of the day
+// or week, or one part of each bar
+type AttributionBucket struct {
+	Label       string
+	Bars        int
+	MeanReturn  float64 // mean log return per bar
+	TotalReturn float64 // compounded return of all its bars
+	Share       float64 // of the summed log returns of the series
+	WinRate     float64 // share of its bars with a positive return
+	TStat       float64 // of MeanReturn against zero
+}
+
+// ReturnAttribution decomposes the log returns of a series by where they
+// accrue: across the session breaks and within sessions, by hour of the day
+// and by day of the week, in UTC
+type ReturnAttribution struct {
+	Bars                    int
+	TotalReturn             float64
+	Overnight               *AttributionBucket  `json:",omitempty"` // previous close to open, nil when opens equal the previous closes
+	Intraday                *AttributionBucket  `json:",omitempty"` // open to close
+	Hours                   []AttributionBucket `json:",omitempty"` // by hour of the bar's open, for bars of an hour or less
+	Weekdays                []AttributionBucket `json:",omitempty"` // Monday first, for bars of a day or less
+	CheapestHour            string              `json:",omitempty"` // hour at which the average day's detrended price is lowest
+	CheapestHourDiscount    float64             // of that price against the day's average
+	CheapestWeekday         string              `json:",omitempty"`
+	CheapestWeekdayDiscount float64
+}
+
+// LiquidityEstimates holds bid-ask spread and price impact estimated from
+// OHLCV bars alone, over the whole series and its last Window bars. Spreads
+// are fractions of the price.
+type LiquidityEstimates struct {
+	Window              int
+	CorwinSchultz       float64 // from the high-low ranges of consecutive bars
+	AbdiRanaldo         float64 // from closes against high-low midpoints
+	Amihud              float64 // mean absolute return per million units of volume
+	RecentCorwinSchultz float64
+	RecentAbdiRanaldo   float64
+	RecentAmihud        float64
+	HasRanges           bool // false for close-only data, which leaves the spreads at 0
+}
+
+// MetricPercentile places the latest value of a headline metric within its
+// own trailing history
+type MetricPercentile struct {
+	Metric     string
+	Value      float64
+	Percentile float64 // 0-100, share of the history at or below Value
+	Samples    int     // values in the history, at most a year of bars
+	Label      string  // e.g. "unusually quiet"
+}
+
+// HeatIndex is a weighted average of standardized market metrics: high
+// readings mark euphoric, overheated markets and low readings cold ones
+type HeatIndex struct {
+	Components []HeatComponent
+	Points     []HeatPoint
+	Latest     float64
+	Percentile float64 // of Latest within the index history
+	LowerBand  float64 // 5th percentile of the index history
+	UpperBand  float64 // 95th percentile of the index history
+	Zone       string  // "overheated", "cold" or "neutral"
+}
+
+// HeatComponent is one metric of the heat index
+type HeatComponent struct {
+	Name   string
+	Weight float64
+	Latest float64 // latest z-score
+}
+
+// HeatPoint is the heat index at one bar
+type HeatPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// LongTermValuation holds the BTC valuation heuristics computed on daily
+// closes, and those the history is too short for
+type LongTermValuation struct {
+	Heuristics  []ValuationHeuristic
+	Unavailable []string // e.g. "Pi-Cycle Top: needs 350 days, have 365"
+}
+
+// ValuationHeuristic is the latest reading of a valuation heuristic and the
+// days it triggered
+type ValuationHeuristic struct {
+	Name     string
+	Ratio    float64 // e.g. price over the 200-day moving average
+	Level    float64 // price at which Ratio is 1
+	Triggers []ValuationTrigger
+}
+
+// ValuationTrigger is a day a valuation heuristic crossed its threshold
+type ValuationTrigger struct {
+	Timestamp   time.Time
+	Price       float64
+	Description string
+}
+
+// StockToFlowModel compares the price with the stock-to-flow reference
+// model, which prices BTC from the ratio of its supply to its annual
+// issuance. It is a reference model, not a prediction.
+type StockToFlowModel struct {
+	Points         []StockToFlowPoint
+	Supply         float64 // estimated circulating supply at the last bar
+	StockToFlow    float64
+	ModelPrice     float64
+	Residual       float64 // log of price over model price at the last bar
+	MeanResidual   float64
+	ResidualStdDev float64
+	NextHalving    time.Time
+}
+
+// StockToFlowPoint is the model at one daily bar
+type StockToFlowPoint struct {
+	Timestamp   time.Time
+	Price       float64
+	StockToFlow float64
+	ModelPrice  float64
+	Residual    float64
+}
+
+// RealPriceAnalysis compares the price deflated by a consumer price index
+// with the nominal price. Real prices are in dollars of the BaseDate reading.
+type RealPriceAnalysis struct {
+	Index             string    // name of the price index series, e.g. CPIAUCSL
+	BaseDate          time.Time // index reading real prices are expressed in
+	Points            []RealPricePoint
+	RealStats         Statistics // statistics of the real closes
+	NominalReturn     float64    // total return over the points
+	RealReturn        float64
+	NominalAnnualized float64
+	RealAnnualized    float64
+	Inflation         float64 // change of the index over the points
+	NominalDrawdown   float64 // maximum drawdown of the nominal closes
+	RealDrawdown      float64
+}
+
+// AllTimeExtremes tracks the highest and lowest closes and how far the
+// latest close sits from them. When a historical all-time high above the
+// loaded data is known, High is that high and Drawdown is measured from it.
+type AllTimeExtremes struct {
+	High          float64
+	HighAt        time.Time // zero when a historical high was given without a date
+	HighSource    string    // "data", "coingecko" or "manual"
+	DataHigh      float64   // highest close of the loaded data
+	DataHighAt    time.Time
+	Low           float64 // lowest close of the loaded data
+	LowAt         time.Time
+	Drawdown      float64 // fall of the latest close below High, as a fraction
+	AboveLow      float64 // rise of the latest close above Low, as a fraction
+	DaysSinceHigh float64 // 0 when the date of High is unknown
+	DaysSinceLow  float64
+	// Past drawdowns in the loaded data at least as deep as the current one,
+	// oldest first, and the median days they took to recover from that depth
+	SimilarDrawdowns   []DrawdownEpisode `json:",omitempty"`
+	MedianRecoveryDays float64
+}
+
+// DrawdownEpisode is a fall below a running peak close until the peak is
+// regained. Reached is when the fall first reached the depth it is compared
+// at; recovery days count from there.
+type DrawdownEpisode struct {
+	Peak          time.Time
+	PeakPrice     float64
+	Trough        time.Time
+	Depth         float64 // deepest fall below the peak, as a fraction
+	Reached       time.Time
+	Recovered     bool
+	RecoveredAt   time.Time
+	DaysToRecover float64 // from Reached to RecoveredAt
+}
+
+// RealPricePoint is the nominal and real close of one bar
+type RealPricePoint struct {
+	Timestamp time.Time
+	Nominal   float64
+	Real      float64
+	Index     float64 // index reading the bar is deflated by
+}
+
+// OnChainPoint holds the on-chain metrics of one day
+type OnChainPoint struct {
+	Timestamp time.Time
+	TxVolume  float64 // value transferred on-chain, in USD
+	Supply    float64 // circulating supply, in BTC
+}
+
+// NVTAnalysis holds the network value to transactions ratio: market cap
+// over the USD value transferred on-chain. High readings mean the price runs
+// ahead of network use.
+type NVTAnalysis struct {
+	Points           []NVTPoint
+	SignalWindow     int // days in the transaction volume average of the NVT signal
+	NVT              float64
+	NVTSignal        float64
+	NVTPercentile    float64 // of the latest NVT within its history
+	SignalPercentile float64
+	Extremes         []string // historically extreme latest readings
+}
+
+// NVTPoint holds the NVT ratios of one day; NVTSignal is 0 until its window fills
+type NVTPoint struct {
+	Timestamp time.Time
+	NVT       float64
+	NVTSignal float64
+}
+
+// BacktestRun is one stored backtest: a strategy with its parameters, the
+// period it ran over and its performance
+type BacktestRun struct {
+	ID           string              `json:"id"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Version      string              `json:"version"`
+	Strategy     string              `json:"strategy"`
+	Params       map[string]string   `json:"params,omitempty"`
+	Source       string              `json:"source"`
+	Symbol       string              `json:"symbol"`
+	Start        time.Time           `json:"start"`
+	End          time.Time           `json:"end"`
+	Bars         int                 `json:"bars"`
+	Risk         RiskConfig          `json:"risk"`
+	Costs        BacktestCosts       `json:"costs"`
+	Metrics      BacktestMetrics     `json:"metrics"` // net of Costs
+	CostSweep    []CostPoint         `json:"cost_sweep,omitempty"`
+	BreakEvenBps float64             `json:"break_even_bps,omitempty"` // cost per trade at which the total return falls to zero
+	Validation   *BacktestValidation `json:"validation,omitempty"`
+	MonteCarlo   *MonteCarloSummary  `json:"monte_carlo,omitempty"`
+}
+
+// BacktestCosts are the trading costs charged on every change of position,
+// in basis points of the traded value
+type BacktestCosts struct {
+	FeeBps      float64 `json:"fee_bps"`
+	SlippageBps float64 `json:"slippage_bps"`
+}
+
+// CostPoint is the performance of a strategy at one trading cost
+type CostPoint struct {
+	CostBps          float64 `json:"cost_bps"` // fee and slippage together
+	TotalReturn      float64 `json:"total_return"`
+	AnnualizedReturn float64 `json:"annualized_return"`
+	SharpeRatio      float64 `json:"sharpe_ratio"`
+}
+
+// MonthlyReturn is the compounded return of a strategy over the periods
+// ending in one calendar month
+type MonthlyReturn struct {
+	Year   int        `json:"year"`
+	Month  time.Month `json:"month"`
+	Return float64    `json:"return"`
+}
+
+// MonteCarloSummary is the distribution of outcomes over random sequences
+// of a strategy's trades, resampled with replacement. Returns and drawdowns
+// are fractions.
+type MonteCarloSummary struct {
+	Simulations       int     `json:"simulations"`
+	Trades            int     `json:"trades"` // trades in each sequence, as in the backtest
+	FinalReturnP5     float64 `json:"final_return_p5"`
+	FinalReturnMedian float64 `json:"final_return_median"`
+	FinalReturnP95    float64 `json:"final_return_p95"`
+	ProbabilityOfLoss float64 `json:"probability_of_loss"`
+	DrawdownMedian    float64 `json:"drawdown_median"`
+	DrawdownP95       float64 `json:"drawdown_p95"`
+	RuinDrawdown      float64 `json:"ruin_drawdown"` // drawdown that counts as ruin
+	RiskOfRuin        float64 `json:"risk_of_ruin"`
+}
+
+// BacktestValidation assesses how a backtest generalizes: a chronological
+// train/test split, purged k-fold splits, and overfitting diagnostics over
+// all strategies tested together
+type BacktestValidation struct {
+	TrainFraction  float64         `json:"train_fraction"`
+	InSample       BacktestMetrics `json:"in_sample"`
+	OutOfSample    BacktestMetrics `json:"out_of_sample"`
+	Purge          int             `json:"purge"` // bars dropped from training on each side of a test fold
+	Folds          []BacktestFold  `json:"folds,omitempty"`
+	MeanTestSharpe float64         `json:"mean_test_sharpe"`
+	Trials         int             `json:"trials"`          // strategies tested together
+	DeflatedSharpe float64         `json:"deflated_sharpe"` // probability the true Sharpe beats the best expected of Trials unskilled strategies
+	PBO            float64         `json:"pbo"`             // probability of backtest overfitting
+	PBOSplits      int             `json:"pbo_splits"`      // train/test combinations behind PBO, 0 when not estimated
+}
+
+// BacktestFold is one purged k-fold split: the metrics of the test fold and
+// of the training bars around it
+type BacktestFold struct {
+	TestStart time.Time       `json:"test_start"`
+	TestEnd   time.Time       `json:"test_end"`
+	Train     BacktestMetrics `json:"train"`
+	Test      BacktestMetrics `json:"test"`
+}
+
+// BacktestMetrics is the performance of a strategy over its period. Returns
+// and drawdowns are fractions.
+type BacktestMetrics struct {
+	TotalReturn      float64 `json:"total_return"`
+	AnnualizedReturn float64 `json:"annualized_return"`
+	Volatility       float64 `json:"volatility"`
+	SharpeRatio      float64 `json:"sharpe_ratio"`
+	MaxDrawdown      float64 `json:"max_drawdown"`
+	Trades           int     `json:"trades"`
+	WinRate          float64 `json:"win_rate"` // share of trades closed with a gain
+	Exposure         float64 `json:"exposure"` // share of periods holding a position
+}
+
+// AuxPoint represents a single observation of an auxiliary series
+type AuxPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// AuxSeries represents a non-price time series (search interest, macro data, ...)
+type AuxSeries struct {
+	Name   string
+	Points []AuxPoint
+}
+
+// LagCorrelation holds the correlation between two series at a given lag
+type LagCorrelation struct {
+	Lag         int
+	Correlation float64
+}
+
+// LeadLagAnalysis holds the cross-correlation of an auxiliary series with returns.
+// A positive lag means the auxiliary series leads returns by that many periods.
+type LeadLagAnalysis struct {
+	Series          string
+	Target          string `json:",omitempty"` // what Series is correlated with, "returns" when empty
+	Observations    int
+	Correlations    []LagCorrelation
+	BestLag         int
+	BestCorrelation float64
+	ConfidenceBand  float64 `json:",omitempty"` // correlations within ±band are not significant at 95%
+}
+
+// OrderBookLevel represents a single price level of an order book
+type OrderBookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBook represents an order book depth snapshot.
+// Bids are sorted by descending price, asks by ascending price.
+type OrderBook struct {
+	Symbol    string
+	Timestamp time.Time
+	Bids      []OrderBookLevel
+	Asks      []OrderBookLevel
+}
+
+// OrderBookMetrics holds liquidity metrics derived from an order book snapshot
+type OrderBookMetrics struct {
+	Symbol    string
+	Timestamp time.Time
+	BestBid   float64
+	BestAsk   float64
+	MidPrice  float64
+	SpreadPct float64
+	DepthBand float64 // fraction around mid used for depth, e.g. 0.02 for ±2%
+	BidDepth  float64 // cumulative bid quantity within the band
+	AskDepth  float64 // cumulative ask quantity within the band
+	Imbalance float64 // (bid - ask) / (bid + ask), in [-1, 1]
+	BidWalls  []OrderBookLevel
+	AskWalls  []OrderBookLevel
+}
+
+// Trade represents a single executed (aggregated) trade
+type Trade struct {
+	Timestamp    time.Time
+	Price        float64
+	Quantity     float64
+	IsBuyerMaker bool // true when the seller was the aggressor
+}
+
+// LargeTrade represents a trade flagged as unusually large
+type LargeTrade struct {
+	Timestamp   time.Time
+	Price       float64
+	Quantity    float64
+	Side        string // "buy" or "sell" aggressor
+	CandleIndex int
+}
+
+// OrderFlowData holds per-candle footprint metrics aligned with the price series
+type OrderFlowData struct {
+	BuyVolume   []float64
+	SellVolume  []float64
+	Delta       []float64 // buy minus sell volume per candle
+	CVD         []float64 // cumulative volume delta
+	LargeTrades []LargeTrade
+	Threshold   float64 // quantity above which a trade counts as large
+}
+
+// SwingPoint represents a confirmed swing high or low
+type SwingPoint struct {
+	Index     int
+	Timestamp time.Time
+	Price     float64
+	Type      string // "high" or "low"
+}
+
+// WaveCount represents a candidate Elliott wave count over consecutive swings
+type WaveCount struct {
+	Kind       string // "impulse" or "corrective"
+	Direction  string // "up" or "down"
+	Labels     []string
+	Points     []SwingPoint // wave origin followed by the end of each labeled wave
+	Confidence float64      // 0..1, how closely the guideline ratios are met
+	Notes      []string
+}
+
+// ElliottAnalysis holds the swing skeleton and the candidate wave counts
+type ElliottAnalysis struct {
+	Threshold float64 // minimum reversal for a swing, e.g. 0.05 for 5%
+	Swings    []SwingPoint
+	Counts    []WaveCount
+}
+
+// HarmonicPattern represents an XABCD harmonic pattern. Pending patterns have
+// only X, A, B and C; their PRZ is where D would complete the pattern.
+type HarmonicPattern struct {
+	Name      string // "Gartley", "Bat", "Butterfly" or "Crab"
+	Direction string // "bullish" (D is a low) or "bearish" (D is a high)
+	Points    []SwingPoint
+	Complete  bool
+	Ratios    map[string]float64 // AB/XA, BC/AB, CD/BC and AD/XA
+	PRZLow    float64            // potential reversal zone
+	PRZHigh   float64
+}
+
+// WyckoffEvent represents a labeled event within a Wyckoff trading range
+type WyckoffEvent struct {
+	Index     int
+	Timestamp time.Time
+	Price     float64
+	Event     string // "SC", "BC", "AR", "Spring", "UT", "SOS", "SOW"
+	Volume    float64
+}
+
+// WyckoffAnalysis describes the current trading range and its Wyckoff schematic
+type WyckoffAnalysis struct {
+	Schematic         string // "accumulation", "distribution" or "undetermined"
+	Phase             string // "A" through "E"
+	RangeStart        int
+	RangeEnd          int
+	Support           float64
+	Resistance        float64
+	VolumeTrend       string  // "declining", "flat" or "rising" across the range
+	UpDownVolumeRatio float64 // average volume of up bars over down bars in the range
+	Events            []WyckoffEvent
+}
+
+// StructureEvent represents a break of a prior swing level. A BOS continues
+// the current bias, a CHOCH (change of character) reverses it.
+type StructureEvent struct {
+	Index     int
+	Timestamp time.Time
+	Type      string // "BOS" or "CHOCH"
+	Direction string // "bullish" or "bearish"
+	Level     float64
+}
+
+// MarketStructure holds labeled swings, structure breaks and the resulting bias
+type MarketStructure struct {
+	Swings []SwingPoint
+	Labels []string // "HH", "LH", "HL" or "LL" for each swing; empty for the first high and low
+	Events []StructureEvent
+	Bias   string // "bullish", "bearish" or "neutral"
+}
+
+// HorizonOutcome holds forward return statistics after a pattern for one horizon
+type HorizonOutcome struct {
+	Bars      int
+	Samples   int
+	WinRate   float64 // fraction of moves in the pattern's expected direction (up for neutral patterns)
+	AvgReturn float64
+}
+
+// PatternOutcome holds the historical forward performance of a detected pattern
+type PatternOutcome struct {
+	Pattern     string
+	Bias        string // "bullish", "bearish" or "neutral"
+	Occurrences int
+	Outcomes    []HorizonOutcome
+}
+
+// LiquidityZone represents an estimated pool of resting stops beyond clustered
+// wick rejections or equal highs/lows that price has not yet swept
+type LiquidityZone struct {
+	Side        string // "buy-side" above price or "sell-side" below
+	Low         float64
+	High        float64
+	Wicks       int // long-wick rejections in the zone
+	EqualLevels int // swing highs/lows forming equal highs/lows
+	LastSeen    time.Time
+	DistancePct float64 // from the latest close to the near edge of the zone
+	Strength    float64 // wicks plus double-weighted equal levels
+}
+
+// SessionGap is a price gap between the close of one trading session and the
+// open of the next, e.g. over a CME futures weekend
+type SessionGap struct {
+	Closed      time.Time // last bar of the session before the gap
+	Opened      time.Time // first bar of the next session
+	Close       float64   // the gap is filled when price trades back to it
+	Open        float64
+	Size        float64 // open over close minus one; positive for gaps up
+	Filled      bool
+	FilledAt    time.Time
+	HoursToFill float64 // from the open of the next session
+}
+
+// SessionGapStats summarizes the price gaps between the sessions of a series
+// with trading breaks. Unfilled gaps are the ones price has not traded back to.
+type SessionGapStats struct {
+	Sessions          int // trading sessions in the series
+	Gaps              []SessionGap
+	Up, Down          int
+	Filled            int
+	FillRate          float64 // filled gaps over all gaps
+	MedianHoursToFill float64
+	FilledInSession   float64 // share of gaps filled in the session they opened
+	Unfilled          []SessionGap
+}
+
+// VolumeForensics summarizes data-quality and wash-trading checks on volume
+type VolumeForensics struct {
+	Samples           int
+	BenfordObserved   [9]float64 // share of volumes with leading digit 1-9
+	BenfordChiSquare  float64
+	BenfordMAD        float64            // mean absolute deviation from Benford's distribution
+	BenfordConformity string             // "close", "acceptable", "marginal" or "nonconforming"
+	RoundShare        float64            // share of volumes that are round numbers
+	SuspiciousPeriods []SuspiciousPeriod `json:",omitempty"`
+}
+
+// SuspiciousPeriod is a window whose volume looks inflated or fabricated
+type SuspiciousPeriod struct {
+	Start      time.Time
+	End        time.Time
+	RoundShare float64
+	FlatBars   int // high-volume bars with almost no price movement
+	Reasons    []string
+}
+
+// SourceQuality summarizes how one data source agrees with the others
+type SourceQuality struct {
+	Source            string
+	Bars              int
+	MissingBars       int     // aligned timestamps the source has no bar for
+	ValidationIssues  int     // problems found by data validation
+	MeanDivergence    float64 // mean |close - consensus| / consensus
+	MaxDivergence     float64
+	MaxDivergenceAt   time.Time
+	VolumeRatio       float64 // median of volume / consensus volume
+	BenfordConformity string
+	RoundShare        float64
+	Score             float64 // lower is cleaner
+}
+
+// BarDivergence is the disagreement between sources on one aligned bar
+type BarDivergence struct {
+	Timestamp time.Time
+	Closes    map[string]float64
+	Volumes   map[string]float64
+	Spread    float64 // (max close - min close) / consensus close
+}
+
+// SourceComparison is the result of aligning the same period from several sources
+type SourceComparison struct {
+	Interval    time.Duration
+	Start       time.Time
+	End         time.Time
+	AlignedBars int
+	Sources     []SourceQuality
+	Bars        []BarDivergence
+	Recommended string
+}
+
+// SnapshotDiff is what changed between two analyses of the same market:
+// metric deltas, threshold crossings, support/resistance levels and signals
+type SnapshotDiff struct {
+	OldGeneratedAt time.Time      `json:"old_generated_at"`
+	NewGeneratedAt time.Time      `json:"new_generated_at"`
+	OldEnd         time.Time      `json:"old_end"` // last bar of the older analysis
+	NewEnd         time.Time      `json:"new_end"`
+	Metrics        []MetricChange `json:"metrics"`
+	Events         []string       `json:"events,omitempty"` // threshold crossings, e.g. "RSI crossed above 70"
+	Levels         []LevelChange  `json:"levels,omitempty"`
+	Signals        []SignalChange `json:"signals,omitempty"`
+	Changelog      []string       `json:"changelog"` // every change as one human-readable line
+}
+
+// MetricChange is the change of one metric between two analyses
+type MetricChange struct {
+	Metric string  `json:"metric"`
+	Old    float64 `json:"old"`
+	New    float64 `json:"new"`
+	Change float64 `json:"change"` // New - Old
+}
+
+// LevelChange is a support or resistance level that appeared, disappeared
+// or broke between two analyses
+type LevelChange struct {
+	Type   string  `json:"type"` // "support" or "resistance"
+	Price  float64 `json:"price"`
+	Change string  `json:"change"` // "appeared", "disappeared" or "broken"
+}
+
+// SignalChange is a trading signal that changed between two analyses; Old
+// or New is empty when the signal was only in one of them
+type SignalChange struct {
+	Signal string `json:"signal"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// WeeklyCandle is one calendar week of bars, from Monday 00:00 UTC to the
+// following Monday
+type WeeklyCandle struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+	Bars   int       `json:"bars"`
+}
+
+// WeeklyEvent is a pattern or signal that fired during the week
+type WeeklyEvent struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Kind        string    `json:"kind"` // "pattern", "structure", "wyckoff", "harmonic", "level" or "indicator"
+	Description string    `json:"description"`
+}
+
+// WeeklyReport summarizes one calendar week against the week before it
+type WeeklyReport struct {
+	Symbol       string            `json:"symbol"`
+	Denomination *Denomination     `json:"denomination,omitempty"`
+	Week         WeeklyCandle      `json:"week"`
+	PriorWeek    *WeeklyCandle     `json:"prior_week,omitempty"` // nil when the series starts within the week
+	Complete     bool              `json:"complete"`             // false for a week still in progress
+	Change       float64           `json:"change"`               // close over the prior week's close, or the week's open without one
+	VolumeChange float64           `json:"volume_change"`        // volume over the prior week's, 0 without one
+	Events       []WeeklyEvent     `json:"events"`
+	Signals      map[string]string `json:"trading_signals"` // as of the end of the week
+	SignalScore  float64           `json:"signal_score"`
+	Diff         *SnapshotDiff     `json:"diff,omitempty"` // from the end of the prior week, nil without one
+	Narrative    []string          `json:"narrative,omitempty"`
+}
+
+// PremiumPoint is the premium of the analyzed price over the reference at one bar
+type PremiumPoint struct {
+	Timestamp time.Time
+	Price     float64
+	Reference float64
+	Premium   float64 // price / reference - 1
+	ZScore    float64 // against the trailing window, 0 until the window fills
+}
+
+// PremiumAnalysis tracks the basis between the analyzed series and a reference index
+type PremiumAnalysis struct {
+	Reference string
+	Window    int     // bars in the trailing z-score window
+	Threshold float64 // |z| at which a premium or discount is extreme
+	Points    []PremiumPoint
+	Mean      float64
+	StdDev    float64
+	Alerts    []PremiumPoint // bars entering an extreme premium or discount
+}
+
+// BetaAnalysis is the regression of the analyzed returns on the returns of
+// a benchmark over the bars both series have
+type BetaAnalysis struct {
+	Benchmark      string
+	Observations   int // aligned return pairs
+	Beta           float64
+	Alpha          float64 // intercept, annualized
+	Correlation    float64
+	RSquared       float64
+	PeriodsPerYear float64     // aligned returns per year, e.g. about 252 against a stock index
+	Window         int         // returns in each rolling regression
+	Rolling        []BetaPoint `json:",omitempty"`
+}
+
+// PairConfig sets the z-score of a pairs analysis and the thresholds of
+// its trading template
+type PairConfig struct {
+	Window int     `json:"window,omitempty"` // bars of the rolling z-score, 0 for the spread's half-life
+	EntryZ float64 `json:"entry_z"`          // |z| at which a position in the spread opens
+	ExitZ  float64 `json:"exit_z"`           // |z| at which it closes
+}
+
+// PairAnalysis tests whether the log prices of the analyzed series and a
+// second series are cointegrated (Engle-Granger) and tracks their spread:
+// log price - HedgeRatio·log pair price - Intercept. A position of 1 is long
+// the spread, i.e. long the analyzed series and short HedgeRatio of the pair.
+type PairAnalysis struct {
+	Pair          string
+	Observations  int // aligned bars
+	HedgeRatio    float64
+	Intercept     float64
+	Correlation   float64          // of the aligned returns
+	Cointegration StationarityTest // ADF of the spread against Engle-Granger critical values
+	HalfLife      OUFit            // Ornstein-Uhlenbeck fit of the spread
+	Config        PairConfig       // with the window in use
+	ZScore        float64          // latest
+	Position      float64          // latest position of the z-score template: 1 long, -1 short, 0 flat
+	Points        []SpreadPoint    `json:",omitempty"`
+}
+
+// SpreadPoint is the spread of a pair and its rolling z-score at one bar
+type SpreadPoint struct {
+	Timestamp time.Time
+	Spread    float64
+	ZScore    float64
+}
+
+// BetaPoint is the beta and annualized alpha of the rolling window ending at Timestamp
+type BetaPoint struct {
+	Timestamp time.Time
+	Beta      float64
+	Alpha     float64
+}
+
+// TermPoint is the at-the-money implied volatility of one option expiry
+type TermPoint struct {
+	Expiry time.Time
+	Days   float64
+	ATMIV  float64 // annualized, as a fraction
+}
+
+// IVRVPoint pairs implied and realized volatility at one timestamp
+type IVRVPoint struct {
+	Timestamp time.Time
+	Implied   float64
+	Realized  float64
+}
+
+// VolatilityAnalysis compares options-implied with realized volatility
+type VolatilityAnalysis struct {
+	ImpliedVol    float64 // latest implied volatility index, as a fraction
+	RealizedVol   float64 // annualized realized volatility over the trailing window
+	Ratio         float64 // implied / realized
+	WindowDays    int
+	History       []IVRVPoint
+	TermStructure []TermPoint `json:",omitempty"`
+}
+
+// PriceAlert represents a price alert condition
+type PriceAlert struct {
+	Type      string // "above", "below", "change"
+	Threshold float64
+	Urgency   string `json:",omitempty"` // "high" notifies at once, "normal" (default) goes into the digest
+	Triggered bool
+	Timestamp time.Time
+}
+
+// Watchlist is one market tracked in server mode: a symbol and timeframe
+// analyzed on its own schedule against its own alert rules
+type Watchlist struct {
+	ID        string
+	Owner     string
+	Source    string // "binance" (default) or "sample"
+	Symbol    string // Binance symbol, e.g. BTCUSDT
+	Timeframe string // Binance kline interval, e.g. 1h
+	Days      int    // history loaded for each analysis
+	Every     string // re-analysis interval as a Go duration, e.g. "15m"
+	Alerts    []PriceAlert
+	LastRun   time.Time
+	LastError string `json:",omitempty"`
+}
+
+// DownloadCheckpoint is the progress of an interrupted backfill: the
+// candles of its complete chunks are in Staging, and the next chunk starts
+// at Next
+type DownloadCheckpoint struct {
+	ID        string    `json:"id"` // symbol, interval, range and output of the backfill
+	Symbol    string    `json:"symbol"`
+	Interval  string    `json:"interval"`
+	Staging   string    `json:"staging"` // candle log of the fetched candles
+	Next      time.Time `json:"next"`
+	Chunks    int       `json:"chunks"`
+	Candles   int       `json:"candles"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PaperAccount is a virtual account that trades the signals of one strategy
+// against live prices. It is either all cash or fully long.
+type PaperAccount struct {
+	Strategy        string        `json:"strategy"` // trading signal it follows, e.g. "RSI" or "Custom: trend"
+	Symbol          string        `json:"symbol"`
+	FeeBps          float64       `json:"fee_bps"`
+	StartingBalance float64       `json:"starting_balance"`
+	Cash            float64       `json:"cash"`
+	Quantity        float64       `json:"quantity"`
+	CostBasis       float64       `json:"cost_basis"` // cash spent on the open position, fees included
+	LastPrice       float64       `json:"last_price"`
+	Equity          float64       `json:"equity"`
+	RealizedPnL     float64       `json:"realized_pnl"`
+	UnrealizedPnL   float64       `json:"unrealized_pnl"`
+	Fills           []PaperFill   `json:"fills"`
+	History         []EquityPoint `json:"history"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// PaperFill is one simulated order of a paper account
+type PaperFill struct {
+	Time     time.Time `json:"time"`
+	Side     string    `json:"side"` // "buy" or "sell"
+	Price    float64   `json:"price"`
+	Quantity float64   `json:"quantity"`
+	Fee      float64   `json:"fee"`
+	PnL      float64   `json:"pnl,omitempty"` // realized by a sell
+	Signal   string    `json:"signal"`
+}
+
+// ExecutionOrder is one order the execution adapter placed or simulated
+type ExecutionOrder struct {
+	Time     time.Time `json:"time"`
+	Exchange string    `json:"exchange"`
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side"` // "buy" or "sell"
+	Type     string    `json:"type"` // "market" or "limit"
+	Quantity float64   `json:"quantity"`
+	Price    float64   `json:"price,omitempty"` // limit price
+	ClientID string    `json:"client_id"`
+	Signal   string    `json:"signal"`
+	DryRun   bool      `json:"dry_run"`
+	OrderID  string    `json:"order_id,omitempty"` // exchange order ID
+	Status   string    `json:"status,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// EquityPoint is the value of an account at one time
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// CoinGeckoResponse represents API response from CoinGecko
+type CoinGeckoResponse struct {
+	Prices       [][]float64 `json:"prices"`
+	MarketCaps   [][]float64 `json:"market_caps"`
+	TotalVolumes [][]float64 `json:"total_volumes"`
+}
+
+// CoinMarket is one coin of the CoinGecko market listing, ranked by market cap
+type CoinMarket struct {
+	ID            string  `json:"id"`     // CoinGecko id, e.g. "bitcoin"
+	Symbol        string  `json:"symbol"` // ticker, e.g. "btc"
+	Name          string  `json:"name"`
+	MarketCapRank int     `json:"market_cap_rank"`
+	MarketCap     float64 `json:"market_cap"`    // in USD
+	CurrentPrice  float64 `json:"current_price"` // in USD
+}
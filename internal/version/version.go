@@ -0,0 +1,51 @@
+// Package version identifies the analyzer build
+package version
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Version is the release version, set at build time with
+// -ldflags "-X btc-analyzer/internal/version.Version=1.2.0"
+var Version = "dev"
+
+// Revision returns the VCS revision the binary was built from, marked when
+// the working tree had changes, or "" when the build has no VCS information
+func Revision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	revision, modified := "", ""
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			if setting.Value == "true" {
+				modified = "+modified"
+			}
+		}
+	}
+	if revision == "" {
+		return ""
+	}
+	return revision + modified
+}
+
+// String returns the version with the short revision, e.g. "dev (3f2a1c9+modified)"
+func String() string {
+	revision := Revision()
+	if revision == "" {
+		return Version
+	}
+	hash, modified, _ := strings.Cut(revision, "+")
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	if modified != "" {
+		hash += "+" + modified
+	}
+	return Version + " (" + hash + ")"
+}
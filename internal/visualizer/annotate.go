@@ -0,0 +1,265 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/supply"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// AnnotationStyle is how an annotation is drawn
+type AnnotationStyle struct {
+	Color  color.Color
+	Width  vg.Length // line width, 1pt when zero
+	Dashed bool
+	Legend string // legend entry, none when empty
+}
+
+// Annotations is a layer of horizontal levels, vertical events, shaded
+// regions and text labels drawn over a chart in data coordinates. Levels
+// and events span the whole axis; regions may be unbounded on any side
+// with an infinite coordinate. Finite coordinates widen the axes to fit.
+type Annotations struct {
+	hlines  []annotationLine
+	vlines  []annotationLine
+	regions []annotationRegion
+	labels  []annotationLabel
+}
+
+type annotationLine struct {
+	at    float64
+	label string
+	style AnnotationStyle
+}
+
+type annotationRegion struct {
+	x0, x1, y0, y1 float64
+	style          AnnotationStyle
+}
+
+type annotationLabel struct {
+	x, y float64
+	text string
+	clr  color.Color
+}
+
+// AddHLine adds a horizontal level at y, labeled past the right end
+func (a *Annotations) AddHLine(y float64, label string, style AnnotationStyle) {
+	a.hlines = append(a.hlines, annotationLine{y, label, style})
+}
+
+// AddVLine adds a vertical event line at x, labeled at the top
+func (a *Annotations) AddVLine(x float64, label string, style AnnotationStyle) {
+	a.vlines = append(a.vlines, annotationLine{x, label, style})
+}
+
+// AddRegion adds a region from x0 to x1 and y0 to y1, shaded with the
+// style color, or outlined when the style is dashed
+func (a *Annotations) AddRegion(x0, x1, y0, y1 float64, style AnnotationStyle) {
+	a.regions = append(a.regions, annotationRegion{math.Min(x0, x1), math.Max(x0, x1), math.Min(y0, y1), math.Max(y0, y1), style})
+}
+
+// AddLabel adds text just above and right of the point x, y
+func (a *Annotations) AddLabel(x, y float64, txt string, clr color.Color) {
+	a.labels = append(a.labels, annotationLabel{x, y, txt, clr})
+}
+
+// AddHalvings marks each halving within the bars at timestamps with an
+// event line at the first bar at or after it
+func (a *Annotations) AddHalvings(timestamps []time.Time) {
+	if len(timestamps) == 0 {
+		return
+	}
+	style := AnnotationStyle{Color: color.RGBA{R: 247, G: 147, B: 26, A: 255}, Dashed: true, Legend: "Halving"}
+	for _, halving := range supply.Halvings {
+		if halving.Before(timestamps[0]) {
+			continue
+		}
+		i := sort.Search(len(timestamps), func(i int) bool { return !timestamps[i].Before(halving) })
+		if i < len(timestamps) {
+			a.AddVLine(float64(i), halving.Format("Halving 2006-01-02"), style)
+		}
+	}
+}
+
+// Draw adds the layer to p, with the legend entries of its styles, each
+// once. Add it after the data so that it is drawn on top.
+func (a *Annotations) Draw(p *plot.Plot, config ChartConfig) {
+	p.Add(&annotationLayer{a})
+	if !config.ShowLegend {
+		return
+	}
+	seen := make(map[string]bool)
+	entry := func(style AnnotationStyle, region bool) {
+		if style.Legend == "" || seen[style.Legend] {
+			return
+		}
+		seen[style.Legend] = true
+		p.Legend.Add(style.Legend, annotationThumb{style, region})
+	}
+	for _, r := range a.regions {
+		entry(r.style, !r.style.Dashed)
+	}
+	for _, l := range append(append([]annotationLine(nil), a.hlines...), a.vlines...) {
+		entry(l.style, false)
+	}
+}
+
+// annotationLayer is the plotter of Annotations
+type annotationLayer struct {
+	*Annotations
+}
+
+// Plot implements plot.Plotter, clipping every annotation to the axes
+func (l *annotationLayer) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+	clampX := func(x float64) float64 { return math.Max(p.X.Min, math.Min(p.X.Max, x)) }
+	clampY := func(y float64) float64 { return math.Max(p.Y.Min, math.Min(p.Y.Max, y)) }
+
+	for _, r := range l.regions {
+		if r.x1 < p.X.Min || r.x0 > p.X.Max || r.y1 < p.Y.Min || r.y0 > p.Y.Max {
+			continue
+		}
+		x0, x1 := trX(clampX(r.x0)), trX(clampX(r.x1))
+		y0, y1 := trY(clampY(r.y0)), trY(clampY(r.y1))
+		corners := []vg.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+		if r.style.Dashed {
+			c.StrokeLines(lineStyle(r.style), append(corners, corners[0]))
+			continue
+		}
+		c.FillPolygon(regionColor(r.style.Color), corners)
+	}
+
+	for _, h := range l.hlines {
+		if h.at < p.Y.Min || h.at > p.Y.Max {
+			continue
+		}
+		y := trY(h.at)
+		c.StrokeLine2(lineStyle(h.style), c.Min.X, y, c.Max.X, y)
+		if h.label != "" {
+			c.FillText(labelStyle(h.style.Color, draw.XLeft, draw.YCenter), vg.Point{X: c.Max.X + vg.Points(2), Y: y}, h.label)
+		}
+	}
+
+	for _, v := range l.vlines {
+		if v.at < p.X.Min || v.at > p.X.Max {
+			continue
+		}
+		x := trX(v.at)
+		c.StrokeLine2(lineStyle(v.style), x, c.Min.Y, x, c.Max.Y)
+		if v.label != "" {
+			c.FillText(labelStyle(v.style.Color, draw.XLeft, draw.YTop), vg.Point{X: x + vg.Points(2), Y: c.Max.Y}, v.label)
+		}
+	}
+
+	for _, lb := range l.labels {
+		if lb.x < p.X.Min || lb.x > p.X.Max || lb.y < p.Y.Min || lb.y > p.Y.Max {
+			continue
+		}
+		c.FillText(labelStyle(lb.clr, draw.XLeft, draw.YBottom), vg.Point{X: trX(lb.x), Y: trY(lb.y)}, lb.text)
+	}
+}
+
+// DataRange implements plot.DataRanger over the finite coordinates
+func (l *annotationLayer) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, ymin = math.Inf(1), math.Inf(1)
+	xmax, ymax = math.Inf(-1), math.Inf(-1)
+	widen := func(v float64, lo, hi *float64) {
+		if !math.IsInf(v, 0) && !math.IsNaN(v) {
+			*lo, *hi = math.Min(*lo, v), math.Max(*hi, v)
+		}
+	}
+	for _, h := range l.hlines {
+		widen(h.at, &ymin, &ymax)
+	}
+	for _, v := range l.vlines {
+		widen(v.at, &xmin, &xmax)
+	}
+	for _, r := range l.regions {
+		widen(r.x0, &xmin, &xmax)
+		widen(r.x1, &xmin, &xmax)
+		widen(r.y0, &ymin, &ymax)
+		widen(r.y1, &ymin, &ymax)
+	}
+	for _, lb := range l.labels {
+		widen(lb.x, &xmin, &xmax)
+		widen(lb.y, &ymin, &ymax)
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// GlyphBoxes implements plot.GlyphBoxer so that the plot is padded to fit
+// the level labels past its right edge and the point labels
+func (l *annotationLayer) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
+	var boxes []plot.GlyphBox
+	for _, h := range l.hlines {
+		if h.label != "" {
+			sty := labelStyle(h.style.Color, draw.XLeft, draw.YCenter)
+			boxes = append(boxes, plot.GlyphBox{X: 1, Y: p.Y.Norm(h.at), Rectangle: sty.Rectangle(h.label).Add(vg.Point{X: vg.Points(2)})})
+		}
+	}
+	for _, lb := range l.labels {
+		sty := labelStyle(lb.clr, draw.XLeft, draw.YBottom)
+		boxes = append(boxes, plot.GlyphBox{X: p.X.Norm(lb.x), Y: p.Y.Norm(lb.y), Rectangle: sty.Rectangle(lb.text)})
+	}
+	return boxes
+}
+
+// annotationThumb is the legend entry of an annotation style
+type annotationThumb struct {
+	style  AnnotationStyle
+	region bool
+}
+
+// Thumbnail implements plot.Thumbnailer
+func (t annotationThumb) Thumbnail(c *draw.Canvas) {
+	if t.region {
+		pts := []vg.Point{{X: c.Min.X, Y: c.Min.Y}, {X: c.Max.X, Y: c.Min.Y}, {X: c.Max.X, Y: c.Max.Y}, {X: c.Min.X, Y: c.Max.Y}}
+		c.FillPolygon(regionColor(t.style.Color), pts)
+		return
+	}
+	y := c.Center().Y
+	c.StrokeLine2(lineStyle(t.style), c.Min.X, y, c.Max.X, y)
+}
+
+// lineStyle returns the line style of an annotation
+func lineStyle(style AnnotationStyle) draw.LineStyle {
+	ls := plotter.DefaultLineStyle
+	ls.Color = style.Color
+	ls.Width = vg.Points(1)
+	if style.Width > 0 {
+		ls.Width = style.Width
+	}
+	if style.Dashed {
+		ls.Dashes = []vg.Length{vg.Points(6), vg.Points(4)}
+	}
+	return ls
+}
+
+// regionColor returns the translucent fill of a shaded region
+func regionColor(clr color.Color) color.Color {
+	r, g, b, _ := clr.RGBA()
+	return color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 50}
+}
+
+// labelStyle returns the text style of annotation labels
+func labelStyle(clr color.Color, xAlign text.XAlignment, yAlign text.YAlignment) text.Style {
+	if clr == nil {
+		clr = color.Black
+	}
+	return text.Style{
+		Color:   clr,
+		Font:    font.From(plotter.DefaultFont, plotter.DefaultFontSize),
+		Handler: plot.DefaultTextHandler,
+		XAlign:  xAlign,
+		YAlign:  yAlign,
+	}
+}
@@ -0,0 +1,122 @@
+package visualizer
+
+import (
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// holdColor is the color of the buy-and-hold baseline on backtest charts
+var holdColor = color.RGBA{R: 150, G: 150, B: 150, A: 255}
+
+// newBacktestPlot creates a plot with the titles of config and a grid
+func newBacktestPlot(config ChartConfig) *plot.Plot {
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+	p.Legend.Top = true
+	p.Legend.Left = true
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+	return p
+}
+
+// DrawEquityChart plots the equity curve of a strategy against the
+// buy-and-hold baseline, both as the growth of 100 over the same bars.
+// hold may be nil to plot the strategy alone.
+func DrawEquityChart(name string, equity, hold []float64, config ChartConfig) ([]byte, error) {
+	if len(equity) == 0 {
+		return nil, fmt.Errorf("no equity curve to plot")
+	}
+
+	p := newBacktestPlot(config)
+	lines := []struct {
+		label  string
+		values []float64
+		color  color.RGBA
+	}{
+		{"Buy and hold", hold, holdColor},
+		{name, equity, overlayColors[0]},
+	}
+	for _, l := range lines {
+		if len(l.values) == 0 {
+			continue
+		}
+		values := make([]float64, len(l.values))
+		for i, v := range l.values {
+			values[i] = v * 100
+		}
+		line, err := plotter.NewLine(makeChartXYs(values, config))
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = l.color
+		line.LineStyle.Width = config.LineWidth
+		p.Add(line)
+		if config.ShowLegend {
+			p.Legend.Add(l.label, line)
+		}
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawUnderwaterChart plots the drawdown of an equity curve from its
+// running peak, in percent, as a filled area below zero
+func DrawUnderwaterChart(drawdowns []float64, config ChartConfig) ([]byte, error) {
+	if len(drawdowns) == 0 {
+		return nil, fmt.Errorf("no drawdowns to plot")
+	}
+
+	p := newBacktestPlot(config)
+	percent := make([]float64, len(drawdowns))
+	for i, v := range drawdowns {
+		percent[i] = v * 100
+	}
+	points := makeChartXYs(percent, config)
+
+	// The area is closed along the zero line from the last point back to
+	// the first
+	outline := append(plotter.XYs{{X: points[0].X, Y: 0}}, points...)
+	outline = append(outline, plotter.XY{X: points[len(points)-1].X, Y: 0})
+	area, err := plotter.NewPolygon(outline)
+	if err != nil {
+		return nil, err
+	}
+	area.Color = color.RGBA{R: 240, G: 170, B: 175, A: 255}
+	area.LineStyle.Color = color.RGBA{R: 220, G: 53, B: 69, A: 255}
+	area.LineStyle.Width = config.LineWidth / 2
+	p.Add(area)
+	p.Y.Max = 0
+
+	return renderPlot(p, config)
+}
+
+// DrawRollingSharpeChart plots rolling Sharpe ratios ending at the last of
+// bars bars, with the zero line
+func DrawRollingSharpeChart(sharpes []float64, bars int, config ChartConfig) ([]byte, error) {
+	if len(sharpes) == 0 {
+		return nil, fmt.Errorf("no rolling Sharpe ratios to plot")
+	}
+
+	p := newBacktestPlot(config)
+	p.X.Min, p.X.Max = 0, float64(bars-1)
+
+	var notes Annotations
+	notes.AddHLine(0, "", AnnotationStyle{Color: holdColor})
+	notes.Draw(p, config)
+
+	line, err := plotter.NewLine(makeAlignedXYs(sharpes, bars, config))
+	if err != nil {
+		return nil, err
+	}
+	line.LineStyle.Color = overlayColors[0]
+	line.LineStyle.Width = config.LineWidth
+	p.Add(line)
+
+	return renderPlot(p, config)
+}
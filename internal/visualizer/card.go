@@ -0,0 +1,156 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"bytes"
+	"fmt"
+	"image/color"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// Summary card size in pixels, the preview size of social media links
+const (
+	CardWidth  = 1200
+	CardHeight = 630
+)
+
+// SummaryCard is the content of a summary card. Changes are fractions;
+// unset ones are shown as n/a.
+type SummaryCard struct {
+	Symbol      string
+	Price       string // formatted in the series denomination
+	Change24h   *float64
+	Change30d   *float64
+	RSI         *float64
+	SignalScore float64 // -1 (all SELL) to +1 (all BUY)
+	Signals     int
+	Sparkline   []float64 // recent closes
+	AsOf        time.Time
+}
+
+var (
+	cardBackground = color.RGBA{R: 17, G: 20, B: 24, A: 255}
+	cardPanel      = color.RGBA{R: 28, G: 33, B: 39, A: 255}
+	cardText       = color.RGBA{R: 233, G: 236, B: 239, A: 255}
+	cardMuted      = color.RGBA{R: 138, G: 147, B: 156, A: 255}
+	cardUp         = color.RGBA{R: 40, G: 167, B: 69, A: 255}
+	cardDown       = color.RGBA{R: 220, G: 53, B: 69, A: 255}
+	cardNeutral    = color.RGBA{R: 255, G: 193, B: 7, A: 255}
+)
+
+// DrawSummaryCard renders a 1200x630 PNG with the latest price, its 24-hour
+// and 30-day changes, RSI and the composite signal over a sparkline of
+// recent closes, in the dark style of the risk dashboard
+func DrawSummaryCard(card SummaryCard) ([]byte, error) {
+	img := vgimg.NewWith(vgimg.UseWH(CardWidth, CardHeight), vgimg.UseDPI(72))
+	c := draw.New(img)
+	c.FillPolygon(cardBackground, rect(0, 0, CardWidth, CardHeight))
+
+	write := func(x, y vg.Length, size vg.Length, clr color.Color, xAlign text.XAlignment, txt string) {
+		style := text.Style{
+			Color:   clr,
+			Font:    font.From(font.Font{Typeface: "Liberation", Variant: "Sans"}, size),
+			Handler: plot.DefaultTextHandler,
+			XAlign:  xAlign,
+			YAlign:  draw.YTop,
+		}
+		c.FillText(style, vg.Point{X: x, Y: CardHeight - y}, txt)
+	}
+
+	write(60, 50, 34, cardMuted, draw.XLeft, card.Symbol)
+	write(CardWidth-60, 56, 24, cardMuted, draw.XRight, card.AsOf.UTC().Format("Jan 2, 2006 15:04 UTC"))
+	write(60, 100, 96, cardText, draw.XLeft, card.Price)
+
+	// Sparkline across the middle, colored by the direction of the period
+	if len(card.Sparkline) >= 2 {
+		p := plot.New()
+		p.HideAxes()
+		p.BackgroundColor = color.Transparent
+		line, err := plotter.NewLine(makeSimpleXYs(card.Sparkline))
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = cardUp
+		if card.Sparkline[len(card.Sparkline)-1] < card.Sparkline[0] {
+			line.LineStyle.Color = cardDown
+		}
+		line.LineStyle.Width = vg.Points(4)
+		p.Add(line)
+		p.Draw(draw.Crop(c, 60, -60, CardHeight-415, -230))
+	}
+
+	// Metric panels along the bottom
+	panels := []struct {
+		label, value string
+		clr          color.Color
+	}{
+		{"24h", formatChange(card.Change24h), changeColor(card.Change24h)},
+		{"30d", formatChange(card.Change30d), changeColor(card.Change30d)},
+		{"RSI (14)", "n/a", cardMuted},
+		{"Signal", fmt.Sprintf("%+.0f", card.SignalScore*100), cardNeutral},
+	}
+	if card.RSI != nil {
+		panels[2].value, panels[2].clr = fmt.Sprintf("%.0f", *card.RSI), cardText
+		if *card.RSI >= 70 {
+			panels[2].clr = cardDown
+		} else if *card.RSI <= 30 {
+			panels[2].clr = cardUp
+		}
+	}
+	if card.SignalScore >= 0.3 {
+		panels[3].clr = cardUp
+	} else if card.SignalScore <= -0.3 {
+		panels[3].clr = cardDown
+	}
+	if card.Signals == 0 {
+		panels[3].value, panels[3].clr = "n/a", cardMuted
+	}
+
+	const gap, top, height = 20, 440, 150
+	width := (CardWidth - 120 - gap*vg.Length(len(panels)-1)) / vg.Length(len(panels))
+	for i, panel := range panels {
+		x := 60 + vg.Length(i)*(width+gap)
+		c.FillPolygon(cardPanel, rect(x, CardHeight-top-height, x+width, CardHeight-top))
+		write(x+width/2, top+20, 26, cardMuted, draw.XCenter, panel.label)
+		write(x+width/2, top+62, 56, panel.clr, draw.XCenter, panel.value)
+	}
+
+	var buf bytes.Buffer
+	if _, err := (vgimg.PngCanvas{Canvas: img}).WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rect returns the corners of a rectangle
+func rect(x0, y0, x1, y1 vg.Length) []vg.Point {
+	return []vg.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+}
+
+// formatChange formats a change as a signed percentage
+func formatChange(change *float64) string {
+	if change == nil {
+		return "n/a"
+	}
+	return numfmt.SignedPct(*change)
+}
+
+// changeColor colors a change by its sign
+func changeColor(change *float64) color.Color {
+	switch {
+	case change == nil:
+		return cardMuted
+	case *change < 0:
+		return cardDown
+	default:
+		return cardUp
+	}
+}
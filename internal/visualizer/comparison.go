@@ -0,0 +1,81 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// DrawComparisonChart plots the closes of several series over the bars of
+// the first, all resampled to the coarsest interval among them. Bars of the
+// other series are matched by time; those without a match are skipped. The
+// series are usually in different units, so normalize to compare them.
+func DrawComparisonChart(names []string, series []*types.BTCTimeSeries, config ChartConfig) ([]byte, error) {
+	if len(series) == 0 || len(series[0].Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+
+	interval := timeseries.DetectFrequency(series[0]).Interval
+	for _, bts := range series[1:] {
+		if len(bts.Data) > 0 {
+			if other := timeseries.DetectFrequency(bts).Interval; other > interval {
+				interval = other
+			}
+		}
+	}
+
+	// Keyed by Unix time: time.Time keys also compare location and monotonic
+	// readings, which generated series carry
+	index := make(map[int64]int)
+	for i, bar := range timeseries.Resample(series[0], interval).Data {
+		index[bar.Timestamp.Unix()] = i
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+	p.Legend.Top = true
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	for i, bts := range series {
+		var points plotter.XYs
+		var y func(float64) float64
+		for _, bar := range timeseries.Resample(bts, interval).Data {
+			x, ok := index[bar.Timestamp.Unix()]
+			if !ok || bar.Close <= 0 {
+				continue
+			}
+			if y == nil {
+				y = priceScale(bar.Close, config)
+			}
+			points = append(points, plotter.XY{X: float64(x), Y: y(bar.Close)})
+		}
+		if len(points) == 0 {
+			return nil, fmt.Errorf("%s does not overlap %s", names[i], names[0])
+		}
+		if config.MaxPoints > 0 {
+			points = DownsampleLTTB(points, config.MaxPoints)
+		}
+
+		line, err := plotter.NewLine(points)
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = overlayColors[i%len(overlayColors)]
+		line.LineStyle.Width = config.LineWidth
+		p.Add(line)
+		if config.ShowLegend {
+			p.Legend.Add(names[i], line)
+		}
+	}
+
+	setPriceScale(p, config)
+	return renderPlot(p, config)
+}
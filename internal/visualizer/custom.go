@@ -0,0 +1,74 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// DrawCustomIndicatorChart plots a plugin indicator by bar, with markers
+// where its buy and sell rules became true
+func DrawCustomIndicatorChart(ci types.CustomIndicator, config ChartConfig) ([]byte, error) {
+	if len(ci.Values) == 0 {
+		return nil, fmt.Errorf("indicator %s has no values to plot", ci.Name)
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	points := makeChartXYs(ci.Values, config)
+	for i := range points {
+		points[i].X += float64(ci.Offset)
+	}
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		return nil, err
+	}
+	line.LineStyle.Color = color.RGBA{R: 111, G: 66, B: 193, A: 255}
+	line.LineStyle.Width = config.LineWidth
+	p.Add(line)
+	if config.ShowLegend {
+		p.Legend.Add(ci.Name, line)
+	}
+
+	for _, markers := range []struct {
+		bars  []int
+		color color.RGBA
+		label string
+	}{
+		{ci.Buys, color.RGBA{R: 40, G: 167, B: 69, A: 255}, "Buy rule"},
+		{ci.Sells, color.RGBA{R: 220, G: 53, B: 69, A: 255}, "Sell rule"},
+	} {
+		var xys plotter.XYs
+		for _, bar := range markers.bars {
+			if i := bar - ci.Offset; i >= 0 && i < len(ci.Values) {
+				xys = append(xys, plotter.XY{X: float64(bar), Y: ci.Values[i]})
+			}
+		}
+		if len(xys) == 0 {
+			continue
+		}
+		scatter, err := plotter.NewScatter(xys)
+		if err != nil {
+			return nil, err
+		}
+		scatter.GlyphStyle.Color = markers.color
+		scatter.GlyphStyle.Radius = vg.Points(4)
+		p.Add(scatter)
+		if config.ShowLegend {
+			p.Legend.Add(markers.label, scatter)
+		}
+	}
+
+	return renderPlot(p, config)
+}
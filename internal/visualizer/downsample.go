@@ -0,0 +1,62 @@
+package visualizer
+
+import (
+	"math"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+// DownsampleLTTB reduces points to threshold points with the
+// Largest-Triangle-Three-Buckets algorithm, which keeps the visual shape of
+// the series (peaks and troughs) far better than uniform sampling. Points are
+// expected in ascending X order; the input is never modified.
+func DownsampleLTTB(points plotter.XYs, threshold int) plotter.XYs {
+	n := len(points)
+	if threshold >= n || threshold < 3 {
+		return points
+	}
+
+	sampled := make(plotter.XYs, 0, threshold)
+	sampled = append(sampled, points[0])
+
+	// Every bucket except the first and last point
+	bucketSize := float64(n-2) / float64(threshold-2)
+	selected := 0
+
+	for bucket := 0; bucket < threshold-2; bucket++ {
+		// Average of the next bucket is the third triangle vertex
+		nextStart := int(math.Floor(float64(bucket+1)*bucketSize)) + 1
+		nextEnd := int(math.Floor(float64(bucket+2)*bucketSize)) + 1
+		if nextEnd > n {
+			nextEnd = n
+		}
+		avgX, avgY := 0.0, 0.0
+		for i := nextStart; i < nextEnd; i++ {
+			avgX += points[i].X
+			avgY += points[i].Y
+		}
+		count := float64(nextEnd - nextStart)
+		if count > 0 {
+			avgX /= count
+			avgY /= count
+		}
+
+		// Pick the point of the current bucket forming the largest triangle
+		start := int(math.Floor(float64(bucket)*bucketSize)) + 1
+		end := int(math.Floor(float64(bucket+1)*bucketSize)) + 1
+		anchor := points[selected]
+
+		maxArea := -1.0
+		for i := start; i < end; i++ {
+			area := math.Abs((anchor.X-avgX)*(points[i].Y-anchor.Y) - (anchor.X-points[i].X)*(avgY-anchor.Y))
+			if area > maxArea {
+				maxArea = area
+				selected = i
+			}
+		}
+
+		sampled = append(sampled, points[selected])
+	}
+
+	return append(sampled, points[n-1])
+}
@@ -0,0 +1,49 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/patterns"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot/vg"
+)
+
+// DrawSupportResistanceChart plots the close price with each scored level as
+// a horizontal line whose thickness is proportional to its strength, labeled
+// with its sources. Round numbers are gray and broken levels are dashed.
+func DrawSupportResistanceChart(bts *types.BTCTimeSeries, levels []types.SRLevel, config ChartConfig) ([]byte, error) {
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no support or resistance levels to plot")
+	}
+
+	p, y, err := newSwingPlot(bts, config)
+	if err != nil {
+		return nil, err
+	}
+
+	supportStyle := AnnotationStyle{Color: color.RGBA{R: 40, G: 167, B: 69, A: 255}, Legend: "Support (width = strength)"}
+	resistanceStyle := AnnotationStyle{Color: color.RGBA{R: 220, G: 53, B: 69, A: 255}, Legend: "Resistance (width = strength)"}
+	roundStyle := AnnotationStyle{Color: color.RGBA{R: 108, G: 117, B: 125, A: 255}, Legend: "Round number (width = strength)"}
+	var notes Annotations
+	notes.AddHalvings(barTimes(bts))
+	for _, level := range levels {
+		style := resistanceStyle
+		switch level.Type {
+		case "support":
+			style = supportStyle
+		case patterns.RoundLevelType:
+			style = roundStyle
+		}
+		style.Width = vg.Points(0.5 + 5*level.Score)
+		style.Dashed = level.Broken
+		notes.AddHLine(y(level.Price), level.Source, style)
+	}
+	notes.Draw(p, config)
+
+	setPriceScale(p, config)
+	return renderPlot(p, config)
+}
@@ -0,0 +1,120 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"image/color"
+	"slices"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// PriceIndicators are the indicators DrawPriceChart draws: Bollinger Bands
+// over the price, and RSI, MACD and volume in panes below it
+var PriceIndicators = []string{"bb", "rsi", "macd", "volume"}
+
+// DrawPriceChart plots the close price with the indicators of
+// PriceIndicators listed in indicators, which are aligned to end at the last
+// bar of bts. An indicator with no values is left out.
+func DrawPriceChart(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, indicators []string, config ChartConfig) ([]byte, error) {
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+	for _, name := range indicators {
+		if !slices.Contains(PriceIndicators, name) {
+			return nil, fmt.Errorf("unknown indicator %q", name)
+		}
+	}
+
+	bars := len(bts.Data)
+	price := newPane(PriceLabel(bts.Denomination, config), bars, config)
+	y := priceScale(bts.Data[0].Close, config)
+	scaled := func(values []float64) []float64 {
+		out := make([]float64, len(values))
+		for i, v := range values {
+			out[i] = y(v)
+		}
+		return out
+	}
+	closes := make([]float64, bars)
+	for i, bar := range bts.Data {
+		closes[i] = bar.Close
+	}
+
+	bands := analytics.BollingerBands
+	if slices.Contains(indicators, "bb") && len(bands.Middle) > 0 {
+		for _, band := range []struct {
+			label  string
+			values []float64
+			dashed bool
+		}{
+			{"Upper band", bands.Upper, false},
+			{"Middle band", bands.Middle, true},
+			{"Lower band", bands.Lower, false},
+		} {
+			line, err := plotter.NewLine(makeAlignedXYs(scaled(band.values), bars, config))
+			if err != nil {
+				return nil, err
+			}
+			line.LineStyle.Color = color.RGBA{R: 255, G: 140, B: 0, A: 255}
+			line.LineStyle.Width = vg.Points(1)
+			if band.dashed {
+				line.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(3)}
+			}
+			price.Add(line)
+			if config.ShowLegend {
+				price.Legend.Add(band.label, line)
+			}
+		}
+	}
+	closeLine, err := plotter.NewLine(makeChartXYs(scaled(closes), config))
+	if err != nil {
+		return nil, err
+	}
+	closeLine.LineStyle.Color = color.RGBA{R: 0, G: 100, B: 200, A: 255}
+	closeLine.LineStyle.Width = config.LineWidth
+	price.Add(closeLine)
+	if config.ShowLegend {
+		price.Legend.Add("Close", closeLine)
+	}
+	setPriceScale(price, config)
+
+	panes := []*plot.Plot{price}
+	if slices.Contains(indicators, "rsi") && len(analytics.RSI) > 0 {
+		p := newPane("RSI", bars, config)
+		if err := drawRSIPane(p, analytics.RSI, bars, config); err != nil {
+			return nil, err
+		}
+		panes = append(panes, p)
+	}
+	if slices.Contains(indicators, "macd") && len(analytics.MACD.MACD) > 0 {
+		p := newPane("MACD", bars, config)
+		if err := drawMACDPane(p, analytics.MACD, bars, config); err != nil {
+			return nil, err
+		}
+		panes = append(panes, p)
+	}
+	if slices.Contains(indicators, "volume") {
+		p := newPane("Volume", bars, config)
+		volumes := make(plotter.Values, bars)
+		for i, bar := range bts.Data {
+			volumes[i] = bar.Volume
+		}
+		volumeBars, err := plotter.NewBarChart(volumes, vg.Length(config.Width)*0.7/vg.Length(bars))
+		if err != nil {
+			return nil, err
+		}
+		volumeBars.Color = color.RGBA{R: 108, G: 117, B: 125, A: 255}
+		volumeBars.LineStyle.Width = 0
+		p.Add(volumeBars)
+		panes = append(panes, p)
+	}
+
+	price.Title.Text = config.Title
+	for _, p := range panes[:len(panes)-1] {
+		p.X.Label.Text = ""
+	}
+	return renderPlots(panes, config)
+}
@@ -0,0 +1,64 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// DrawRealPriceChart plots the nominal close against the close deflated by
+// the price index, with the total return of each in the legend. Normalized,
+// both lines start at 100.
+func DrawRealPriceChart(rp *types.RealPriceAnalysis, config ChartConfig) ([]byte, error) {
+	if rp == nil || len(rp.Points) == 0 {
+		return nil, fmt.Errorf("no real price data to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+	p.Legend.Top = true
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	nominals := make([]float64, len(rp.Points))
+	reals := make([]float64, len(rp.Points))
+	for i, point := range rp.Points {
+		nominals[i], reals[i] = point.Nominal, point.Real
+	}
+
+	lines := []struct {
+		label  string
+		values []float64
+		color  color.RGBA
+	}{
+		{fmt.Sprintf("Nominal (%s)", numfmt.SignedPct(rp.NominalReturn)), nominals, color.RGBA{R: 247, G: 147, B: 26, A: 255}},
+		{fmt.Sprintf("Real, %s dollars (%s)", rp.BaseDate.Format("2006-01"), numfmt.SignedPct(rp.RealReturn)), reals, color.RGBA{R: 0, G: 123, B: 255, A: 255}},
+	}
+	for _, l := range lines {
+		y := priceScale(l.values[0], config)
+		for i, v := range l.values {
+			l.values[i] = y(v)
+		}
+		line, err := plotter.NewLine(makeChartXYs(l.values, config))
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = l.color
+		line.LineStyle.Width = config.LineWidth
+		p.Add(line)
+		if config.ShowLegend {
+			p.Legend.Add(l.label, line)
+		}
+	}
+
+	setPriceScale(p, config)
+	return renderPlot(p, config)
+}
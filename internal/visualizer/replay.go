@@ -0,0 +1,87 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/types"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"math"
+	"time"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// DrawTradesReplay renders the trades chart of a strategy as an animated GIF
+// of frames frames, each adding bars up to the next of evenly spaced cut-offs
+// so that positions appear as they are taken and a held one shows its change
+// so far. The axes span the whole series in every frame. The last frame is
+// held for a few seconds before the animation loops.
+func DrawTradesReplay(bts *types.BTCTimeSeries, positions []float64, frames int, delay time.Duration, config ChartConfig) ([]byte, error) {
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+	if len(positions) != len(bts.Data) {
+		return nil, fmt.Errorf("%d positions for %d bars", len(positions), len(bts.Data))
+	}
+	if frames < 1 {
+		return nil, fmt.Errorf("a replay needs at least 1 frame")
+	}
+	frames = min(frames, len(bts.Data))
+
+	y := priceScale(bts.Data[0].Close, config)
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, bar := range bts.Data {
+		low, high = math.Min(low, y(bar.Close)), math.Max(high, y(bar.Close))
+	}
+
+	anim := &gif.GIF{}
+	indices := make(map[color.RGBA]uint8)
+	hundredths := max(int(delay/(10*time.Millisecond)), 1)
+	for f := 1; f <= frames; f++ {
+		end := f * len(bts.Data) / frames
+		window := &types.BTCTimeSeries{Symbol: bts.Symbol, Data: bts.Data[:end], Denomination: bts.Denomination}
+		p, err := tradesPlot(window, positions[:end], config)
+		if err != nil {
+			return nil, err
+		}
+		p.X.Min, p.X.Max = 0, float64(len(bts.Data)-1)
+		p.Y.Min, p.Y.Max = low, high
+		setPriceScale(p, config)
+
+		img := vgimg.New(vg.Length(config.Width), vg.Length(config.Height))
+		p.Draw(draw.New(img))
+		anim.Image = append(anim.Image, paletted(img.Image(), indices))
+		anim.Delay = append(anim.Delay, hundredths)
+	}
+	anim.Delay[len(anim.Delay)-1] = 300
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// paletted converts a rendered frame to the web-safe palette. Charts have
+// few distinct colors, so the palette index of each is looked up once and
+// kept in indices across frames.
+func paletted(img image.Image, indices map[color.RGBA]uint8) *image.Paletted {
+	frame := image.NewPaletted(img.Bounds(), palette.WebSafe)
+	for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+		for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			index, ok := indices[c]
+			if !ok {
+				index = uint8(frame.Palette.Index(c))
+				indices[c] = index
+			}
+			frame.SetColorIndex(x, y, index)
+		}
+	}
+	return frame
+}
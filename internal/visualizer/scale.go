@@ -0,0 +1,44 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/types"
+
+	"gonum.org/v1/plot"
+)
+
+// PriceLabel returns the Y axis label of a price chart in denomination d
+// under the scale options of config
+func PriceLabel(d *types.Denomination, config ChartConfig) string {
+	label := "Price (" + denom.Label(d)
+	if config.Normalize {
+		label = "Index (first point = 100"
+	}
+	if config.LogScale {
+		label += ", log scale"
+	}
+	return label + ")"
+}
+
+// priceScale returns the function placing the prices of a line starting at
+// first on the Y axis: rebased to 100 when config.Normalize, as is otherwise
+func priceScale(first float64, config ChartConfig) func(float64) float64 {
+	if !config.Normalize || first == 0 {
+		return func(v float64) float64 { return v }
+	}
+	return func(v float64) float64 { return v / first * 100 }
+}
+
+// setPriceScale switches the Y axis of a price chart to a log scale when
+// config asks for it. Call it once every plotter is added: an axis reaching
+// zero or below keeps the linear scale. Ticks mark powers of ten only on
+// axes spanning a decade or more, which would otherwise have few labels.
+func setPriceScale(p *plot.Plot, config ChartConfig) {
+	if !config.LogScale || p.Y.Min <= 0 {
+		return
+	}
+	p.Y.Scale = plot.LogScale{}
+	if p.Y.Max/p.Y.Min >= 10 {
+		p.Y.Tick.Marker = plot.LogTicks{Prec: -1}
+	}
+}
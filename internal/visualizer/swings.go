@@ -0,0 +1,171 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// overlayColors cycles through the colors used for swing-based overlays
+var overlayColors = []color.RGBA{
+	{R: 0, G: 123, B: 255, A: 255},
+	{R: 255, G: 140, B: 0, A: 255},
+	{R: 111, G: 66, B: 193, A: 255},
+}
+
+// newSwingPlot creates a plot with the close price as a thin background line.
+// It returns the scale of the close line, for prices drawn over it.
+func newSwingPlot(bts *types.BTCTimeSeries, config ChartConfig) (*plot.Plot, func(float64) float64, error) {
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = PriceLabel(bts.Denomination, config)
+	p.Legend.Top = true
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	y := priceScale(bts.Data[0].Close, config)
+	closes := make([]float64, len(bts.Data))
+	for i, bar := range bts.Data {
+		closes[i] = y(bar.Close)
+	}
+	priceLine, err := plotter.NewLine(makeChartXYs(closes, config))
+	if err != nil {
+		return nil, nil, err
+	}
+	priceLine.LineStyle.Color = color.RGBA{R: 150, G: 150, B: 150, A: 255}
+	priceLine.LineStyle.Width = vg.Points(1)
+	p.Add(priceLine)
+	if config.ShowLegend {
+		p.Legend.Add("Close", priceLine)
+	}
+
+	return p, y, nil
+}
+
+// barTimes returns the timestamps of the bars of bts, for event annotations
+func barTimes(bts *types.BTCTimeSeries) []time.Time {
+	times := make([]time.Time, len(bts.Data))
+	for i, bar := range bts.Data {
+		times[i] = bar.Timestamp
+	}
+	return times
+}
+
+// addLabeledPath draws a polyline through swing points and adds a label at
+// each point to notes, placing prices with y
+func addLabeledPath(p *plot.Plot, notes *Annotations, swings []types.SwingPoint, labels []string, pathColor color.RGBA, y func(float64) float64, config ChartConfig) (*plotter.Line, error) {
+	points := make(plotter.XYs, len(swings))
+	for i, swing := range swings {
+		points[i] = plotter.XY{X: float64(swing.Index), Y: y(swing.Price)}
+	}
+
+	line, scatter, err := plotter.NewLinePoints(points)
+	if err != nil {
+		return nil, err
+	}
+	line.LineStyle.Color = pathColor
+	line.LineStyle.Width = config.LineWidth
+	scatter.Color = pathColor
+	p.Add(line, scatter)
+
+	for i, point := range points {
+		notes.AddLabel(point.X, point.Y, labels[i], pathColor)
+	}
+
+	return line, nil
+}
+
+// DrawElliottWaveChart plots the close price with the candidate wave counts
+// overlaid and labeled, each count annotated with its confidence score
+func DrawElliottWaveChart(bts *types.BTCTimeSeries, elliott *types.ElliottAnalysis, config ChartConfig) ([]byte, error) {
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+	if elliott == nil || len(elliott.Counts) == 0 {
+		return nil, fmt.Errorf("no wave counts to plot")
+	}
+
+	p, y, err := newSwingPlot(bts, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes Annotations
+	notes.AddHalvings(barTimes(bts))
+	for i, count := range elliott.Counts {
+		line, err := addLabeledPath(p, &notes, count.Points, count.Labels, overlayColors[i%len(overlayColors)], y, config)
+		if err != nil {
+			return nil, err
+		}
+		if config.ShowLegend {
+			p.Legend.Add(fmt.Sprintf("%s %s (%.0f%%)", count.Direction, count.Kind, count.Confidence*100), line)
+		}
+	}
+	notes.Draw(p, config)
+
+	setPriceScale(p, config)
+	return renderPlot(p, config)
+}
+
+// DrawHarmonicChart plots the most recent harmonic patterns as labeled XABCD
+// paths, with the potential reversal zone of each shaded
+func DrawHarmonicChart(bts *types.BTCTimeSeries, harmonics []types.HarmonicPattern, config ChartConfig) ([]byte, error) {
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+	if len(harmonics) == 0 {
+		return nil, fmt.Errorf("no harmonic patterns to plot")
+	}
+
+	p, y, err := newSwingPlot(bts, config)
+	if err != nil {
+		return nil, err
+	}
+
+	recent := harmonics
+	if len(recent) > len(overlayColors) {
+		recent = recent[len(recent)-len(overlayColors):]
+	}
+
+	var notes Annotations
+	notes.AddHalvings(barTimes(bts))
+	for i, pattern := range recent {
+		patternColor := overlayColors[i%len(overlayColors)]
+		labels := []string{"X", "A", "B", "C", "D"}[:len(pattern.Points)]
+
+		line, err := addLabeledPath(p, &notes, pattern.Points, labels, patternColor, y, config)
+		if err != nil {
+			return nil, err
+		}
+
+		// PRZ spans from C past D, or to the end of the chart while pending
+		start := float64(pattern.Points[3].Index)
+		end := float64(len(bts.Data) - 1)
+		if pattern.Complete {
+			d := pattern.Points[4].Index
+			end = math.Min(end, float64(2*d-pattern.Points[3].Index))
+		}
+		notes.AddRegion(start, end, y(pattern.PRZLow), y(pattern.PRZHigh), AnnotationStyle{Color: patternColor})
+
+		if config.ShowLegend {
+			status := "pending"
+			if pattern.Complete {
+				status = "complete"
+			}
+			p.Legend.Add(fmt.Sprintf("%s %s (%s)", pattern.Direction, pattern.Name, status), line)
+		}
+	}
+	notes.Draw(p, config)
+
+	setPriceScale(p, config)
+	return renderPlot(p, config)
+}
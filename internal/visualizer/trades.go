@@ -0,0 +1,68 @@
+package visualizer
+
+import (
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+)
+
+// DrawTradesChart plots the close price with each holding period of a
+// strategy's positions shaded and labeled at its exit with the price change
+// over it. A position is held from the close it is taken at to the close it
+// is dropped at, or to the last bar while still open.
+func DrawTradesChart(bts *types.BTCTimeSeries, positions []float64, config ChartConfig) ([]byte, error) {
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+	if len(positions) != len(bts.Data) {
+		return nil, fmt.Errorf("%d positions for %d bars", len(positions), len(bts.Data))
+	}
+
+	p, err := tradesPlot(bts, positions, config)
+	if err != nil {
+		return nil, err
+	}
+
+	setPriceScale(p, config)
+	return renderPlot(p, config)
+}
+
+// tradesPlot creates the plot of DrawTradesChart
+func tradesPlot(bts *types.BTCTimeSeries, positions []float64, config ChartConfig) (*plot.Plot, error) {
+	p, y, err := newSwingPlot(bts, config)
+	if err != nil {
+		return nil, err
+	}
+
+	long := AnnotationStyle{Color: color.RGBA{R: 40, G: 167, B: 69, A: 255}, Legend: "Long"}
+	win := color.RGBA{R: 40, G: 167, B: 69, A: 255}
+	loss := color.RGBA{R: 220, G: 53, B: 69, A: 255}
+	var notes Annotations
+	notes.AddHalvings(barTimes(bts))
+	entry := -1
+	for i := range positions {
+		if positions[i] > 0 && entry < 0 {
+			entry = i
+		}
+		last := i == len(positions)-1
+		if entry < 0 || (positions[i] > 0 && !last) {
+			continue
+		}
+		if entry < i {
+			change := bts.Data[i].Close/bts.Data[entry].Close - 1
+			labelColor := win
+			if change < 0 {
+				labelColor = loss
+			}
+			notes.AddRegion(float64(entry), float64(i), math.Inf(-1), math.Inf(1), long)
+			notes.AddLabel(float64(i), y(bts.Data[i].Close), numfmt.SignedPct(change), labelColor)
+		}
+		entry = -1
+	}
+	notes.Draw(p, config)
+	return p, nil
+}
@@ -1,162 +1,921 @@
-package visualizer
-
-import (
-	"btc-analyzer/internal/types"
-	"fmt"
-	"image/color"
-
-	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/vg"
-)
-
-// ChartConfig holds configuration for chart generation
-type ChartConfig struct {
-	Width       int
-	Height      int
-	Title       string
-	XLabel      string
-	YLabel      string
-	ShowGrid    bool
-	ShowLegend  bool
-	LineWidth   vg.Length
-	FontSize    vg.Length
-	Theme       string
-}
-
-// DefaultChartConfig returns default chart configuration
-func DefaultChartConfig() ChartConfig {
-	return ChartConfig{
-		Width:      1000,
-		Height:     600,
-		Title:      "Bitcoin Technical Indicators",
-		XLabel:     "Time",
-		YLabel:     "Value",
-		ShowGrid:   true,
-		ShowLegend: true,
-		LineWidth:  vg.Points(2),
-		FontSize:   vg.Points(12),
-		Theme:      "default",
-	}
-}
-
-// writeBuffer implements io.Writer for byte slice
-type writeBuffer struct {
-	buf *[]byte
-}
-
-func (wb *writeBuffer) Write(p []byte) (n int, err error) {
-	*wb.buf = append(*wb.buf, p...)
-	return len(p), nil
-}
-
-// DrawTechnicalIndicatorsChart creates a chart with RSI and MACD indicators
-func DrawTechnicalIndicatorsChart(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, config ChartConfig) ([]byte, error) {
-	if len(bts.Data) == 0 {
-		return nil, fmt.Errorf("no data to plot")
-	}
-
-	p := plot.New()
-	p.Title.Text = config.Title
-	p.X.Label.Text = config.XLabel
-	p.Y.Label.Text = config.YLabel
-
-	// Add grid
-	if config.ShowGrid {
-		p.Add(plotter.NewGrid())
-	}
-
-	// Plot RSI if available (0-100 scale)
-	if len(analytics.RSI) > 0 {
-		rsiLine, err := plotter.NewLine(makeSimpleXYs(analytics.RSI))
-		if err == nil {
-			rsiLine.LineStyle.Color = color.RGBA{R: 150, G: 0, B: 150, A: 255}
-			rsiLine.LineStyle.Width = config.LineWidth
-			p.Add(rsiLine)
-			
-			if config.ShowLegend {
-				p.Legend.Add("RSI", rsiLine)
-			}
-		}
-	}
-
-	// Plot MACD if available (scaled to fit with RSI)
-	if len(analytics.MACD.MACD) > 0 {
-		// Scale MACD to 0-100 range to match RSI
-		scaledMACD := make([]float64, len(analytics.MACD.MACD))
-		for i, val := range analytics.MACD.MACD {
-			scaledMACD[i] = (val * 10) + 50 // Scale and shift to 0-100 range
-		}
-		
-		macdLine, err := plotter.NewLine(makeSimpleXYs(scaledMACD))
-		if err == nil {
-			macdLine.LineStyle.Color = color.RGBA{R: 0, G: 100, B: 200, A: 255}
-			macdLine.LineStyle.Width = config.LineWidth
-			p.Add(macdLine)
-			
-			if config.ShowLegend {
-				p.Legend.Add("MACD (scaled)", macdLine)
-			}
-		}
-	}
-
-	// Add RSI reference lines at 30 and 70
-	if len(analytics.RSI) > 0 {
-		// Oversold line at 30
-		oversoldLine, _ := plotter.NewLine(plotter.XYs{
-			{X: 0, Y: 30},
-			{X: float64(len(analytics.RSI)), Y: 30},
-		})
-		oversoldLine.LineStyle.Color = color.RGBA{R: 255, G: 0, B: 0, A: 100}
-		oversoldLine.LineStyle.Dashes = []vg.Length{vg.Points(5), vg.Points(5)}
-		oversoldLine.LineStyle.Width = vg.Points(1)
-		p.Add(oversoldLine)
-
-		// Overbought line at 70
-		overboughtLine, _ := plotter.NewLine(plotter.XYs{
-			{X: 0, Y: 70},
-			{X: float64(len(analytics.RSI)), Y: 70},
-		})
-		overboughtLine.LineStyle.Color = color.RGBA{R: 255, G: 0, B: 0, A: 100}
-		overboughtLine.LineStyle.Dashes = []vg.Length{vg.Points(5), vg.Points(5)}
-		overboughtLine.LineStyle.Width = vg.Points(1)
-		p.Add(overboughtLine)
-
-		if config.ShowLegend {
-			p.Legend.Add("RSI 30/70", oversoldLine)
-		}
-	}
-
-	return renderPlot(p, config)
-}
-
-// Helper function to create simple XY points
-func makeSimpleXYs(values []float64) plotter.XYs {
-	points := make(plotter.XYs, len(values))
-	for i, v := range values {
-		points[i].X = float64(i)
-		points[i].Y = v
-	}
-	return points
-}
-
-// Helper function to render plot to bytes
-func renderPlot(p *plot.Plot, config ChartConfig) ([]byte, error) {
-	w, err := p.WriterTo(vg.Length(config.Width), vg.Length(config.Height), "png")
-	if err != nil {
-		return nil, err
-	}
-
-	var buf []byte
-	buf = make([]byte, 0)
-	_, err = w.WriteTo(&writeBuffer{buf: &buf})
-	return buf, err
-}
-
-// GenerateIndicatorChart creates just the technical indicators chart
-func GenerateIndicatorChart(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) ([]byte, error) {
-	config := DefaultChartConfig()
-	config.Title = "Bitcoin Technical Indicators (RSI & MACD)"
-	
-	return DrawTechnicalIndicatorsChart(bts, analytics, config)
-}
\ No newline at end of file
+package visualizer
+
+import (
+	"btc-analyzer/internal/types"
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+	"gonum.org/v1/plot/vg/vgsvg"
+)
+
+// ChartConfig holds configuration for chart generation
+type ChartConfig struct {
+	Width      int
+	Height     int
+	Title      string
+	XLabel     string
+	YLabel     string
+	ShowGrid   bool
+	ShowLegend bool
+	LineWidth  vg.Length
+	FontSize   vg.Length
+	Theme      string
+	MaxPoints  int    // lines with more points are downsampled (LTTB); 0 disables
+	LogScale   bool   // price charts: logarithmic Y axis
+	Normalize  bool   // price charts: each price line rebased to 100 at its first point
+	Format     string // "png" (default) or "svg"
+}
+
+// DefaultChartConfig returns default chart configuration
+func DefaultChartConfig() ChartConfig {
+	return ChartConfig{
+		Width:      1000,
+		Height:     600,
+		Title:      "Bitcoin Technical Indicators",
+		XLabel:     "Time",
+		YLabel:     "Value",
+		ShowGrid:   true,
+		ShowLegend: true,
+		LineWidth:  vg.Points(2),
+		FontSize:   vg.Points(12),
+		Theme:      "default",
+		MaxPoints:  1000,
+	}
+}
+
+// writeBuffer implements io.Writer for byte slice
+type writeBuffer struct {
+	buf *[]byte
+}
+
+func (wb *writeBuffer) Write(p []byte) (n int, err error) {
+	*wb.buf = append(*wb.buf, p...)
+	return len(p), nil
+}
+
+// DrawTechnicalIndicatorsChart creates a chart with RSI and MACD indicators,
+// each in its own pane over the same bars: RSI on a 0-100 axis with the
+// overbought and oversold zones shaded, MACD and its signal line over the
+// histogram as bars around a zero line. A series too short for both, whose
+// indicators were skipped, gets a placeholder pane saying so.
+func DrawTechnicalIndicatorsChart(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, config ChartConfig) ([]byte, error) {
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data to plot")
+	}
+
+	bars := len(bts.Data)
+	if len(analytics.RSI) == 0 && len(analytics.MACD.MACD) == 0 {
+		p, err := placeholderPane(fmt.Sprintf("Too few bars for RSI and MACD (%d in the series)", bars), config)
+		if err != nil {
+			return nil, err
+		}
+		return renderPlots([]*plot.Plot{p}, config)
+	}
+
+	var panes []*plot.Plot
+	if len(analytics.RSI) > 0 {
+		p := newPane("RSI", bars, config)
+		if err := drawRSIPane(p, analytics.RSI, bars, config); err != nil {
+			return nil, err
+		}
+		panes = append(panes, p)
+	}
+	if len(analytics.MACD.MACD) > 0 {
+		p := newPane("MACD", bars, config)
+		if err := drawMACDPane(p, analytics.MACD, bars, config); err != nil {
+			return nil, err
+		}
+		panes = append(panes, p)
+	}
+
+	panes[0].Title.Text = config.Title
+	for _, p := range panes[:len(panes)-1] {
+		p.X.Label.Text = ""
+	}
+	return renderPlots(panes, config)
+}
+
+// placeholderPane creates a pane without axes showing message in its middle
+func placeholderPane(message string, config ChartConfig) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.HideAxes()
+	p.X.Min, p.X.Max = 0, 1
+	p.Y.Min, p.Y.Max = 0, 1
+	labels, err := plotter.NewLabels(plotter.XYLabels{XYs: plotter.XYs{{X: 0.5, Y: 0.5}}, Labels: []string{message}})
+	if err != nil {
+		return nil, err
+	}
+	labels.TextStyle[0].Font.Size = config.FontSize
+	labels.TextStyle[0].XAlign = draw.XCenter
+	labels.TextStyle[0].YAlign = draw.YCenter
+	p.Add(labels)
+	return p, nil
+}
+
+// newPane creates a pane of a chart of stacked panes over bars bars
+func newPane(yLabel string, bars int, config ChartConfig) *plot.Plot {
+	p := plot.New()
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = yLabel
+	p.X.Min, p.X.Max = 0, float64(bars-1)
+	p.Legend.Top = true
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+	return p
+}
+
+// drawRSIPane draws rsi, ending at the last of bars bars, on a 0-100 axis
+// with the overbought and oversold zones shaded
+func drawRSIPane(p *plot.Plot, rsi []float64, bars int, config ChartConfig) error {
+	p.Y.Min, p.Y.Max = 0, 100
+
+	zone := color.RGBA{R: 220, G: 53, B: 69, A: 255}
+	var notes Annotations
+	notes.AddRegion(math.Inf(-1), math.Inf(1), 70, 100, AnnotationStyle{Color: zone, Legend: "Overbought (> 70)"})
+	notes.AddRegion(math.Inf(-1), math.Inf(1), 0, 30, AnnotationStyle{Color: color.RGBA{R: 40, G: 167, B: 69, A: 255}, Legend: "Oversold (< 30)"})
+	notes.Draw(p, config)
+
+	rsiLine, err := plotter.NewLine(makeAlignedXYs(rsi, bars, config))
+	if err != nil {
+		return err
+	}
+	rsiLine.LineStyle.Color = color.RGBA{R: 150, G: 0, B: 150, A: 255}
+	rsiLine.LineStyle.Width = config.LineWidth
+	p.Add(rsiLine)
+	if config.ShowLegend {
+		p.Legend.Add("RSI", rsiLine)
+	}
+	return nil
+}
+
+// drawMACDPane draws the MACD and its signal line over the histogram as bars
+// around a zero line, ending at the last of bars bars
+func drawMACDPane(p *plot.Plot, macd types.MACDData, bars int, config ChartConfig) error {
+	// Rising and falling histogram bars are separate charts to color
+	// them; each is zero where the other has the bar
+	histogram := macd.Histogram
+	positive := make(plotter.Values, len(histogram))
+	negative := make(plotter.Values, len(histogram))
+	for i, v := range histogram {
+		if v >= 0 {
+			positive[i] = v
+		} else {
+			negative[i] = v
+		}
+	}
+	barWidth := vg.Length(config.Width) * 0.7 / vg.Length(bars)
+	for _, side := range []struct {
+		values plotter.Values
+		color  color.RGBA
+	}{
+		{positive, color.RGBA{R: 120, G: 200, B: 140, A: 255}},
+		{negative, color.RGBA{R: 235, G: 140, B: 150, A: 255}},
+	} {
+		if len(side.values) == 0 {
+			continue
+		}
+		histBars, err := plotter.NewBarChart(side.values, barWidth)
+		if err != nil {
+			return err
+		}
+		histBars.XMin = float64(bars - len(side.values))
+		histBars.Color = side.color
+		histBars.LineStyle.Width = 0
+		p.Add(histBars)
+	}
+
+	var notes Annotations
+	notes.AddHLine(0, "", AnnotationStyle{Color: color.RGBA{R: 120, G: 120, B: 120, A: 255}})
+	notes.Draw(p, config)
+
+	for _, l := range []struct {
+		label  string
+		values []float64
+		color  color.RGBA
+	}{
+		{"MACD", macd.MACD, color.RGBA{R: 0, G: 100, B: 200, A: 255}},
+		{"Signal", macd.Signal, color.RGBA{R: 255, G: 140, B: 0, A: 255}},
+	} {
+		if len(l.values) == 0 {
+			continue
+		}
+		line, err := plotter.NewLine(makeAlignedXYs(l.values, bars, config))
+		if err != nil {
+			return err
+		}
+		line.LineStyle.Color = l.color
+		line.LineStyle.Width = config.LineWidth
+		p.Add(line)
+		if config.ShowLegend {
+			p.Legend.Add(l.label, line)
+		}
+	}
+	return nil
+}
+
+// makeAlignedXYs creates XY points for indicator values that end at the last
+// of bars bars, downsampled to the configured point budget
+func makeAlignedXYs(values []float64, bars int, config ChartConfig) plotter.XYs {
+	points := makeSimpleXYs(values)
+	offset := float64(bars - len(values))
+	for i := range points {
+		points[i].X += offset
+	}
+	if config.MaxPoints > 0 {
+		return DownsampleLTTB(points, config.MaxPoints)
+	}
+	return points
+}
+
+// Helper function to create simple XY points
+func makeSimpleXYs(values []float64) plotter.XYs {
+	points := make(plotter.XYs, len(values))
+	for i, v := range values {
+		points[i].X = float64(i)
+		points[i].Y = v
+	}
+	return points
+}
+
+// makeChartXYs creates XY points, downsampled to the configured point budget
+func makeChartXYs(values []float64, config ChartConfig) plotter.XYs {
+	points := makeSimpleXYs(values)
+	if config.MaxPoints > 0 {
+		return DownsampleLTTB(points, config.MaxPoints)
+	}
+	return points
+}
+
+// Helper function to render plot to bytes
+func renderPlot(p *plot.Plot, config ChartConfig) ([]byte, error) {
+	w, err := p.WriterTo(vg.Length(config.Width), vg.Length(config.Height), chartFormat(config))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	buf = make([]byte, 0)
+	_, err = w.WriteTo(&writeBuffer{buf: &buf})
+	return buf, err
+}
+
+// renderPlots renders plots stacked top to bottom in one image, their data
+// areas aligned so that they share the X axis
+func renderPlots(plots []*plot.Plot, config ChartConfig) ([]byte, error) {
+	var canvas vg.CanvasWriterTo = vgimg.PngCanvas{Canvas: vgimg.New(vg.Length(config.Width), vg.Length(config.Height))}
+	if chartFormat(config) == "svg" {
+		canvas = vgsvg.New(vg.Length(config.Width), vg.Length(config.Height))
+	}
+	dc := draw.New(canvas)
+	tiles := draw.Tiles{Rows: len(plots), Cols: 1, PadX: vg.Points(4), PadY: vg.Points(4)}
+
+	grid := make([][]*plot.Plot, len(plots))
+	for i, p := range plots {
+		grid[i] = []*plot.Plot{p}
+	}
+	canvases := plot.Align(grid, tiles, dc)
+	for i, p := range plots {
+		p.Draw(canvases[i][0])
+	}
+
+	var buf []byte
+	_, err := canvas.WriteTo(&writeBuffer{buf: &buf})
+	return buf, err
+}
+
+// chartFormat returns the image format of config, png unless svg
+func chartFormat(config ChartConfig) string {
+	if config.Format == "svg" {
+		return "svg"
+	}
+	return "png"
+}
+
+// GenerateIndicatorChart creates just the technical indicators chart
+func GenerateIndicatorChart(bts *types.BTCTimeSeries, analytics types.BTCAnalytics) ([]byte, error) {
+	config := DefaultChartConfig()
+	config.Title = "Bitcoin Technical Indicators (RSI & MACD)"
+	
+	return DrawTechnicalIndicatorsChart(bts, analytics, config)
+}
+// DrawDepthChart creates a cumulative depth chart for an order book snapshot
+func DrawDepthChart(book *types.OrderBook, metrics types.OrderBookMetrics, config ChartConfig) ([]byte, error) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return nil, fmt.Errorf("order book is empty")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = "Price"
+	p.Y.Label.Text = "Cumulative Quantity"
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	lower := metrics.MidPrice * (1 - metrics.DepthBand)
+	upper := metrics.MidPrice * (1 + metrics.DepthBand)
+
+	sides := []struct {
+		name   string
+		levels []types.OrderBookLevel
+		color  color.RGBA
+	}{
+		{"Bids", book.Bids, color.RGBA{R: 40, G: 167, B: 69, A: 255}},
+		{"Asks", book.Asks, color.RGBA{R: 220, G: 53, B: 69, A: 255}},
+	}
+
+	for _, side := range sides {
+		var points plotter.XYs
+		total := 0.0
+		for _, level := range side.levels {
+			if level.Price < lower || level.Price > upper {
+				continue
+			}
+			total += level.Quantity
+			points = append(points, plotter.XY{X: level.Price, Y: total})
+		}
+		if len(points) == 0 {
+			continue
+		}
+		if config.MaxPoints > 0 {
+			points = DownsampleLTTB(points, config.MaxPoints)
+		}
+
+		line, err := plotter.NewLine(points)
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = side.color
+		line.LineStyle.Width = config.LineWidth
+		p.Add(line)
+
+		if config.ShowLegend {
+			p.Legend.Add(side.name, line)
+		}
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawOrderFlowChart creates a chart pane with per-candle volume delta bars
+// and the cumulative volume delta line
+func DrawOrderFlowChart(flow *types.OrderFlowData, config ChartConfig) ([]byte, error) {
+	if flow == nil || len(flow.CVD) == 0 {
+		return nil, fmt.Errorf("no order flow data to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = "Volume Delta"
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	bars, err := plotter.NewBarChart(plotter.Values(flow.Delta), vg.Points(2))
+	if err != nil {
+		return nil, err
+	}
+	bars.Color = color.RGBA{R: 120, G: 120, B: 200, A: 180}
+	bars.LineStyle.Width = 0
+	p.Add(bars)
+
+	cvdLine, err := plotter.NewLine(makeChartXYs(flow.CVD, config))
+	if err != nil {
+		return nil, err
+	}
+	cvdLine.LineStyle.Color = color.RGBA{R: 255, G: 140, B: 0, A: 255}
+	cvdLine.LineStyle.Width = config.LineWidth
+	p.Add(cvdLine)
+
+	if config.ShowLegend {
+		p.Legend.Add("Delta", bars)
+		p.Legend.Add("CVD", cvdLine)
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawPremiumChart plots the premium over the reference in percent, marking
+// the bars that entered an extreme premium or discount
+func DrawPremiumChart(premium *types.PremiumAnalysis, config ChartConfig) ([]byte, error) {
+	if premium == nil || len(premium.Points) == 0 {
+		return nil, fmt.Errorf("no premium data to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	values := make([]float64, len(premium.Points))
+	indexByTime := make(map[int64]int, len(premium.Points))
+	for i, point := range premium.Points {
+		values[i] = point.Premium * 100
+		indexByTime[point.Timestamp.UnixNano()] = i
+	}
+
+	premiumLine, err := plotter.NewLine(makeChartXYs(values, config))
+	if err != nil {
+		return nil, err
+	}
+	premiumLine.LineStyle.Color = color.RGBA{R: 70, G: 130, B: 180, A: 255}
+	premiumLine.LineStyle.Width = config.LineWidth
+	p.Add(premiumLine)
+
+	zero, err := plotter.NewLine(plotter.XYs{{X: 0, Y: 0}, {X: float64(len(values) - 1), Y: 0}})
+	if err != nil {
+		return nil, err
+	}
+	zero.LineStyle.Color = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+	zero.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+	p.Add(zero)
+
+	var premiumAlerts, discountAlerts plotter.XYs
+	for _, alert := range premium.Alerts {
+		i := indexByTime[alert.Timestamp.UnixNano()]
+		if alert.ZScore > 0 {
+			premiumAlerts = append(premiumAlerts, plotter.XY{X: float64(i), Y: values[i]})
+		} else {
+			discountAlerts = append(discountAlerts, plotter.XY{X: float64(i), Y: values[i]})
+		}
+	}
+	for _, alerts := range []struct {
+		points plotter.XYs
+		color  color.RGBA
+		label  string
+	}{
+		{premiumAlerts, color.RGBA{R: 220, G: 53, B: 69, A: 255}, "Extreme premium"},
+		{discountAlerts, color.RGBA{R: 40, G: 167, B: 69, A: 255}, "Extreme discount"},
+	} {
+		if len(alerts.points) == 0 {
+			continue
+		}
+		scatter, err := plotter.NewScatter(alerts.points)
+		if err != nil {
+			return nil, err
+		}
+		scatter.GlyphStyle.Color = alerts.color
+		scatter.GlyphStyle.Radius = vg.Points(4)
+		p.Add(scatter)
+		if config.ShowLegend {
+			p.Legend.Add(alerts.label, scatter)
+		}
+	}
+
+	if config.ShowLegend {
+		p.Legend.Add("Premium", premiumLine)
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawVolatilityChart plots implied against realized volatility in percent
+func DrawVolatilityChart(va *types.VolatilityAnalysis, config ChartConfig) ([]byte, error) {
+	if va == nil || len(va.History) == 0 {
+		return nil, fmt.Errorf("no volatility data to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	implied := make([]float64, len(va.History))
+	realized := make([]float64, len(va.History))
+	for i, point := range va.History {
+		implied[i] = point.Implied * 100
+		realized[i] = point.Realized * 100
+	}
+
+	impliedLine, err := plotter.NewLine(makeChartXYs(implied, config))
+	if err != nil {
+		return nil, err
+	}
+	impliedLine.LineStyle.Color = color.RGBA{R: 128, G: 0, B: 128, A: 255}
+	impliedLine.LineStyle.Width = config.LineWidth
+	p.Add(impliedLine)
+
+	realizedLine, err := plotter.NewLine(makeChartXYs(realized, config))
+	if err != nil {
+		return nil, err
+	}
+	realizedLine.LineStyle.Color = color.RGBA{R: 255, G: 140, B: 0, A: 255}
+	realizedLine.LineStyle.Width = config.LineWidth
+	p.Add(realizedLine)
+
+	if config.ShowLegend {
+		p.Legend.Add("Implied (DVOL)", impliedLine)
+		p.Legend.Add(fmt.Sprintf("Realized (%d-day)", va.WindowDays), realizedLine)
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawCorrelogram plots the correlation of each lead/lag analysis against the
+// lag, with the widest 95% confidence band of the analyses dashed
+func DrawCorrelogram(analyses []types.LeadLagAnalysis, config ChartConfig) ([]byte, error) {
+	if len(analyses) == 0 {
+		return nil, fmt.Errorf("no correlations to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	colors := []color.RGBA{
+		{R: 70, G: 130, B: 180, A: 255},
+		{R: 255, G: 140, B: 0, A: 255},
+		{R: 40, G: 167, B: 69, A: 255},
+		{R: 128, G: 0, B: 128, A: 255},
+	}
+	band := 0.0
+	minLag, maxLag := 0, 0
+	for i, analysis := range analyses {
+		points := make(plotter.XYs, len(analysis.Correlations))
+		for j, lc := range analysis.Correlations {
+			points[j] = plotter.XY{X: float64(lc.Lag), Y: lc.Correlation}
+			if lc.Lag < minLag {
+				minLag = lc.Lag
+			}
+			if lc.Lag > maxLag {
+				maxLag = lc.Lag
+			}
+		}
+		line, scatter, err := plotter.NewLinePoints(points)
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = colors[i%len(colors)]
+		line.LineStyle.Width = config.LineWidth
+		scatter.GlyphStyle.Color = colors[i%len(colors)]
+		p.Add(line, scatter)
+		if config.ShowLegend {
+			target := analysis.Target
+			if target == "" {
+				target = "returns"
+			}
+			p.Legend.Add(fmt.Sprintf("%s vs %s", analysis.Series, target), line)
+		}
+		if analysis.ConfidenceBand > band {
+			band = analysis.ConfidenceBand
+		}
+	}
+
+	if band > 0 {
+		for _, y := range []float64{band, -band} {
+			bound, err := plotter.NewLine(plotter.XYs{{X: float64(minLag), Y: y}, {X: float64(maxLag), Y: y}})
+			if err != nil {
+				return nil, err
+			}
+			bound.LineStyle.Color = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+			bound.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+			p.Add(bound)
+			if config.ShowLegend && y > 0 {
+				p.Legend.Add("95% confidence band", bound)
+			}
+		}
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawRollingBetaChart plots the rolling beta against the benchmark, with the
+// full-sample beta dashed
+func DrawRollingBetaChart(ba *types.BetaAnalysis, config ChartConfig) ([]byte, error) {
+	if ba == nil || len(ba.Rolling) == 0 {
+		return nil, fmt.Errorf("no rolling beta to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	values := make([]float64, len(ba.Rolling))
+	for i, point := range ba.Rolling {
+		values[i] = point.Beta
+	}
+
+	rollingLine, err := plotter.NewLine(makeChartXYs(values, config))
+	if err != nil {
+		return nil, err
+	}
+	rollingLine.LineStyle.Color = color.RGBA{R: 70, G: 130, B: 180, A: 255}
+	rollingLine.LineStyle.Width = config.LineWidth
+	p.Add(rollingLine)
+
+	overall, err := plotter.NewLine(plotter.XYs{{X: 0, Y: ba.Beta}, {X: float64(len(values) - 1), Y: ba.Beta}})
+	if err != nil {
+		return nil, err
+	}
+	overall.LineStyle.Color = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+	overall.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+	p.Add(overall)
+
+	if config.ShowLegend {
+		p.Legend.Add(fmt.Sprintf("Rolling %d-return beta", ba.Window), rollingLine)
+		p.Legend.Add("Full-sample beta", overall)
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawHeatChart plots the market heat index with its historical extreme
+// bands dashed
+func DrawHeatChart(heat *types.HeatIndex, config ChartConfig) ([]byte, error) {
+	if heat == nil || len(heat.Points) == 0 {
+		return nil, fmt.Errorf("no heat index to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	values := make([]float64, len(heat.Points))
+	for i, point := range heat.Points {
+		values[i] = point.Value
+	}
+
+	heatLine, err := plotter.NewLine(makeChartXYs(values, config))
+	if err != nil {
+		return nil, err
+	}
+	heatLine.LineStyle.Color = color.RGBA{R: 220, G: 80, B: 40, A: 255}
+	heatLine.LineStyle.Width = config.LineWidth
+	p.Add(heatLine)
+
+	last := float64(len(values) - 1)
+	bands := []struct {
+		label string
+		value float64
+		color color.RGBA
+	}{
+		{"Overheated (95th pct)", heat.UpperBand, color.RGBA{R: 200, G: 0, B: 0, A: 255}},
+		{"Cold (5th pct)", heat.LowerBand, color.RGBA{R: 0, G: 90, B: 200, A: 255}},
+	}
+	for _, band := range bands {
+		line, err := plotter.NewLine(plotter.XYs{{X: 0, Y: band.value}, {X: last, Y: band.value}})
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = band.color
+		line.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+		p.Add(line)
+		if config.ShowLegend {
+			p.Legend.Add(band.label, line)
+		}
+	}
+
+	if config.ShowLegend {
+		p.Legend.Add("Heat index", heatLine)
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawStockToFlowChart plots the price against the stock-to-flow model
+// price on a log scale, with the halvings marked
+func DrawStockToFlowChart(model *types.StockToFlowModel, config ChartConfig) ([]byte, error) {
+	if model == nil || len(model.Points) == 0 {
+		return nil, fmt.Errorf("no stock-to-flow data to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+	p.Y.Scale = plot.LogScale{}
+	p.Y.Tick.Marker = plot.LogTicks{Prec: -1}
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	prices := make([]float64, len(model.Points))
+	modelPrices := make([]float64, len(model.Points))
+	for i, point := range model.Points {
+		prices[i] = point.Price
+		modelPrices[i] = point.ModelPrice
+	}
+
+	priceLine, err := plotter.NewLine(makeChartXYs(prices, config))
+	if err != nil {
+		return nil, err
+	}
+	priceLine.LineStyle.Color = color.RGBA{R: 247, G: 147, B: 26, A: 255}
+	priceLine.LineStyle.Width = config.LineWidth
+	p.Add(priceLine)
+
+	modelLine, err := plotter.NewLine(makeChartXYs(modelPrices, config))
+	if err != nil {
+		return nil, err
+	}
+	modelLine.LineStyle.Color = color.RGBA{R: 90, G: 90, B: 90, A: 255}
+	modelLine.LineStyle.Width = config.LineWidth
+	modelLine.LineStyle.Dashes = []vg.Length{vg.Points(6), vg.Points(3)}
+	p.Add(modelLine)
+
+	if config.ShowLegend {
+		p.Legend.Add("Price", priceLine)
+		p.Legend.Add("S2F model (reference)", modelLine)
+	}
+
+	times := make([]time.Time, len(model.Points))
+	for i, point := range model.Points {
+		times[i] = point.Timestamp
+	}
+	var notes Annotations
+	notes.AddHalvings(times)
+	notes.Draw(p, config)
+
+	return renderPlot(p, config)
+}
+
+// DrawStockToFlowResiduals plots the log residual of the price against the
+// stock-to-flow model, with the mean and one standard deviation dashed
+func DrawStockToFlowResiduals(model *types.StockToFlowModel, config ChartConfig) ([]byte, error) {
+	if model == nil || len(model.Points) == 0 {
+		return nil, fmt.Errorf("no stock-to-flow data to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	residuals := make([]float64, len(model.Points))
+	for i, point := range model.Points {
+		residuals[i] = point.Residual
+	}
+
+	residualLine, err := plotter.NewLine(makeChartXYs(residuals, config))
+	if err != nil {
+		return nil, err
+	}
+	residualLine.LineStyle.Color = color.RGBA{R: 70, G: 130, B: 180, A: 255}
+	residualLine.LineStyle.Width = config.LineWidth
+	p.Add(residualLine)
+
+	last := float64(len(residuals) - 1)
+	for i, level := range []float64{model.MeanResidual, model.MeanResidual + model.ResidualStdDev, model.MeanResidual - model.ResidualStdDev} {
+		line, err := plotter.NewLine(plotter.XYs{{X: 0, Y: level}, {X: last, Y: level}})
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+		line.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+		p.Add(line)
+		if config.ShowLegend && i == 1 {
+			p.Legend.Add("Mean ± 1 std dev", line)
+		}
+	}
+
+	if config.ShowLegend {
+		p.Legend.Add("ln(price / model)", residualLine)
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawCostSensitivityChart plots the net total return of each backtest run
+// against the cost per trade, with break-even dashed
+func DrawCostSensitivityChart(runs []types.BacktestRun, config ChartConfig) ([]byte, error) {
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+	p.Legend.Top = true
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	colors := []color.RGBA{
+		{R: 70, G: 130, B: 180, A: 255},
+		{R: 255, G: 140, B: 0, A: 255},
+		{R: 40, G: 167, B: 69, A: 255},
+		{R: 128, G: 0, B: 128, A: 255},
+		{R: 220, G: 53, B: 69, A: 255},
+	}
+	maxCost, plotted := 0.0, 0
+	for _, run := range runs {
+		if len(run.CostSweep) == 0 {
+			continue
+		}
+		points := make(plotter.XYs, len(run.CostSweep))
+		for j, cp := range run.CostSweep {
+			points[j] = plotter.XY{X: cp.CostBps, Y: cp.TotalReturn * 100}
+			maxCost = math.Max(maxCost, cp.CostBps)
+		}
+		line, scatter, err := plotter.NewLinePoints(points)
+		if err != nil {
+			return nil, err
+		}
+		line.LineStyle.Color = colors[plotted%len(colors)]
+		line.LineStyle.Width = config.LineWidth
+		scatter.GlyphStyle.Color = colors[plotted%len(colors)]
+		p.Add(line, scatter)
+		if config.ShowLegend {
+			p.Legend.Add(run.Strategy, line)
+		}
+		plotted++
+	}
+	if plotted == 0 {
+		return nil, fmt.Errorf("no cost sweep to plot")
+	}
+
+	zero, err := plotter.NewLine(plotter.XYs{{X: 0, Y: 0}, {X: maxCost, Y: 0}})
+	if err != nil {
+		return nil, err
+	}
+	zero.LineStyle.Color = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+	zero.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+	p.Add(zero)
+	if config.ShowLegend {
+		p.Legend.Add("Break-even", zero)
+	}
+
+	return renderPlot(p, config)
+}
+
+// DrawHistogram plots the distribution of values in bins bins, with a
+// dashed vertical marker at marker labelled markerLabel
+func DrawHistogram(values []float64, bins int, marker float64, markerLabel string, config ChartConfig) ([]byte, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = config.Title
+	p.X.Label.Text = config.XLabel
+	p.Y.Label.Text = config.YLabel
+	p.Legend.Top = true
+
+	if config.ShowGrid {
+		p.Add(plotter.NewGrid())
+	}
+
+	hist, err := plotter.NewHist(plotter.Values(values), bins)
+	if err != nil {
+		return nil, err
+	}
+	hist.FillColor = color.RGBA{R: 173, G: 204, B: 230, A: 255}
+	hist.LineStyle.Color = color.RGBA{R: 70, G: 130, B: 180, A: 255}
+	p.Add(hist)
+
+	top := 0.0
+	for _, bin := range hist.Bins {
+		top = math.Max(top, bin.Weight)
+	}
+	line, err := plotter.NewLine(plotter.XYs{{X: marker, Y: 0}, {X: marker, Y: top}})
+	if err != nil {
+		return nil, err
+	}
+	line.LineStyle.Color = color.RGBA{R: 220, G: 53, B: 69, A: 255}
+	line.LineStyle.Width = config.LineWidth
+	line.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+	p.Add(line)
+	if config.ShowLegend {
+		p.Legend.Add(markerLabel, line)
+	}
+
+	return renderPlot(p, config)
+}
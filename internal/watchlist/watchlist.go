@@ -0,0 +1,356 @@
+package watchlist
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/notify"
+	"btc-analyzer/internal/pool"
+	"btc-analyzer/internal/signalstate"
+	"btc-analyzer/internal/store"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultEvery is the re-analysis interval of watchlists that do not set one
+const DefaultEvery = time.Hour
+
+// minEvery is the shortest allowed re-analysis interval
+const minEvery = time.Minute
+
+// timeframes are the Binance kline intervals a watchlist may use
+var timeframes = map[string]bool{
+	"1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true,
+	"1d": true, "3d": true, "1w": true,
+}
+
+// Loader loads the price series a watchlist tracks
+type Loader func(w types.Watchlist) (*types.BTCTimeSeries, error)
+
+// Result is the latest analysis of one watchlist
+type Result struct {
+	Analysis  *types.AnalysisResult
+	Triggered []types.PriceAlert
+	RanAt     time.Time
+}
+
+// Notifier is told about the alerts triggered on the latest close of a watchlist
+type Notifier interface {
+	Notify(w types.Watchlist, triggered []types.PriceAlert, close float64) error
+}
+
+// Manager analyzes every stored watchlist on its own schedule and keeps the
+// latest result of each in memory
+type Manager struct {
+	store   *store.Store
+	load    Loader
+	risk    types.RiskConfig
+	signals types.SignalConfig
+	states  *signalstate.Tracker
+	notify  Notifier
+	workers int
+
+	mu      sync.RWMutex
+	results map[string]*Result
+	running map[string]bool
+}
+
+// NewManager returns a manager for the watchlists of st whose signals use
+// the thresholds of signals
+func NewManager(st *store.Store, load Loader, risk types.RiskConfig, signals types.SignalConfig) *Manager {
+	return &Manager{
+		store:   st,
+		load:    load,
+		risk:    risk,
+		signals: signals,
+		results: make(map[string]*Result),
+		running: make(map[string]bool),
+	}
+}
+
+// Store returns the watchlist store of the manager
+func (m *Manager) Store() *store.Store {
+	return m.store
+}
+
+// SetNotifier delivers triggered alerts to n. It must be called before Run.
+func (m *Manager) SetNotifier(n Notifier) {
+	m.notify = n
+}
+
+// SetSignalState stabilizes the signals of every watchlist with t, keyed by
+// watchlist ID. It must be called before Run.
+func (m *Manager) SetSignalState(t *signalstate.Tracker) {
+	m.states = t
+}
+
+// SetWorkers sets how many due watchlists Run analyzes at the same time.
+// It must be called before Run.
+func (m *Manager) SetWorkers(n int) {
+	m.workers = n
+}
+
+// Validate checks a watchlist and fills in defaults for unset fields
+func Validate(w *types.Watchlist) error {
+	w.Symbol = strings.ToUpper(strings.TrimSpace(w.Symbol))
+	if w.Source == "" {
+		w.Source = "binance"
+	}
+	if w.Timeframe == "" {
+		w.Timeframe = "1h"
+	}
+	if w.Days == 0 {
+		w.Days = 30
+	}
+	if w.Every == "" {
+		w.Every = DefaultEvery.String()
+	}
+	every, err := time.ParseDuration(w.Every)
+	if err != nil {
+		return fmt.Errorf("invalid re-analysis interval %q: %w", w.Every, err)
+	}
+
+	switch {
+	case w.Source != "binance" && w.Source != "sample":
+		return fmt.Errorf("unknown source %q: use 'binance' or 'sample'", w.Source)
+	case w.Symbol == "":
+		return fmt.Errorf("symbol is required")
+	case !timeframes[w.Timeframe]:
+		return fmt.Errorf("unsupported timeframe %q", w.Timeframe)
+	case w.Days < 1:
+		return fmt.Errorf("days must be positive")
+	case every < minEvery:
+		return fmt.Errorf("re-analysis interval must be at least %s", minEvery)
+	}
+	for _, alert := range w.Alerts {
+		if alert.Type != "above" && alert.Type != "below" && alert.Type != "change" {
+			return fmt.Errorf("unknown alert type %q: use 'above', 'below' or 'change'", alert.Type)
+		}
+		if !notify.ValidUrgency(alert.Urgency) {
+			return fmt.Errorf("unknown alert urgency %q: use 'high' or 'normal'", alert.Urgency)
+		}
+	}
+	return nil
+}
+
+// Run checks every tick for watchlists whose interval has elapsed and
+// analyzes them until stop is closed, a few at a time. A watchlist is never
+// analyzed twice at the same time, and one failing does not hold up the
+// others.
+func (m *Manager) Run(tick time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		var due []string
+		for _, w := range m.store.List("") {
+			if now.Sub(w.LastRun) >= interval(w) && !m.isRunning(w.ID) {
+				due = append(due, w.ID)
+			}
+		}
+		if len(due) > 0 {
+			m.refresh(due)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh analyzes the watchlists in ids on the worker pool and logs the
+// progress of batches of more than one
+func (m *Manager) refresh(ids []string) {
+	start := time.Now()
+	errs := pool.Run(len(ids), m.workers, func(i int) error {
+		return m.Analyze(ids[i])
+	}, func(p pool.Progress) {
+		if p.Err != nil {
+			log.Printf("Watchlist %s: %v", ids[p.Index], p.Err)
+		}
+		if p.Total > 1 {
+			log.Printf("Watchlists %d/%d: %s done", p.Done, p.Total, ids[p.Index])
+		}
+	})
+	if len(ids) > 1 {
+		log.Printf("Refreshed %d watchlists in %.1fs, %d failed", len(ids), time.Since(start).Seconds(), pool.Failed(errs))
+	}
+}
+
+// Analyze loads and analyzes one watchlist now and records its result. It
+// returns an error when the watchlist is unknown, already running, or fails.
+func (m *Manager) Analyze(id string) error {
+	m.mu.Lock()
+	if m.running[id] {
+		m.mu.Unlock()
+		return fmt.Errorf("analysis already running")
+	}
+	m.running[id] = true
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.running, id)
+		m.mu.Unlock()
+	}()
+
+	w, ok := m.store.Get(id)
+	if !ok {
+		return fmt.Errorf("unknown watchlist")
+	}
+
+	result, runErr := m.analyze(w)
+	if runErr == nil {
+		m.mu.Lock()
+		m.results[id] = result
+		m.mu.Unlock()
+	}
+
+	// Re-read so API edits made during the run are kept; skip deleted watchlists
+	if latest, ok := m.store.Get(id); ok {
+		latest.LastRun = time.Now()
+		latest.LastError = ""
+		if runErr != nil {
+			latest.LastError = runErr.Error()
+		}
+		if _, err := m.store.Put(latest); err != nil {
+			return err
+		}
+	}
+	return runErr
+}
+
+// analyze runs the analysis of w and evaluates its alerts. A panic fails
+// only this watchlist and is recorded as its error.
+func (m *Manager) analyze(w types.Watchlist) (result *Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("analysis panicked: %v", r)
+		}
+	}()
+
+	bts, err := m.load(w)
+	if err != nil {
+		return nil, err
+	}
+	if len(bts.Data) == 0 {
+		return nil, fmt.Errorf("no data returned for %s", w.Symbol)
+	}
+
+	start := time.Now()
+	analysis := analyzer.NewResult(bts, analyzer.PerformComprehensiveAnalysis(bts, m.risk), m.signals)
+	analysis.Metadata.ComputeSeconds = time.Since(start).Seconds()
+	analysis.Metadata.Parameters = &types.AnalysisParams{Source: w.Source, Risk: m.risk, Signals: m.signals}
+	if m.states != nil {
+		if err := m.states.Apply("watchlist:"+w.ID, analysis); err != nil {
+			log.Printf("Watchlist %s: failed to save signal states: %v", w.ID, err)
+		}
+	}
+	result = &Result{Analysis: analysis, RanAt: start}
+	result.Triggered = EvaluateAlerts(bts, w.Alerts)
+	m.report(w, bts, result.Triggered)
+	return result, nil
+}
+
+// report logs the triggered alerts of w and passes them to the notifier
+func (m *Manager) report(w types.Watchlist, bts *types.BTCTimeSeries, triggered []types.PriceAlert) {
+	for _, alert := range triggered {
+		log.Printf("🔔 Watchlist %s (%s %s): %s %.2f triggered", w.ID, w.Symbol, w.Timeframe, alert.Type, alert.Threshold)
+	}
+	if m.notify == nil || len(triggered) == 0 {
+		return
+	}
+	if err := m.notify.Notify(w, triggered, bts.Data[len(bts.Data)-1].Close); err != nil {
+		log.Printf("Watchlist %s: failed to send notification: %v", w.ID, err)
+	}
+}
+
+// interval returns the re-analysis interval of w, DefaultEvery when unparsable
+func interval(w types.Watchlist) time.Duration {
+	every, err := time.ParseDuration(w.Every)
+	if err != nil {
+		return DefaultEvery
+	}
+	return every
+}
+
+// isRunning reports whether the watchlist is being analyzed
+func (m *Manager) isRunning(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.running[id]
+}
+
+// Result returns the latest analysis of a watchlist
+func (m *Manager) Result(id string) (*Result, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result, ok := m.results[id]
+	return result, ok
+}
+
+// Forget drops the stored result of a deleted watchlist
+func (m *Manager) Forget(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.results, id)
+}
+
+// EvaluateAll re-evaluates the alerts of every watchlist against its latest
+// result, logging and recording those that trigger, and returns how many did
+func (m *Manager) EvaluateAll() int {
+	count := 0
+	for _, w := range m.store.List("") {
+		m.mu.Lock()
+		result, ok := m.results[w.ID]
+		var triggered []types.PriceAlert
+		if ok {
+			triggered = EvaluateAlerts(result.Analysis.Series, w.Alerts)
+			result.Triggered = triggered
+			count += len(triggered)
+		}
+		m.mu.Unlock()
+		if ok {
+			m.report(w, result.Analysis.Series, triggered)
+		}
+	}
+	return count
+}
+
+// EvaluateAlerts returns the alerts triggered by the latest bar: "above" and
+// "below" compare its close with Threshold, "change" fires when the close
+// moved at least Threshold percent from the previous close
+func EvaluateAlerts(bts *types.BTCTimeSeries, alerts []types.PriceAlert) []types.PriceAlert {
+	if len(bts.Data) == 0 {
+		return nil
+	}
+	latest := bts.Data[len(bts.Data)-1]
+
+	var triggered []types.PriceAlert
+	for _, alert := range alerts {
+		hit := false
+		switch alert.Type {
+		case "above":
+			hit = latest.Close > alert.Threshold
+		case "below":
+			hit = latest.Close < alert.Threshold
+		case "change":
+			if len(bts.Data) > 1 {
+				previous := bts.Data[len(bts.Data)-2].Close
+				hit = previous > 0 && math.Abs(latest.Close-previous)/previous*100 >= alert.Threshold
+			}
+		}
+		if hit {
+			alert.Triggered = true
+			alert.Timestamp = latest.Timestamp
+			triggered = append(triggered, alert)
+		}
+	}
+	return triggered
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"btc-analyzer/internal/i18n"
+	"strings"
+)
+
+// loadMessages returns the message catalog of -locale: a registered locale,
+// or a JSON catalog file that is loaded and registered
+func loadMessages(locale string) (*i18n.Catalog, error) {
+	if strings.HasSuffix(locale, ".json") {
+		return i18n.Load(locale)
+	}
+	return i18n.Lookup(locale)
+}
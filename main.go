@@ -1,600 +1,940 @@
-package main
-
-import (
-	"btc-analyzer/internal/analyzer"
-	"btc-analyzer/internal/types"
-	"btc-analyzer/internal/dataloader"
-	"btc-analyzer/internal/reporter"
-	"btc-analyzer/internal/visualizer"
-	"encoding/base64"  // Move this to the top with other imports
-	"flag"
-	"fmt"
-	"log"
-	"os"
-)
-
-// generateSingleChart creates just the technical indicators chart
-func generateSingleChart(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, outputDir string) {
-	fmt.Println("\n📊 Generating Technical Indicators Chart...")
-	
-	// Create charts directory
-	chartsDir := fmt.Sprintf("%s/charts", outputDir)
-	if err := os.MkdirAll(chartsDir, 0755); err != nil {
-		fmt.Printf("Error creating charts directory: %v\n", err)
-		return
-	}
-	
-	// Generate just the technical indicators chart
-	chartData, err := visualizer.GenerateIndicatorChart(bts, analytics)
-	if err != nil {
-		fmt.Printf("Error generating technical indicators chart: %v\n", err)
-		return
-	}
-	
-	// Save chart as PNG file
-	chartPath := fmt.Sprintf("%s/technical_indicators.png", chartsDir)
-	if err := os.WriteFile(chartPath, chartData, 0644); err != nil {
-		fmt.Printf("Error saving chart: %v\n", err)
-		return
-	}
-	
-	fmt.Printf("✅ Technical indicators chart saved: %s\n", chartPath)
-	
-	// Generate simple HTML report with just this chart
-	htmlReport := generateSimpleHTMLReport(bts, analytics, chartData)
-	htmlPath := fmt.Sprintf("%s/technical_analysis.html", outputDir)
-	if err := os.WriteFile(htmlPath, []byte(htmlReport), 0644); err != nil {
-		fmt.Printf("Error saving HTML report: %v\n", err)
-	} else {
-		fmt.Printf("✅ HTML report with chart: %s\n", htmlPath)
-	}
-	
-	fmt.Println("📈 Technical indicators visualization complete!")
-	fmt.Println("🌐 Open the HTML file in your browser to view the chart")
-}
-
-// generateSimpleHTMLReport creates a basic HTML report with the single chart
-// generateSimpleHTMLReport creates a basic HTML report with the single chart and data tables
-func generateSimpleHTMLReport(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, chartData []byte) string {
-	// Convert chart to base64
-	base64Chart := ""
-	if len(chartData) > 0 {
-		base64Chart = base64.StdEncoding.EncodeToString(chartData)
-	}
-	
-	html := `<!DOCTYPE html>
-<html>
-<head>
-    <title>Bitcoin Technical Indicators Analysis</title>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <style>
-        body { 
-            font-family: 'Segoe UI', Arial, sans-serif; 
-            margin: 0; 
-            padding: 20px; 
-            background: #f5f5f5;
-        }
-        .container { 
-            max-width: 1400px; 
-            margin: 0 auto; 
-            background: white; 
-            padding: 30px; 
-            border-radius: 10px; 
-            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
-        }
-        .header { 
-            text-align: center; 
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); 
-            color: white; 
-            padding: 30px; 
-            border-radius: 10px; 
-            margin-bottom: 30px;
-        }
-        .header h1 { margin: 0; font-size: 2.2em; }
-        .stats-grid { 
-            display: grid; 
-            grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); 
-            gap: 20px; 
-            margin: 30px 0; 
-        }
-        .stat-card { 
-            background: #f8f9fa; 
-            padding: 20px; 
-            border-radius: 8px; 
-            text-align: center;
-            border-left: 4px solid #667eea;
-        }
-        .stat-value { font-size: 1.8em; font-weight: bold; color: #333; }
-        .stat-label { color: #666; margin-top: 5px; }
-        .chart-container { 
-            text-align: center; 
-            margin: 30px 0; 
-            padding: 20px; 
-            background: #f8f9fa; 
-            border-radius: 10px;
-        }
-        .chart-title { 
-            font-size: 1.5em; 
-            color: #333; 
-            margin-bottom: 20px; 
-        }
-        img { 
-            max-width: 100%; 
-            height: auto; 
-            border: 1px solid #ddd; 
-            border-radius: 8px;
-        }
-        .data-section {
-            margin: 30px 0;
-            background: #f8f9fa;
-            padding: 20px;
-            border-radius: 10px;
-        }
-        .data-section h3 {
-            color: #333;
-            margin-top: 0;
-        }
-        .data-table {
-            width: 100%;
-            border-collapse: collapse;
-            margin: 20px 0;
-            background: white;
-            border-radius: 8px;
-            overflow: hidden;
-            box-shadow: 0 2px 8px rgba(0,0,0,0.1);
-        }
-        .data-table th,
-        .data-table td {
-            padding: 12px;
-            text-align: left;
-            border-bottom: 1px solid #ddd;
-        }
-        .data-table th {
-            background: #667eea;
-            color: white;
-            font-weight: 600;
-        }
-        .data-table tr:hover {
-            background: #f5f5f5;
-        }
-        .data-table td.number {
-            text-align: right;
-            font-family: 'Courier New', monospace;
-        }
-        .data-table td.date {
-            font-weight: 500;
-        }
-        .indicators { 
-            background: #e3f2fd; 
-            padding: 20px; 
-            border-radius: 10px; 
-            margin: 20px 0;
-        }
-        .indicators h3 { margin-top: 0; color: #1976d2; }
-        .indicator-item { 
-            display: inline-block; 
-            margin: 10px 15px; 
-            padding: 10px; 
-            background: white; 
-            border-radius: 5px;
-            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
-        }
-        .summary-stats {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(150px, 1fr));
-            gap: 15px;
-            margin: 20px 0;
-        }
-        .summary-item {
-            background: white;
-            padding: 15px;
-            border-radius: 8px;
-            text-align: center;
-            border-left: 3px solid #667eea;
-        }
-        .scrollable {
-            max-height: 400px;
-            overflow-y: auto;
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>📊 Bitcoin Technical Analysis</h1>
-            <p>RSI & MACD Indicators with Raw Data</p>
-        </div>
-
-        <div class="stats-grid">
-            <div class="stat-card">
-                <div class="stat-value">` + fmt.Sprintf("%d", len(bts.Data)) + `</div>
-                <div class="stat-label">Data Points</div>
-            </div>
-            <div class="stat-card">
-                <div class="stat-value">$` + fmt.Sprintf("%.2f", analytics.PriceStats.Mean) + `</div>
-                <div class="stat-label">Average Price</div>
-            </div>
-            <div class="stat-card">
-                <div class="stat-value">` + fmt.Sprintf("%.2f%%", analytics.Volatility*100) + `</div>
-                <div class="stat-label">Volatility</div>
-            </div>`
-
-	// Add current RSI if available
-	if len(analytics.RSI) > 0 {
-		currentRSI := analytics.RSI[len(analytics.RSI)-2]
-		html += `
-            <div class="stat-card">
-                <div class="stat-value">` + fmt.Sprintf("%.1f", currentRSI) + `</div>
-                <div class="stat-label">Current RSI</div>
-            </div>`
-	}
-
-	html += `
-        </div>`
-
-	// Add chart if available
-	if base64Chart != "" {
-		html += `
-        <div class="chart-container">
-            <div class="chart-title">📈 Technical Indicators Chart</div>
-            <img src="data:image/png;base64,` + base64Chart + `" alt="Technical Indicators Chart">
-        </div>`
-	}
-
-	// Add Price Data Table
-	html += `
-        <div class="data-section">
-            <h3>💰 Price Data (Last 20 Records)</h3>
-            <div class="scrollable">
-                <table class="data-table">
-                    <thead>
-                        <tr>
-                            <th>Date</th>
-                            <th>Open</th>
-                            <th>High</th>
-                            <th>Low</th>
-                            <th>Close</th>
-                            <th>Volume</th>
-                        </tr>
-                    </thead>
-                    <tbody>`
-
-	// Show last 20 price records
-	start := len(bts.Data) - 20
-	if start < 0 {
-		start = 0
-	}
-	
-	for i := start; i < len(bts.Data); i++ {
-		data := bts.Data[i]
-		html += `
-                        <tr>
-                            <td class="date">` + data.Timestamp.Format("Jan 02, 2006") + `</td>
-                            <td class="number">$` + fmt.Sprintf("%.2f", data.Open) + `</td>
-                            <td class="number">$` + fmt.Sprintf("%.2f", data.High) + `</td>
-                            <td class="number">$` + fmt.Sprintf("%.2f", data.Low) + `</td>
-                            <td class="number">$` + fmt.Sprintf("%.2f", data.Close) + `</td>
-                            <td class="number">` + fmt.Sprintf("%.0f", data.Volume) + `</td>
-                        </tr>`
-	}
-
-	html += `
-                    </tbody>
-                </table>
-            </div>
-        </div>`
-
-	// Add RSI Data Table if available
-	if len(analytics.RSI) > 0 {
-		html += `
-        <div class="data-section">
-            <h3>📊 RSI Values (Last 20 Records)</h3>
-            <div class="summary-stats">
-                <div class="summary-item">
-                    <strong>` + fmt.Sprintf("%.1f", analytics.RSI[len(analytics.RSI)-2]) + `</strong><br>
-                    <small>Current RSI</small>
-                </div>
-                <div class="summary-item">
-                    <strong>` + fmt.Sprintf("%d", len(analytics.RSI)) + `</strong><br>
-                    <small>Total RSI Points</small>
-                </div>`
-		
-		// Calculate RSI average
-		rsiSum := 0.0
-		for _, rsi := range analytics.RSI {
-			rsiSum += rsi
-		}
-		rsiAvg := rsiSum / float64(len(analytics.RSI))
-		
-		html += `
-                <div class="summary-item">
-                    <strong>` + fmt.Sprintf("%.1f", rsiAvg) + `</strong><br>
-                    <small>Average RSI</small>
-                </div>
-            </div>
-            <div class="scrollable">
-                <table class="data-table">
-                    <thead>
-                        <tr>
-                            <th>Index</th>
-                            <th>RSI Value</th>
-                            <th>Status</th>
-                        </tr>
-                    </thead>
-                    <tbody>`
-
-		// Show last 20 RSI values
-		rsiStart := len(analytics.RSI) - 20
-		if rsiStart < 0 {
-			rsiStart = 0
-		}
-		
-		for i := rsiStart; i < len(analytics.RSI); i++ {
-			rsi := analytics.RSI[i]
-			status := "Neutral"
-			if rsi < 30 {
-				status = "Oversold"
-			} else if rsi > 70 {
-				status = "Overbought"
-			}
-			
-			html += `
-                        <tr>
-                            <td class="number">` + fmt.Sprintf("%d", i+1) + `</td>
-                            <td class="number">` + fmt.Sprintf("%.2f", rsi) + `</td>
-                            <td>` + status + `</td>
-                        </tr>`
-		}
-
-		html += `
-                    </tbody>
-                </table>
-            </div>
-        </div>`
-	}
-
-	// Add MACD Data Table if available
-	if len(analytics.MACD.MACD) > 0 {
-		html += `
-        <div class="data-section">
-            <h3>📈 MACD Values (Last 20 Records)</h3>
-            <div class="summary-stats">
-                <div class="summary-item">
-                    <strong>` + fmt.Sprintf("%.3f", analytics.MACD.MACD[len(analytics.MACD.MACD)-1]) + `</strong><br>
-                    <small>Current MACD</small>
-                </div>`
-		
-		if len(analytics.MACD.Signal) > 0 {
-			html += `
-                <div class="summary-item">
-                    <strong>` + fmt.Sprintf("%.3f", analytics.MACD.Signal[len(analytics.MACD.Signal)-1]) + `</strong><br>
-                    <small>Current Signal</small>
-                </div>`
-		}
-		
-		html += `
-                <div class="summary-item">
-                    <strong>` + fmt.Sprintf("%d", len(analytics.MACD.MACD)) + `</strong><br>
-                    <small>Total MACD Points</small>
-                </div>
-            </div>
-            <div class="scrollable">
-                <table class="data-table">
-                    <thead>
-                        <tr>
-                            <th>Index</th>
-                            <th>MACD</th>
-                            <th>Signal</th>
-                            <th>Histogram</th>
-                            <th>Trend</th>
-                        </tr>
-                    </thead>
-                    <tbody>`
-
-		// Show last 20 MACD values
-		macdStart := len(analytics.MACD.MACD) - 20
-		if macdStart < 0 {
-			macdStart = 0
-		}
-		
-		for i := macdStart; i < len(analytics.MACD.MACD); i++ {
-			macd := analytics.MACD.MACD[i]
-			signal := ""
-			histogram := ""
-			trend := "Neutral"
-			
-			if i < len(analytics.MACD.Signal) {
-				signalVal := analytics.MACD.Signal[i]
-				signal = fmt.Sprintf("%.3f", signalVal)
-				
-				if macd > signalVal {
-					trend = "Bullish"
-				} else if macd < signalVal {
-					trend = "Bearish"
-				}
-			}
-			
-			if i < len(analytics.MACD.Histogram) {
-				histogram = fmt.Sprintf("%.3f", analytics.MACD.Histogram[i])
-			}
-			
-			html += `
-                        <tr>
-                            <td class="number">` + fmt.Sprintf("%d", i+1) + `</td>
-                            <td class="number">` + fmt.Sprintf("%.3f", macd) + `</td>
-                            <td class="number">` + signal + `</td>
-                            <td class="number">` + histogram + `</td>
-                            <td>` + trend + `</td>
-                        </tr>`
-		}
-
-		html += `
-                    </tbody>
-                </table>
-            </div>
-        </div>`
-	}
-
-	// Add indicator explanations
-	html += `
-        <div class="indicators">
-            <h3>📋 Current Indicator Status</h3>`
-
-	if len(analytics.RSI) > 0 {
-		currentRSI := analytics.RSI[len(analytics.RSI)-1]
-		rsiStatus := "Neutral"
-		if currentRSI < 30 {
-			rsiStatus = "Oversold (Buy Signal)"
-		} else if currentRSI > 70 {
-			rsiStatus = "Overbought (Sell Signal)"
-		}
-		html += `
-            <div class="indicator-item">
-                <strong>RSI (` + fmt.Sprintf("%.1f", currentRSI) + `):</strong> ` + rsiStatus + `
-            </div>`
-	}
-
-	if len(analytics.MACD.MACD) > 0 && len(analytics.MACD.Signal) > 0 {
-		currentMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
-		currentSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-1]
-		macdStatus := "Neutral"
-		if currentMACD > currentSignal {
-			macdStatus = "Bullish Trend"
-		} else if currentMACD < currentSignal {
-			macdStatus = "Bearish Trend"
-		}
-		html += `
-            <div class="indicator-item">
-                <strong>MACD:</strong> ` + macdStatus + ` (` + fmt.Sprintf("%.3f", currentMACD) + `)
-            </div>`
-	}
-
-	html += `
-        </div>
-    </div>
-</body>
-</html>`
-
-	return html
-}
-
-func main() {
-	// Command line flags
-	var (
-		source         = flag.String("source", "api", "Data source: 'api', 'csv', 'json', or 'sample'")
-		days           = flag.Int("days", 30, "Number of days for API data")
-		csvFile        = flag.String("csv", "", "CSV file path")
-		jsonFile       = flag.String("json", "", "JSON file path")
-		outputDir      = flag.String("output", ".", "Output directory for reports")
-		htmlReport     = flag.Bool("html", true, "Generate HTML report")
-		jsonReport     = flag.Bool("json-report", true, "Generate JSON report")
-		generateChart  = flag.Bool("chart", true, "Generate technical indicators chart")
-		verbose        = flag.Bool("verbose", false, "Verbose output")
-	)
-	flag.Parse()
-
-	fmt.Println("🚀 Bitcoin Market Analyzer Starting...")
-
-	// Load data based on source
-	var bts *types.BTCTimeSeries
-	var err error
-
-	switch *source {
-	case "api":
-		fmt.Printf("📡 Fetching %d days of data from CoinGecko API...\n", *days)
-		bts, err = dataloader.LoadFromCoinGecko(*days)
-		if err != nil {
-			log.Fatalf("Failed to load data from API: %v", err)
-		}
-
-	case "csv":
-		if *csvFile == "" {
-			log.Fatal("CSV file path required when using -source=csv")
-		}
-		fmt.Printf("📄 Loading data from CSV file: %s\n", *csvFile)
-		bts, err = dataloader.LoadFromCSV(*csvFile)
-		if err != nil {
-			log.Fatalf("Failed to load CSV data: %v", err)
-		}
-
-	case "json":
-		if *jsonFile == "" {
-			log.Fatal("JSON file path required when using -source=json")
-		}
-		fmt.Printf("📄 Loading data from JSON file: %s\n", *jsonFile)
-		bts, err = dataloader.LoadFromJSON(*jsonFile)
-		if err != nil {
-			log.Fatalf("Failed to load JSON data: %v", err)
-		}
-
-	case "sample":
-		fmt.Println("🎲 Generating sample data for demonstration...")
-		bts = dataloader.GenerateSampleData(*days, 50000.0)
-
-	default:
-		log.Fatalf("Invalid source: %s. Use 'api', 'csv', 'json', or 'sample'", *source)
-	}
-
-	if bts == nil {
-		log.Fatal("Failed to load data")
-	}
-
-	// Validate data
-	fmt.Println("🔍 Validating data...")
-	issues := dataloader.ValidateData(bts)
-	if len(issues) > 0 {
-		fmt.Printf("⚠️  Data validation warnings:\n")
-		for _, issue := range issues {
-			fmt.Printf("  - %s\n", issue)
-		}
-	} else {
-		fmt.Println("✅ Data validation passed")
-	}
-
-	// Perform analysis
-	fmt.Println("📊 Performing comprehensive analysis...")
-	analytics := analyzer.PerformComprehensiveAnalysis(bts)
-
-	// Print summary to console
-	reporter.PrintSummary(bts, analytics)
-
-	// Generate technical indicators chart
-	if *generateChart {
-		generateSingleChart(bts, analytics, *outputDir)
-	}
-
-	// Generate reports
-	if *htmlReport {
-		htmlPath := fmt.Sprintf("%s/btc_analysis_report.html", *outputDir)
-		fmt.Printf("📝 Generating HTML report: %s\n", htmlPath)
-		if err := reporter.GenerateHTMLReport(bts, analytics, htmlPath); err != nil {
-			log.Printf("Failed to generate HTML report: %v", err)
-		} else {
-			fmt.Printf("✅ HTML report generated successfully\n")
-		}
-	}
-
-	if *jsonReport {
-		jsonPath := fmt.Sprintf("%s/btc_analysis_report.json", *outputDir)
-		fmt.Printf("📝 Generating JSON report: %s\n", jsonPath)
-		if err := reporter.GenerateJSONReport(bts, analytics, jsonPath); err != nil {
-			log.Printf("Failed to generate JSON report: %v", err)
-		} else {
-			fmt.Printf("✅ JSON report generated successfully\n")
-		}
-	}
-
-	// Save processed data
-	csvPath := fmt.Sprintf("%s/btc_data.csv", *outputDir)
-	fmt.Printf("💾 Saving data to CSV: %s\n", csvPath)
-	if err := dataloader.SaveToCSV(bts, csvPath); err != nil {
-		log.Printf("Failed to save CSV: %v", err)
-	}
-
-	if *verbose {
-		fmt.Println("\n" + analyzer.GenerateReport(bts, analytics))
-	}
-
-	fmt.Println("🎉 Analysis complete! Check the output directory for reports and charts.")
-}
\ No newline at end of file
+package main
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/visualizer"
+	"encoding/base64"  // Move this to the top with other imports
+	"flag"
+	"fmt"
+	stdhtml "html"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// generateSingleChart creates just the technical indicators chart, with its HTML page in the locale of msgs and the data tables of rc
+func generateSingleChart(result *types.AnalysisResult, outputDir string, rc types.ReportConfig, msgs *i18n.Catalog) error {
+	bts, analytics := result.Series, result.Analytics
+	fmt.Println("\n📊 Generating Technical Indicators Chart...")
+	
+	// Create charts directory
+	chartsDir := fmt.Sprintf("%s/charts", outputDir)
+	if err := os.MkdirAll(chartsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create charts directory: %w", err)
+	}
+	
+	// Generate just the technical indicators chart
+	chartData, err := visualizer.GenerateIndicatorChart(bts, analytics)
+	if err != nil {
+		return fmt.Errorf("failed to generate technical indicators chart: %w", err)
+	}
+	
+	// Save chart as PNG file
+	chartPath := fmt.Sprintf("%s/technical_indicators.png", chartsDir)
+	if err := os.WriteFile(chartPath, chartData, 0644); err != nil {
+		return fmt.Errorf("failed to save technical indicators chart: %w", err)
+	}
+	
+	fmt.Printf("✅ Technical indicators chart saved: %s\n", chartPath)
+	
+	// Generate simple HTML report with just this chart
+	htmlReport := generateSimpleHTMLReport(bts, analytics, analyzer.ResolveSignalConfig(result.SignalThresholds), chartData, reporter.ChartAltText("technical_indicators", result, msgs), rc, msgs)
+	htmlPath := fmt.Sprintf("%s/technical_analysis.html", outputDir)
+	if err := os.WriteFile(htmlPath, []byte(htmlReport), 0644); err != nil {
+		return fmt.Errorf("failed to save technical analysis HTML: %w", err)
+	}
+	fmt.Printf("✅ HTML report with chart: %s\n", htmlPath)
+	
+	fmt.Println("📈 Technical indicators visualization complete!")
+	fmt.Println("🌐 Open the HTML file in your browser to view the chart")
+	return nil
+}
+
+// saveChartFile writes chart PNG data into the charts directory of outputDir
+func saveChartFile(outputDir, filename string, chartData []byte) (string, error) {
+	chartsDir := fmt.Sprintf("%s/charts", outputDir)
+	if err := os.MkdirAll(chartsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create charts directory: %w", err)
+	}
+
+	chartPath := fmt.Sprintf("%s/%s", chartsDir, filename)
+	if err := os.WriteFile(chartPath, chartData, 0644); err != nil {
+		return "", fmt.Errorf("failed to save chart: %w", err)
+	}
+	return chartPath, nil
+}
+
+// generateDepthChart saves the order book depth chart next to the indicators chart
+func generateDepthChart(book *types.OrderBook, metrics types.OrderBookMetrics, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = fmt.Sprintf("%s Order Book Depth (±%.0f%%)", book.Symbol, metrics.DepthBand*100)
+
+	chartData, err := visualizer.DrawDepthChart(book, metrics, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate depth chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "order_book_depth.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save depth chart: %w", err)
+	}
+
+	fmt.Printf("✅ Order book depth chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateOrderFlowChart saves the volume delta / CVD chart pane
+func generateOrderFlowChart(flow *types.OrderFlowData, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = "Volume Delta & Cumulative Volume Delta"
+	config.XLabel = "Candle"
+
+	chartData, err := visualizer.DrawOrderFlowChart(flow, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate order flow chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "order_flow.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save order flow chart: %w", err)
+	}
+
+	fmt.Printf("✅ Order flow chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateVolatilityChart saves the implied vs realized volatility chart
+func generateVolatilityChart(va *types.VolatilityAnalysis, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = "Implied (DVOL) vs Realized Volatility"
+	config.XLabel = "Observation"
+	config.YLabel = "Annualized Volatility (%)"
+
+	chartData, err := visualizer.DrawVolatilityChart(va, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate volatility chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "implied_vs_realized.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save volatility chart: %w", err)
+	}
+
+	fmt.Printf("✅ Volatility chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateCorrelogramChart saves the volume and volatility lead/lag correlogram
+func generateCorrelogramChart(drivers []types.LeadLagAnalysis, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = "Volume and Volatility vs Returns by Lag"
+	config.XLabel = "Lag (periods; positive = series leads)"
+	config.YLabel = "Correlation"
+
+	chartData, err := visualizer.DrawCorrelogram(drivers, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate correlogram: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "return_correlogram.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save correlogram: %w", err)
+	}
+
+	fmt.Printf("✅ Correlogram saved: %s\n", chartPath)
+	return nil
+}
+
+// generateStockToFlowCharts saves the stock-to-flow overlay and residual
+// charts, with prices in denomination d
+func generateStockToFlowCharts(model *types.StockToFlowModel, d *types.Denomination, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = "Price vs Stock-to-Flow (reference model, not a prediction)"
+	config.XLabel = "Day"
+	config.YLabel = "Price (" + denom.Label(d) + ", log scale)"
+
+	chartData, err := visualizer.DrawStockToFlowChart(model, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate stock-to-flow chart: %w", err)
+	}
+	chartPath, err := saveChartFile(outputDir, "stock_to_flow.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save stock-to-flow chart: %w", err)
+	}
+	fmt.Printf("✅ Stock-to-flow chart saved: %s\n", chartPath)
+
+	config.Title = "Stock-to-Flow Residuals (reference model, not a prediction)"
+	config.YLabel = "ln(price / model)"
+	chartData, err = visualizer.DrawStockToFlowResiduals(model, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate stock-to-flow residual chart: %w", err)
+	}
+	chartPath, err = saveChartFile(outputDir, "stock_to_flow_residuals.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save stock-to-flow residual chart: %w", err)
+	}
+	fmt.Printf("✅ Stock-to-flow residual chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateRealPriceChart saves the nominal vs inflation-adjusted price chart,
+// with prices in denomination d
+func generateRealPriceChart(rp *types.RealPriceAnalysis, d *types.Denomination, config visualizer.ChartConfig, outputDir string) error {
+	config.Title = "Nominal vs Real Price (" + rp.Index + ")"
+	config.XLabel = "Bar"
+	config.YLabel = visualizer.PriceLabel(d, config)
+
+	chartData, err := visualizer.DrawRealPriceChart(rp, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate real price chart: %w", err)
+	}
+	chartPath, err := saveChartFile(outputDir, "real_price.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save real price chart: %w", err)
+	}
+	fmt.Printf("✅ Real price chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateBenchmarkChart saves the price chart against the -benchmark series
+func generateBenchmarkChart(bts, benchmark *types.BTCTimeSeries, name string, config visualizer.ChartConfig, outputDir string) error {
+	config.Title = fmt.Sprintf("%s vs %s", bts.Symbol, name)
+	config.XLabel = "Bar"
+	config.YLabel = visualizer.PriceLabel(bts.Denomination, config)
+
+	chartData, err := visualizer.DrawComparisonChart([]string{bts.Symbol, name}, []*types.BTCTimeSeries{bts, benchmark}, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate benchmark chart: %w", err)
+	}
+	chartPath, err := saveChartFile(outputDir, "price_vs_benchmark.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save benchmark chart: %w", err)
+	}
+	fmt.Printf("✅ Benchmark chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateSummaryCard saves the social media summary card of a result
+func generateSummaryCard(result *types.AnalysisResult, outputDir string) error {
+	bts := result.Series
+	if len(bts.Data) == 0 {
+		return fmt.Errorf("failed to generate summary card: no data")
+	}
+	latest := bts.Data[len(bts.Data)-1]
+	card := visualizer.SummaryCard{
+		Symbol:      bts.Symbol,
+		Price:       denom.Price(bts.Denomination, latest.Close),
+		Change24h:   timeseries.ChangeOver(bts, 24*time.Hour),
+		Change30d:   timeseries.ChangeOver(bts, 30*24*time.Hour),
+		SignalScore: result.SignalScore,
+		Signals:     len(result.Signals),
+		AsOf:        latest.Timestamp,
+	}
+	if rsi := result.Analytics.RSI; len(rsi) > 0 {
+		card.RSI = &rsi[len(rsi)-1]
+	}
+	for _, bar := range bts.Data {
+		if !bar.Timestamp.Before(latest.Timestamp.Add(-30 * 24 * time.Hour)) {
+			card.Sparkline = append(card.Sparkline, bar.Close)
+		}
+	}
+
+	cardData, err := visualizer.DrawSummaryCard(card)
+	if err != nil {
+		return fmt.Errorf("failed to generate summary card: %w", err)
+	}
+	cardPath := fmt.Sprintf("%s/summary_card.png", outputDir)
+	if err := os.WriteFile(cardPath, cardData, 0644); err != nil {
+		return fmt.Errorf("failed to save summary card: %w", err)
+	}
+	fmt.Printf("✅ Summary card saved: %s\n", cardPath)
+	return nil
+}
+
+// generateHeatChart saves the market heat index chart with its extreme bands
+func generateHeatChart(heat *types.HeatIndex, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = "Market Heat Index"
+	config.XLabel = "Time Period"
+	config.YLabel = "Weighted z-score"
+
+	chartData, err := visualizer.DrawHeatChart(heat, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate heat chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "market_heat.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save heat chart: %w", err)
+	}
+
+	fmt.Printf("✅ Heat chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateBetaChart saves the rolling beta chart against the benchmark
+func generateBetaChart(ba *types.BetaAnalysis, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = fmt.Sprintf("Rolling Beta vs %s", ba.Benchmark)
+	config.XLabel = "Window"
+	config.YLabel = "Beta"
+
+	chartData, err := visualizer.DrawRollingBetaChart(ba, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate beta chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "rolling_beta.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save beta chart: %w", err)
+	}
+
+	fmt.Printf("✅ Beta chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generatePremiumChart saves the premium/discount chart against the reference index
+func generatePremiumChart(premium *types.PremiumAnalysis, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = fmt.Sprintf("Premium / Discount vs %s", premium.Reference)
+	config.XLabel = "Bar"
+	config.YLabel = "Premium (%)"
+
+	chartData, err := visualizer.DrawPremiumChart(premium, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate premium chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "premium.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save premium chart: %w", err)
+	}
+
+	fmt.Printf("✅ Premium chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateElliottChart saves the price chart annotated with candidate wave counts
+func generateElliottChart(bts *types.BTCTimeSeries, elliott *types.ElliottAnalysis, config visualizer.ChartConfig, outputDir string) error {
+	config.Title = "Elliott Wave Candidate Counts"
+	config.XLabel = "Candle"
+
+	chartData, err := visualizer.DrawElliottWaveChart(bts, elliott, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate Elliott wave chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "elliott_waves.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save Elliott wave chart: %w", err)
+	}
+
+	fmt.Printf("✅ Elliott wave chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateHarmonicChart saves the price chart with recent XABCD patterns and their PRZ
+func generateHarmonicChart(bts *types.BTCTimeSeries, harmonics []types.HarmonicPattern, config visualizer.ChartConfig, outputDir string) error {
+	config.Title = "Harmonic Patterns (XABCD)"
+	config.XLabel = "Candle"
+
+	chartData, err := visualizer.DrawHarmonicChart(bts, harmonics, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate harmonic pattern chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "harmonic_patterns.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save harmonic pattern chart: %w", err)
+	}
+
+	fmt.Printf("✅ Harmonic pattern chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateCustomIndicatorChart saves the chart of a plugin indicator with its rule triggers
+func generateCustomIndicatorChart(ci types.CustomIndicator, outputDir string) error {
+	config := visualizer.DefaultChartConfig()
+	config.Title = fmt.Sprintf("Custom Indicator: %s", ci.Name)
+	config.XLabel = "Candle"
+	config.YLabel = ci.Name
+
+	chartData, err := visualizer.DrawCustomIndicatorChart(ci, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate custom indicator chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, fmt.Sprintf("custom_%s.png", ci.Name), chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save custom indicator chart: %w", err)
+	}
+
+	fmt.Printf("✅ Custom indicator chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateLevelsChart saves the price chart with support/resistance levels sized by strength
+func generateLevelsChart(bts *types.BTCTimeSeries, levels []types.SRLevel, config visualizer.ChartConfig, outputDir string) error {
+	config.Title = "Support & Resistance Strength"
+	config.XLabel = "Candle"
+
+	chartData, err := visualizer.DrawSupportResistanceChart(bts, levels, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate support/resistance chart: %w", err)
+	}
+
+	chartPath, err := saveChartFile(outputDir, "support_resistance.png", chartData)
+	if err != nil {
+		return fmt.Errorf("failed to save support/resistance chart: %w", err)
+	}
+
+	fmt.Printf("✅ Support/resistance chart saved: %s\n", chartPath)
+	return nil
+}
+
+// generateSimpleHTMLReport creates a basic HTML report with the single chart
+// generateSimpleHTMLReport creates a basic HTML report with the single chart and data tables
+// in the locale of msgs, with RSI statuses at thresholds, described to
+// screen readers by altText. The data tables show the latest records of rc,
+// unless rc hides them.
+func generateSimpleHTMLReport(bts *types.BTCTimeSeries, analytics types.BTCAnalytics, thresholds types.SignalConfig, chartData []byte, altText string, rc types.ReportConfig, msgs *i18n.Catalog) string {
+	t := func(key string, args ...interface{}) string { return stdhtml.EscapeString(msgs.T(key, args...)) }
+	tables := analyzer.ShowsSection(rc, analyzer.SectionTables)
+	rows := analyzer.TableRows(rc)
+
+	// Convert chart to base64
+	base64Chart := ""
+	if len(chartData) > 0 {
+		base64Chart = base64.StdEncoding.EncodeToString(chartData)
+	}
+	
+	var html strings.Builder
+	html.WriteString(`<!DOCTYPE html>
+<html lang="` + msgs.Lang() + `">
+<head>
+    <title>` + t("tech.page_title") + `</title>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body { 
+            font-family: 'Segoe UI', Arial, sans-serif; 
+            margin: 0; 
+            padding: 20px; 
+            background: #f5f5f5;
+        }
+        .container { 
+            max-width: 1400px; 
+            margin: 0 auto; 
+            background: white; 
+            padding: 30px; 
+            border-radius: 10px; 
+            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
+        }
+        .header { 
+            text-align: center; 
+            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); 
+            color: white; 
+            padding: 30px; 
+            border-radius: 10px; 
+            margin-bottom: 30px;
+        }
+        .header h1 { margin: 0; font-size: 2.2em; }
+        .stats-grid { 
+            display: grid; 
+            grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); 
+            gap: 20px; 
+            margin: 30px 0; 
+        }
+        .stat-card { 
+            background: #f8f9fa; 
+            padding: 20px; 
+            border-radius: 8px; 
+            text-align: center;
+            border-left: 4px solid #667eea;
+        }
+        .stat-value { font-size: 1.8em; font-weight: bold; color: #333; }
+        .stat-label { color: #666; margin-top: 5px; }
+        .chart-container { 
+            text-align: center; 
+            margin: 30px 0; 
+            padding: 20px; 
+            background: #f8f9fa; 
+            border-radius: 10px;
+        }
+        .chart-title { 
+            font-size: 1.5em; 
+            color: #333; 
+            margin-bottom: 20px; 
+        }
+        img { 
+            max-width: 100%; 
+            height: auto; 
+            border: 1px solid #ddd; 
+            border-radius: 8px;
+        }
+        .data-section {
+            margin: 30px 0;
+            background: #f8f9fa;
+            padding: 20px;
+            border-radius: 10px;
+        }
+        .data-section h3 {
+            color: #333;
+            margin-top: 0;
+        }
+        .data-table {
+            width: 100%;
+            border-collapse: collapse;
+            margin: 20px 0;
+            background: white;
+            border-radius: 8px;
+            overflow: hidden;
+            box-shadow: 0 2px 8px rgba(0,0,0,0.1);
+        }
+        .data-table th,
+        .data-table td {
+            padding: 12px;
+            text-align: left;
+            border-bottom: 1px solid #ddd;
+        }
+        .data-table th {
+            background: #667eea;
+            color: white;
+            font-weight: 600;
+        }
+        .data-table tr:hover {
+            background: #f5f5f5;
+        }
+        .data-table td.number {
+            text-align: right;
+            font-family: 'Courier New', monospace;
+        }
+        .data-table td.date {
+            font-weight: 500;
+        }
+        .indicators { 
+            background: #e3f2fd; 
+            padding: 20px; 
+            border-radius: 10px; 
+            margin: 20px 0;
+        }
+        .indicators h3 { margin-top: 0; color: #1976d2; }
+        .indicator-item { 
+            display: inline-block; 
+            margin: 10px 15px; 
+            padding: 10px; 
+            background: white; 
+            border-radius: 5px;
+            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
+        }
+        .summary-stats {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(150px, 1fr));
+            gap: 15px;
+            margin: 20px 0;
+        }
+        .summary-item {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            text-align: center;
+            border-left: 3px solid #667eea;
+        }
+        .scrollable {
+            max-height: 400px;
+            overflow-y: auto;
+        }` + reporter.PrintStyles + `
+    </style>
+</head>
+<body>
+    <main class="container">
+        <header class="header">
+            <h1>📊 ` + t("tech.title") + `</h1>
+            <p>` + t("tech.subtitle") + `</p>
+        </header>
+
+        <div class="stats-grid">
+            <div class="stat-card">
+                <div class="stat-value">` + fmt.Sprintf("%d", len(bts.Data)) + `</div>
+                <div class="stat-label">` + t("tech.data_points") + `</div>
+            </div>
+            <div class="stat-card">
+                <div class="stat-value">` + denom.Price(bts.Denomination, analytics.PriceStats.Mean) + `</div>
+                <div class="stat-label">` + t("tech.avg_price") + `</div>
+            </div>
+            <div class="stat-card">
+                <div class="stat-value">` + numfmt.Pct(analytics.Volatility) + `</div>
+                <div class="stat-label">` + t("tech.volatility") + `</div>
+            </div>`)
+
+	// Add current RSI if available
+	if len(analytics.RSI) > 0 {
+		currentRSI := analytics.RSI[len(analytics.RSI)-1]
+		html.WriteString(`
+            <div class="stat-card">
+                <div class="stat-value">` + fmt.Sprintf("%.1f", currentRSI) + `</div>
+                <div class="stat-label">` + t("tech.current_rsi") + `</div>
+            </div>`)
+	}
+
+	html.WriteString(`
+        </div>`)
+
+	if len(analytics.Performance) > 0 {
+		var horizons, returns strings.Builder
+		for _, hr := range analytics.Performance {
+			value := t("common.not_available")
+			if hr.Available {
+				value = numfmt.SignedPct(hr.Return)
+			}
+			horizons.WriteString(`<th scope="col">` + hr.Horizon + `</th>`)
+			returns.WriteString(`<td class="number">` + value + `</td>`)
+		}
+		html.WriteString(`
+        <div class="data-section">
+            <h3>` + t("tech.performance") + `</h3>
+            <table class="data-table">
+                <thead><tr>` + horizons.String() + `</tr></thead>
+                <tbody><tr>` + returns.String() + `</tr></tbody>
+            </table>
+        </div>`)
+	}
+
+	// Add chart if available
+	if base64Chart != "" {
+		html.WriteString(`
+        <div class="chart-container">
+            <div class="chart-title">📈 ` + t("tech.chart") + `</div>
+            <img src="data:image/png;base64,` + base64Chart + `" alt="` + stdhtml.EscapeString(altText) + `">
+        </div>`)
+	}
+
+	// Add Price Data Table
+	if tables {
+		html.WriteString(`
+        <div class="data-section">
+            <h3>💰 ` + t("tech.price_data", rows) + `</h3>
+            <div class="scrollable">
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th scope="col">` + t("col.date") + `</th>
+                            <th scope="col">` + t("col.open") + `</th>
+                            <th scope="col">` + t("col.high") + `</th>
+                            <th scope="col">` + t("col.low") + `</th>
+                            <th scope="col">` + t("col.close") + `</th>
+                            <th scope="col">` + t("col.volume") + `</th>
+                        </tr>
+                    </thead>
+                    <tbody>`)
+
+		// Show the latest price records
+		start := len(bts.Data) - rows
+		if start < 0 {
+			start = 0
+		}
+		
+		for i := start; i < len(bts.Data); i++ {
+			data := bts.Data[i]
+			html.WriteString(`
+                        <tr>
+                            <td class="date">` + data.Timestamp.Format("Jan 02, 2006") + `</td>
+                            <td class="number">` + denom.Price(bts.Denomination, data.Open) + `</td>
+                            <td class="number">` + denom.Price(bts.Denomination, data.High) + `</td>
+                            <td class="number">` + denom.Price(bts.Denomination, data.Low) + `</td>
+                            <td class="number">` + denom.Price(bts.Denomination, data.Close) + `</td>
+                            <td class="number">` + fmt.Sprintf("%.0f", data.Volume) + `</td>
+                        </tr>`)
+		}
+
+		html.WriteString(`
+                    </tbody>
+                </table>
+            </div>
+        </div>`)
+	}
+
+	// Add RSI Data Table if available
+	if tables && len(analytics.RSI) > 0 {
+		html.WriteString(`
+        <div class="data-section">
+            <h3>📊 ` + t("tech.rsi_values", rows) + `</h3>
+            <div class="summary-stats">
+                <div class="summary-item">
+                    <strong>` + fmt.Sprintf("%.1f", analytics.RSI[len(analytics.RSI)-1]) + `</strong><br>
+                    <small>` + t("tech.current_rsi") + `</small>
+                </div>
+                <div class="summary-item">
+                    <strong>` + fmt.Sprintf("%d", len(analytics.RSI)) + `</strong><br>
+                    <small>` + t("tech.total_rsi") + `</small>
+                </div>`)
+		
+		// Calculate RSI average
+		rsiSum := 0.0
+		for _, rsi := range analytics.RSI {
+			rsiSum += rsi
+		}
+		rsiAvg := rsiSum / float64(len(analytics.RSI))
+		
+		html.WriteString(`
+                <div class="summary-item">
+                    <strong>` + fmt.Sprintf("%.1f", rsiAvg) + `</strong><br>
+                    <small>` + t("tech.avg_rsi") + `</small>
+                </div>
+            </div>
+            <div class="scrollable">
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th scope="col">` + t("tech.col.index") + `</th>
+                            <th scope="col">` + t("tech.col.rsi") + `</th>
+                            <th scope="col">` + t("tech.col.status") + `</th>
+                        </tr>
+                    </thead>
+                    <tbody>`)
+
+		// Show the latest RSI values
+		rsiStart := len(analytics.RSI) - rows
+		if rsiStart < 0 {
+			rsiStart = 0
+		}
+		
+		for i := rsiStart; i < len(analytics.RSI); i++ {
+			rsi := analytics.RSI[i]
+			status := t("tech.neutral")
+			if rsi < thresholds.RSIOversold {
+				status = t("tech.oversold")
+			} else if rsi > thresholds.RSIOverbought {
+				status = t("tech.overbought")
+			}
+			
+			html.WriteString(`
+                        <tr>
+                            <td class="number">` + fmt.Sprintf("%d", i+1) + `</td>
+                            <td class="number">` + fmt.Sprintf("%.2f", rsi) + `</td>
+                            <td>` + status + `</td>
+                        </tr>`)
+		}
+
+		html.WriteString(`
+                    </tbody>
+                </table>
+            </div>
+        </div>`)
+	}
+
+	// Add MACD Data Table if available
+	if tables && len(analytics.MACD.MACD) > 0 {
+		html.WriteString(`
+        <div class="data-section">
+            <h3>📈 ` + t("tech.macd_values", rows) + `</h3>
+            <div class="summary-stats">
+                <div class="summary-item">
+                    <strong>` + fmt.Sprintf("%.3f", analytics.MACD.MACD[len(analytics.MACD.MACD)-1]) + `</strong><br>
+                    <small>` + t("tech.current_macd") + `</small>
+                </div>`)
+		
+		if len(analytics.MACD.Signal) > 0 {
+			html.WriteString(`
+                <div class="summary-item">
+                    <strong>` + fmt.Sprintf("%.3f", analytics.MACD.Signal[len(analytics.MACD.Signal)-1]) + `</strong><br>
+                    <small>` + t("tech.current_signal") + `</small>
+                </div>`)
+		}
+		
+		html.WriteString(`
+                <div class="summary-item">
+                    <strong>` + fmt.Sprintf("%d", len(analytics.MACD.MACD)) + `</strong><br>
+                    <small>` + t("tech.total_macd") + `</small>
+                </div>
+            </div>
+            <div class="scrollable">
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th scope="col">` + t("tech.col.index") + `</th>
+                            <th scope="col">` + t("tech.col.macd") + `</th>
+                            <th scope="col">` + t("tech.col.signal") + `</th>
+                            <th scope="col">` + t("tech.col.histogram") + `</th>
+                            <th scope="col">` + t("tech.col.trend") + `</th>
+                        </tr>
+                    </thead>
+                    <tbody>`)
+
+		// Show the latest MACD values
+		macdStart := len(analytics.MACD.MACD) - rows
+		if macdStart < 0 {
+			macdStart = 0
+		}
+		
+		for i := macdStart; i < len(analytics.MACD.MACD); i++ {
+			macd := analytics.MACD.MACD[i]
+			signal := ""
+			histogram := ""
+			trend := t("tech.neutral")
+			
+			if i < len(analytics.MACD.Signal) {
+				signalVal := analytics.MACD.Signal[i]
+				signal = fmt.Sprintf("%.3f", signalVal)
+				
+				if macd > signalVal {
+					trend = t("tech.bullish")
+				} else if macd < signalVal {
+					trend = t("tech.bearish")
+				}
+			}
+			
+			if i < len(analytics.MACD.Histogram) {
+				histogram = fmt.Sprintf("%.3f", analytics.MACD.Histogram[i])
+			}
+			
+			html.WriteString(`
+                        <tr>
+                            <td class="number">` + fmt.Sprintf("%d", i+1) + `</td>
+                            <td class="number">` + fmt.Sprintf("%.3f", macd) + `</td>
+                            <td class="number">` + signal + `</td>
+                            <td class="number">` + histogram + `</td>
+                            <td>` + trend + `</td>
+                        </tr>`)
+		}
+
+		html.WriteString(`
+                    </tbody>
+                </table>
+            </div>
+        </div>`)
+	}
+
+	// Add indicator explanations
+	html.WriteString(`
+        <div class="indicators">
+            <h3>📋 ` + t("tech.status") + `</h3>`)
+
+	if len(analytics.RSI) > 0 {
+		currentRSI := analytics.RSI[len(analytics.RSI)-1]
+		rsiStatus := t("tech.neutral")
+		if currentRSI < thresholds.RSIOversold {
+			rsiStatus = t("tech.rsi.oversold")
+		} else if currentRSI > thresholds.RSIOverbought {
+			rsiStatus = t("tech.rsi.overbought")
+		}
+		html.WriteString(`
+            <div class="indicator-item">
+                <strong>` + t("tech.rsi_status", currentRSI) + `</strong> ` + rsiStatus + `
+            </div>`)
+	}
+
+	if len(analytics.MACD.MACD) > 0 && len(analytics.MACD.Signal) > 0 {
+		currentMACD := analytics.MACD.MACD[len(analytics.MACD.MACD)-1]
+		currentSignal := analytics.MACD.Signal[len(analytics.MACD.Signal)-1]
+		macdStatus := t("tech.neutral")
+		if currentMACD > currentSignal {
+			macdStatus = t("tech.macd.bullish")
+		} else if currentMACD < currentSignal {
+			macdStatus = t("tech.macd.bearish")
+		}
+		html.WriteString(`
+            <div class="indicator-item">
+                <strong>` + t("tech.macd_status") + `</strong> ` + macdStatus + ` (` + fmt.Sprintf("%.3f", currentMACD) + `)
+            </div>`)
+	}
+
+	html.WriteString(`
+        </div>
+    </main>
+</body>
+</html>`)
+
+	return html.String()
+}
+
+func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bundle":
+			runBundleCommand(os.Args[2:])
+			return
+		case "open-bundle":
+			runOpenBundleCommand(os.Args[2:])
+			return
+		case "compare":
+			runCompareCommand(os.Args[2:])
+			return
+		case "diff":
+			runDiffCommand(os.Args[2:])
+			return
+		case "schedule":
+			runScheduleCommand(os.Args[2:])
+			return
+		case "serve":
+			runServeCommand(os.Args[2:])
+			return
+		case "daemon":
+			runServeCommand(append([]string{"-daemon"}, os.Args[2:]...))
+			return
+		case "doctor":
+			runDoctorCommand(os.Args[2:])
+			return
+		case "backtest":
+			runBacktestCommand(os.Args[2:])
+			return
+		case "backfill":
+			runBackfillCommand(os.Args[2:])
+			return
+		case "store":
+			runStoreCommand(os.Args[2:])
+			return
+		case "screen":
+			runScreenCommand(os.Args[2:])
+			return
+		}
+	}
+
+	cfg := registerRunFlags(flag.CommandLine)
+	publishTarget := registerPublishFlag(flag.CommandLine)
+	flag.Parse()
+	if err := applyEnvFlags(flag.CommandLine); err != nil {
+		log.Fatal(err)
+	}
+	if err := validateRunConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
+	publisher, err := parsePublishTarget(*publishTarget)
+	if err != nil {
+		log.Fatal(err)
+	}
+	configureCache(cfg)
+
+	fmt.Println("🚀 Bitcoin Market Analyzer Starting...")
+
+	if cfg.Chunked {
+		if err := runChunkedAnalysis(cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	inputs, err := loadInputs(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result := runPipeline(inputs, cfg)
+	if publisher != nil {
+		recordFailure(result, publishReports(publisher, cfg.OutputDir))
+	}
+	if len(result.Metadata.Errors) > 0 {
+		fmt.Println("⚠️  Analysis completed with errors. Check the output directory for the reports and charts that were generated.")
+		exitOnFailure(result)
+	}
+
+	fmt.Println("🎉 Analysis complete! Check the output directory for reports and charts.")
+}
@@ -0,0 +1,1168 @@
+package main
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/calendar"
+	"btc-analyzer/internal/candlelog"
+	"btc-analyzer/internal/comparison"
+	"btc-analyzer/internal/config"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/httpcache"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/orderbook"
+	"btc-analyzer/internal/orderflow"
+	"btc-analyzer/internal/plugins"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/resultcache"
+	"btc-analyzer/internal/signalstate"
+	"btc-analyzer/internal/statistics"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/visualizer"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// runConfig holds the options of a single analysis run. It is stored in
+// bundles so a run can be reproduced without the original command line.
+type runConfig struct {
+	Source          string
+	Days            int
+	CSVFile         string
+	JSONFile        string
+	NDJSONFile      string
+	OutputDir       string
+	HTMLReport      bool
+	ReportMode      string
+	JSONReport      bool
+	RiskDashboard   bool
+	SummaryCard     bool
+	Embed           bool
+	Chart           bool
+	Verbose         bool
+	TrendsFile      string
+	OnChainFile     string
+	MaxLag          int
+	OrderBook       bool
+	OrderBookSymbol string
+	TradesFile      string
+	AggTrades       bool
+	LargeTradeQty   float64
+	NoCache         bool
+	CacheDir        string
+	CacheTTL        time.Duration
+	CacheMaxStale   time.Duration
+	Chunked         bool
+	ChunkSize       int
+	RiskFreeRate    float64
+	FundingRate     float64
+	PeriodsPerYear  int
+	Compounding     string
+	VolEstimator    string
+	GlobalMetrics   bool
+	DVOL            bool
+	Reference       string
+	PremiumWindow   int
+	PremiumZ        float64
+	Benchmark       string
+	BetaWindow      int
+	Pair            string
+	PairWindow      int
+	PairEntry       float64
+	PairExit        float64
+	HeatWeights     string
+	ICal            bool
+	DCAEvery        time.Duration
+	DCACount        int
+	DCAAmount       float64
+	PluginDir       string
+	Indicators      string
+	Warmup          string // warm-up policy of the technical indicators
+	ResultCacheDir  string
+	ForceRecompute  bool
+	ExportFormat    string
+	Failover        string
+	Merge           string
+	MergePolicy     string
+	Denomination    string
+	Locale          string
+	Messages        *i18n.Catalog // catalog of Locale, set by validateRunConfig
+	Unit            float64
+	Adjust          string
+	BenchmarkAdjust string
+	CPI             string
+	ATH             string
+	SignalConfig    string                 // config file whose signals section sets the signal thresholds
+	Signals         types.SignalConfig     // thresholds of SignalConfig and the environment, set by validateRunConfig
+	Report          types.ReportConfig     // report sections of SignalConfig and the environment, set by validateRunConfig
+	Precision       *types.PrecisionConfig // decimals in force for the run, recorded in bundles
+	SignalState     *signalstate.Tracker   // damps the signals of every run when set, by serve
+	ChartLog        string
+	ChartNormalize  string
+}
+
+// registerRunFlags registers the analysis flags on fs and returns the config they fill
+func registerRunFlags(fs *flag.FlagSet) *runConfig {
+	cfg := &runConfig{}
+	cacheDefaults := httpcache.DefaultConfig()
+
+	fs.StringVar(&cfg.Source, "source", "api", "Data source: 'api' (CoinGecko), 'binance', 'kraken', 'csv', 'json', 'ndjson' (candle log), 'cache' (last series fetched), or 'sample'")
+	fs.StringVar(&cfg.Failover, "failover", "", "Comma-separated sources to try in order when -source fails, e.g. 'kraken,api,cache'; files as csv:<file>, json:<file> or ndjson:<file>")
+	fs.IntVar(&cfg.Days, "days", 30, "Number of days for API data")
+	fs.StringVar(&cfg.CSVFile, "csv", "", "CSV file path")
+	fs.StringVar(&cfg.JSONFile, "json", "", "JSON file path")
+	fs.StringVar(&cfg.NDJSONFile, "ndjson", "", "NDJSON candle log path, read with its rotated segments")
+	fs.StringVar(&cfg.Merge, "merge", "", "Comma-separated series merged into the loaded one in order, e.g. 'csv:archive.csv'; sources as for -failover")
+	fs.StringVar(&cfg.MergePolicy, "merge-policy", string(timeseries.PreferFirst), "Bar kept where merged series overlap: prefer-first, prefer-latest, average or error-on-conflict")
+	fs.StringVar(&cfg.Adjust, "adjust", "", "Split adjustments of the price series as date:ratio pairs, e.g. '2024-06-10:10' for a 10-for-1 split")
+	fs.StringVar(&cfg.Denomination, "denomination", denom.Fiat, "Price denomination of reports and charts: 'fiat' (per -unit BTC), 'sats' or 'btc' (per -unit of fiat)")
+	fs.StringVar(&cfg.Locale, "locale", i18n.DefaultLocale, "Language of the reports: a built-in locale or a JSON message catalog file")
+	fs.Float64Var(&cfg.Unit, "unit", 1, "Denomination unit: BTC priced in fiat, e.g. 0.00000001 for per sat, or fiat spent in sats and btc")
+	fs.StringVar(&cfg.OutputDir, "output", ".", "Output directory for reports")
+	fs.BoolVar(&cfg.HTMLReport, "html", true, "Generate HTML report")
+	fs.StringVar(&cfg.ReportMode, "report-mode", reportModeFull, "HTML report: 'full' or 'weekly' (one page on the last complete week against the week before)")
+	fs.BoolVar(&cfg.JSONReport, "json-report", true, "Generate JSON report")
+	fs.BoolVar(&cfg.RiskDashboard, "risk-dashboard", true, "Generate the risk dashboard HTML page")
+	fs.BoolVar(&cfg.SummaryCard, "summary-card", false, "Generate a 1200x630 PNG summary card for social media and dashboards")
+	fs.BoolVar(&cfg.Embed, "embed", false, "Generate embeddable HTML widgets and their JSON data in the embed directory of the output")
+	fs.BoolVar(&cfg.Chart, "chart", true, "Generate technical indicators chart")
+	fs.StringVar(&cfg.ChartLog, "chart-log", "", "Comma-separated price charts with a log-scale Y axis, or 'all': "+strings.Join(priceCharts, ", "))
+	fs.StringVar(&cfg.ChartNormalize, "chart-normalize", "price_vs_benchmark", "Comma-separated price charts rebased to 100 at the first point, or 'all' ('none' for none)")
+	fs.BoolVar(&cfg.Verbose, "verbose", false, "Verbose output")
+	fs.StringVar(&cfg.TrendsFile, "trends", "", "Google Trends CSV export for search-interest correlation")
+	fs.StringVar(&cfg.CPI, "cpi", "", "Price index for inflation-adjusted prices: csv:<file> or fred[:<series>] (default series "+dataloader.DefaultCPISeries+")")
+	fs.StringVar(&cfg.ATH, "ath", "", "Historical all-time high used when above the loaded data: 'api' (CoinGecko, USD) or price[@YYYY-MM-DD]")
+	fs.StringVar(&cfg.SignalConfig, "signal-config", "", "JSON config file whose 'signals' section sets the trading-signal thresholds, 'report' section the report sections and 'precision' section the decimals of money and percentages")
+	fs.StringVar(&cfg.OnChainFile, "onchain", "", "Daily on-chain CSV with USD transaction volume and supply, for NVT ratios")
+	fs.IntVar(&cfg.MaxLag, "max-lag", 8, "Maximum lead/lag in periods for cross-correlation")
+	fs.BoolVar(&cfg.OrderBook, "orderbook", false, "Fetch an order book snapshot from Binance")
+	fs.StringVar(&cfg.OrderBookSymbol, "orderbook-symbol", "BTCUSDT", "Binance symbol for the order book snapshot")
+	fs.StringVar(&cfg.TradesFile, "trades", "", "Binance aggTrades CSV dump for order flow metrics")
+	fs.BoolVar(&cfg.AggTrades, "aggtrades", false, "Fetch aggTrades from Binance for the loaded period")
+	fs.Float64Var(&cfg.LargeTradeQty, "large-trade", 0, "Minimum quantity of a large trade (0 = mean + 3 std dev)")
+	fs.BoolVar(&cfg.NoCache, "no-cache", false, "Bypass the on-disk HTTP cache")
+	fs.StringVar(&cfg.CacheDir, "cache-dir", cacheDefaults.Dir, "Directory for cached API responses")
+	fs.DurationVar(&cfg.CacheTTL, "cache-ttl", cacheDefaults.TTL, "How long cached API responses stay fresh")
+	fs.DurationVar(&cfg.CacheMaxStale, "cache-max-stale", cacheDefaults.MaxStale, "Age up to which a cached API response stands in for a failed request, with a warning (0 = never; never with -failover)")
+	fs.BoolVar(&cfg.Chunked, "chunked", false, "Stream the CSV in chunks with constant memory (requires -source=csv)")
+	fs.IntVar(&cfg.ChunkSize, "chunk-size", 100000, "Bars per chunk in chunked mode")
+	fs.Float64Var(&cfg.RiskFreeRate, "risk-free", 0, "Annual risk-free rate for Sharpe/Sortino, e.g. 0.045")
+	fs.Float64Var(&cfg.FundingRate, "funding-rate", 0, "Annual funding/carry cost charged on top of the risk-free rate")
+	fs.IntVar(&cfg.PeriodsPerYear, "periods-per-year", 0, "Bars per year for annualization (0 = detect from data)")
+	fs.StringVar(&cfg.Compounding, "compounding", "simple", "Return annualization: 'simple' or 'geometric'")
+	fs.StringVar(&cfg.VolEstimator, "vol-estimator", statistics.EstimatorCloseToClose, "Volatility estimator for volatility and Sharpe: "+strings.Join(statistics.VolEstimators, ", "))
+	fs.BoolVar(&cfg.GlobalMetrics, "global", false, "Fetch BTC dominance and stablecoin market cap from CoinGecko as macro context")
+	fs.BoolVar(&cfg.DVOL, "dvol", false, "Fetch the Deribit DVOL index and option term structure for implied vs realized volatility")
+	fs.StringVar(&cfg.Reference, "reference", "", "Reference index for premium/discount tracking: api, binance, csv:<file> or json:<file>")
+	fs.IntVar(&cfg.PremiumWindow, "premium-window", 30, "Bars in the trailing window for the premium z-score")
+	fs.Float64Var(&cfg.PremiumZ, "premium-z", 2.5, "Premium z-score at which a premium or discount is extreme")
+	fs.StringVar(&cfg.Benchmark, "benchmark", "", "Benchmark series for beta and alpha: csv:<file>, json:<file>, binance:<symbol>, api or sample")
+	fs.StringVar(&cfg.BenchmarkAdjust, "benchmark-adjust", "", "Split adjustments of the benchmark series, as for -adjust")
+	fs.IntVar(&cfg.BetaWindow, "beta-window", 30, "Returns in each rolling beta regression")
+	fs.StringVar(&cfg.Pair, "pair", "", "Second series for pairs analysis: csv:<file>, json:<file>, binance:<symbol>, api or sample")
+	fs.IntVar(&cfg.PairWindow, "pair-window", 0, "Bars in the rolling window of the spread z-score (0 = the spread's half-life)")
+	fs.Float64Var(&cfg.PairEntry, "pair-entry", comparison.DefaultPairConfig.EntryZ, "Spread z-score beyond which the pairs template opens a position")
+	fs.Float64Var(&cfg.PairExit, "pair-exit", comparison.DefaultPairConfig.ExitZ, "Spread z-score within which the pairs template closes its position")
+	fs.StringVar(&cfg.HeatWeights, "heat-weights", "", "Market heat component weights as name=weight pairs, e.g. 'price-ma=2,volume=0' (default 1 each)")
+	fs.BoolVar(&cfg.ICal, "ical", false, "Export projected crosses, DCA buys and option expiries as an .ics calendar")
+	fs.DurationVar(&cfg.DCAEvery, "dca-every", 0, "Interval of scheduled DCA buys in the calendar, e.g. 168h (0 = none)")
+	fs.IntVar(&cfg.DCACount, "dca-count", 12, "Number of scheduled DCA buys in the calendar")
+	fs.Float64Var(&cfg.DCAAmount, "dca-amount", 100, "Amount of each scheduled DCA buy")
+	fs.StringVar(&cfg.PluginDir, "plugins", "", "Directory of script indicator definitions (*.json)")
+	fs.StringVar(&cfg.ResultCacheDir, "result-cache-dir", resultcache.DefaultDir(), "Directory for cached analysis results")
+	fs.BoolVar(&cfg.ForceRecompute, "force-recompute", false, "Recompute the analysis even if a cached result matches the inputs")
+	fs.StringVar(&cfg.ExportFormat, "export-format", dataloader.FormatCSV, "Format of the exported series: 'csv', 'json' or 'ndjson'")
+	fs.StringVar(&cfg.Indicators, "indicators", "", "Comma-separated custom indicators to compute with their dependencies (default all)")
+	fs.StringVar(&cfg.Warmup, "warmup", analyzer.WarmupFetch, "Indicator warm-up: 'fetch' (load extra bars before the -days period), 'truncate' (leave the first bars out of the period) or 'none'")
+
+	return cfg
+}
+
+// validateRunConfig checks option values that flag parsing cannot
+func validateRunConfig(cfg *runConfig) error {
+	if cfg.ReportMode != reportModeFull && cfg.ReportMode != reportModeWeekly {
+		return fmt.Errorf("invalid report mode %q: use 'full' or 'weekly'", cfg.ReportMode)
+	}
+	if cfg.Compounding != "simple" && cfg.Compounding != "geometric" {
+		return fmt.Errorf("invalid compounding %q: use 'simple' or 'geometric'", cfg.Compounding)
+	}
+	if !validVolEstimator(cfg.VolEstimator) {
+		return fmt.Errorf("invalid volatility estimator %q: use %s", cfg.VolEstimator, strings.Join(statistics.VolEstimators, ", "))
+	}
+	if cfg.PeriodsPerYear < 0 {
+		return fmt.Errorf("periods per year must not be negative")
+	}
+	if cfg.Reference != "" && (cfg.PremiumWindow < 2 || cfg.PremiumZ <= 0) {
+		return fmt.Errorf("premium window must be at least 2 and premium z-score positive")
+	}
+	if cfg.Benchmark != "" && cfg.BetaWindow < 3 {
+		return fmt.Errorf("beta window must be at least 3")
+	}
+	if cfg.Pair != "" && (cfg.PairWindow < 0 || cfg.PairExit < 0 || cfg.PairEntry <= cfg.PairExit) {
+		return fmt.Errorf("pair window must not be negative and pair entry z-score must exceed the exit z-score, which must not be negative")
+	}
+	if _, err := analyzer.ParseHeatWeights(cfg.HeatWeights); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateWarmupPolicy(cfg.Warmup); err != nil {
+		return err
+	}
+	if cfg.DCAEvery < 0 || (cfg.DCAEvery > 0 && cfg.DCACount < 1) {
+		return fmt.Errorf("DCA interval must not be negative and DCA count must be positive")
+	}
+	switch cfg.ExportFormat {
+	case dataloader.FormatCSV, dataloader.FormatJSON, dataloader.FormatNDJSON:
+	default:
+		return fmt.Errorf("invalid export format %q: use 'csv', 'json' or 'ndjson'", cfg.ExportFormat)
+	}
+	for _, spec := range append(splitList(cfg.Failover), splitList(cfg.Merge)...) {
+		if _, err := sourceSpecConfig(cfg, spec); err != nil {
+			return err
+		}
+	}
+	if _, err := timeseries.ParseMergePolicy(cfg.MergePolicy); err != nil {
+		return err
+	}
+	if _, err := denom.Parse(cfg.Denomination, cfg.Unit); err != nil {
+		return err
+	}
+	msgs, err := loadMessages(cfg.Locale)
+	if err != nil {
+		return err
+	}
+	cfg.Messages = msgs
+	for _, list := range []string{cfg.Adjust, cfg.BenchmarkAdjust} {
+		if _, err := timeseries.ParseSplits(list); err != nil {
+			return err
+		}
+	}
+	for _, list := range []string{cfg.ChartLog, cfg.ChartNormalize} {
+		for _, name := range splitList(list) {
+			if name != "all" && name != "none" && !listed(strings.Join(priceCharts, ","), name) {
+				return fmt.Errorf("invalid price chart %q: use all, none or %s", name, strings.Join(priceCharts, ", "))
+			}
+		}
+	}
+	if cfg.CPI != "" {
+		kind, arg, _ := strings.Cut(cfg.CPI, ":")
+		if (kind != "csv" || arg == "") && kind != "fred" {
+			return fmt.Errorf("invalid price index %q: use csv:<file> or fred[:<series>]", cfg.CPI)
+		}
+		if cfg.Denomination != denom.Fiat {
+			return fmt.Errorf("inflation-adjusted prices need a fiat denomination")
+		}
+	}
+	if cfg.ATH != "" && cfg.ATH != "api" {
+		if _, err := dataloader.ParseATH(cfg.ATH); err != nil {
+			return err
+		}
+	}
+	if cfg.ATH == "api" && cfg.Denomination != denom.Fiat {
+		return fmt.Errorf("the CoinGecko all-time high needs a fiat denomination")
+	}
+	appConfig, err := config.LoadWithEnv(cfg.SignalConfig)
+	if err != nil {
+		return err
+	}
+	if appConfig != nil && appConfig.Signals != nil {
+		cfg.Signals = *appConfig.Signals
+	}
+	if appConfig != nil && appConfig.Report != nil {
+		cfg.Report = *appConfig.Report
+	}
+	if appConfig != nil && appConfig.Precision != nil {
+		if err := numfmt.Configure(*appConfig.Precision); err != nil {
+			return err
+		}
+	}
+	if err := analyzer.ValidateSignalConfig(cfg.Signals); err != nil {
+		return err
+	}
+	if err := analyzer.ValidateReportConfig(cfg.Report); err != nil {
+		return err
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// riskConfig returns the risk conventions of cfg
+func riskConfig(cfg *runConfig) types.RiskConfig {
+	return types.RiskConfig{
+		RiskFreeRate:   cfg.RiskFreeRate,
+		FundingRate:    cfg.FundingRate,
+		PeriodsPerYear: cfg.PeriodsPerYear,
+		Compounding:    cfg.Compounding,
+		VolEstimator:   cfg.VolEstimator,
+	}
+}
+
+// pairConfig returns the pairs template of cfg
+func pairConfig(cfg *runConfig) types.PairConfig {
+	return types.PairConfig{Window: cfg.PairWindow, EntryZ: cfg.PairEntry, ExitZ: cfg.PairExit}
+}
+
+// priceCharts are the charts of prices, which take the -chart-log and
+// -chart-normalize options, named after their files
+var priceCharts = []string{"support_resistance", "elliott_waves", "harmonic_patterns", "real_price", "price_vs_benchmark", "backtest_trades"}
+
+// priceChartConfig returns the chart config of the price chart name with the
+// scale options cfg selects for it
+func priceChartConfig(cfg *runConfig, name string) visualizer.ChartConfig {
+	config := visualizer.DefaultChartConfig()
+	config.LogScale = listed(cfg.ChartLog, "all") || listed(cfg.ChartLog, name)
+	config.Normalize = listed(cfg.ChartNormalize, "all") || listed(cfg.ChartNormalize, name)
+	return config
+}
+
+// listed reports whether name is in the comma-separated list
+func listed(list, name string) bool {
+	for _, item := range splitList(list) {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validVolEstimator reports whether name is one of the volatility estimators
+func validVolEstimator(name string) bool {
+	for _, estimator := range statistics.VolEstimators {
+		if name == estimator {
+			return true
+		}
+	}
+	return false
+}
+
+// configureCache applies the cache options of cfg to the shared HTTP cache.
+// With -failover a failed request fails its source, so the next source is
+// tried rather than an outdated response served.
+func configureCache(cfg *runConfig) {
+	maxStale := cfg.CacheMaxStale
+	if cfg.Failover != "" {
+		maxStale = 0
+	}
+	httpcache.Configure(httpcache.Config{
+		Dir:      cfg.CacheDir,
+		TTL:      cfg.CacheTTL,
+		MaxStale: maxStale,
+		Disabled: cfg.NoCache,
+	})
+}
+
+// runInputs holds all data loaded for a run
+type runInputs struct {
+	Series      *types.BTCTimeSeries
+	Trends      *types.AuxSeries
+	CPI         *types.AuxSeries
+	ATH         *types.AuxPoint // historical all-time high of -ath
+	OnChain     []types.OnChainPoint
+	Trades      []types.Trade
+	OrderBook   *types.OrderBook
+	Reference   *types.BTCTimeSeries
+	Benchmark   *types.BTCTimeSeries
+	Pair        *types.BTCTimeSeries
+	Dominance   *types.AuxSeries
+	Stablecoins *types.AuxSeries
+	DVOL        *types.AuxSeries
+	IVTerm      []types.TermPoint
+	Indicators  []plugins.Indicator
+	Warmup      []types.BTCPrice  // bars before Series that only warm up the technical indicators
+	WarmupInfo  *types.WarmupInfo // how the indicators were warmed up
+	Errors      []string          // auxiliary inputs that failed to load
+	Stale       []string          // cached responses that stood in for failed requests
+	ServedBy    string            // source that served the price series
+	Failover    []string          // sources that failed before it, with their errors
+}
+
+// loadSeries loads the primary price series from the configured source
+func loadSeries(cfg *runConfig) (*types.BTCTimeSeries, error) {
+	var bts *types.BTCTimeSeries
+	var err error
+
+	switch cfg.Source {
+	case "api":
+		fmt.Printf("📡 Fetching %d days of data from CoinGecko API...\n", cfg.Days)
+		bts, err = dataloader.LoadFromCoinGecko(cfg.Days)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load data from API: %w", err)
+		}
+
+	case "csv":
+		if cfg.CSVFile == "" {
+			return nil, fmt.Errorf("CSV file path required when using -source=csv")
+		}
+		fmt.Printf("📄 Loading data from CSV file: %s\n", cfg.CSVFile)
+		bts, err = dataloader.LoadFromCSV(cfg.CSVFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CSV data: %w", err)
+		}
+
+	case "json":
+		if cfg.JSONFile == "" {
+			return nil, fmt.Errorf("JSON file path required when using -source=json")
+		}
+		fmt.Printf("📄 Loading data from JSON file: %s\n", cfg.JSONFile)
+		bts, err = dataloader.LoadFromJSON(cfg.JSONFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JSON data: %w", err)
+		}
+
+	case "ndjson":
+		if cfg.NDJSONFile == "" {
+			return nil, fmt.Errorf("NDJSON file path required when using -source=ndjson")
+		}
+		fmt.Printf("📄 Loading candle log: %s\n", cfg.NDJSONFile)
+		bts, err = candlelog.Load(cfg.NDJSONFile, "BTC-USD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load candle log: %w", err)
+		}
+
+	case "binance":
+		end := time.Now()
+		fmt.Printf("📡 Fetching %d days of %s daily klines from Binance...\n", cfg.Days, cfg.OrderBookSymbol)
+		bts, err = dataloader.LoadKlinesFromBinance(cfg.OrderBookSymbol, "1d", end.AddDate(0, 0, -cfg.Days), end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load data from Binance: %w", err)
+		}
+
+	case "kraken":
+		if cfg.Days > dataloader.MaxKrakenCandles {
+			fmt.Printf("⚠️  Kraken serves only the last %d daily candles\n", dataloader.MaxKrakenCandles)
+		}
+		fmt.Printf("📡 Fetching %d days of %s daily candles from Kraken...\n", cfg.Days, dataloader.KrakenPair)
+		bts, err = dataloader.LoadOHLCFromKraken(dataloader.KrakenPair, 24*60, time.Now().AddDate(0, 0, -cfg.Days))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load data from Kraken: %w", err)
+		}
+
+	case "cache":
+		path := lastSeriesPath(cfg)
+		fmt.Printf("🗄️  Loading the last fetched series: %s\n", path)
+		bts, err = dataloader.LoadFromJSON(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the last fetched series: %w", err)
+		}
+
+	case "sample":
+		fmt.Println("🎲 Generating sample data for demonstration...")
+		bts = dataloader.GenerateSampleData(cfg.Days, 50000.0)
+
+	default:
+		return nil, fmt.Errorf("invalid source: %s. Use 'api', 'binance', 'kraken', 'csv', 'json', 'ndjson', 'cache', or 'sample'", cfg.Source)
+	}
+
+	if bts == nil {
+		return nil, fmt.Errorf("failed to load data")
+	}
+
+	return bts, nil
+}
+
+// mergeSeries merges the -merge series into bts in order with -merge-policy
+func mergeSeries(cfg *runConfig, bts *types.BTCTimeSeries) (*types.BTCTimeSeries, error) {
+	policy, err := timeseries.ParseMergePolicy(cfg.MergePolicy)
+	if err != nil {
+		return nil, err
+	}
+	for _, spec := range splitList(cfg.Merge) {
+		sourceCfg, err := sourceSpecConfig(cfg, spec)
+		if err != nil {
+			return nil, err
+		}
+		other, err := loadSeries(sourceCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load merged series %s: %w", spec, err)
+		}
+		merged, err := timeseries.Merge(bts, other, policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", spec, err)
+		}
+		fmt.Printf("🧩 Merged %d bars of %s into %d bars: %d bars now (%s)\n", len(other.Data), spec, len(bts.Data), len(merged.Data), policy)
+		bts = merged
+	}
+	return bts, nil
+}
+
+// loadInputs loads the price series and every auxiliary input enabled in cfg.
+// Only a failure to load the price series is returned as an error; auxiliary
+// inputs that fail are recorded in Errors and left out of the analysis, and
+// cached responses served for failed requests in Stale.
+func loadInputs(cfg *runConfig) (*runInputs, error) {
+	// stale responses served to other loads, e.g. of watchlists, are not this run's
+	httpcache.TakeStale()
+	fetchCfg := cfg
+	if cfg.Warmup == analyzer.WarmupFetch && periodSources[cfg.Source] {
+		days := warmupDays(cfg)
+		fmt.Printf("🔥 Loading %d extra days to warm up the indicators\n", days)
+		fetchCfg = &runConfig{}
+		*fetchCfg = *cfg
+		fetchCfg.Days += days
+	}
+	bts, servedBy, failures, err := loadSeriesWithFailover(fetchCfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Merge != "" {
+		if bts, err = mergeSeries(cfg, bts); err != nil {
+			return nil, err
+		}
+	}
+	if bts, err = adjustSeries(bts, cfg.Adjust, "price series"); err != nil {
+		return nil, err
+	}
+	d, err := denom.Parse(cfg.Denomination, cfg.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if d != nil {
+		fmt.Printf("🪙 Prices in %s\n", denom.Label(d))
+		bts = denom.Apply(bts, d)
+	}
+	bts, warmup, info := analyzer.SplitWarmup(bts, cfg.Warmup, periodStart(cfg, servedBy, bts))
+	fmt.Printf("🔥 Indicator warm-up: %s\n", analyzer.DescribeWarmup(info))
+	inputs := &runInputs{Series: bts, Warmup: warmup, WarmupInfo: &info, Indicators: plugins.Registered(), ServedBy: servedBy, Failover: failures}
+
+	if cfg.TrendsFile != "" {
+		fmt.Printf("🔎 Loading Google Trends data: %s\n", cfg.TrendsFile)
+		inputs.Trends, err = dataloader.LoadGoogleTrendsCSV(cfg.TrendsFile)
+		if err != nil {
+			inputs.loadFailed("Google Trends data", err)
+		}
+	}
+
+	if cfg.CPI != "" {
+		inputs.CPI, err = loadCPI(cfg, bts)
+		if err != nil {
+			inputs.loadFailed("price index", err)
+		}
+	}
+
+	if cfg.ATH == "api" {
+		fmt.Println("🏔️  Fetching the all-time high from CoinGecko...")
+		inputs.ATH, err = dataloader.LoadATHFromCoinGecko("bitcoin")
+		if err != nil {
+			inputs.loadFailed("all-time high", err)
+		}
+	} else if cfg.ATH != "" {
+		inputs.ATH, _ = dataloader.ParseATH(cfg.ATH)
+	}
+
+	if cfg.OnChainFile != "" {
+		fmt.Printf("⛓️  Loading on-chain data: %s\n", cfg.OnChainFile)
+		inputs.OnChain, err = dataloader.LoadOnChainCSV(cfg.OnChainFile)
+		if err != nil {
+			inputs.loadFailed("on-chain data", err)
+		}
+	}
+
+	if cfg.OrderBook {
+		fmt.Printf("📚 Fetching %s order book from Binance...\n", cfg.OrderBookSymbol)
+		inputs.OrderBook, err = dataloader.LoadOrderBookFromBinance(cfg.OrderBookSymbol, 1000)
+		if err != nil {
+			inputs.loadFailed("order book", err)
+		}
+	}
+
+	if cfg.TradesFile != "" || cfg.AggTrades {
+		if cfg.TradesFile != "" {
+			fmt.Printf("🧾 Loading trades from CSV file: %s\n", cfg.TradesFile)
+			inputs.Trades, err = dataloader.LoadTradesFromCSV(cfg.TradesFile)
+		} else {
+			// Through the close of the last candle, not its open
+			start, last := timeseries.GetTimeRange(bts)
+			end := last.Add(timeseries.DetectFrequency(bts).Interval)
+			if now := time.Now(); end.After(now) {
+				end = now
+			}
+			fmt.Printf("🧾 Fetching %s aggTrades from Binance...\n", cfg.OrderBookSymbol)
+			inputs.Trades, err = dataloader.LoadAggTradesFromBinance(cfg.OrderBookSymbol, start, end)
+		}
+		if err != nil {
+			inputs.loadFailed("trades", err)
+		}
+	}
+
+	if cfg.GlobalMetrics {
+		days := globalMetricsDays(bts)
+		fmt.Printf("🌐 Fetching %d days of BTC dominance and stablecoin market cap from CoinGecko...\n", days)
+		inputs.Dominance, err = dataloader.LoadBTCDominance(days)
+		if err != nil {
+			inputs.loadFailed("BTC dominance", err)
+		}
+		inputs.Stablecoins, err = dataloader.LoadStablecoinMarketCap(days, dataloader.DefaultStablecoinIDs)
+		if err != nil {
+			inputs.loadFailed("stablecoin market cap", err)
+		}
+	}
+
+	if cfg.DVOL {
+		start, end := timeseries.GetTimeRange(bts)
+		fmt.Println("🎯 Fetching BTC DVOL and option term structure from Deribit...")
+		inputs.DVOL, err = dataloader.LoadDVOLFromDeribit("BTC", start, end, deribitResolution(bts))
+		if err != nil {
+			inputs.loadFailed("DVOL", err)
+		}
+		inputs.IVTerm, err = dataloader.LoadOptionTermStructureFromDeribit("BTC")
+		if err != nil {
+			inputs.loadFailed("option term structure", err)
+		}
+	}
+
+	if cfg.PluginDir != "" {
+		fmt.Printf("🧩 Loading indicator plugins from %s\n", cfg.PluginDir)
+		scripts, err := plugins.LoadDir(cfg.PluginDir)
+		if err != nil {
+			inputs.loadFailed("indicator plugins", err)
+		}
+		for _, script := range scripts {
+			inputs.Indicators = append(inputs.Indicators, script)
+		}
+	}
+
+	if cfg.Reference != "" {
+		inputs.Reference, err = loadReference(cfg, bts)
+		if err != nil {
+			inputs.loadFailed("reference index", err)
+		} else if d != nil {
+			inputs.Reference = denom.Apply(inputs.Reference, d)
+		}
+	}
+	if cfg.Benchmark != "" {
+		inputs.Benchmark, err = loadSecondSeries(cfg.Benchmark, "benchmark", cfg, bts)
+		if err == nil {
+			inputs.Benchmark, err = adjustSeries(inputs.Benchmark, cfg.BenchmarkAdjust, "benchmark")
+		}
+		if err != nil {
+			inputs.loadFailed("benchmark", err)
+		}
+	}
+	if cfg.Pair != "" {
+		inputs.Pair, err = loadSecondSeries(cfg.Pair, "pair", cfg, bts)
+		if err != nil {
+			inputs.loadFailed("pair", err)
+		}
+	}
+
+	for _, hit := range httpcache.TakeStale() {
+		inputs.Stale = append(inputs.Stale, hit.String())
+	}
+	return inputs, nil
+}
+
+// periodSources are the sources that load the -days period before the
+// latest bar
+var periodSources = map[string]bool{"api": true, "binance": true, "kraken": true, "sample": true}
+
+// warmupDays returns the days loaded before the -days period so that its
+// first bar has the warm-up bars of the indicators before it. CoinGecko
+// serves hourly bars up to 90 days and daily bars beyond, so such periods
+// only get the days that keep the bars hourly; a one-day period of 5-minute
+// bars gets none.
+func warmupDays(cfg *runConfig) int {
+	bars := analyzer.WarmupBars()
+	if cfg.Source != "api" || cfg.Days > 90 {
+		return bars
+	}
+	if cfg.Days == 1 {
+		return 0
+	}
+	return min((bars+23)/24, 90-cfg.Days)
+}
+
+// periodStart returns when the reported period of bts starts under the
+// fetch warm-up policy: -days before the latest bar of a series served by a
+// period source. Files, merged series and the other policies report the
+// whole series, zero.
+func periodStart(cfg *runConfig, servedBy string, bts *types.BTCTimeSeries) time.Time {
+	kind, _, _ := strings.Cut(servedBy, ":")
+	if cfg.Warmup != analyzer.WarmupFetch || !periodSources[kind] || cfg.Merge != "" || len(bts.Data) == 0 {
+		return time.Time{}
+	}
+	return bts.Data[len(bts.Data)-1].Timestamp.AddDate(0, 0, -cfg.Days)
+}
+
+// adjustSeries back-adjusts bts for the splits in list, if any
+func adjustSeries(bts *types.BTCTimeSeries, list, what string) (*types.BTCTimeSeries, error) {
+	splits, err := timeseries.ParseSplits(list)
+	if err != nil || len(splits) == 0 {
+		return bts, err
+	}
+	fmt.Printf("✂️  Adjusting the %s for %d split(s)\n", what, len(splits))
+	return timeseries.AdjustForSplits(bts, splits), nil
+}
+
+// loadCPI loads the -cpi price index: a CSV file, or a FRED series from two
+// months before the first bar so that it has a reading in effect
+func loadCPI(cfg *runConfig, bts *types.BTCTimeSeries) (*types.AuxSeries, error) {
+	kind, arg, _ := strings.Cut(cfg.CPI, ":")
+	if kind == "csv" {
+		fmt.Printf("🧮 Loading price index: %s\n", arg)
+		return dataloader.LoadCPICSV(arg)
+	}
+	if arg == "" {
+		arg = dataloader.DefaultCPISeries
+	}
+	start, _ := timeseries.GetTimeRange(bts)
+	fmt.Printf("🧮 Fetching %s from FRED...\n", arg)
+	return dataloader.LoadCPIFromFRED(arg, os.Getenv(dataloader.FREDKeyEnv), start.AddDate(0, -2, 0))
+}
+
+// loadFailed logs an auxiliary input that failed to load and records it
+func (inputs *runInputs) loadFailed(what string, err error) {
+	err = fmt.Errorf("failed to load %s: %w", what, err)
+	log.Print(err)
+	inputs.Errors = append(inputs.Errors, err.Error())
+}
+
+// globalMetricsDays returns how many days back CoinGecko history must reach
+// to cover the price series
+func globalMetricsDays(bts *types.BTCTimeSeries) int {
+	start, _ := timeseries.GetTimeRange(bts)
+	days := int(time.Since(start).Hours()/24) + 1
+	if days < 1 {
+		return 1
+	}
+	return days
+}
+
+// deribitResolution picks the finest DVOL resolution no finer than the bar interval
+func deribitResolution(bts *types.BTCTimeSeries) string {
+	interval := timeseries.DetectFrequency(bts).Interval
+	switch {
+	case interval <= time.Minute:
+		return "60"
+	case interval <= time.Hour:
+		return "3600"
+	case interval <= 12*time.Hour:
+		return "43200"
+	}
+	return "1D"
+}
+
+// loadReference loads the reference index for premium tracking. Binance
+// klines are fetched for the period and frequency of the price series.
+func loadReference(cfg *runConfig, bts *types.BTCTimeSeries) (*types.BTCTimeSeries, error) {
+	if cfg.Reference != "binance" {
+		return loadComparisonSource(cfg.Reference, cfg.Days, cfg.OrderBookSymbol, "1d")
+	}
+
+	start, end := timeseries.GetTimeRange(bts)
+	interval := binanceInterval(timeseries.DetectFrequency(bts))
+	fmt.Printf("📡 Fetching %s %s klines from Binance as reference...\n", cfg.OrderBookSymbol, interval)
+	reference, err := dataloader.LoadKlinesFromBinance(cfg.OrderBookSymbol, interval, start, end.Add(time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reference from Binance: %w", err)
+	}
+	return reference, nil
+}
+
+// loadSecondSeries loads spec as the benchmark for beta or the pair of a
+// pairs analysis, named what. binance:<symbol> fetches klines of that
+// symbol for the period and frequency of the price series; other specs load
+// like comparison sources.
+func loadSecondSeries(spec, what string, cfg *runConfig, bts *types.BTCTimeSeries) (*types.BTCTimeSeries, error) {
+	kind, symbol, _ := strings.Cut(spec, ":")
+	if kind != "binance" || symbol == "" {
+		return loadComparisonSource(spec, cfg.Days, cfg.OrderBookSymbol, "1d")
+	}
+
+	start, end := timeseries.GetTimeRange(bts)
+	interval := binanceInterval(timeseries.DetectFrequency(bts))
+	fmt.Printf("📡 Fetching %s %s klines from Binance as %s...\n", symbol, interval, what)
+	series, err := dataloader.LoadKlinesFromBinance(symbol, interval, start, end.Add(time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s from Binance: %w", what, err)
+	}
+	return series, nil
+}
+
+// binanceInterval maps a detected frequency to the closest Binance kline interval
+func binanceInterval(frequency types.Frequency) string {
+	intervals := map[string]string{
+		"minute":    "1m",
+		"5-minute":  "5m",
+		"15-minute": "15m",
+		"30-minute": "30m",
+		"hourly":    "1h",
+		"4-hour":    "4h",
+		"weekly":    "1w",
+	}
+	if interval, ok := intervals[frequency.Name]; ok {
+		return interval
+	}
+	return "1d"
+}
+
+// analyzeInputs runs the analyses of a run, reusing the cached result of an
+// earlier run with the same inputs and parameters unless -force-recompute is set
+func analyzeInputs(inputs *runInputs, cfg *runConfig) *types.AnalysisResult {
+	var warnings []string
+	key, err := analysisKey(inputs, cfg)
+	if err != nil {
+		log.Printf("Result cache disabled: %v", err)
+		warnings = append(warnings, fmt.Sprintf("result cache disabled: %v", err))
+	} else if !cfg.ForceRecompute {
+		if result, ok := resultcache.Load(cfg.ResultCacheDir, key); ok {
+			fmt.Printf("♻️  Reusing cached analysis %s (use -force-recompute to recompute)\n", key[:12])
+			result.Series = inputs.Series
+			result.Metadata.Cached = true
+			result.Metadata.Warnings = warnings
+			return result
+		}
+	}
+
+	start := time.Now()
+	analytics, err := computeAnalytics(inputs, cfg)
+	result := analyzer.NewResult(inputs.Series, analytics, cfg.Signals)
+	result.Metadata.ComputeSeconds = time.Since(start).Seconds()
+	result.Metadata.InputHash = key
+	result.Metadata.Parameters = analysisParams(cfg)
+	if err != nil {
+		// Not cached, so the problem is reported again on the next run
+		recordFailure(result, err)
+		result.Metadata.Warnings = warnings
+		return result
+	}
+	if key != "" {
+		if err := resultcache.Save(cfg.ResultCacheDir, key, result); err != nil {
+			log.Printf("Failed to cache analysis: %v", err)
+			warnings = append(warnings, fmt.Sprintf("failed to cache analysis: %v", err))
+		}
+	}
+	result.Metadata.Warnings = warnings
+	return result
+}
+
+// analysisParams returns the options of cfg that affect the analyses
+func analysisParams(cfg *runConfig) *types.AnalysisParams {
+	params := &types.AnalysisParams{
+		Source:          cfg.Source,
+		Risk:            riskConfig(cfg),
+		MaxLag:          cfg.MaxLag,
+		LargeTradeQty:   cfg.LargeTradeQty,
+		Reference:       cfg.Reference,
+		PremiumWindow:   cfg.PremiumWindow,
+		PremiumZ:        cfg.PremiumZ,
+		Benchmark:       cfg.Benchmark,
+		BetaWindow:      cfg.BetaWindow,
+		Adjust:          cfg.Adjust,
+		BenchmarkAdjust: cfg.BenchmarkAdjust,
+		CPI:             cfg.CPI,
+		ATH:             cfg.ATH,
+		HeatWeights:     cfg.HeatWeights,
+		Indicators:      splitList(cfg.Indicators),
+		Signals:         cfg.Signals,
+	}
+	if cfg.Pair != "" {
+		pc := pairConfig(cfg)
+		params.Pair, params.Pairs = cfg.Pair, &pc
+	}
+	return params
+}
+
+// analysisKey hashes the loaded inputs and every option that affects the
+// analyses. Compiled-in indicators are identified by name only; the build
+// revision in the key covers their code.
+func analysisKey(inputs *runInputs, cfg *runConfig) (string, error) {
+	var indicators []interface{}
+	for _, ind := range inputs.Indicators {
+		if script, ok := ind.(*plugins.Script); ok {
+			indicators = append(indicators, script.Definition())
+		} else {
+			indicators = append(indicators, ind.Name())
+		}
+	}
+	params := analysisParams(cfg)
+	// The source only names where the series came from; the series is hashed
+	params.Source = ""
+
+	return resultcache.Key(inputs.Series, inputs.Warmup, inputs.Trends, inputs.CPI, inputs.Trades, inputs.OrderBook, inputs.Reference,
+		inputs.Dominance, inputs.Stablecoins, inputs.DVOL, inputs.IVTerm, inputs.Benchmark, inputs.Pair, inputs.OnChain, inputs.ATH, params, indicators)
+}
+
+// computeAnalytics runs every analysis the inputs allow. An error means some
+// analysis failed; the analytics hold the results of the others.
+func computeAnalytics(inputs *runInputs, cfg *runConfig) (types.BTCAnalytics, error) {
+	bts := inputs.Series
+	fmt.Println("📊 Performing comprehensive analysis...")
+	analytics := analyzer.AnalyzeWithWarmup(bts, inputs.Warmup, riskConfig(cfg))
+
+	if inputs.Trends != nil {
+		analytics.SearchInterest = analyzer.AnalyzeLeadLag(bts, inputs.Trends, cfg.MaxLag)
+	}
+	if inputs.Dominance != nil {
+		analytics.Dominance = analyzer.AnalyzeLeadLag(bts, inputs.Dominance, cfg.MaxLag)
+	}
+	if inputs.Stablecoins != nil {
+		analytics.Stablecoins = analyzer.AnalyzeLeadLag(bts, inputs.Stablecoins, cfg.MaxLag)
+	}
+	if len(bts.Data) >= analyzer.MinBars(analyzer.AnalysisReturnDrivers) {
+		analytics.ReturnDrivers = analyzer.AnalyzeReturnDrivers(bts, cfg.MaxLag)
+	}
+	if inputs.DVOL != nil {
+		analytics.ImpliedVol = analyzer.AnalyzeImpliedVolatility(bts, inputs.DVOL, inputs.IVTerm, 30, analytics.RiskConvention.PeriodsPerYear)
+	}
+
+	if inputs.OrderBook != nil {
+		metrics := orderbook.Analyze(inputs.OrderBook, 0.02, 5)
+		analytics.OrderBook = &metrics
+	}
+
+	if len(inputs.Trades) > 0 {
+		analytics.OrderFlow = orderflow.Compute(bts, inputs.Trades, cfg.LargeTradeQty)
+	}
+
+	if len(inputs.OnChain) > 0 {
+		analytics.NVT = analyzer.AnalyzeNVT(bts, inputs.OnChain)
+	}
+	if inputs.CPI != nil {
+		analytics.RealPrices = analyzer.AnalyzeRealPrices(bts, inputs.CPI)
+	}
+	if inputs.ATH != nil {
+		source := analyzer.ATHSourceManual
+		if cfg.ATH == "api" {
+			source = analyzer.ATHSourceCoinGecko
+		}
+		analytics.AllTime = analyzer.TrackAllTimeExtremes(bts, inputs.ATH, source)
+	}
+	if inputs.Benchmark != nil {
+		analytics.Beta = comparison.EstimateBeta(bts, inputs.Benchmark, cfg.Benchmark, cfg.BetaWindow)
+	}
+	if inputs.Pair != nil {
+		analytics.Pair = comparison.AnalyzePair(bts, inputs.Pair, cfg.Pair, pairConfig(cfg))
+	}
+	if inputs.Reference != nil {
+		analytics.Premium = comparison.TrackPremium(bts, inputs.Reference, cfg.Reference, cfg.PremiumWindow, cfg.PremiumZ)
+	}
+	analytics.Percentiles = analyzer.RankHeadlineMetrics(bts, analytics)
+	if weights, err := analyzer.ParseHeatWeights(cfg.HeatWeights); err == nil {
+		analytics.MarketHeat = analyzer.MarketHeat(bts, analytics, weights)
+	}
+
+	if len(inputs.Indicators) > 0 {
+		var err error
+		analytics.Custom, err = plugins.Evaluate(analyzer.WithWarmup(bts, inputs.Warmup), inputs.Indicators, splitList(cfg.Indicators))
+		analytics.Custom = plugins.DropBars(analytics.Custom, len(inputs.Warmup))
+		if err != nil {
+			return analytics, fmt.Errorf("failed to evaluate custom indicators: %w", err)
+		}
+	}
+
+	return analytics, nil
+}
+
+// runPipeline validates, analyzes and reports on the loaded inputs. A stage
+// that fails does not stop the others; its error is logged and recorded in
+// the result's metadata.
+func runPipeline(inputs *runInputs, cfg *runConfig) *types.AnalysisResult {
+	bts := inputs.Series
+
+	// Validate data
+	fmt.Println("🔍 Validating data...")
+	issues := dataloader.ValidateData(bts)
+	if len(issues) > 0 {
+		fmt.Printf("⚠️  Data validation warnings:\n")
+		for _, issue := range issues {
+			fmt.Printf("  - %s\n", issue)
+		}
+	} else {
+		fmt.Println("✅ Data validation passed")
+	}
+
+	result := analyzeInputs(inputs, cfg)
+	result.Metadata.DataSource = inputs.ServedBy
+	result.Metadata.Warmup = inputs.WarmupInfo
+	if len(inputs.Failover) > 0 {
+		issues = append(issues, fmt.Sprintf("price series served by %s after failover: %s", inputs.ServedBy, strings.Join(inputs.Failover, "; ")))
+	}
+	issues = append(issues, inputs.Stale...)
+	result.Metadata.Warnings = append(issues, result.Metadata.Warnings...)
+	result.Metadata.Errors = append(append([]string(nil), inputs.Errors...), result.Metadata.Errors...)
+	if cfg.SignalState != nil {
+		if err := cfg.SignalState.Apply(cfg.Source+":"+result.Metadata.Symbol, result); err != nil {
+			recordFailure(result, fmt.Errorf("failed to save signal states: %w", err))
+		}
+	}
+	analytics := result.Analytics
+
+	// Print summary to console
+	reporter.PrintSummary(result, cfg.Messages)
+
+	// Generate charts
+	if cfg.Chart {
+		recordFailure(result, generateSingleChart(result, cfg.OutputDir, cfg.Report, cfg.Messages))
+		if len(analytics.LevelMap) > 0 {
+			recordFailure(result, generateLevelsChart(bts, analytics.LevelMap, priceChartConfig(cfg, "support_resistance"), cfg.OutputDir))
+		}
+		if analytics.OrderBook != nil {
+			recordFailure(result, generateDepthChart(inputs.OrderBook, *analytics.OrderBook, cfg.OutputDir))
+		}
+		if analytics.OrderFlow != nil {
+			recordFailure(result, generateOrderFlowChart(analytics.OrderFlow, cfg.OutputDir))
+		}
+		if analytics.ImpliedVol != nil {
+			recordFailure(result, generateVolatilityChart(analytics.ImpliedVol, cfg.OutputDir))
+		}
+		if len(analytics.ReturnDrivers) > 0 {
+			recordFailure(result, generateCorrelogramChart(analytics.ReturnDrivers, cfg.OutputDir))
+		}
+		if analytics.StockToFlow != nil {
+			recordFailure(result, generateStockToFlowCharts(analytics.StockToFlow, bts.Denomination, cfg.OutputDir))
+		}
+		if analytics.RealPrices != nil {
+			recordFailure(result, generateRealPriceChart(analytics.RealPrices, bts.Denomination, priceChartConfig(cfg, "real_price"), cfg.OutputDir))
+		}
+		if analytics.MarketHeat != nil {
+			recordFailure(result, generateHeatChart(analytics.MarketHeat, cfg.OutputDir))
+		}
+		if analytics.Beta != nil && len(analytics.Beta.Rolling) > 0 {
+			recordFailure(result, generateBetaChart(analytics.Beta, cfg.OutputDir))
+		}
+		if analytics.Beta != nil && inputs.Benchmark != nil {
+			recordFailure(result, generateBenchmarkChart(bts, inputs.Benchmark, cfg.Benchmark, priceChartConfig(cfg, "price_vs_benchmark"), cfg.OutputDir))
+		}
+		if analytics.Premium != nil {
+			recordFailure(result, generatePremiumChart(analytics.Premium, cfg.OutputDir))
+		}
+		if analytics.ElliottWaves != nil {
+			recordFailure(result, generateElliottChart(bts, analytics.ElliottWaves, priceChartConfig(cfg, "elliott_waves"), cfg.OutputDir))
+		}
+		if len(analytics.Harmonics) > 0 {
+			recordFailure(result, generateHarmonicChart(bts, analytics.Harmonics, priceChartConfig(cfg, "harmonic_patterns"), cfg.OutputDir))
+		}
+		for _, ci := range analytics.Custom {
+			recordFailure(result, generateCustomIndicatorChart(ci, cfg.OutputDir))
+		}
+	}
+
+	// Generate reports
+	if cfg.HTMLReport && cfg.ReportMode == reportModeWeekly {
+		recordFailure(result, generateWeeklyReport(result, inputs, cfg))
+	} else if cfg.HTMLReport {
+		htmlPath := fmt.Sprintf("%s/btc_analysis_report.html", cfg.OutputDir)
+		fmt.Printf("📝 Generating HTML report: %s\n", htmlPath)
+		if err := reporter.GenerateHTMLReport(result, htmlPath, cfg.Report, cfg.Messages); err != nil {
+			recordFailure(result, err)
+		} else {
+			fmt.Printf("✅ HTML report generated successfully\n")
+		}
+	}
+
+	if cfg.RiskDashboard {
+		dashboardPath := fmt.Sprintf("%s/risk_dashboard.html", cfg.OutputDir)
+		fmt.Printf("📝 Generating risk dashboard: %s\n", dashboardPath)
+		if err := reporter.GenerateRiskDashboard(result, dashboardPath, 0, cfg.Messages); err != nil {
+			recordFailure(result, err)
+		} else {
+			fmt.Printf("✅ Risk dashboard generated successfully\n")
+		}
+	}
+
+	if cfg.SummaryCard {
+		recordFailure(result, generateSummaryCard(result, cfg.OutputDir))
+	}
+
+	if cfg.Embed {
+		embedDir := fmt.Sprintf("%s/embed", cfg.OutputDir)
+		fmt.Printf("📝 Generating embeddable widgets: %s\n", embedDir)
+		if err := reporter.GenerateWidgets(result, embedDir, cfg.Messages); err != nil {
+			recordFailure(result, err)
+		} else {
+			fmt.Printf("✅ Embeddable widgets generated successfully\n")
+		}
+	}
+
+	if cfg.ICal {
+		icsPath := fmt.Sprintf("%s/btc_events.ics", cfg.OutputDir)
+		events := predictedEvents(inputs, cfg)
+		fmt.Printf("📅 Exporting %d events to calendar: %s\n", len(events), icsPath)
+		if err := calendar.WriteICS(icsPath, events); err != nil {
+			recordFailure(result, fmt.Errorf("failed to export calendar: %w", err))
+		}
+	}
+
+	// Save processed data
+	dataPath := fmt.Sprintf("%s/btc_data.%s", cfg.OutputDir, cfg.ExportFormat)
+	fmt.Printf("💾 Saving data to %s: %s\n", strings.ToUpper(cfg.ExportFormat), dataPath)
+	if err := dataloader.SaveSeries(bts, dataPath, cfg.ExportFormat); err != nil {
+		recordFailure(result, fmt.Errorf("failed to save data: %w", err))
+	}
+
+	// Written last so it records the errors of every other stage
+	if cfg.JSONReport {
+		jsonPath := fmt.Sprintf("%s/btc_analysis_report.json", cfg.OutputDir)
+		fmt.Printf("📝 Generating JSON report: %s\n", jsonPath)
+		if err := reporter.GenerateJSONReport(result, jsonPath); err != nil {
+			recordFailure(result, err)
+		} else {
+			fmt.Printf("✅ JSON report generated successfully\n")
+		}
+	}
+
+	if cfg.Verbose {
+		fmt.Println("\n" + analyzer.GenerateReport(result, cfg.Report))
+	}
+
+	if errs := result.Metadata.Errors; len(errs) > 0 {
+		fmt.Printf("⚠️  %d stage(s) failed, their outputs are missing:\n", len(errs))
+		for _, err := range errs {
+			fmt.Printf("  - %s\n", err)
+		}
+	}
+
+	return result
+}
+
+// recordFailure logs the error of a failed stage and records it in the
+// result's metadata. A nil err is ignored.
+func recordFailure(result *types.AnalysisResult, err error) {
+	if err == nil {
+		return
+	}
+	log.Print(err)
+	result.Metadata.Errors = append(result.Metadata.Errors, err.Error())
+}
+
+// exitPartialFailure is the exit code of a run that completed with some
+// stages failed. Errors that stop a run before any output exit with 1.
+const exitPartialFailure = 2
+
+// exitOnFailure exits with exitPartialFailure when a stage of the run failed
+func exitOnFailure(result *types.AnalysisResult) {
+	if len(result.Metadata.Errors) > 0 {
+		os.Exit(exitPartialFailure)
+	}
+}
+
+// predictedEvents collects the upcoming events of a run for the calendar
+// export: the projected SMA50/SMA200 cross, scheduled DCA buys and the option
+// expiries of the loaded term structure
+func predictedEvents(inputs *runInputs, cfg *runConfig) []calendar.Event {
+	var events []calendar.Event
+	if cross := calendar.ProjectCrossover(inputs.Series, 10, 365); cross != nil {
+		events = append(events, *cross)
+	}
+	if cfg.DCAEvery > 0 {
+		start := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		events = append(events, calendar.DCASchedule(inputs.Series.Symbol, start, cfg.DCAEvery, cfg.DCACount, cfg.DCAAmount)...)
+	}
+	events = append(events, calendar.OptionExpiries(inputs.Series.Symbol, inputs.IVTerm)...)
+	return events
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"btc-analyzer/internal/publish"
+	"flag"
+	"fmt"
+)
+
+// registerPublishFlag registers the -publish flag on fs. It is kept out of
+// runConfig so that reopening a bundle never republishes it.
+func registerPublishFlag(fs *flag.FlagSet) *string {
+	return fs.String("publish", "", "Upload the HTML reports, JSON report and charts after each run to s3://bucket/prefix or gh-pages:<git repository> (empty disables)")
+}
+
+// parsePublishTarget returns the publisher of target, nil when it is empty
+func parsePublishTarget(target string) (publish.Publisher, error) {
+	if target == "" {
+		return nil, nil
+	}
+	return publish.Parse(target)
+}
+
+// publishReports uploads the snapshot of outputDir with publisher
+func publishReports(publisher publish.Publisher, outputDir string) error {
+	files, err := publish.Collect(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	fmt.Printf("🌍 Publishing %d files to %s\n", len(files), publisher)
+	if err := publisher.Publish(outputDir, files); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", publisher, err)
+	}
+	fmt.Printf("✅ Published snapshot to %s\n", publisher)
+	return nil
+}
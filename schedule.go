@@ -0,0 +1,211 @@
+package main
+
+import (
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/i18n"
+	"btc-analyzer/internal/mailer"
+	"btc-analyzer/internal/publish"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// smtpPasswordEnv names the environment variable holding the SMTP password,
+// kept out of flags so it does not show up in process listings
+const smtpPasswordEnv = "BTC_ANALYZER_SMTP_PASSWORD"
+
+// runScheduleCommand runs the analysis every day at a fixed time and emails
+// the HTML report with its charts inline to the configured recipients. The
+// weekly report is sent on Mondays only, without charts.
+func runScheduleCommand(args []string) {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	cfg := registerRunFlags(fs)
+	at := fs.String("at", "07:00", "Local time of day to run and send the report (HH:MM)")
+	smtpHost := fs.String("smtp-host", "", "SMTP server host")
+	smtpPort := fs.Int("smtp-port", 587, "SMTP server port")
+	smtpUser := fs.String("smtp-user", "", "SMTP username (password from "+smtpPasswordEnv+")")
+	from := fs.String("from", "", "Sender address")
+	to := fs.String("to", "", "Comma-separated recipient addresses")
+	retries := fs.Int("retries", 3, "Send attempts before giving up on a report")
+	retryDelay := fs.Duration("retry-delay", time.Minute, "Delay before the first retry, doubled after each failure")
+	dryRun := fs.Bool("dry-run", false, "Write the email to report_email.eml instead of sending it")
+	once := fs.Bool("once", false, "Run and send immediately, then exit")
+	publishTarget := registerPublishFlag(fs)
+	fs.Parse(args)
+	if err := applyEnvFlags(fs); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateRunConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
+	runAt, err := time.Parse("15:04", *at)
+	if err != nil {
+		log.Fatalf("invalid -at time %q: use HH:MM", *at)
+	}
+	if *retries < 1 {
+		log.Fatal("retries must be at least 1")
+	}
+	publisher, err := parsePublishTarget(*publishTarget)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mailCfg := mailer.Config{
+		Host:     *smtpHost,
+		Port:     *smtpPort,
+		Username: *smtpUser,
+		Password: os.Getenv(smtpPasswordEnv),
+		From:     *from,
+		To:       splitList(*to),
+	}
+	if !*dryRun {
+		if err := mailCfg.Validate(); err != nil {
+			log.Fatalf("Invalid mail settings: %v", err)
+		}
+	}
+
+	configureCache(cfg)
+	// The email body is the HTML report
+	cfg.HTMLReport = true
+
+	for {
+		if !*once {
+			next := nextRunTime(time.Now(), runAt)
+			if cfg.ReportMode == reportModeWeekly {
+				next = nextMonday(next)
+			}
+			fmt.Printf("⏰ Next report at %s\n", next.Format("2006-01-02 15:04"))
+			time.Sleep(time.Until(next))
+		}
+
+		if err := runScheduledReport(cfg, mailCfg, publisher, *retries, *retryDelay, *dryRun); err != nil {
+			log.Printf("Scheduled report failed: %v", err)
+		}
+
+		if *once {
+			return
+		}
+	}
+}
+
+// runScheduledReport loads fresh data, runs the pipeline, publishes the
+// snapshot when publisher is set and mails the report
+func runScheduledReport(cfg *runConfig, mailCfg mailer.Config, publisher publish.Publisher, retries int, retryDelay time.Duration, dryRun bool) error {
+	inputs, err := loadInputs(cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	// A partial report is still mailed; its errors are listed in it
+	result := runPipeline(inputs, cfg)
+	// A failed upload does not hold back the email
+	if publisher != nil {
+		if err := publishReports(publisher, cfg.OutputDir); err != nil {
+			log.Print(err)
+		}
+	}
+
+	msg, err := buildReportEmail(cfg.OutputDir, result, cfg.ReportMode, cfg.Messages)
+	if err != nil {
+		return err
+	}
+	message, err := mailer.Build(mailCfg, msg)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		emlPath := filepath.Join(cfg.OutputDir, "report_email.eml")
+		if err := os.WriteFile(emlPath, message, 0644); err != nil {
+			return fmt.Errorf("failed to write email: %w", err)
+		}
+		fmt.Printf("📧 Dry run: email to %s written to %s (%d inline charts)\n",
+			strings.Join(mailCfg.To, ", "), emlPath, len(msg.Images))
+		return nil
+	}
+
+	fmt.Printf("📧 Sending report to %s...\n", strings.Join(mailCfg.To, ", "))
+	if err := mailer.SendWithRetry(mailCfg, message, retries, retryDelay); err != nil {
+		return err
+	}
+	fmt.Println("✅ Report sent")
+	return nil
+}
+
+// buildReportEmail turns the HTML report in outputDir into an email, with
+// every chart in outputDir/charts embedded inline below the report and
+// described from result. The one-page weekly report of the weekly mode is
+// sent as it is. The subject and descriptions are in the locale of msgs.
+func buildReportEmail(outputDir string, result *types.AnalysisResult, mode string, msgs *i18n.Catalog) (mailer.Message, error) {
+	bts := result.Series
+	reportFile, kind := "btc_analysis_report.html", "daily"
+	if mode == reportModeWeekly {
+		reportFile, kind = weeklyReportFile, "weekly"
+	}
+	report, err := os.ReadFile(filepath.Join(outputDir, reportFile))
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("failed to read HTML report: %w", err)
+	}
+
+	msg := mailer.Message{Subject: msgs.T("email.subject."+kind, bts.Symbol, time.Now().Format("2006-01-02"))}
+	if len(bts.Data) > 0 {
+		msg.Subject += fmt.Sprintf(" (%s)", denom.Price(bts.Denomination, timeseries.GetLatestPrice(bts).Close))
+	}
+	if mode == reportModeWeekly {
+		msg.HTML = string(report)
+		return msg, nil
+	}
+
+	charts, _ := filepath.Glob(filepath.Join(outputDir, "charts", "*.png"))
+	sort.Strings(charts)
+
+	var section strings.Builder
+	for _, chart := range charts {
+		data, err := os.ReadFile(chart)
+		if err != nil {
+			return mailer.Message{}, fmt.Errorf("failed to read chart: %w", err)
+		}
+		name := filepath.Base(chart)
+		cid := strings.TrimSuffix(name, ".png") + "@btc-analyzer"
+		msg.Images = append(msg.Images, mailer.InlineImage{ContentID: cid, Filename: name, Data: data})
+		fmt.Fprintf(&section, "    <section class=\"section\"><h2>%s</h2><img src=\"cid:%s\" alt=\"%s\" style=\"max-width: 100%%\"></section>\n",
+			name, cid, html.EscapeString(reporter.ChartAltText(name, result, msgs)))
+	}
+
+	page := string(report)
+	if idx := strings.LastIndex(page, "</body>"); idx >= 0 {
+		page = page[:idx] + section.String() + page[idx:]
+	} else {
+		page += section.String()
+	}
+	msg.HTML = page
+
+	return msg, nil
+}
+
+// nextRunTime returns the next occurrence of the time of day of at after now
+func nextRunTime(now, at time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// nextMonday returns t moved forward by whole days to the first Monday at or
+// after it
+func nextMonday(t time.Time) time.Time {
+	return t.AddDate(0, 0, (int(time.Monday-t.Weekday())+7)%7)
+}
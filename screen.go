@@ -0,0 +1,274 @@
+package main
+
+import (
+	"btc-analyzer/internal/config"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/pool"
+	"btc-analyzer/internal/screener"
+	"btc-analyzer/internal/types"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// maxScreenCoins bounds the coins of a universe; each costs one CoinGecko
+// request, paced at 30 a minute
+const maxScreenCoins = 500
+
+// screenResult is the JSON output of a screen
+type screenResult struct {
+	Universe  string         `json:"universe"`
+	Filter    string         `json:"filter,omitempty"`
+	Generated time.Time      `json:"generated"`
+	Screened  int            `json:"screened"` // coins evaluated, without those that failed to load
+	Failed    []string       `json:"failed,omitempty"`
+	Columns   []string       `json:"columns"`
+	Matches   []screener.Row `json:"matches"`
+}
+
+// runScreenCommand fetches the coins of a universe, computes a light
+// indicator set on the daily bars of each and prints those matching the
+// filter as a table, CSV or JSON. Progress goes to stderr so the output can
+// be piped.
+func runScreenCommand(args []string) {
+	fs := flag.NewFlagSet("screen", flag.ExitOnError)
+	universe := fs.String("universe", "top50", "Coins to screen: topN, the N largest by market cap on CoinGecko, or sampleN, N offline sample coins")
+	filter := fs.String("filter", "", "Expression a coin's latest daily bar must match, e.g. \"rsi<30 && volume_zscore>2\" (empty = all)")
+	days := fs.Int("days", 365, "Days of history to load per coin")
+	sortBy := fs.String("sort", "rank", "Order of the matches: rank, market_cap, symbol or a variable, prefixed with - for descending")
+	format := fs.String("format", "table", "Output format: table, csv or json")
+	out := fs.String("out", "", "File to write the matches to (empty = stdout)")
+	configPath := fs.String("config", "", "JSON config file whose 'precision' section sets the decimals of money and percentages")
+	fs.Parse(args)
+	if err := applyEnvFlags(fs); err != nil {
+		log.Fatal(err)
+	}
+	appConfig, err := config.LoadWithEnv(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if appConfig != nil && appConfig.Precision != nil {
+		if err := numfmt.Configure(*appConfig.Precision); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *format != "table" && *format != "csv" && *format != "json" {
+		log.Fatalf("Invalid -format %q: use table, csv or json", *format)
+	}
+	if *days < 2 {
+		log.Fatal("-days must be at least 2")
+	}
+	kind, n, err := parseUniverse(*universe)
+	if err != nil {
+		log.Fatal(err)
+	}
+	screen, err := screener.New(*filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// check the sort key before spending minutes on requests
+	descending := strings.HasPrefix(*sortBy, "-")
+	key := strings.TrimPrefix(*sortBy, "-")
+	if err := screener.SortRows(nil, key, descending); err != nil {
+		log.Fatal(err)
+	}
+
+	coins := loadScreenCoins(kind, n, *days)
+	result := screenResult{
+		Universe:  *universe,
+		Filter:    *filter,
+		Generated: time.Now().UTC(),
+		Columns:   screen.Columns(),
+		Matches:   []screener.Row{},
+	}
+	for _, coin := range coins {
+		if coin.Series == nil {
+			result.Failed = append(result.Failed, coin.Market.ID)
+			continue
+		}
+		row, match, err := screen.Evaluate(coin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", coin.Market.ID, err)
+			result.Failed = append(result.Failed, coin.Market.ID)
+			continue
+		}
+		result.Screened++
+		if match {
+			result.Matches = append(result.Matches, row)
+		}
+	}
+	if result.Screened == 0 {
+		log.Fatalf("None of the %d coins could be screened", len(coins))
+	}
+	screener.SortRows(result.Matches, key, descending)
+	for i, row := range result.Matches {
+		result.Matches[i] = row.Rounded()
+	}
+	fmt.Fprintf(os.Stderr, "🔎 %d of %d coins match\n", len(result.Matches), result.Screened)
+	if len(result.Failed) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Screened without %d failed coin(s): %s\n", len(result.Failed), strings.Join(result.Failed, ", "))
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "table":
+		err = writeScreenTable(w, result)
+	case "csv":
+		err = writeScreenCSV(w, result)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(result)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write the matches: %v", err)
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "💾 Matches saved: %s\n", *out)
+	}
+}
+
+// parseUniverse splits a universe such as top50 or sample10 into its kind
+// and number of coins
+func parseUniverse(universe string) (string, int, error) {
+	for _, kind := range []string{"top", "sample"} {
+		if rest, ok := strings.CutPrefix(universe, kind); ok {
+			n, err := strconv.Atoi(rest)
+			if err != nil || n < 1 || n > maxScreenCoins {
+				break
+			}
+			return kind, n, nil
+		}
+	}
+	return "", 0, fmt.Errorf("invalid -universe %q: use topN or sampleN with N from 1 to %d", universe, maxScreenCoins)
+}
+
+// loadScreenCoins loads the history of the n coins of a universe
+// concurrently, printing each as it finishes. A coin that fails keeps a nil
+// series.
+func loadScreenCoins(kind string, n, days int) []screener.Coin {
+	var markets []types.CoinMarket
+	if kind == "sample" {
+		for i := 1; i <= n; i++ {
+			markets = append(markets, types.CoinMarket{
+				ID:            fmt.Sprintf("sample-%d", i),
+				Symbol:        fmt.Sprintf("smp%d", i),
+				Name:          fmt.Sprintf("Sample %d", i),
+				MarketCapRank: i,
+				MarketCap:     1e12 / float64(i),
+				CurrentPrice:  50000 / float64(i),
+			})
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "📡 Fetching the top %d coins by market cap from CoinGecko...\n", n)
+		var err error
+		if markets, err = dataloader.LoadTopCoinsFromCoinGecko(n); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(os.Stderr, "📡 Fetching %d days of history per coin (CoinGecko allows about 30 requests a minute)...\n", days)
+	}
+
+	coins := make([]screener.Coin, len(markets))
+	pool.Run(len(markets), pool.DefaultWorkers, func(i int) error {
+		m := markets[i]
+		coins[i].Market = m
+		var bts *types.BTCTimeSeries
+		if kind == "sample" {
+			// shift each coin's random walk so the coins differ on the latest bar
+			bts = dataloader.GenerateSampleData(days+i, m.CurrentPrice)
+		} else {
+			var err error
+			if bts, err = dataloader.LoadCoinFromCoinGecko(m.ID, strings.ToUpper(m.Symbol)+"-USD", days); err != nil {
+				return err
+			}
+		}
+		if len(bts.Data) == 0 {
+			return fmt.Errorf("no data returned")
+		}
+		coins[i].Series = bts
+		return nil
+	}, func(p pool.Progress) {
+		if p.Err != nil {
+			fmt.Fprintf(os.Stderr, "❌ [%d/%d] %s: %v\n", p.Done, p.Total, markets[p.Index].ID, p.Err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "📥 [%d/%d] %s: %d bars\n", p.Done, p.Total, markets[p.Index].ID, len(coins[p.Index].Series.Data))
+	})
+	return coins
+}
+
+// writeScreenTable writes the matches as an aligned table
+func writeScreenTable(w io.Writer, result screenResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := append([]string{"Rank", "Symbol", "Name"}, result.Columns...)
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	for _, row := range result.Matches {
+		fields := []string{strconv.Itoa(row.Rank), row.Symbol, row.Name}
+		for _, column := range result.Columns {
+			fields = append(fields, formatScreenValue(screenValue(row, column), column, false))
+		}
+		fmt.Fprintln(tw, strings.Join(fields, "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+// writeScreenCSV writes the matches as CSV; an undefined value is an empty
+// field
+func writeScreenCSV(w io.Writer, result screenResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"rank", "id", "symbol", "name", "as_of"}, result.Columns...))
+	for _, row := range result.Matches {
+		record := []string{strconv.Itoa(row.Rank), row.ID, row.Symbol, row.Name, row.AsOf.Format("2006-01-02")}
+		for _, column := range result.Columns {
+			record = append(record, formatScreenValue(screenValue(row, column), column, true))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// screenValue returns the value of column in row, which may be the market
+// cap of the coin
+func screenValue(row screener.Row, column string) *float64 {
+	if column == screener.VarMarketCap {
+		return &row.MarketCap
+	}
+	return row.Values[column]
+}
+
+// formatScreenValue formats the value v of column: money and percentages
+// with the decimals of their numfmt kind, other values in full precision or,
+// for the table, with two decimals; "-" when undefined in the table
+func formatScreenValue(v *float64, column string, full bool) string {
+	kind := screener.Kind(column)
+	switch {
+	case v == nil && full:
+		return ""
+	case v == nil:
+		return "-"
+	case kind != "":
+		return numfmt.Format(kind, *v)
+	case full:
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(*v, 'f', 2, 64)
+}
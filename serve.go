@@ -0,0 +1,553 @@
+package main
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/candlelog"
+	"btc-analyzer/internal/config"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/denom"
+	"btc-analyzer/internal/execution"
+	"btc-analyzer/internal/grpcapi"
+	"btc-analyzer/internal/notify"
+	"btc-analyzer/internal/numfmt"
+	"btc-analyzer/internal/paper"
+	"btc-analyzer/internal/pool"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/scheduler"
+	"btc-analyzer/internal/server"
+	"btc-analyzer/internal/signalstate"
+	"btc-analyzer/internal/snapshot"
+	"btc-analyzer/internal/store"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/watchlist"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// analyzeOverrides are the flags the web UI may change through /api/analyze.
+// File paths and output locations stay fixed so the API cannot read or write
+// arbitrary files.
+var analyzeOverrides = map[string]bool{
+	"source":           true,
+	"days":             true,
+	"risk-free":        true,
+	"funding-rate":     true,
+	"periods-per-year": true,
+	"compounding":      true,
+	"vol-estimator":    true,
+	"max-lag":          true,
+	"premium-window":   true,
+	"premium-z":        true,
+}
+
+// serveFlags holds the parsed flags of the serve command
+type serveFlags struct {
+	cfg        *runConfig
+	addr       string
+	grpcAddr   string
+	refresh    time.Duration
+	watchlists string
+	workers    int
+	states     string
+	config     string
+	candleLog  candlelog.Config
+	paper      paperFlags
+	publish    string
+	daemon     bool
+	stateDir   string
+}
+
+// shutdownTimeout bounds the wait for in-flight HTTP requests on shutdown
+const shutdownTimeout = 30 * time.Second
+
+// paperFlags configure paper trading in serve mode
+type paperFlags struct {
+	strategy string
+	store    string
+	balance  float64
+	feeBps   float64
+}
+
+// parseServeFlags parses the serve command line. errorHandling decides
+// whether bad flags exit (startup) or return an error (API overrides).
+func parseServeFlags(args []string, errorHandling flag.ErrorHandling) (*serveFlags, error) {
+	fs := flag.NewFlagSet("serve", errorHandling)
+	sf := &serveFlags{cfg: registerRunFlags(fs)}
+	fs.StringVar(&sf.addr, "addr", ":8080", "HTTP listen address")
+	fs.StringVar(&sf.grpcAddr, "grpc-addr", "", "gRPC listen address of the Analyzer service (empty disables)")
+	fs.DurationVar(&sf.refresh, "refresh", 5*time.Minute, "How often to reload data and re-analyze (0 = never)")
+	fs.StringVar(&sf.watchlists, "watchlists", "watchlists.json", "Watchlist store file (empty disables watchlists)")
+	fs.IntVar(&sf.workers, "watchlist-workers", pool.DefaultWorkers, "How many due watchlists to fetch and analyze at the same time")
+	fs.StringVar(&sf.config, "config", "", "JSON config file with scheduled jobs")
+	fs.StringVar(&sf.states, "signal-state", "signal_state.json", "Signal state store file for damping flapping signals (empty disables damping)")
+	fs.StringVar(&sf.candleLog.Path, "candle-log", "", "Append closed candles to this NDJSON log on every analysis (empty disables)")
+	fs.Int64Var(&sf.candleLog.MaxBytes, "candle-log-max-bytes", 64<<20, "Rotate the candle log before it grows past this size (0 = no limit)")
+	fs.BoolVar(&sf.candleLog.Daily, "candle-log-daily", false, "Rotate the candle log when a candle starts a new UTC day")
+	fs.StringVar(&sf.paper.strategy, "paper", "", "Paper trade this trading signal, e.g. 'RSI' or 'Custom: trend' (empty disables)")
+	fs.StringVar(&sf.paper.store, "paper-store", "paper.json", "Paper trading account file")
+	fs.Float64Var(&sf.paper.balance, "paper-balance", 10000, "Starting balance of a new paper account")
+	fs.Float64Var(&sf.paper.feeBps, "paper-fee-bps", 10, "Fee of each paper trade, in basis points")
+	fs.StringVar(&sf.publish, "publish", "", "Target of the 'publish' job: s3://bucket/prefix or gh-pages:<git repository>")
+	fs.BoolVar(&sf.daemon, "daemon", false, "Run for containers: log only, without console output")
+	fs.StringVar(&sf.stateDir, "state-dir", "", "Directory to run in, holding the stores, logs and reports given by relative paths (empty = current directory)")
+	if errorHandling == flag.ContinueOnError {
+		fs.SetOutput(io.Discard)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := applyEnvFlags(fs); err != nil {
+		return nil, err
+	}
+	if err := validateRunConfig(sf.cfg); err != nil {
+		return nil, err
+	}
+	if sf.workers < 1 {
+		return nil, fmt.Errorf("-watchlist-workers must be at least 1")
+	}
+	return sf, nil
+}
+
+// runServeCommand runs the analysis and serves it over HTTP with the embedded
+// web dashboard, reloading the data and re-running the analysis every
+// refresh interval or when the dashboard asks for different parameters
+func runServeCommand(args []string) {
+	sf, err := parseServeFlags(args, flag.ExitOnError)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if sf.stateDir != "" {
+		if err := os.MkdirAll(sf.stateDir, 0755); err != nil {
+			log.Fatalf("Failed to create state directory: %v", err)
+		}
+		if err := os.Chdir(sf.stateDir); err != nil {
+			log.Fatalf("Failed to enter state directory: %v", err)
+		}
+	}
+	if sf.daemon {
+		// Progress output goes nowhere; logs and errors still reach stderr
+		os.Stdout, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Each refresh must reach the APIs rather than the previous refresh's
+	// answers, which are a little younger than the refresh interval
+	if sf.refresh > 0 && sf.cfg.CacheTTL > sf.refresh/2 {
+		sf.cfg.CacheTTL = sf.refresh / 2
+	}
+	configureCache(sf.cfg)
+
+	publisher, err := parsePublishTarget(sf.publish)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	appConfig, err := config.LoadWithEnv(sf.config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if appConfig != nil && appConfig.Signals != nil && sf.cfg.SignalConfig == "" {
+		sf.cfg.Signals = *appConfig.Signals
+		if err := analyzer.ValidateSignalConfig(sf.cfg.Signals); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if appConfig != nil && appConfig.Report != nil && sf.cfg.SignalConfig == "" {
+		sf.cfg.Report = *appConfig.Report
+		if err := analyzer.ValidateReportConfig(sf.cfg.Report); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if appConfig != nil && appConfig.Precision != nil && sf.cfg.SignalConfig == "" {
+		if err := numfmt.Configure(*appConfig.Precision); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if sf.states != "" {
+		st, err := store.OpenSignalStates(sf.states)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var damping types.SignalStateConfig
+		if appConfig != nil && appConfig.SignalState != nil {
+			damping = *appConfig.SignalState
+		}
+		if sf.cfg.SignalState, err = signalstate.NewTracker(st, damping); err != nil {
+			log.Fatal(err)
+		}
+		damping = signalstate.Resolve(damping)
+		fmt.Printf("🧲 Damping signals from %s: hysteresis %g RSI points and %.1f%% of the price at the bands, %s minimum hold, %s cooldown\n",
+			sf.states, damping.RSIHysteresis, damping.BandHysteresis*100, damping.MinHold, damping.Cooldown)
+	}
+	configSource := sf.config
+	if configSource == "" {
+		configSource = "the environment"
+	}
+
+	var candles *candlelog.Log
+	if sf.candleLog.Path != "" {
+		if candles, err = candlelog.Open(sf.candleLog); err != nil {
+			log.Fatal(err)
+		}
+		defer candles.Close()
+		if last := candles.Last(); !last.IsZero() {
+			fmt.Printf("🕯️  Resuming candle log %s after %s\n", sf.candleLog.Path, last.Format(time.RFC3339))
+		}
+	}
+
+	srv := server.New()
+	srv.SetMessages(sf.cfg.Messages)
+	srv.SetReportConfig(sf.cfg.Report)
+	if sf.paper.strategy != "" {
+		st, err := store.OpenPaper(sf.paper.store)
+		if err != nil {
+			log.Fatal(err)
+		}
+		trader, err := paper.NewTrader(st, sf.paper.strategy, sf.paper.balance, sf.paper.feeBps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv.SetPaper(trader)
+		account := trader.Account()
+		fmt.Printf("📝 Paper trading %s from %s (equity %s)\n", account.Strategy, sf.paper.store, denom.Price(nil, account.Equity))
+	}
+	if appConfig != nil && appConfig.Execution != nil {
+		ex := *appConfig.Execution
+		var exchange execution.Exchange
+		if ex.Live() {
+			if exchange, err = execution.NewExchange(ex); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("🚨 LIVE execution: %s signals place real %s orders for %s on %s (max position %g %s)\n",
+				ex.Strategy, ex.OrderType, ex.Symbol, ex.Exchange, ex.MaxPosition, ex.BaseAsset)
+		} else {
+			fmt.Printf("🧪 Dry-run execution: %s signals are logged as simulated %s orders to %s\n", ex.Strategy, ex.Exchange, ex.AuditLog)
+		}
+		executor, err := execution.New(ex, exchange)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv.SetExecution(executor)
+		fmt.Printf("🛑 Kill switch: create %s or POST /api/execution/kill to stop all orders\n", ex.KillSwitch)
+	}
+	var notifier *notify.Notifier
+	if appConfig != nil && appConfig.Notify != nil {
+		telegram, err := notify.NewTelegram(os.Getenv(notify.TelegramTokenEnv), appConfig.Notify.TelegramChatID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		notifier = notify.New(telegram, appConfig.Notify.Digest != "")
+		if appConfig.Notify.Digest != "" {
+			fmt.Printf("📨 Sending high-urgency alerts to Telegram at once, the others in the %s digest\n", appConfig.Notify.Digest)
+		} else {
+			fmt.Println("📨 Sending every triggered alert to Telegram")
+		}
+	}
+	if err := analyzeForServer(srv, sf.cfg, candles, true); err != nil {
+		log.Fatal(err)
+	}
+	if trader := srv.Paper(); trader != nil {
+		if result, ok := srv.Current(); ok {
+			if _, exists := result.Signals[sf.paper.strategy]; !exists {
+				log.Printf("⚠️  No trading signal named %q; the paper account will hold", sf.paper.strategy)
+			}
+		}
+	}
+
+	// Runs are serialized: the pipeline writes its reports to the output directory
+	var mu sync.Mutex
+	// Closed on shutdown to stop the watchlists and the scheduler
+	stop := make(chan struct{})
+	current := sf.cfg
+	currentArgs := args
+
+	srv.OnAnalyze(func(params map[string]string) error {
+		names := make([]string, 0, len(params))
+		for name := range params {
+			if !analyzeOverrides[name] {
+				return fmt.Errorf("parameter %q cannot be changed from the API", name)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		mu.Lock()
+		defer mu.Unlock()
+		nextArgs := append([]string(nil), currentArgs...)
+		for _, name := range names {
+			nextArgs = append(nextArgs, fmt.Sprintf("-%s=%s", name, params[name]))
+		}
+		next, err := parseServeFlags(nextArgs, flag.ContinueOnError)
+		if err != nil {
+			return err
+		}
+		// Settings of the config file carry over; the signal states stay in one store
+		if next.cfg.SignalConfig == "" {
+			next.cfg.Signals = sf.cfg.Signals
+		}
+		next.cfg.SignalState = sf.cfg.SignalState
+		// Only the startup source feeds the candle log, so an API override
+		// cannot mix candles of another series into it
+		nextCandles := candles
+		if next.cfg.Source != sf.cfg.Source {
+			nextCandles = nil
+		}
+		// Only runs of the startup flags trade, so overrides cannot steer orders
+		if err := analyzeForServer(srv, next.cfg, nextCandles, false); err != nil {
+			return err
+		}
+		current, currentArgs = next.cfg, nextArgs
+		return nil
+	})
+
+	if sf.refresh > 0 {
+		go func() {
+			for range time.Tick(sf.refresh) {
+				mu.Lock()
+				if err := analyzeForServer(srv, current, candles, current == sf.cfg); err != nil {
+					log.Printf("Re-analysis failed, still serving the previous one: %v", err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var manager *watchlist.Manager
+	if sf.watchlists != "" {
+		st, err := store.Open(sf.watchlists)
+		if err != nil {
+			log.Fatal(err)
+		}
+		manager = watchlist.NewManager(st, loadWatchlistSeries, riskConfig(sf.cfg), sf.cfg.Signals)
+		manager.SetWorkers(sf.workers)
+		if sf.cfg.SignalState != nil {
+			manager.SetSignalState(sf.cfg.SignalState)
+		}
+		srv.SetWatchlists(manager)
+		if notifier != nil {
+			manager.SetNotifier(notifier)
+		}
+		go manager.Run(30*time.Second, stop)
+		fmt.Printf("👀 Watching %d watchlists from %s\n", len(st.List("")), sf.watchlists)
+	} else if notifier != nil {
+		log.Printf("⚠️  Watchlists are disabled; no alert will be sent to Telegram")
+	}
+
+	if appConfig != nil {
+		tasks := map[string]scheduler.Task{
+			// fetch refreshes the HTTP cache so the next analysis reads fresh data
+			"fetch": func() error {
+				mu.Lock()
+				cfg := current
+				mu.Unlock()
+				_, err := loadInputs(cfg)
+				return err
+			},
+			"analyze": func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				return analyzeForServer(srv, current, candles, current == sf.cfg)
+			},
+			"report": func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				return writeServedReports(srv, current)
+			},
+			"alerts": func() error {
+				if manager == nil {
+					return fmt.Errorf("watchlists are disabled")
+				}
+				manager.EvaluateAll()
+				return nil
+			},
+			"publish": func() error {
+				if publisher == nil {
+					return fmt.Errorf("publishing is disabled: set -publish")
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				return publishReports(publisher, current.OutputDir)
+			},
+		}
+
+		sch := scheduler.New()
+		for _, job := range appConfig.Jobs {
+			if err := sch.Add(job.Name, job.Schedule, tasks[job.Task]); err != nil {
+				log.Fatalf("Failed to schedule job %s: %v", job.Name, err)
+			}
+		}
+		if notifier != nil && appConfig.Notify.Digest != "" {
+			if err := sch.Add("alert-digest", config.DigestSchedules[appConfig.Notify.Digest], notifier.Flush); err != nil {
+				log.Fatalf("Failed to schedule the alert digest: %v", err)
+			}
+		}
+		srv.SetScheduler(sch)
+		go sch.Run(stop)
+		fmt.Printf("⏰ Scheduled %d jobs from %s\n", len(appConfig.Jobs), configSource)
+	}
+
+	if sf.refresh > 0 {
+		// Stale once two refreshes in a row were missed
+		srv.SetHealthMaxAge(3 * sf.refresh)
+	}
+	httpServer := &http.Server{Addr: sf.addr, Handler: srv.Handler()}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	fmt.Printf("🌐 Serving dashboard on %s (GraphQL at /graphql)\n", sf.addr)
+	var grpcServer *grpcapi.Server
+	if sf.grpcAddr != "" {
+		listener, err := net.Listen("tcp", sf.grpcAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC on %s: %v", sf.grpcAddr, err)
+		}
+		grpcServer = grpcapi.NewServer(srv)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal(err)
+			}
+		}()
+		fmt.Printf("🛰  Serving gRPC on %s\n", sf.grpcAddr)
+	}
+	if sf.daemon {
+		workDir, _ := os.Getwd()
+		log.Printf("Daemon serving %s on %s with state in %s", current.Source, sf.addr, workDir)
+		if appConfig != nil && appConfig.Execution != nil {
+			mode := "dry run"
+			if appConfig.Execution.Live() {
+				mode = "LIVE"
+			}
+			log.Printf("Execution of %s signals on %s: %s", appConfig.Execution.Strategy, appConfig.Execution.Exchange, mode)
+		}
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	<-signals.Done()
+
+	// Finish in-flight requests and runs, then flush what is kept in memory
+	log.Printf("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("HTTP shutdown: %v", err)
+	}
+	if grpcServer != nil {
+		grpcServer.Shutdown(ctx)
+	}
+	close(stop)
+	mu.Lock()
+	if err := writeServedReports(srv, current); err != nil {
+		log.Printf("Failed to write the final reports: %v", err)
+	}
+	mu.Unlock()
+	if notifier != nil && notifier.Pending() > 0 {
+		if err := notifier.Flush(); err != nil {
+			log.Printf("Failed to send the pending alert digest: %v", err)
+		}
+	}
+	log.Printf("Stopped")
+}
+
+// analyzeForServer loads the inputs of cfg, runs the pipeline and publishes
+// the result on srv. Closed candles not yet in candles are appended to it
+// first; candles may be nil. The result is passed to the order executor only
+// when execute is set.
+func analyzeForServer(srv *server.Server, cfg *runConfig, candles *candlelog.Log, execute bool) error {
+	inputs, err := loadInputs(cfg)
+	if err != nil {
+		return err
+	}
+	// A failover source serves another series, which must not enter the log
+	if candles != nil && inputs.ServedBy == cfg.Source {
+		n, err := candles.Append(candlelog.Closed(inputs.Series, time.Now()))
+		if err != nil {
+			log.Printf("Failed to append to the candle log: %v", err)
+		} else if n > 0 && cfg.Verbose {
+			fmt.Printf("🕯️  Logged %d closed candles\n", n)
+		}
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	previous, hadPrevious := srv.Current()
+	result := runPipeline(inputs, cfg)
+	if err := srv.Update(result); err != nil {
+		return err
+	}
+	if trader := srv.Paper(); trader != nil {
+		fill, err := trader.Update(result)
+		if err != nil {
+			log.Printf("Paper trading skipped: %v", err)
+		} else if fill != nil {
+			account := trader.Account()
+			fmt.Printf("📝 Paper %s %.6f @ %s (%s), equity %s\n", fill.Side, fill.Quantity, denom.Price(nil, fill.Price), fill.Signal, denom.Price(nil, account.Equity))
+		}
+	}
+	if executor := srv.Execution(); executor != nil && execute {
+		order, err := executor.OnResult(context.Background(), result)
+		if order != nil {
+			mode := "LIVE"
+			if order.DryRun {
+				mode = "dry-run"
+			}
+			fmt.Printf("💱 %s %s %s %g %s (%s)\n", mode, order.Type, order.Side, order.Quantity, order.Symbol, order.Signal)
+		}
+		if err != nil {
+			log.Printf("Execution: %v", err)
+		}
+	}
+	if hadPrevious {
+		diff := snapshot.Diff(previous, result)
+		printChangelog(diff)
+		if err := writeJSONFile(filepath.Join(cfg.OutputDir, diffFile), diff); err != nil {
+			log.Printf("Failed to save diff: %v", err)
+		}
+	}
+	return nil
+}
+
+// writeServedReports writes the HTML, JSON and risk dashboard reports of the
+// analysis srv is serving into the output directory of cfg
+func writeServedReports(srv *server.Server, cfg *runConfig) error {
+	result, ok := srv.Current()
+	if !ok {
+		return fmt.Errorf("no analysis available yet")
+	}
+	if err := reporter.GenerateHTMLReport(result, fmt.Sprintf("%s/btc_analysis_report.html", cfg.OutputDir), cfg.Report, cfg.Messages); err != nil {
+		return err
+	}
+	if err := reporter.GenerateJSONReport(result, fmt.Sprintf("%s/btc_analysis_report.json", cfg.OutputDir)); err != nil {
+		return err
+	}
+	return reporter.GenerateRiskDashboard(result, fmt.Sprintf("%s/risk_dashboard.html", cfg.OutputDir), 0, cfg.Messages)
+}
+
+// loadWatchlistSeries loads the last Days of a watchlist's symbol and timeframe
+func loadWatchlistSeries(w types.Watchlist) (*types.BTCTimeSeries, error) {
+	if w.Source == "sample" {
+		bts, err := loadSeries(&runConfig{Source: "sample", Days: w.Days})
+		if err != nil {
+			return nil, err
+		}
+		bts.Symbol = w.Symbol
+		return bts, nil
+	}
+	end := time.Now()
+	return dataloader.LoadKlinesFromBinance(w.Symbol, w.Timeframe, end.AddDate(0, 0, -w.Days), end)
+}
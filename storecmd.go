@@ -0,0 +1,460 @@
+package main
+
+import (
+	"btc-analyzer/internal/bundle"
+	"btc-analyzer/internal/candlelog"
+	"btc-analyzer/internal/dataloader"
+	"btc-analyzer/internal/store"
+	"btc-analyzer/internal/types"
+	"btc-analyzer/internal/version"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// storeArchiveFormat is the layout version of the archives store export
+// writes; import refuses archives of a later layout
+const storeArchiveFormat = 1
+
+// Kinds of archive entry besides the store kinds
+const (
+	archiveCandles = "candles" // candle log as CSV, read back on import
+	archiveTable   = "table"   // flattened CSV view of a store, for other tools only
+)
+
+// storeArchiveFile is a store file that store export and import carry: its
+// kind, name in the archive and path flag
+type storeArchiveFile struct {
+	kind, file, flag, path, usage string
+}
+
+// storeArchiveFiles are the persisted stores in an archive. Backfill
+// checkpoints are left out: they only matter on the machine that staged the
+// download.
+var storeArchiveFiles = []storeArchiveFile{
+	{store.KindWatchlists, "watchlists.json", "watchlists", "watchlists.json", "Watchlist store file"},
+	{store.KindRuns, "backtests.json", "backtest-store", "backtests.json", "Backtest run store file"},
+	{store.KindSignalStates, "signal_states.json", "signal-state", "signal_state.json", "Signal state store file"},
+	{store.KindPaper, "paper.json", "paper-store", "paper.json", "Paper trading account file"},
+}
+
+// storeManifest is manifest.json of a store archive
+type storeManifest struct {
+	Format    int                  `json:"format"`
+	Version   string               `json:"version"` // btc-analyzer build that wrote it
+	CreatedAt time.Time            `json:"created_at"`
+	Entries   []storeManifestEntry `json:"entries"`
+}
+
+// storeManifestEntry describes one file of a store archive
+type storeManifestEntry struct {
+	File          string `json:"file"`
+	Kind          string `json:"kind"`                     // a store kind, "candles" or "table"
+	SchemaVersion int    `json:"schema_version,omitempty"` // of a store's data
+	Symbol        string `json:"symbol,omitempty"`         // of the candles
+	Records       int    `json:"records"`
+	SHA256        string `json:"sha256"`
+}
+
+// storeDocument is the layout of a store's file in an archive, matching a
+// versioned store file
+type storeDocument struct {
+	SchemaVersion int             `json:"schema_version"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// runStoreCommand exports the persisted stores and candle log to a portable
+// archive or imports one
+func runStoreCommand(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			runStoreExport(args[1:])
+			return
+		case "import":
+			runStoreImport(args[1:])
+			return
+		}
+	}
+	log.Fatal("usage: btc-analyzer store export|import [flags]")
+}
+
+// registerStorePaths registers a path flag for every store in an archive
+// and returns the paths by kind
+func registerStorePaths(fs *flag.FlagSet) map[string]*string {
+	paths := make(map[string]*string)
+	for _, f := range storeArchiveFiles {
+		paths[f.kind] = fs.String(f.flag, f.path, f.usage+" (empty = skip)")
+	}
+	return paths
+}
+
+// runStoreExport writes the stores and candle log to a tar.gz of CSV and
+// JSON files with a manifest
+func runStoreExport(args []string) {
+	fs := flag.NewFlagSet("store export", flag.ExitOnError)
+	archivePath := fs.String("out", "btc_store.tar.gz", "Archive file to write")
+	candleLogPath := fs.String("candle-log", "", "Candle log to export (empty = skip)")
+	symbol := fs.String("symbol", "BTC", "Symbol of the candle log's candles")
+	paths := registerStorePaths(fs)
+	fs.Parse(args)
+
+	staging, err := os.MkdirTemp("", "btc-analyzer-store-*")
+	if err != nil {
+		log.Fatalf("Failed to create staging directory: %v", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest := storeManifest{Format: storeArchiveFormat, Version: version.String(), CreatedAt: time.Now().UTC()}
+	for _, f := range storeArchiveFiles {
+		path := *paths[f.kind]
+		if path == "" {
+			continue
+		}
+		entries, err := exportStore(staging, f, path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		manifest.Entries = append(manifest.Entries, entries...)
+	}
+	if *candleLogPath != "" {
+		entry, err := exportCandles(staging, *candleLogPath, *symbol)
+		if err != nil {
+			log.Fatal(err)
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+	if len(manifest.Entries) == 0 {
+		log.Fatal("Nothing to export: none of the store files exist")
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(staging, "manifest.json"), data, 0644); err != nil {
+		log.Fatalf("Failed to write manifest: %v", err)
+	}
+	if err := bundle.Create(*archivePath, staging); err != nil {
+		log.Fatalf("Failed to create store archive: %v", err)
+	}
+
+	for _, entry := range manifest.Entries {
+		fmt.Printf("   %-22s %-16s %d records\n", entry.File, entry.Kind, entry.Records)
+	}
+	fmt.Printf("📦 Store archive written: %s\n", *archivePath)
+}
+
+// exportStore writes the store f at path into dir at the current schema,
+// with a CSV view of the stores that have one; none when the file does not
+// exist
+func exportStore(dir string, f storeArchiveFile, path string) ([]storeManifestEntry, error) {
+	data, ok, err := store.ReadData(path, f.kind)
+	if err != nil || !ok {
+		return nil, err
+	}
+	records, err := store.Records(f.kind, data)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := json.MarshalIndent(storeDocument{SchemaVersion: store.SchemaVersion(f.kind), Data: data}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", f.kind, err)
+	}
+	entry, err := writeArchiveFile(dir, f.file, doc)
+	if err != nil {
+		return nil, err
+	}
+	entry.Kind, entry.SchemaVersion, entry.Records = f.kind, store.SchemaVersion(f.kind), records
+	entries := []storeManifestEntry{entry}
+
+	var header []string
+	var rows [][]string
+	switch f.kind {
+	case store.KindRuns:
+		var runs []types.BacktestRun
+		if err := json.Unmarshal(data, &runs); err != nil {
+			return nil, fmt.Errorf("invalid %s data: %w", f.kind, err)
+		}
+		header, rows = backtestTable(runs)
+	case store.KindSignalStates:
+		var states map[string]map[string]types.SignalState
+		if err := json.Unmarshal(data, &states); err != nil {
+			return nil, fmt.Errorf("invalid %s data: %w", f.kind, err)
+		}
+		header, rows = signalStateTable(states)
+	default:
+		return entries, nil
+	}
+	table, err := encodeCSV(header, rows)
+	if err != nil {
+		return nil, err
+	}
+	name := f.file[:len(f.file)-len(filepath.Ext(f.file))] + ".csv"
+	tableEntry, err := writeArchiveFile(dir, name, table)
+	if err != nil {
+		return nil, err
+	}
+	tableEntry.Kind, tableEntry.Records = archiveTable, len(rows)
+	return append(entries, tableEntry), nil
+}
+
+// exportCandles writes the candle log at path into dir as candles.csv, with
+// full timestamps and prices, in a layout -source csv reads
+func exportCandles(dir, path, symbol string) (storeManifestEntry, error) {
+	bts, err := candlelog.Load(path, symbol)
+	if err != nil {
+		return storeManifestEntry{}, err
+	}
+	rows := make([][]string, 0, len(bts.Data))
+	for _, bar := range bts.Data {
+		row := []string{bar.Timestamp.UTC().Format("2006-01-02T15:04:05Z")}
+		for _, v := range []float64{bar.Open, bar.High, bar.Low, bar.Close, bar.Volume} {
+			row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		rows = append(rows, row)
+	}
+	data, err := encodeCSV([]string{"Timestamp", "Open", "High", "Low", "Close", "Volume"}, rows)
+	if err != nil {
+		return storeManifestEntry{}, err
+	}
+	entry, err := writeArchiveFile(dir, "candles.csv", data)
+	if err != nil {
+		return storeManifestEntry{}, err
+	}
+	entry.Kind, entry.Symbol, entry.Records = archiveCandles, symbol, len(rows)
+	return entry, nil
+}
+
+// backtestTable flattens stored backtest runs into one row per run, with
+// their costs and metrics at full precision, like the store itself
+func backtestTable(runs []types.BacktestRun) ([]string, [][]string) {
+	header := []string{"id", "created_at", "strategy", "symbol", "source", "start", "end", "bars", "fee_bps", "slippage_bps",
+		"total_return", "annualized_return", "volatility", "sharpe_ratio", "max_drawdown", "trades", "win_rate", "exposure"}
+	formatFloat := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	var rows [][]string
+	for _, run := range runs {
+		m := run.Metrics
+		rows = append(rows, []string{
+			run.ID, run.CreatedAt.UTC().Format(time.RFC3339), run.Strategy, run.Symbol, run.Source,
+			run.Start.UTC().Format(time.RFC3339), run.End.UTC().Format(time.RFC3339), strconv.Itoa(run.Bars),
+			formatFloat(run.Costs.FeeBps), formatFloat(run.Costs.SlippageBps),
+			formatFloat(m.TotalReturn), formatFloat(m.AnnualizedReturn), formatFloat(m.Volatility), formatFloat(m.SharpeRatio),
+			formatFloat(m.MaxDrawdown), strconv.Itoa(m.Trades), formatFloat(m.WinRate), formatFloat(m.Exposure),
+		})
+	}
+	return header, rows
+}
+
+// signalStateTable flattens the signal states into one row per series and
+// signal, sorted
+func signalStateTable(states map[string]map[string]types.SignalState) ([]string, [][]string) {
+	header := []string{"series", "signal_name", "action", "signal", "since", "exited_action", "exited_at"}
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	var rows [][]string
+	for series, signals := range states {
+		for name, state := range signals {
+			rows = append(rows, []string{series, name, state.Action, state.Signal, formatTime(state.Since), state.ExitedAction, formatTime(state.ExitedAt)})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i][0] != rows[j][0] {
+			return rows[i][0] < rows[j][0]
+		}
+		return rows[i][1] < rows[j][1]
+	})
+	return header, rows
+}
+
+// encodeCSV encodes header and rows as CSV
+func encodeCSV(header []string, rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeArchiveFile writes data as name in dir and returns its manifest
+// entry with the checksum filled in
+func writeArchiveFile(dir, name string, data []byte) (storeManifestEntry, error) {
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return storeManifestEntry{}, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	return storeManifestEntry{File: name, SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+// runStoreImport restores the stores and candles of an archive written by
+// store export. Existing store files are kept unless -force, which backs
+// them up first; candles are appended after the last one of the candle log.
+func runStoreImport(args []string) {
+	fs := flag.NewFlagSet("store import", flag.ExitOnError)
+	candleLogPath := fs.String("candle-log", "", "Candle log to append the archive's candles to (empty = skip)")
+	force := fs.Bool("force", false, "Replace existing store files, keeping each as <file>.pre-import.bak")
+	paths := registerStorePaths(fs)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatal("usage: btc-analyzer store import [flags] <store.tar.gz>")
+	}
+	archivePath := fs.Arg(0)
+
+	staging, err := os.MkdirTemp("", "btc-analyzer-store-*")
+	if err != nil {
+		log.Fatalf("Failed to create staging directory: %v", err)
+	}
+	defer os.RemoveAll(staging)
+	if err := bundle.Extract(archivePath, staging); err != nil {
+		log.Fatalf("Failed to extract store archive: %v", err)
+	}
+	manifest, err := readStoreManifest(staging)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Check every entry before writing anything, so a bad archive or an
+	// existing file leaves all stores as they were
+	for _, entry := range manifest.Entries {
+		if entry.Kind == archiveTable {
+			continue
+		}
+		path := importPath(entry, paths, *candleLogPath)
+		if path == "" {
+			continue
+		}
+		if entry.Kind != archiveCandles && !*force {
+			if _, err := os.Stat(path); err == nil {
+				log.Fatalf("%s %s already exists; use -force to replace it", entry.Kind, path)
+			}
+		}
+	}
+
+	fmt.Printf("📦 Importing store archive %s (written %s by %s)\n", archivePath, manifest.CreatedAt.Format("2006-01-02 15:04"), manifest.Version)
+	for _, entry := range manifest.Entries {
+		if entry.Kind == archiveTable {
+			continue
+		}
+		path := importPath(entry, paths, *candleLogPath)
+		if path == "" {
+			fmt.Printf("   %-22s skipped (no destination)\n", entry.File)
+			continue
+		}
+		file := filepath.Join(staging, entry.File)
+		var n int
+		if entry.Kind == archiveCandles {
+			n, err = importCandles(file, path)
+		} else {
+			n, err = importStore(file, entry.Kind, path)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   %-22s %d records → %s\n", entry.File, n, path)
+	}
+	fmt.Println("✅ Store archive imported")
+}
+
+// readStoreManifest reads the manifest of an archive extracted into dir and
+// checks the layout version and the checksum of every file
+func readStoreManifest(dir string) (*storeManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("not a store archive: no manifest.json")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest storeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if manifest.Format > storeArchiveFormat {
+		return nil, fmt.Errorf("store archive has format %d, newer than format %d of this build; upgrade btc-analyzer", manifest.Format, storeArchiveFormat)
+	}
+	for _, entry := range manifest.Entries {
+		if entry.File != filepath.Base(entry.File) {
+			return nil, fmt.Errorf("invalid file name %q in manifest", entry.File)
+		}
+		file, err := os.Open(filepath.Join(dir, entry.File))
+		if err != nil {
+			return nil, fmt.Errorf("store archive is missing %s: %w", entry.File, err)
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.File, err)
+		}
+		if hex.EncodeToString(hash.Sum(nil)) != entry.SHA256 {
+			return nil, fmt.Errorf("%s does not match its checksum; the archive is corrupt", entry.File)
+		}
+	}
+	return &manifest, nil
+}
+
+// importPath returns where the archive entry is imported to, empty when its
+// destination flag is empty or its kind is unknown to this build
+func importPath(entry storeManifestEntry, paths map[string]*string, candleLogPath string) string {
+	if entry.Kind == archiveCandles {
+		return candleLogPath
+	}
+	if path, ok := paths[entry.Kind]; ok {
+		return *path
+	}
+	return ""
+}
+
+// importStore writes the store document file of kind to path, backing up
+// an existing file, and returns the number of records
+func importStore(file, kind, path string) (int, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", filepath.Base(file), err)
+	}
+	var doc storeDocument
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return 0, fmt.Errorf("failed to decode %s: %w", filepath.Base(file), err)
+	}
+	if existing, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+".pre-import.bak", existing, 0644); err != nil {
+			return 0, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	return store.WriteData(path, kind, doc.SchemaVersion, doc.Data)
+}
+
+// importCandles appends the candles of a CSV file to the candle log at path
+// and returns how many were new
+func importCandles(file, path string) (int, error) {
+	bts, err := dataloader.LoadFromCSV(file)
+	if err != nil {
+		return 0, err
+	}
+	candles, err := candlelog.Open(candlelog.Config{Path: path})
+	if err != nil {
+		return 0, err
+	}
+	defer candles.Close()
+	return candles.Append(bts.Data)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"btc-analyzer/internal/analyzer"
+	"btc-analyzer/internal/reporter"
+	"btc-analyzer/internal/snapshot"
+	"btc-analyzer/internal/timeseries"
+	"btc-analyzer/internal/types"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Report modes of -report-mode
+const (
+	reportModeFull   = "full"
+	reportModeWeekly = "weekly"
+)
+
+// weeklyReportFile is the HTML report of the weekly mode, written in place of
+// the full report
+const weeklyReportFile = "weekly_report.html"
+
+// generateWeeklyReport writes the weekly report on the last complete week of
+// the inputs. The week and the week before are analyzed as of their ends;
+// result is reused when the series ends with the week.
+func generateWeeklyReport(result *types.AnalysisResult, inputs *runInputs, cfg *runConfig) error {
+	bts := inputs.Series
+	if len(bts.Data) == 0 {
+		return fmt.Errorf("no data for the weekly report")
+	}
+	start, complete := snapshot.ReportWeek(bts)
+	fmt.Printf("📅 Analyzing the week of %s\n", start.Format("2006-01-02"))
+
+	current := result
+	if end := snapshot.WeekEnd(start); end.Before(bts.Data[len(bts.Data)-1].Timestamp) {
+		var err error
+		if current, err = analyzeAsOf(inputs, cfg, end); err != nil {
+			return err
+		}
+	}
+	var previous *types.AnalysisResult
+	if end := snapshot.WeekEnd(start.AddDate(0, 0, -7)); !bts.Data[0].Timestamp.After(end) {
+		var err error
+		if previous, err = analyzeAsOf(inputs, cfg, end); err != nil {
+			return err
+		}
+	}
+
+	report := snapshot.Weekly(current, previous, start, complete)
+	path := filepath.Join(cfg.OutputDir, weeklyReportFile)
+	fmt.Printf("📝 Generating weekly report: %s\n", path)
+	if err := reporter.GenerateWeeklyReport(&report, path, cfg.Messages); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Weekly report generated successfully\n")
+	return nil
+}
+
+// analyzeAsOf analyzes the inputs with the price series cut off at end
+func analyzeAsOf(inputs *runInputs, cfg *runConfig, end time.Time) (*types.AnalysisResult, error) {
+	bts := inputs.Series
+	cut := *inputs
+	cut.Series = &types.BTCTimeSeries{
+		Symbol:       bts.Symbol,
+		Data:         timeseries.Between(bts, bts.Data[0].Timestamp, end),
+		Denomination: bts.Denomination,
+	}
+	analytics, err := computeAnalytics(&cut, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze the series as of %s: %w", end.Format("2006-01-02"), err)
+	}
+	return analyzer.NewResult(cut.Series, analytics, cfg.Signals), nil
+}